@@ -0,0 +1,108 @@
+// Package address converts and validates the account addresses of the chain, which are 20 bytes EVM addresses
+// written either as 0x hex strings or bech32 strings of Bech32Prefix
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Bech32Prefix is the bech32 human readable prefix of the account addresses of the chain
+const Bech32Prefix = "mc"
+
+var (
+	// ErrInvalidAddress is returned when a string is neither a 0x hex address nor a bech32 address
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrInvalidChecksum is returned when a mixed case hex address does not match its EIP-55 checksum
+	ErrInvalidChecksum = errors.New("invalid address checksum")
+	// ErrInvalidPrefix is returned when a bech32 address has another prefix than the expected one
+	ErrInvalidPrefix = errors.New("invalid bech32 address prefix")
+	// ErrZeroAddress is returned when an address which must be set is the zero address
+	ErrZeroAddress = errors.New("zero address")
+)
+
+// ToBech32 return the bech32 string of the address with Bech32Prefix
+func ToBech32(addr common.Address) string {
+	return ToBech32WithPrefix(Bech32Prefix, addr)
+}
+
+// ToBech32WithPrefix return the bech32 string of the address with the prefix
+func ToBech32WithPrefix(prefix string, addr common.Address) string {
+	encoded, err := bech32Encode(strings.ToLower(prefix), addr.Bytes())
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}
+
+// FromBech32 parses a bech32 address with Bech32Prefix
+func FromBech32(encoded string) (common.Address, error) {
+	return FromBech32WithPrefix(Bech32Prefix, encoded)
+}
+
+// FromBech32WithPrefix parses a bech32 address with the prefix
+func FromBech32WithPrefix(prefix, encoded string) (common.Address, error) {
+	hrp, data, err := bech32Decode(encoded)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrInvalidAddress, err)
+	}
+	if hrp != strings.ToLower(prefix) {
+		return common.Address{}, fmt.Errorf("%w: %q, expect %q", ErrInvalidPrefix, hrp, prefix)
+	}
+	if len(data) != common.AddressLength {
+		return common.Address{}, fmt.Errorf("%w: %d bytes", ErrInvalidAddress, len(data))
+	}
+	return common.BytesToAddress(data), nil
+}
+
+// FromHex parses a 0x hex address, a mixed case address must match its EIP-55 checksum
+func FromHex(hex string) (common.Address, error) {
+	if !common.IsHexAddress(hex) || !strings.HasPrefix(strings.ToLower(hex), "0x") {
+		return common.Address{}, fmt.Errorf("%w: %q", ErrInvalidAddress, hex)
+	}
+	if err := ValidateChecksum(hex); err != nil {
+		return common.Address{}, err
+	}
+	return common.HexToAddress(hex), nil
+}
+
+// ValidateChecksum return ErrInvalidChecksum if the mixed case hex address does not match its EIP-55 checksum,
+// an all lower or all upper case address carries no checksum
+func ValidateChecksum(hex string) error {
+	digits := hex[min(2, len(hex)):]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return nil
+	}
+	if expected := common.HexToAddress(hex).Hex(); expected[2:] != digits {
+		return fmt.Errorf("%w: %s, expect %s", ErrInvalidChecksum, hex, expected)
+	}
+	return nil
+}
+
+// Parse parses a 0x hex address or a bech32 address with Bech32Prefix
+func Parse(addr string) (common.Address, error) {
+	if strings.HasPrefix(strings.ToLower(addr), "0x") {
+		return FromHex(addr)
+	}
+	return FromBech32(addr)
+}
+
+// IsZero return true if the address is the zero address
+func IsZero(addr common.Address) bool {
+	return addr == common.Address{}
+}
+
+// ParseNonZero parses the address like Parse and return ErrZeroAddress if it is the zero address
+func ParseNonZero(addr string) (common.Address, error) {
+	parsed, err := Parse(addr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if IsZero(parsed) {
+		return common.Address{}, ErrZeroAddress
+	}
+	return parsed, nil
+}
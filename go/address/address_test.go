@@ -0,0 +1,101 @@
+package address
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBech32(t *testing.T) {
+	// BIP-173 valid strings
+	for _, valid := range []string{
+		"A12UEL5L",
+		"a12uel5l",
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+		"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w",
+	} {
+		hrp, _, err := bech32Decode(valid)
+		assert.NoError(t, err, valid)
+		assert.Equal(t, strings.ToLower(valid[:strings.LastIndexByte(valid, '1')]), hrp)
+	}
+	// BIP-173 invalid strings
+	for _, invalid := range []string{
+		"pzry9x0s0muk", "1pzry9x0s0muk", "x1b4n0q5v", "li1dgmt3", "A1G7SGD8", "10a06t8", "1qzzfhee", "A12uEL5L",
+	} {
+		_, _, err := bech32Decode(invalid)
+		assert.ErrorIs(t, err, errInvalidBech32, invalid)
+	}
+
+	assert.Equal(t, "cosmos1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqnrql8a", ToBech32WithPrefix("cosmos", common.Address{}))
+}
+
+func TestConvert(t *testing.T) {
+	addr := common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+	encoded := ToBech32(addr)
+	assert.True(t, strings.HasPrefix(encoded, Bech32Prefix+"1"))
+
+	decoded, err := FromBech32(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, addr, decoded)
+	decoded, err = FromBech32(strings.ToUpper(encoded))
+	require.NoError(t, err)
+	assert.Equal(t, addr, decoded)
+
+	for _, s := range []string{encoded, addr.Hex(), strings.ToLower(addr.Hex()), "0X" + addr.Hex()[2:]} {
+		parsed, err := Parse(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, addr, parsed, s)
+	}
+
+	_, err = FromBech32(ToBech32WithPrefix("cosmos", addr))
+	assert.ErrorIs(t, err, ErrInvalidPrefix)
+	corrupted := []byte(encoded)
+	corrupted[len(corrupted)-1] ^= 1
+	_, err = Parse(string(corrupted))
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	short, err := bech32Encode(Bech32Prefix, addr.Bytes()[:19])
+	require.NoError(t, err)
+	_, err = FromBech32(short)
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+}
+
+func TestChecksum(t *testing.T) {
+	// EIP-55 test vectors
+	for _, valid := range []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		"0x52908400098527886e0f7030069857d2e4169ee7",
+		"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+	} {
+		assert.NoError(t, ValidateChecksum(valid), valid)
+		_, err := FromHex(valid)
+		assert.NoError(t, err, valid)
+	}
+	_, err := FromHex("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD")
+	assert.ErrorIs(t, err, ErrInvalidChecksum)
+
+	for _, invalid := range []string{
+		"", "0x", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeg",
+	} {
+		_, err = FromHex(invalid)
+		assert.ErrorIs(t, err, ErrInvalidAddress, invalid)
+	}
+}
+
+func TestZero(t *testing.T) {
+	assert.True(t, IsZero(common.Address{}))
+	assert.False(t, IsZero(common.HexToAddress("0x01")))
+	_, err := ParseNonZero("0x0000000000000000000000000000000000000000")
+	assert.ErrorIs(t, err, ErrZeroAddress)
+	_, err = ParseNonZero(ToBech32(common.Address{}))
+	assert.ErrorIs(t, err, ErrZeroAddress)
+	addr, err := ParseNonZero(ToBech32(common.HexToAddress("0x01")))
+	require.NoError(t, err)
+	assert.Equal(t, common.HexToAddress("0x01"), addr)
+}
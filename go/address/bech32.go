@@ -0,0 +1,133 @@
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// bech32Charset is the BIP-173 alphabet of the 5 bit groups
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32MaxLength is the maximum length of a BIP-173 string
+const bech32MaxLength = 90
+
+var errInvalidBech32 = errors.New("invalid bech32 string")
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32Polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i, g := range bech32Generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+// bech32HRPExpand return the high bits of the hrp characters followed by their low bits
+func bech32HRPExpand(hrp string) []byte {
+	expanded := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]>>5)
+	}
+	expanded = append(expanded, 0)
+	for i := 0; i < len(hrp); i++ {
+		expanded = append(expanded, hrp[i]&31)
+	}
+	return expanded
+}
+
+func bech32Checksum(hrp string, data []byte) []byte {
+	values := append(bech32HRPExpand(hrp), data...)
+	polymod := bech32Polymod(append(values, 0, 0, 0, 0, 0, 0)) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(polymod>>uint(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// convertBits regroups the fromBits groups of data into toBits groups, the last group is zero padded if pad is
+// true and must be made of zero padding bits otherwise
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc, bits uint
+	maxValue := uint(1)<<toBits - 1
+	converted := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, value := range data {
+		if uint(value)>>fromBits != 0 {
+			return nil, fmt.Errorf("%w: invalid data value %d", errInvalidBech32, value)
+		}
+		acc = acc<<fromBits | uint(value)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			converted = append(converted, byte(acc>>bits&maxValue))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			converted = append(converted, byte(acc<<(toBits-bits)&maxValue))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxValue != 0 {
+		return nil, fmt.Errorf("%w: invalid padding", errInvalidBech32)
+	}
+	return converted, nil
+}
+
+// bech32Encode return the bech32 string of the hrp and the bytes of data
+func bech32Encode(hrp string, data []byte) (string, error) {
+	groups, err := convertBits(data, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	var encoded strings.Builder
+	encoded.WriteString(hrp)
+	encoded.WriteByte('1')
+	for _, group := range append(groups, bech32Checksum(hrp, groups)...) {
+		encoded.WriteByte(bech32Charset[group])
+	}
+	return encoded.String(), nil
+}
+
+// bech32Decode return the hrp and the bytes of a bech32 string, the hrp is lower cased
+func bech32Decode(encoded string) (string, []byte, error) {
+	if len(encoded) > bech32MaxLength {
+		return "", nil, fmt.Errorf("%w: length %d", errInvalidBech32, len(encoded))
+	}
+	lower := strings.ToLower(encoded)
+	if lower != encoded && strings.ToUpper(encoded) != encoded {
+		return "", nil, fmt.Errorf("%w: mixed case", errInvalidBech32)
+	}
+	separator := strings.LastIndexByte(lower, '1')
+	if separator < 1 || separator+7 > len(lower) {
+		return "", nil, fmt.Errorf("%w: invalid separator position", errInvalidBech32)
+	}
+	hrp := lower[:separator]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, fmt.Errorf("%w: invalid hrp character", errInvalidBech32)
+		}
+	}
+	groups := make([]byte, 0, len(lower)-separator-1)
+	for i := separator + 1; i < len(lower); i++ {
+		group := strings.IndexByte(bech32Charset, lower[i])
+		if group < 0 {
+			return "", nil, fmt.Errorf("%w: invalid character %q", errInvalidBech32, lower[i])
+		}
+		groups = append(groups, byte(group))
+	}
+	if bech32Polymod(append(bech32HRPExpand(hrp), groups...)) != 1 {
+		return "", nil, fmt.Errorf("%w: invalid checksum", errInvalidBech32)
+	}
+	data, err := convertBits(groups[:len(groups)-6], 5, 8, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return hrp, data, nil
+}
@@ -0,0 +1,307 @@
+// Package breaker tracks the health of the remote endpoints, e.g. the SPs the pieces are put to, and stops calling
+// the failing or slow ones for a while so their callers fail fast instead of waiting on them. A Breaker is closed
+// while the calls succeed; it opens once the failed and the slow calls exceed a ratio of the calls of its window, and
+// every call fails with ErrOpen; after OpenTimeout it is half-open and lets a few probes through, which close it if
+// they succeed or open it again otherwise.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned by the calls rejected by an open Breaker
+var ErrOpen = errors.New("circuit breaker is open")
+
+const (
+	// DefaultWindow is the duration over which the calls are counted
+	DefaultWindow = 30 * time.Second
+	// DefaultMinCalls is the number of calls in the window below which the breaker does not open
+	DefaultMinCalls = 10
+	// DefaultFailureRatio is the ratio of failed or slow calls which opens the breaker
+	DefaultFailureRatio = 0.5
+	// DefaultOpenTimeout is the time the breaker stays open before letting probes through
+	DefaultOpenTimeout = 10 * time.Second
+	// DefaultHalfOpenProbes is the number of successful probes closing a half-open breaker
+	DefaultHalfOpenProbes = 1
+)
+
+// windowBuckets is the number of buckets the window is divided into, the calls of the oldest bucket are forgotten
+// when it slides
+const windowBuckets = 10
+
+// State is the state of a Breaker
+type State int
+
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateOpen rejects every call
+	StateOpen
+	// StateHalfOpen lets the probes through
+	StateHalfOpen
+)
+
+// String return the name of the state
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Config configures the Breakers, the zero values are replaced by the defaults
+type Config struct {
+	// Window is the duration over which the calls are counted
+	Window time.Duration
+	// MinCalls is the number of calls in the window below which the breaker does not open
+	MinCalls int
+	// FailureRatio is the ratio of failed or slow calls in the window which opens the breaker
+	FailureRatio float64
+	// SlowCall is the latency above which a successful call counts as a failure, 0 disables it
+	SlowCall time.Duration
+	// OpenTimeout is the time the breaker stays open before letting probes through
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of probes let through a half-open breaker, which closes once they all succeed
+	HalfOpenProbes int
+	// IsFailure classifies the errors counted as failures, by default every error except context.Canceled, which is
+	// the caller giving up rather than the endpoint failing
+	IsFailure func(err error) bool
+	// OnStateChange, if not nil, is called with the endpoint of a breaker when its state changes, e.g. to log it. It
+	// is called while the breaker is locked so it must not call the breaker.
+	OnStateChange func(endpoint string, from, to State)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Window <= 0 {
+		c.Window = DefaultWindow
+	}
+	if c.MinCalls <= 0 {
+		c.MinCalls = DefaultMinCalls
+	}
+	if c.FailureRatio <= 0 {
+		c.FailureRatio = DefaultFailureRatio
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = DefaultOpenTimeout
+	}
+	if c.HalfOpenProbes <= 0 {
+		c.HalfOpenProbes = DefaultHalfOpenProbes
+	}
+	if c.IsFailure == nil {
+		c.IsFailure = defaultIsFailure
+	}
+	return c
+}
+
+func defaultIsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled)
+}
+
+// Health is a snapshot of the calls of an endpoint in the window of its Breaker
+type Health struct {
+	State     State
+	Calls     int
+	Failures  int
+	SlowCalls int
+	// AvgLatency is the average latency of the calls
+	AvgLatency time.Duration
+}
+
+// FailureRatio return the ratio of failed or slow calls
+func (h Health) FailureRatio() float64 {
+	if h.Calls == 0 {
+		return 0
+	}
+	return float64(h.Failures+h.SlowCalls) / float64(h.Calls)
+}
+
+// bucket counts the calls of a slice of the window
+type bucket struct {
+	start     time.Time
+	calls     int
+	failures  int
+	slowCalls int
+	latency   time.Duration
+}
+
+// Breaker is the circuit breaker of an endpoint. It is safe for concurrent use.
+type Breaker struct {
+	endpoint string
+	config   Config
+	now      func() time.Time
+
+	mu      sync.Mutex
+	state   State
+	buckets [windowBuckets]bucket
+	// openedAt is the time the breaker opened
+	openedAt time.Time
+	// probes is the number of probes let through the half-open breaker and succeeded the successful ones
+	probes    int
+	succeeded int
+	// generation is bumped on every state change so the calls started before are not counted
+	generation uint64
+}
+
+// New return a closed Breaker of the endpoint, which only names it to the OnStateChange hook
+func New(endpoint string, config Config) *Breaker {
+	return &Breaker{endpoint: endpoint, config: config.withDefaults(), now: time.Now}
+}
+
+// Do calls fn if the breaker allows it and records its outcome, or return ErrOpen
+func (b *Breaker) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn(ctx)
+	done(err)
+	return err
+}
+
+// Allow return ErrOpen if the breaker rejects a call, or a func to be called with the error of the call once it
+// ends, which records its outcome and its latency
+func (b *Breaker) Allow() (func(err error), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(StateHalfOpen)
+	}
+	switch b.state {
+	case StateOpen:
+		return nil, fmt.Errorf("%w: %s", ErrOpen, b.endpoint)
+	case StateHalfOpen:
+		if b.probes >= b.config.HalfOpenProbes {
+			return nil, fmt.Errorf("%w: %s", ErrOpen, b.endpoint)
+		}
+		b.probes++
+	}
+	generation := b.generation
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			b.record(generation, now, err)
+		})
+	}, nil
+}
+
+// record records the outcome of a call started at start
+func (b *Breaker) record(generation uint64, start time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	now := b.now()
+	latency := now.Sub(start)
+	failed := b.config.IsFailure(err)
+	if err != nil && !failed {
+		// the call tells nothing about the endpoint, e.g. it was canceled by the caller
+		if b.state == StateHalfOpen {
+			b.probes--
+		}
+		return
+	}
+	slow := err == nil && b.config.SlowCall > 0 && latency > b.config.SlowCall
+
+	if b.state == StateHalfOpen {
+		if failed || slow {
+			b.open(now)
+			return
+		}
+		b.succeeded++
+		if b.succeeded >= b.config.HalfOpenProbes {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	bkt := b.bucket(now)
+	bkt.calls++
+	bkt.latency += latency
+	switch {
+	case failed:
+		bkt.failures++
+	case slow:
+		bkt.slowCalls++
+	}
+	if health := b.health(now); health.Calls >= b.config.MinCalls && health.FailureRatio() >= b.config.FailureRatio {
+		b.open(now)
+	}
+}
+
+// bucket return the bucket of the time, reset if it held the calls of an older slice of the window. The lock must
+// be held.
+func (b *Breaker) bucket(now time.Time) *bucket {
+	width := b.config.Window / windowBuckets
+	start := now.Truncate(width)
+	bkt := &b.buckets[(start.UnixNano()/int64(width))%windowBuckets]
+	if !bkt.start.Equal(start) {
+		*bkt = bucket{start: start}
+	}
+	return bkt
+}
+
+// health return the health of the calls in the window ending at now. The lock must be held.
+func (b *Breaker) health(now time.Time) Health {
+	health := Health{State: b.state}
+	var latency time.Duration
+	for i := range b.buckets {
+		bkt := &b.buckets[i]
+		if bkt.calls == 0 || now.Sub(bkt.start) >= b.config.Window {
+			continue
+		}
+		health.Calls += bkt.calls
+		health.Failures += bkt.failures
+		health.SlowCalls += bkt.slowCalls
+		latency += bkt.latency
+	}
+	if health.Calls > 0 {
+		health.AvgLatency = latency / time.Duration(health.Calls)
+	}
+	return health
+}
+
+// open opens the breaker. The lock must be held.
+func (b *Breaker) open(now time.Time) {
+	b.openedAt = now
+	b.setState(StateOpen)
+}
+
+// setState changes the state and forgets the calls of the previous one. The lock must be held.
+func (b *Breaker) setState(state State) {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.probes = 0
+	b.succeeded = 0
+	b.buckets = [windowBuckets]bucket{}
+	if b.config.OnStateChange != nil && from != state {
+		b.config.OnStateChange(b.endpoint, from, state)
+	}
+}
+
+// State return the state of the breaker
+func (b *Breaker) State() State {
+	return b.Health().State
+}
+
+// Health return the health of the endpoint
+func (b *Breaker) Health() Health {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.OpenTimeout {
+		b.setState(StateHalfOpen)
+	}
+	return b.health(now)
+}
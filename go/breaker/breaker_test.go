@@ -0,0 +1,114 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestBreaker(config Config) (*Breaker, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	b := New("sp1", config)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	var changes []string
+	b, clock := newTestBreaker(Config{MinCalls: 4, FailureRatio: 0.5, OpenTimeout: time.Minute, HalfOpenProbes: 2,
+		OnStateChange: func(endpoint string, from, to State) {
+			changes = append(changes, fmt.Sprintf("%s %s->%s", endpoint, from, to))
+		}})
+	fail := func(context.Context) error { return errFailed }
+	succeed := func(context.Context) error { return nil }
+
+	assert.NoError(t, b.Do(ctx, succeed))
+	assert.NoError(t, b.Do(ctx, succeed))
+	assert.ErrorIs(t, b.Do(ctx, fail), errFailed)
+	assert.Equal(t, StateClosed, b.State())
+	assert.ErrorIs(t, b.Do(ctx, fail), errFailed)
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Do(ctx, succeed), ErrOpen)
+
+	clock.now = clock.now.Add(time.Minute)
+	assert.Equal(t, StateHalfOpen, b.State())
+	done1, err := b.Allow()
+	require.NoError(t, err)
+	done2, err := b.Allow()
+	require.NoError(t, err)
+	_, err = b.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+	done1(nil)
+	done2(errFailed)
+	assert.Equal(t, StateOpen, b.State())
+
+	clock.now = clock.now.Add(time.Minute)
+	done1, err = b.Allow()
+	require.NoError(t, err)
+	done1(context.Canceled)
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, b.Do(ctx, succeed))
+	assert.NoError(t, b.Do(ctx, succeed))
+	assert.Equal(t, StateClosed, b.State())
+	assert.Equal(t, Health{State: StateClosed}, b.Health())
+
+	assert.Equal(t, []string{"sp1 closed->open", "sp1 open->half-open", "sp1 half-open->open",
+		"sp1 open->half-open", "sp1 half-open->closed"}, changes)
+}
+
+func TestBreakerWindow(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	b, clock := newTestBreaker(Config{Window: 10 * time.Second, MinCalls: 3, SlowCall: time.Second})
+
+	assert.Error(t, b.Do(ctx, func(context.Context) error { return errFailed }))
+	assert.Error(t, b.Do(ctx, func(context.Context) error { return errFailed }))
+	clock.now = clock.now.Add(10 * time.Second)
+	assert.NoError(t, b.Do(ctx, func(context.Context) error { return nil }))
+	health := b.Health()
+	assert.Equal(t, StateClosed, health.State)
+	assert.Equal(t, 1, health.Calls)
+
+	assert.NoError(t, b.Do(ctx, func(context.Context) error {
+		clock.now = clock.now.Add(2 * time.Second)
+		return nil
+	}))
+	health = b.Health()
+	assert.Equal(t, 2, health.Calls)
+	assert.Equal(t, 1, health.SlowCalls)
+	assert.Equal(t, time.Second, health.AvgLatency)
+	assert.Equal(t, 0.5, health.FailureRatio())
+	assert.Equal(t, StateClosed, health.State)
+	assert.Error(t, b.Do(ctx, func(context.Context) error { return errFailed }))
+	assert.Equal(t, StateOpen, b.State())
+}
+
+func TestGroup(t *testing.T) {
+	ctx := context.Background()
+	g := NewGroup(Config{MinCalls: 1})
+	assert.Same(t, g.Get("sp1"), g.Get("sp1"))
+	assert.Error(t, g.Do(ctx, "sp1", func(context.Context) error { return errors.New("failed") }))
+	assert.NoError(t, g.Do(ctx, "sp2", func(context.Context) error { return nil }))
+	assert.ErrorIs(t, g.Do(ctx, "sp1", func(context.Context) error { return nil }), ErrOpen)
+
+	health := g.Health()
+	assert.Len(t, health, 2)
+	assert.Equal(t, StateOpen, health["sp1"].State)
+	assert.Equal(t, StateClosed, health["sp2"].State)
+	assert.Equal(t, 1, health["sp2"].Calls)
+}
@@ -0,0 +1,56 @@
+package breaker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group holds the Breakers of a set of endpoints sharing a Config, created on their first call. It is safe for
+// concurrent use.
+type Group struct {
+	config Config
+	now    func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*Breaker
+}
+
+// NewGroup return a Group of Breakers configured by config
+func NewGroup(config Config) *Group {
+	return &Group{config: config, now: time.Now, breakers: make(map[string]*Breaker)}
+}
+
+// Get return the Breaker of the endpoint
+func (g *Group) Get(endpoint string) *Breaker {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	b, ok := g.breakers[endpoint]
+	if !ok {
+		b = New(endpoint, g.config)
+		b.now = g.now
+		g.breakers[endpoint] = b
+	}
+	return b
+}
+
+// Do calls fn through the Breaker of the endpoint, see Breaker.Do
+func (g *Group) Do(ctx context.Context, endpoint string, fn func(ctx context.Context) error) error {
+	return g.Get(endpoint).Do(ctx, fn)
+}
+
+// Health return the health of the endpoints called so far
+func (g *Group) Health() map[string]Health {
+	g.mu.Lock()
+	breakers := make(map[string]*Breaker, len(g.breakers))
+	for endpoint, b := range g.breakers {
+		breakers[endpoint] = b
+	}
+	g.mu.Unlock()
+
+	health := make(map[string]Health, len(breakers))
+	for endpoint, b := range breakers {
+		health[endpoint] = b.Health()
+	}
+	return health
+}
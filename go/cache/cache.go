@@ -0,0 +1,295 @@
+// Package cache is a size-bounded TTL cache of the values loaded by a func, e.g. the storage params of the chain or
+// the verified approvals. The concurrent loads of a key are coalesced into one, and a value older than its TTL can
+// still be served for a stale window while it is reloaded in the background:
+//
+//	c := cache.New(func(ctx context.Context, bucket string) (*Bucket, error) {
+//		return client.HeadBucket(ctx, bucket)
+//	}, cache.WithTTL(time.Minute), cache.WithStale(time.Minute), cache.WithMaxEntries(10000))
+//	b, err := c.Get(ctx, "photos")
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is the time a loaded value is fresh if the cache has no TTL
+const DefaultTTL = time.Minute
+
+type options struct {
+	ttl         time.Duration
+	stale       time.Duration
+	errorTTL    time.Duration
+	maxEntries  int
+	loadTimeout time.Duration
+	now         func() time.Time
+}
+
+// Option configures a Cache
+type Option func(*options)
+
+// WithTTL serves a loaded value without reloading it for ttl, DefaultTTL by default
+func WithTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.ttl = ttl
+	}
+}
+
+// WithStale serves a value for stale after its TTL while it is reloaded in the background, so the callers do not wait
+// for the reload. A value is not served stale by default. A failed reload keeps the stale value.
+func WithStale(stale time.Duration) Option {
+	return func(o *options) {
+		o.stale = stale
+	}
+}
+
+// WithErrorTTL caches the load errors for ttl, e.g. a missing bucket, the errors are not cached by default
+func WithErrorTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.errorTTL = ttl
+	}
+}
+
+// WithMaxEntries evicts the least recently used keys beyond n, the cache is unbounded by default
+func WithMaxEntries(n int) Option {
+	return func(o *options) {
+		o.maxEntries = n
+	}
+}
+
+// WithLoadTimeout bounds the loads, which are otherwise only bounded by the load func. The loads run with the
+// values but without the cancellation of the context of the caller starting them, since other callers wait for them.
+func WithLoadTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.loadTimeout = d
+	}
+}
+
+// WithClock reads the time from now instead of time.Now, e.g. in tests
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// Stats are the counters of a Cache
+type Stats struct {
+	// Hits are the gets served by a fresh value or error
+	Hits uint64
+	// StaleHits are the gets served by a stale value
+	StaleHits uint64
+	// Misses are the gets waiting for a load
+	Misses uint64
+	// Loads are the calls to the load func, LoadErrors the ones which failed
+	Loads      uint64
+	LoadErrors uint64
+	// Evictions are the keys evicted to bound the size of the cache
+	Evictions uint64
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	value    V
+	err      error
+	loadedAt time.Time
+}
+
+// call is a load in flight
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+	// invalidated is set if the key is deleted or set during the load, its result is then not cached
+	invalidated bool
+}
+
+// Cache is a TTL cache of the values of the keys loaded by a func, it is safe for concurrent use
+type Cache[K comparable, V any] struct {
+	load func(ctx context.Context, key K) (V, error)
+	options
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	lru     *list.List
+	calls   map[K]*call[V]
+	stats   Stats
+}
+
+// New return an empty Cache of the values loaded by load
+func New[K comparable, V any](load func(ctx context.Context, key K) (V, error), opts ...Option) *Cache[K, V] {
+	o := options{ttl: DefaultTTL, now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &Cache[K, V]{
+		load:    load,
+		options: o,
+		entries: make(map[K]*list.Element),
+		lru:     list.New(),
+		calls:   make(map[K]*call[V]),
+	}
+}
+
+// Get return the value of the key: the cached value if it is fresh, the stale value while it is reloaded in the
+// background, or the value loaded otherwise, the concurrent loads of the key being coalesced. The caller stops
+// waiting for the load when ctx is done.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		value, err, age := e.value, e.err, c.now().Sub(e.loadedAt)
+		switch {
+		case err == nil && age < c.ttl, err != nil && age < c.errorTTL:
+			c.lru.MoveToFront(elem)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return value, err
+		case err == nil && age < c.ttl+c.stale:
+			c.lru.MoveToFront(elem)
+			c.stats.StaleHits++
+			c.startLoad(ctx, key)
+			c.mu.Unlock()
+			return value, nil
+		}
+	}
+	c.stats.Misses++
+	cl := c.startLoad(ctx, key)
+	c.mu.Unlock()
+	return wait(ctx, cl)
+}
+
+// Reload loads the value of the key regardless of the cache, joining the load in flight if any
+func (c *Cache[K, V]) Reload(ctx context.Context, key K) (V, error) {
+	c.mu.Lock()
+	cl := c.startLoad(ctx, key)
+	c.mu.Unlock()
+	return wait(ctx, cl)
+}
+
+func wait[V any](ctx context.Context, cl *call[V]) (V, error) {
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// startLoad return the load in flight of the key or starts one, the lock must be held
+func (c *Cache[K, V]) startLoad(ctx context.Context, key K) *call[V] {
+	if cl, ok := c.calls[key]; ok {
+		return cl
+	}
+	cl := &call[V]{done: make(chan struct{})}
+	c.calls[key] = cl
+	c.stats.Loads++
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if c.loadTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.loadTimeout)
+			defer cancel()
+		}
+		defer close(cl.done)
+		defer func() {
+			if r := recover(); r != nil {
+				cl.err = fmt.Errorf("cache load panic: %v", r)
+				c.finish(key, cl)
+			}
+		}()
+		cl.value, cl.err = c.load(ctx, key)
+		c.finish(key, cl)
+	}()
+	return cl
+}
+
+// finish caches the result of the load of the key
+func (c *Cache[K, V]) finish(key K, cl *call[V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.calls, key)
+	if cl.err != nil {
+		c.stats.LoadErrors++
+	}
+	switch {
+	case cl.invalidated:
+	case cl.err == nil:
+		c.set(key, cl.value, nil)
+	case c.errorTTL > 0:
+		if elem, ok := c.entries[key]; ok && elem.Value.(*entry[K, V]).err == nil {
+			// keep the stale value rather than the error
+			return
+		}
+		c.set(key, cl.value, cl.err)
+	}
+}
+
+// set caches the value or the error of the key and evicts the least recently used keys, the lock must be held
+func (c *Cache[K, V]) set(key K, value V, err error) {
+	now := c.now()
+	if elem, ok := c.entries[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.err, e.loadedAt = value, err, now
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&entry[K, V]{key: key, value: value, err: err, loadedAt: now})
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry[K, V]).key)
+		c.stats.Evictions++
+	}
+}
+
+// Set caches the value of the key as freshly loaded, the load in flight of the key is not cached
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cl, ok := c.calls[key]; ok {
+		cl.invalidated = true
+	}
+	c.set(key, value, nil)
+}
+
+// Delete removes the key from the cache, the load in flight of the key is not cached
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cl, ok := c.calls[key]; ok {
+		cl.invalidated = true
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+	}
+}
+
+// Purge removes all the keys from the cache
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cl := range c.calls {
+		cl.invalidated = true
+	}
+	c.entries = make(map[K]*list.Element)
+	c.lru.Init()
+}
+
+// Len return the number of cached keys, including the stale ones
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats return the counters of the cache
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Unix(1700000000, 0)}
+}
+
+func TestCacheCoalescing(t *testing.T) {
+	ctx := context.Background()
+	var loads atomic.Int32
+	release := make(chan struct{})
+	c := New(func(_ context.Context, key string) (int, error) {
+		loads.Add(1)
+		<-release
+		return len(key), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.Get(ctx, "photos")
+			assert.NoError(t, err)
+			assert.Equal(t, 6, value)
+		}()
+	}
+	assert.Eventually(t, func() bool { return c.Stats().Misses == 8 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	value, err := c.Get(ctx, "photos")
+	require.NoError(t, err)
+	assert.Equal(t, 6, value)
+	assert.Equal(t, int32(1), loads.Load())
+	assert.Equal(t, Stats{Hits: 1, Misses: 8, Loads: 1}, c.Stats())
+}
+
+func TestCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	clock := newClock()
+	errMissing := errors.New("bucket not found")
+	var loads atomic.Int32
+	c := New(func(_ context.Context, key string) (string, error) {
+		n := loads.Add(1)
+		if key == "missing" {
+			return "", errMissing
+		}
+		return key + string(rune('0'+n)), nil
+	}, WithTTL(time.Minute), WithErrorTTL(10*time.Second), WithClock(clock.Now))
+
+	value, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a1", value)
+	clock.Add(59 * time.Second)
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, "a1", value)
+	clock.Add(time.Second)
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, "a2", value)
+
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, errMissing)
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, errMissing)
+	assert.Equal(t, int32(3), loads.Load())
+	clock.Add(10 * time.Second)
+	_, err = c.Get(ctx, "missing")
+	assert.ErrorIs(t, err, errMissing)
+	assert.Equal(t, int32(4), loads.Load())
+
+	value, err = c.Reload(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a5", value)
+	c.Set("a", "set")
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, "set", value)
+	c.Delete("a")
+	value, _ = c.Get(ctx, "a")
+	assert.Equal(t, "a6", value)
+	assert.Equal(t, 2, c.Len())
+	c.Purge()
+	assert.Zero(t, c.Len())
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	clock := newClock()
+	loadErr := error(nil)
+	var mu sync.Mutex
+	loaded := make(chan struct{}, 1)
+	n := 0
+	c := New(func(context.Context, string) (int, error) {
+		defer func() { loaded <- struct{}{} }()
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return n, loadErr
+	}, WithTTL(time.Minute), WithStale(time.Minute), WithClock(clock.Now))
+
+	value, err := c.Get(ctx, "params")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+	<-loaded
+
+	// the stale value is served while it is reloaded
+	clock.Add(90 * time.Second)
+	value, err = c.Get(ctx, "params")
+	require.NoError(t, err)
+	assert.Equal(t, 1, value)
+	<-loaded
+	assert.Eventually(t, func() bool {
+		value, _ := c.Get(ctx, "params")
+		return value == 2
+	}, time.Second, time.Millisecond)
+
+	// a failed reload keeps the stale value
+	mu.Lock()
+	loadErr = errors.New("node unavailable")
+	mu.Unlock()
+	clock.Add(90 * time.Second)
+	value, err = c.Get(ctx, "params")
+	require.NoError(t, err)
+	assert.Equal(t, 2, value)
+	<-loaded
+	assert.Eventually(t, func() bool { return c.Stats().LoadErrors == 1 }, time.Second, time.Millisecond)
+
+	// past the stale window the callers wait for the load
+	clock.Add(time.Minute)
+	_, err = c.Get(ctx, "params")
+	assert.EqualError(t, err, "node unavailable")
+	<-loaded
+}
+
+func TestCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := New(func(_ context.Context, key int) (int, error) {
+		return key * key, nil
+	}, WithMaxEntries(2))
+	for _, key := range []int{1, 2, 1, 3} {
+		_, err := c.Get(ctx, key)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, uint64(1), c.Stats().Evictions)
+	_, err := c.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), c.Stats().Hits, "1 must be kept as the most recently used key")
+}
+
+func TestCacheCanceled(t *testing.T) {
+	release := make(chan struct{})
+	c := New(func(ctx context.Context, _ string) (string, error) {
+		<-release
+		return "loaded", ctx.Err()
+	}, WithLoadTimeout(time.Minute))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Get(ctx, "key")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the load is not canceled with its first caller
+	close(release)
+	value, err := c.Get(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, "loaded", value)
+
+	panicking := New(func(context.Context, string) (string, error) {
+		panic("boom")
+	})
+	_, err = panicking.Get(context.Background(), "key")
+	assert.EqualError(t, err, "cache load panic: boom")
+}
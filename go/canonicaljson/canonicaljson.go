@@ -0,0 +1,201 @@
+// Package canonicaljson encodes the JSON signed by the users and the SPs in a canonical form, so every party signs
+// and verifies the same bytes: the object keys are sorted by their UTF-8 bytes like the sorted JSON of the chain,
+// there is no whitespace, the numbers have a single formatting and the strings are escaped minimally, without the
+// HTML escaping of encoding/json.
+package canonicaljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidJSON is returned when the input is not a single valid JSON value
+	ErrInvalidJSON = errors.New("invalid json")
+	// ErrDuplicateKey is returned when an object has the same key twice, its canonical form would be ambiguous
+	ErrDuplicateKey = errors.New("duplicate json object key")
+)
+
+// Marshal return the canonical JSON encoding of v, v is first encoded by encoding/json so its json tags and
+// Marshaler implementations apply
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return Canonicalize(buf.Bytes())
+}
+
+// Canonicalize return the canonical form of the JSON data
+func Canonicalize(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var buf bytes.Buffer
+	if err := canonicalizeValue(decoder, &buf); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: data after the value", ErrInvalidJSON)
+	}
+	return buf.Bytes(), nil
+}
+
+// Valid reports whether the data is already in canonical form
+func Valid(data []byte) bool {
+	canonical, err := Canonicalize(data)
+	return err == nil && bytes.Equal(canonical, data)
+}
+
+// canonicalizeValue writes the canonical form of the next value of the decoder
+func canonicalizeValue(decoder *json.Decoder, buf *bytes.Buffer) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			return canonicalizeArray(decoder, buf)
+		}
+		return canonicalizeObject(decoder, buf)
+	case string:
+		writeString(buf, t)
+	case json.Number:
+		number, err := formatNumber(t)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(number)
+	case bool:
+		buf.WriteString(strconv.FormatBool(t))
+	case nil:
+		buf.WriteString("null")
+	}
+	return nil
+}
+
+func canonicalizeArray(decoder *json.Decoder, buf *bytes.Buffer) error {
+	buf.WriteByte('[')
+	for first := true; decoder.More(); first = false {
+		if !first {
+			buf.WriteByte(',')
+		}
+		if err := canonicalizeValue(decoder, buf); err != nil {
+			return err
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func canonicalizeObject(decoder *json.Decoder, buf *bytes.Buffer) error {
+	members := make(map[string][]byte)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("%w: object key %v", ErrInvalidJSON, token)
+		}
+		if _, ok = members[key]; ok {
+			return fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+		}
+		var value bytes.Buffer
+		if err = canonicalizeValue(decoder, &value); err != nil {
+			return err
+		}
+		members[key] = value.Bytes()
+	}
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+	}
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeString(buf, key)
+		buf.WriteByte(':')
+		buf.Write(members[key])
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// formatNumber return the canonical form of the number: the integers are written in decimal without exponent, so
+// integers larger than 2^53 keep their value, the other numbers like the ECMAScript number serialization
+func formatNumber(number json.Number) (string, error) {
+	text := number.String()
+	if !strings.ContainsAny(text, ".eE") {
+		if text == "-0" {
+			return "0", nil
+		}
+		return text, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return "", fmt.Errorf("%w: number %s", ErrInvalidJSON, text)
+	}
+	if f == 0 {
+		return "0", nil
+	}
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+	// strip the leading zeros of the exponent, e.g. 1e-07 is written 1e-7
+	mantissa, exponent, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+	return mantissa + "e" + sign + digits, nil
+}
+
+// writeString writes the string escaping only the quotation mark, the reverse solidus and the control characters
+func writeString(buf *bytes.Buffer, s string) {
+	const hexDigits = "0123456789abcdef"
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[r>>4])
+				buf.WriteByte(hexDigits[r&0xf])
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
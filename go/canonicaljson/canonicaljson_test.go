@@ -0,0 +1,61 @@
+package canonicaljson
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalize(t *testing.T) {
+	for _, tc := range []struct {
+		input, canonical string
+	}{
+		{` { "b" : 1, "a" : [true, false, null], "c": {"z": "", "y": {}} } `,
+			`{"a":[true,false,null],"b":1,"c":{"y":{},"z":""}}`},
+		{`{"B": 1, "a": 2, "é": 3, "_": 4}`, `{"B":1,"_":4,"a":2,"é":3}`},
+		{`["<a&b>", "\u2028", "\u00e9", "\/", "\"\\", "\u0001\n\t"]`,
+			`["<a&b>","` + "\u2028" + `","é","/","\"\\","\u0001\n\t"]`},
+		{`[0, -0, 1.0, 1.50, -0.0, 1e3, 1E-7, 0.000001, 1e21, 123456789012345678901234567890, 2.5e-10]`,
+			`[0,0,1,1.5,0,1000,1e-7,0.000001,1e+21,123456789012345678901234567890,2.5e-10]`},
+		{`[]`, `[]`},
+		{`"text"`, `"text"`},
+	} {
+		canonical, err := Canonicalize([]byte(tc.input))
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.canonical, string(canonical), tc.input)
+		assert.True(t, Valid(canonical), tc.input)
+	}
+	assert.False(t, Valid([]byte(`{"b":1,"a":2}`)))
+	assert.False(t, Valid([]byte(`{"a": 2}`)))
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	for _, input := range []string{``, `{`, `{"a":1,}`, `[1] [2]`, `{"a":1}x`, `1e999`, `nul`} {
+		_, err := Canonicalize([]byte(input))
+		assert.ErrorIs(t, err, ErrInvalidJSON, input)
+	}
+	_, err := Canonicalize([]byte(`{"a": 1, "b": {"c": 1, "c": 2}}`))
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+}
+
+func TestMarshal(t *testing.T) {
+	type doc struct {
+		Zeta   string   `json:"zeta"`
+		Alpha  *big.Int `json:"alpha"`
+		Bytes  []byte   `json:"bytes"`
+		Hidden string   `json:"-"`
+		Nested map[string]interface{}
+	}
+	value, ok := new(big.Int).SetString("340282366920938463463374607431768211455", 10)
+	require.True(t, ok)
+	data, err := Marshal(doc{Zeta: "a<b", Alpha: value, Bytes: []byte{0xfb, 0xff}, Hidden: "x",
+		Nested: map[string]interface{}{"y": 0.1, "x": uint64(1 << 63)}})
+	require.NoError(t, err)
+	assert.Equal(t, `{"Nested":{"x":9223372036854775808,"y":0.1},"alpha":340282366920938463463374607431768211455,`+
+		`"bytes":"+/8=","zeta":"a<b"}`, string(data))
+
+	_, err = Marshal(func() {})
+	assert.Error(t, err)
+}
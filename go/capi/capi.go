@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// checksumSize is the size of an integrity hash
+const checksumSize = 32
+
+// computeIntegrityHash return the integrity hashes of the data concatenated, the PrimarySP first
+func computeIntegrityHash(data []byte, segmentSize int64, dataShards, parityShards int) ([]byte, error) {
+	result, err := hash.ComputeIntegrityHashWithOptions(bytes.NewReader(data), segmentSize, dataShards,
+		parityShards)
+	if err != nil {
+		return nil, err
+	}
+	sums := make([]byte, 0, len(result.Checksums)*checksumSize)
+	for _, checksum := range result.Checksums {
+		sums = append(sums, checksum...)
+	}
+	return sums, nil
+}
+
+// shardSize return the size of the shards of a segment, -1 if dataShards is not positive
+func shardSize(segmentSize int64, dataShards int) int64 {
+	if dataShards <= 0 || segmentSize < 0 {
+		return -1
+	}
+	return (segmentSize + int64(dataShards) - 1) / int64(dataShards)
+}
+
+// encode erasure encodes the segment into the contiguous shards
+func encode(segment, shards []byte, dataShards, parityShards int) error {
+	size := int(shardSize(int64(len(segment)), dataShards))
+	return redundancy.EncodeRawSegmentInto(segment, split(shards, size, dataShards+parityShards), dataShards,
+		parityShards)
+}
+
+// decode decodes the segment from the contiguous shards, present flags the available ones
+func decode(shards, present, segment []byte, dataShards, parityShards int) error {
+	size := int(shardSize(int64(len(segment)), dataShards))
+	pieces := split(shards, size, dataShards+parityShards)
+	for i := range pieces {
+		if present[i] == 0 {
+			pieces[i] = nil
+		}
+	}
+	decoded, err := redundancy.DecodeRawSegment(pieces, int64(len(segment)), dataShards, parityShards)
+	if err != nil {
+		return err
+	}
+	if len(decoded) != len(segment) {
+		return fmt.Errorf("decoded %d bytes instead of %d", len(decoded), len(segment))
+	}
+	copy(segment, decoded)
+	return nil
+}
+
+// split return the n shards of size bytes of buf, capped so they can not grow into the next one
+func split(buf []byte, size, n int) [][]byte {
+	shards := make([][]byte, n)
+	for i := range shards {
+		shards[i] = buf[i*size : (i+1)*size : (i+1)*size]
+	}
+	return shards
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestComputeIntegrityHash(t *testing.T) {
+	data := make([]byte, 3000)
+	_, _ = rand.Read(data)
+	sums, err := computeIntegrityHash(data, 1024, redundancy.DataBlocks, redundancy.ParityBlocks)
+	require.NoError(t, err)
+
+	expected, _, _, err := hash.ComputeIntegrityHash(bytes.NewReader(data), 1024, redundancy.DataBlocks,
+		redundancy.ParityBlocks, false)
+	require.NoError(t, err)
+	require.Len(t, sums, len(expected)*checksumSize)
+	for i, checksum := range expected {
+		assert.Equal(t, checksum, sums[i*checksumSize:(i+1)*checksumSize])
+	}
+
+	_, err = computeIntegrityHash(data, 1024, 0, redundancy.ParityBlocks)
+	assert.Error(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	dataShards, parityShards := redundancy.DataBlocks, redundancy.ParityBlocks
+	segment := make([]byte, 1001)
+	_, _ = rand.Read(segment)
+	size := shardSize(int64(len(segment)), dataShards)
+	assert.Equal(t, int64(251), size)
+	assert.Equal(t, int64(-1), shardSize(1001, 0))
+
+	shards := make([]byte, size*int64(dataShards+parityShards))
+	require.NoError(t, encode(segment, shards, dataShards, parityShards))
+	expected, err := redundancy.EncodeRawSegment(segment, dataShards, parityShards)
+	require.NoError(t, err)
+	assert.Equal(t, bytes.Join(expected, nil), shards)
+
+	// any data shards are enough
+	present := []byte{0, 1, 0, 1, 1, 1}
+	decoded := make([]byte, len(segment))
+	require.NoError(t, decode(shards, present, decoded, dataShards, parityShards))
+	assert.Equal(t, segment, decoded)
+
+	present = []byte{0, 1, 0, 1, 0, 1}
+	assert.ErrorIs(t, decode(shards, present, decoded, dataShards, parityShards), redundancy.ErrTooFewShards)
+
+	// an empty segment has empty shards
+	require.NoError(t, encode(nil, nil, dataShards, parityShards))
+	require.NoError(t, decode(nil, make([]byte, 6), nil, dataShards, parityShards))
+}
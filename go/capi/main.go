@@ -0,0 +1,132 @@
+// Command capi exports the integrity hashes and the erasure encoding of this module through a C ABI, so the SDKs in
+// the other languages link the exact implementation of the SPs instead of porting it. Build the library and its
+// header with
+//
+//	go build -buildmode=c-shared -o libmechain.so ./capi
+//
+// or make cshared. The buffers are allocated by the caller and the functions return NULL on success or an error
+// message which must be freed with free_error.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+
+// compute_integrity_hash computes the integrity hashes of the len bytes of data split in segments of segment_size
+// bytes, each erasure encoded into data_shards and parity_shards. checksums receives the 32 bytes of the integrity
+// hash of the PrimarySP followed by the ones of the SecondarySPs, (1 + data_shards + parity_shards) * 32 bytes.
+// It returns NULL on success or an error message to free with free_error.
+//
+// char *compute_integrity_hash(uint8_t *data, int64_t len, int64_t segment_size, int data_shards,
+//     int parity_shards, uint8_t *checksums);
+
+// ec_shard_size returns the size of the shards of a segment of segment_size bytes, or -1 if data_shards is not
+// positive.
+//
+// int64_t ec_shard_size(int64_t segment_size, int data_shards);
+
+// ec_encode erasure encodes the len bytes of segment into shards, (data_shards + parity_shards) shards of
+// ec_shard_size(len, data_shards) bytes each, the shard i starting at i * ec_shard_size(len, data_shards).
+// It returns NULL on success or an error message to free with free_error.
+//
+// char *ec_encode(uint8_t *segment, int64_t len, int data_shards, int parity_shards, uint8_t *shards);
+
+// ec_decode decodes the segment of segment_size bytes from the shards laid out like ec_encode: present[i] is not 0
+// when the shard i is available, at least data_shards shards must be. The missing shards are not written.
+// It returns NULL on success or an error message to free with free_error.
+//
+// char *ec_decode(uint8_t *shards, uint8_t *present, int64_t segment_size, int data_shards,
+//     int parity_shards, uint8_t *segment);
+
+// free_error frees an error message returned by the other functions.
+//
+// void free_error(char *err);
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+func main() {}
+
+//export compute_integrity_hash
+func compute_integrity_hash(data *C.uint8_t, length C.int64_t, segmentSize C.int64_t, dataShards,
+	parityShards C.int, checksums *C.uint8_t,
+) (errMsg *C.char) {
+	defer recoverError(&errMsg)
+	if checksums == nil {
+		return errorOf(errors.New("checksums is NULL"))
+	}
+	sums, err := computeIntegrityHash(bytesOf(data, int64(length)), int64(segmentSize), int(dataShards),
+		int(parityShards))
+	if err != nil {
+		return errorOf(err)
+	}
+	copy(bytesOf(checksums, int64(len(sums))), sums)
+	return nil
+}
+
+//export ec_shard_size
+func ec_shard_size(segmentSize C.int64_t, dataShards C.int) C.int64_t {
+	return C.int64_t(shardSize(int64(segmentSize), int(dataShards)))
+}
+
+//export ec_encode
+func ec_encode(segment *C.uint8_t, length C.int64_t, dataShards, parityShards C.int,
+	shards *C.uint8_t,
+) (errMsg *C.char) {
+	defer recoverError(&errMsg)
+	size := shardSize(int64(length), int(dataShards))
+	if size < 0 || parityShards < 0 {
+		return errorOf(fmt.Errorf("invalid shards: %d data shards and %d parity shards", dataShards, parityShards))
+	}
+	err := encode(bytesOf(segment, int64(length)), bytesOf(shards, size*int64(dataShards+parityShards)),
+		int(dataShards), int(parityShards))
+	return errorOf(err)
+}
+
+//export ec_decode
+func ec_decode(shards *C.uint8_t, present *C.uint8_t, segmentSize C.int64_t, dataShards, parityShards C.int,
+	segment *C.uint8_t,
+) (errMsg *C.char) {
+	defer recoverError(&errMsg)
+	size := shardSize(int64(segmentSize), int(dataShards))
+	if size < 0 || parityShards < 0 {
+		return errorOf(fmt.Errorf("invalid shards: %d data shards and %d parity shards", dataShards, parityShards))
+	}
+	total := int64(dataShards + parityShards)
+	err := decode(bytesOf(shards, size*total), bytesOf(present, total), bytesOf(segment, int64(segmentSize)),
+		int(dataShards), int(parityShards))
+	return errorOf(err)
+}
+
+//export free_error
+func free_error(errMsg *C.char) {
+	C.free(unsafe.Pointer(errMsg))
+}
+
+// bytesOf return the slice of the n bytes of C memory at p, it must not be retained after the call
+func bytesOf(p *C.uint8_t, n int64) []byte {
+	if p == nil || n <= 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(p)), n)
+}
+
+// errorOf return the C string of the error message, NULL if err is nil
+func errorOf(err error) *C.char {
+	if err == nil {
+		return nil
+	}
+	return C.CString(err.Error())
+}
+
+// recoverError turns a panic into the error message of the call, a panic must not cross the C boundary
+func recoverError(errMsg **C.char) {
+	if v := recover(); v != nil {
+		*errMsg = errorOf(fmt.Errorf("panic: %v", v))
+	}
+}
@@ -0,0 +1,101 @@
+// Package challenge builds and verifies the responses of the SPs to the storage challenges of a piece of an object
+package challenge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// ErrInvalidChallenge is returned when the challenged segment or ec index is out of the object
+var ErrInvalidChallenge = errors.New("invalid challenge")
+
+// Challenge identifies the challenged piece, a segment piece of the PrimarySP if ECIndex is piece.NoECIndex and an
+// erasure encoded piece of a SecondarySP otherwise
+type Challenge struct {
+	ObjectID     uint64
+	SegmentIndex uint32
+	ECIndex      int32
+}
+
+// Key return the key of the challenged piece
+func (c Challenge) Key() piece.Key {
+	return piece.Key{ObjectID: c.ObjectID, SegmentIndex: c.SegmentIndex, ECIndex: c.ECIndex}
+}
+
+// Response is the proof that a SP stores the challenged piece: the checksum of the piece data and the checksums of
+// the other segments, which together recompute the integrity hash of the challenged SP
+type Response struct {
+	// PieceHash is the checksum of the challenged piece data
+	PieceHash []byte `json:"piece_hash"`
+	// SiblingChecksums are the checksums of the piece list without the challenged one, ordered by segment index
+	SiblingChecksums [][]byte `json:"sibling_checksums"`
+}
+
+// NewResponse return the response to the challenge of the piece data, checksums are the piece checksums of the
+// challenged SP ordered by segment index, i.e. the segment checksums for the PrimarySP or the checksums of the
+// ec pieces of its ec index for a SecondarySP.
+// It fails with hash.ErrPieceChecksumMismatch if the stored piece data does not match its checksum.
+func NewResponse(challenge Challenge, checksums [][]byte, pieceData []byte) (*Response, error) {
+	if err := checkChallenge(challenge, len(checksums)); err != nil {
+		return nil, err
+	}
+	pieceHash := hash.GenerateChecksum(pieceData)
+	if !bytes.Equal(checksums[challenge.SegmentIndex], pieceHash) {
+		return nil, fmt.Errorf("%w: segment %d", hash.ErrPieceChecksumMismatch, challenge.SegmentIndex)
+	}
+	siblings := make([][]byte, 0, len(checksums)-1)
+	siblings = append(siblings, checksums[:challenge.SegmentIndex]...)
+	siblings = append(siblings, checksums[challenge.SegmentIndex+1:]...)
+	return &Response{PieceHash: pieceHash, SiblingChecksums: siblings}, nil
+}
+
+// VerifyResponse verify the response recomputes integrityHash, the integrity hash of the challenged SP stored on
+// chain, see IntegrityHash
+func VerifyResponse(integrityHash []byte, challenge Challenge, response *Response) error {
+	if err := checkChallenge(challenge, len(response.SiblingChecksums)+1); err != nil {
+		return err
+	}
+	return hash.VerifyIntegrityHash(integrityHash, response.checksums(challenge.SegmentIndex))
+}
+
+// VerifyPieceData verify the piece data returned along the response matches the PieceHash of the response
+func (r *Response) VerifyPieceData(pieceData []byte) error {
+	if !bytes.Equal(r.PieceHash, hash.GenerateChecksum(pieceData)) {
+		return hash.ErrPieceChecksumMismatch
+	}
+	return nil
+}
+
+// checksums return the piece checksum list with the PieceHash inserted at the challenged segment index
+func (r *Response) checksums(segmentIndex uint32) [][]byte {
+	checksums := make([][]byte, 0, len(r.SiblingChecksums)+1)
+	checksums = append(checksums, r.SiblingChecksums[:segmentIndex]...)
+	checksums = append(checksums, r.PieceHash)
+	return append(checksums, r.SiblingChecksums[segmentIndex:]...)
+}
+
+// IntegrityHash return the integrity hash of the SP challenged by the challenge from the hashes of the object
+func IntegrityHash(result *hash.HashResult, challenge Challenge) ([]byte, error) {
+	index := int(challenge.ECIndex) + 1
+	if challenge.ECIndex < piece.NoECIndex || index >= len(result.Checksums) {
+		return nil, fmt.Errorf("%w: ec index %d of %d ec pieces", ErrInvalidChallenge, challenge.ECIndex,
+			len(result.SecondaryChecksums()))
+	}
+	return result.Checksums[index], nil
+}
+
+// checkChallenge return ErrInvalidChallenge if the challenged segment is out of the segmentCount segments
+func checkChallenge(challenge Challenge, segmentCount int) error {
+	if challenge.ECIndex < piece.NoECIndex {
+		return fmt.Errorf("%w: ec index %d", ErrInvalidChallenge, challenge.ECIndex)
+	}
+	if int(challenge.SegmentIndex) >= segmentCount {
+		return fmt.Errorf("%w: segment %d of %d segments", ErrInvalidChallenge, challenge.SegmentIndex,
+			segmentCount)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package challenge
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+const (
+	segmentSize  = 16 * 1024
+	dataShards   = 4
+	parityShards = 2
+)
+
+// object return the content of an object and the segments and ec pieces stored by the SPs
+func object(t *testing.T, size int) ([]byte, [][]byte, [][][]byte) {
+	content := make([]byte, size)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+	var segments [][]byte
+	var pieces [][][]byte
+	for offset := 0; offset < size; offset += segmentSize {
+		end := min(offset+segmentSize, size)
+		segments = append(segments, content[offset:end])
+		encoded, err := redundancy.EncodeRawSegment(bytes.Clone(content[offset:end]), dataShards, parityShards)
+		require.NoError(t, err)
+		pieces = append(pieces, encoded)
+	}
+	return content, segments, pieces
+}
+
+func checksumsOf(pieces [][]byte) [][]byte {
+	checksums := make([][]byte, len(pieces))
+	for index, data := range pieces {
+		checksums[index] = hash.GenerateChecksum(data)
+	}
+	return checksums
+}
+
+func TestChallenge(t *testing.T) {
+	content, segments, pieces := object(t, 5*segmentSize+100)
+	result, err := hash.ComputeIntegrityHashWithOptions(bytes.NewReader(content), segmentSize, dataShards,
+		parityShards)
+	require.NoError(t, err)
+
+	for ecIndex := piece.NoECIndex; ecIndex < dataShards+parityShards; ecIndex++ {
+		spPieces := segments
+		if ecIndex != piece.NoECIndex {
+			spPieces = make([][]byte, len(pieces))
+			for segIndex := range pieces {
+				spPieces[segIndex] = pieces[segIndex][ecIndex]
+			}
+		}
+		checksums := checksumsOf(spPieces)
+		for segIndex := range spPieces {
+			challenge := Challenge{ObjectID: 1, SegmentIndex: uint32(segIndex), ECIndex: ecIndex}
+			root, err := IntegrityHash(result, challenge)
+			require.NoError(t, err)
+			response, err := NewResponse(challenge, checksums, spPieces[segIndex])
+			require.NoError(t, err)
+			assert.Len(t, response.SiblingChecksums, len(spPieces)-1)
+			assert.NoError(t, VerifyResponse(root, challenge, response))
+			assert.NoError(t, response.VerifyPieceData(spPieces[segIndex]))
+
+			other := challenge
+			other.SegmentIndex = uint32((segIndex + 1) % len(spPieces))
+			assert.ErrorIs(t, VerifyResponse(root, other, response), hash.ErrIntegrityHashMismatch)
+		}
+	}
+}
+
+func TestChallengeTampered(t *testing.T) {
+	_, segments, _ := object(t, 3*segmentSize)
+	checksums := checksumsOf(segments)
+	root := hash.GenerateIntegrityHash(checksums)
+	challenge := Challenge{ObjectID: 1, SegmentIndex: 1, ECIndex: piece.NoECIndex}
+	assert.Equal(t, piece.SegmentPieceKey(1, 1), challenge.Key().String())
+
+	corrupted := bytes.Clone(segments[1])
+	corrupted[0] ^= 1
+	_, err := NewResponse(challenge, checksums, corrupted)
+	assert.ErrorIs(t, err, hash.ErrPieceChecksumMismatch)
+
+	response, err := NewResponse(challenge, checksums, segments[1])
+	require.NoError(t, err)
+	assert.ErrorIs(t, response.VerifyPieceData(corrupted), hash.ErrPieceChecksumMismatch)
+
+	forged := &Response{PieceHash: hash.GenerateChecksum(corrupted), SiblingChecksums: response.SiblingChecksums}
+	assert.ErrorIs(t, VerifyResponse(root, challenge, forged), hash.ErrIntegrityHashMismatch)
+
+	response.SiblingChecksums[0] = hash.GenerateChecksum(corrupted)
+	assert.ErrorIs(t, VerifyResponse(root, challenge, response), hash.ErrIntegrityHashMismatch)
+}
+
+func TestInvalidChallenge(t *testing.T) {
+	_, segments, _ := object(t, 2*segmentSize)
+	checksums := checksumsOf(segments)
+	result := hash.NewHashResult([][]byte{hash.GenerateIntegrityHash(checksums)}, 2*segmentSize, 0)
+
+	for _, challenge := range []Challenge{
+		{SegmentIndex: 2, ECIndex: piece.NoECIndex},
+		{SegmentIndex: 0, ECIndex: -2},
+	} {
+		_, err := NewResponse(challenge, checksums, segments[0])
+		assert.ErrorIs(t, err, ErrInvalidChallenge)
+		err = VerifyResponse(result.PrimaryChecksum(), challenge, &Response{SiblingChecksums: checksums[1:]})
+		assert.ErrorIs(t, err, ErrInvalidChallenge)
+	}
+	_, err := IntegrityHash(result, Challenge{ECIndex: 0})
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+	root, err := IntegrityHash(result, Challenge{ECIndex: piece.NoECIndex})
+	require.NoError(t, err)
+	assert.Equal(t, result.PrimaryChecksum(), root)
+}
@@ -0,0 +1,103 @@
+// Package clock reads the time and waits through a Clock, the system clock in production and a Fake advanced by
+// hand in tests, so the timeouts and the expiries of the other packages can be tested without sleeping.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock tells the time and waits
+type Clock interface {
+	// Now return the current time
+	Now() time.Time
+	// Since return the time elapsed since t
+	Since(t time.Time) time.Duration
+	// After return a channel receiving the time once d elapsed
+	After(d time.Duration) <-chan time.Time
+	// Sleep waits for d to elapse
+	Sleep(d time.Duration)
+	// NewTimer return a Timer firing once d elapsed
+	NewTimer(d time.Duration) Timer
+	// NewTicker return a Ticker firing every d, d must be positive
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a time.Timer of a Clock
+type Timer interface {
+	// C return the channel receiving the time when the timer fires
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, it returns false if the timer already fired or was stopped
+	Stop() bool
+	// Reset changes the timer to fire once d elapsed, it returns false if the timer already fired or was stopped
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a time.Ticker of a Clock
+type Ticker interface {
+	// C return the channel receiving the time of the ticks
+	C() <-chan time.Time
+	// Stop turns off the ticker
+	Stop()
+	// Reset changes the period of the ticker to d, d must be positive
+	Reset(d time.Duration)
+}
+
+// Real return the system clock
+func Real() Clock {
+	return realClock{}
+}
+
+// SleepContext waits for d to elapse on the clock, it returns the error of ctx if it is done first
+func SleepContext(ctx context.Context, c Clock, d time.Duration) error {
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
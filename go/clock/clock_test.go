@@ -0,0 +1,40 @@
+package clock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReal(t *testing.T) {
+	c := Real()
+	start := c.Now()
+	c.Sleep(time.Millisecond)
+	assert.GreaterOrEqual(t, c.Since(start), time.Millisecond)
+	<-c.After(time.Millisecond)
+
+	timer := c.NewTimer(time.Hour)
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	ticker := c.NewTicker(time.Millisecond)
+	<-ticker.C()
+	ticker.Stop()
+}
+
+func TestSleepContext(t *testing.T) {
+	c := NewFake(time.Unix(1700000000, 0))
+	done := make(chan error, 1)
+	go func() {
+		done <- SleepContext(context.Background(), c, time.Second)
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+	assert.NoError(t, <-done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, SleepContext(ctx, c, time.Second), context.Canceled)
+	assert.Zero(t, c.Waiters())
+}
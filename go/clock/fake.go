@@ -0,0 +1,185 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when it is advanced, the timers, tickers and sleepers waiting on it fire in
+// the order of their deadlines as the time passes them. It is safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a timer, or a ticker when its period is positive, of a Fake
+type fakeWaiter struct {
+	clock    *Fake
+	deadline time.Time
+	period   time.Duration
+	c        chan time.Time
+}
+
+// NewFake return a Fake clock starting at now
+func NewFake(now time.Time) *Fake {
+	c := &Fake{now: now}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now implements Clock
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Since implements Clock
+func (c *Fake) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+// After implements Clock
+func (c *Fake) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Sleep implements Clock, it returns once the clock is advanced by d
+func (c *Fake) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// NewTimer implements Clock
+func (c *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, c: make(chan time.Time, 1)}
+	w.Reset(d)
+	return w
+}
+
+// NewTicker implements Clock
+func (c *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{clock: c, period: d, c: make(chan time.Time, 1)}
+	w.Reset(d)
+	return fakeTicker{w}
+}
+
+// Advance moves the time forward by d and fires the waiters whose deadline is passed
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(c.now.Add(d))
+}
+
+// Set moves the time to t and fires the waiters whose deadline is passed, the time never moves backward
+func (c *Fake) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.advanceTo(t)
+}
+
+// Waiters return the number of timers, tickers and sleepers waiting on the clock
+func (c *Fake) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers, tickers and sleepers wait on the clock, e.g. before advancing it past
+// the deadline of a goroutine about to sleep
+func (c *Fake) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+// advanceTo fires the waiters in the order of their deadlines up to t, the ticks of a ticker are dropped like the
+// ones of a time.Ticker when they are not received
+func (c *Fake) advanceTo(t time.Time) {
+	for len(c.waiters) > 0 && !c.waiters[0].deadline.After(t) {
+		w := c.waiters[0]
+		if w.deadline.After(c.now) {
+			c.now = w.deadline
+		}
+		select {
+		case w.c <- c.now:
+		default:
+		}
+		c.remove(w)
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+			c.add(w)
+		}
+	}
+	if t.After(c.now) {
+		c.now = t
+	}
+}
+
+// add schedules the waiter by its deadline
+func (c *Fake) add(w *fakeWaiter) {
+	i := sort.Search(len(c.waiters), func(i int) bool { return c.waiters[i].deadline.After(w.deadline) })
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+	c.changed.Broadcast()
+}
+
+// remove unschedules the waiter and return whether it was scheduled
+func (c *Fake) remove(w *fakeWaiter) bool {
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.remove(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	c := w.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	active := c.remove(w)
+	if w.period > 0 {
+		w.period = d
+	}
+	w.deadline = c.now.Add(d)
+	c.add(w)
+	// the timers of non-positive durations fire right away
+	c.advanceTo(c.now)
+	return active
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.fakeWaiter.Reset(d)
+}
@@ -0,0 +1,83 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeTimer(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	c := NewFake(start)
+	timer := c.NewTimer(2 * time.Second)
+	after := c.After(time.Second)
+	assert.Equal(t, 2, c.Waiters())
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-after)
+	assert.Empty(t, timer.C())
+	assert.Equal(t, time.Second, c.Since(start))
+
+	assert.True(t, timer.Reset(3*time.Second))
+	c.Advance(2 * time.Second)
+	assert.Empty(t, timer.C())
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(4*time.Second), <-timer.C())
+	assert.Equal(t, start.Add(time.Hour+3*time.Second), c.Now())
+	assert.False(t, timer.Stop())
+
+	timer = c.NewTimer(time.Second)
+	assert.True(t, timer.Stop())
+	c.Advance(time.Second)
+	assert.Empty(t, timer.C())
+	assert.Zero(t, c.Waiters())
+
+	// the timers of non-positive durations fire right away
+	assert.Equal(t, c.Now(), <-c.After(0))
+
+	// the time never moves backward
+	now := c.Now()
+	c.Set(start)
+	assert.Equal(t, now, c.Now())
+}
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	c := NewFake(start)
+	ticker := c.NewTicker(time.Second)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-ticker.C())
+
+	// the ticks which are not received are dropped
+	c.Advance(3 * time.Second)
+	assert.Equal(t, start.Add(2*time.Second), <-ticker.C())
+	assert.Empty(t, ticker.C())
+
+	ticker.Reset(time.Minute)
+	c.Advance(time.Second)
+	assert.Empty(t, ticker.C())
+	c.Set(start.Add(4*time.Second + time.Minute))
+	assert.Equal(t, start.Add(4*time.Second+time.Minute), <-ticker.C())
+
+	ticker.Stop()
+	c.Advance(time.Hour)
+	assert.Empty(t, ticker.C())
+	assert.Panics(t, func() { c.NewTicker(0) })
+}
+
+func TestFakeSleep(t *testing.T) {
+	c := NewFake(time.Unix(1700000000, 0))
+	woken := make(chan time.Time)
+	for i := 1; i <= 2; i++ {
+		go func(d time.Duration) {
+			c.Sleep(d)
+			woken <- c.Now()
+		}(time.Duration(i) * time.Second)
+	}
+	c.BlockUntil(2)
+	c.Advance(time.Second)
+	assert.Equal(t, time.Unix(1700000001, 0), <-woken)
+	c.Advance(time.Second)
+	assert.Equal(t, time.Unix(1700000002, 0), <-woken)
+}
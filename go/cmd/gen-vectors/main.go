@@ -0,0 +1,190 @@
+// gen-vectors emits the corpus of test vectors of the hash package as JSON, so the SDKs of other languages can
+// vendor it and check their integrity hash implementations against this package.
+//
+// Usage:
+//
+//	gen-vectors [-out vectors.json] [-inline-size 4096] [-greenfield]
+//
+// Every vector records its input, the redundancy params, the checksums of the segments, the checksums of the ec
+// pieces and the integrity hashes, the roots. The input of a vector of n bytes is the n first bytes of the pattern
+// described by the corpus, the byte at offset i is i mod 251 plus one, it is also inlined in base64 up to the inline
+// size. The checksums and the roots are hex encoded. -greenfield adds the vectors of the params of the Greenfield
+// chain, whose objects span several segments of 16MiB, with the roots computed by greenfield-common and pinned in the
+// hash package.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// corpus is the JSON document emitted by gen-vectors
+type corpus struct {
+	// HashVersion is the version of the algorithms of the integrity hashes
+	HashVersion  uint8  `json:"hash_version"`
+	InputPattern string `json:"input_pattern"`
+	// Checksum describes how the checksums of the segments and of the pieces are computed, Root how the integrity
+	// hashes are computed from them
+	Checksum string `json:"checksum"`
+	Root     string `json:"root"`
+	// GreenfieldCommon is the version of greenfield-common which computed the roots of the Greenfield vectors
+	GreenfieldCommon string   `json:"greenfield_common,omitempty"`
+	Vectors          []vector `json:"vectors"`
+}
+
+// vector is a test vector of the corpus
+type vector struct {
+	Name         string `json:"name"`
+	ObjectSize   int64  `json:"object_size"`
+	SegmentSize  int64  `json:"segment_size"`
+	DataShards   int    `json:"data_shards"`
+	ParityShards int    `json:"parity_shards"`
+	// InputSHA256 is the sha256 of the input, Input the base64 input if it is not larger than the inline size
+	InputSHA256 string `json:"input_sha256"`
+	Input       string `json:"input,omitempty"`
+	// SegmentChecksums are the checksums of the segments
+	SegmentChecksums []string `json:"segment_checksums"`
+	// PieceChecksums are the checksums of the ec pieces by ec index then by segment index
+	PieceChecksums [][]string `json:"piece_checksums"`
+	// Roots are the integrity hash of the PrimarySP, of the segment checksums, followed by the integrity hashes of the
+	// SecondarySPs, of the piece checksums of each ec index
+	Roots          []string `json:"roots"`
+	RedundancyType int32    `json:"redundancy_type"`
+}
+
+func main() {
+	output := flag.String("out", "", "file to write the corpus to, the standard output by default")
+	inlineSize := flag.Int64("inline-size", 4096, "largest input inlined in the vectors")
+	greenfield := flag.Bool("greenfield", false, "add the vectors of the params of the Greenfield chain")
+	flag.Parse()
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create the corpus: %s\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := generate(out, *inlineSize, *greenfield); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate the corpus: %s\n", err)
+		if *output != "" {
+			os.Remove(*output)
+		}
+		os.Exit(1)
+	}
+}
+
+// generate writes the corpus to out
+func generate(out io.Writer, inlineSize int64, greenfield bool) error {
+	testVectors, err := hash.GenerateTestVectors()
+	if err != nil {
+		return err
+	}
+	greenfieldCommon := ""
+	if greenfield {
+		greenfieldVectors, version, err := hash.GreenfieldTestVectors()
+		if err != nil {
+			return err
+		}
+		if len(greenfieldVectors) == 0 {
+			return errors.New("no vector of greenfield-common is pinned in the hash package")
+		}
+		testVectors = append(testVectors, greenfieldVectors...)
+		greenfieldCommon = version
+	}
+	c := corpus{
+		HashVersion:  uint8(hash.CurrentHashVersion),
+		InputPattern: "the byte at offset i is i mod 251 plus one",
+		Checksum:     "sha256 of the segment or of the ec piece, the pieces are the Reed-Solomon shards of the segment",
+		Root:         "sha256 of the concatenated checksums, of an empty list for an empty object",
+		Vectors:      make([]vector, 0, len(testVectors)),
+	}
+	c.GreenfieldCommon = greenfieldCommon
+	for _, testVector := range testVectors {
+		v, err := newVector(testVector, inlineSize)
+		if err != nil {
+			return fmt.Errorf("vector %s: %w", testVector.Name, err)
+		}
+		c.Vectors = append(c.Vectors, v)
+	}
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+// newVector computes the checksums of the segments and of the pieces of the test vector, and checks that they make
+// the integrity hashes of the hash package
+func newVector(testVector hash.TestVector, inlineSize int64) (vector, error) {
+	pieceCount := testVector.DataShards + testVector.ParityShards
+	input := hash.TestVectorData(testVector.ObjectSize)
+	inputSum := sha256.Sum256(input)
+	v := vector{
+		Name:           testVector.Name,
+		ObjectSize:     testVector.ObjectSize,
+		SegmentSize:    testVector.SegmentSize,
+		DataShards:     testVector.DataShards,
+		ParityShards:   testVector.ParityShards,
+		InputSHA256:    hash.ChecksumToHex(inputSum[:]),
+		PieceChecksums: make([][]string, pieceCount),
+		RedundancyType: int32(testVector.RedundancyType),
+	}
+	if testVector.ObjectSize <= inlineSize {
+		v.Input = base64.StdEncoding.EncodeToString(input)
+	}
+
+	segmentChecksums := make([][]byte, 0, redundancy.SegmentCount(testVector.ObjectSize, testVector.SegmentSize))
+	pieceChecksums := make([][][]byte, pieceCount)
+	for offset := int64(0); offset < int64(len(input)); offset += testVector.SegmentSize {
+		end := min(offset+testVector.SegmentSize, int64(len(input)))
+		// limit the capacity, the encoding uses the spare capacity of the segment for the parity shards
+		segment := input[offset:end:end]
+		segmentChecksums = append(segmentChecksums, hash.GenerateChecksum(segment))
+		shards, err := redundancy.EncodeRawSegment(bytes.Clone(segment), testVector.DataShards,
+			testVector.ParityShards)
+		if err != nil {
+			return vector{}, err
+		}
+		for index, shard := range shards {
+			pieceChecksums[index] = append(pieceChecksums[index], hash.GenerateChecksum(shard))
+		}
+	}
+
+	roots := append([][]byte{hash.GenerateIntegrityHash(segmentChecksums)}, make([][]byte, pieceCount)...)
+	for index, checksums := range pieceChecksums {
+		roots[index+1] = hash.GenerateIntegrityHash(checksums)
+		v.PieceChecksums[index] = encodeHex(checksums)
+	}
+	if len(roots) != len(testVector.Checksums) {
+		return vector{}, fmt.Errorf("%d roots, the hash package computed %d", len(roots), len(testVector.Checksums))
+	}
+	for index, root := range roots {
+		if !bytes.Equal(root, testVector.Checksums[index]) {
+			return vector{}, fmt.Errorf("root %d differs from the integrity hash of the hash package", index)
+		}
+	}
+	v.SegmentChecksums = encodeHex(segmentChecksums)
+	v.Roots = encodeHex(roots)
+	return v, nil
+}
+
+// encodeHex return the hex encoded checksums, an empty list for no checksum
+func encodeHex(checksums [][]byte) []string {
+	encoded := make([]string, len(checksums))
+	for i, checksum := range checksums {
+		encoded[i] = hash.ChecksumToHex(checksum)
+	}
+	return encoded
+}
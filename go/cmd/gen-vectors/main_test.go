@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+func TestGenerate(t *testing.T) {
+	var out bytes.Buffer
+	if err := generate(&out, 1024, false); err != nil {
+		t.Fatalf("generate failed: %s", err)
+	}
+	var c corpus
+	if err := json.Unmarshal(out.Bytes(), &c); err != nil {
+		t.Fatalf("invalid corpus: %s", err)
+	}
+	golden, err := hash.GoldenTestVectors()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Vectors) != len(golden) {
+		t.Fatalf("expect %d vectors, got %d", len(golden), len(c.Vectors))
+	}
+
+	for i, v := range c.Vectors {
+		for index, checksum := range golden[i].Checksums {
+			if v.Roots[index] != hex.EncodeToString(checksum) {
+				t.Errorf("vector %s: root %d differs from the golden vector", v.Name, index)
+			}
+		}
+		if len(v.PieceChecksums) != v.DataShards+v.ParityShards {
+			t.Errorf("vector %s: %d piece checksum lists", v.Name, len(v.PieceChecksums))
+		}
+		for _, checksums := range v.PieceChecksums {
+			if len(checksums) != len(v.SegmentChecksums) {
+				t.Errorf("vector %s: %d piece checksums for %d segments", v.Name, len(checksums),
+					len(v.SegmentChecksums))
+			}
+		}
+
+		// the inlined inputs match their sha256
+		if v.ObjectSize > 1024 {
+			if v.Input != "" {
+				t.Errorf("vector %s: input of %d bytes inlined", v.Name, v.ObjectSize)
+			}
+			continue
+		}
+		input, err := base64.StdEncoding.DecodeString(v.Input)
+		if err != nil || int64(len(input)) != v.ObjectSize {
+			t.Errorf("vector %s: invalid input", v.Name)
+		}
+		if sum := sha256.Sum256(input); v.InputSHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("vector %s: the input does not match its sha256", v.Name)
+		}
+	}
+}
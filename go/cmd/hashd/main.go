@@ -0,0 +1,107 @@
+// hashd serves the integrity hashing of the hash package over gRPC, the clients stream the objects and receive their
+// integrity hashes.
+//
+// Usage:
+//
+//	hashd [-listen :9400] [-segment-size 16777216] [-max-segment-size 67108864] [-log-levels info]
+//
+// The service is mechain.common.hash.v1.HashService defined in proto/mechain/common/hash/v1/hash.proto, it is
+// served along the standard gRPC health service until SIGINT or SIGTERM.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	commongrpc "github.com/zkMeLabs/mechain-common/go/grpc"
+	"github.com/zkMeLabs/mechain-common/go/lifecycle"
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/proto/hashpb"
+)
+
+var logger = log.Module("hashd")
+
+func main() {
+	listen := flag.String("listen", ":9400", "address to serve the gRPC requests on")
+	segmentSize := flag.Int64("segment-size", 16*1024*1024, "segment size of the requests without params")
+	maxSegmentSize := flag.Int64("max-segment-size", 64*1024*1024, "largest segment size of the requests")
+	logLevels := flag.String("log-levels", "info", "levels of the log modules, e.g. info,hashd=debug")
+	flag.Parse()
+
+	if *segmentSize <= 0 || *segmentSize > *maxSegmentSize {
+		fmt.Fprintf(os.Stderr, "segment size %d is not between 1 and the max segment size %d\n", *segmentSize,
+			*maxSegmentSize)
+		os.Exit(2)
+	}
+	levels, err := log.ParseLevels(*logLevels)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log levels: %s\n", err)
+		os.Exit(2)
+	}
+	log.SetLevels(levels)
+	log.SetLogger(log.NewSlogLogger(slog.New(slog.NewTextHandler(os.Stderr,
+		&slog.HandlerOptions{Level: slog.LevelDebug}))))
+
+	srv := newGRPCServer(&server{segmentSize: *segmentSize, maxSegmentSize: *maxSegmentSize})
+	manager := lifecycle.NewManager(lifecycle.WithLogger(logger))
+	if err = manager.Register(grpcService(srv, *listen)); err == nil {
+		err = manager.Run(context.Background())
+	}
+	if err != nil {
+		logger.Errorf("hashd failed: %s", err)
+		os.Exit(1)
+	}
+}
+
+// newGRPCServer return a gRPC server serving the hash service and the health service
+func newGRPCServer(hashServer hashpb.HashServiceServer) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainStreamInterceptor(
+		commongrpc.StreamServerRecovery(commongrpc.DefaultRecoveryHandler),
+		commongrpc.StreamServerRequestID(),
+		commongrpc.StreamServerLogging(logger),
+	))
+	hashpb.RegisterHashServiceServer(srv, hashServer)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
+
+// grpcService return the service serving srv on address
+func grpcService(srv *grpc.Server, address string) lifecycle.Service {
+	return lifecycle.NewService("grpc",
+		func(context.Context) error {
+			listener, err := net.Listen("tcp", address)
+			if err != nil {
+				return err
+			}
+			logger.Infof("serving gRPC on %s", listener.Addr())
+			go func() {
+				if err := srv.Serve(listener); err != nil {
+					logger.Errorf("failed to serve gRPC: %s", err)
+				}
+			}()
+			return nil
+		},
+		func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+				return nil
+			case <-ctx.Done():
+				// the streams still open are cancelled
+				srv.Stop()
+				return ctx.Err()
+			}
+		})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/proto/hashpb"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// server implements the HashService with the IntegrityHasher of the hash package
+type server struct {
+	hashpb.UnimplementedHashServiceServer
+	// segmentSize is the segment size of the requests without params
+	segmentSize int64
+	// maxSegmentSize bounds the segment size of the requests, the hashing keeps a segment in memory
+	maxSegmentSize int64
+}
+
+// ComputeIntegrityHash implements hashpb.HashServiceServer
+func (s *server) ComputeIntegrityHash(stream hashpb.HashService_ComputeIntegrityHashServer) error {
+	req, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		// no request is an empty object
+		req, err = &hashpb.ComputeIntegrityHashRequest{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	result, err := s.hash(req.GetParams(), req.GetData(), func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetData(), err
+	})
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resultOf(result))
+}
+
+// VerifyIntegrityHash implements hashpb.HashServiceServer
+func (s *server) VerifyIntegrityHash(stream hashpb.HashService_VerifyIntegrityHashServer) error {
+	req, err := stream.Recv()
+	if errors.Is(err, io.EOF) {
+		return status.Error(codes.InvalidArgument, "no expected checksums")
+	}
+	if err != nil {
+		return err
+	}
+	expected := req.GetExpectedChecksums()
+	if len(expected) == 0 {
+		return status.Error(codes.InvalidArgument, "no expected checksums")
+	}
+	result, err := s.hash(req.GetParams(), req.GetData(), func() ([]byte, error) {
+		req, err := stream.Recv()
+		return req.GetData(), err
+	})
+	if err != nil {
+		return err
+	}
+	mismatches := compareChecksums(result.Checksums, expected)
+	return stream.SendAndClose(&hashpb.VerifyIntegrityHashResponse{
+		Valid:      len(mismatches) == 0,
+		Result:     resultOf(result),
+		Mismatches: mismatches,
+	})
+}
+
+// hash return the integrity hashes of the object made of data followed by the data returned by recv until io.EOF
+func (s *server) hash(params *hashpb.HashParams, data []byte, recv func() ([]byte, error)) (*hash.HashResult,
+	error,
+) {
+	segmentSize, dataShards, parityShards, err := s.params(params)
+	if err != nil {
+		return nil, err
+	}
+	hasher := hash.AcquireHasher(segmentSize, dataShards, parityShards)
+	defer hash.ReleaseHasher(hasher)
+	for {
+		// the hasher takes at most a segment at once
+		for len(data) > 0 {
+			n := min(int64(len(data)), segmentSize)
+			if err = hasher.Append(data[:n]); err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+			data = data[n:]
+		}
+		data, err = recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	checksums, contentLength, redundancyType, err := hasher.Finish()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return hash.NewHashResult(checksums, contentLength, redundancyType), nil
+}
+
+// params return the params of the request, or the defaults of the server for the zero ones
+func (s *server) params(params *hashpb.HashParams) (segmentSize int64, dataShards, parityShards int, err error) {
+	segmentSize, dataShards, parityShards = s.segmentSize, redundancy.DataBlocks, redundancy.ParityBlocks
+	if params.GetSegmentSize() != 0 {
+		segmentSize = params.GetSegmentSize()
+	}
+	if params.GetDataShards() != 0 {
+		dataShards = int(params.GetDataShards())
+	}
+	if params.GetParityShards() != 0 {
+		parityShards = int(params.GetParityShards())
+	}
+	if segmentSize <= 0 || segmentSize > s.maxSegmentSize {
+		return 0, 0, 0, status.Errorf(codes.InvalidArgument, "segment size %d is not between 1 and %d", segmentSize,
+			s.maxSegmentSize)
+	}
+	if _, err = redundancy.NewRedundancyParams(segmentSize, dataShards, parityShards); err != nil {
+		return 0, 0, 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return segmentSize, dataShards, parityShards, nil
+}
+
+// compareChecksums return the indexes of the checksums which differ from the expected ones, including the ones
+// missing on either side
+func compareChecksums(checksums, expected [][]byte) []uint32 {
+	var mismatches []uint32
+	for i := 0; i < max(len(checksums), len(expected)); i++ {
+		if i >= len(checksums) || i >= len(expected) || !bytes.Equal(checksums[i], expected[i]) {
+			mismatches = append(mismatches, uint32(i))
+		}
+	}
+	return mismatches
+}
+
+// resultOf return the message of the result
+func resultOf(result *hash.HashResult) *hashpb.HashResult {
+	return &hashpb.HashResult{
+		Version:        uint32(result.Version),
+		Checksums:      result.Checksums,
+		ContentLength:  result.ContentLength,
+		RedundancyType: int32(result.RedundancyType),
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/proto/hashpb"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+const testSegmentSize = 1024
+
+// serve starts hashd and return a client of its hash service
+func serve(t *testing.T) hashpb.HashServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	srv := newGRPCServer(&server{segmentSize: testSegmentSize, maxSegmentSize: 4 * testSegmentSize})
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return hashpb.NewHashServiceClient(conn)
+}
+
+// compute streams the object in chunks of chunkSize
+func compute(t *testing.T, client hashpb.HashServiceClient, params *hashpb.HashParams, object []byte,
+	chunkSize int,
+) (*hashpb.HashResult, error) {
+	stream, err := client.ComputeIntegrityHash(context.Background())
+	require.NoError(t, err)
+	req := &hashpb.ComputeIntegrityHashRequest{Params: params}
+	for len(object) > 0 {
+		n := min(len(object), chunkSize)
+		req.Data = object[:n]
+		require.NoError(t, stream.Send(req))
+		req = &hashpb.ComputeIntegrityHashRequest{}
+		object = object[n:]
+	}
+	return stream.CloseAndRecv()
+}
+
+func TestComputeIntegrityHash(t *testing.T) {
+	client := serve(t)
+	object := make([]byte, 5*testSegmentSize+100)
+	_, err := rand.Read(object)
+	require.NoError(t, err)
+	params, err := redundancy.NewRedundancyParams(testSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	require.NoError(t, err)
+	expected, err := hash.ComputeIntegrityHashWithParams(bytes.NewReader(object), params)
+	require.NoError(t, err)
+
+	// the chunks are larger or shorter than the segments
+	for _, chunkSize := range []int{100, testSegmentSize, 3000} {
+		result, err := compute(t, client, nil, object, chunkSize)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(hash.CurrentHashVersion), result.Version)
+		assert.Equal(t, expected.Checksums, result.Checksums)
+		assert.Equal(t, int64(len(object)), result.ContentLength)
+		assert.Equal(t, int32(expected.RedundancyType), result.RedundancyType)
+	}
+
+	// explicit params
+	params, err = redundancy.NewRedundancyParams(2*testSegmentSize, 2, 1)
+	require.NoError(t, err)
+	expected, err = hash.ComputeIntegrityHashWithParams(bytes.NewReader(object), params)
+	require.NoError(t, err)
+	result, err := compute(t, client, &hashpb.HashParams{SegmentSize: 2 * testSegmentSize, DataShards: 2,
+		ParityShards: 1}, object, 700)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Checksums, result.Checksums)
+	assert.Len(t, result.Checksums, 4)
+
+	// empty object
+	result, err = compute(t, client, nil, nil, 100)
+	require.NoError(t, err)
+	assert.Zero(t, result.ContentLength)
+
+	// invalid params
+	_, err = compute(t, client, &hashpb.HashParams{SegmentSize: 5 * testSegmentSize}, object, 100)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = compute(t, client, &hashpb.HashParams{DataShards: 300}, object, 100)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestVerifyIntegrityHash(t *testing.T) {
+	client := serve(t)
+	object := make([]byte, 3*testSegmentSize)
+	_, err := rand.Read(object)
+	require.NoError(t, err)
+	result, err := compute(t, client, nil, object, testSegmentSize)
+	require.NoError(t, err)
+
+	verify := func(expected [][]byte) (*hashpb.VerifyIntegrityHashResponse, error) {
+		stream, err := client.VerifyIntegrityHash(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, stream.Send(&hashpb.VerifyIntegrityHashRequest{
+			ExpectedChecksums: expected,
+			Data:              object[:500],
+		}))
+		require.NoError(t, stream.Send(&hashpb.VerifyIntegrityHashRequest{Data: object[500:]}))
+		return stream.CloseAndRecv()
+	}
+
+	resp, err := verify(result.Checksums)
+	require.NoError(t, err)
+	assert.True(t, resp.Valid)
+	assert.Empty(t, resp.Mismatches)
+	assert.Equal(t, result.Checksums, resp.Result.Checksums)
+
+	// a corrupted and a missing checksum
+	expected := make([][]byte, len(result.Checksums)-1)
+	copy(expected, result.Checksums)
+	expected[2] = bytes.Repeat([]byte{1}, len(expected[2]))
+	resp, err = verify(expected)
+	require.NoError(t, err)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, []uint32{2, uint32(len(result.Checksums) - 1)}, resp.Mismatches)
+
+	_, err = verify(nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,159 @@
+// mechain-ec checks the erasure encoding of the mechain redundancy package on the operator's machine, and encodes,
+// decodes, verifies and repairs the ec pieces of objects stored on disk.
+//
+// Usage:
+//
+//	mechain-ec doctor [-data 4] [-parity 2] [-segment-size 16777216] [-segments 8]
+//	mechain-ec encode [-data 4] [-parity 2] [-segment-size 16777216] [-object-id 0] -dir pieces file
+//	mechain-ec decode [-object-id 0] -dir pieces -out file
+//	mechain-ec verify [-object-id 0] -dir pieces
+//	mechain-ec repair [-object-id 0] -dir pieces
+//
+// doctor encodes random segments, drops random shards, reconstructs them and verifies the integrity hashes of the
+// reconstructed pieces end-to-end, then prints the encode and decode throughputs and the CPU acceleration.
+//
+// encode splits a file into segments and stores their ec pieces in the pieces directory under their piece keys, with
+// the layout of the piecestore FileStore, along a manifest named by the object id which records the checksums of the
+// segments and of the pieces. decode reconstructs the file from any data shards of each segment, verify reports the
+// missing and the corrupted pieces, and repair reconstructs them from the intact ones.
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"os"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+type doctorConfig struct {
+	dataShards   int
+	parityShards int
+	segmentSize  int64
+	segments     int
+}
+
+// commands are the subcommands by name, they parse their arguments
+var commands = map[string]func(args []string) error{
+	"doctor": runDoctor,
+	"encode": runEncode,
+	"decode": runDecode,
+	"verify": runVerify,
+	"repair": runRepair,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: mechain-ec doctor|encode|decode|verify|repair [flags]")
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %s\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// runDoctor runs the doctor subcommand
+func runDoctor(args []string) error {
+	config := doctorConfig{}
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	flags.IntVar(&config.dataShards, "data", redundancy.DataBlocks, "number of data shards")
+	flags.IntVar(&config.parityShards, "parity", redundancy.ParityBlocks, "number of parity shards")
+	flags.Int64Var(&config.segmentSize, "segment-size", 16*1024*1024, "segment size in bytes")
+	flags.IntVar(&config.segments, "segments", 8, "number of random segments to check")
+	_ = flags.Parse(args)
+	return doctor(os.Stdout, config)
+}
+
+// doctor runs the checks and writes the report to out, an error is returned if any check fails
+func doctor(out io.Writer, config doctorConfig) error {
+	params, err := redundancy.NewRedundancyParams(config.segmentSize, config.dataShards, config.parityShards)
+	if err != nil {
+		return err
+	}
+	if config.segments <= 0 {
+		return fmt.Errorf("at least one segment is required, got %d", config.segments)
+	}
+	capabilities := redundancy.Capabilities()
+	fmt.Fprintf(out, "cpu: %s\n", capabilities)
+	if !capabilities.Accelerated {
+		fmt.Fprintln(out, "warning: the erasure encoding is not hardware accelerated")
+	}
+
+	// the last segment is short to check the padding
+	object := make([]byte, config.segmentSize*int64(config.segments)-config.segmentSize/3)
+	if _, err = rand.Read(object); err != nil {
+		return err
+	}
+	expected, err := hash.ComputeIntegrityHashWithParams(bytes.NewReader(object), params)
+	if err != nil {
+		return fmt.Errorf("failed to compute the integrity hash: %w", err)
+	}
+
+	var encodeTime, decodeTime time.Duration
+	pieceChecksums := make([][][]byte, params.PieceCount())
+	for offset := int64(0); offset < int64(len(object)); offset += config.segmentSize {
+		end := min(offset+config.segmentSize, int64(len(object)))
+		// limit the capacity, the encoding uses the spare capacity of the segment for the parity shards
+		segment := object[offset:end:end]
+		start := time.Now()
+		shards, err := redundancy.EncodeRawSegment(segment, params.DataShards, params.ParityShards)
+		if err != nil {
+			return fmt.Errorf("failed to encode segment at %d: %w", offset, err)
+		}
+		encodeTime += time.Since(start)
+		if corrupted, err := redundancy.VerifyShards(shards, params.DataShards, params.ParityShards); err != nil ||
+			len(corrupted) > 0 {
+			return fmt.Errorf("inconsistent shards of segment at %d: %v %v", offset, corrupted, err)
+		}
+
+		// lose up to parityShards random shards
+		lost := make([][]byte, len(shards))
+		copy(lost, shards)
+		for _, index := range mathrand.Perm(len(shards))[:1+mathrand.Intn(params.ParityShards)] {
+			lost[index] = nil
+		}
+		start = time.Now()
+		decoded, err := redundancy.DecodeRawSegment(lost, int64(len(segment)), params.DataShards, params.ParityShards)
+		if err != nil {
+			return fmt.Errorf("failed to decode segment at %d: %w", offset, err)
+		}
+		decodeTime += time.Since(start)
+		if !bytes.Equal(decoded, segment) {
+			return fmt.Errorf("the decoded segment at %d differs", offset)
+		}
+		if err = redundancy.ReconstructShards(lost, params.DataShards, params.ParityShards); err != nil {
+			return fmt.Errorf("failed to reconstruct the shards of segment at %d: %w", offset, err)
+		}
+		for index, shard := range lost {
+			pieceChecksums[index] = append(pieceChecksums[index], hash.GenerateChecksum(shard))
+		}
+	}
+	for index, checksums := range pieceChecksums {
+		if !bytes.Equal(hash.GenerateIntegrityHash(checksums), expected.Checksums[index+1]) {
+			return errors.New("the integrity hash of the reconstructed pieces differs")
+		}
+	}
+
+	fmt.Fprintf(out, "checked %d segments of %d bytes with %d data and %d parity shards\n", config.segments,
+		config.segmentSize, params.DataShards, params.ParityShards)
+	fmt.Fprintf(out, "encode: %.1f MB/s\n", throughput(len(object), encodeTime))
+	fmt.Fprintf(out, "decode: %.1f MB/s\n", throughput(len(object), decodeTime))
+	fmt.Fprintln(out, "ok")
+	return nil
+}
+
+// throughput return the MB/s of processing size bytes in elapsed
+func throughput(size int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(size) / 1e6 / elapsed.Seconds()
+}
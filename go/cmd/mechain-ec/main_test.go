@@ -0,0 +1,22 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	var out bytes.Buffer
+	err := doctor(&out, doctorConfig{dataShards: 4, parityShards: 2, segmentSize: 64 * 1024, segments: 3})
+	if err != nil {
+		t.Fatalf("doctor failed: %s\n%s", err, out.String())
+	}
+	if !strings.HasSuffix(out.String(), "ok\n") {
+		t.Errorf("unexpected report %s", out.String())
+	}
+
+	if err = doctor(&out, doctorConfig{dataShards: 4, parityShards: 0, segmentSize: 1024, segments: 1}); err == nil {
+		t.Errorf("expect invalid params to fail")
+	}
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// manifest describes an object encoded by encode, it is stored along the pieces to decode, verify and repair them
+type manifest struct {
+	ObjectID     uint64 `json:"object_id"`
+	ObjectSize   int64  `json:"object_size"`
+	SegmentSize  int64  `json:"segment_size"`
+	DataShards   int    `json:"data_shards"`
+	ParityShards int    `json:"parity_shards"`
+	// Checksums are the integrity hashes of the PrimarySP followed by the integrity hashes of the SecondarySPs
+	Checksums [][]byte `json:"checksums"`
+	// SegmentChecksums are the checksums of the segments
+	SegmentChecksums [][]byte `json:"segment_checksums"`
+	// PieceChecksums are the checksums of the ec pieces by ec index then by segment index
+	PieceChecksums [][][]byte `json:"piece_checksums"`
+}
+
+// manifestPath return the path of the manifest of the object in the pieces directory
+func manifestPath(dir string, objectID uint64) string {
+	return filepath.Join(dir, strconv.FormatUint(objectID, 10)+".json")
+}
+
+// loadManifest loads and validates the manifest of the object from the pieces directory
+func loadManifest(dir string, objectID uint64) (*manifest, redundancy.RedundancyParams, error) {
+	data, err := os.ReadFile(manifestPath(dir, objectID))
+	if err != nil {
+		return nil, redundancy.RedundancyParams{}, err
+	}
+	m := &manifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, redundancy.RedundancyParams{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+	params, err := m.validate()
+	if err != nil {
+		return nil, redundancy.RedundancyParams{}, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, params, nil
+}
+
+// saveManifest writes the manifest to the pieces directory
+func saveManifest(dir string, m *manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(dir, m.ObjectID), data, 0o640)
+}
+
+// validate return the params of the manifest, or an error if its checksums are not consistent with its layout or
+// with its integrity hashes
+func (m *manifest) validate() (redundancy.RedundancyParams, error) {
+	params, err := redundancy.NewRedundancyParams(m.SegmentSize, m.DataShards, m.ParityShards)
+	if err != nil {
+		return params, err
+	}
+	segmentCount := int(redundancy.SegmentCount(m.ObjectSize, m.SegmentSize))
+	if len(m.SegmentChecksums) != segmentCount || len(m.PieceChecksums) != params.PieceCount() ||
+		len(m.Checksums) != params.PieceCount()+1 {
+		return params, errors.New("the checksums do not match the layout of the object")
+	}
+	if !bytes.Equal(hash.GenerateIntegrityHash(m.SegmentChecksums), m.Checksums[0]) {
+		return params, errors.New("the integrity hash of the PrimarySP does not match the segment checksums")
+	}
+	for ecIndex, checksums := range m.PieceChecksums {
+		if len(checksums) != segmentCount {
+			return params, fmt.Errorf("the checksums of the ec pieces %d do not match the layout of the object",
+				ecIndex)
+		}
+		if !bytes.Equal(hash.GenerateIntegrityHash(checksums), m.Checksums[ecIndex+1]) {
+			return params, fmt.Errorf("the integrity hash of the ec pieces %d does not match their checksums", ecIndex)
+		}
+	}
+	return params, nil
+}
+
+// encode splits the object read from input into segments, stores their ec pieces in store and return the manifest
+// of the object
+func encode(ctx context.Context, store piecestore.PieceStore, input io.Reader, objectID uint64,
+	params redundancy.RedundancyParams,
+) (*manifest, error) {
+	m := &manifest{
+		ObjectID:       objectID,
+		SegmentSize:    params.SegmentSize,
+		DataShards:     params.DataShards,
+		ParityShards:   params.ParityShards,
+		PieceChecksums: make([][][]byte, params.PieceCount()),
+	}
+	buffer := make([]byte, params.SegmentSize)
+	for segIndex := uint32(0); ; segIndex++ {
+		n, readErr := io.ReadFull(input, buffer)
+		if n == 0 && errors.Is(readErr, io.EOF) {
+			break
+		}
+		if readErr != nil && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return nil, readErr
+		}
+		// limit the capacity, the encoding uses the spare capacity of the segment for the parity shards
+		segment := buffer[:n:n]
+		shards, err := redundancy.EncodeRawSegment(segment, params.DataShards, params.ParityShards)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode segment %d: %w", segIndex, err)
+		}
+		for ecIndex, shard := range shards {
+			if err = store.Put(ctx, piece.NewECKey(objectID, segIndex, uint32(ecIndex)), shard); err != nil {
+				return nil, err
+			}
+			m.PieceChecksums[ecIndex] = append(m.PieceChecksums[ecIndex], hash.GenerateChecksum(shard))
+		}
+		m.SegmentChecksums = append(m.SegmentChecksums, hash.GenerateChecksum(segment))
+		m.ObjectSize += int64(n)
+		if readErr != nil {
+			break
+		}
+	}
+	m.Checksums = append(m.Checksums, hash.GenerateIntegrityHash(m.SegmentChecksums))
+	for _, checksums := range m.PieceChecksums {
+		m.Checksums = append(m.Checksums, hash.GenerateIntegrityHash(checksums))
+	}
+	return m, nil
+}
+
+// readSegment return the ec pieces of the segment of index, the missing and the corrupted pieces are nil and
+// described by damaged
+func readSegment(ctx context.Context, store piecestore.PieceStore, m *manifest, segIndex uint32) (
+	shards [][]byte, damaged map[int]string, err error,
+) {
+	shards = make([][]byte, len(m.PieceChecksums))
+	damaged = make(map[int]string)
+	for ecIndex := range shards {
+		data, err := store.Get(ctx, piece.NewECKey(m.ObjectID, segIndex, uint32(ecIndex)))
+		switch {
+		case errors.Is(err, piecestore.ErrPieceNotFound):
+			damaged[ecIndex] = "missing"
+		case err != nil:
+			return nil, nil, err
+		case !bytes.Equal(hash.GenerateChecksum(data), m.PieceChecksums[ecIndex][segIndex]):
+			damaged[ecIndex] = "corrupted"
+		default:
+			shards[ecIndex] = data
+		}
+	}
+	return shards, damaged, nil
+}
+
+// decode reconstructs the object from the ec pieces of store and writes it to out
+func decode(ctx context.Context, store piecestore.PieceStore, m *manifest, params redundancy.RedundancyParams,
+	out io.Writer,
+) error {
+	layout := params.Layout(m.ObjectSize)
+	for segIndex := uint32(0); int64(segIndex) < layout.SegmentCount; segIndex++ {
+		shards, damaged, err := readSegment(ctx, store, m, segIndex)
+		if err != nil {
+			return err
+		}
+		if len(damaged) > params.ParityShards {
+			return fmt.Errorf("segment %d can not be reconstructed, %d of its pieces are missing or corrupted",
+				segIndex, len(damaged))
+		}
+		segment, err := redundancy.DecodeRawSegment(shards, layout.SegmentLength(int64(segIndex)),
+			params.DataShards, params.ParityShards)
+		if err != nil {
+			return fmt.Errorf("failed to decode segment %d: %w", segIndex, err)
+		}
+		if !bytes.Equal(hash.GenerateChecksum(segment), m.SegmentChecksums[segIndex]) {
+			return fmt.Errorf("the checksum of the decoded segment %d differs", segIndex)
+		}
+		if _, err = out.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verify checks the checksums of the ec pieces of store and reports the missing and the corrupted ones to out, an
+// error is returned if any piece is damaged
+func verify(ctx context.Context, store piecestore.PieceStore, m *manifest, params redundancy.RedundancyParams,
+	out io.Writer,
+) error {
+	layout := params.Layout(m.ObjectSize)
+	damagedPieces, unrecoverable := 0, 0
+	for segIndex := uint32(0); int64(segIndex) < layout.SegmentCount; segIndex++ {
+		_, damaged, err := readSegment(ctx, store, m, segIndex)
+		if err != nil {
+			return err
+		}
+		for ecIndex := 0; ecIndex < params.PieceCount(); ecIndex++ {
+			if reason, ok := damaged[ecIndex]; ok {
+				fmt.Fprintf(out, "%s: %s\n", piece.ECPieceKey(m.ObjectID, segIndex, uint32(ecIndex)), reason)
+			}
+		}
+		damagedPieces += len(damaged)
+		if len(damaged) > params.ParityShards {
+			fmt.Fprintf(out, "segment %d: unrecoverable\n", segIndex)
+			unrecoverable++
+		}
+	}
+	if damagedPieces > 0 {
+		return fmt.Errorf("%d of %d pieces are damaged, %d segments are unrecoverable", damagedPieces,
+			layout.SegmentCount*int64(params.PieceCount()), unrecoverable)
+	}
+	fmt.Fprintf(out, "%d segments of %d pieces verified\n", layout.SegmentCount, params.PieceCount())
+	fmt.Fprintln(out, "ok")
+	return nil
+}
+
+// repair reconstructs the missing and the corrupted ec pieces of store from the intact ones and stores them again
+func repair(ctx context.Context, store piecestore.PieceStore, m *manifest, params redundancy.RedundancyParams,
+	out io.Writer,
+) error {
+	layout := params.Layout(m.ObjectSize)
+	repaired := 0
+	for segIndex := uint32(0); int64(segIndex) < layout.SegmentCount; segIndex++ {
+		shards, damaged, err := readSegment(ctx, store, m, segIndex)
+		if err != nil {
+			return err
+		}
+		if len(damaged) == 0 {
+			continue
+		}
+		if err = redundancy.ReconstructShards(shards, params.DataShards, params.ParityShards); err != nil {
+			return fmt.Errorf("failed to reconstruct the pieces of segment %d: %w", segIndex, err)
+		}
+		for ecIndex := 0; ecIndex < params.PieceCount(); ecIndex++ {
+			if _, ok := damaged[ecIndex]; !ok {
+				continue
+			}
+			key := piece.NewECKey(m.ObjectID, segIndex, uint32(ecIndex))
+			if !bytes.Equal(hash.GenerateChecksum(shards[ecIndex]), m.PieceChecksums[ecIndex][segIndex]) {
+				return fmt.Errorf("the checksum of the reconstructed piece %s differs", key)
+			}
+			if err = store.Put(ctx, key, shards[ecIndex]); err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "%s: repaired\n", key)
+			repaired++
+		}
+	}
+	fmt.Fprintf(out, "%d pieces repaired\n", repaired)
+	return nil
+}
+
+// runEncode runs the encode subcommand
+func runEncode(args []string) error {
+	var (
+		dataShards, parityShards int
+		segmentSize              int64
+		objectID                 uint64
+		dir                      string
+	)
+	flags := flag.NewFlagSet("encode", flag.ExitOnError)
+	flags.IntVar(&dataShards, "data", redundancy.DataBlocks, "number of data shards")
+	flags.IntVar(&parityShards, "parity", redundancy.ParityBlocks, "number of parity shards")
+	flags.Int64Var(&segmentSize, "segment-size", 16*1024*1024, "segment size in bytes")
+	flags.Uint64Var(&objectID, "object-id", 0, "object id of the piece keys")
+	flags.StringVar(&dir, "dir", "", "directory of the pieces")
+	_ = flags.Parse(args)
+	if dir == "" || flags.NArg() != 1 {
+		return errors.New("usage: mechain-ec encode [flags] -dir pieces file")
+	}
+	params, err := redundancy.NewRedundancyParams(segmentSize, dataShards, parityShards)
+	if err != nil {
+		return err
+	}
+	store, err := piecestore.NewFileStore(dir)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	m, err := encode(context.Background(), store, file, objectID, params)
+	if err != nil {
+		return err
+	}
+	if err = saveManifest(dir, m); err != nil {
+		return err
+	}
+	layout := params.Layout(m.ObjectSize)
+	fmt.Printf("encoded %d bytes into %d segments of %d pieces\n", m.ObjectSize, layout.SegmentCount,
+		layout.PieceCount)
+	for i, checksum := range m.Checksums {
+		fmt.Printf("integrity hash %d: %s\n", i, hash.ChecksumToHex(checksum))
+	}
+	fmt.Printf("manifest: %s\n", manifestPath(dir, objectID))
+	return nil
+}
+
+// runDecode runs the decode subcommand, the output file is removed if the object can not be decoded
+func runDecode(args []string) (err error) {
+	flags, objectID, dir := pieceFlags("decode")
+	output := flags.String("out", "", "file to write the decoded object to")
+	_ = flags.Parse(args)
+	if *dir == "" || *output == "" {
+		return errors.New("usage: mechain-ec decode [-object-id id] -dir pieces -out file")
+	}
+	store, m, params, err := openPieces(*dir, *objectID)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(*output)
+		}
+	}()
+	return decode(context.Background(), store, m, params, file)
+}
+
+// runVerify runs the verify subcommand
+func runVerify(args []string) error {
+	flags, objectID, dir := pieceFlags("verify")
+	_ = flags.Parse(args)
+	store, m, params, err := openPieces(*dir, *objectID)
+	if err != nil {
+		return err
+	}
+	return verify(context.Background(), store, m, params, os.Stdout)
+}
+
+// runRepair runs the repair subcommand
+func runRepair(args []string) error {
+	flags, objectID, dir := pieceFlags("repair")
+	_ = flags.Parse(args)
+	store, m, params, err := openPieces(*dir, *objectID)
+	if err != nil {
+		return err
+	}
+	return repair(context.Background(), store, m, params, os.Stdout)
+}
+
+// pieceFlags return the flags of the subcommands reading the pieces of an object
+func pieceFlags(name string) (flags *flag.FlagSet, objectID *uint64, dir *string) {
+	flags = flag.NewFlagSet(name, flag.ExitOnError)
+	objectID = flags.Uint64("object-id", 0, "object id of the piece keys")
+	dir = flags.String("dir", "", "directory of the pieces")
+	return flags, objectID, dir
+}
+
+// openPieces return the store of the pieces directory and the manifest of the object
+func openPieces(dir string, objectID uint64) (piecestore.PieceStore, *manifest, redundancy.RedundancyParams,
+	error,
+) {
+	if dir == "" {
+		return nil, nil, redundancy.RedundancyParams{}, errors.New("the directory of the pieces is required")
+	}
+	m, params, err := loadManifest(dir, objectID)
+	if err != nil {
+		return nil, nil, params, err
+	}
+	store, err := piecestore.NewFileStore(dir)
+	if err != nil {
+		return nil, nil, params, err
+	}
+	return store, m, params, nil
+}
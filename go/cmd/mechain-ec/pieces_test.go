@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestPieces(t *testing.T) {
+	ctx := context.Background()
+	params, err := redundancy.NewRedundancyParams(4096, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := make([]byte, 3*4096+1000)
+	if _, err = rand.Read(object); err != nil {
+		t.Fatal(err)
+	}
+	store, err := piecestore.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := encode(ctx, store, bytes.NewReader(object), 100, params)
+	if err != nil {
+		t.Fatalf("encode failed: %s", err)
+	}
+	expected, err := hash.ComputeIntegrityHashWithParams(bytes.NewReader(object), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, checksum := range expected.Checksums {
+		if !bytes.Equal(checksum, m.Checksums[i]) {
+			t.Errorf("integrity hash %d differs from the hash package", i)
+		}
+	}
+	if _, err = m.validate(); err != nil {
+		t.Errorf("invalid manifest: %s", err)
+	}
+
+	// lose a piece and corrupt another one of the same segment
+	if err = store.Delete(ctx, piece.NewECKey(100, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Put(ctx, piece.NewECKey(100, 1, 5), []byte("corrupted")); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = verify(ctx, store, m, params, &out); err == nil {
+		t.Errorf("expect damaged pieces to fail the verification")
+	}
+	if !strings.Contains(out.String(), "100_s1_p0: missing\n100_s1_p5: corrupted\n") {
+		t.Errorf("unexpected report %s", out.String())
+	}
+
+	var decoded bytes.Buffer
+	if err = decode(ctx, store, m, params, &decoded); err != nil {
+		t.Fatalf("decode failed: %s", err)
+	}
+	if !bytes.Equal(decoded.Bytes(), object) {
+		t.Errorf("the decoded object differs")
+	}
+
+	out.Reset()
+	if err = repair(ctx, store, m, params, &out); err != nil {
+		t.Fatalf("repair failed: %s", err)
+	}
+	if !strings.HasSuffix(out.String(), "2 pieces repaired\n") {
+		t.Errorf("unexpected report %s", out.String())
+	}
+	out.Reset()
+	if err = verify(ctx, store, m, params, &out); err != nil {
+		t.Errorf("verify failed after the repair: %s\n%s", err, out.String())
+	}
+
+	// more lost pieces than parity shards
+	for ecIndex := uint32(0); ecIndex < 3; ecIndex++ {
+		if err = store.Delete(ctx, piece.NewECKey(100, 2, ecIndex)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = decode(ctx, store, m, params, &decoded); err == nil {
+		t.Errorf("expect an unrecoverable segment to fail the decoding")
+	}
+	if err = repair(ctx, store, m, params, &out); err == nil {
+		t.Errorf("expect an unrecoverable segment to fail the repair")
+	}
+
+	// a tampered manifest
+	m.Checksums[3] = m.Checksums[2]
+	if _, err = m.validate(); err == nil {
+		t.Errorf("expect a tampered manifest to be invalid")
+	}
+}
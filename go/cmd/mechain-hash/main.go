@@ -0,0 +1,324 @@
+// mechain-hash computes the integrity hashes of files, of the files of directories or of the standard input, to
+// investigate the integrity hash mismatches reported for objects.
+//
+// Usage:
+//
+//	mechain-hash [-segment-size 16777216] [-data 4] [-parity 2] [-format hex|base64|json] [-all]
+//		[-expect checksums | -manifest file] [path ...]
+//
+// The standard input is hashed without path or for the path "-", the regular files of a directory are hashed
+// recursively. The hex and base64 formats print the integrity hash of the PrimarySP and the path of each object, -all
+// prints the comma separated integrity hashes of the PrimarySP and of the SecondarySPs instead. The json format prints
+// a JSON object per line, a manifest is such an output.
+//
+// -expect verifies a single object against comma separated hex or base64 integrity hashes, the one of the PrimarySP
+// first, the following ones are optional. -manifest verifies the objects against the results of a manifest with the
+// same paths. The exit code is 1 if an object can not be hashed or differs from its expected integrity hashes.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+const (
+	formatHex    = "hex"
+	formatBase64 = "base64"
+	formatJSON   = "json"
+)
+
+type config struct {
+	params redundancy.RedundancyParams
+	format string
+	all    bool
+	// expect are the expected integrity hashes of the single object
+	expect [][]byte
+	// manifest are the expected results by path
+	manifest map[string]*hash.HashResult
+}
+
+// result is the result of an object, the json format prints one per line
+type result struct {
+	Path string `json:"path"`
+	*hash.HashResult
+	// Valid is set if the object is verified
+	Valid *bool `json:"valid,omitempty"`
+	// Errors explains why the object is not valid
+	Errors []string `json:"errors,omitempty"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs mechain-hash with the command line arguments and return its exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var (
+		conf                     config
+		segmentSize              int64
+		dataShards, parityShards int
+		expect, manifest         string
+		err                      error
+	)
+	flags := flag.NewFlagSet("mechain-hash", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Int64Var(&segmentSize, "segment-size", 16*1024*1024, "segment size in bytes")
+	flags.IntVar(&dataShards, "data", redundancy.DataBlocks, "number of data shards")
+	flags.IntVar(&parityShards, "parity", redundancy.ParityBlocks, "number of parity shards")
+	flags.StringVar(&conf.format, "format", formatHex, "output format: hex, base64 or json")
+	flags.BoolVar(&conf.all, "all", false, "print the integrity hashes of the SecondarySPs too")
+	flags.StringVar(&expect, "expect", "", "comma separated expected integrity hashes of a single object")
+	flags.StringVar(&manifest, "manifest", "", "manifest of the expected integrity hashes by path")
+	if err = flags.Parse(args); err != nil {
+		return 2
+	}
+
+	usageError := func(format string, args ...interface{}) int {
+		fmt.Fprintf(stderr, format+"\n", args...)
+		return 2
+	}
+	if conf.params, err = redundancy.NewRedundancyParams(segmentSize, dataShards, parityShards); err != nil {
+		return usageError("%s", err)
+	}
+	if conf.format != formatHex && conf.format != formatBase64 && conf.format != formatJSON {
+		return usageError("unknown format %q", conf.format)
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	if expect != "" && manifest != "" {
+		return usageError("-expect and -manifest are exclusive")
+	}
+	if expect != "" {
+		if len(paths) != 1 {
+			return usageError("-expect verifies a single object")
+		}
+		if conf.expect, err = parseChecksums(expect); err != nil {
+			return usageError("invalid expected integrity hashes: %s", err)
+		}
+	}
+	if manifest != "" {
+		if conf.manifest, err = loadManifest(manifest); err != nil {
+			return usageError("invalid manifest: %s", err)
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	exitCode := 0
+	report := func(path string, reader io.Reader) {
+		res, err := hashObject(conf, path, reader)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", path, err)
+			exitCode = 1
+			return
+		}
+		if res.Valid != nil && !*res.Valid {
+			exitCode = 1
+		}
+		if err = printResult(out, conf, res); err != nil {
+			fmt.Fprintf(stderr, "failed to print the result: %s\n", err)
+			exitCode = 1
+		}
+	}
+	for _, path := range paths {
+		if path == "-" {
+			report(path, stdin)
+			continue
+		}
+		if err = walk(path, conf.expect != nil, report); err != nil {
+			fmt.Fprintf(stderr, "%s: %s\n", path, err)
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// walk reports path if it is a file, or the regular files of path if it is a directory, the symlinks within the
+// directory are not followed
+func walk(path string, single bool, report func(path string, reader io.Reader)) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return reportFile(path, report)
+	}
+	if single {
+		return errors.New("-expect verifies a single object, not a directory")
+	}
+	return filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		return reportFile(path, report)
+	})
+}
+
+// reportFile reports the file at path
+func reportFile(path string, report func(path string, reader io.Reader)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	report(path, file)
+	return nil
+}
+
+// hashObject computes the result of the object read from reader and verifies it if expected results are configured
+func hashObject(conf config, path string, reader io.Reader) (*result, error) {
+	hashResult, err := hash.ComputeIntegrityHashWithParams(reader, conf.params)
+	if err != nil {
+		return nil, err
+	}
+	res := &result{Path: path, HashResult: hashResult}
+	switch {
+	case conf.expect != nil:
+		res.Errors = compareChecksums(conf, conf.expect, hashResult.Checksums)
+	case conf.manifest != nil:
+		expected, ok := conf.manifest[path]
+		if !ok {
+			res.Errors = []string{"not in the manifest"}
+			break
+		}
+		if expected.ContentLength != hashResult.ContentLength {
+			res.Errors = append(res.Errors, fmt.Sprintf("content length: expected %d, got %d",
+				expected.ContentLength, hashResult.ContentLength))
+		}
+		res.Errors = append(res.Errors, compareChecksums(conf, expected.Checksums, hashResult.Checksums)...)
+	default:
+		return res, nil
+	}
+	valid := len(res.Errors) == 0
+	res.Valid = &valid
+	return res, nil
+}
+
+// compareChecksums return the differences between the expected checksums and the computed ones, the computed ones
+// beyond the expected ones are not compared
+func compareChecksums(conf config, expected, checksums [][]byte) []string {
+	var differences []string
+	for i, checksum := range expected {
+		if i >= len(checksums) {
+			differences = append(differences, fmt.Sprintf("%s: expected %s, got none", checksumName(i),
+				encodeChecksum(conf, checksum)))
+		} else if !bytes.Equal(checksum, checksums[i]) {
+			differences = append(differences, fmt.Sprintf("%s: expected %s, got %s", checksumName(i),
+				encodeChecksum(conf, checksum), encodeChecksum(conf, checksums[i])))
+		}
+	}
+	return differences
+}
+
+// checksumName return the name of the integrity hash at index of the checksums of a HashResult
+func checksumName(index int) string {
+	if index == 0 {
+		return "primary integrity hash"
+	}
+	return fmt.Sprintf("secondary integrity hash %d", index-1)
+}
+
+// printResult prints the result in the format of the config
+func printResult(out io.Writer, conf config, res *result) error {
+	if conf.format == formatJSON {
+		line, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(out, "%s\n", line)
+		return err
+	}
+	checksums := res.Checksums
+	if !conf.all {
+		checksums = checksums[:1]
+	}
+	encoded := hash.ChecksumsToHexList(checksums)
+	if conf.format == formatBase64 {
+		encoded = hash.ChecksumsToBase64List(checksums)
+	}
+	if _, err := fmt.Fprintf(out, "%s  %s\n", encoded, res.Path); err != nil {
+		return err
+	}
+	if res.Valid == nil {
+		return nil
+	}
+	if *res.Valid {
+		_, err := fmt.Fprintf(out, "%s: OK\n", res.Path)
+		return err
+	}
+	if _, err := fmt.Fprintf(out, "%s: FAILED\n", res.Path); err != nil {
+		return err
+	}
+	for _, e := range res.Errors {
+		if _, err := fmt.Fprintf(out, "  %s\n", e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeChecksum encodes the checksum in base64 for the base64 format, in hex otherwise
+func encodeChecksum(conf config, checksum []byte) string {
+	if conf.format == formatBase64 {
+		return hash.ChecksumToBase64(checksum)
+	}
+	return hash.ChecksumToHex(checksum)
+}
+
+// parseChecksums parses comma separated checksums, each encoded in hex or in base64
+func parseChecksums(s string) ([][]byte, error) {
+	var checksums [][]byte
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		checksum, err := hash.ParseChecksumHex(item)
+		if err != nil || len(checksum) != sha256.Size {
+			checksum, err = hash.ParseChecksumBase64(item)
+		}
+		if err != nil || len(checksum) != sha256.Size {
+			return nil, fmt.Errorf("%q is not a hex or base64 encoded integrity hash", item)
+		}
+		checksums = append(checksums, checksum)
+	}
+	return checksums, nil
+}
+
+// loadManifest loads the results by path of a manifest printed by the json format
+func loadManifest(path string) (map[string]*hash.HashResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	manifest := make(map[string]*hash.HashResult)
+	decoder := json.NewDecoder(file)
+	for {
+		var res result
+		if err = decoder.Decode(&res); errors.Is(err, io.EOF) {
+			return manifest, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if res.HashResult == nil || len(res.Checksums) == 0 {
+			return nil, fmt.Errorf("no integrity hashes for %q", res.Path)
+		}
+		if err = res.CheckVersion(); err != nil {
+			return nil, fmt.Errorf("%q: %w", res.Path, err)
+		}
+		manifest[res.Path] = res.HashResult
+	}
+}
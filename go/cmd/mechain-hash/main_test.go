@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// runArgs runs mechain-hash and return its exit code, stdout and stderr
+func runArgs(stdin []byte, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	exitCode := run(args, bytes.NewReader(stdin), &stdout, &stderr)
+	return exitCode, stdout.String(), stderr.String()
+}
+
+func TestRun(t *testing.T) {
+	object := bytes.Repeat([]byte("mechain"), 3000)
+	params, err := redundancy.NewRedundancyParams(4096, 4, 2)
+	require.NoError(t, err)
+	expected, err := hash.ComputeIntegrityHashWithParams(bytes.NewReader(object), params)
+	require.NoError(t, err)
+	primary := hex.EncodeToString(expected.PrimaryChecksum())
+
+	// standard input
+	exitCode, stdout, stderr := runArgs(object, "-segment-size", "4096")
+	require.Zero(t, exitCode, stderr)
+	assert.Equal(t, primary+"  -\n", stdout)
+
+	// all the integrity hashes are accepted by -expect
+	exitCode, stdout, _ = runArgs(object, "-segment-size", "4096", "-all")
+	require.Zero(t, exitCode)
+	all := strings.Fields(stdout)[0]
+	assert.Len(t, strings.Split(all, ","), 7)
+	exitCode, stdout, _ = runArgs(object, "-segment-size", "4096", "-expect", all)
+	assert.Zero(t, exitCode)
+	assert.Contains(t, stdout, "-: OK\n")
+
+	// a different segment size differs from the expected primary integrity hash
+	exitCode, stdout, _ = runArgs(object, "-expect", primary)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout, "-: FAILED\n  primary integrity hash: expected "+primary)
+
+	// usage errors
+	exitCode, _, _ = runArgs(object, "-format", "yaml")
+	assert.Equal(t, 2, exitCode)
+	exitCode, _, _ = runArgs(object, "-expect", "not a hash")
+	assert.Equal(t, 2, exitCode)
+	exitCode, _, _ = runArgs(object, "-parity", "0")
+	assert.Equal(t, 2, exitCode)
+}
+
+func TestRunManifest(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a"), []byte("first object"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b"), bytes.Repeat([]byte{7}, 10000), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty"), nil, 0o600))
+
+	// the json output of a directory is a manifest
+	exitCode, manifest, stderr := runArgs(nil, "-format", "json", "-segment-size", "4096", dir)
+	require.Zero(t, exitCode, stderr)
+	assert.Len(t, strings.Split(strings.TrimSpace(manifest), "\n"), 3)
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(manifestPath, []byte(manifest), 0o600))
+
+	exitCode, stdout, stderr := runArgs(nil, "-segment-size", "4096", "-manifest", manifestPath, dir)
+	require.Zero(t, exitCode, stderr)
+	assert.Equal(t, 3, strings.Count(stdout, ": OK\n"))
+
+	// a modified object
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b"), bytes.Repeat([]byte{8}, 10001), 0o600))
+	exitCode, stdout, _ = runArgs(nil, "-segment-size", "4096", "-manifest", manifestPath, "-format", "json", dir)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stdout, `"valid":false`)
+	assert.Contains(t, stdout, "content length: expected 10000, got 10001")
+
+	// -expect does not verify directories
+	exitCode, _, stderr = runArgs(nil, "-expect", strings.Repeat("00", 32), dir)
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, stderr, "single object")
+}
@@ -0,0 +1,289 @@
+// Package compression compresses the objects before they are split into pieces. The content is compressed by chunks
+// with a pluggable Codec, each chunk stored in a frame of the compressed stream, and the chunks which do not compress
+// are stored raw, so the integrity hashes and the erasure coding are computed over the compressed stream unchanged.
+package compression
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+const (
+	// DeflateCodec is the name of the deflate codec of the standard library
+	DeflateCodec = "deflate"
+	// frameHeaderSize is the size of the header of the frames: the kind of frame, the size of the chunk and the size
+	// of the frame data
+	frameHeaderSize = 9
+	// sampleSize is the size of the prefix of the chunks whose entropy is estimated
+	sampleSize = 4096
+	// maxEntropy is the entropy in bits per byte above which a chunk is considered incompressible
+	maxEntropy = 7.5
+)
+
+const (
+	frameRaw byte = iota
+	frameCompressed
+)
+
+var (
+	// ErrUnknownCodec is returned when no codec is registered under a name
+	ErrUnknownCodec = errors.New("unknown compression codec")
+	// ErrCorruptedStream is returned when a compressed stream can not be decompressed
+	ErrCorruptedStream = errors.New("corrupted compressed stream")
+)
+
+// Codec compresses and decompresses the chunks of the objects, e.g. zstd or lz4
+type Codec interface {
+	// Name return the name of the codec recorded in the Info of the objects
+	Name() string
+	// Compress return the compressed chunk
+	Compress(chunk []byte) ([]byte, error)
+	// Decompress return the chunk of size bytes of the compressed data
+	Decompress(data []byte, size int) ([]byte, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{DeflateCodec: deflateCodec{}}
+)
+
+// RegisterCodec makes the codec available to decompress the objects compressed with it, replacing the codec of the
+// same name
+func RegisterCodec(codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[codec.Name()] = codec
+}
+
+// LookupCodec return the codec registered under the name
+func LookupCodec(name string) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, name)
+	}
+	return codec, nil
+}
+
+// Deflate return the deflate codec of the standard library
+func Deflate() Codec {
+	return deflateCodec{}
+}
+
+type deflateCodec struct{}
+
+// Name implements Codec
+func (deflateCodec) Name() string {
+	return DeflateCodec
+}
+
+// Compress implements Codec
+func (deflateCodec) Compress(chunk []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = writer.Write(chunk); err != nil {
+		return nil, err
+	}
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress implements Codec
+func (deflateCodec) Decompress(data []byte, size int) ([]byte, error) {
+	chunk := make([]byte, size)
+	reader := flate.NewReader(bytes.NewReader(data))
+	defer reader.Close()
+	if _, err := io.ReadFull(reader, chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// Info is the compression metadata of an object, stored with the object to decompress it
+type Info struct {
+	Codec string `json:"codec"`
+	// ChunkSize is the size of the chunks compressed on their own
+	ChunkSize int64 `json:"chunk_size"`
+	// ContentLength is the size of the uncompressed content
+	ContentLength int64 `json:"content_length"`
+	// CompressedChunks is the number of chunks stored compressed, the other ones are stored raw
+	CompressedChunks int64 `json:"compressed_chunks"`
+}
+
+// Reader compresses the content read from another reader into a stream of frames
+type Reader struct {
+	codec  Codec
+	reader io.Reader
+	info   Info
+	buf    []byte
+	err    error
+}
+
+// NewReader return a Reader compressing the content of r by chunks of chunkSize bytes with the codec
+func NewReader(codec Codec, chunkSize int64, r io.Reader) (*Reader, error) {
+	if chunkSize <= 0 || chunkSize > math.MaxUint32 {
+		return nil, fmt.Errorf("invalid compression chunk size %d", chunkSize)
+	}
+	return &Reader{codec: codec, reader: r, info: Info{Codec: codec.Name(), ChunkSize: chunkSize}}, nil
+}
+
+// Info return the compression metadata of the content read so far, which is the metadata of the object once Read
+// returned io.EOF
+func (r *Reader) Info() Info {
+	return r.info
+}
+
+// Read implements io.Reader
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.frame()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// frame reads and compresses the next chunk, it return io.EOF at the end of the content
+func (r *Reader) frame() error {
+	chunk := make([]byte, r.info.ChunkSize)
+	n, err := io.ReadFull(r.reader, chunk)
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	chunk = chunk[:n]
+	r.info.ContentLength += int64(n)
+
+	kind, data := frameRaw, chunk
+	if !incompressible(chunk) {
+		compressed, err := r.codec.Compress(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to compress with %s: %w", r.codec.Name(), err)
+		}
+		// the compressed chunk must save at least 1/16 of its size to be worth decompressing
+		if len(compressed) < len(chunk)-len(chunk)/16 {
+			kind, data = frameCompressed, compressed
+			r.info.CompressedChunks++
+		}
+	}
+	r.buf = make([]byte, frameHeaderSize, frameHeaderSize+len(data))
+	r.buf[0] = kind
+	binary.BigEndian.PutUint32(r.buf[1:5], uint32(len(chunk)))
+	binary.BigEndian.PutUint32(r.buf[5:9], uint32(len(data)))
+	r.buf = append(r.buf, data...)
+	return nil
+}
+
+// incompressible estimates whether the chunk does not compress from the entropy of its prefix, e.g. for content
+// already compressed or encrypted
+func incompressible(chunk []byte) bool {
+	sample := chunk[:min(len(chunk), sampleSize)]
+	if len(sample) == 0 {
+		return true
+	}
+	var counts [256]int
+	for _, b := range sample {
+		counts[b]++
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(len(sample))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	// a short sample can not reach the entropy of its alphabet, e.g. 8 bits per byte needs 256 bytes
+	return entropy > min(maxEntropy, math.Log2(float64(len(sample)))-0.5)
+}
+
+// Writer decompresses the stream of frames written to it into another writer
+type Writer struct {
+	codec  Codec
+	info   Info
+	writer io.Writer
+	buf    []byte
+	// written is the size of the content decompressed so far
+	written int64
+}
+
+// NewWriter return a Writer decompressing the stream of the object of info into w. Close must be called after the
+// last write, it fails if the stream is truncated.
+func NewWriter(info Info, w io.Writer) (*Writer, error) {
+	codec, err := LookupCodec(info.Codec)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{codec: codec, info: info, writer: w}, nil
+}
+
+// Write implements io.Writer
+func (w *Writer) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= frameHeaderSize {
+		kind := w.buf[0]
+		size := int64(binary.BigEndian.Uint32(w.buf[1:5]))
+		dataSize := int(binary.BigEndian.Uint32(w.buf[5:9]))
+		if size > w.info.ChunkSize || w.written+size > w.info.ContentLength || int64(dataSize) > size {
+			return 0, fmt.Errorf("%w: chunk of %d bytes at %d", ErrCorruptedStream, size, w.written)
+		}
+		if len(w.buf) < frameHeaderSize+dataSize {
+			break
+		}
+		data := w.buf[frameHeaderSize : frameHeaderSize+dataSize]
+		chunk, err := w.chunk(kind, data, int(size))
+		if err != nil {
+			return 0, err
+		}
+		if _, err = w.writer.Write(chunk); err != nil {
+			return 0, err
+		}
+		w.written += size
+		w.buf = w.buf[frameHeaderSize+dataSize:]
+	}
+	return len(p), nil
+}
+
+// chunk return the content of the frame
+func (w *Writer) chunk(kind byte, data []byte, size int) ([]byte, error) {
+	switch kind {
+	case frameRaw:
+		if len(data) != size {
+			return nil, fmt.Errorf("%w: raw chunk of %d bytes, expect %d", ErrCorruptedStream, len(data), size)
+		}
+		return data, nil
+	case frameCompressed:
+		chunk, err := w.codec.Decompress(data, size)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptedStream, err)
+		}
+		return chunk, nil
+	default:
+		return nil, fmt.Errorf("%w: frame kind %d", ErrCorruptedStream, kind)
+	}
+}
+
+// Close implements io.Closer, it return ErrCorruptedStream if the content is not complete
+func (w *Writer) Close() error {
+	if len(w.buf) > 0 || w.written != w.info.ContentLength {
+		return fmt.Errorf("%w: %d bytes decompressed, expect %d", ErrCorruptedStream, w.written,
+			w.info.ContentLength)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package compression
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// compress return the compressed stream of the content and its metadata
+func compress(t *testing.T, codec Codec, chunkSize int64, content []byte) ([]byte, Info) {
+	reader, err := NewReader(codec, chunkSize, iotest.HalfReader(bytes.NewReader(content)))
+	require.NoError(t, err)
+	stream, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return stream, reader.Info()
+}
+
+// decompress return the content of the stream written in chunks of chunkSize bytes
+func decompress(info Info, stream []byte, chunkSize int) ([]byte, error) {
+	var content bytes.Buffer
+	writer, err := NewWriter(info, &content)
+	if err != nil {
+		return nil, err
+	}
+	for start := 0; start < len(stream); start += chunkSize {
+		if _, err = writer.Write(stream[start:min(start+chunkSize, len(stream))]); err != nil {
+			return content.Bytes(), err
+		}
+	}
+	err = writer.Close()
+	return content.Bytes(), err
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	data := make([]byte, size)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+	return data
+}
+
+func TestCompression(t *testing.T) {
+	text := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog "), 500)
+	random := randomBytes(t, 20000)
+	for _, tc := range []struct {
+		name       string
+		content    []byte
+		compressed int64
+	}{
+		{"empty", nil, 0},
+		{"text", text, 3},
+		{"random", random, 0},
+		{"mixed", append(bytes.Clone(text[:8192]), random[:8192]...), 1},
+	} {
+		stream, info := compress(t, Deflate(), 8192, tc.content)
+		assert.Equal(t, Info{Codec: DeflateCodec, ChunkSize: 8192, ContentLength: int64(len(tc.content)),
+			CompressedChunks: tc.compressed}, info, tc.name)
+		if tc.name == "text" {
+			assert.Less(t, len(stream), len(tc.content)/10)
+		}
+		if tc.name == "random" {
+			assert.Equal(t, len(tc.content)+3*frameHeaderSize, len(stream))
+		}
+		for _, chunkSize := range []int{1, 100, 1 << 20} {
+			content, err := decompress(info, stream, chunkSize)
+			require.NoError(t, err, tc.name)
+			assert.True(t, bytes.Equal(tc.content, content), tc.name)
+		}
+	}
+}
+
+func TestIncompressible(t *testing.T) {
+	assert.True(t, incompressible(nil))
+	assert.True(t, incompressible(randomBytes(t, 100)))
+	assert.True(t, incompressible(randomBytes(t, 10000)))
+	assert.False(t, incompressible(bytes.Repeat([]byte("abc"), 100)))
+	assert.False(t, incompressible([]byte("a short sentence of plain text, which compresses")))
+}
+
+func TestCorruptedStream(t *testing.T) {
+	text := bytes.Repeat([]byte("compressible "), 2000)
+	stream, info := compress(t, Deflate(), 4096, text)
+
+	_, err := decompress(info, stream[:len(stream)-1], 100)
+	assert.ErrorIs(t, err, ErrCorruptedStream)
+	corrupted := bytes.Clone(stream)
+	corrupted[0] = 7
+	_, err = decompress(info, corrupted, 100)
+	assert.ErrorIs(t, err, ErrCorruptedStream)
+	corrupted = bytes.Clone(stream)
+	corrupted[frameHeaderSize+2] ^= 0xff
+	_, err = decompress(info, corrupted, 100)
+	assert.ErrorIs(t, err, ErrCorruptedStream)
+	longer := info
+	longer.ContentLength++
+	_, err = decompress(longer, stream, 100)
+	assert.ErrorIs(t, err, ErrCorruptedStream)
+
+	unknown := info
+	unknown.Codec = "zstd"
+	_, err = NewWriter(unknown, io.Discard)
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+	_, err = NewReader(Deflate(), 0, bytes.NewReader(text))
+	assert.Error(t, err)
+}
+
+// reverseCodec is a test codec storing the chunks reversed
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string { return "reverse" }
+
+func (reverseCodec) Compress(chunk []byte) ([]byte, error) {
+	compressed := make([]byte, 0, len(chunk)/2)
+	for i := len(chunk) - 1; i >= 0 && i >= len(chunk)/2; i-- {
+		compressed = append(compressed, chunk[i])
+	}
+	return compressed, nil
+}
+
+func (reverseCodec) Decompress(data []byte, size int) ([]byte, error) {
+	chunk := make([]byte, size)
+	for i, b := range data {
+		chunk[size-1-i] = b
+		chunk[size-1-i-len(data)] = b
+	}
+	return chunk, nil
+}
+
+func TestRegisterCodec(t *testing.T) {
+	_, err := LookupCodec("reverse")
+	assert.ErrorIs(t, err, ErrUnknownCodec)
+	RegisterCodec(reverseCodec{})
+	codec, err := LookupCodec("reverse")
+	require.NoError(t, err)
+
+	// a chunk made of two equal halves is "compressed" by the codec
+	content := bytes.Repeat([]byte("abcdefgh"), 64)
+	stream, info := compress(t, codec, 256, content)
+	assert.Equal(t, int64(2), info.CompressedChunks)
+	decompressed, err := decompress(info, stream, 10)
+	require.NoError(t, err)
+	assert.Equal(t, content, decompressed)
+}
@@ -0,0 +1,219 @@
+// Package config loads the configuration of a service into a struct from a TOML, YAML or JSON file overridden by
+// environment variables, resolves its secrets, validates it and dumps the effective configuration:
+//
+//	type Config struct {
+//		Address  string          `toml:"address" yaml:"address" json:"address" validate:"required"`
+//		Timeout  config.Duration `toml:"timeout" yaml:"timeout" json:"timeout" validate:"min=1s"`
+//		Password string          `toml:"password" yaml:"password" json:"password" secret:"true"`
+//	}
+//	cfg := Config{Timeout: config.Duration(10 * time.Second)}
+//	err := config.Load("sp.toml", &cfg, config.WithEnvPrefix("SP"))
+//
+// The value passed to Load holds the defaults, the file only replaces the keys it sets. Then every field is
+// overridden by its environment variable, SP_ADDRESS, SP_TIMEOUT and SP_PASSWORD above, see the env tag. The fields
+// tagged secret may refer to their value by "file:<path>" or "env:<name>" and are redacted by Dump. Finally the
+// fields are validated by their validate tag and the structs implementing Validator are validated.
+//
+// Each format reads the keys of the fields from its own tag only, the toml tag in the TOML files, the yaml tag in the
+// YAML files and the json tag in the JSON files, the decoders name the other fields after the field name. A field
+// loaded from several formats needs a tag per format. The environment variables and the error messages name the fields by the key of
+// their toml, yaml or json tag, in that order, or by their name. A nil pointer to a struct is left nil.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the format of a configuration file
+type Format string
+
+const (
+	// TOML is the format of the .toml files
+	TOML Format = "toml"
+	// YAML is the format of the .yaml and .yml files
+	YAML Format = "yaml"
+	// JSON is the format of the .json files
+	JSON Format = "json"
+)
+
+// FormatOf return the format of the file by its extension
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return TOML, nil
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	default:
+		return "", fmt.Errorf("unknown format of configuration file %s", path)
+	}
+}
+
+// Duration is a time.Duration written as a string in the configuration files, e.g. "1m30s"
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// String implements fmt.Stringer
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Validator is implemented by the configuration structs with checks beyond the validate tags, e.g. between fields.
+// Validate is called on every struct of the configuration implementing it.
+type Validator interface {
+	Validate() error
+}
+
+type options struct {
+	format    Format
+	envPrefix string
+	lookupEnv func(key string) (string, bool)
+}
+
+// Option configures Load
+type Option func(*options)
+
+// WithFormat decodes the file in the format rather than the format of its extension
+func WithFormat(format Format) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithEnvPrefix prefixes the names of the environment variables overriding the fields with prefix and an underscore
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithLookupEnv looks the environment variables up by lookup rather than os.LookupEnv
+func WithLookupEnv(lookup func(key string) (string, bool)) Option {
+	return func(o *options) {
+		o.lookupEnv = lookup
+	}
+}
+
+// Load loads the configuration of the file into out, a pointer to a struct, overrides it by the environment, resolves
+// its secrets and validates it. The file is skipped if path is empty. The relative paths of the secret files are
+// relative to the directory of the file.
+func Load(path string, out interface{}, opts ...Option) error {
+	o := options{lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	dir := ""
+	if path != "" {
+		format := o.format
+		if format == "" {
+			var err error
+			if format, err = FormatOf(path); err != nil {
+				return err
+			}
+		}
+		data, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return fmt.Errorf("read configuration: %w", err)
+		}
+		if err = Decode(data, format, out); err != nil {
+			return fmt.Errorf("decode configuration %s: %w", path, err)
+		}
+		dir = filepath.Dir(path)
+	}
+	v, err := structValue(out)
+	if err != nil {
+		return err
+	}
+	if err = applyEnv(v, o.envPrefix, o.lookupEnv); err != nil {
+		return err
+	}
+	if err = resolveSecrets(v, dir, o.lookupEnv); err != nil {
+		return err
+	}
+	return Validate(out)
+}
+
+// Decode decodes the configuration in the format into out with the tags of the format, the keys unknown to out are
+// rejected
+func Decode(data []byte, format Format, out interface{}) error {
+	switch format {
+	case TOML:
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(out)
+		var strictErr *toml.StrictMissingError
+		if errors.As(err, &strictErr) {
+			return fmt.Errorf("%w\n%s", err, strictErr.String())
+		}
+		return err
+	case YAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(out); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+	case JSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		return dec.Decode(out)
+	default:
+		return fmt.Errorf("unknown configuration format %q", format)
+	}
+}
+
+// Redacted replaces the values of the secrets in the dumps
+const Redacted = "<redacted>"
+
+// Dump writes the configuration in the format with its secrets redacted, e.g. to log the effective configuration of
+// a service at startup
+func Dump(w io.Writer, cfg interface{}, format Format) error {
+	v, err := structValue(cfg)
+	if err != nil {
+		return err
+	}
+	redacted := redact(v)
+	var data []byte
+	switch format {
+	case TOML:
+		data, err = toml.Marshal(redacted.Interface())
+	case YAML:
+		data, err = yaml.Marshal(redacted.Interface())
+	case JSON:
+		data, err = json.MarshalIndent(redacted.Interface(), "", "  ")
+		data = append(data, '\n')
+	default:
+		err = fmt.Errorf("unknown configuration format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type storeConfig struct {
+	Path   string `toml:"path" yaml:"path" json:"path" validate:"required"`
+	Shards int    `toml:"shards" yaml:"shards" json:"shards" validate:"min=1,max=64"`
+}
+
+type testConfig struct {
+	Address  string       `toml:"address" yaml:"address" json:"address" validate:"required"`
+	Timeout  Duration     `toml:"timeout" yaml:"timeout" json:"timeout" validate:"min=1s"`
+	Mode     string       `toml:"mode" yaml:"mode" json:"mode" validate:"oneof=primary secondary"`
+	Peers    []string     `toml:"peers" yaml:"peers" json:"peers"`
+	Password string       `toml:"password" yaml:"password" json:"password" secret:"true"`
+	Store    storeConfig  `toml:"store" yaml:"store" json:"store"`
+	Cache    *storeConfig `toml:"cache,omitempty" yaml:"cache,omitempty" json:"cache,omitempty"`
+}
+
+func defaultConfig() testConfig {
+	return testConfig{Timeout: Duration(10 * time.Second), Mode: "primary", Store: storeConfig{Shards: 4}}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func noEnv(string) (string, bool) {
+	return "", false
+}
+
+func TestLoad(t *testing.T) {
+	files := map[string]string{
+		"sp.toml": "address = \"0.0.0.0:9033\"\ntimeout = \"1m\"\npeers = [\"a\", \"b\"]\n[store]\npath = \"/data\"\n",
+		"sp.yaml": "address: 0.0.0.0:9033\ntimeout: 1m\npeers: [a, b]\nstore:\n  path: /data\n",
+		"sp.json": `{"address": "0.0.0.0:9033", "timeout": "1m", "peers": ["a", "b"], "store": {"path": "/data"}}`,
+	}
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultConfig()
+			require.NoError(t, Load(writeFile(t, name, content), &cfg, WithLookupEnv(noEnv)))
+			assert.Equal(t, testConfig{Address: "0.0.0.0:9033", Timeout: Duration(time.Minute), Mode: "primary",
+				Peers: []string{"a", "b"}, Store: storeConfig{Path: "/data", Shards: 4}}, cfg)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	cfg := defaultConfig()
+	err := Load(writeFile(t, "sp.toml", "address = \"a\"\nadress = \"b\"\n"), &cfg, WithLookupEnv(noEnv))
+	assert.ErrorContains(t, err, "adress")
+	assert.ErrorContains(t, Load(writeFile(t, "sp.ini", ""), &cfg), "unknown format")
+	assert.NoError(t, Load(writeFile(t, "sp.conf", "address: a\nstore:\n  path: /data\n"), &cfg, WithFormat(YAML),
+		WithLookupEnv(noEnv)))
+	assert.ErrorContains(t, Load("", cfg), "pointer to a struct")
+}
+
+func TestDecodeTags(t *testing.T) {
+	// each format reads its own tag only
+	var cfg struct {
+		SegmentSize int `toml:"segment_size"`
+	}
+	require.NoError(t, Decode([]byte("segment_size = 1\n"), TOML, &cfg))
+	assert.Equal(t, 1, cfg.SegmentSize)
+	assert.Error(t, Decode([]byte("segment_size: 2\n"), YAML, &cfg))
+	require.NoError(t, Decode([]byte("segmentsize: 2\n"), YAML, &cfg))
+	assert.Equal(t, 2, cfg.SegmentSize)
+	assert.Error(t, Decode([]byte(`{"segment_size": 3}`), JSON, &cfg))
+}
+
+func TestDump(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Address, cfg.Password, cfg.Peers = "0.0.0.0:9033", "hunter2", []string{"a"}
+	cfg.Cache = &storeConfig{Path: "/cache", Shards: 1}
+
+	var buf bytes.Buffer
+	require.NoError(t, Dump(&buf, &cfg, TOML))
+	assert.Contains(t, buf.String(), "timeout = '10s'")
+	assert.Contains(t, buf.String(), "password = '<redacted>'")
+	assert.NotContains(t, buf.String(), "hunter2")
+	assert.Equal(t, "hunter2", cfg.Password)
+
+	var dumped testConfig
+	for _, format := range []Format{TOML, YAML, JSON} {
+		buf.Reset()
+		require.NoError(t, Dump(&buf, &cfg, format))
+		require.NoError(t, Decode(buf.Bytes(), format, &dumped), format)
+		expected := cfg
+		expected.Password = Redacted
+		assert.Equal(t, expected, dumped, format)
+	}
+}
@@ -0,0 +1,278 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// field is a leaf field of a configuration
+type field struct {
+	value  reflect.Value
+	tag    reflect.StructTag
+	key    string
+	envKey string
+}
+
+// structValue return the struct pointed by cfg
+func structValue(cfg interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("configuration must be a pointer to a struct, not %T", cfg)
+	}
+	return v.Elem(), nil
+}
+
+// isLeaf reports whether the value of the type is set as a whole rather than by its fields
+func isLeaf(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() != reflect.Struct || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// keyOf return the key of the struct field in the configuration files, or "" if it is skipped
+func keyOf(sf reflect.StructField) string {
+	for _, name := range []string{"toml", "yaml", "json"} {
+		if tag, ok := sf.Tag.Lookup(name); ok {
+			key, _, _ := strings.Cut(tag, ",")
+			if key == "-" {
+				return ""
+			}
+			if key != "" {
+				return key
+			}
+		}
+	}
+	return sf.Name
+}
+
+// envKeyOf return the part of the name of the environment variable of the key, e.g. MAX_CONNS for maxConns
+func envKeyOf(key string) string {
+	var b strings.Builder
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1])):
+			b.WriteByte('_')
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToUpper(r))
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// walk calls fn with the exported leaf fields of the struct, descending into its structs and its non nil pointers to
+// struct. fn is also called with the structs themselves, as fields without tag, if visitStructs is set.
+func walk(v reflect.Value, key, envKey string, visitStructs bool, fn func(f field) error) error {
+	if visitStructs {
+		if err := fn(field{value: v, key: key, envKey: envKey}); err != nil {
+			return err
+		}
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := keyOf(sf)
+		if name == "" {
+			continue
+		}
+		f := field{value: v.Field(i), tag: sf.Tag, key: name, envKey: envKeyOf(name)}
+		if key != "" {
+			f.key = key + "." + f.key
+		}
+		if envKey != "" {
+			f.envKey = envKey + "_" + f.envKey
+		}
+		if isLeaf(sf.Type) {
+			if err := fn(f); err != nil {
+				return err
+			}
+			continue
+		}
+		fv := f.value
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if err := walk(fv, f.key, f.envKey, visitStructs, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEnv overrides the fields by the environment variables prefixed by prefix. The name of the variable of a
+// field is given by its env tag, not prefixed, or by its key.
+func applyEnv(v reflect.Value, prefix string, lookupEnv func(string) (string, bool)) error {
+	return walk(v, "", prefix, false, func(f field) error {
+		name := f.envKey
+		if tag, ok := f.tag.Lookup("env"); ok {
+			if tag == "-" {
+				return nil
+			}
+			name = tag
+		}
+		s, ok := lookupEnv(name)
+		if !ok {
+			return nil
+		}
+		if err := setString(f.value, s); err != nil {
+			return fmt.Errorf("invalid %s of %s: %w", name, f.key, err)
+		}
+		return nil
+	})
+}
+
+// setString sets the value from its representation in an environment variable, the items of a slice are separated
+// by commas
+func setString(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		items := strings.Split(s, ",")
+		if s == "" {
+			items = nil
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setString(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// isSecret reports whether the field is tagged as a secret
+func isSecret(f field) bool {
+	secret, _ := strconv.ParseBool(f.tag.Get("secret"))
+	return secret && f.value.Kind() == reflect.String
+}
+
+// resolveSecrets replaces the secrets referring to a file by "file:<path>" or to an environment variable by
+// "env:<name>" by their value, the relative paths are relative to dir
+func resolveSecrets(v reflect.Value, dir string, lookupEnv func(string) (string, bool)) error {
+	return walk(v, "", "", false, func(f field) error {
+		if !isSecret(f) {
+			return nil
+		}
+		ref := f.value.String()
+		switch {
+		case strings.HasPrefix(ref, "file:"):
+			path := strings.TrimPrefix(ref, "file:")
+			if !filepath.IsAbs(path) && dir != "" {
+				path = filepath.Join(dir, path)
+			}
+			data, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				return fmt.Errorf("read secret %s: %w", f.key, err)
+			}
+			f.value.SetString(strings.TrimRight(string(data), "\r\n"))
+		case strings.HasPrefix(ref, "env:"):
+			name := strings.TrimPrefix(ref, "env:")
+			s, ok := lookupEnv(name)
+			if !ok {
+				return fmt.Errorf("read secret %s: %w", f.key, errors.New("environment variable "+name+" is not set"))
+			}
+			f.value.SetString(s)
+		}
+		return nil
+	})
+}
+
+// redact return a copy of the struct whose non empty secrets are replaced by Redacted
+func redact(v reflect.Value) reflect.Value {
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := c.Field(i)
+		switch {
+		case isSecret(field{value: fv, tag: sf.Tag}):
+			if fv.String() != "" {
+				fv.SetString(Redacted)
+			}
+		case isLeaf(sf.Type):
+		case fv.Kind() == reflect.Pointer:
+			if !fv.IsNil() {
+				p := reflect.New(sf.Type.Elem())
+				p.Elem().Set(redact(fv.Elem()))
+				fv.Set(p)
+			}
+		default:
+			fv.Set(redact(fv))
+		}
+	}
+	return c
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvKeyOf(t *testing.T) {
+	for key, envKey := range map[string]string{
+		"address":   "ADDRESS",
+		"max-conns": "MAX_CONNS",
+		"max_conns": "MAX_CONNS",
+		"MaxConns":  "MAX_CONNS",
+		"HTTPPort":  "HTTP_PORT",
+		"GRPC":      "GRPC",
+	} {
+		assert.Equal(t, envKey, envKeyOf(key), key)
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	type envConfig struct {
+		Address  string        `toml:"address"`
+		Timeout  time.Duration `toml:"timeout"`
+		Interval Duration      `toml:"interval"`
+		Enabled  bool          `toml:"enabled"`
+		Ratio    float64       `toml:"ratio"`
+		Peers    []string      `toml:"peers"`
+		Ports    []uint16      `toml:"ports"`
+		Limit    *int          `toml:"limit"`
+		Token    string        `env:"API_TOKEN"`
+		Store    storeConfig   `toml:"store"`
+	}
+	env := map[string]string{
+		"SP_ADDRESS":      "0.0.0.0:9033",
+		"SP_TIMEOUT":      "5s",
+		"SP_INTERVAL":     "1m",
+		"SP_ENABLED":      "true",
+		"SP_RATIO":        "0.5",
+		"SP_PEERS":        "a, b",
+		"SP_PORTS":        "80,443",
+		"SP_LIMIT":        "0x10",
+		"API_TOKEN":       "token",
+		"SP_STORE_PATH":   "/data",
+		"SP_STORE_SHARDS": "8",
+	}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	var cfg envConfig
+	require.NoError(t, Load("", &cfg, WithEnvPrefix("SP"), WithLookupEnv(lookup)))
+	limit := 16
+	assert.Equal(t, envConfig{Address: "0.0.0.0:9033", Timeout: 5 * time.Second, Interval: Duration(time.Minute),
+		Enabled: true, Ratio: 0.5, Peers: []string{"a", "b"}, Ports: []uint16{80, 443}, Limit: &limit, Token: "token",
+		Store: storeConfig{Path: "/data", Shards: 8}}, cfg)
+
+	env["SP_PORTS"] = "80,100000"
+	assert.ErrorContains(t, Load("", &cfg, WithEnvPrefix("SP"), WithLookupEnv(lookup)), "invalid SP_PORTS of ports")
+}
+
+func TestLoadSecrets(t *testing.T) {
+	type secretConfig struct {
+		Password string `toml:"password" secret:"true"`
+		Key      string `toml:"key" secret:"true"`
+		Plain    string `toml:"plain"`
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "key"), []byte("s3cr3t\n"), 0o600))
+	path := filepath.Join(dir, "sp.toml")
+	content := "password = \"env:PASSWORD\"\nkey = \"file:key\"\nplain = \"env:PASSWORD\"\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	env := map[string]string{"PASSWORD": "hunter2"}
+	lookup := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+
+	var cfg secretConfig
+	require.NoError(t, Load(path, &cfg, WithLookupEnv(lookup)))
+	assert.Equal(t, secretConfig{Password: "hunter2", Key: "s3cr3t", Plain: "env:PASSWORD"}, cfg)
+
+	delete(env, "PASSWORD")
+	assert.ErrorContains(t, Load(path, &cfg, WithLookupEnv(lookup)), "environment variable PASSWORD is not set")
+}
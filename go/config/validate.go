@@ -0,0 +1,149 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Problem is a field of a configuration failing its validation
+type Problem struct {
+	// Key is the dotted key of the field, e.g. "store.shards", or the key of the struct failing Validate
+	Key     string
+	Message string
+}
+
+// ValidationError is returned when a configuration is invalid, it lists all the problems of the configuration
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		if p.Key == "" {
+			messages = append(messages, p.Message)
+			continue
+		}
+		messages = append(messages, p.Key+": "+p.Message)
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Validate validates the configuration, a pointer to a struct, by the validate tags of its fields and the Validate
+// method of its structs. The tag is a comma separated list of rules:
+//
+//	required      the field is not zero
+//	min=<n>       the number or duration is at least n, the string, slice or map has at least n items
+//	max=<n>       the number or duration is at most n, the string, slice or map has at most n items
+//	oneof=<a b c> the field is one of the values separated by spaces
+//
+// It return a *ValidationError listing the problems.
+func Validate(cfg interface{}) error {
+	v, err := structValue(cfg)
+	if err != nil {
+		return err
+	}
+	var problems []Problem
+	err = walk(v, "", "", true, func(f field) error {
+		if f.value.Kind() == reflect.Struct && f.tag == "" {
+			if validator, ok := f.value.Addr().Interface().(Validator); ok {
+				if err := validator.Validate(); err != nil {
+					problems = append(problems, Problem{Key: f.key, Message: err.Error()})
+				}
+			}
+			return nil
+		}
+		tag := f.tag.Get("validate")
+		if tag == "" {
+			return nil
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			message, err := check(f.value, name, arg)
+			if err != nil {
+				return fmt.Errorf("invalid validate tag of %s: %w", f.key, err)
+			}
+			if message != "" {
+				problems = append(problems, Problem{Key: f.key, Message: message})
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// check return the message of the problem of the value with the rule, or "" if it passes
+func check(v reflect.Value, rule, arg string) (string, error) {
+	switch rule {
+	case "required":
+		if v.IsZero() {
+			return "is required", nil
+		}
+		return "", nil
+	case "min", "max":
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return "", nil
+			}
+			v = v.Elem()
+		}
+		n, limit, unit, err := measure(v, arg)
+		if err != nil {
+			return "", err
+		}
+		if rule == "min" && n < limit {
+			return "must be at least " + arg + unit, nil
+		}
+		if rule == "max" && n > limit {
+			return "must be at most " + arg + unit, nil
+		}
+		return "", nil
+	case "oneof":
+		values := strings.Fields(arg)
+		s := fmt.Sprint(v.Interface())
+		for _, value := range values {
+			if s == value {
+				return "", nil
+			}
+		}
+		return fmt.Sprintf("must be one of %s, not %q", strings.Join(values, ", "), s), nil
+	default:
+		return "", fmt.Errorf("unknown rule %q", rule)
+	}
+}
+
+// measure return the value or the length of v and the limit to compare it to, unit describes the limit
+func measure(v reflect.Value, arg string) (n, limit float64, unit string, err error) {
+	if v.Type() == durationType || v.Type() == reflect.TypeOf(Duration(0)) {
+		d, err := time.ParseDuration(arg)
+		return float64(v.Int()), float64(d), "", err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	case reflect.String, reflect.Slice, reflect.Map:
+		n, unit = float64(v.Len()), " items"
+		if v.Kind() == reflect.String {
+			unit = " characters"
+		}
+	default:
+		return 0, 0, "", errors.New("min and max do not apply to " + v.Type().String())
+	}
+	limit, err = strconv.ParseFloat(arg, 64)
+	return n, limit, unit, err
+}
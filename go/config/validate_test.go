@@ -0,0 +1,60 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedConfig struct {
+	Primary   string   `toml:"primary"`
+	Secondary []string `toml:"secondary" validate:"max=2"`
+}
+
+func (c *validatedConfig) Validate() error {
+	for _, secondary := range c.Secondary {
+		if secondary == c.Primary {
+			return errors.New("the primary can not be a secondary")
+		}
+	}
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Timeout = Duration(time.Millisecond)
+	cfg.Mode = "backup"
+	cfg.Store.Shards = 0
+	cfg.Cache = &storeConfig{Path: "/cache", Shards: 100}
+	err := Validate(&cfg)
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, []Problem{
+		{Key: "address", Message: "is required"},
+		{Key: "timeout", Message: "must be at least 1s"},
+		{Key: "mode", Message: `must be one of primary, secondary, not "backup"`},
+		{Key: "store.path", Message: "is required"},
+		{Key: "store.shards", Message: "must be at least 1"},
+		{Key: "cache.shards", Message: "must be at most 64"},
+	}, validationErr.Problems)
+	assert.Equal(t, "invalid configuration: address: is required; timeout: must be at least 1s; "+
+		`mode: must be one of primary, secondary, not "backup"; store.path: is required; store.shards: must be at `+
+		"least 1; cache.shards: must be at most 64", err.Error())
+
+	nested := struct {
+		Replicas validatedConfig `toml:"replicas"`
+	}{Replicas: validatedConfig{Primary: "sp1", Secondary: []string{"sp1", "sp2", "sp3"}}}
+	require.ErrorAs(t, Validate(&nested), &validationErr)
+	assert.Equal(t, []Problem{
+		{Key: "replicas", Message: "the primary can not be a secondary"},
+		{Key: "replicas.secondary", Message: "must be at most 2 items"},
+	}, validationErr.Problems)
+
+	invalid := struct {
+		Name string `validate:"between=1"`
+	}{}
+	assert.ErrorContains(t, Validate(&invalid), `invalid validate tag of Name: unknown rule "between"`)
+}
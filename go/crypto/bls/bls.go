@@ -0,0 +1,112 @@
+// Package bls aggregates and verifies the BLS signatures of the SecondarySPs over the objects they store, with the
+// same keys, domain and sign docs as the checks of the chain, e.g. of SealObject
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	edgebls "github.com/0xPolygon/polygon-edge/bls"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/zkMeLabs/mechain-common/go/canonicaljson"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+var (
+	// ErrInvalidPublicKey is returned when a BLS public key can not be unmarshalled
+	ErrInvalidPublicKey = errors.New("invalid bls public key")
+	// ErrInvalidSignature is returned when a BLS signature can not be unmarshalled
+	ErrInvalidSignature = errors.New("invalid bls signature")
+	// ErrSignatureMismatch is returned when a BLS signature does not match the sign hash and the public keys
+	ErrSignatureMismatch = errors.New("bls signature verification failed")
+)
+
+// DST is the domain separation tag of the BLS signatures verified by the chain
+var DST = crypto.Keccak256([]byte("BLS_SIG_BN254G1_XMD:SHA-256_SVDW_RO_NUL_"))
+
+// sealObjectSignDoc is the SecondarySpSealObjectSignDoc of the chain, signed as canonical json
+type sealObjectSignDoc struct {
+	ChainID              string `json:"chain_id"`
+	Checksum             []byte `json:"checksum"`
+	GlobalVirtualGroupID uint32 `json:"global_virtual_group_id"`
+	ObjectID             string `json:"object_id"`
+}
+
+// SealObjectSignBytes return the sign bytes of the seal of the object by the SecondarySPs of the global virtual
+// group, integrityHashes are the integrity hashes of the object, i.e. the Checksums of its hash.HashResult
+func SealObjectSignBytes(chainID string, gvgID uint32, objectID uint64, integrityHashes [][]byte) []byte {
+	signBytes, err := canonicaljson.Marshal(sealObjectSignDoc{
+		ChainID:              chainID,
+		Checksum:             hash.GenerateIntegrityHash(integrityHashes),
+		GlobalVirtualGroupID: gvgID,
+		ObjectID:             strconv.FormatUint(objectID, 10),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return signBytes
+}
+
+// SealObjectSignHash return the hash of the sign bytes signed by the SecondarySPs to seal the object
+func SealObjectSignHash(chainID string, gvgID uint32, objectID uint64, integrityHashes [][]byte) [32]byte {
+	return crypto.Keccak256Hash(SealObjectSignBytes(chainID, gvgID, objectID, integrityHashes))
+}
+
+// Sign return the BLS signature of the sign hash by the private key
+func Sign(privateKey *edgebls.PrivateKey, signHash [32]byte) ([]byte, error) {
+	signature, err := privateKey.Sign(signHash[:], DST)
+	if err != nil {
+		return nil, err
+	}
+	return signature.Marshal()
+}
+
+// AggregateSignatures aggregates the BLS signatures of the same sign hash into one signature
+func AggregateSignatures(signatures [][]byte) ([]byte, error) {
+	sigs := make(edgebls.Signatures, len(signatures))
+	for index, signature := range signatures {
+		sig, err := edgebls.UnmarshalSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("%w: signature %d: %w", ErrInvalidSignature, index, err)
+		}
+		sigs[index] = sig
+	}
+	return sigs.Aggregate().Marshal()
+}
+
+// VerifySignature verify the BLS signature of the sign hash by the owner of the public key
+func VerifySignature(publicKey []byte, signHash [32]byte, signature []byte) error {
+	return VerifyAggregatedSignature([][]byte{publicKey}, signHash, signature)
+}
+
+// VerifyAggregatedSignature verify the aggregated BLS signature of the sign hash by the owners of the public keys
+func VerifyAggregatedSignature(publicKeys [][]byte, signHash [32]byte, aggSignature []byte) error {
+	pubKeys := make([]*edgebls.PublicKey, len(publicKeys))
+	for index, publicKey := range publicKeys {
+		pubKey, err := edgebls.UnmarshalPublicKey(publicKey)
+		if err != nil {
+			return fmt.Errorf("%w: public key %d: %w", ErrInvalidPublicKey, index, err)
+		}
+		pubKeys[index] = pubKey
+	}
+	sig, err := edgebls.UnmarshalSignature(aggSignature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if !sig.VerifyAggregated(pubKeys, signHash[:], DST) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// VerifySealObject verify the aggregated BLS signature of the SecondarySPs of the global virtual group over the
+// object like the SealObject message of the chain, secondarySPKeys are the BLS public keys of the SecondarySPs
+// ordered as the SecondarySpIds of the global virtual group
+func VerifySealObject(chainID string, gvgID uint32, objectID uint64, integrityHashes [][]byte,
+	secondarySPKeys [][]byte, aggSignature []byte,
+) error {
+	return VerifyAggregatedSignature(secondarySPKeys, SealObjectSignHash(chainID, gvgID, objectID, integrityHashes),
+		aggSignature)
+}
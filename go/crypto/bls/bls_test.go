@@ -0,0 +1,51 @@
+package bls
+
+import (
+	"testing"
+
+	edgebls "github.com/0xPolygon/polygon-edge/bls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+func TestSealObjectSignBytes(t *testing.T) {
+	integrityHashes := [][]byte{hash.GenerateChecksum([]byte("primary")), hash.GenerateChecksum([]byte("secondary"))}
+	signBytes := SealObjectSignBytes("mechain_5151-1", 3, 42, integrityHashes)
+	assert.Equal(t, `{"chain_id":"mechain_5151-1","checksum":"RqIwzAMf+R/1B9/X4Dh1/80uzfeuYdpwIe4IlB/BynY=",`+
+		`"global_virtual_group_id":3,"object_id":"42"}`, string(signBytes))
+}
+
+func TestVerifySealObject(t *testing.T) {
+	keys, err := edgebls.CreateRandomBlsKeys(3)
+	require.NoError(t, err)
+	publicKeys := make([][]byte, len(keys))
+	for index, key := range keys {
+		publicKeys[index] = key.PublicKey().Marshal()
+	}
+
+	integrityHashes := [][]byte{hash.GenerateChecksum([]byte("primary")), hash.GenerateChecksum([]byte("secondary"))}
+	signHash := SealObjectSignHash("mechain_5151-1", 3, 42, integrityHashes)
+	signatures := make([][]byte, len(keys))
+	for index, key := range keys {
+		signatures[index], err = Sign(key, signHash)
+		require.NoError(t, err)
+		assert.NoError(t, VerifySignature(publicKeys[index], signHash, signatures[index]))
+	}
+	aggSignature, err := AggregateSignatures(signatures)
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifySealObject("mechain_5151-1", 3, 42, integrityHashes, publicKeys, aggSignature))
+	assert.ErrorIs(t, VerifySealObject("mechain_5151-1", 3, 43, integrityHashes, publicKeys, aggSignature),
+		ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySealObject("mechain_5151-1", 3, 42, integrityHashes, publicKeys[:2], aggSignature),
+		ErrSignatureMismatch)
+	assert.ErrorIs(t, VerifySignature(publicKeys[0], signHash, signatures[1]), ErrSignatureMismatch)
+
+	assert.ErrorIs(t, VerifySealObject("mechain_5151-1", 3, 42, integrityHashes, [][]byte{{1, 2}}, aggSignature),
+		ErrInvalidPublicKey)
+	assert.ErrorIs(t, VerifySignature(publicKeys[0], signHash, []byte{1, 2}), ErrInvalidSignature)
+	_, err = AggregateSignatures([][]byte{signatures[0], {1}})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
@@ -0,0 +1,289 @@
+package bls
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	edgebls "github.com/0xPolygon/polygon-edge/bls"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// PrivateKeySize is the size of the serialized BLS private keys
+	PrivateKeySize = 32
+	// PublicKeySize is the size of the uncompressed BLS public keys registered on the chain
+	PublicKeySize = edgebls.PublicKeySize
+	// CompressedPublicKeySize is the size of the compressed BLS public keys
+	CompressedPublicKeySize = PublicKeySize / 2
+	// SignatureSize is the size of the uncompressed BLS signatures submitted to the chain
+	SignatureSize = edgebls.SignatureSize
+	// CompressedSignatureSize is the size of the compressed BLS signatures
+	CompressedSignatureSize = SignatureSize / 2
+)
+
+const (
+	// compressedFlag marks the compressed points, the field elements are less than 2^254 so the top two bits of
+	// their encoding are free
+	compressedFlag = 0x80
+	// greaterYFlag marks the compressed points whose y is the greater of the two candidates
+	greaterYFlag = 0x40
+)
+
+var (
+	// ErrInvalidPrivateKey is returned when a BLS private key can not be unmarshalled
+	ErrInvalidPrivateKey = errors.New("invalid bls private key")
+	// ErrInvalidProofOfPossession is returned when a proof of possession does not match its public key
+	ErrInvalidProofOfPossession = errors.New("invalid bls proof of possession")
+)
+
+var (
+	// fieldModulus is the modulus p of the base field of the BN254 curve
+	fieldModulus, _ = new(big.Int).SetString(
+		"21888242871839275222246405745257275088696311157297823662689037894645226208583", 10)
+	// groupOrder is the order of the G1 and G2 groups of the BN254 curve
+	groupOrder, _ = new(big.Int).SetString(
+		"21888242871839275222246405745257275088548364400416034343698204186575808495617", 10)
+	// halfModulus is (p-1)/2, the greatest y of the pair y, p-y
+	halfModulus = new(big.Int).Rsh(fieldModulus, 1)
+	// curveB is the b of the G1 curve y² = x³ + b
+	curveB = big.NewInt(3)
+	// twistB is the b of the G2 twist curve y² = x³ + 3/(9+i)
+	twistB = fp2Mul(fp2{big.NewInt(3), new(big.Int)}, fp2Inverse(fp2{big.NewInt(9), big.NewInt(1)}))
+)
+
+// GenerateKey return a random BLS private key
+func GenerateKey() (*edgebls.PrivateKey, error) {
+	return edgebls.GenerateBlsKey()
+}
+
+// MarshalPrivateKey return the PrivateKeySize bytes big endian encoding of the private key
+func MarshalPrivateKey(privateKey *edgebls.PrivateKey) ([]byte, error) {
+	text, err := privateKey.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	scalar, ok := new(big.Int).SetString(string(text), 16)
+	if !ok || scalar.Sign() <= 0 || scalar.Cmp(groupOrder) >= 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	return scalar.FillBytes(make([]byte, PrivateKeySize)), nil
+}
+
+// UnmarshalPrivateKey parses the encoding of a private key returned by MarshalPrivateKey
+func UnmarshalPrivateKey(data []byte) (*edgebls.PrivateKey, error) {
+	if len(data) != PrivateKeySize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidPrivateKey, len(data))
+	}
+	scalar := new(big.Int).SetBytes(data)
+	if scalar.Sign() == 0 || scalar.Cmp(groupOrder) >= 0 {
+		return nil, fmt.Errorf("%w: scalar out of range", ErrInvalidPrivateKey)
+	}
+	return edgebls.UnmarshalPrivateKey([]byte(scalar.String()))
+}
+
+// ProofOfPossessionHash return the hash signed by the owner of the public key to prove its possession
+func ProofOfPossessionHash(publicKey []byte) [32]byte {
+	return crypto.Keccak256Hash(publicKey)
+}
+
+// ProveProofOfPossession return the proof of possession of the private key, submitted with its public key when the SP
+// registers its BLS key so the keys of aggregated signatures can not be chosen from the keys of others
+func ProveProofOfPossession(privateKey *edgebls.PrivateKey) ([]byte, error) {
+	return Sign(privateKey, ProofOfPossessionHash(privateKey.PublicKey().Marshal()))
+}
+
+// VerifyProofOfPossession verify the proof of possession of the private key of the public key
+func VerifyProofOfPossession(publicKey, proof []byte) error {
+	if err := VerifySignature(publicKey, ProofOfPossessionHash(publicKey), proof); err != nil {
+		if errors.Is(err, ErrSignatureMismatch) {
+			return ErrInvalidProofOfPossession
+		}
+		return err
+	}
+	return nil
+}
+
+// CompressPublicKey return the CompressedPublicKeySize bytes encoding of the public key, its x coordinate flagged
+// with the choice of y
+func CompressPublicKey(publicKey []byte) ([]byte, error) {
+	if _, err := edgebls.UnmarshalPublicKey(publicKey); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	// the coordinates are encoded imaginary part first
+	y := fp2{new(big.Int).SetBytes(publicKey[96:128]), new(big.Int).SetBytes(publicKey[64:96])}
+	compressed := make([]byte, CompressedPublicKeySize)
+	copy(compressed, publicKey[:CompressedPublicKeySize])
+	compressed[0] |= compressedFlag
+	if fp2Greater(y) {
+		compressed[0] |= greaterYFlag
+	}
+	return compressed, nil
+}
+
+// DecompressPublicKey return the uncompressed encoding of a public key compressed by CompressPublicKey
+func DecompressPublicKey(compressed []byte) ([]byte, error) {
+	if len(compressed) != CompressedPublicKeySize || compressed[0]&compressedFlag == 0 {
+		return nil, fmt.Errorf("%w: not a compressed public key", ErrInvalidPublicKey)
+	}
+	greater := compressed[0]&greaterYFlag != 0
+	xBytes := make([]byte, CompressedPublicKeySize)
+	copy(xBytes, compressed)
+	xBytes[0] &^= compressedFlag | greaterYFlag
+	x := fp2{new(big.Int).SetBytes(xBytes[32:]), new(big.Int).SetBytes(xBytes[:32])}
+	if !x.valid() {
+		return nil, fmt.Errorf("%w: coordinate out of range", ErrInvalidPublicKey)
+	}
+	y, ok := fp2Sqrt(fp2Add(fp2Mul(fp2Mul(x, x), x), twistB))
+	if !ok {
+		return nil, fmt.Errorf("%w: not on the curve", ErrInvalidPublicKey)
+	}
+	if fp2Greater(y) != greater {
+		y = fp2Neg(y)
+	}
+	publicKey := make([]byte, PublicKeySize)
+	copy(publicKey, xBytes)
+	y.im.FillBytes(publicKey[64:96])
+	y.re.FillBytes(publicKey[96:128])
+	if _, err := edgebls.UnmarshalPublicKey(publicKey); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPublicKey, err)
+	}
+	return publicKey, nil
+}
+
+// CompressSignature return the CompressedSignatureSize bytes encoding of the signature, its x coordinate flagged with
+// the choice of y
+func CompressSignature(signature []byte) ([]byte, error) {
+	if _, err := edgebls.UnmarshalSignature(signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	compressed := make([]byte, CompressedSignatureSize)
+	copy(compressed, signature[:CompressedSignatureSize])
+	compressed[0] |= compressedFlag
+	if new(big.Int).SetBytes(signature[32:64]).Cmp(halfModulus) > 0 {
+		compressed[0] |= greaterYFlag
+	}
+	return compressed, nil
+}
+
+// DecompressSignature return the uncompressed encoding of a signature compressed by CompressSignature
+func DecompressSignature(compressed []byte) ([]byte, error) {
+	if len(compressed) != CompressedSignatureSize || compressed[0]&compressedFlag == 0 {
+		return nil, fmt.Errorf("%w: not a compressed signature", ErrInvalidSignature)
+	}
+	greater := compressed[0]&greaterYFlag != 0
+	xBytes := make([]byte, CompressedSignatureSize)
+	copy(xBytes, compressed)
+	xBytes[0] &^= compressedFlag | greaterYFlag
+	x := new(big.Int).SetBytes(xBytes)
+	if x.Cmp(fieldModulus) >= 0 {
+		return nil, fmt.Errorf("%w: coordinate out of range", ErrInvalidSignature)
+	}
+	y2 := new(big.Int).Mul(x, x)
+	y2.Mul(y2, x).Add(y2, curveB).Mod(y2, fieldModulus)
+	y := new(big.Int).ModSqrt(y2, fieldModulus)
+	if y == nil {
+		return nil, fmt.Errorf("%w: not on the curve", ErrInvalidSignature)
+	}
+	if (y.Cmp(halfModulus) > 0) != greater {
+		y.Sub(fieldModulus, y)
+	}
+	signature := make([]byte, SignatureSize)
+	copy(signature, xBytes)
+	y.FillBytes(signature[32:])
+	if _, err := edgebls.UnmarshalSignature(signature); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return signature, nil
+}
+
+// fp2 is an element re + im·i of the quadratic extension Fp[i]/(i²+1) of the base field
+type fp2 struct {
+	re, im *big.Int
+}
+
+func (a fp2) valid() bool {
+	return a.re.Cmp(fieldModulus) < 0 && a.im.Cmp(fieldModulus) < 0
+}
+
+func (a fp2) isZero() bool {
+	return a.re.Sign() == 0 && a.im.Sign() == 0
+}
+
+func (a fp2) equal(b fp2) bool {
+	return a.re.Cmp(b.re) == 0 && a.im.Cmp(b.im) == 0
+}
+
+func fp2Add(a, b fp2) fp2 {
+	return fp2{
+		new(big.Int).Mod(new(big.Int).Add(a.re, b.re), fieldModulus),
+		new(big.Int).Mod(new(big.Int).Add(a.im, b.im), fieldModulus),
+	}
+}
+
+func fp2Neg(a fp2) fp2 {
+	return fp2{
+		new(big.Int).Mod(new(big.Int).Neg(a.re), fieldModulus),
+		new(big.Int).Mod(new(big.Int).Neg(a.im), fieldModulus),
+	}
+}
+
+func fp2Mul(a, b fp2) fp2 {
+	re := new(big.Int).Sub(new(big.Int).Mul(a.re, b.re), new(big.Int).Mul(a.im, b.im))
+	im := new(big.Int).Add(new(big.Int).Mul(a.re, b.im), new(big.Int).Mul(a.im, b.re))
+	return fp2{re.Mod(re, fieldModulus), im.Mod(im, fieldModulus)}
+}
+
+func fp2Inverse(a fp2) fp2 {
+	norm := new(big.Int).Add(new(big.Int).Mul(a.re, a.re), new(big.Int).Mul(a.im, a.im))
+	norm.ModInverse(norm.Mod(norm, fieldModulus), fieldModulus)
+	return fp2{
+		new(big.Int).Mod(new(big.Int).Mul(a.re, norm), fieldModulus),
+		new(big.Int).Mod(new(big.Int).Mul(new(big.Int).Neg(a.im), norm), fieldModulus),
+	}
+}
+
+// fp2Sqrt return a square root of a and whether it exists, from the square root of its norm
+func fp2Sqrt(a fp2) (fp2, bool) {
+	if a.isZero() {
+		return a, true
+	}
+	half := new(big.Int).ModInverse(big.NewInt(2), fieldModulus)
+	norm := new(big.Int).Add(new(big.Int).Mul(a.re, a.re), new(big.Int).Mul(a.im, a.im))
+	normRoot := new(big.Int).ModSqrt(norm.Mod(norm, fieldModulus), fieldModulus)
+	if normRoot == nil {
+		return fp2{}, false
+	}
+	// the real part x of the root satisfies x² = (re ± |a|)/2
+	for _, candidate := range []*big.Int{new(big.Int).Add(a.re, normRoot), new(big.Int).Sub(a.re, normRoot)} {
+		candidate.Mul(candidate, half).Mod(candidate, fieldModulus)
+		re := new(big.Int).ModSqrt(candidate, fieldModulus)
+		if re == nil {
+			continue
+		}
+		var root fp2
+		if re.Sign() == 0 {
+			// a is real and its roots are imaginary
+			im := new(big.Int).ModSqrt(new(big.Int).Mod(new(big.Int).Neg(a.re), fieldModulus), fieldModulus)
+			if im == nil {
+				continue
+			}
+			root = fp2{re, im}
+		} else {
+			im := new(big.Int).ModInverse(new(big.Int).Lsh(re, 1), fieldModulus)
+			root = fp2{re, im.Mul(im, a.im).Mod(im, fieldModulus)}
+		}
+		if fp2Mul(root, root).equal(a) {
+			return root, true
+		}
+	}
+	return fp2{}, false
+}
+
+// fp2Greater reports whether y is the greater of y and -y, comparing the imaginary parts first like their encoding
+func fp2Greater(y fp2) bool {
+	if y.im.Sign() != 0 {
+		return y.im.Cmp(halfModulus) > 0
+	}
+	return y.re.Cmp(halfModulus) > 0
+}
@@ -0,0 +1,108 @@
+package bls
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateKeySerialization(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	data, err := MarshalPrivateKey(key)
+	require.NoError(t, err)
+	assert.Len(t, data, PrivateKeySize)
+	parsed, err := UnmarshalPrivateKey(data)
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey().Marshal(), parsed.PublicKey().Marshal())
+
+	_, err = UnmarshalPrivateKey(data[1:])
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+	_, err = UnmarshalPrivateKey(make([]byte, PrivateKeySize))
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+	_, err = UnmarshalPrivateKey(groupOrder.FillBytes(make([]byte, PrivateKeySize)))
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+}
+
+func TestProofOfPossession(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	proof, err := ProveProofOfPossession(key)
+	require.NoError(t, err)
+
+	assert.NoError(t, VerifyProofOfPossession(key.PublicKey().Marshal(), proof))
+	assert.ErrorIs(t, VerifyProofOfPossession(other.PublicKey().Marshal(), proof), ErrInvalidProofOfPossession)
+	assert.ErrorIs(t, VerifyProofOfPossession(key.PublicKey().Marshal(), proof[:10]), ErrInvalidSignature)
+}
+
+func TestCompression(t *testing.T) {
+	var signHash [32]byte
+	for i := 0; i < 16; i++ {
+		key, err := GenerateKey()
+		require.NoError(t, err)
+		publicKey := key.PublicKey().Marshal()
+		compressed, err := CompressPublicKey(publicKey)
+		require.NoError(t, err)
+		assert.Len(t, compressed, CompressedPublicKeySize)
+		decompressed, err := DecompressPublicKey(compressed)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(publicKey, decompressed))
+
+		signHash[0] = byte(i)
+		signature, err := Sign(key, signHash)
+		require.NoError(t, err)
+		compressed, err = CompressSignature(signature)
+		require.NoError(t, err)
+		assert.Len(t, compressed, CompressedSignatureSize)
+		decompressed, err = DecompressSignature(compressed)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(signature, decompressed))
+		assert.NoError(t, VerifySignature(publicKey, signHash, decompressed))
+	}
+}
+
+func TestCompressionErrors(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	compressed, err := CompressPublicKey(key.PublicKey().Marshal())
+	require.NoError(t, err)
+
+	// flipping the choice of y gives the negated key
+	flipped := bytes.Clone(compressed)
+	flipped[0] ^= greaterYFlag
+	decompressed, err := DecompressPublicKey(flipped)
+	require.NoError(t, err)
+	assert.NotEqual(t, key.PublicKey().Marshal(), decompressed)
+
+	_, err = DecompressPublicKey(compressed[1:])
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	uncompressed := bytes.Clone(compressed)
+	uncompressed[0] &^= compressedFlag
+	_, err = DecompressPublicKey(uncompressed)
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	outOfRange := bytes.Repeat([]byte{0xff}, CompressedPublicKeySize)
+	_, err = DecompressPublicKey(outOfRange)
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+	_, err = CompressPublicKey([]byte{1, 2})
+	assert.ErrorIs(t, err, ErrInvalidPublicKey)
+
+	_, err = DecompressSignature(outOfRange[:CompressedSignatureSize])
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	_, err = CompressSignature([]byte{1, 2})
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	// x = 0 gives y² = 3, which is not a square
+	notOnCurve := make([]byte, CompressedSignatureSize)
+	notOnCurve[0] = compressedFlag
+	_, err = DecompressSignature(notOnCurve)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestTwistB(t *testing.T) {
+	// the b of the twist of the bn256 package
+	assert.Equal(t, "19485874751759354771024239261021720505790618469301721065564631296452457478373", twistB.re.String())
+	assert.Equal(t, "266929791119991161246907387137283842545076965332900288569378510910307636690", twistB.im.String())
+}
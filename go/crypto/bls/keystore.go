@@ -0,0 +1,264 @@
+package bls
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	edgebls "github.com/0xPolygon/polygon-edge/bls"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// KeystoreVersion is the version of the EIP-2335 keystores
+	KeystoreVersion = 4
+	// DefaultScryptN is the scrypt cost of the keystores, the one recommended by EIP-2335
+	DefaultScryptN = 1 << 18
+
+	kdfScrypt      = "scrypt"
+	kdfPBKDF2      = "pbkdf2"
+	checksumSHA256 = "sha256"
+	cipherAES128   = "aes-128-ctr"
+	derivedKeySize = 32
+	saltSize       = 32
+)
+
+var (
+	// ErrInvalidKeystore is returned when a keystore is malformed or uses unsupported functions
+	ErrInvalidKeystore = errors.New("invalid bls keystore")
+	// ErrInvalidPassword is returned when the password of a keystore does not match its checksum
+	ErrInvalidPassword = errors.New("invalid bls keystore password")
+)
+
+// KeystoreModule is a function of a keystore with its params and its output
+type KeystoreModule struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+// KeystoreCrypto are the functions encrypting the private key of a keystore
+type KeystoreCrypto struct {
+	KDF      KeystoreModule `json:"kdf"`
+	Checksum KeystoreModule `json:"checksum"`
+	Cipher   KeystoreModule `json:"cipher"`
+}
+
+// Keystore is a BLS private key encrypted with a password in the EIP-2335 layout, the private key and the public key
+// are the BN254 keys of the chain instead of the BLS12-381 keys of the EIP
+type Keystore struct {
+	Crypto      KeystoreCrypto `json:"crypto"`
+	Description string         `json:"description"`
+	PublicKey   string         `json:"pubkey"`
+	Path        string         `json:"path"`
+	UUID        string         `json:"uuid"`
+	Version     int            `json:"version"`
+}
+
+type keystoreOptions struct {
+	kdf         string
+	scryptN     int
+	pbkdf2Count int
+	description string
+}
+
+// KeystoreOption configures the encryption of a keystore
+type KeystoreOption func(*keystoreOptions)
+
+// WithScryptN derives the encryption key with scrypt of cost n, DefaultScryptN by default
+func WithScryptN(n int) KeystoreOption {
+	return func(o *keystoreOptions) {
+		o.kdf, o.scryptN = kdfScrypt, n
+	}
+}
+
+// WithPBKDF2 derives the encryption key with pbkdf2-sha256 of count iterations instead of scrypt
+func WithPBKDF2(count int) KeystoreOption {
+	return func(o *keystoreOptions) {
+		o.kdf, o.pbkdf2Count = kdfPBKDF2, count
+	}
+}
+
+// WithDescription sets the description of the keystore
+func WithDescription(description string) KeystoreOption {
+	return func(o *keystoreOptions) {
+		o.description = description
+	}
+}
+
+// EncryptKey return the keystore of the private key encrypted with the password
+func EncryptKey(privateKey *edgebls.PrivateKey, password string, opts ...KeystoreOption) (*Keystore, error) {
+	options := keystoreOptions{kdf: kdfScrypt, scryptN: DefaultScryptN}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	secret, err := MarshalPrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	random := make([]byte, saltSize+aes.BlockSize+16)
+	if _, err = rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, iv, id := random[:saltSize], random[saltSize:saltSize+aes.BlockSize], random[saltSize+aes.BlockSize:]
+
+	kdf := KeystoreModule{Function: options.kdf, Params: map[string]interface{}{
+		"dklen": derivedKeySize,
+		"salt":  hex.EncodeToString(salt),
+	}}
+	if options.kdf == kdfScrypt {
+		kdf.Params["n"], kdf.Params["r"], kdf.Params["p"] = options.scryptN, 8, 1
+	} else {
+		kdf.Params["c"], kdf.Params["prf"] = options.pbkdf2Count, "hmac-sha256"
+	}
+	// round trip the params through json so they have the types of a parsed keystore
+	if kdf.Params, err = normalizeParams(kdf.Params); err != nil {
+		return nil, err
+	}
+	derivedKey, err := deriveKey(kdf, password)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := aes128CTR(derivedKey[:16], iv, secret)
+	if err != nil {
+		return nil, err
+	}
+	// set the version and the variant of a random UUID
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return &Keystore{
+		Crypto: KeystoreCrypto{
+			KDF: kdf,
+			Checksum: KeystoreModule{Function: checksumSHA256, Params: map[string]interface{}{},
+				Message: hex.EncodeToString(keystoreChecksum(derivedKey, cipherText))},
+			Cipher: KeystoreModule{Function: cipherAES128, Params: map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				Message: hex.EncodeToString(cipherText)},
+		},
+		Description: options.description,
+		PublicKey:   hex.EncodeToString(privateKey.PublicKey().Marshal()),
+		UUID:        fmt.Sprintf("%x-%x-%x-%x-%x", id[:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version:     KeystoreVersion,
+	}, nil
+}
+
+// DecryptKey return the private key of the keystore encrypted with the password
+func DecryptKey(keystore *Keystore, password string) (*edgebls.PrivateKey, error) {
+	if keystore.Version != KeystoreVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidKeystore, keystore.Version)
+	}
+	if keystore.Crypto.Checksum.Function != checksumSHA256 || keystore.Crypto.Cipher.Function != cipherAES128 {
+		return nil, fmt.Errorf("%w: %s checksum, %s cipher", ErrInvalidKeystore, keystore.Crypto.Checksum.Function,
+			keystore.Crypto.Cipher.Function)
+	}
+	checksum, err := hex.DecodeString(keystore.Crypto.Checksum.Message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: checksum: %w", ErrInvalidKeystore, err)
+	}
+	cipherText, err := hex.DecodeString(keystore.Crypto.Cipher.Message)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cipher message: %w", ErrInvalidKeystore, err)
+	}
+	ivHex, _ := keystore.Crypto.Cipher.Params["iv"].(string)
+	iv, err := hex.DecodeString(ivHex)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("%w: cipher iv", ErrInvalidKeystore)
+	}
+	derivedKey, err := deriveKey(keystore.Crypto.KDF, password)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(checksum, keystoreChecksum(derivedKey, cipherText)) {
+		return nil, ErrInvalidPassword
+	}
+	secret, err := aes128CTR(derivedKey[:16], iv, cipherText)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := UnmarshalPrivateKey(secret)
+	if err != nil {
+		return nil, err
+	}
+	if keystore.PublicKey != "" && keystore.PublicKey != hex.EncodeToString(privateKey.PublicKey().Marshal()) {
+		return nil, fmt.Errorf("%w: public key does not match the private key", ErrInvalidKeystore)
+	}
+	return privateKey, nil
+}
+
+// deriveKey return the key derived from the password by the kdf of a keystore
+func deriveKey(kdf KeystoreModule, password string) ([]byte, error) {
+	saltHex, _ := kdf.Params["salt"].(string)
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: kdf salt", ErrInvalidKeystore)
+	}
+	if dkLen, _ := kdf.Params["dklen"].(float64); dkLen != derivedKeySize {
+		return nil, fmt.Errorf("%w: kdf dklen %v", ErrInvalidKeystore, kdf.Params["dklen"])
+	}
+	passwordBytes := normalizePassword(password)
+	switch kdf.Function {
+	case kdfScrypt:
+		n, _ := kdf.Params["n"].(float64)
+		r, _ := kdf.Params["r"].(float64)
+		p, _ := kdf.Params["p"].(float64)
+		derivedKey, err := scrypt.Key(passwordBytes, salt, int(n), int(r), int(p), derivedKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKeystore, err)
+		}
+		return derivedKey, nil
+	case kdfPBKDF2:
+		c, _ := kdf.Params["c"].(float64)
+		if prf, _ := kdf.Params["prf"].(string); prf != "hmac-sha256" || c < 1 {
+			return nil, fmt.Errorf("%w: pbkdf2 prf %q, count %v", ErrInvalidKeystore, prf, kdf.Params["c"])
+		}
+		return pbkdf2.Key(passwordBytes, salt, int(c), derivedKeySize, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("%w: kdf %q", ErrInvalidKeystore, kdf.Function)
+	}
+}
+
+// normalizePassword return the NFKD normalization of the password without its control codes, as EIP-2335
+func normalizePassword(password string) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(password)))
+}
+
+// normalizeParams return the params as parsed from json
+func normalizeParams(params map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	normalized := make(map[string]interface{})
+	return normalized, json.Unmarshal(data, &normalized)
+}
+
+func keystoreChecksum(derivedKey, cipherText []byte) []byte {
+	h := sha256.New()
+	h.Write(derivedKey[16:32])
+	h.Write(cipherText)
+	return h.Sum(nil)
+}
+
+func aes128CTR(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package bls
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeystore(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	for _, opt := range []KeystoreOption{WithScryptN(1 << 10), WithPBKDF2(1000)} {
+		keystore, err := EncryptKey(key, "pässword\u0007", opt, WithDescription("sp seal key"))
+		require.NoError(t, err)
+		assert.Equal(t, KeystoreVersion, keystore.Version)
+		assert.Len(t, keystore.UUID, 36)
+		assert.Equal(t, "sp seal key", keystore.Description)
+
+		// the keystore is decrypted after a json round trip, with the NFKD normalization of the password
+		data, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		var parsed Keystore
+		require.NoError(t, json.Unmarshal(data, &parsed))
+		decrypted, err := DecryptKey(&parsed, "pässword")
+		require.NoError(t, err)
+		assert.Equal(t, key.PublicKey().Marshal(), decrypted.PublicKey().Marshal())
+
+		_, err = DecryptKey(&parsed, "password")
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+	}
+}
+
+func TestKeystoreErrors(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+	keystore, err := EncryptKey(key, "password", WithScryptN(1<<10))
+	require.NoError(t, err)
+
+	version := *keystore
+	version.Version = 3
+	_, err = DecryptKey(&version, "password")
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+
+	kdf := *keystore
+	kdf.Crypto.KDF.Function = "argon2"
+	_, err = DecryptKey(&kdf, "password")
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+
+	other, err := GenerateKey()
+	require.NoError(t, err)
+	otherKeystore, err := EncryptKey(other, "password", WithScryptN(1<<10))
+	require.NoError(t, err)
+	publicKey := *keystore
+	publicKey.PublicKey = otherKeystore.PublicKey
+	_, err = DecryptKey(&publicKey, "password")
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+
+	_, err = EncryptKey(key, "password", WithScryptN(1000))
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+}
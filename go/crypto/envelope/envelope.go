@@ -0,0 +1,260 @@
+// Package envelope encrypts the objects with AES-256-GCM before they are split into pieces. Every object has its own
+// random data key, wrapped by a key encryption key of the caller, e.g. a KMS, and every segment is sealed on its own,
+// so the integrity hashes, the challenges and the erasure coding are computed over the ciphertext unchanged.
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/segment"
+)
+
+const (
+	// Algorithm is the encryption algorithm of the envelopes
+	Algorithm = "AES-256-GCM"
+	// KeySize is the size of the data keys
+	KeySize = 32
+	// NonceSize is the size of the nonces
+	NonceSize = 12
+	// Overhead is the size of the authentication tag appended to every sealed segment
+	Overhead = 16
+)
+
+var (
+	// ErrInvalidEnvelope is returned when an envelope can not be used to decrypt, e.g. of an unknown algorithm
+	ErrInvalidEnvelope = errors.New("invalid envelope")
+	// ErrDecryptFailed is returned when a sealed segment fails its authentication
+	ErrDecryptFailed = errors.New("failed to decrypt segment")
+)
+
+// KeyEncryptionKey wraps and unwraps the data keys of the objects, the wrapped keys are stored with the objects
+type KeyEncryptionKey interface {
+	// WrapKey return the data key encrypted by the key encryption key
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+	// UnwrapKey return the data key of the wrapped key
+	UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// Envelope is the encryption metadata of an object, stored with the object to decrypt it. The segment i is sealed
+// with the nonce Nonce xor i and authenticates its index and whether it is the last segment, so the segments can not
+// be reordered or truncated. An empty object is a single sealed empty segment, so even its truncation to nothing is
+// detected.
+type Envelope struct {
+	Algorithm  string `json:"algorithm"`
+	WrappedKey []byte `json:"wrapped_key"`
+	Nonce      []byte `json:"nonce"`
+	// SegmentSize is the size of the sealed segments, which is the segment size of the redundancy params, their
+	// plaintext is Overhead bytes shorter
+	SegmentSize int64 `json:"segment_size"`
+}
+
+// NewEnvelope generates the data key and the nonce of an object of sealed segments of segmentSize bytes, it return
+// the envelope holding the data key wrapped by kek and the data key
+func NewEnvelope(ctx context.Context, kek KeyEncryptionKey, segmentSize int64) (*Envelope, []byte, error) {
+	if segmentSize <= Overhead {
+		return nil, nil, fmt.Errorf("%w: segment size %d", ErrInvalidEnvelope, segmentSize)
+	}
+	dataKey := make([]byte, KeySize)
+	nonce := make([]byte, NonceSize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+	wrappedKey, err := kek.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to wrap the data key: %w", err)
+	}
+	return &Envelope{Algorithm: Algorithm, WrappedKey: wrappedKey, Nonce: nonce, SegmentSize: segmentSize},
+		dataKey, nil
+}
+
+// DataKey return the data key of the envelope unwrapped by kek
+func (e *Envelope) DataKey(ctx context.Context, kek KeyEncryptionKey) ([]byte, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	dataKey, err := kek.UnwrapKey(ctx, e.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap the data key: %w", err)
+	}
+	if len(dataKey) != KeySize {
+		return nil, fmt.Errorf("%w: %d bytes data key", ErrInvalidEnvelope, len(dataKey))
+	}
+	return dataKey, nil
+}
+
+// validate return ErrInvalidEnvelope if the envelope is not of the algorithm or malformed
+func (e *Envelope) validate() error {
+	switch {
+	case e.Algorithm != Algorithm:
+		return fmt.Errorf("%w: algorithm %q", ErrInvalidEnvelope, e.Algorithm)
+	case len(e.Nonce) != NonceSize:
+		return fmt.Errorf("%w: %d bytes nonce", ErrInvalidEnvelope, len(e.Nonce))
+	case e.SegmentSize <= Overhead:
+		return fmt.Errorf("%w: segment size %d", ErrInvalidEnvelope, e.SegmentSize)
+	}
+	return nil
+}
+
+// PlaintextSegmentSize return the size of the plaintext of the full segments
+func (e *Envelope) PlaintextSegmentSize() int64 {
+	return e.SegmentSize - Overhead
+}
+
+// CiphertextLength return the content length of the object of plaintextLength bytes once encrypted
+func (e *Envelope) CiphertextLength(plaintextLength int64) int64 {
+	plainSegment := e.PlaintextSegmentSize()
+	segments := max((plaintextLength+plainSegment-1)/plainSegment, 1)
+	return plaintextLength + segments*Overhead
+}
+
+// PlaintextLength return the length of the plaintext of the encrypted object of ciphertextLength bytes
+func (e *Envelope) PlaintextLength(ciphertextLength int64) int64 {
+	segments := (ciphertextLength + e.SegmentSize - 1) / e.SegmentSize
+	return ciphertextLength - segments*Overhead
+}
+
+// aead return the AES-256-GCM cipher of the data key
+func (e *Envelope) aead(dataKey []byte) (cipher.AEAD, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce return the nonce of the segment, the last 8 bytes of the nonce of the envelope xor the segment index
+func (e *Envelope) nonce(segIndex uint64) []byte {
+	nonce := bytes.Clone(e.Nonce)
+	binary.BigEndian.PutUint64(nonce[NonceSize-8:], binary.BigEndian.Uint64(nonce[NonceSize-8:])^segIndex)
+	return nonce
+}
+
+// additionalData return the authenticated data of the segment
+func additionalData(segIndex uint64, last bool) []byte {
+	ad := binary.BigEndian.AppendUint64(nil, segIndex)
+	if last {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+// EncryptReader return a reader of the ciphertext of the plaintext read from r, made of sealed segments of the
+// segment size of the envelope except the last one
+func (e *Envelope) EncryptReader(dataKey []byte, r io.Reader) (io.Reader, error) {
+	aead, err := e.aead(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptReader{envelope: e, aead: aead, splitter: segment.SplitBySegment(r, e.PlaintextSegmentSize())},
+		nil
+}
+
+type encryptReader struct {
+	envelope *Envelope
+	aead     cipher.AEAD
+	// splitter reads ahead of the plaintext segments to know whether a segment is the last one
+	splitter *segment.Splitter
+	segIndex uint64
+	buf      []byte
+	err      error
+}
+
+// Read implements io.Reader
+func (r *encryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if err := r.seal(); err != nil {
+			r.err = err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// seal reads and seals the next plaintext segment, it return io.EOF after the last one. An empty plaintext is sealed
+// as an empty last segment.
+func (r *encryptReader) seal() error {
+	_, plaintext, last, err := r.splitter.Next()
+	if errors.Is(err, io.EOF) && r.segIndex == 0 {
+		plaintext, last, err = nil, true, nil
+	}
+	if err != nil {
+		return err
+	}
+	r.buf = r.aead.Seal(nil, r.envelope.nonce(r.segIndex), plaintext, additionalData(r.segIndex, last))
+	r.splitter.Release(plaintext)
+	r.segIndex++
+	return nil
+}
+
+// DecryptWriter return a writer decrypting the ciphertext written to it into w. Close must be called after the
+// last write to decrypt the last segment, it fails if the ciphertext is truncated.
+func (e *Envelope) DecryptWriter(dataKey []byte, w io.Writer) (io.WriteCloser, error) {
+	aead, err := e.aead(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptWriter{envelope: e, aead: aead, writer: w}, nil
+}
+
+type decryptWriter struct {
+	envelope *Envelope
+	aead     cipher.AEAD
+	writer   io.Writer
+	segIndex uint64
+	// buf is the ciphertext not decrypted yet, a full segment is kept until a following byte or Close tells whether
+	// it is the last one
+	buf    []byte
+	closed bool
+}
+
+// Write implements io.Writer
+func (w *decryptWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for int64(len(w.buf)) > w.envelope.SegmentSize {
+		if err := w.open(w.buf[:w.envelope.SegmentSize], false); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[w.envelope.SegmentSize:]
+	}
+	return len(p), nil
+}
+
+// Close implements io.Closer, it decrypts the last segment, which is sealed even for an empty object
+func (w *decryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.open(w.buf, true)
+	w.buf = nil
+	return err
+}
+
+// open decrypts the sealed segment and writes its plaintext
+func (w *decryptWriter) open(sealed []byte, last bool) error {
+	plaintext, err := w.aead.Open(nil, w.envelope.nonce(w.segIndex), sealed, additionalData(w.segIndex, last))
+	if err != nil {
+		return fmt.Errorf("%w %d: %w", ErrDecryptFailed, w.segIndex, err)
+	}
+	w.segIndex++
+	_, err = w.writer.Write(plaintext)
+	return err
+}
@@ -0,0 +1,146 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKEK wraps the data keys with AES-GCM under a fixed key
+type testKEK struct {
+	aead cipher.AEAD
+}
+
+func newTestKEK(t *testing.T) *testKEK {
+	block, err := aes.NewCipher(bytes.Repeat([]byte{7}, KeySize))
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return &testKEK{aead: aead}
+}
+
+func (k *testKEK) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	return k.aead.Seal(nil, make([]byte, NonceSize), dataKey, nil), nil
+}
+
+func (k *testKEK) UnwrapKey(_ context.Context, wrappedKey []byte) ([]byte, error) {
+	return k.aead.Open(nil, make([]byte, NonceSize), wrappedKey, nil)
+}
+
+// encrypt return the ciphertext of the plaintext, read one byte at a time
+func encrypt(t *testing.T, env *Envelope, dataKey, plaintext []byte) []byte {
+	reader, err := env.EncryptReader(dataKey, iotest.OneByteReader(bytes.NewReader(plaintext)))
+	require.NoError(t, err)
+	ciphertext, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return ciphertext
+}
+
+// decrypt return the plaintext of the ciphertext written in chunks of chunkSize bytes
+func decrypt(env *Envelope, dataKey, ciphertext []byte, chunkSize int) ([]byte, error) {
+	var plaintext bytes.Buffer
+	writer, err := env.DecryptWriter(dataKey, &plaintext)
+	if err != nil {
+		return nil, err
+	}
+	for start := 0; start < len(ciphertext); start += chunkSize {
+		if _, err = writer.Write(ciphertext[start:min(start+chunkSize, len(ciphertext))]); err != nil {
+			return plaintext.Bytes(), err
+		}
+	}
+	err = writer.Close()
+	return plaintext.Bytes(), err
+}
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	kek := newTestKEK(t)
+	env, dataKey, err := NewEnvelope(ctx, kek, 64)
+	require.NoError(t, err)
+	assert.Equal(t, Algorithm, env.Algorithm)
+	assert.Len(t, env.Nonce, NonceSize)
+	unwrapped, err := env.DataKey(ctx, kek)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, unwrapped)
+
+	for _, size := range []int{0, 1, 47, 48, 49, 3 * 48, 3*48 + 5} {
+		plaintext := bytes.Repeat([]byte{byte(size)}, size)
+		ciphertext := encrypt(t, env, dataKey, plaintext)
+		assert.Equal(t, env.CiphertextLength(int64(size)), int64(len(ciphertext)), size)
+		assert.Equal(t, int64(size), env.PlaintextLength(int64(len(ciphertext))), size)
+		for _, chunkSize := range []int{1, 64, 100} {
+			decrypted, err := decrypt(env, dataKey, ciphertext, chunkSize)
+			require.NoError(t, err, size)
+			assert.True(t, bytes.Equal(plaintext, decrypted), size)
+		}
+	}
+}
+
+func TestEnvelopeTampering(t *testing.T) {
+	ctx := context.Background()
+	env, dataKey, err := NewEnvelope(ctx, newTestKEK(t), 64)
+	require.NoError(t, err)
+	plaintext := bytes.Repeat([]byte("plaintext"), 20)
+	ciphertext := encrypt(t, env, dataKey, plaintext)
+	require.Len(t, ciphertext, 180+4*Overhead)
+
+	corrupted := bytes.Clone(ciphertext)
+	corrupted[70] ^= 1
+	decrypted, err := decrypt(env, dataKey, corrupted, 64)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+	assert.Equal(t, plaintext[:48], decrypted)
+
+	// the last full segment is not authenticated as the last one
+	_, err = decrypt(env, dataKey, ciphertext[:128], 64)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+	reordered := append(append(bytes.Clone(ciphertext[64:128]), ciphertext[:64]...), ciphertext[128:]...)
+	_, err = decrypt(env, dataKey, reordered, 64)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+	_, err = decrypt(env, bytes.Repeat([]byte{1}, KeySize), ciphertext, 64)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+
+	// the ciphertext truncated to nothing does not decrypt as an empty object
+	_, err = decrypt(env, dataKey, nil, 64)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+	empty := encrypt(t, env, dataKey, nil)
+	assert.Len(t, empty, Overhead)
+	_, err = decrypt(env, dataKey, empty[:0], 64)
+	assert.ErrorIs(t, err, ErrDecryptFailed)
+}
+
+func TestInvalidEnvelope(t *testing.T) {
+	ctx := context.Background()
+	kek := newTestKEK(t)
+	_, _, err := NewEnvelope(ctx, kek, Overhead)
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	env, _, err := NewEnvelope(ctx, kek, 64)
+	require.NoError(t, err)
+	invalid := *env
+	invalid.Algorithm = "AES-128-CTR"
+	_, err = invalid.DataKey(ctx, kek)
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+	invalid = *env
+	invalid.Nonce = invalid.Nonce[:8]
+	_, err = invalid.EncryptReader(make([]byte, KeySize), bytes.NewReader(nil))
+	assert.ErrorIs(t, err, ErrInvalidEnvelope)
+
+	invalid = *env
+	invalid.WrappedKey = []byte("wrapped")
+	_, err = invalid.DataKey(ctx, kek)
+	assert.Error(t, err)
+
+	errReader := errors.New("read failed")
+	reader, err := env.EncryptReader(make([]byte, KeySize), iotest.ErrReader(errReader))
+	require.NoError(t, err)
+	_, err = io.ReadAll(reader)
+	assert.ErrorIs(t, err, errReader)
+}
@@ -0,0 +1,161 @@
+// Package dedupe detects the objects whose content is already stored: the content of an object is identified by a
+// content address derived from its integrity hashes, recorded in a pluggable index once the object is stored, so a
+// gateway can look an upload up after hashing it and reuse the stored object instead of uploading it again.
+package dedupe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// addressDomain separates the content addresses from the other sha256 digests
+const addressDomain = "mechain-content-address-v1"
+
+var (
+	// ErrNotFound is returned when no object is recorded under a content address
+	ErrNotFound = errors.New("content address not found")
+	// ErrInvalidAddress is returned when a content address can not be parsed
+	ErrInvalidAddress = errors.New("invalid content address")
+)
+
+// Address is the content address of an object, the same content split with the same redundancy params has the same
+// address
+type Address [sha256.Size]byte
+
+// NewAddress return the content address of the content of contentLength bytes whose PrimarySP integrity hash is
+// primaryRoot, split with params
+func NewAddress(primaryRoot []byte, contentLength int64, params redundancy.RedundancyParams) (Address, error) {
+	if err := params.Validate(); err != nil {
+		return Address{}, err
+	}
+	if len(primaryRoot) != sha256.Size {
+		return Address{}, fmt.Errorf("%w: %d bytes integrity hash", hash.ErrIntegrityHashMismatch, len(primaryRoot))
+	}
+	h := sha256.New()
+	h.Write([]byte(addressDomain))
+	var buf [8]byte
+	for _, v := range []uint64{uint64(contentLength), uint64(params.SegmentSize), uint64(params.DataShards),
+		uint64(params.ParityShards)} {
+		binary.BigEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	h.Write(primaryRoot)
+	var address Address
+	h.Sum(address[:0])
+	return address, nil
+}
+
+// AddressOf return the content address of the object whose integrity hashes are result, computed with params
+func AddressOf(result *hash.HashResult, params redundancy.RedundancyParams) (Address, error) {
+	if err := result.CheckVersion(); err != nil {
+		return Address{}, err
+	}
+	return NewAddress(result.PrimaryChecksum(), result.ContentLength, params)
+}
+
+// String return the hex encoding of the address
+func (a Address) String() string {
+	return hex.EncodeToString(a[:])
+}
+
+// ParseAddress parses the hex encoding of an address
+func ParseAddress(s string) (Address, error) {
+	var address Address
+	decoded, err := hex.DecodeString(s)
+	if err != nil || len(decoded) != len(address) {
+		return Address{}, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	copy(address[:], decoded)
+	return address, nil
+}
+
+// Entry is the stored object recorded under a content address
+type Entry struct {
+	ObjectID      uint64 `json:"object_id"`
+	BucketName    string `json:"bucket_name"`
+	ObjectName    string `json:"object_name"`
+	ContentLength int64  `json:"content_length"`
+}
+
+// Index records the stored object of the content addresses, e.g. in a database shared by the gateways
+type Index interface {
+	// Lookup return the entry recorded under the address, or ErrNotFound
+	Lookup(ctx context.Context, address Address) (Entry, error)
+	// Record records the entry under the address if none is recorded, it return the recorded entry and whether it
+	// is the given one, so concurrent uploads of the same content agree on a single object
+	Record(ctx context.Context, address Address, entry Entry) (Entry, bool, error)
+	// Remove removes the entry of the object recorded under the address, e.g. when the object is deleted, removing an
+	// entry of another object or a missing entry is not an error
+	Remove(ctx context.Context, address Address, objectID uint64) error
+}
+
+// MemoryIndex is an Index keeping the entries in memory, e.g. for tests or a single gateway. It is safe for
+// concurrent use.
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	entries map[Address]Entry
+}
+
+// NewMemoryIndex return an empty MemoryIndex
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{entries: make(map[Address]Entry)}
+}
+
+// Lookup implements Index
+func (i *MemoryIndex) Lookup(_ context.Context, address Address) (Entry, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	entry, ok := i.entries[address]
+	if !ok {
+		return Entry{}, fmt.Errorf("%w: %s", ErrNotFound, address)
+	}
+	return entry, nil
+}
+
+// Record implements Index
+func (i *MemoryIndex) Record(_ context.Context, address Address, entry Entry) (Entry, bool, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if recorded, ok := i.entries[address]; ok {
+		return recorded, recorded == entry, nil
+	}
+	i.entries[address] = entry
+	return entry, true, nil
+}
+
+// Remove implements Index
+func (i *MemoryIndex) Remove(_ context.Context, address Address, objectID uint64) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if entry, ok := i.entries[address]; ok && entry.ObjectID == objectID {
+		delete(i.entries, address)
+	}
+	return nil
+}
+
+// Find return the stored object with the content of the hash result split with params, and false if the content is
+// not stored yet
+func Find(ctx context.Context, index Index, result *hash.HashResult, params redundancy.RedundancyParams) (Entry, bool,
+	error,
+) {
+	address, err := AddressOf(result, params)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	entry, err := index.Lookup(ctx, address)
+	if errors.Is(err, ErrNotFound) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+	return entry, true, nil
+}
@@ -0,0 +1,121 @@
+package dedupe
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func hashResult(t *testing.T, content []byte, params redundancy.RedundancyParams) *hash.HashResult {
+	result, err := hash.ComputeIntegrityHashWithParams(bytes.NewReader(content), params)
+	require.NoError(t, err)
+	return result
+}
+
+func TestAddress(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	other, err := redundancy.NewRedundancyParams(2048, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(5000)
+
+	address, err := AddressOf(hashResult(t, content, params), params)
+	require.NoError(t, err)
+	same, err := AddressOf(hashResult(t, bytes.Clone(content), params), params)
+	require.NoError(t, err)
+	assert.Equal(t, address, same)
+
+	changed := bytes.Clone(content)
+	changed[4999] ^= 1
+	differs, err := AddressOf(hashResult(t, changed, params), params)
+	require.NoError(t, err)
+	assert.NotEqual(t, address, differs)
+	differs, err = AddressOf(hashResult(t, content, other), other)
+	require.NoError(t, err)
+	assert.NotEqual(t, address, differs)
+
+	parsed, err := ParseAddress(address.String())
+	require.NoError(t, err)
+	assert.Equal(t, address, parsed)
+	_, err = ParseAddress("abcd")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+	_, err = ParseAddress(address.String()[:62] + "zz")
+	assert.ErrorIs(t, err, ErrInvalidAddress)
+
+	_, err = NewAddress([]byte{1, 2, 3}, 3, params)
+	assert.ErrorIs(t, err, hash.ErrIntegrityHashMismatch)
+	future := hashResult(t, content, params)
+	future.Version = hash.CurrentHashVersion + 1
+	_, err = AddressOf(future, params)
+	assert.ErrorIs(t, err, hash.ErrUnsupportedHashVersion)
+}
+
+func TestMemoryIndex(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	index := NewMemoryIndex()
+	result := hashResult(t, hash.TestVectorData(3000), params)
+
+	_, found, err := Find(ctx, index, result, params)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	address, err := AddressOf(result, params)
+	require.NoError(t, err)
+	_, err = index.Lookup(ctx, address)
+	assert.ErrorIs(t, err, ErrNotFound)
+	stored := Entry{ObjectID: 1, BucketName: "bucket", ObjectName: "a", ContentLength: 3000}
+	recorded, ok, err := index.Record(ctx, address, stored)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, stored, recorded)
+	recorded, ok, err = index.Record(ctx, address, Entry{ObjectID: 2, BucketName: "bucket", ObjectName: "b"})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, stored, recorded)
+
+	entry, found, err := Find(ctx, index, result, params)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, stored, entry)
+
+	require.NoError(t, index.Remove(ctx, address, 2))
+	_, err = index.Lookup(ctx, address)
+	require.NoError(t, err)
+	require.NoError(t, index.Remove(ctx, address, 1))
+	_, err = index.Lookup(ctx, address)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryIndexConcurrentRecord(t *testing.T) {
+	ctx := context.Background()
+	index := NewMemoryIndex()
+	address := Address{1}
+	var wg sync.WaitGroup
+	winners := make(chan uint64, 16)
+	for i := uint64(1); i <= 16; i++ {
+		wg.Add(1)
+		go func(objectID uint64) {
+			defer wg.Done()
+			recorded, ok, err := index.Record(ctx, address, Entry{ObjectID: objectID})
+			assert.NoError(t, err)
+			if ok {
+				winners <- recorded.ObjectID
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(winners)
+	assert.Len(t, winners, 1)
+	entry, err := index.Lookup(ctx, address)
+	require.NoError(t, err)
+	assert.Equal(t, <-winners, entry.ObjectID)
+}
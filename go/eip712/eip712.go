@@ -0,0 +1,169 @@
+// Package eip712 builds the EIP-712 typed data digests of the storage messages signed by the users and the SPs, e.g.
+// the approvals of the PrimarySP, and signs and verifies them with secp256k1 keys
+package eip712
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/zkMeLabs/mechain-common/go/canonicaljson"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature is not a 65 bytes [R||S||V] secp256k1 signature
+	ErrInvalidSignature = errors.New("invalid eip712 signature")
+	// ErrSignerMismatch is returned when a signature is not signed by the expected signer
+	ErrSignerMismatch = errors.New("eip712 signature is not signed by the signer")
+)
+
+const (
+	// DefaultDomainName is the domain name of the storage messages signed by NewDomain
+	DefaultDomainName = "Mechain Storage"
+	// DefaultDomainVersion is the domain version of the storage messages signed by NewDomain
+	DefaultDomainVersion = "1.0.0"
+	// DefaultVerifyingContract is the verifying contract of the storage messages signed by NewDomain
+	DefaultVerifyingContract = "mechain"
+)
+
+// Domain is the EIP-712 domain separating the messages signed for different chains and applications, the empty
+// fields are left out of the domain type
+type Domain struct {
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract string
+	Salt              string
+}
+
+// NewDomain return the domain of the storage messages of the chain
+func NewDomain(chainID *big.Int) Domain {
+	return Domain{
+		Name:              DefaultDomainName,
+		Version:           DefaultDomainVersion,
+		ChainID:           chainID,
+		VerifyingContract: DefaultVerifyingContract,
+		Salt:              "0",
+	}
+}
+
+// typedDataDomain return the domain and its struct type
+func (d Domain) typedDataDomain() (apitypes.TypedDataDomain, []apitypes.Type) {
+	domain := apitypes.TypedDataDomain{
+		Name:              d.Name,
+		Version:           d.Version,
+		VerifyingContract: d.VerifyingContract,
+		Salt:              d.Salt,
+	}
+	var types []apitypes.Type
+	if d.Name != "" {
+		types = append(types, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if d.Version != "" {
+		types = append(types, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if d.ChainID != nil {
+		domain.ChainId = (*math.HexOrDecimal256)(d.ChainID)
+		types = append(types, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if d.VerifyingContract != "" {
+		types = append(types, apitypes.Type{Name: "verifyingContract", Type: "string"})
+	}
+	if d.Salt != "" {
+		types = append(types, apitypes.Type{Name: "salt", Type: "string"})
+	}
+	return domain, types
+}
+
+// Message is a storage message signed as EIP-712 typed data
+type Message interface {
+	// PrimaryType return the name of the struct type of the message
+	PrimaryType() string
+	// Types return the fields of the struct type of the message
+	Types() []apitypes.Type
+	// Values return the values of the fields of the message
+	Values() apitypes.TypedDataMessage
+}
+
+// TypedData return the EIP-712 typed data of the message in the domain, e.g. to be signed by a wallet with
+// eth_signTypedData_v4
+func TypedData(domain Domain, msg Message) apitypes.TypedData {
+	typedDataDomain, domainTypes := domain.typedDataDomain()
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain":    domainTypes,
+			msg.PrimaryType(): msg.Types(),
+		},
+		PrimaryType: msg.PrimaryType(),
+		Domain:      typedDataDomain,
+		Message:     msg.Values(),
+	}
+}
+
+// TypedDataJSON return the canonical json of the typed data of the message in the domain, so the payloads sent to
+// the wallets and the signing services are the same bytes whatever encodes them
+func TypedDataJSON(domain Domain, msg Message) ([]byte, error) {
+	return canonicaljson.Marshal(TypedData(domain, msg))
+}
+
+// Digest return the EIP-712 digest of the message in the domain, which is the hash signed by Sign
+func Digest(domain Domain, msg Message) ([]byte, error) {
+	digest, _, err := apitypes.TypedDataAndHash(TypedData(domain, msg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash %s: %w", msg.PrimaryType(), err)
+	}
+	return digest, nil
+}
+
+// Sign return the [R||S||V] signature of the digest of the message by the private key, V is 27 or 28 like the
+// signatures of the wallets
+func Sign(privateKey *ecdsa.PrivateKey, domain Domain, msg Message) ([]byte, error) {
+	digest, err := Digest(domain, msg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(digest, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// RecoverSigner return the address of the signer of the message, V of the signature may be 0, 1, 27 or 28
+func RecoverSigner(domain Domain, msg Message, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: length %d", ErrInvalidSignature, len(sig))
+	}
+	digest, err := Digest(domain, msg)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// Verify verify the signature of the message is signed by the signer
+func Verify(signer common.Address, domain Domain, msg Message, sig []byte) error {
+	recovered, err := RecoverSigner(domain, msg, sig)
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return fmt.Errorf("%w: signed by %s instead of %s", ErrSignerMismatch, recovered, signer)
+	}
+	return nil
+}
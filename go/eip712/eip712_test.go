@@ -0,0 +1,110 @@
+package eip712
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/canonicaljson"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	domain := NewDomain(big.NewInt(5151))
+
+	for _, msg := range []Message{
+		&CreateObjectApproval{
+			Creator:                    signer,
+			BucketName:                 "bucket",
+			ObjectName:                 "object",
+			PayloadSize:                16 * 1024 * 1024,
+			ContentType:                "application/octet-stream",
+			ExpectChecksums:            [][]byte{crypto.Keccak256([]byte("primary"))},
+			GlobalVirtualGroupFamilyID: 1,
+			ExpiredHeight:              100,
+		},
+		&MigrateBucketApproval{Operator: signer, BucketName: "bucket", DstPrimarySPID: 2, ExpiredHeight: 100},
+		&SecondarySPMigrationApproval{BucketID: 1, SPID: 2, SrcGVGID: 3, DstGVGID: 4},
+	} {
+		sig, err := Sign(privateKey, domain, msg)
+		require.NoError(t, err, msg.PrimaryType())
+		assert.Contains(t, []byte{27, 28}, sig[crypto.RecoveryIDOffset])
+		assert.NoError(t, Verify(signer, domain, msg, sig), msg.PrimaryType())
+
+		digest, err := Digest(domain, msg)
+		require.NoError(t, err)
+		rawSig, err := crypto.Sign(digest, privateKey)
+		require.NoError(t, err)
+		assert.NoError(t, Verify(signer, domain, msg, rawSig), msg.PrimaryType())
+
+		otherDomain := NewDomain(big.NewInt(5152))
+		assert.ErrorIs(t, Verify(signer, otherDomain, msg, sig), ErrSignerMismatch, msg.PrimaryType())
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	domain := NewDomain(big.NewInt(5151))
+
+	approval := &CreateObjectApproval{Creator: signer, BucketName: "bucket", ObjectName: "object", PayloadSize: 1}
+	sig, err := Sign(privateKey, domain, approval)
+	require.NoError(t, err)
+
+	tampered := *approval
+	tampered.PayloadSize = 2
+	assert.ErrorIs(t, Verify(signer, domain, &tampered, sig), ErrSignerMismatch)
+	tampered = *approval
+	tampered.ExpectChecksums = [][]byte{{1}}
+	assert.ErrorIs(t, Verify(signer, domain, &tampered, sig), ErrSignerMismatch)
+
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	assert.ErrorIs(t, Verify(crypto.PubkeyToAddress(other.PublicKey), domain, approval, sig), ErrSignerMismatch)
+
+	assert.ErrorIs(t, Verify(signer, domain, approval, sig[:64]), ErrInvalidSignature)
+	invalid := append([]byte{}, sig...)
+	invalid[crypto.RecoveryIDOffset] = 5
+	assert.ErrorIs(t, Verify(signer, domain, approval, invalid), ErrInvalidSignature)
+}
+
+func TestDigestDomain(t *testing.T) {
+	approval := &SecondarySPMigrationApproval{BucketID: 1, SPID: 2, SrcGVGID: 3, DstGVGID: 4}
+	digest, err := Digest(NewDomain(big.NewInt(5151)), approval)
+	require.NoError(t, err)
+	assert.Len(t, digest, 32)
+
+	minimal, err := Digest(Domain{Name: DefaultDomainName}, approval)
+	require.NoError(t, err)
+	assert.NotEqual(t, digest, minimal)
+}
+
+func TestTypedDataJSON(t *testing.T) {
+	approval := &MigrateBucketApproval{Operator: common.HexToAddress("0x01"), BucketName: "a&b", DstPrimarySPID: 2,
+		ExpiredHeight: 100}
+	data, err := TypedDataJSON(NewDomain(big.NewInt(5151)), approval)
+	require.NoError(t, err)
+	assert.True(t, canonicaljson.Valid(data))
+	assert.Contains(t, string(data), `"bucketName":"a&b"`)
+
+	// the wallets hash the typed data parsed from the json to the same digest
+	var typedData apitypes.TypedData
+	require.NoError(t, json.Unmarshal(data, &typedData))
+	hashed, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+	digest, err := Digest(NewDomain(big.NewInt(5151)), approval)
+	require.NoError(t, err)
+	assert.Equal(t, digest, hashed)
+	again, err := TypedDataJSON(NewDomain(big.NewInt(5151)), approval)
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+}
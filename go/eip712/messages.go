@@ -0,0 +1,128 @@
+package eip712
+
+import (
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+// CreateObjectApproval is the approval of the PrimarySP to store an object created by the creator
+type CreateObjectApproval struct {
+	Creator        common.Address
+	BucketName     string
+	ObjectName     string
+	PayloadSize    uint64
+	ContentType    string
+	RedundancyType uint32
+	// ExpectChecksums are the integrity hashes of the object, they are signed as their integrity hash
+	ExpectChecksums            [][]byte
+	GlobalVirtualGroupFamilyID uint32
+	ExpiredHeight              uint64
+}
+
+// PrimaryType implements Message
+func (a *CreateObjectApproval) PrimaryType() string {
+	return "CreateObjectApproval"
+}
+
+// Types implements Message
+func (a *CreateObjectApproval) Types() []apitypes.Type {
+	return []apitypes.Type{
+		{Name: "creator", Type: "address"},
+		{Name: "bucketName", Type: "string"},
+		{Name: "objectName", Type: "string"},
+		{Name: "payloadSize", Type: "uint64"},
+		{Name: "contentType", Type: "string"},
+		{Name: "redundancyType", Type: "uint32"},
+		{Name: "expectChecksumsHash", Type: "bytes32"},
+		{Name: "globalVirtualGroupFamilyId", Type: "uint32"},
+		{Name: "expiredHeight", Type: "uint64"},
+	}
+}
+
+// Values implements Message
+func (a *CreateObjectApproval) Values() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"creator":                    a.Creator.Hex(),
+		"bucketName":                 a.BucketName,
+		"objectName":                 a.ObjectName,
+		"payloadSize":                strconv.FormatUint(a.PayloadSize, 10),
+		"contentType":                a.ContentType,
+		"redundancyType":             strconv.FormatUint(uint64(a.RedundancyType), 10),
+		"expectChecksumsHash":        hexutil.Bytes(hash.GenerateIntegrityHash(a.ExpectChecksums)),
+		"globalVirtualGroupFamilyId": strconv.FormatUint(uint64(a.GlobalVirtualGroupFamilyID), 10),
+		"expiredHeight":              strconv.FormatUint(a.ExpiredHeight, 10),
+	}
+}
+
+// MigrateBucketApproval is the approval of the destination PrimarySP to take over the bucket migrated by the
+// operator
+type MigrateBucketApproval struct {
+	Operator       common.Address
+	BucketName     string
+	DstPrimarySPID uint32
+	ExpiredHeight  uint64
+}
+
+// PrimaryType implements Message
+func (a *MigrateBucketApproval) PrimaryType() string {
+	return "MigrateBucketApproval"
+}
+
+// Types implements Message
+func (a *MigrateBucketApproval) Types() []apitypes.Type {
+	return []apitypes.Type{
+		{Name: "operator", Type: "address"},
+		{Name: "bucketName", Type: "string"},
+		{Name: "dstPrimarySpId", Type: "uint32"},
+		{Name: "expiredHeight", Type: "uint64"},
+	}
+}
+
+// Values implements Message
+func (a *MigrateBucketApproval) Values() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"operator":       a.Operator.Hex(),
+		"bucketName":     a.BucketName,
+		"dstPrimarySpId": strconv.FormatUint(uint64(a.DstPrimarySPID), 10),
+		"expiredHeight":  strconv.FormatUint(a.ExpiredHeight, 10),
+	}
+}
+
+// SecondarySPMigrationApproval is the approval of a SecondarySP to join the global virtual group of a bucket
+// migrated from the source global virtual group
+type SecondarySPMigrationApproval struct {
+	BucketID uint64
+	SPID     uint32
+	SrcGVGID uint32
+	DstGVGID uint32
+}
+
+// PrimaryType implements Message
+func (a *SecondarySPMigrationApproval) PrimaryType() string {
+	return "SecondarySpMigrationApproval"
+}
+
+// Types implements Message
+func (a *SecondarySPMigrationApproval) Types() []apitypes.Type {
+	return []apitypes.Type{
+		{Name: "bucketId", Type: "uint256"},
+		{Name: "spId", Type: "uint32"},
+		{Name: "srcGlobalVirtualGroupId", Type: "uint32"},
+		{Name: "dstGlobalVirtualGroupId", Type: "uint32"},
+	}
+}
+
+// Values implements Message
+func (a *SecondarySPMigrationApproval) Values() apitypes.TypedDataMessage {
+	return apitypes.TypedDataMessage{
+		"bucketId":                strconv.FormatUint(a.BucketID, 10),
+		"spId":                    strconv.FormatUint(uint64(a.SPID), 10),
+		"srcGlobalVirtualGroupId": strconv.FormatUint(uint64(a.SrcGVGID), 10),
+		"dstGlobalVirtualGroupId": strconv.FormatUint(uint64(a.DstGVGID), 10),
+	}
+}
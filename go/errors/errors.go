@@ -0,0 +1,223 @@
+// Package errors defines the numeric error codes shared by the mechain services and their mapping to the HTTP
+// statuses and the gRPC codes, so the clients get the same errors from every component. The codes below 10000 are
+// defined by this package, the services register their own codes from 10000 with Register. An error carries its
+// code by being or wrapping an *Error, e.g.
+//
+//	return errors.Wrap(err, errors.CodeNotFound, "object %s", name)
+//
+// and is returned to the HTTP clients by WriteHTTP and to the gRPC clients by the GRPCStatus method of *Error.
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Code is the numeric code of an error
+type Code uint32
+
+// The codes defined by the package
+const (
+	// CodeOK is the code of no error
+	CodeOK Code = 0
+	// CodeInternal is the code of the errors without a code
+	CodeInternal Code = 1
+	// CodeInvalidArgument is the code of the malformed requests, e.g. a missing header
+	CodeInvalidArgument Code = 2
+	// CodeNotFound is the code of the requests of missing resources, e.g. a missing object or piece
+	CodeNotFound Code = 3
+	// CodeAlreadyExists is the code of the requests creating an existing resource
+	CodeAlreadyExists Code = 4
+	// CodeUnauthenticated is the code of the requests without a valid signature
+	CodeUnauthenticated Code = 5
+	// CodePermissionDenied is the code of the requests of a caller lacking the permission
+	CodePermissionDenied Code = 6
+	// CodeRateLimited is the code of the requests exceeding the rate limit of the caller
+	CodeRateLimited Code = 7
+	// CodeQuotaExceeded is the code of the reads exceeding the read quota of a bucket
+	CodeQuotaExceeded Code = 8
+	// CodeChecksumMismatch is the code of the contents whose integrity hashes differ from the expected ones
+	CodeChecksumMismatch Code = 9
+	// CodeCanceled is the code of the requests canceled by the caller
+	CodeCanceled Code = 10
+	// CodeDeadlineExceeded is the code of the requests which timed out
+	CodeDeadlineExceeded Code = 11
+	// CodeUnavailable is the code of the requests failed on a transient error, e.g. an unreachable SP, which may
+	// be retried
+	CodeUnavailable Code = 12
+	// CodeNotImplemented is the code of the requests of an unsupported operation
+	CodeNotImplemented Code = 13
+)
+
+// FirstServiceCode is the first code the services may register
+const FirstServiceCode Code = 10000
+
+type codeInfo struct {
+	name       string
+	httpStatus int
+	grpcCode   codes.Code
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[Code]codeInfo)
+	names      = make(map[string]Code)
+)
+
+func init() {
+	for code, info := range map[Code]codeInfo{
+		CodeOK:               {"OK", http.StatusOK, codes.OK},
+		CodeInternal:         {"InternalError", http.StatusInternalServerError, codes.Internal},
+		CodeInvalidArgument:  {"InvalidArgument", http.StatusBadRequest, codes.InvalidArgument},
+		CodeNotFound:         {"NotFound", http.StatusNotFound, codes.NotFound},
+		CodeAlreadyExists:    {"AlreadyExists", http.StatusConflict, codes.AlreadyExists},
+		CodeUnauthenticated:  {"Unauthenticated", http.StatusUnauthorized, codes.Unauthenticated},
+		CodePermissionDenied: {"PermissionDenied", http.StatusForbidden, codes.PermissionDenied},
+		CodeRateLimited:      {"RateLimited", http.StatusTooManyRequests, codes.ResourceExhausted},
+		CodeQuotaExceeded:    {"QuotaExceeded", http.StatusForbidden, codes.ResourceExhausted},
+		CodeChecksumMismatch: {"ChecksumMismatch", http.StatusBadRequest, codes.DataLoss},
+		CodeCanceled:         {"Canceled", 499, codes.Canceled},
+		CodeDeadlineExceeded: {"DeadlineExceeded", http.StatusGatewayTimeout, codes.DeadlineExceeded},
+		CodeUnavailable:      {"Unavailable", http.StatusServiceUnavailable, codes.Unavailable},
+		CodeNotImplemented:   {"NotImplemented", http.StatusNotImplemented, codes.Unimplemented},
+	} {
+		register(code, info)
+	}
+}
+
+// Register registers the code of a service under the name, returned by the String method of the code, with the HTTP
+// status and the gRPC code of its errors. It panics if the code is below FirstServiceCode or if the code or the name
+// is already registered, so it is meant to be called while initializing the package variables, e.g.
+//
+//	var CodeBucketFrozen = errors.Register(10001, "BucketFrozen", http.StatusForbidden, codes.FailedPrecondition)
+func Register(code Code, name string, httpStatus int, grpcCode codes.Code) Code {
+	if code < FirstServiceCode {
+		panic(fmt.Sprintf("errors: code %d of %s is reserved", code, name))
+	}
+	register(code, codeInfo{name: name, httpStatus: httpStatus, grpcCode: grpcCode})
+	return code
+}
+
+func register(code Code, info codeInfo) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[code]; ok {
+		panic(fmt.Sprintf("errors: code %d already registered", code))
+	}
+	if _, ok := names[info.name]; ok {
+		panic(fmt.Sprintf("errors: code name %s already registered", info.name))
+	}
+	registry[code] = info
+	names[info.name] = code
+}
+
+func (c Code) info() codeInfo {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	if info, ok := registry[c]; ok {
+		return info
+	}
+	return codeInfo{name: fmt.Sprintf("Code(%d)", uint32(c)), httpStatus: http.StatusInternalServerError,
+		grpcCode: codes.Unknown}
+}
+
+// String return the name of the code
+func (c Code) String() string {
+	return c.info().name
+}
+
+// HTTPStatus return the HTTP status of the errors of the code, 500 for an unregistered code
+func (c Code) HTTPStatus() int {
+	return c.info().httpStatus
+}
+
+// GRPCCode return the gRPC code of the errors of the code, codes.Unknown for an unregistered code
+func (c Code) GRPCCode() codes.Code {
+	return c.info().grpcCode
+}
+
+// CodeByName return the code registered under the name
+func CodeByName(name string) (Code, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	code, ok := names[name]
+	return code, ok
+}
+
+// Error is an error with a code, the message is returned to the clients while the wrapped error, which may hold
+// internal details, is not
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+// New return an error of the code with the formatted message
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Wrap return an error of the code with the formatted message wrapping err, or nil if err is nil
+func Wrap(err error, code Code, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	msg := e.Code.String()
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap return the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error of the same code without a message, so errors.Is(err, &Error{Code: c})
+// checks the code of err
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Err == nil && t.Code == e.Code
+}
+
+// CodeOf return the code of err: the code of the first *Error it wraps, CodeCanceled or CodeDeadlineExceeded for the
+// errors of the contexts, CodeOK for nil and CodeInternal otherwise
+func CodeOf(err error) Code {
+	if err == nil {
+		return CodeOK
+	}
+	var e *Error
+	switch {
+	case stderrors.As(err, &e):
+		return e.Code
+	case stderrors.Is(err, context.Canceled):
+		return CodeCanceled
+	case stderrors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	}
+	return CodeInternal
+}
+
+// MessageOf return the message of err returned to the clients: the message of the first *Error it wraps, or the
+// name of its code so the internal details of the errors without a code are not leaked
+func MessageOf(err error) string {
+	var e *Error
+	if stderrors.As(err, &e) && e.Message != "" {
+		return e.Message
+	}
+	return CodeOf(err).String()
+}
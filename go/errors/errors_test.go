@@ -0,0 +1,55 @@
+package errors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+var codeTest = Register(FirstServiceCode+1, "TestFailure", http.StatusTeapot, codes.Aborted)
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, "NotFound", CodeNotFound.String())
+	assert.Equal(t, http.StatusNotFound, CodeNotFound.HTTPStatus())
+	assert.Equal(t, codes.NotFound, CodeNotFound.GRPCCode())
+	assert.Equal(t, "TestFailure", codeTest.String())
+	assert.Equal(t, http.StatusTeapot, codeTest.HTTPStatus())
+	assert.Equal(t, codes.Aborted, codeTest.GRPCCode())
+	code, ok := CodeByName("TestFailure")
+	assert.True(t, ok)
+	assert.Equal(t, codeTest, code)
+
+	unknown := Code(99999)
+	assert.Equal(t, "Code(99999)", unknown.String())
+	assert.Equal(t, http.StatusInternalServerError, unknown.HTTPStatus())
+	assert.Equal(t, codes.Unknown, unknown.GRPCCode())
+
+	assert.Panics(t, func() { Register(CodeNotFound, "Other", http.StatusNotFound, codes.NotFound) })
+	assert.Panics(t, func() { Register(codeTest, "Other", http.StatusTeapot, codes.Aborted) })
+	assert.Panics(t, func() { Register(FirstServiceCode+2, "TestFailure", http.StatusTeapot, codes.Aborted) })
+}
+
+func TestError(t *testing.T) {
+	cause := stderrors.New("key 1_s0 missing")
+	err := Wrap(cause, CodeNotFound, "piece %d not found", 0)
+	assert.EqualError(t, err, "NotFound: piece 0 not found: key 1_s0 missing")
+	assert.ErrorIs(t, err, cause)
+	assert.ErrorIs(t, err, &Error{Code: CodeNotFound})
+	assert.NotErrorIs(t, err, &Error{Code: CodeInternal})
+	assert.Nil(t, Wrap(nil, CodeNotFound, "ignored"))
+	assert.EqualError(t, New(CodeInvalidArgument, "bad %s", "header"), "InvalidArgument: bad header")
+
+	wrapped := fmt.Errorf("get: %w", err)
+	assert.Equal(t, CodeNotFound, CodeOf(wrapped))
+	assert.Equal(t, "piece 0 not found", MessageOf(wrapped))
+	assert.Equal(t, CodeOK, CodeOf(nil))
+	assert.Equal(t, CodeCanceled, CodeOf(fmt.Errorf("read: %w", context.Canceled)))
+	assert.Equal(t, CodeDeadlineExceeded, CodeOf(context.DeadlineExceeded))
+	assert.Equal(t, CodeInternal, CodeOf(cause))
+	assert.Equal(t, "InternalError", MessageOf(cause))
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	stderrors "errors"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain is the domain of the errdetails.ErrorInfo carrying the codes in the gRPC statuses
+const Domain = "mechain"
+
+const codeMetadataKey = "code"
+
+// GRPCStatus return the gRPC status of the error, its code is the gRPC code of the code of the error and its
+// details an errdetails.ErrorInfo carrying the code, which is read back by FromGRPC. It is called by status.FromError
+// and status.Code so the handlers may return an *Error as is.
+func (e *Error) GRPCStatus() *status.Status {
+	return toStatus(e.Code, MessageOf(e))
+}
+
+// ToGRPC return the error returned to a gRPC client, see GRPCStatus. The gRPC status errors are returned as is.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	return toStatus(CodeOf(err), MessageOf(err)).Err()
+}
+
+func toStatus(code Code, message string) *status.Status {
+	st := status.New(code.GRPCCode(), message)
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   code.String(),
+		Domain:   Domain,
+		Metadata: map[string]string{codeMetadataKey: strconv.FormatUint(uint64(code), 10)},
+	})
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// FromGRPC return the *Error of an error returned by a gRPC call. The code is read from the details of the status,
+// or derived from the gRPC code if the server did not set it. It return nil if err is nil.
+func FromGRPC(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return &Error{Code: CodeOf(err), Message: err.Error(), Err: err}
+	}
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != Domain {
+			continue
+		}
+		if code, parseErr := strconv.ParseUint(info.GetMetadata()[codeMetadataKey], 10, 32); parseErr == nil {
+			return &Error{Code: Code(code), Message: st.Message(), Err: err}
+		}
+	}
+	return &Error{Code: codeOfGRPC(st.Code()), Message: st.Message(), Err: err}
+}
+
+// codeOfGRPC return the code of the errors of a gRPC code, for the statuses without details
+func codeOfGRPC(c codes.Code) Code {
+	switch c {
+	case codes.OK:
+		return CodeOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return CodeInvalidArgument
+	case codes.NotFound:
+		return CodeNotFound
+	case codes.AlreadyExists:
+		return CodeAlreadyExists
+	case codes.Unauthenticated:
+		return CodeUnauthenticated
+	case codes.PermissionDenied:
+		return CodePermissionDenied
+	case codes.ResourceExhausted:
+		return CodeRateLimited
+	case codes.DataLoss:
+		return CodeChecksumMismatch
+	case codes.Canceled:
+		return CodeCanceled
+	case codes.DeadlineExceeded:
+		return CodeDeadlineExceeded
+	case codes.Unavailable:
+		return CodeUnavailable
+	case codes.Unimplemented:
+		return CodeNotImplemented
+	}
+	return CodeInternal
+}
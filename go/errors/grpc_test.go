@@ -0,0 +1,38 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPC(t *testing.T) {
+	err := Wrap(stderrors.New("internal detail"), CodeQuotaExceeded, "read quota of bucket exceeded")
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, "read quota of bucket exceeded", st.Message())
+
+	e := FromGRPC(st.Err())
+	assert.Equal(t, CodeQuotaExceeded, e.Code)
+	assert.Equal(t, "read quota of bucket exceeded", e.Message)
+
+	e = FromGRPC(ToGRPC(fmt.Errorf("handler: %w", New(codeTest, "aborted"))))
+	assert.Equal(t, codeTest, e.Code)
+	e = FromGRPC(ToGRPC(stderrors.New("internal detail")))
+	assert.Equal(t, CodeInternal, e.Code)
+	assert.Equal(t, "InternalError", e.Message)
+
+	plain := status.Error(codes.NotFound, "no such object")
+	assert.Same(t, plain, ToGRPC(plain))
+	e = FromGRPC(plain)
+	assert.Equal(t, CodeNotFound, e.Code)
+	assert.Equal(t, "no such object", e.Message)
+
+	assert.Nil(t, ToGRPC(nil))
+	assert.Nil(t, FromGRPC(nil))
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+// Response is the body of the error responses of the SP gateway, encoded in XML by default
+//
+//	<Error><Code>3</Code><Message>object not found</Message><RequestId>...</RequestId></Error>
+//
+// or in JSON if the client accepts application/json
+type Response struct {
+	XMLName   xml.Name `xml:"Error" json:"-"`
+	Code      Code     `xml:"Code" json:"code"`
+	Message   string   `xml:"Message" json:"message"`
+	RequestID string   `xml:"RequestId,omitempty" json:"request_id,omitempty"`
+}
+
+// NewResponse return the response of err, the request ID is the one of the context of r if any
+func NewResponse(r *http.Request, err error) *Response {
+	return &Response{Code: CodeOf(err), Message: MessageOf(err), RequestID: requestid.FromContext(r.Context())}
+}
+
+// WriteHTTP writes the response of err with the HTTP status of its code, in JSON if the Accept header of r accepts
+// application/json and in XML otherwise
+func WriteHTTP(w http.ResponseWriter, r *http.Request, err error) {
+	resp := NewResponse(r, err)
+	var (
+		body        []byte
+		contentType string
+	)
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		body, _ = json.Marshal(resp)
+		contentType = "application/json"
+	} else {
+		body, _ = xml.Marshal(resp)
+		body = append([]byte(xml.Header), body...)
+		contentType = "application/xml"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(resp.Code.HTTPStatus())
+	_, _ = w.Write(body)
+}
+
+// ReadHTTP return the *Error of an error response read by a client, the body is decoded according to its
+// Content-Type, and the code is derived from the HTTP status if the body is not a Response. It does not close the
+// body.
+func ReadHTTP(resp *http.Response) *Error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &Error{Code: CodeUnavailable, Message: "failed to read the error response", Err: err}
+	}
+	var r Response
+	if strings.Contains(resp.Header.Get("Content-Type"), "json") {
+		err = json.Unmarshal(body, &r)
+	} else {
+		err = xml.Unmarshal(body, &r)
+	}
+	if err != nil || r.Code == CodeOK {
+		return &Error{Code: codeOfHTTP(resp.StatusCode), Message: strings.TrimSpace(string(body)),
+			Err: fmt.Errorf("http status %d", resp.StatusCode)}
+	}
+	return &Error{Code: r.Code, Message: r.Message}
+}
+
+// codeOfHTTP return the code of the errors of an HTTP status, for the responses without a Response body
+func codeOfHTTP(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeDeadlineExceeded
+	}
+	return CodeInternal
+}
@@ -0,0 +1,49 @@
+package errors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+func TestWriteHTTP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+	r = r.WithContext(requestid.NewContext(r.Context(), "req-1"))
+	w := httptest.NewRecorder()
+	WriteHTTP(w, r, New(CodeNotFound, "object not found"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<Error><Code>3</Code><Message>object not found</Message><RequestId>req-1</RequestId></Error>`,
+		w.Body.String())
+	e := ReadHTTP(w.Result())
+	assert.Equal(t, CodeNotFound, e.Code)
+	assert.Equal(t, "object not found", e.Message)
+
+	r = httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	WriteHTTP(w, r, New(CodeRateLimited, "slow down"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"code":7,"message":"slow down"}`, w.Body.String())
+	e = ReadHTTP(w.Result())
+	assert.Equal(t, CodeRateLimited, e.Code)
+
+	resp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}, Body: http.NoBody}
+	e = ReadHTTP(resp)
+	assert.Equal(t, CodePermissionDenied, e.Code)
+
+	w = httptest.NewRecorder()
+	http.Error(w, "bad gateway\n", http.StatusBadGateway)
+	e = ReadHTTP(w.Result())
+	require.NotNil(t, e)
+	assert.Equal(t, CodeUnavailable, e.Code)
+	assert.True(t, strings.HasPrefix(e.Message, "bad gateway"))
+}
@@ -0,0 +1,211 @@
+// Package gossip signs and verifies the messages exchanged between the SPs, e.g. the notifications of the replicated
+// pieces or of the migrated buckets: a payload is wrapped with its topic, the address of its signer, a timestamp and
+// a random nonce, and the receiver rejects the messages outside its replay window or already received.
+package gossip
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/zkMeLabs/mechain-common/go/canonicaljson"
+	"github.com/zkMeLabs/mechain-common/go/keys"
+)
+
+const (
+	// NonceSize is the size of the random nonces of the messages
+	NonceSize = 16
+	// DefaultReplayWindow is the maximum age of the messages accepted by a Verifier, and their maximum time ahead
+	DefaultReplayWindow = 5 * time.Minute
+	// signDomain separates the digests of the messages from the other digests signed by the SPs
+	signDomain = "mechain-gossip-v1"
+)
+
+var (
+	// ErrInvalidSignature is returned when the signature of a message can not be recovered
+	ErrInvalidSignature = errors.New("invalid gossip message signature")
+	// ErrSignerMismatch is returned when a message is not signed by its signer
+	ErrSignerMismatch = errors.New("gossip message is not signed by its signer")
+	// ErrUntrustedSigner is returned when a message is signed by a signer the verifier does not trust
+	ErrUntrustedSigner = errors.New("untrusted gossip message signer")
+	// ErrTopicMismatch is returned when a message was signed for another topic
+	ErrTopicMismatch = errors.New("gossip message topic mismatch")
+	// ErrStaleMessage is returned when the timestamp of a message is outside the replay window
+	ErrStaleMessage = errors.New("gossip message outside the replay window")
+	// ErrInvalidNonce is returned when the nonce of a message is not NonceSize bytes
+	ErrInvalidNonce = errors.New("invalid gossip message nonce")
+	// ErrReplayedMessage is returned when a message was already received
+	ErrReplayedMessage = errors.New("replayed gossip message")
+)
+
+// Message is a payload signed by a SP
+type Message struct {
+	Topic  string         `json:"topic"`
+	Signer common.Address `json:"signer"`
+	// Timestamp is the signing time in unix milliseconds
+	Timestamp int64         `json:"timestamp"`
+	Nonce     hexutil.Bytes `json:"nonce"`
+	Payload   []byte        `json:"payload"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// signDoc is the signed content of a message, encoded as canonical json
+type signDoc struct {
+	Domain    string         `json:"domain"`
+	Topic     string         `json:"topic"`
+	Signer    common.Address `json:"signer"`
+	Timestamp int64          `json:"timestamp"`
+	Nonce     hexutil.Bytes  `json:"nonce"`
+	Payload   []byte         `json:"payload"`
+}
+
+// Digest return the digest of the message signed by its signer
+func (m *Message) Digest() ([]byte, error) {
+	signBytes, err := canonicaljson.Marshal(signDoc{
+		Domain:    signDomain,
+		Topic:     m.Topic,
+		Signer:    m.Signer,
+		Timestamp: m.Timestamp,
+		Nonce:     m.Nonce,
+		Payload:   m.Payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(signBytes), nil
+}
+
+// Wrap return the message of the payload on the topic signed by the signer at now
+func Wrap(signer keys.Signer, topic string, payload []byte, now time.Time) (*Message, error) {
+	nonce := make([]byte, NonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	msg := &Message{
+		Topic:     topic,
+		Signer:    signer.Address(),
+		Timestamp: now.UnixMilli(),
+		Nonce:     nonce,
+		Payload:   payload,
+	}
+	digest, err := msg.Digest()
+	if err != nil {
+		return nil, err
+	}
+	if msg.Signature, err = signer.SignDigest(digest); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// VerifySignature verify the message is signed by its signer
+func VerifySignature(msg *Message) error {
+	if len(msg.Signature) != crypto.SignatureLength {
+		return fmt.Errorf("%w: length %d", ErrInvalidSignature, len(msg.Signature))
+	}
+	digest, err := msg.Digest()
+	if err != nil {
+		return err
+	}
+	pubKey, err := crypto.SigToPub(digest, msg.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSignature, err)
+	}
+	if recovered := crypto.PubkeyToAddress(*pubKey); recovered != msg.Signer {
+		return fmt.Errorf("%w: signed by %s instead of %s", ErrSignerMismatch, recovered, msg.Signer)
+	}
+	return nil
+}
+
+// Verifier verifies the received messages and rejects the replayed ones, it remembers the nonces of the messages
+// received within the replay window. It is safe for concurrent use.
+type Verifier struct {
+	window  time.Duration
+	trusted map[common.Address]struct{}
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+// VerifierOption configures a Verifier
+type VerifierOption func(*Verifier)
+
+// WithReplayWindow accepts the messages signed at most window before or after the time of their verification,
+// DefaultReplayWindow by default
+func WithReplayWindow(window time.Duration) VerifierOption {
+	return func(v *Verifier) {
+		v.window = window
+	}
+}
+
+// WithTrustedSigners only accepts the messages of the signers, e.g. the operator addresses of the SPs of the chain
+func WithTrustedSigners(signers ...common.Address) VerifierOption {
+	return func(v *Verifier) {
+		v.trusted = make(map[common.Address]struct{}, len(signers))
+		for _, signer := range signers {
+			v.trusted[signer] = struct{}{}
+		}
+	}
+}
+
+// NewVerifier return a Verifier of the received messages
+func NewVerifier(opts ...VerifierOption) *Verifier {
+	v := &Verifier{window: DefaultReplayWindow, seen: make(map[string]time.Time)}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// Verify verify the message received on the topic at now and return its signer, a message is only accepted once
+func (v *Verifier) Verify(msg *Message, topic string, now time.Time) (common.Address, error) {
+	if msg.Topic != topic {
+		return common.Address{}, fmt.Errorf("%w: %q instead of %q", ErrTopicMismatch, msg.Topic, topic)
+	}
+	if v.trusted != nil {
+		if _, ok := v.trusted[msg.Signer]; !ok {
+			return common.Address{}, fmt.Errorf("%w: %s", ErrUntrustedSigner, msg.Signer)
+		}
+	}
+	if len(msg.Nonce) != NonceSize {
+		return common.Address{}, fmt.Errorf("%w: %d bytes", ErrInvalidNonce, len(msg.Nonce))
+	}
+	signedAt := time.UnixMilli(msg.Timestamp)
+	if signedAt.Before(now.Add(-v.window)) || signedAt.After(now.Add(v.window)) {
+		return common.Address{}, fmt.Errorf("%w: signed at %s", ErrStaleMessage, signedAt.UTC().Format(time.RFC3339))
+	}
+	if err := VerifySignature(msg); err != nil {
+		return common.Address{}, err
+	}
+
+	key := string(msg.Signer.Bytes()) + string(msg.Nonce)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.prune(now)
+	if _, ok := v.seen[key]; ok {
+		return common.Address{}, fmt.Errorf("%w: nonce %s of %s", ErrReplayedMessage, msg.Nonce, msg.Signer)
+	}
+	// the nonce is remembered until the message leaves the replay window
+	v.seen[key] = signedAt.Add(v.window)
+	return msg.Signer, nil
+}
+
+// prune forgets the nonces of the messages out of the replay window, at most once per window
+func (v *Verifier) prune(now time.Time) {
+	if now.Sub(v.lastPrune) < v.window {
+		return
+	}
+	for key, expiry := range v.seen {
+		if expiry.Before(now) {
+			delete(v.seen, key)
+		}
+	}
+	v.lastPrune = now
+}
@@ -0,0 +1,122 @@
+package gossip
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/keys"
+)
+
+func newSigner(t *testing.T) keys.Signer {
+	ring := keys.NewKeyRing()
+	address, err := ring.Generate()
+	require.NoError(t, err)
+	signer, err := ring.Signer(address)
+	require.NoError(t, err)
+	return signer
+}
+
+func TestWrapAndVerify(t *testing.T) {
+	signer := newSigner(t)
+	now := time.Unix(1700000000, 0)
+	msg, err := Wrap(signer, "replicate_piece", []byte(`{"object_id":"7"}`), now)
+	require.NoError(t, err)
+	assert.NoError(t, VerifySignature(msg))
+
+	// the message is verified after a json round trip
+	data, err := json.Marshal(msg)
+	require.NoError(t, err)
+	var received Message
+	require.NoError(t, json.Unmarshal(data, &received))
+	verifier := NewVerifier(WithReplayWindow(time.Minute))
+	from, err := verifier.Verify(&received, "replicate_piece", now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), from)
+
+	_, err = verifier.Verify(&received, "replicate_piece", now.Add(40*time.Second))
+	assert.ErrorIs(t, err, ErrReplayedMessage)
+	_, err = NewVerifier().Verify(&received, "migrate_bucket", now)
+	assert.ErrorIs(t, err, ErrTopicMismatch)
+	_, err = verifier.Verify(msg, "replicate_piece", now.Add(2*time.Minute))
+	assert.ErrorIs(t, err, ErrStaleMessage)
+	_, err = verifier.Verify(msg, "replicate_piece", now.Add(-2*time.Minute))
+	assert.ErrorIs(t, err, ErrStaleMessage)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	signer := newSigner(t)
+	other := newSigner(t)
+	now := time.Now()
+	msg, err := Wrap(signer, "migrate_bucket", []byte("bucket"), now)
+	require.NoError(t, err)
+
+	tampered := *msg
+	tampered.Payload = []byte("other bucket")
+	_, err = NewVerifier().Verify(&tampered, "migrate_bucket", now)
+	assert.ErrorIs(t, err, ErrSignerMismatch)
+	tampered = *msg
+	tampered.Timestamp++
+	assert.ErrorIs(t, VerifySignature(&tampered), ErrSignerMismatch)
+	tampered = *msg
+	tampered.Signer = other.Address()
+	assert.ErrorIs(t, VerifySignature(&tampered), ErrSignerMismatch)
+	tampered = *msg
+	tampered.Signature = tampered.Signature[:64]
+	assert.ErrorIs(t, VerifySignature(&tampered), ErrInvalidSignature)
+	tampered = *msg
+	tampered.Nonce = nil
+	_, err = NewVerifier().Verify(&tampered, "migrate_bucket", now)
+	assert.ErrorIs(t, err, ErrInvalidNonce)
+
+	_, err = NewVerifier(WithTrustedSigners(other.Address())).Verify(msg, "migrate_bucket", now)
+	assert.ErrorIs(t, err, ErrUntrustedSigner)
+	from, err := NewVerifier(WithTrustedSigners(other.Address(), signer.Address())).Verify(msg, "migrate_bucket", now)
+	require.NoError(t, err)
+	assert.Equal(t, signer.Address(), from)
+}
+
+func TestVerifierConcurrentReplay(t *testing.T) {
+	signer := newSigner(t)
+	now := time.Now()
+	msg, err := Wrap(signer, "replicate_piece", []byte("piece"), now)
+	require.NoError(t, err)
+	verifier := NewVerifier()
+	var wg sync.WaitGroup
+	accepted := make(chan common.Address, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if from, err := verifier.Verify(msg, "replicate_piece", now); err == nil {
+				accepted <- from
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, accepted, 1)
+}
+
+func TestVerifierPrune(t *testing.T) {
+	signer := newSigner(t)
+	start := time.Now()
+	verifier := NewVerifier(WithReplayWindow(time.Minute))
+	for i := 0; i < 10; i++ {
+		msg, err := Wrap(signer, "replicate_piece", nil, start)
+		require.NoError(t, err)
+		_, err = verifier.Verify(msg, "replicate_piece", start)
+		require.NoError(t, err)
+	}
+	assert.Len(t, verifier.seen, 10)
+	later := start.Add(3 * time.Minute)
+	msg, err := Wrap(signer, "replicate_piece", nil, later)
+	require.NoError(t, err)
+	_, err = verifier.Verify(msg, "replicate_piece", later)
+	require.NoError(t, err)
+	assert.Len(t, verifier.seen, 1)
+}
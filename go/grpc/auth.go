@@ -0,0 +1,315 @@
+// Package grpc provides the unary and stream interceptors shared by the gRPC services of mechain: the signing of the
+// requests with the GNFD1-ECDSA auth type and their verification, the propagation of the request IDs, logging,
+// Prometheus metrics, the recovery of the panics and per-method rate limiting. The server interceptors are meant to be
+// chained with the request ID and the recovery first, e.g.
+//
+//	grpc.NewServer(grpc.ChainUnaryInterceptor(
+//		commongrpc.UnaryServerRequestID(),
+//		commongrpc.UnaryServerRecovery(nil),
+//		commongrpc.UnaryServerLogging(logger),
+//		commongrpc.UnaryServerMetrics(metrics.NewGRPCMetrics("mechain")),
+//		limiter.UnaryServerInterceptor(),
+//		authenticator.UnaryServerInterceptor(),
+//	))
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+)
+
+const (
+	// MetadataAuthorization is the metadata key of the authorization of a request, formatted like the
+	// Authorization header of the http requests, e.g. "GNFD1-ECDSA, Signature=<hex signature>"
+	MetadataAuthorization = "authorization"
+	// MetadataExpiryTimestamp is the metadata key of the signed expiry timestamp of a request, in the
+	// ExpiryTimestampFormat of the http requests
+	MetadataExpiryTimestamp = "x-gnfd-expiry-timestamp"
+	// DefaultRequestExpiry is the validity of the authorization of the requests signed by the client interceptors
+	DefaultRequestExpiry = time.Minute
+	// signDomain separates the digests of the gRPC requests from the other digests signed by the same keys
+	signDomain = "mechain-grpc-v1"
+)
+
+// ErrNotProtoMessage is returned when a signed unary request is not a protobuf message
+var ErrNotProtoMessage = errors.New("request is not a protobuf message")
+
+type callerKey struct{}
+
+type callerHolderKey struct{}
+
+// callerHolder reports the caller authenticated by an Authenticator to the outer interceptors, e.g. the logging ones
+type callerHolder struct {
+	mu     sync.Mutex
+	caller common.Address
+	ok     bool
+}
+
+func (h *callerHolder) set(caller common.Address) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.caller, h.ok = caller, true
+}
+
+func (h *callerHolder) get() (common.Address, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.caller, h.ok
+}
+
+// CallerFromContext return the address of the caller authenticated by an Authenticator
+func CallerFromContext(ctx context.Context) (common.Address, bool) {
+	caller, ok := ctx.Value(callerKey{}).(common.Address)
+	return caller, ok
+}
+
+// RequestDigest return the digest signed for a request of the full method, e.g. "/grpc.health.v1.Health/Check",
+// valid until the expiry timestamp. The payload is the deterministic protobuf encoding of a unary request, and is
+// empty for a stream, whose authorization only covers its method.
+func RequestDigest(fullMethod, expiryTimestamp string, payload []byte) []byte {
+	payloadHash := sha256.Sum256(payload)
+	return crypto.Keccak256([]byte(signDomain + "\n" + fullMethod + "\n" + expiryTimestamp + "\n" +
+		hex.EncodeToString(payloadHash[:])))
+}
+
+// ClientAuthOption configures the client interceptors signing the requests
+type ClientAuthOption func(*clientAuth)
+
+type clientAuth struct {
+	privateKey *ecdsa.PrivateKey
+	expiry     time.Duration
+	now        func() time.Time
+}
+
+// WithRequestExpiry sets the validity of the authorization of the requests, DefaultRequestExpiry by default
+func WithRequestExpiry(expiry time.Duration) ClientAuthOption {
+	return func(a *clientAuth) {
+		a.expiry = expiry
+	}
+}
+
+// WithClientClock dates the expiry timestamps of the requests from the time of c instead of the system clock, e.g. a
+// clock.Fake in tests
+func WithClientClock(c clock.Clock) ClientAuthOption {
+	return func(a *clientAuth) {
+		a.now = c.Now
+	}
+}
+
+func newClientAuth(privateKey *ecdsa.PrivateKey, opts []ClientAuthOption) *clientAuth {
+	a := &clientAuth{privateKey: privateKey, expiry: DefaultRequestExpiry, now: time.Now}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// sign return the context of the request with its authorization in the outgoing metadata
+func (a *clientAuth) sign(ctx context.Context, fullMethod string, payload []byte) (context.Context, error) {
+	expiryTimestamp := a.now().Add(a.expiry).UTC().Format(commonhttp.ExpiryTimestampFormat)
+	sig, err := crypto.Sign(RequestDigest(fullMethod, expiryTimestamp, payload), a.privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx,
+		MetadataExpiryTimestamp, expiryTimestamp,
+		MetadataAuthorization, commonhttp.AuthorizationHeader(commonhttp.Gnfd1Ecdsa, sig),
+	), nil
+}
+
+// UnarySigningInterceptor signs the unary requests with the private key in the GNFD1-ECDSA auth type
+func UnarySigningInterceptor(privateKey *ecdsa.PrivateKey, opts ...ClientAuthOption) grpc.UnaryClientInterceptor {
+	a := newClientAuth(privateKey, opts)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption,
+	) error {
+		payload, err := marshalRequest(req)
+		if err != nil {
+			return err
+		}
+		if ctx, err = a.sign(ctx, method, payload); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, callOpts...)
+	}
+}
+
+// StreamSigningInterceptor signs the streams with the private key in the GNFD1-ECDSA auth type
+func StreamSigningInterceptor(privateKey *ecdsa.PrivateKey, opts ...ClientAuthOption) grpc.StreamClientInterceptor {
+	a := newClientAuth(privateKey, opts)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		ctx, err := a.sign(ctx, method, nil)
+		if err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, callOpts...)
+	}
+}
+
+// Authenticator verifies the authorization of the requests signed by the signing interceptors and sets the address
+// of their caller in the context of the handlers, see CallerFromContext
+type Authenticator struct {
+	callers map[common.Address]struct{}
+	public  map[string]struct{}
+	now     func() time.Time
+}
+
+// AuthOption configures an Authenticator
+type AuthOption func(*Authenticator)
+
+// WithAuthorizedCallers only serves the requests signed by the keys of the callers, the requests signed by any key
+// are served by default
+func WithAuthorizedCallers(callers ...common.Address) AuthOption {
+	return func(a *Authenticator) {
+		a.callers = make(map[common.Address]struct{}, len(callers))
+		for _, caller := range callers {
+			a.callers[caller] = struct{}{}
+		}
+	}
+}
+
+// WithPublicMethods serves the requests of the full methods without authorization, e.g. the health checks
+func WithPublicMethods(fullMethods ...string) AuthOption {
+	return func(a *Authenticator) {
+		for _, method := range fullMethods {
+			a.public[method] = struct{}{}
+		}
+	}
+}
+
+// WithClock checks the expiry timestamps of the requests at the time of c instead of the system clock, e.g. a
+// clock.Fake in tests
+func WithClock(c clock.Clock) AuthOption {
+	return func(a *Authenticator) {
+		a.now = c.Now
+	}
+}
+
+// NewAuthenticator return an Authenticator of the requests
+func NewAuthenticator(opts ...AuthOption) *Authenticator {
+	a := &Authenticator{public: make(map[string]struct{}), now: time.Now}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Authenticate verify the authorization of the request of the full method at now and return its caller, the
+// errors are gRPC status errors
+func (a *Authenticator) Authenticate(ctx context.Context, fullMethod string, payload []byte) (common.Address,
+	error,
+) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	authorization := md.Get(MetadataAuthorization)
+	if len(authorization) != 1 {
+		return common.Address{}, status.Error(codes.Unauthenticated, commonhttp.ErrMissingAuthorization.Error())
+	}
+	authType, sig, err := commonhttp.ParseAuthorizationHeader(authorization[0])
+	if err != nil {
+		return common.Address{}, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if authType != commonhttp.Gnfd1Ecdsa {
+		return common.Address{}, status.Errorf(codes.Unauthenticated, "%s: %s", commonhttp.ErrUnsupportedAuthType,
+			authType)
+	}
+	var expiryTimestamp string
+	if values := md.Get(MetadataExpiryTimestamp); len(values) == 1 {
+		expiryTimestamp = values[0]
+	}
+	if err = commonhttp.CheckExpiryTimestamp(expiryTimestamp, a.now()); err != nil {
+		return common.Address{}, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, status.Errorf(codes.Unauthenticated, "%s: signature length %d",
+			commonhttp.ErrInvalidAuthorization, len(sig))
+	}
+	pubKey, err := crypto.SigToPub(RequestDigest(fullMethod, expiryTimestamp, payload), sig)
+	if err != nil {
+		return common.Address{}, status.Errorf(codes.Unauthenticated, "%s: %s", commonhttp.ErrInvalidAuthorization,
+			err)
+	}
+	caller := crypto.PubkeyToAddress(*pubKey)
+	if a.callers != nil {
+		if _, ok := a.callers[caller]; !ok {
+			return common.Address{}, status.Errorf(codes.PermissionDenied, "caller %s is not authorized", caller)
+		}
+	}
+	if holder, ok := ctx.Value(callerHolderKey{}).(*callerHolder); ok {
+		holder.set(caller)
+	}
+	return caller, nil
+}
+
+// UnaryServerInterceptor return the interceptor verifying the authorization of the unary requests
+func (a *Authenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(ctx, req)
+		}
+		payload, err := marshalRequest(req)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		caller, err := a.Authenticate(ctx, info.FullMethod, payload)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, callerKey{}, caller), req)
+	}
+}
+
+// StreamServerInterceptor return the interceptor verifying the authorization of the streams
+func (a *Authenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := a.public[info.FullMethod]; ok {
+			return handler(srv, ss)
+		}
+		caller, err := a.Authenticate(ss.Context(), info.FullMethod, nil)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), callerKey{}, caller)})
+	}
+}
+
+// contextStream is a grpc.ServerStream with another context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// marshalRequest return the deterministic protobuf encoding of a unary request, the same on the client and the
+// server for a given message
+func marshalRequest(req interface{}) ([]byte, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, req)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
@@ -0,0 +1,170 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+)
+
+// serve starts a health service with the server options and return a client connection dialed with the dial options
+func serve(t *testing.T, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOpts...)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("mechain", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestAuthenticator(t *testing.T) {
+	callerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	caller := crypto.PubkeyToAddress(callerKey.PublicKey)
+
+	var authenticated interface{}
+	recordCaller := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		authenticated, _ = CallerFromContext(ctx)
+		return handler(ctx, req)
+	}
+	authenticator := NewAuthenticator(WithAuthorizedCallers(caller))
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor(), recordCaller),
+		grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor()),
+	}
+	ctx := context.Background()
+	req := &healthpb.HealthCheckRequest{Service: "mechain"}
+
+	// signed by an authorized caller
+	conn := serve(t, serverOpts,
+		grpc.WithUnaryInterceptor(UnarySigningInterceptor(callerKey)),
+		grpc.WithStreamInterceptor(StreamSigningInterceptor(callerKey)),
+	)
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, caller, authenticated)
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, req)
+	require.NoError(t, err)
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	// signed by another caller
+	conn = serve(t, serverOpts, grpc.WithUnaryInterceptor(UnarySigningInterceptor(otherKey)))
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// not signed
+	conn = serve(t, serverOpts)
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, req)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	stream, err = healthpb.NewHealthClient(conn).Watch(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// public method
+	conn = serve(t, []grpc.ServerOption{grpc.UnaryInterceptor(
+		NewAuthenticator(WithAuthorizedCallers(caller), WithPublicMethods(healthpb.Health_Check_FullMethodName)).
+			UnaryServerInterceptor(),
+	)})
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, req)
+	assert.NoError(t, err)
+}
+
+func TestAuthenticate(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	c := clock.NewFake(time.Unix(1700000000, 0))
+	now := c.Now()
+	authenticator := NewAuthenticator(WithClock(c))
+	method := healthpb.Health_Check_FullMethodName
+	payload := []byte("payload")
+
+	incoming := func(expiry time.Time, method string, payload []byte) context.Context {
+		expiryTimestamp := expiry.UTC().Format(commonhttp.ExpiryTimestampFormat)
+		sig, err := crypto.Sign(RequestDigest(method, expiryTimestamp, payload), privateKey)
+		require.NoError(t, err)
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			MetadataExpiryTimestamp, expiryTimestamp,
+			MetadataAuthorization, commonhttp.AuthorizationHeader(commonhttp.Gnfd1Ecdsa, sig),
+		))
+	}
+
+	caller, err := authenticator.Authenticate(incoming(now.Add(time.Minute), method, payload), method, payload)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey), caller)
+
+	// the signature covers the method and the payload
+	caller, err = authenticator.Authenticate(incoming(now.Add(time.Minute), method, payload), method, []byte("other"))
+	if err == nil {
+		assert.NotEqual(t, crypto.PubkeyToAddress(privateKey.PublicKey), caller)
+	}
+	caller, err = authenticator.Authenticate(incoming(now.Add(time.Minute), "/other", payload), method, payload)
+	if err == nil {
+		assert.NotEqual(t, crypto.PubkeyToAddress(privateKey.PublicKey), caller)
+	}
+
+	_, err = authenticator.Authenticate(incoming(now.Add(-time.Second), method, payload), method, payload)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Contains(t, err.Error(), commonhttp.ErrRequestExpired.Error())
+
+	// the requests expire on the clock of the signer
+	signed, err := newClientAuth(privateKey, []ClientAuthOption{WithClientClock(c)}).sign(context.Background(), method,
+		payload)
+	require.NoError(t, err)
+	outgoing, _ := metadata.FromOutgoingContext(signed)
+	ctx := metadata.NewIncomingContext(context.Background(), outgoing)
+	_, err = authenticator.Authenticate(ctx, method, payload)
+	require.NoError(t, err)
+	c.Advance(DefaultRequestExpiry + time.Second)
+	_, err = authenticator.Authenticate(ctx, method, payload)
+	assert.Contains(t, err.Error(), commonhttp.ErrRequestExpired.Error())
+
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		MetadataExpiryTimestamp, c.Now().Add(time.Minute).UTC().Format(commonhttp.ExpiryTimestampFormat),
+		MetadataAuthorization, "GNFD1-EDDSA, Signature=00",
+	))
+	_, err = authenticator.Authenticate(ctx, method, payload)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Contains(t, err.Error(), commonhttp.ErrUnsupportedAuthType.Error())
+
+	_, err = authenticator.Authenticate(context.Background(), method, payload)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+// UnaryServerLogging logs the unary requests with their code and duration to the logger, e.g. log.NewSlogLogger or
+// zerologger.New: the successful requests at debug level, the server errors at error level and the other errors at
+// warn level. The logs are prefixed with the request ID set by UnaryServerRequestID.
+func UnaryServerLogging(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		// the caller is authenticated by an inner interceptor, which reports it through the holder
+		holder := &callerHolder{}
+		resp, err := handler(context.WithValue(ctx, callerHolderKey{}, holder), req)
+		logRequest(requestid.Logger(ctx, logger), holder, info.FullMethod, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamServerLogging logs the streams with their code and duration to the logger, like UnaryServerLogging
+func StreamServerLogging(logger log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		holder := &callerHolder{}
+		ctx := context.WithValue(ss.Context(), callerHolderKey{}, holder)
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		logRequest(requestid.Logger(ctx, logger), holder, info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
+func logRequest(logger log.Logger, holder *callerHolder, fullMethod string, duration time.Duration, err error) {
+	code := status.Code(err)
+	caller := "anonymous"
+	if address, ok := holder.get(); ok {
+		caller = address.String()
+	}
+	switch {
+	case err == nil:
+		logger.Debugf("grpc %s: code=%s caller=%s duration=%s", fullMethod, code, caller, duration)
+	case isServerError(code):
+		logger.Errorf("grpc %s: code=%s caller=%s duration=%s error=%v", fullMethod, code, caller, duration, err)
+	default:
+		logger.Warnf("grpc %s: code=%s caller=%s duration=%s error=%v", fullMethod, code, caller, duration, err)
+	}
+}
+
+// isServerError reports whether the code is returned for a fault of the server rather than of the request
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable,
+		codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
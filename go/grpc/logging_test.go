@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+type recordCollector struct {
+	mu      sync.Mutex
+	methods []string
+	codes   []codes.Code
+}
+
+func (c *recordCollector) ObserveRequest(fullMethod string, code codes.Code, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.methods = append(c.methods, fullMethod)
+	c.codes = append(c.codes, code)
+}
+
+func TestServerLoggingAndMetrics(t *testing.T) {
+	logger := &recordLogger{}
+	collector := &recordCollector{}
+	conn := serve(t, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerLogging(logger), UnaryServerMetrics(collector)),
+		grpc.ChainStreamInterceptor(StreamServerLogging(logger), StreamServerMetrics(collector)),
+	})
+	client := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "mechain"})
+	require.NoError(t, err)
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	watchCtx, cancel := context.WithCancel(ctx)
+	stream, err := client.Watch(watchCtx, &healthpb.HealthCheckRequest{Service: "mechain"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	cancel()
+	// the stream is logged once its handler returned
+	assert.Eventually(t, func() bool {
+		collector.mu.Lock()
+		defer collector.mu.Unlock()
+		return len(collector.codes) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+
+	assert.Equal(t, []string{healthpb.Health_Check_FullMethodName, healthpb.Health_Check_FullMethodName,
+		healthpb.Health_Watch_FullMethodName}, collector.methods)
+	assert.Equal(t, []codes.Code{codes.OK, codes.NotFound, codes.Canceled}, collector.codes)
+	require.Len(t, logger.debugs, 1)
+	assert.Contains(t, logger.debugs[0], "code=OK caller=anonymous")
+	require.Len(t, logger.warns, 2)
+	assert.Contains(t, logger.warns[0], "code=NotFound")
+	assert.Contains(t, logger.warns[1], "code=Canceled")
+	assert.Empty(t, logger.errors)
+}
+
+func TestServerLoggingCaller(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	logger := &recordLogger{}
+	conn := serve(t, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerLogging(logger), NewAuthenticator().UnaryServerInterceptor()),
+	}, grpc.WithUnaryInterceptor(UnarySigningInterceptor(privateKey)))
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	require.Len(t, logger.debugs, 1)
+	assert.Contains(t, logger.debugs[0], "caller="+crypto.PubkeyToAddress(privateKey.PublicKey).String())
+}
@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsCollector collects the measurements of the served requests, e.g. metrics.GRPCMetrics exporting them to
+// Prometheus
+type MetricsCollector interface {
+	// ObserveRequest is called after a unary request or a stream of the full method was served with the code
+	ObserveRequest(fullMethod string, code codes.Code, latency time.Duration)
+}
+
+// UnaryServerMetrics reports the unary requests to the collector
+func UnaryServerMetrics(collector MetricsCollector) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		collector.ObserveRequest(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamServerMetrics reports the streams to the collector once closed
+func StreamServerMetrics(collector MetricsCollector) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		collector.ObserveRequest(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limit is the rate of the requests of a method served by a RateLimiter, a zero Rate does not limit the requests
+type Limit struct {
+	// Rate is the number of requests per second
+	Rate float64
+	// Burst is the number of requests which can be served at once, at least 1
+	Burst int
+}
+
+// RateLimiter limits the rate of the requests of each method with a token bucket. It is safe for concurrent use.
+type RateLimiter struct {
+	defaultLimit Limit
+	limits       map[string]Limit
+	now          func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// bucket is the token bucket of a method, refilled at the rate of its limit
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimitOption configures a RateLimiter
+type RateLimitOption func(*RateLimiter)
+
+// WithMethodLimit limits the requests of the full method, e.g. "/grpc.health.v1.Health/Check", instead of the
+// default limit
+func WithMethodLimit(fullMethod string, limit Limit) RateLimitOption {
+	return func(l *RateLimiter) {
+		l.limits[fullMethod] = limit
+	}
+}
+
+// NewRateLimiter return a RateLimiter limiting the requests of each method to the default limit
+func NewRateLimiter(defaultLimit Limit, opts ...RateLimitOption) *RateLimiter {
+	l := &RateLimiter{
+		defaultLimit: defaultLimit,
+		limits:       make(map[string]Limit),
+		now:          time.Now,
+		buckets:      make(map[string]*bucket),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow reports whether a request of the full method can be served now, and consumes a token of its bucket if so
+func (l *RateLimiter) Allow(fullMethod string) bool {
+	limit, ok := l.limits[fullMethod]
+	if !ok {
+		limit = l.defaultLimit
+	}
+	if limit.Rate <= 0 {
+		return true
+	}
+	burst := math.Max(float64(limit.Burst), 1)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[fullMethod]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[fullMethod] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*limit.Rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// UnaryServerInterceptor return the interceptor rejecting the unary requests over the limit of their method with
+// ResourceExhausted
+func (l *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !l.Allow(info.FullMethod) {
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded", info.FullMethod)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor return the interceptor rejecting the streams over the limit of their method with
+// ResourceExhausted
+func (l *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !l.Allow(info.FullMethod) {
+			return status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded", info.FullMethod)
+		}
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewRateLimiter(Limit{Rate: 1, Burst: 2}, WithMethodLimit("/slow", Limit{Rate: 0.5, Burst: 1}),
+		WithMethodLimit("/free", Limit{}))
+	limiter.now = func() time.Time { return now }
+
+	// the bursts are served at once, then the requests at the rate
+	assert.True(t, limiter.Allow("/fast"))
+	assert.True(t, limiter.Allow("/fast"))
+	assert.False(t, limiter.Allow("/fast"))
+	assert.True(t, limiter.Allow("/slow"))
+	assert.False(t, limiter.Allow("/slow"))
+	for i := 0; i < 100; i++ {
+		assert.True(t, limiter.Allow("/free"))
+	}
+
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow("/fast"))
+	assert.False(t, limiter.Allow("/fast"))
+	assert.False(t, limiter.Allow("/slow"))
+	now = now.Add(time.Second)
+	assert.True(t, limiter.Allow("/slow"))
+
+	// the tokens are capped by the burst
+	now = now.Add(time.Hour)
+	assert.True(t, limiter.Allow("/fast"))
+	assert.True(t, limiter.Allow("/fast"))
+	assert.False(t, limiter.Allow("/fast"))
+
+	handler := func(context.Context, interface{}) (interface{}, error) { return "ok", nil }
+	_, err := limiter.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/fast"},
+		handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	resp, err := limiter.UnaryServerInterceptor()(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/other"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+// RecoveryHandler return the error of a request of the full method whose handler panicked with p, stack is the stack
+// trace of the panic
+type RecoveryHandler func(ctx context.Context, fullMethod string, p interface{}, stack []byte) error
+
+// DefaultRecoveryHandler logs the panic and its stack trace with the logger of the log package and return an
+// Internal error, without the details of the panic which are only logged
+func DefaultRecoveryHandler(_ context.Context, fullMethod string, p interface{}, stack []byte) error {
+	log.Errorf("grpc %s: panic: %v\n%s", fullMethod, p, stack)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerRecovery recovers the panics of the unary handlers and return the error of the handler,
+// DefaultRecoveryHandler if nil
+func UnaryServerRecovery(handler RecoveryHandler) grpc.UnaryServerInterceptor {
+	if handler == nil {
+		handler = DefaultRecoveryHandler
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		next grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				resp, err = nil, handler(ctx, info.FullMethod, p, debug.Stack())
+			}
+		}()
+		return next(ctx, req)
+	}
+}
+
+// StreamServerRecovery recovers the panics of the stream handlers like UnaryServerRecovery
+func StreamServerRecovery(handler RecoveryHandler) grpc.StreamServerInterceptor {
+	if handler == nil {
+		handler = DefaultRecoveryHandler
+	}
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, next grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = handler(ss.Context(), info.FullMethod, p, debug.Stack())
+			}
+		}()
+		return next(srv, ss)
+	}
+}
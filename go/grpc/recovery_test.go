@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+type recordLogger struct {
+	log.NopLogger
+	mu                    sync.Mutex
+	debugs, warns, errors []string
+}
+
+func (l *recordLogger) Debugf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debugs = append(l.debugs, fmt.Sprintf(format, args...))
+}
+
+func (l *recordLogger) Warnf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.warns = append(l.warns, fmt.Sprintf(format, args...))
+}
+
+func (l *recordLogger) Errorf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestServerRecovery(t *testing.T) {
+	logger := &recordLogger{}
+	log.SetLogger(logger)
+	defer log.SetLogger(log.NopLogger{})
+
+	conn := serve(t, []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerRecovery(nil), func(context.Context, interface{}, *grpc.UnaryServerInfo,
+			grpc.UnaryHandler,
+		) (interface{}, error) {
+			panic("unary boom")
+		}),
+		grpc.ChainStreamInterceptor(StreamServerRecovery(nil), func(interface{}, grpc.ServerStream,
+			*grpc.StreamServerInfo, grpc.StreamHandler,
+		) error {
+			panic("stream boom")
+		}),
+	})
+	ctx := context.Background()
+	req := &healthpb.HealthCheckRequest{}
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, req)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.NotContains(t, err.Error(), "boom")
+	stream, err := healthpb.NewHealthClient(conn).Watch(ctx, req)
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	require.Len(t, logger.errors, 2)
+	assert.Contains(t, logger.errors[0], "panic: unary boom")
+	assert.Contains(t, logger.errors[0], "runtime/debug.Stack")
+	assert.Contains(t, logger.errors[1], "panic: stream boom")
+
+	var recovered interface{}
+	interceptor := UnaryServerRecovery(func(_ context.Context, _ string, p interface{}, _ []byte) error {
+		recovered = p
+		return status.Error(codes.Unavailable, "retry")
+	})
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/panic"},
+		func(context.Context, interface{}) (interface{}, error) {
+			panic(42)
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 42, recovered)
+}
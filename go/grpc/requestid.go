@@ -0,0 +1,56 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+// UnaryServerRequestID sets the ID of the unary requests in their context, the ID of their metadata or a new random
+// ID, see requestid.FromContext
+func UnaryServerRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		return handler(requestContext(ctx), req)
+	}
+}
+
+// StreamServerRequestID sets the ID of the streams in their context like UnaryServerRequestID
+func StreamServerRequestID() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: requestContext(ss.Context())})
+	}
+}
+
+// UnaryClientRequestID propagates the request ID of the context of the unary requests in their metadata, a new
+// random ID if the context carries none
+func UnaryClientRequestID() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption,
+	) error {
+		ctx, _ = requestid.Ensure(ctx)
+		return invoker(requestid.InjectMetadata(ctx), method, req, reply, cc, callOpts...)
+	}
+}
+
+// StreamClientRequestID propagates the request ID of the context of the streams like UnaryClientRequestID
+func StreamClientRequestID() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, callOpts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		ctx, _ = requestid.Ensure(ctx)
+		return streamer(requestid.InjectMetadata(ctx), desc, cc, method, callOpts...)
+	}
+}
+
+// requestContext return the context carrying the request ID of its incoming metadata or a new one
+func requestContext(ctx context.Context) context.Context {
+	id, ok := requestid.ExtractMetadata(ctx)
+	if !ok {
+		id = requestid.New()
+	}
+	return requestid.NewContext(ctx, id)
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	recordID := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		mu.Lock()
+		ids = append(ids, requestid.FromContext(ctx))
+		mu.Unlock()
+		return handler(ctx, req)
+	}
+	logger := &recordLogger{}
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(UnaryServerRequestID(), UnaryServerLogging(logger), recordID),
+	}
+	req := &healthpb.HealthCheckRequest{}
+
+	// the request ID of the client context is propagated
+	conn := serve(t, serverOpts, grpc.WithUnaryInterceptor(UnaryClientRequestID()))
+	_, err := healthpb.NewHealthClient(conn).Check(requestid.NewContext(context.Background(), "gateway-42"), req)
+	require.NoError(t, err)
+	// a new request ID is set by the client
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), req)
+	require.NoError(t, err)
+	// a new request ID is set by the server
+	conn = serve(t, serverOpts)
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), req)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, ids, 3)
+	assert.Equal(t, "gateway-42", ids[0])
+	assert.Len(t, ids[1], 32)
+	assert.Len(t, ids[2], 32)
+	assert.NotEqual(t, ids[1], ids[2])
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	require.Len(t, logger.debugs, 3)
+	assert.Contains(t, logger.debugs[0], "request_id=gateway-42 grpc /grpc.health.v1.Health/Check: code=OK")
+}
@@ -4,12 +4,12 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/keys/eth/ethsecp256k1"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 )
 
-// RecoverAddr recovers the sender address from msg and signature
+// RecoverAddr recovers the sender address from msg and signature. The recovery falls back to a pure Go
+// implementation without cgo, e.g. in the wasm build.
 func RecoverAddr(msg []byte, sig []byte) (sdk.AccAddress, ethsecp256k1.PubKey, error) {
-	pubKeyByte, err := secp256k1.RecoverPubkey(msg, sig)
+	pubKeyByte, err := ethcrypto.Ecrecover(msg, sig)
 	if err != nil {
 		return nil, ethsecp256k1.PubKey{}, err
 	}
@@ -0,0 +1,95 @@
+package hash
+
+import (
+	"fmt"
+	"slices"
+)
+
+// HasherCheckpoint is the state of an IntegrityHasher after its complete segments, it is serializable so the
+// computation of an interrupted upload can resume from the last segment instead of restarting
+type HasherCheckpoint struct {
+	SegmentSize  int64 `json:"segment_size"`
+	DataShards   int   `json:"data_shards"`
+	ParityShards int   `json:"parity_shards"`
+	// ContentLength is the size of the hashed segments
+	ContentLength int64 `json:"content_length"`
+	// SegmentChecksums are the checksums of the hashed segments
+	SegmentChecksums [][]byte `json:"segment_checksums"`
+	// PieceChecksums are the checksums of the ec pieces of the hashed segments, indexed by ec index then segment
+	PieceChecksums [][][]byte `json:"piece_checksums"`
+}
+
+// Checkpoint return the state of the hasher after its complete segments, the data buffered since the last complete
+// segment is not part of it and must be appended again to the restored hasher
+func (i *IntegrityHasher) Checkpoint() HasherCheckpoint {
+	pieceChecksums := make([][][]byte, len(i.ecDataHashes))
+	for index, checksums := range i.ecDataHashes {
+		pieceChecksums[index] = slices.Clone(checksums)
+	}
+	return HasherCheckpoint{
+		SegmentSize:      i.segmentSize,
+		DataShards:       i.dataShards,
+		ParityShards:     i.parityShards,
+		ContentLength:    i.contentLen,
+		SegmentChecksums: slices.Clone(i.segHashes),
+		PieceChecksums:   pieceChecksums,
+	}
+}
+
+// Truncate return the checkpoint of the first segments of the checkpoint
+func (c HasherCheckpoint) Truncate(segments int) (HasherCheckpoint, error) {
+	if segments < 0 || segments > len(c.SegmentChecksums) {
+		return HasherCheckpoint{}, fmt.Errorf("%w: %d segments, checkpoint of %d", ErrInvalidCheckpoint, segments,
+			len(c.SegmentChecksums))
+	}
+	if segments == len(c.SegmentChecksums) {
+		return c, nil
+	}
+	truncated := c
+	truncated.ContentLength = int64(segments) * c.SegmentSize
+	truncated.SegmentChecksums = c.SegmentChecksums[:segments:segments]
+	truncated.PieceChecksums = make([][][]byte, len(c.PieceChecksums))
+	for index, checksums := range c.PieceChecksums {
+		truncated.PieceChecksums[index] = checksums[:segments:segments]
+	}
+	return truncated, nil
+}
+
+// Validate return ErrInvalidCheckpoint if the checksums do not match the content length and the ec params
+func (c HasherCheckpoint) Validate() error {
+	segments := int64(len(c.SegmentChecksums))
+	switch {
+	case c.SegmentSize <= 0 || c.DataShards <= 0 || c.ParityShards < 0:
+		return fmt.Errorf("%w: segment size %d, %d data shards, %d parity shards", ErrInvalidCheckpoint,
+			c.SegmentSize, c.DataShards, c.ParityShards)
+	case c.ContentLength > segments*c.SegmentSize || segments > 0 && c.ContentLength <= (segments-1)*c.SegmentSize ||
+		segments == 0 && c.ContentLength != 0:
+		return fmt.Errorf("%w: content length %d for %d segments", ErrInvalidCheckpoint, c.ContentLength, segments)
+	case len(c.PieceChecksums) != c.DataShards+c.ParityShards:
+		return fmt.Errorf("%w: checksums of %d ec pieces, expect %d", ErrInvalidCheckpoint, len(c.PieceChecksums),
+			c.DataShards+c.ParityShards)
+	}
+	for index, checksums := range c.PieceChecksums {
+		if len(checksums) != len(c.SegmentChecksums) {
+			return fmt.Errorf("%w: %d checksums of ec piece %d for %d segments", ErrInvalidCheckpoint,
+				len(checksums), index, segments)
+		}
+	}
+	return nil
+}
+
+// RestoreHasher return a hasher in the state of the checkpoint. If the last segment of the checkpoint is shorter
+// than the segment size, it is the end of the object and no data should be appended before Finish.
+func RestoreHasher(checkpoint HasherCheckpoint) (*IntegrityHasher, error) {
+	if err := checkpoint.Validate(); err != nil {
+		return nil, err
+	}
+	hasher := NewHasher(checkpoint.SegmentSize, checkpoint.DataShards, checkpoint.ParityShards)
+	hasher.Init()
+	hasher.contentLen = checkpoint.ContentLength
+	hasher.segHashes = append(hasher.segHashes, checkpoint.SegmentChecksums...)
+	for index, checksums := range checkpoint.PieceChecksums {
+		hasher.ecDataHashes[index] = append(hasher.ecDataHashes[index], checksums...)
+	}
+	return hasher, nil
+}
@@ -0,0 +1,71 @@
+package hash
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendChunks appends the data to the hasher in chunks of chunkSize bytes
+func appendChunks(t *testing.T, hasher *IntegrityHasher, data []byte, chunkSize int) {
+	for len(data) > 0 {
+		n := min(chunkSize, len(data))
+		require.NoError(t, hasher.Append(data[:n]))
+		data = data[n:]
+	}
+}
+
+func TestHasherCheckpoint(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(5*segSize + 100)
+	expected, contentLen, _, err := ComputeIntegrityHashSerial(bytes.NewReader(content), segSize, 4, 2)
+	require.NoError(t, err)
+
+	hasher := NewHasher(segSize, 4, 2)
+	hasher.Init()
+	appendChunks(t, hasher, content[:3*segSize+10], segSize)
+	checkpoint := hasher.Checkpoint()
+	assert.Equal(t, int64(3*segSize), checkpoint.ContentLength)
+	assert.NoError(t, checkpoint.Validate())
+
+	encoded, err := json.Marshal(checkpoint)
+	require.NoError(t, err)
+	var decoded HasherCheckpoint
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	truncated, err := decoded.Truncate(2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2*segSize), truncated.ContentLength)
+
+	restored, err := RestoreHasher(truncated)
+	require.NoError(t, err)
+	appendChunks(t, restored, content[2*segSize:], segSize)
+	checksums, restoredLen, _, err := restored.Finish()
+	require.NoError(t, err)
+	assert.Equal(t, expected, checksums)
+	assert.Equal(t, contentLen, restoredLen)
+	assert.Len(t, checkpoint.SegmentChecksums, 3)
+
+	end, err := RestoreHasher(restored.Checkpoint())
+	require.NoError(t, err)
+	checksums, _, _, err = end.Finish()
+	require.NoError(t, err)
+	assert.Equal(t, expected, checksums)
+
+	_, err = checkpoint.Truncate(4)
+	assert.ErrorIs(t, err, ErrInvalidCheckpoint)
+	invalid := checkpoint
+	invalid.ContentLength = 3*segSize + 1
+	assert.ErrorIs(t, invalid.Validate(), ErrInvalidCheckpoint)
+	invalid = checkpoint
+	invalid.PieceChecksums = invalid.PieceChecksums[:5]
+	_, err = RestoreHasher(invalid)
+	assert.ErrorIs(t, err, ErrInvalidCheckpoint)
+	invalid, err = checkpoint.Truncate(0)
+	require.NoError(t, err)
+	assert.NoError(t, invalid.Validate())
+	invalid.PieceChecksums[1] = checkpoint.SegmentChecksums
+	assert.ErrorIs(t, invalid.Validate(), ErrInvalidCheckpoint)
+}
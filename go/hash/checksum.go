@@ -3,7 +3,20 @@ package hash
 import (
 	"bytes"
 	"crypto/sha256"
-	"fmt"
+	"encoding/binary"
+)
+
+// IntegrityDomain tags the role of a checksum list hashed by GenerateIntegrityHashWithDomain, so the same
+// checksums hashed in different roles never produce the same integrity hash
+type IntegrityDomain string
+
+const (
+	// DomainPrimary tags the segment checksums of the PrimarySP
+	DomainPrimary IntegrityDomain = "mechain/integrity/primary/v1"
+	// DomainSecondary tags the ec piece checksums of a SecondarySP
+	DomainSecondary IntegrityDomain = "mechain/integrity/secondary/v1"
+	// DomainMultipart tags the integrity hashes of the parts combined into the integrity hash of a multipart object
+	DomainMultipart IntegrityDomain = "mechain/integrity/multipart/v1"
 )
 
 // SegmentInfo describes segment info
@@ -27,6 +40,41 @@ func GenerateIntegrityHash(checksumList [][]byte) []byte {
 	return hash.Sum(nil)
 }
 
+// GenerateIntegrityHashWithDomain generates a domain separated integrity hash of any number of checksums of any
+// length: the sha256 of the length prefixed domain and the leaf count, followed by every checksum prefixed by its
+// index and its length. It differs from GenerateIntegrityHash, which is the root stored on chain.
+func GenerateIntegrityHashWithDomain(domain IntegrityDomain, checksumList [][]byte) []byte {
+	hash := sha256.New()
+	var prefix [8]byte
+	writeUint64 := func(v uint64) {
+		binary.BigEndian.PutUint64(prefix[:], v)
+		hash.Write(prefix[:])
+	}
+	writeUint64(uint64(len(domain)))
+	hash.Write([]byte(domain))
+	writeUint64(uint64(len(checksumList)))
+	for index, checksum := range checksumList {
+		writeUint64(uint64(index))
+		writeUint64(uint64(len(checksum)))
+		hash.Write(checksum)
+	}
+	return hash.Sum(nil)
+}
+
+// VerifyIntegrityHashWithDomain verify the domain separated integrity hash of the checksum list
+func VerifyIntegrityHashWithDomain(domain IntegrityDomain, integrityHash []byte, checksumList [][]byte) error {
+	if !bytes.Equal(integrityHash, GenerateIntegrityHashWithDomain(domain, checksumList)) {
+		return ErrIntegrityHashMismatch
+	}
+	return nil
+}
+
+// EmptyIntegrityHash return the integrity hash of an empty checksum list, which is the sha256 of empty content.
+// All the integrity hashes of an empty object are EmptyIntegrityHash.
+func EmptyIntegrityHash() []byte {
+	return GenerateIntegrityHash(nil)
+}
+
 // ChallengePieceHash challenge integrity hash and checksum list
 // integrityHash represents the integrity hash of one piece list, this piece list may be ec piece data list or
 // segment piece data list; if piece data list is ec, this list is all ec1 piece data; if piece list is segment, all
@@ -36,10 +84,10 @@ func GenerateIntegrityHash(checksumList [][]byte) []byte {
 // pieceData represents piece physical data that user want to challenge
 func ChallengePieceHash(integrityHash []byte, checksumList [][]byte, index int, pieceData []byte) error {
 	if len(checksumList) <= index {
-		return fmt.Errorf("invalid checksum list")
+		return ErrInvalidChecksumList
 	}
 	if !bytes.Equal(checksumList[index], GenerateChecksum(pieceData)) {
-		return fmt.Errorf("piece data and piece hash are inconsistent")
+		return ErrPieceChecksumMismatch
 	}
 	if err := VerifyIntegrityHash(integrityHash, checksumList); err != nil {
 		return err
@@ -50,7 +98,7 @@ func ChallengePieceHash(integrityHash []byte, checksumList [][]byte, index int,
 // VerifyIntegrityHash verify integrity hash if right
 func VerifyIntegrityHash(integrityHash []byte, checksumList [][]byte) error {
 	if !bytes.Equal(integrityHash, GenerateIntegrityHash(checksumList)) {
-		return fmt.Errorf("invalid integrity hash")
+		return ErrIntegrityHashMismatch
 	}
 	return nil
 }
@@ -0,0 +1,249 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/reedsolomon"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// greenfieldVectors stores the test vectors of the params of the Greenfield chain computed by greenfield-common itself
+// with its version, run `make greenfield-vectors` from the go module root to pin them, never regenerate them with
+// this package
+//
+//go:embed golden/greenfield_vectors.json
+var greenfieldVectors []byte
+
+const (
+	// GreenfieldSegmentSize is the segment size of the storage params of the Greenfield chain
+	GreenfieldSegmentSize = 16 * 1024 * 1024
+	// GreenfieldDataShards is the number of data shards of the storage params of the Greenfield chain
+	GreenfieldDataShards = 4
+	// GreenfieldParityShards is the number of parity shards of the storage params of the Greenfield chain
+	GreenfieldParityShards = 2
+)
+
+// errGreenfieldStopped fails the reads of CompatCheck once the greenfield-common computation returned
+var errGreenfieldStopped = errors.New("the greenfield-common computation stopped reading")
+
+// greenfieldCorpus is the content of golden/greenfield_vectors.json written by tools/greenfield-vectors
+type greenfieldCorpus struct {
+	// Module and Version identify the greenfield-common release which computed the vectors
+	Module  string             `json:"module"`
+	Version string             `json:"version"`
+	Vectors []greenfieldVector `json:"vectors"`
+}
+
+// greenfieldVector is a test vector computed by greenfield-common, InputSHA256 is the hex sha256 of its input
+type greenfieldVector struct {
+	TestVector
+	InputSHA256 string `json:"input_sha256"`
+}
+
+// ComputeGreenfieldIntegrityHash computes the integrity hashes like greenfield-common: the segments are split into
+// Reed-Solomon pieces by the reed-solomon library with its default options, the segments and the pieces are hashed
+// with sha256 and each integrity hash is the sha256 of the concatenated checksums. It is a serial reference
+// implementation independent of the optimized versions of this package, which CompatCheck compares to it. The
+// Version of the result is HashVersionV1, the version greenfield-common is compatible with.
+func ComputeGreenfieldIntegrityHash(reader io.Reader, segmentSize int64, dataShards, parityShards int) (*HashResult,
+	error,
+) {
+	encoder, err := reedsolomon.New(dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	if segmentSize <= 0 {
+		return nil, fmt.Errorf("invalid segment size %d", segmentSize)
+	}
+	var (
+		segmentChecksums [][]byte
+		pieceChecksums   = make([][][]byte, dataShards+parityShards)
+		contentLength    int64
+		segment          = make([]byte, segmentSize)
+	)
+	for {
+		n, readErr := io.ReadFull(reader, segment)
+		if n > 0 {
+			contentLength += int64(n)
+			segmentChecksums = append(segmentChecksums, GenerateChecksum(segment[:n]))
+			shards, err := encoder.Split(segment[:n])
+			if err != nil {
+				return nil, err
+			}
+			if err = encoder.Encode(shards); err != nil {
+				return nil, err
+			}
+			for index, shard := range shards {
+				pieceChecksums[index] = append(pieceChecksums[index], GenerateChecksum(shard))
+			}
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			return nil, readerError(readErr)
+		}
+	}
+	checksums := [][]byte{GenerateIntegrityHash(segmentChecksums)}
+	for _, checksumList := range pieceChecksums {
+		checksums = append(checksums, GenerateIntegrityHash(checksumList))
+	}
+	return &HashResult{
+		Version:        HashVersionV1,
+		Checksums:      checksums,
+		ContentLength:  contentLength,
+		RedundancyType: redundancy.RedundancyECType,
+	}, nil
+}
+
+// CompatReport compares the integrity hashes computed by this package to the ones of the greenfield-common algorithm
+type CompatReport struct {
+	// Result is computed by this package
+	Result *HashResult
+	// Greenfield is computed by ComputeGreenfieldIntegrityHash
+	Greenfield *HashResult
+	// Divergences describe the differences of Result from Greenfield, there is none if they are compatible
+	Divergences []string
+}
+
+// Compatible return true if the result of this package is the result of greenfield-common
+func (r *CompatReport) Compatible() bool {
+	return len(r.Divergences) == 0
+}
+
+// CompatCheck computes the integrity hashes of the reader content with both ComputeIntegrityHashWithOptions
+// configured by opts and ComputeGreenfieldIntegrityHash, reading the content once, and reports their divergences.
+// The options changing the pieces, e.g. WithStrategy, make the results diverge.
+func CompatCheck(reader io.Reader, segmentSize int64, dataShards, parityShards int, opts ...Option) (*CompatReport,
+	error,
+) {
+	return compatCheck(reader, segmentSize, dataShards, parityShards, newHashOptions(opts))
+}
+
+func compatCheck(reader io.Reader, segmentSize int64, dataShards, parityShards int, options *hashOptions) (
+	*CompatReport, error,
+) {
+	type greenfieldResult struct {
+		result *HashResult
+		err    error
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	done := make(chan greenfieldResult, 1)
+	go func() {
+		result, err := ComputeGreenfieldIntegrityHash(pipeReader, segmentSize, dataShards, parityShards)
+		// unblock the writes of the content if the computation failed before reading it all
+		pipeReader.CloseWithError(errGreenfieldStopped)
+		done <- greenfieldResult{result: result, err: err}
+	}()
+
+	result, err := computeHashResultWithOptions(io.TeeReader(reader, pipeWriter), segmentSize, dataShards,
+		parityShards, options)
+	pipeWriter.CloseWithError(err)
+	greenfield := <-done
+	if err != nil && !errors.Is(err, errGreenfieldStopped) {
+		return nil, err
+	}
+	if greenfield.err != nil {
+		return nil, fmt.Errorf("greenfield-common: %w", greenfield.err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CompatReport{
+		Result:      result,
+		Greenfield:  greenfield.result,
+		Divergences: compatDivergences(result, greenfield.result),
+	}, nil
+}
+
+// compatDivergences return every difference of the result from the greenfield-common result
+func compatDivergences(result, greenfield *HashResult) []string {
+	var divergences []string
+	if result.Version != greenfield.Version {
+		divergences = append(divergences, fmt.Sprintf("hash version %d, greenfield-common %d", result.Version,
+			greenfield.Version))
+	}
+	if result.ContentLength != greenfield.ContentLength {
+		divergences = append(divergences, fmt.Sprintf("content length %d, greenfield-common %d",
+			result.ContentLength, greenfield.ContentLength))
+	}
+	if result.RedundancyType != greenfield.RedundancyType {
+		divergences = append(divergences, fmt.Sprintf("redundancy type %s, greenfield-common %s",
+			result.RedundancyType, greenfield.RedundancyType))
+	}
+	if len(result.Checksums) != len(greenfield.Checksums) {
+		return append(divergences, fmt.Sprintf("%d integrity hashes, greenfield-common %d", len(result.Checksums),
+			len(greenfield.Checksums)))
+	}
+	for index, checksum := range result.Checksums {
+		if bytes.Equal(checksum, greenfield.Checksums[index]) {
+			continue
+		}
+		if index == 0 {
+			divergences = append(divergences, "integrity hash of the PrimarySP")
+		} else {
+			divergences = append(divergences, fmt.Sprintf("integrity hash of the SecondarySP %d", index-1))
+		}
+	}
+	return divergences
+}
+
+// compatError return ErrGreenfieldDivergence describing the divergences of the report, nil if it is compatible
+func compatError(report *CompatReport) error {
+	if report.Compatible() {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrGreenfieldDivergence, strings.Join(report.Divergences, ", "))
+}
+
+// GreenfieldTestVectors return the test vectors of the params of the Greenfield chain computed by greenfield-common
+// and embedded in this package, with the version of greenfield-common. There is no vector until they are pinned.
+func GreenfieldTestVectors() ([]TestVector, string, error) {
+	var corpus greenfieldCorpus
+	if err := json.Unmarshal(greenfieldVectors, &corpus); err != nil {
+		return nil, "", err
+	}
+	if len(corpus.Vectors) != 0 && corpus.Version == "" {
+		return nil, "", errors.New("the greenfield vectors do not record the version of greenfield-common")
+	}
+	vectors := make([]TestVector, 0, len(corpus.Vectors))
+	for _, vector := range corpus.Vectors {
+		inputSum := sha256.Sum256(TestVectorData(vector.ObjectSize))
+		if vector.InputSHA256 != hex.EncodeToString(inputSum[:]) {
+			return nil, "", fmt.Errorf("greenfield vector %s: the input is not the input of the test vectors",
+				vector.Name)
+		}
+		// greenfield-common computes the integrity hashes of the first version
+		vector.Version = HashVersionV1
+		vectors = append(vectors, vector.TestVector)
+	}
+	return vectors, corpus.Version, nil
+}
+
+// VerifyGreenfieldTestVector checks with CompatCheck that this package computes the test vector like
+// greenfield-common, with both the serial and the parallel versions
+func VerifyGreenfieldTestVector(vector TestVector) error {
+	for _, mode := range []Mode{ModeSerial, ModeParallel} {
+		report, err := CompatCheck(bytes.NewReader(TestVectorData(vector.ObjectSize)), vector.SegmentSize,
+			vector.DataShards, vector.ParityShards, WithMode(mode))
+		if err != nil {
+			return err
+		}
+		if err = compatError(report); err != nil {
+			return fmt.Errorf("test vector %s (mode %d): %w", vector.Name, mode, err)
+		}
+		if err = diffHashResult(&vector.HashResult, report.Greenfield); err != nil {
+			return fmt.Errorf("test vector %s (greenfield-common): %w", vector.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestGreenfieldVectors(t *testing.T) {
+	vectors, version, err := GreenfieldTestVectors()
+	require.NoError(t, err)
+	if len(vectors) == 0 {
+		t.Skip("no vector of greenfield-common is pinned, run make greenfield-vectors")
+	}
+	if testing.Short() {
+		t.Skip("the greenfield vectors hash objects of several segments of 16MiB")
+	}
+
+	t.Logf("greenfield vectors of greenfield-common %s", version)
+	for _, vector := range vectors {
+		assert.Equal(t, GreenfieldDataShards+GreenfieldParityShards+1, len(vector.Checksums))
+		assert.NoError(t, VerifyGreenfieldTestVector(vector))
+		// all the integrity hashes of an empty object are the sha256 of empty content in greenfield-common
+		if vector.ObjectSize == 0 {
+			for _, checksum := range vector.Checksums {
+				assert.Equal(t, EmptyIntegrityHash(), checksum)
+			}
+		}
+	}
+}
+
+func TestGreenfieldVectorsProvenance(t *testing.T) {
+	defer func(embedded []byte) { greenfieldVectors = embedded }(greenfieldVectors)
+
+	// the vectors must record the greenfield-common version and be computed for the input of the test vectors
+	greenfieldVectors = []byte(`{"module": "m", "version": "", "vectors": [{"name": "v", "object_size": 1}]}`)
+	_, _, err := GreenfieldTestVectors()
+	assert.Error(t, err)
+	greenfieldVectors = []byte(`{"module": "m", "version": "v1.0.0", "vectors": [{"name": "v", "object_size": 1,
+		"input_sha256": "00"}]}`)
+	_, _, err = GreenfieldTestVectors()
+	assert.Error(t, err)
+
+	greenfieldVectors = []byte(`{"module": "m", "version": "v1.0.0", "vectors": [{"name": "v", "object_size": 0,
+		"input_sha256": "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}]}`)
+	vectors, version, err := GreenfieldTestVectors()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", version)
+	require.Len(t, vectors, 1)
+	assert.Equal(t, HashVersionV1, vectors[0].Version)
+}
+
+func TestCompatCheck(t *testing.T) {
+	content := make([]byte, 10*1024+77)
+	_, err := rand.Read(content)
+	require.NoError(t, err)
+
+	for _, mode := range []Mode{ModeSerial, ModeParallel} {
+		report, err := CompatCheck(iotest.HalfReader(bytes.NewReader(content)), 1024, 4, 2, WithMode(mode))
+		require.NoError(t, err)
+		assert.True(t, report.Compatible(), report.Divergences)
+		assert.Equal(t, report.Greenfield, report.Result)
+	}
+
+	// the replication pieces are not the pieces of greenfield-common
+	report, err := CompatCheck(bytes.NewReader(content), 1024, 4, 2,
+		WithStrategy(redundancy.NewReplicationStrategy(6)))
+	require.NoError(t, err)
+	assert.False(t, report.Compatible())
+	assert.Len(t, report.Divergences, 7)
+	assert.NotContains(t, report.Divergences, "integrity hash of the PrimarySP")
+
+	// verification mode
+	result, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), 1024, 4, 2, WithGreenfieldCompat())
+	require.NoError(t, err)
+	assert.Equal(t, report.Greenfield, result)
+	_, err = ComputeIntegrityHashWithOptions(bytes.NewReader(content), 1024, 4, 2, WithGreenfieldCompat(),
+		WithStrategy(redundancy.NewReplicationStrategy(6)))
+	assert.ErrorIs(t, err, ErrGreenfieldDivergence)
+
+	// the errors of the reader are returned by both computations
+	readErr := errors.New("read failed")
+	_, err = CompatCheck(iotest.ErrReader(readErr), 1024, 4, 2)
+	assert.ErrorIs(t, err, readErr)
+	_, err = CompatCheck(bytes.NewReader(content), 1024, 0, 2)
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package hash
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// ChecksumListSeparator separates the checksums of the lists formatted by ChecksumsToHexList and
+// ChecksumsToBase64List, e.g. in the x-headers of the http package
+const ChecksumListSeparator = ','
+
+const hexDigits = "0123456789abcdef"
+
+// base64Chunk is the size of the chunks of checksum encoded through a stack buffer, a multiple of 3 bytes so the
+// encoded chunks concatenate without padding, base64Chunk * 4 / 3 encoded bytes are decoded at once
+const base64Chunk = 48
+
+// ChecksumToHex return the lowercase hex encoding of the checksum, it allocates the returned string only
+func ChecksumToHex(checksum []byte) string {
+	var builder strings.Builder
+	builder.Grow(2 * len(checksum))
+	writeHex(&builder, checksum)
+	return builder.String()
+}
+
+// ChecksumToBase64 return the standard base64 encoding of the checksum, it allocates the returned string only
+func ChecksumToBase64(checksum []byte) string {
+	var builder strings.Builder
+	builder.Grow(base64.StdEncoding.EncodedLen(len(checksum)))
+	writeBase64(&builder, checksum)
+	return builder.String()
+}
+
+// ChecksumsToHexList return the hex encoded checksums separated by ChecksumListSeparator, it allocates the returned
+// string only
+func ChecksumsToHexList(checksums [][]byte) string {
+	size := max(len(checksums)-1, 0)
+	for _, checksum := range checksums {
+		size += 2 * len(checksum)
+	}
+	var builder strings.Builder
+	builder.Grow(size)
+	for i, checksum := range checksums {
+		if i > 0 {
+			builder.WriteByte(ChecksumListSeparator)
+		}
+		writeHex(&builder, checksum)
+	}
+	return builder.String()
+}
+
+// ChecksumsToBase64List return the base64 encoded checksums separated by ChecksumListSeparator, it allocates the
+// returned string only
+func ChecksumsToBase64List(checksums [][]byte) string {
+	size := max(len(checksums)-1, 0)
+	for _, checksum := range checksums {
+		size += base64.StdEncoding.EncodedLen(len(checksum))
+	}
+	var builder strings.Builder
+	builder.Grow(size)
+	for i, checksum := range checksums {
+		if i > 0 {
+			builder.WriteByte(ChecksumListSeparator)
+		}
+		writeBase64(&builder, checksum)
+	}
+	return builder.String()
+}
+
+// AppendChecksumHex appends the hex encoding of the checksum to dst, it does not allocate if dst has the capacity
+func AppendChecksumHex(dst, checksum []byte) []byte {
+	for _, b := range checksum {
+		dst = append(dst, hexDigits[b>>4], hexDigits[b&0x0f])
+	}
+	return dst
+}
+
+// AppendChecksumBase64 appends the base64 encoding of the checksum to dst, it does not allocate if dst has the
+// capacity
+func AppendChecksumBase64(dst, checksum []byte) []byte {
+	size := base64.StdEncoding.EncodedLen(len(checksum))
+	if cap(dst)-len(dst) < size {
+		dst = append(make([]byte, 0, len(dst)+size), dst...)
+	}
+	base64.StdEncoding.Encode(dst[len(dst):len(dst)+size], checksum)
+	return dst[:len(dst)+size]
+}
+
+// ParseChecksumHex decodes a hex encoded checksum of either case, it allocates the returned checksum only
+func ParseChecksumHex(s string) ([]byte, error) {
+	if len(s)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd hex length %d", ErrInvalidChecksumEncoding, len(s))
+	}
+	checksum := make([]byte, len(s)/2)
+	if err := decodeHex(checksum, s); err != nil {
+		return nil, err
+	}
+	return checksum, nil
+}
+
+// ParseChecksumBase64 decodes a standard base64 encoded checksum, it allocates the returned checksum only
+func ParseChecksumBase64(s string) ([]byte, error) {
+	checksum := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+	n, err := decodeBase64(checksum, s)
+	if err != nil {
+		return nil, err
+	}
+	return checksum[:n], nil
+}
+
+// ParseChecksumsHexList decodes the hex encoded checksums separated by ChecksumListSeparator, the checksums share a
+// single allocation. An empty string is an empty list.
+func ParseChecksumsHexList(s string) ([][]byte, error) {
+	if len(s) == 0 {
+		return [][]byte{}, nil
+	}
+	count := strings.Count(s, string(ChecksumListSeparator)) + 1
+	checksums := make([][]byte, 0, count)
+	buffer := make([]byte, (len(s)-count+1)/2)
+	for i := 0; i < count; i++ {
+		item, rest, _ := strings.Cut(s, string(ChecksumListSeparator))
+		s = rest
+		if len(item)%2 != 0 {
+			return nil, fmt.Errorf("%w: checksum %d has an odd hex length %d", ErrInvalidChecksumEncoding, i,
+				len(item))
+		}
+		checksum := buffer[: len(item)/2 : len(item)/2]
+		buffer = buffer[len(item)/2:]
+		if err := decodeHex(checksum, item); err != nil {
+			return nil, fmt.Errorf("checksum %d: %w", i, err)
+		}
+		checksums = append(checksums, checksum)
+	}
+	return checksums, nil
+}
+
+// ParseChecksumsBase64List decodes the base64 encoded checksums separated by ChecksumListSeparator, the checksums
+// share a single allocation. An empty string is an empty list.
+func ParseChecksumsBase64List(s string) ([][]byte, error) {
+	if len(s) == 0 {
+		return [][]byte{}, nil
+	}
+	count := strings.Count(s, string(ChecksumListSeparator)) + 1
+	checksums := make([][]byte, 0, count)
+	buffer := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+	for i := 0; i < count; i++ {
+		item, rest, _ := strings.Cut(s, string(ChecksumListSeparator))
+		s = rest
+		n, err := decodeBase64(buffer, item)
+		if err != nil {
+			return nil, fmt.Errorf("checksum %d: %w", i, err)
+		}
+		checksums = append(checksums, buffer[:n:n])
+		buffer = buffer[n:]
+	}
+	return checksums, nil
+}
+
+// writeHex writes the hex encoding of the checksum to the builder
+func writeHex(builder *strings.Builder, checksum []byte) {
+	for _, b := range checksum {
+		builder.WriteByte(hexDigits[b>>4])
+		builder.WriteByte(hexDigits[b&0x0f])
+	}
+}
+
+// writeBase64 writes the base64 encoding of the checksum to the builder through a stack buffer
+func writeBase64(builder *strings.Builder, checksum []byte) {
+	var encoded [base64Chunk / 3 * 4]byte
+	for len(checksum) > 0 {
+		chunk := checksum[:min(len(checksum), base64Chunk)]
+		checksum = checksum[len(chunk):]
+		n := base64.StdEncoding.EncodedLen(len(chunk))
+		base64.StdEncoding.Encode(encoded[:n], chunk)
+		builder.Write(encoded[:n])
+	}
+}
+
+// decodeHex decodes the hex string into dst of half its length
+func decodeHex(dst []byte, s string) error {
+	for i := range dst {
+		high, ok1 := fromHexDigit(s[2*i])
+		low, ok2 := fromHexDigit(s[2*i+1])
+		if !ok1 || !ok2 {
+			return fmt.Errorf("%w: invalid hex digit at offset %d", ErrInvalidChecksumEncoding, 2*i)
+		}
+		dst[i] = high<<4 | low
+	}
+	return nil
+}
+
+func fromHexDigit(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// decodeBase64 decodes the base64 string into dst through a stack buffer and return the number of decoded bytes,
+// the padding may only end the string
+func decodeBase64(dst []byte, s string) (int, error) {
+	if len(s)%4 != 0 {
+		return 0, fmt.Errorf("%w: base64 length %d is not a multiple of 4", ErrInvalidChecksumEncoding, len(s))
+	}
+	var encoded [base64Chunk / 3 * 4]byte
+	decoded := 0
+	for len(s) > 0 {
+		n := copy(encoded[:], s)
+		s = s[n:]
+		if len(s) > 0 && encoded[n-1] == '=' {
+			return 0, fmt.Errorf("%w: base64 padding before the end", ErrInvalidChecksumEncoding)
+		}
+		m, err := base64.StdEncoding.Decode(dst[decoded:], encoded[:n])
+		if err != nil {
+			return 0, fmt.Errorf("%w: %w", ErrInvalidChecksumEncoding, err)
+		}
+		decoded += m
+	}
+	return decoded, nil
+}
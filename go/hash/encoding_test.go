@@ -0,0 +1,115 @@
+package hash
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksumEncoding(t *testing.T) {
+	for _, size := range []int{0, 1, 2, 3, 32, 47, 48, 49, 100} {
+		checksum := make([]byte, size)
+		for i := range checksum {
+			checksum[i] = byte(i * 37)
+		}
+		assert.Equal(t, hex.EncodeToString(checksum), ChecksumToHex(checksum), "size %d", size)
+		assert.Equal(t, base64.StdEncoding.EncodeToString(checksum), ChecksumToBase64(checksum), "size %d", size)
+		assert.Equal(t, "x"+hex.EncodeToString(checksum), string(AppendChecksumHex([]byte("x"), checksum)))
+		assert.Equal(t, "x"+base64.StdEncoding.EncodeToString(checksum),
+			string(AppendChecksumBase64([]byte("x"), checksum)))
+
+		decoded, err := ParseChecksumHex(ChecksumToHex(checksum))
+		require.NoError(t, err)
+		assert.Equal(t, checksum, decoded)
+		decoded, err = ParseChecksumBase64(ChecksumToBase64(checksum))
+		require.NoError(t, err)
+		assert.Equal(t, checksum, decoded)
+	}
+
+	decoded, err := ParseChecksumHex("0aFf")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x0a, 0xff}, decoded)
+	for _, invalid := range []string{"0", "0g", "zz"} {
+		_, err = ParseChecksumHex(invalid)
+		assert.ErrorIs(t, err, ErrInvalidChecksumEncoding, invalid)
+	}
+	// the padding ends a decoded chunk of the second invalid checksum
+	chunk := base64.StdEncoding.EncodeToString(make([]byte, 45))
+	for _, invalid := range []string{"AA", "AA=A", chunk + "AA==AAAA", "AA==AAAA", "!!!!"} {
+		_, err = ParseChecksumBase64(invalid)
+		assert.ErrorIs(t, err, ErrInvalidChecksumEncoding, invalid)
+	}
+}
+
+func TestChecksumListEncoding(t *testing.T) {
+	checksums := testChecksums()
+	hexList, base64List := make([]string, len(checksums)), make([]string, len(checksums))
+	for i, checksum := range checksums {
+		hexList[i] = hex.EncodeToString(checksum)
+		base64List[i] = base64.StdEncoding.EncodeToString(checksum)
+	}
+
+	encoded := ChecksumsToHexList(checksums)
+	assert.Equal(t, strings.Join(hexList, ","), encoded)
+	decoded, err := ParseChecksumsHexList(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, checksums, decoded)
+
+	encoded = ChecksumsToBase64List(checksums)
+	assert.Equal(t, strings.Join(base64List, ","), encoded)
+	decoded, err = ParseChecksumsBase64List(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, checksums, decoded)
+
+	// the checksums share the allocation without overlapping
+	decoded[0] = append(decoded[0], 1)
+	assert.Equal(t, checksums[1], decoded[1])
+
+	assert.Equal(t, "", ChecksumsToHexList(nil))
+	assert.Equal(t, "", ChecksumsToBase64List(nil))
+	decoded, err = ParseChecksumsHexList("")
+	require.NoError(t, err)
+	assert.Empty(t, decoded)
+	decoded, err = ParseChecksumsBase64List("")
+	require.NoError(t, err)
+	assert.Empty(t, decoded)
+
+	_, err = ParseChecksumsHexList(hexList[0] + ",0")
+	assert.ErrorIs(t, err, ErrInvalidChecksumEncoding)
+	_, err = ParseChecksumsBase64List(base64List[0] + ",A")
+	assert.ErrorIs(t, err, ErrInvalidChecksumEncoding)
+}
+
+func TestChecksumEncodingAllocs(t *testing.T) {
+	checksums := testChecksums()
+	hexList, base64List := ChecksumsToHexList(checksums), ChecksumsToBase64List(checksums)
+	buffer := make([]byte, 0, 128)
+	for name, tc := range map[string]struct {
+		allocs float64
+		f      func()
+	}{
+		"ChecksumToHex":            {1, func() { ChecksumToHex(checksums[0]) }},
+		"ChecksumToBase64":         {1, func() { ChecksumToBase64(checksums[0]) }},
+		"ChecksumsToHexList":       {1, func() { ChecksumsToHexList(checksums) }},
+		"ChecksumsToBase64List":    {1, func() { ChecksumsToBase64List(checksums) }},
+		"AppendChecksumHex":        {0, func() { buffer = AppendChecksumHex(buffer[:0], checksums[0]) }},
+		"AppendChecksumBase64":     {0, func() { buffer = AppendChecksumBase64(buffer[:0], checksums[0]) }},
+		"ParseChecksumsHexList":    {2, func() { _, _ = ParseChecksumsHexList(hexList) }},
+		"ParseChecksumsBase64List": {2, func() { _, _ = ParseChecksumsBase64List(base64List) }},
+	} {
+		assert.Equal(t, tc.allocs, testing.AllocsPerRun(100, tc.f), name)
+	}
+}
+
+// testChecksums return the sha256 checksums of the integrity hashes of an object with 6 pieces
+func testChecksums() [][]byte {
+	checksums := make([][]byte, 7)
+	for i := range checksums {
+		checksums[i] = GenerateChecksum([]byte{byte(i)})
+	}
+	return checksums
+}
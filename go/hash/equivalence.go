@@ -0,0 +1,94 @@
+package hash
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// EquivalenceReader wraps the content read by the serial and the parallel versions in EquivalenceCheck, e.g. into a
+// reader of short reads
+type EquivalenceReader struct {
+	Name string
+	Wrap func(io.Reader) io.Reader
+}
+
+// EquivalenceCheck reads the whole reader and computes its integrity hashes with every implementation of this
+// package: the serial and the parallel version functions, both fed by the content and by each of the readers
+// wrapping it, and the IntegrityHasher fed by chunks which do not align with the segment size.
+// It returns an error describing the first implementation whose result differs from the serial version.
+// The func is intended for tests and fuzzing, it keeps the whole content in memory.
+func EquivalenceCheck(reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	readers ...EquivalenceReader,
+) error {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	expected, err := computeHashResult(bytes.NewReader(content), segmentSize, dataShards, parityShards, true)
+	if err != nil {
+		return fmt.Errorf("serial: %w", err)
+	}
+
+	readers = append([]EquivalenceReader{{Name: "content", Wrap: func(r io.Reader) io.Reader { return r }}},
+		readers...)
+	for _, r := range readers {
+		for _, isSerial := range []bool{true, false} {
+			name := "parallel " + r.Name
+			if isSerial {
+				name = "serial " + r.Name
+			}
+			computed, err := computeHashResult(r.Wrap(bytes.NewReader(content)), segmentSize, dataShards,
+				parityShards, isSerial)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if err = diffHashResult(expected, computed); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+
+	computed, err := computeHasherResult(content, segmentSize, dataShards, parityShards)
+	if err != nil {
+		return fmt.Errorf("integrity hasher: %w", err)
+	}
+	if err = diffHashResult(expected, computed); err != nil {
+		return fmt.Errorf("integrity hasher: %w", err)
+	}
+	return nil
+}
+
+// computeHashResult wraps the result of ComputeIntegrityHash into a HashResult
+func computeHashResult(reader io.Reader, segmentSize int64, dataShards, parityShards int, isSerial bool) (*HashResult,
+	error,
+) {
+	checksums, contentLen, redundancyType, err := ComputeIntegrityHash(reader, segmentSize, dataShards, parityShards,
+		isSerial)
+	if err != nil {
+		return nil, err
+	}
+	return NewHashResult(checksums, contentLen, redundancyType), nil
+}
+
+// computeHasherResult appends the content to an IntegrityHasher in chunks of a little more than half a segment
+func computeHasherResult(content []byte, segmentSize int64, dataShards, parityShards int) (*HashResult, error) {
+	hasher := NewHasher(segmentSize, dataShards, parityShards)
+	hasher.Init()
+	chunkSize := int(segmentSize/2 + 1)
+	for start := 0; start < len(content); start += chunkSize {
+		end := start + chunkSize
+		if end > len(content) {
+			end = len(content)
+		}
+		if err := hasher.Append(content[start:end]); err != nil {
+			return nil, err
+		}
+	}
+	checksums, contentLen, redundancyType, err := hasher.Finish()
+	if err != nil {
+		return nil, err
+	}
+	return NewHashResult(checksums, contentLen, redundancyType), nil
+}
@@ -0,0 +1,42 @@
+package hash
+
+import (
+	"bytes"
+	"testing"
+	"testing/iotest"
+)
+
+// shortReaders feed the implementations by short reads and by data returned with io.EOF
+var shortReaders = []EquivalenceReader{
+	{Name: "one byte reads", Wrap: iotest.OneByteReader},
+	{Name: "half reads", Wrap: iotest.HalfReader},
+	{Name: "data with EOF", Wrap: iotest.DataErrReader},
+}
+
+func TestEquivalenceCheck(t *testing.T) {
+	const segSize = 1024
+	for _, size := range []int{0, 1, segSize - 1, segSize, segSize + 1, 3 * segSize, 3*segSize + 7} {
+		if err := EquivalenceCheck(bytes.NewReader(TestVectorData(int64(size))), segSize, 4, 2, shortReaders...); err != nil {
+			t.Errorf("size %d: %s", size, err)
+		}
+	}
+}
+
+// FuzzEquivalence asserts the implementations of this package always produce identical integrity hashes,
+// run it with `go test ./hash -run XXX -fuzz FuzzEquivalence` from the go module root
+func FuzzEquivalence(f *testing.F) {
+	f.Add([]byte{}, uint16(16), uint8(4), uint8(2))
+	f.Add([]byte{1}, uint16(16), uint8(4), uint8(2))
+	f.Add(TestVectorData(64), uint16(16), uint8(4), uint8(2))
+	f.Add(TestVectorData(65), uint16(16), uint8(6), uint8(3))
+	f.Add(TestVectorData(1000), uint16(333), uint8(1), uint8(1))
+
+	f.Fuzz(func(t *testing.T, content []byte, segmentSize uint16, dataShards, parityShards uint8) {
+		segSize := int64(segmentSize%4096) + 1
+		data := int(dataShards%16) + 1
+		parity := int(parityShards%8) + 1
+		if err := EquivalenceCheck(bytes.NewReader(content), segSize, data, parity, shortReaders...); err != nil {
+			t.Fatalf("segment size %d, ec %d+%d, content length %d: %s", segSize, data, parity, len(content), err)
+		}
+	})
+}
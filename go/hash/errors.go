@@ -0,0 +1,88 @@
+package hash
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrEmptyObject is returned when the object is empty and a minimum object size is required
+	ErrEmptyObject = errors.New("the object is empty")
+	// ErrObjectTooSmall is returned when the object is smaller than the required minimum object size
+	ErrObjectTooSmall = errors.New("the object size is less than the minimum object size")
+	// ErrSegmentTooLarge is returned when the data appended to IntegrityHasher exceeds the segment size
+	ErrSegmentTooLarge = errors.New("the data size should be less than segment size")
+	// ErrAlreadyFinished is returned when data is appended to an IntegrityHasher after Finish without Reset
+	ErrAlreadyFinished = errors.New("the integrity hasher is already finished")
+	// ErrReaderFailed is returned when the content can not be read, it wraps the error of the reader
+	ErrReaderFailed = errors.New("failed to read content")
+	// ErrStoreFailed is returned in a SegmentError when the SegmentSink of ComputeIntegrityHashTee fails to store a
+	// segment
+	ErrStoreFailed = errors.New("failed to store segment")
+	// ErrEncodeFailed is returned in a SegmentError when a segment can not be erasure encoded
+	ErrEncodeFailed = errors.New("failed to erasure encode segment")
+	// ErrSegmentHashMissing is returned in a SegmentError when the hash of a segment was not computed by the workers
+	ErrSegmentHashMissing = errors.New("failed to load the segment hash")
+	// ErrInvalidChecksumList is returned when the challenged index is out of the checksum list
+	ErrInvalidChecksumList = errors.New("invalid checksum list")
+	// ErrPieceChecksumMismatch is returned when the piece data does not match the piece checksum
+	ErrPieceChecksumMismatch = errors.New("piece data and piece hash are inconsistent")
+	// ErrMemoryBudgetExceeded is returned when the memory of a segment can not be reserved from the MemoryLimiter
+	ErrMemoryBudgetExceeded = errors.New("the memory budget of the hash computation is exceeded")
+	// ErrUnsupportedHashVersion is returned when a HashResult was computed by a newer version of the algorithm
+	ErrUnsupportedHashVersion = errors.New("unsupported hash version")
+	// ErrIntegrityHashMismatch is returned when the checksum list does not match the integrity hash
+	ErrIntegrityHashMismatch = errors.New("invalid integrity hash")
+	// ErrInvalidCheckpoint is returned when a HasherCheckpoint is inconsistent
+	ErrInvalidCheckpoint = errors.New("invalid hasher checkpoint")
+	// ErrGreenfieldDivergence is returned by the computations with WithGreenfieldCompat when the integrity hashes
+	// differ from the ones of greenfield-common
+	ErrGreenfieldDivergence = errors.New("the integrity hashes diverge from greenfield-common")
+	// ErrFastChecksumMismatch is returned when the data does not match its fast checksum
+	ErrFastChecksumMismatch = errors.New("data and fast checksum are inconsistent")
+	// ErrInvalidChecksumEncoding is returned when a hex or base64 encoded checksum can not be decoded
+	ErrInvalidChecksumEncoding = errors.New("invalid checksum encoding")
+)
+
+// SegmentError describes the failure of one segment, errors.Is matches both its Kind and the cause Err
+type SegmentError struct {
+	Segment int
+	// Kind is ErrReaderFailed, ErrStoreFailed, ErrEncodeFailed or ErrSegmentHashMissing
+	Kind error
+	Err  error
+}
+
+func (e *SegmentError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("segment %d: %s", e.Segment, e.Kind)
+	}
+	return fmt.Sprintf("segment %d: %s: %s", e.Segment, e.Kind, e.Err)
+}
+
+func (e *SegmentError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// readerError wraps the error of the reader into ErrReaderFailed
+func readerError(err error) error {
+	return fmt.Errorf("%w: %w", ErrReaderFailed, err)
+}
+
+// StatusError is returned when a remote object source answers a request with an unexpected status code
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: unexpected status code %d", e.Method, e.URL, e.StatusCode)
+}
+
+// Temporary return true if the request may succeed when retried
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == 408 || e.StatusCode == 429
+}
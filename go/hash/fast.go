@@ -0,0 +1,88 @@
+package hash
+
+import (
+	"fmt"
+	"hash/crc32"
+	"sync"
+)
+
+// castagnoli is the table of the CRC32C fast checksums, crc32 computes them with the SSE4.2 instructions on amd64 and
+// the CRC instructions on arm64
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// FastChecksum return the CRC32C of the data. It detects the accidental corruption of a transfer at a fraction of the
+// cost of GenerateChecksum but it does not protect against a malicious change, the data must be verified against
+// its sha256 checksum once the fast checksum matches.
+func FastChecksum(data []byte) uint32 {
+	return crc32.Checksum(data, castagnoli)
+}
+
+// VerifyFastChecksum return ErrFastChecksumMismatch if the CRC32C of the data is not checksum
+func VerifyFastChecksum(data []byte, checksum uint32) error {
+	if computed := FastChecksum(data); computed != checksum {
+		return fmt.Errorf("%w: expect %08x, got %08x", ErrFastChecksumMismatch, checksum, computed)
+	}
+	return nil
+}
+
+// FastChecksums are the CRC32C of the segments and of the pieces of an object computed alongside its integrity
+// hashes with WithFastChecksums, see FastChecksum
+type FastChecksums struct {
+	// Segments are the fast checksums of the segments stored by the PrimarySP by segment index
+	Segments []uint32 `json:"segments"`
+	// Pieces are the fast checksums of the pieces stored by the SecondarySPs by ec index then by segment index
+	Pieces [][]uint32 `json:"pieces"`
+}
+
+// fastChecksumCollector gathers the fast checksums of the segments hashed in any order by the workers of a
+// computation
+type fastChecksumCollector struct {
+	mu       sync.Mutex
+	segments []uint32
+	pieces   [][]uint32
+}
+
+// record computes the fast checksums of the segment and of its pieces
+func (c *fastChecksumCollector) record(segIndex int, segment []byte, pieces [][]byte) {
+	segmentChecksum := FastChecksum(segment)
+	pieceChecksums := make([]uint32, len(pieces))
+	for index, piece := range pieces {
+		pieceChecksums[index] = FastChecksum(piece)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.segments) <= segIndex {
+		c.segments = append(c.segments, make([]uint32, segIndex+1-len(c.segments))...)
+	}
+	c.segments[segIndex] = segmentChecksum
+	for len(c.pieces) < len(pieces) {
+		c.pieces = append(c.pieces, nil)
+	}
+	for index, checksum := range pieceChecksums {
+		if len(c.pieces[index]) <= segIndex {
+			c.pieces[index] = append(c.pieces[index], make([]uint32, segIndex+1-len(c.pieces[index]))...)
+		}
+		c.pieces[index][segIndex] = checksum
+	}
+}
+
+// result return the fast checksums of an object of pieceCount pieces per segment, nil if the collector is nil
+func (c *fastChecksumCollector) result(pieceCount int) *FastChecksums {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	checksums := &FastChecksums{
+		Segments: append([]uint32{}, c.segments...),
+		Pieces:   make([][]uint32, pieceCount),
+	}
+	for index := range checksums.Pieces {
+		checksums.Pieces[index] = []uint32{}
+		if index < len(c.pieces) {
+			checksums.Pieces[index] = append(checksums.Pieces[index], c.pieces[index]...)
+		}
+	}
+	return checksums
+}
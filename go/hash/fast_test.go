@@ -0,0 +1,60 @@
+package hash
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestFastChecksum(t *testing.T) {
+	// the check value of CRC32C
+	assert.Equal(t, uint32(0xe3069283), FastChecksum([]byte("123456789")))
+	assert.NoError(t, VerifyFastChecksum([]byte("123456789"), 0xe3069283))
+	assert.ErrorIs(t, VerifyFastChecksum([]byte("123456780"), 0xe3069283), ErrFastChecksumMismatch)
+}
+
+func TestWithFastChecksums(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(3*segSize + 100)
+	expected := &FastChecksums{Pieces: make([][]uint32, redundancy.DataBlocks+redundancy.ParityBlocks)}
+	for offset := 0; offset < len(content); offset += segSize {
+		segment := content[offset:min(offset+segSize, len(content))]
+		expected.Segments = append(expected.Segments, FastChecksum(segment))
+		shards, err := redundancy.EncodeRawSegment(bytes.Clone(segment), redundancy.DataBlocks,
+			redundancy.ParityBlocks)
+		require.NoError(t, err)
+		for index, shard := range shards {
+			expected.Pieces[index] = append(expected.Pieces[index], FastChecksum(shard))
+		}
+	}
+
+	for _, mode := range []Mode{ModeSerial, ModeParallel} {
+		result, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithMode(mode), WithFastChecksums())
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.FastChecksums)
+
+		result, layout, err := ComputeIntegrityHashTee(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, TeeToFiles(t.TempDir()), WithMode(mode), WithFastChecksums())
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.FastChecksums)
+		assert.NoError(t, layout.Remove())
+	}
+
+	// the fast checksums are not computed by default
+	result, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	require.NoError(t, err)
+	assert.Nil(t, result.FastChecksums)
+
+	// an empty object has empty lists
+	result, err = ComputeIntegrityHashWithOptions(bytes.NewReader(nil), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, WithFastChecksums())
+	require.NoError(t, err)
+	assert.Empty(t, result.FastChecksums.Segments)
+	assert.Len(t, result.FastChecksums.Pieces, redundancy.DataBlocks+redundancy.ParityBlocks)
+}
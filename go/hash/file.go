@@ -0,0 +1,77 @@
+//go:build !js
+
+package hash
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// The helpers reading and writing local files are not built for the browsers, see the wasm package.
+
+// ComputerHashFromFile open a local file and compute hash result and segmentSize.
+// The parallel version is used unless other options are provided, e.g. WithMode(ModeSerial) and WithPrefetch
+// overlap the disk reads with the hashing in a single worker.
+func ComputerHashFromFile(filePath string, segmentSize int64, dataShards, parityShards int, opts ...Option) ([][]byte,
+	int64, redundancy.RedundancyType, error,
+) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		logger.Errorf("failed to open file: %s", err)
+		return nil, 0, redundancy.RedundancyECType, err
+	}
+	defer f.Close()
+
+	options := newHashOptions(append([]Option{WithMode(ModeParallel)}, opts...))
+	return computeIntegrityHash(f, segmentSize, dataShards, parityShards, options)
+}
+
+type fileSink struct {
+	dir string
+}
+
+// TeeToFiles return a sink writing every segment to its own temporary file in dir, os.TempDir is used if dir is
+// empty. The files are removed by StoredLayout.Remove.
+func TeeToFiles(dir string) SegmentSink {
+	return &fileSink{dir: dir}
+}
+
+func (s *fileSink) WriteSegment(index int, offset int64, data []byte) (StoredSegment, error) {
+	f, err := os.CreateTemp(s.dir, "segment-*")
+	if err != nil {
+		return StoredSegment{}, err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return StoredSegment{}, err
+	}
+	return StoredSegment{Index: index, Offset: offset, Size: int64(len(data)), Path: f.Name()}, nil
+}
+
+// SaveTuneResult writes the result as JSON to the file
+func SaveTuneResult(result *TuneResult, filePath string) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0o600)
+}
+
+// LoadTuneResult reads the result written by SaveTuneResult
+func LoadTuneResult(filePath string) (*TuneResult, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	result := &TuneResult{}
+	if err = json.Unmarshal(data, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -2,17 +2,18 @@ package hash
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"os"
-	"runtime"
+	"io/fs"
 	"sync"
+	"time"
 
-	"github.com/rs/zerolog/log"
-
-	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
 	"github.com/zkMeLabs/mechain-common/go/redundancy"
+	"github.com/zkMeLabs/mechain-common/go/segment"
+	"github.com/zkMeLabs/mechain-common/go/taskqueue"
+	"github.com/zkMeLabs/mechain-common/go/tracing"
 )
 
 const (
@@ -25,10 +26,38 @@ type IntegrityHasher struct {
 	ecDataHashes [][][]byte
 	segHashes    [][]byte
 	buffer       []byte
+	// borrowed is set when the buffer is the data of AppendOwned, it is not reused by Reset
+	borrowed     bool
 	segmentSize  int64
 	dataShards   int
 	parityShards int
 	contentLen   int64
+	// finished is set by Finish, which caches its result in hashList
+	finished bool
+	hashList [][]byte
+}
+
+var hasherPool = sync.Pool{
+	New: func() interface{} {
+		return &IntegrityHasher{}
+	},
+}
+
+// AcquireHasher return a ready to use hasher from a pool, which keeps the buffers of the released hashers.
+// The hasher should be returned by ReleaseHasher once its result is read.
+func AcquireHasher(size int64, data, parity int) *IntegrityHasher {
+	hasher := hasherPool.Get().(*IntegrityHasher)
+	hasher.segmentSize = size
+	hasher.dataShards = data
+	hasher.parityShards = parity
+	hasher.Reset()
+	return hasher
+}
+
+// ReleaseHasher returns the hasher to the pool of AcquireHasher, it must not be used after the call
+func ReleaseHasher(hasher *IntegrityHasher) {
+	hasher.Reset()
+	hasherPool.Put(hasher)
 }
 
 func NewHasher(size int64, data, parity int) *IntegrityHasher {
@@ -42,31 +71,41 @@ func NewHasher(size int64, data, parity int) *IntegrityHasher {
 
 // Init the integrityHash fields
 func (i *IntegrityHasher) Init() {
+	i.Reset()
+}
+
+// Reset restores the hasher to its initial state to hash another object, the allocated buffers are reused
+func (i *IntegrityHasher) Reset() {
 	ecShards := i.dataShards + i.parityShards
-	encodeDataHash := make([][][]byte, ecShards)
-	for i := 0; i < ecShards; i++ {
-		encodeDataHash[i] = make([][]byte, 0)
+	if len(i.ecDataHashes) != ecShards {
+		i.ecDataHashes = make([][][]byte, ecShards)
 	}
-	segChecksumList := make([][]byte, 0)
-	i.ecDataHashes = encodeDataHash
-	i.segHashes = segChecksumList
-	if len(i.buffer) > 0 {
+	for index := range i.ecDataHashes {
+		i.ecDataHashes[index] = i.ecDataHashes[index][:0]
+	}
+	i.segHashes = i.segHashes[:0]
+	if i.borrowed {
+		i.buffer, i.borrowed = nil, false
+	} else {
 		i.buffer = i.buffer[:0]
 	}
 	i.contentLen = 0
+	i.finished = false
+	i.hashList = nil
 }
 
 // Append the data chunks to IntegrityHasher , the data size should be less than segment size
 func (i *IntegrityHasher) Append(data []byte) error {
+	if i.finished {
+		return ErrAlreadyFinished
+	}
 	dataSize := len(data)
 	if dataSize > int(i.segmentSize) {
-		return errors.New("the length of data size should be less than segmentSize")
+		return fmt.Errorf("%w: data size %d, segment size %d", ErrSegmentTooLarge, dataSize, i.segmentSize)
 	}
 	if len(i.buffer) >= int(i.segmentSize) {
-		return errors.New("the buffer of handler should be less than segmentSize")
+		return fmt.Errorf("%w: buffer size %d, segment size %d", ErrSegmentTooLarge, len(i.buffer), i.segmentSize)
 	}
-	originBuffer := make([]byte, len(i.buffer))
-	copy(originBuffer, i.buffer)
 	// use tempBuffer to store exceed data
 	var tempBuffer []byte
 	totalSize := int64(dataSize + len(i.buffer))
@@ -100,13 +139,37 @@ func (i *IntegrityHasher) Append(data []byte) error {
 	return nil
 }
 
-// Finish return the result of the Integrity hashes
-func (i *IntegrityHasher) Finish() ([][]byte, int64, storagetypes.RedundancyType, error) {
+// AppendOwned appends the data like Append but takes the ownership of it to avoid copying it: the caller must not
+// read, modify or reuse data, including its spare capacity, after the call. A segment sized data appended at a
+// segment boundary is hashed in place and shorter data become the buffer of the hasher.
+func (i *IntegrityHasher) AppendOwned(data []byte) error {
+	if i.finished {
+		return ErrAlreadyFinished
+	}
+	if len(i.buffer) > 0 || len(data) == 0 {
+		return i.Append(data)
+	}
+	if len(data) > int(i.segmentSize) {
+		return fmt.Errorf("%w: data size %d, segment size %d", ErrSegmentTooLarge, len(data), i.segmentSize)
+	}
+	if int64(len(data)) < i.segmentSize {
+		i.buffer, i.borrowed = data, true
+		return nil
+	}
+	return i.computeSegmentHash(data)
+}
+
+// Finish compute the result of the integrity hashes, calling it again return the same result until Reset is called
+func (i *IntegrityHasher) Finish() ([][]byte, int64, redundancy.RedundancyType, error) {
+	if i.finished {
+		return i.hashList, i.contentLen, redundancy.RedundancyECType, nil
+	}
 	// deal with  remain content tot be computed
 	if len(i.buffer) > 0 {
 		if err := i.computeBufferHash(); err != nil {
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+			return nil, 0, redundancy.RedundancyECType, err
 		}
+		i.buffer = i.buffer[:0]
 	}
 
 	hashList := make([][]byte, i.parityShards+i.dataShards+1)
@@ -124,25 +187,27 @@ func (i *IntegrityHasher) Finish() ([][]byte, int64, storagetypes.RedundancyType
 	}
 	wg.Wait()
 
-	return hashList, i.contentLen, storagetypes.REDUNDANCY_EC_TYPE, nil
+	i.finished = true
+	i.hashList = hashList
+	return hashList, i.contentLen, redundancy.RedundancyECType, nil
 }
 
 // computeBufferHash erasure encode the buffer of IntegrityHasher and compute the hash
 func (i *IntegrityHasher) computeBufferHash() error {
-	i.contentLen += int64(len(i.buffer))
-	originBuffer := make([]byte, len(i.buffer))
-	copy(originBuffer, i.buffer)
-	// compute segment hash
-	checksum := GenerateChecksum(i.buffer)
-	i.segHashes = append(i.segHashes, checksum)
-	// get erasure encoded bytes and compute pieces hashes
-	encodeShards, err := redundancy.EncodeRawSegment(i.buffer, i.dataShards, i.parityShards)
+	return i.computeSegmentHash(i.buffer)
+}
+
+// computeSegmentHash appends the hashes of one segment, the hasher is unchanged if the segment can not be encoded
+func (i *IntegrityHasher) computeSegmentHash(segment []byte) error {
+	// get erasure encoded bytes and compute pieces hashes, the encoding never modifies the segment content
+	encodeShards, err := redundancy.EncodeRawSegment(segment, i.dataShards, i.parityShards)
 	if err != nil {
-		// recover buffer content if encode error
-		i.buffer = i.buffer[:0]
-		i.buffer = append(i.buffer, originBuffer...)
-		return err
+		return &SegmentError{Segment: len(i.segHashes), Kind: ErrEncodeFailed, Err: err}
 	}
+	i.contentLen += int64(len(segment))
+	// compute segment hash
+	checksum := GenerateChecksum(segment)
+	i.segHashes = append(i.segHashes, checksum)
 
 	for index, shard := range encodeShards {
 		// compute hash of pieces
@@ -156,22 +221,158 @@ func (i *IntegrityHasher) computeBufferHash() error {
 // ComputeIntegrityHash  return the integrity hash of file and data size
 // If isSerial is true, compute the integrity hash using the serial version
 // If isSerial is false or not provided, compute the integrity hash using the parallel version
+//
+// Deprecated: use ComputeIntegrityHashWithOptions, which selects the version by the object size unless WithMode
+// is provided.
 func ComputeIntegrityHash(reader io.Reader, segmentSize int64, dataShards, parityShards int, isSerial bool) ([][]byte,
-	int64, storagetypes.RedundancyType, error,
+	int64, redundancy.RedundancyType, error,
+) {
+	options := newHashOptions([]Option{WithSerial(isSerial)})
+	return computeIntegrityHash(reader, segmentSize, dataShards, parityShards, options)
+}
+
+func computeIntegrityHash(reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	options *hashOptions,
+) (checksums [][]byte, contentLen int64, redundancyType redundancy.RedundancyType, err error) {
+	var span tracing.Span
+	options.traceCtx, span = tracing.Start(options.traceCtx, "hash.ComputeIntegrityHash",
+		tracing.Int64("segment_size", segmentSize), tracing.Int("data_shards", dataShards),
+		tracing.Int("parity_shards", parityShards))
+	defer func() {
+		span.SetAttributes(tracing.Int64("content_length", contentLen))
+		tracing.End(span, err)
+	}()
+
+	mode := options.mode
+	if mode == ModeAuto {
+		reader, mode, err = selectMode(reader, segmentSize, options.autoThreshold)
+		if err != nil {
+			options.logger.Errorf("failed to read content: %s", err)
+			return nil, 0, redundancy.RedundancyECType, readerError(err)
+		}
+	}
+	if mode == ModeSerial {
+		span.SetAttributes(tracing.String("mode", "serial"))
+		return computeIntegrityHashSerial(reader, segmentSize, dataShards, parityShards, options)
+	}
+	span.SetAttributes(tracing.String("mode", "parallel"))
+	return computeIntegrityHashParallel(reader, segmentSize, dataShards, parityShards, options)
+}
+
+// selectMode return ModeSerial if the reader content is smaller than threshold and ModeParallel otherwise.
+// The size is taken from the reader if it is known, e.g. of a bytes.Reader or a regular file, otherwise up to
+// threshold bytes are read ahead and the returned reader replays them.
+func selectMode(reader io.Reader, segmentSize int64, threshold int64) (io.Reader, Mode, error) {
+	if threshold <= 0 {
+		threshold = DefaultAutoThresholdSegments * segmentSize
+	}
+	size := int64(-1)
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		size = int64(r.Len())
+	case interface {
+		Stat() (fs.FileInfo, error)
+		io.Seeker
+	}:
+		// e.g. an *os.File
+		if info, err := r.Stat(); err == nil && info.Mode().IsRegular() {
+			if offset, err := r.Seek(0, io.SeekCurrent); err == nil {
+				size = info.Size() - offset
+			}
+		}
+	}
+	if size < 0 {
+		head, err := io.ReadAll(io.LimitReader(reader, threshold))
+		if err != nil {
+			return nil, ModeAuto, err
+		}
+		size = int64(len(head))
+		reader = io.MultiReader(bytes.NewReader(head), reader)
+	}
+	if size < threshold {
+		return reader, ModeSerial, nil
+	}
+	return reader, ModeParallel, nil
+}
+
+// ComputeIntegrityHashWithOptions return the integrity hash result of the reader content configured by opts.
+// Without WithMode the version is selected by the object size, see ModeAuto.
+//
+// An empty object has a single empty segment list, so the integrity hash of the PrimarySP and of every SecondarySP
+// is EmptyIntegrityHash. An object smaller than a segment is one segment, which is padded to the shard size by the
+// erasure encoding, e.g. a single byte object is encoded to data shards of one byte each.
+func ComputeIntegrityHashWithOptions(reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	opts ...Option,
+) (*HashResult, error) {
+	options := newHashOptions(opts)
+	if options.greenfieldCompat {
+		report, err := compatCheck(reader, segmentSize, dataShards, parityShards, options)
+		if err != nil {
+			return nil, err
+		}
+		if err = compatError(report); err != nil {
+			return nil, err
+		}
+		return report.Result, nil
+	}
+	return computeHashResultWithOptions(reader, segmentSize, dataShards, parityShards, options)
+}
+
+// computeHashResultWithOptions computes the result of ComputeIntegrityHashWithOptions, without the greenfield-common
+// verification
+func computeHashResultWithOptions(reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	options *hashOptions,
+) (*HashResult, error) {
+	checksums, contentLen, redundancyType, err := computeIntegrityHash(reader, segmentSize, dataShards, parityShards,
+		options)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkObjectSize(contentLen, options); err != nil {
+		return nil, err
+	}
+	result := NewHashResult(checksums, contentLen, redundancyType)
+	result.FastChecksums = options.fastChecksums.result(len(checksums) - 1)
+	return result, nil
+}
+
+// ComputeIntegrityHashWithParams validates the redundancy params, so invalid params fail before the reader is read,
+// and compute the integrity hash of the reader content like ComputeIntegrityHashWithOptions
+func ComputeIntegrityHashWithParams(reader io.Reader, params redundancy.RedundancyParams, opts ...Option) (*HashResult,
+	error,
 ) {
-	if isSerial {
-		return ComputeIntegrityHashSerial(reader, segmentSize, dataShards, parityShards)
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	return ComputeIntegrityHashWithOptions(reader, params.SegmentSize, params.DataShards, params.ParityShards, opts...)
+}
+
+// checkObjectSize return an error if the object is smaller than the minimum object size of options
+func checkObjectSize(contentLen int64, options *hashOptions) error {
+	if contentLen < options.minObjectSize {
+		if contentLen == 0 {
+			return ErrEmptyObject
+		}
+		return fmt.Errorf("%w: object size %d, minimum object size %d", ErrObjectTooSmall, contentLen,
+			options.minObjectSize)
 	}
-	return ComputeIntegrityHashParallel(reader, segmentSize, dataShards, parityShards)
+	return nil
 }
 
 // ComputeIntegrityHashSerial split the reader into segment, ec encode the data, compute the hash roots of pieces in a serial way
 // return the hash result array list and data size
 func ComputeIntegrityHashSerial(reader io.Reader, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
-	storagetypes.RedundancyType, error,
+	redundancy.RedundancyType, error,
 ) {
+	return computeIntegrityHashSerial(reader, segmentSize, dataShards, parityShards, newHashOptions(nil))
+}
+
+func computeIntegrityHashSerial(reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	options *hashOptions,
+) ([][]byte, int64, redundancy.RedundancyType, error) {
 	var segChecksumList [][]byte
-	ecShards := dataShards + parityShards
+	strategy := options.redundancy(dataShards, parityShards)
+	ecShards := strategy.PieceCount()
 
 	encodeDataHash := make([][][]byte, ecShards)
 	for i := 0; i < ecShards; i++ {
@@ -180,28 +381,44 @@ func ComputeIntegrityHashSerial(reader io.Reader, segmentSize int64, dataShards,
 
 	hashList := make([][]byte, ecShards+1)
 	contentLen := int64(0)
+	memory := segmentMemory(segmentSize, dataShards, parityShards)
+	nextSegment, release, stop := readSegments(reader, segmentSize, memory, options)
+	defer stop()
 	// read the data by segment segmentSize
 	for {
-		seg := make([]byte, segmentSize)
-		n, err := reader.Read(seg)
+		data, err := nextSegment()
 		if err != nil {
+			if errors.Is(err, ErrMemoryBudgetExceeded) {
+				return nil, 0, redundancy.RedundancyECType, err
+			}
 			if err != io.EOF {
-				log.Error().Msg("failed to read content:" + err.Error())
-				return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+				options.logger.Errorf("failed to read content: %s", err)
+				return nil, 0, redundancy.RedundancyECType, readerError(err)
 			}
 			break
 		}
 
-		if n > 0 && n <= int(segmentSize) {
+		if n := len(data); n > 0 && n <= int(segmentSize) {
+			start := time.Now()
+			if err = options.storeSegment(len(segChecksumList), contentLen, data); err != nil {
+				release(data)
+				return nil, 0, redundancy.RedundancyECType, err
+			}
 			contentLen += int64(n)
-			data := seg[:n]
+			ctx, span := startSegmentSpan(options.traceCtx, len(segChecksumList), n)
 			// compute segment hash
 			checksum := GenerateChecksum(data)
 			segChecksumList = append(segChecksumList, checksum)
 
-			if err = encodeAndComputeHash(encodeDataHash, data, dataShards, parityShards); err != nil {
-				return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+			err = encodeAndComputeHash(ctx, encodeDataHash, len(segChecksumList)-1, data, dataShards, parityShards,
+				options)
+			release(data)
+			tracing.End(span, err)
+			if err != nil {
+				return nil, 0, redundancy.RedundancyECType, &SegmentError{Segment: len(segChecksumList) - 1,
+					Kind: ErrEncodeFailed, Err: err}
 			}
+			options.metrics.ObserveSegment(n, time.Since(start))
 		}
 	}
 
@@ -221,93 +438,203 @@ func ComputeIntegrityHashSerial(reader io.Reader, segmentSize int64, dataShards,
 
 	wg.Wait()
 
-	return hashList, contentLen, storagetypes.REDUNDANCY_EC_TYPE, nil
+	return hashList, contentLen, strategy.RedundancyType(), nil
 }
 
-func encodeAndComputeHash(encodeDataHash [][][]byte, segment []byte, dataShards, parityShards int) error {
-	// get erasure encode bytes
-	encodeShards, err := redundancy.EncodeRawSegment(segment, dataShards, parityShards)
-	if err != nil {
-		return err
+// readSegments return a func returning the next segment of the reader split by segment.SplitBySegment, or
+// io.EOF once the reader is drained. The memory of every returned segment is reserved from the memory limiter of the
+// options, the returned release func must be called with the segment once it is hashed to release its memory and
+// its buffer.
+// If the prefetch depth of the options is positive the segments are read by a goroutine up to depth segments ahead
+// of the caller, so reading overlaps with the hashing of the previous segments; the returned stop func must be
+// called to release it.
+func readSegments(reader io.Reader, segmentSize int64, memory int64, options *hashOptions) (func() ([]byte, error),
+	func([]byte), func(),
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	splitter := segment.SplitBySegment(reader, segmentSize)
+	read := func() ([]byte, error) {
+		if err := options.acquireMemory(ctx, memory); err != nil {
+			return nil, err
+		}
+		_, data, _, err := splitter.Next()
+		if err != nil {
+			options.releaseMemory(memory)
+			return nil, err
+		}
+		return data, nil
 	}
-
-	for index, shard := range encodeShards {
-		// compute hash of pieces
-		piecesHash := GenerateChecksum(shard)
-		encodeDataHash[index] = append(encodeDataHash[index], piecesHash)
+	release := func(data []byte) {
+		splitter.Release(data)
+		options.releaseMemory(memory)
+	}
+	if options.prefetchDepth <= 0 {
+		return read, release, cancel
 	}
 
-	return nil
+	type prefetched struct {
+		data []byte
+		err  error
+	}
+	segments := make(chan prefetched, options.prefetchDepth)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			data, err := read()
+			select {
+			case segments <- prefetched{data: data, err: err}:
+			case <-done:
+				if err == nil {
+					release(data)
+				}
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	var last error
+	next := func() ([]byte, error) {
+		if last != nil {
+			return nil, last
+		}
+		segment := <-segments
+		last = segment.err
+		return segment.data, segment.err
+	}
+	stop := func() {
+		cancel()
+		close(done)
+		<-exited
+		// release the segments read ahead but never returned
+		for len(segments) > 0 {
+			if segment := <-segments; segment.err == nil {
+				release(segment.data)
+			}
+		}
+	}
+	return next, release, stop
 }
 
-// ComputerHashFromFile open a local file and compute hash result and segmentSize
-func ComputerHashFromFile(filePath string, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
-	storagetypes.RedundancyType, error,
-) {
-	f, err := os.Open(filePath)
+func encodeAndComputeHash(ctx context.Context, encodeDataHash [][][]byte, segIndex int, segment []byte, dataShards,
+	parityShards int, options *hashOptions,
+) error {
+	pieceChecksumList, err := computePieceHashes(ctx, segIndex, segment, dataShards, parityShards, options)
 	if err != nil {
-		log.Error().Msg("failed to open file:" + err.Error())
-		return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+		return err
+	}
+	for index, piecesHash := range pieceChecksumList {
+		encodeDataHash[index] = append(encodeDataHash[index], piecesHash)
 	}
-	defer f.Close()
 
-	return ComputeIntegrityHash(f, segmentSize, dataShards, parityShards, false)
+	return nil
 }
 
 // ComputerHashFromBuffer support computing hash and segmentSize from byte buffer
 func ComputerHashFromBuffer(content []byte, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
-	storagetypes.RedundancyType, error,
+	redundancy.RedundancyType, error,
 ) {
 	reader := bytes.NewReader(content)
 	return ComputeIntegrityHash(reader, segmentSize, dataShards, parityShards, false)
 }
 
-// computePieceHashes encode the segment and return the hashes of ec pieces
-func computePieceHashes(segment []byte, dataShards, parityShards int) ([][]byte, error) {
+// computePieceHashes encode the segment with the redundancy strategy and return the hashes of the pieces, the
+// encoding is traced as a child of the span of ctx. The fast checksums of the segment and of the pieces are recorded
+// if the options collect them.
+func computePieceHashes(ctx context.Context, segIndex int, segment []byte, dataShards, parityShards int,
+	options *hashOptions,
+) ([][]byte, error) {
 	// get erasure encode bytes
-	encodeShards, err := redundancy.EncodeRawSegment(segment, dataShards, parityShards)
+	start := time.Now()
+	_, span := tracing.Start(ctx, "hash.Encode", tracing.Int("size", len(segment)))
+	encodeShards, err := options.redundancy(dataShards, parityShards).Encode(segment)
+	tracing.End(span, err)
 	if err != nil {
 		return nil, err
 	}
-
-	var pieceChecksumList [][]byte
-	for _, shard := range encodeShards {
-		// compute hash of pieces
-		piecesHash := GenerateChecksum(shard)
-		pieceChecksumList = append(pieceChecksumList, piecesHash)
+	options.metrics.ObserveEncode(time.Since(start))
+	if options.fastChecksums != nil {
+		options.fastChecksums.record(segIndex, segment, encodeShards)
 	}
 
-	return pieceChecksumList, nil
+	return hashShards(encodeShards, options.shardConcurrency), nil
 }
 
-// hashWorker receive the segment info and compute the corresponding segment hash and piece hashes.
-// The result will be stored in the sync map to compute integrity hash in order.
-func hashWorker(jobs <-chan SegmentInfo, errChan chan<- error, dataShards, parityShards int, wg *sync.WaitGroup,
-	segmentHashMap *sync.Map, pieceHashMap *sync.Map,
-) {
-	defer wg.Done()
+// startSegmentSpan opens the span of the hashing of a segment of size bytes
+func startSegmentSpan(ctx context.Context, segIndex, size int) (context.Context, tracing.Span) {
+	return tracing.Start(ctx, "hash.Segment", tracing.Int("segment", segIndex), tracing.Int("size", size))
+}
+
+// hashShards return the hashes of the shards in the order of the shards, up to concurrency shards are hashed at
+// the same time
+func hashShards(shards [][]byte, concurrency int) [][]byte {
+	pieceChecksumList := make([][]byte, len(shards))
+	if concurrency > len(shards) {
+		concurrency = len(shards)
+	}
+	if concurrency <= 1 {
+		for index, shard := range shards {
+			// compute hash of pieces
+			pieceChecksumList[index] = GenerateChecksum(shard)
+		}
+		return pieceChecksumList
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for worker := 0; worker < concurrency; worker++ {
+		go func(first int) {
+			defer wg.Done()
+			for index := first; index < len(shards); index += concurrency {
+				pieceChecksumList[index] = GenerateChecksum(shards[index])
+			}
+		}(worker)
+	}
+	wg.Wait()
+	return pieceChecksumList
+}
 
-	for segInfo := range jobs {
+// hashSegmentTask return the task computing the segment hash and the piece hashes of the segment, the results are
+// stored in the sync maps to compute the integrity hashes in order
+func hashSegmentTask(segInfo SegmentInfo, dataShards, parityShards int, segmentHashMap, pieceHashMap *sync.Map,
+	options *hashOptions,
+) taskqueue.Task {
+	return func(context.Context) error {
+		start := time.Now()
+		ctx, span := startSegmentSpan(options.traceCtx, segInfo.SegmentID, len(segInfo.Data))
 		checksum := GenerateChecksum(segInfo.Data)
 		segmentHashMap.Store(segInfo.SegmentID, checksum)
 
-		pieceChecksumList, err := computePieceHashes(segInfo.Data, dataShards, parityShards)
+		pieceChecksumList, err := computePieceHashes(ctx, segInfo.SegmentID, segInfo.Data, dataShards, parityShards,
+			options)
+		tracing.End(span, err)
 		if err != nil {
-			errChan <- err
-			return
+			return &SegmentError{Segment: segInfo.SegmentID, Kind: ErrEncodeFailed, Err: err}
 		}
 		pieceHashMap.Store(segInfo.SegmentID, pieceChecksumList)
+		options.metrics.ObserveSegment(len(segInfo.Data), time.Since(start))
+		return nil
 	}
 }
 
 // ComputeIntegrityHashParallel split the reader into segment, ec encode the data, compute the hash roots of pieces using
 // return the hash result array list and data segmentSize
 func ComputeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShards, parityShards int) ([][]byte, int64,
-	storagetypes.RedundancyType, error,
+	redundancy.RedundancyType, error,
 ) {
+	return computeIntegrityHashParallel(reader, segmentSize, dataShards, parityShards, newHashOptions(nil))
+}
+
+func computeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShards, parityShards int,
+	options *hashOptions,
+) ([][]byte, int64, redundancy.RedundancyType, error) {
 	var (
 		segChecksumList [][]byte
-		ecShards        = dataShards + parityShards
+		strategy        = options.redundancy(dataShards, parityShards)
+		ecShards        = strategy.PieceCount()
 		contentLen      = int64(0)
 		wg              sync.WaitGroup
 	)
@@ -318,67 +645,72 @@ func ComputeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShard
 	// store the result of integrity hash
 	hashList := make([][]byte, ecShards+1)
 
-	jobChan := make(chan SegmentInfo, jobChannelSize)
-	errChan := make(chan error, 1)
-	// the thread num should be less than maxThreadNum
-	threadNum := runtime.NumCPU() / 2
-	if threadNum > maxThreadNum {
-		threadNum = maxThreadNum
-	}
-	// start workers to compute hash of each segment
-	for i := 0; i < threadNum; i++ {
-		wg.Add(1)
-		go hashWorker(jobChan, errChan, dataShards, parityShards, &wg, segHashMap, pieceHashMap)
-	}
+	// the workers compute the hash of each segment, closing the pool waits for the queued segments
+	pool := taskqueue.New(options.workerNum(), taskqueue.WithQueueSize(jobChannelSize),
+		taskqueue.WithActiveObserver(options.metrics.ObserveActiveWorkers))
+	defer pool.Close()
+	group, _ := pool.Group(context.Background())
 
 	jobNum := 0
+	memory := segmentMemory(segmentSize, dataShards, parityShards)
+	splitter := segment.SplitBySegment(reader, segmentSize)
 	for {
-		seg := make([]byte, segmentSize)
-		n, err := reader.Read(seg)
+		// the tasks release the memory of the segments
+		if err := options.acquireMemory(context.Background(), memory); err != nil {
+			return nil, 0, redundancy.RedundancyECType, err
+		}
+		_, data, _, err := splitter.Next()
 		if err != nil {
+			options.releaseMemory(memory)
 			if err != io.EOF {
-				log.Error().Msg("failed to read content:" + err.Error())
-				return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
+				options.logger.Errorf("failed to read content: %s", err)
+				return nil, 0, redundancy.RedundancyECType, readerError(err)
 			}
 			break
 		}
+		release := func() {
+			splitter.Release(data)
+			options.releaseMemory(memory)
+		}
 
-		if n > 0 && n <= int(segmentSize) {
-			contentLen += int64(n)
-			data := seg[:n]
-			// compute segment hash
+		// store the segment while the workers hash the previous ones
+		if err = options.storeSegment(jobNum, contentLen, data); err != nil {
+			release()
+			return nil, 0, redundancy.RedundancyECType, err
+		}
+		contentLen += int64(len(data))
 
-			jobChan <- SegmentInfo{SegmentID: jobNum, Data: data}
-			jobNum++
+		// stop reading at the first segment which failed, the segments are released once they are hashed or skipped
+		err = group.Go(hashSegmentTask(SegmentInfo{SegmentID: jobNum, Data: data}, dataShards, parityShards,
+			segHashMap, pieceHashMap, options), taskqueue.WithCleanup(release))
+		if err != nil {
+			release()
+			break
 		}
+		options.metrics.ObserveQueueDepth(pool.QueueDepth())
+		jobNum++
 	}
-	close(jobChan)
 
-	for i := 0; i < ecShards; i++ {
-		encodeDataHash[i] = make([][]byte, jobNum)
+	// check error
+	if err := group.Wait(); err != nil {
+		options.logger.Errorf("failed to hash segment: %s", err)
+		return nil, 0, redundancy.RedundancyECType, err
 	}
 
-	wg.Wait()
-	close(errChan)
-
-	// check error
-	for err := range errChan {
-		if err != nil {
-			log.Error().Msg("err chan detected err:" + err.Error())
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, err
-		}
+	for i := 0; i < ecShards; i++ {
+		encodeDataHash[i] = make([][]byte, jobNum)
 	}
 
 	for i := 0; i < jobNum; i++ {
 		segHashValue, ok := segHashMap.Load(i)
 		if !ok {
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, fmt.Errorf("fail to load the segment hash")
+			return nil, 0, redundancy.RedundancyECType, &SegmentError{Segment: i, Kind: ErrSegmentHashMissing}
 		}
 		segChecksumList = append(segChecksumList, segHashValue.([]byte))
 
 		pieceHashValue, ok := pieceHashMap.Load(i)
 		if !ok {
-			return nil, 0, storagetypes.REDUNDANCY_EC_TYPE, fmt.Errorf("fail to load the segment hash")
+			return nil, 0, redundancy.RedundancyECType, &SegmentError{Segment: i, Kind: ErrSegmentHashMissing}
 		}
 		hashValues := pieceHashValue.([][]byte)
 		for j := 0; j < len(encodeDataHash); j++ {
@@ -400,5 +732,5 @@ func ComputeIntegrityHashParallel(reader io.Reader, segmentSize int64, dataShard
 	}
 
 	wg.Wait()
-	return hashList, contentLen, storagetypes.REDUNDANCY_EC_TYPE, nil
+	return hashList, contentLen, strategy.RedundancyType(), nil
 }
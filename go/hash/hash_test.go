@@ -2,18 +2,24 @@ package hash
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
-	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/zkMeLabs/mechain-common/go/log"
 	"github.com/zkMeLabs/mechain-common/go/redundancy"
 )
 
@@ -36,7 +42,7 @@ func TestHash(t *testing.T) {
 	if size != length {
 		t.Errorf("compute segmentSize error")
 	}
-	if redundancyType != storagetypes.REDUNDANCY_EC_TYPE {
+	if redundancyType != redundancy.RedundancyECType {
 		t.Errorf("compare  redundnacy type error")
 	}
 
@@ -276,3 +282,487 @@ func verifyHashResult(hashHandler *IntegrityHasher, expectedResult []string, exp
 
 	return nil
 }
+
+func TestEmptyAndSingleByteObject(t *testing.T) {
+	for _, isSerial := range []bool{true, false} {
+		result, err := ComputeIntegrityHashWithOptions(bytes.NewReader(nil), segmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithSerial(isSerial))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), result.ContentLength)
+		assert.Equal(t, redundancy.DataBlocks+redundancy.ParityBlocks+1, len(result.Checksums))
+		for _, checksum := range result.Checksums {
+			assert.Equal(t, EmptyIntegrityHash(), checksum)
+		}
+
+		_, err = ComputeIntegrityHashWithOptions(bytes.NewReader(nil), segmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithSerial(isSerial), WithMinObjectSize(1))
+		assert.ErrorIs(t, err, ErrEmptyObject)
+
+		result, err = ComputeIntegrityHashWithOptions(bytes.NewReader([]byte{'a'}), segmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithSerial(isSerial), WithMinObjectSize(1))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), result.ContentLength)
+		assert.Equal(t, GenerateIntegrityHash([][]byte{GenerateChecksum([]byte{'a'})}), result.PrimaryChecksum())
+
+		_, err = ComputeIntegrityHashWithOptions(bytes.NewReader([]byte{'a'}), segmentSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithSerial(isSerial), WithMinObjectSize(2))
+		assert.ErrorIs(t, err, ErrObjectTooSmall)
+	}
+}
+
+func TestComputeIntegrityHashStream(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(3*segSize + 100)
+	expected, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, WithSerial(true))
+	assert.Nil(t, err)
+
+	results := make(chan SegmentResult)
+	var segments []SegmentResult
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range results {
+			segments = append(segments, result)
+		}
+	}()
+	result, err := ComputeIntegrityHashStream(context.Background(), bytes.NewReader(content), segSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks, results)
+	<-done
+	assert.Nil(t, err)
+	assert.Equal(t, expected, result)
+
+	assert.Equal(t, 4, len(segments))
+	for index, segment := range segments {
+		assert.Equal(t, index, segment.SegmentIndex)
+		end := (index + 1) * segSize
+		if end > len(content) {
+			end = len(content)
+		}
+		assert.Equal(t, GenerateChecksum(content[index*segSize:end]), segment.Checksum)
+		for ecIndex, piece := range segment.Pieces {
+			assert.Equal(t, GenerateChecksum(piece), segment.PieceChecksums[ecIndex])
+		}
+	}
+
+	// a cancelled context stops the computation once the consumer does not receive
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ComputeIntegrityHashStream(ctx, bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, make(chan SegmentResult))
+	assert.ErrorIs(t, err, context.Canceled)
+	// the content is not read once the context is canceled
+	_, err = ComputeIntegrityHashStream(ctx, iotest.ErrReader(errors.New("read after cancel")), segSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks, make(chan SegmentResult, 1))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+type testMetricsCollector struct {
+	mu       sync.Mutex
+	bytes    int
+	segments int
+	encodes  int
+}
+
+func (c *testMetricsCollector) ObserveSegment(size int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bytes += size
+	c.segments++
+}
+
+func (c *testMetricsCollector) ObserveEncode(time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.encodes++
+}
+
+func (c *testMetricsCollector) ObserveQueueDepth(int) {}
+
+func (c *testMetricsCollector) ObserveActiveWorkers(int) {}
+
+func TestHashMetrics(t *testing.T) {
+	const segSize = 1024
+	for _, isSerial := range []bool{true, false} {
+		collector := &testMetricsCollector{}
+		_, err := ComputeIntegrityHashWithOptions(bytes.NewReader(TestVectorData(5*segSize+1)), segSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks, WithSerial(isSerial), WithMetrics(collector))
+		assert.Nil(t, err)
+		assert.Equal(t, 5*segSize+1, collector.bytes)
+		assert.Equal(t, 6, collector.segments)
+		assert.Equal(t, 6, collector.encodes)
+	}
+}
+
+type recordLogger struct {
+	log.NopLogger
+	errors []string
+}
+
+func (l *recordLogger) Errorf(format string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestHashWithLogger(t *testing.T) {
+	for _, isSerial := range []bool{true, false} {
+		logger := &recordLogger{}
+		_, err := ComputeIntegrityHashWithOptions(iotest.ErrReader(errors.New("disk failure")), segmentSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks, WithSerial(isSerial), WithLogger(logger))
+		assert.NotNil(t, err)
+		assert.Equal(t, []string{"failed to read content: disk failure"}, logger.errors)
+	}
+}
+
+func TestHashErrors(t *testing.T) {
+	const segSize = 16
+	hasher := NewHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	hasher.Init()
+	assert.ErrorIs(t, hasher.Append(make([]byte, segSize+1)), ErrSegmentTooLarge)
+
+	readErr := errors.New("disk failure")
+	_, _, _, err := ComputeIntegrityHash(iotest.ErrReader(readErr), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, false)
+	assert.ErrorIs(t, err, ErrReaderFailed)
+	assert.ErrorIs(t, err, readErr)
+
+	// invalid ec params fail to encode every segment, the parallel version should not block on the failed workers
+	for _, isSerial := range []bool{true, false} {
+		_, _, _, err = ComputeIntegrityHash(bytes.NewReader(TestVectorData(300*segSize)), segSize, 0, 2, isSerial)
+		assert.ErrorIs(t, err, ErrEncodeFailed)
+		var segErr *SegmentError
+		assert.True(t, errors.As(err, &segErr))
+		if isSerial {
+			assert.Equal(t, 0, segErr.Segment)
+		}
+	}
+
+	checksums := [][]byte{GenerateChecksum([]byte("a")), GenerateChecksum([]byte("b"))}
+	root := GenerateIntegrityHash(checksums)
+	assert.Nil(t, ChallengePieceHash(root, checksums, 1, []byte("b")))
+	assert.ErrorIs(t, ChallengePieceHash(root, checksums, 2, []byte("b")), ErrInvalidChecksumList)
+	assert.ErrorIs(t, ChallengePieceHash(root, checksums, 0, []byte("b")), ErrPieceChecksumMismatch)
+	assert.ErrorIs(t, VerifyIntegrityHash(root, checksums[:1]), ErrIntegrityHashMismatch)
+}
+
+func TestAutoMode(t *testing.T) {
+	const segSize = 1024
+	small := TestVectorData(DefaultAutoThresholdSegments*segSize - 1)
+	large := TestVectorData(DefaultAutoThresholdSegments * segSize)
+
+	_, mode, err := selectMode(bytes.NewReader(small), segSize, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ModeSerial, mode)
+	_, mode, err = selectMode(bytes.NewReader(large), segSize, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ModeParallel, mode)
+	_, mode, err = selectMode(bytes.NewReader(large), segSize, int64(len(large)+1))
+	assert.Nil(t, err)
+	assert.Equal(t, ModeSerial, mode)
+
+	filePath := filepath.Join(t.TempDir(), "object")
+	assert.Nil(t, os.WriteFile(filePath, large, 0o600))
+	f, err := os.Open(filePath)
+	assert.Nil(t, err)
+	defer f.Close()
+	_, mode, err = selectMode(f, segSize, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, ModeParallel, mode)
+
+	// the size of the reader is unknown, the read ahead content must be hashed as well
+	for _, content := range [][]byte{small, large} {
+		expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, true)
+		assert.Nil(t, err)
+		computed, err := ComputeIntegrityHashWithOptions(iotest.HalfReader(bytes.NewReader(content)), segSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks)
+		assert.Nil(t, err)
+		assert.Nil(t, diffHashResult(expected, computed))
+	}
+
+	_, err = ComputeIntegrityHashWithOptions(iotest.ErrReader(errors.New("read failed")), segSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks)
+	assert.ErrorIs(t, err, ErrReaderFailed)
+}
+
+func TestPrefetch(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(10*segSize + 1)
+	filePath := filepath.Join(t.TempDir(), "object")
+	assert.Nil(t, os.WriteFile(filePath, content, 0o600))
+
+	expected, _, _, err := ComputerHashFromFile(filePath, segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	assert.Nil(t, err)
+	for _, depth := range []int{0, 1, 3, 20} {
+		computed, _, _, err := ComputerHashFromFile(filePath, segSize, redundancy.DataBlocks, redundancy.ParityBlocks,
+			WithMode(ModeSerial), WithPrefetch(depth))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, computed)
+	}
+
+	reader := io.MultiReader(bytes.NewReader(content), iotest.ErrReader(errors.New("disk failure")))
+	_, err = ComputeIntegrityHashWithOptions(reader, segSize, redundancy.DataBlocks, redundancy.ParityBlocks,
+		WithMode(ModeSerial), WithPrefetch(2))
+	assert.ErrorIs(t, err, ErrReaderFailed)
+
+	// the prefetcher is released when the hashing fails before the reader is drained
+	_, err = ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, 0, redundancy.ParityBlocks,
+		WithMode(ModeSerial), WithPrefetch(2))
+	assert.ErrorIs(t, err, ErrEncodeFailed)
+}
+
+func BenchmarkComputerHashFromFile(b *testing.B) {
+	const segSize = 1024 * 1024
+	filePath := filepath.Join(b.TempDir(), "object")
+	if err := os.WriteFile(filePath, TestVectorData(16*segSize), 0o600); err != nil {
+		b.Fatal(err)
+	}
+	for _, depth := range []int{0, 2} {
+		b.Run(fmt.Sprintf("serial prefetch %d", depth), func(b *testing.B) {
+			b.SetBytes(16 * segSize)
+			for i := 0; i < b.N; i++ {
+				if _, _, _, err := ComputerHashFromFile(filePath, segSize, redundancy.DataBlocks,
+					redundancy.ParityBlocks, WithMode(ModeSerial), WithPrefetch(depth)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestShardConcurrency(t *testing.T) {
+	const segSize = 4096
+	content := TestVectorData(5*segSize + 3)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, 6, 3, true)
+	assert.Nil(t, err)
+	for _, n := range []int{1, 2, 4, 9, 16} {
+		for _, mode := range []Mode{ModeSerial, ModeParallel} {
+			computed, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, 6, 3, WithMode(mode),
+				WithShardConcurrency(n))
+			assert.Nil(t, err)
+			assert.Nil(t, diffHashResult(expected, computed))
+		}
+	}
+}
+
+func BenchmarkComputePieceHashes(b *testing.B) {
+	segment := TestVectorData(segmentSize)
+	for _, n := range []int{1, 3, 6} {
+		b.Run(fmt.Sprintf("shard concurrency %d", n), func(b *testing.B) {
+			options := newHashOptions([]Option{WithShardConcurrency(n)})
+			b.SetBytes(segmentSize)
+			for i := 0; i < b.N; i++ {
+				_, err := computePieceHashes(context.Background(), 0, segment, redundancy.DataBlocks,
+					redundancy.ParityBlocks, options)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestAppendOwned(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(7*segSize + 100)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, true)
+	assert.Nil(t, err)
+
+	for _, chunkSizes := range [][]int{{segSize}, {segSize / 2}, {segSize, 1, segSize - 1, 300}, {700, segSize}} {
+		hasher := NewHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+		hasher.Init()
+		for start, chunk := 0, 0; start < len(content); chunk++ {
+			end := min(start+chunkSizes[chunk%len(chunkSizes)], len(content))
+			// the hasher owns the chunk, alternate with Append which copies it
+			owned := append([]byte(nil), content[start:end]...)
+			if chunk%3 == 2 {
+				assert.Nil(t, hasher.Append(owned))
+			} else {
+				assert.Nil(t, hasher.AppendOwned(owned))
+			}
+			start = end
+		}
+		checksums, contentLen, redundancyType, err := hasher.Finish()
+		assert.Nil(t, err)
+		assert.Nil(t, diffHashResult(expected, NewHashResult(checksums, contentLen, redundancyType)), chunkSizes)
+	}
+
+	hasher := NewHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	hasher.Init()
+	assert.ErrorIs(t, hasher.AppendOwned(make([]byte, segSize+1)), ErrSegmentTooLarge)
+}
+
+func TestAppendOwnedPooledHasher(t *testing.T) {
+	const segSize = 1024
+	hasher := AcquireHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	owned := make([]byte, 100, segSize)
+	copy(owned, TestVectorData(100))
+	assert.Nil(t, hasher.AppendOwned(owned))
+	_, _, _, err := hasher.Finish()
+	assert.Nil(t, err)
+	ReleaseHasher(hasher)
+	// the owned data is not written once the hasher is released, even by the next user of the pooled hasher
+	snapshot := append([]byte(nil), owned[:cap(owned)]...)
+
+	hasher = AcquireHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	defer ReleaseHasher(hasher)
+	content := TestVectorData(segSize - 1)
+	for start := 0; start < len(content); start += 100 {
+		assert.Nil(t, hasher.Append(content[start:min(start+100, len(content))]))
+	}
+	checksums, contentLen, redundancyType, err := hasher.Finish()
+	assert.Nil(t, err)
+	assert.Equal(t, snapshot, owned[:cap(owned)])
+
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, true)
+	assert.Nil(t, err)
+	assert.Nil(t, diffHashResult(expected, NewHashResult(checksums, contentLen, redundancyType)))
+}
+
+func BenchmarkIntegrityHasherAppend(b *testing.B) {
+	const segSize = 1024 * 1024
+	for _, owned := range []bool{false, true} {
+		b.Run(fmt.Sprintf("owned %t", owned), func(b *testing.B) {
+			chunks := make([][]byte, 16)
+			b.SetBytes(int64(len(chunks) * segSize))
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				for index := range chunks {
+					chunks[index] = TestVectorData(segSize)
+				}
+				b.StartTimer()
+				hasher := NewHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+				hasher.Init()
+				for _, chunk := range chunks {
+					var err error
+					if owned {
+						err = hasher.AppendOwned(chunk)
+					} else {
+						err = hasher.Append(chunk)
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				if _, _, _, err := hasher.Finish(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateIntegrityHashWithDomain(t *testing.T) {
+	checksums := [][]byte{GenerateChecksum([]byte("a")), GenerateChecksum([]byte("b"))}
+	primary := GenerateIntegrityHashWithDomain(DomainPrimary, checksums)
+	assert.NotEqual(t, primary, GenerateIntegrityHashWithDomain(DomainSecondary, checksums))
+	assert.NotEqual(t, primary, GenerateIntegrityHash(checksums))
+	assert.Nil(t, VerifyIntegrityHashWithDomain(DomainPrimary, primary, checksums))
+	assert.ErrorIs(t, VerifyIntegrityHashWithDomain(DomainMultipart, primary, checksums), ErrIntegrityHashMismatch)
+
+	// the leaf boundaries are part of the hash, unlike the concatenation of GenerateIntegrityHash
+	joined := [][]byte{append(append([]byte(nil), checksums[0]...), checksums[1]...)}
+	assert.Equal(t, GenerateIntegrityHash(checksums), GenerateIntegrityHash(joined))
+	assert.NotEqual(t, primary, GenerateIntegrityHashWithDomain(DomainPrimary, joined))
+	assert.NotEqual(t, GenerateIntegrityHashWithDomain(DomainPrimary, nil),
+		GenerateIntegrityHashWithDomain(DomainPrimary, [][]byte{{}}))
+}
+
+func TestHashVersion(t *testing.T) {
+	result, err := ComputeIntegrityHashWithOptions(bytes.NewReader(TestVectorData(100)), 64, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	assert.Nil(t, err)
+	assert.Equal(t, CurrentHashVersion, result.Version)
+	assert.Nil(t, result.CheckVersion())
+
+	// the results serialized before the version was introduced are read as legacy results
+	var legacy HashResult
+	assert.Nil(t, json.Unmarshal([]byte(`{"checksums":[],"content_length":100,"redundancy_type":0}`), &legacy))
+	assert.Equal(t, HashVersionLegacy, legacy.Version)
+	assert.Nil(t, legacy.CheckVersion())
+
+	future := HashResult{Version: CurrentHashVersion + 1}
+	assert.ErrorIs(t, future.CheckVersion(), ErrUnsupportedHashVersion)
+	assert.ErrorIs(t, diffHashResult(&future, result), ErrUnsupportedHashVersion)
+}
+
+func TestIntegrityHasherReuse(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(3*segSize + 10)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, true)
+	assert.Nil(t, err)
+
+	hasher := AcquireHasher(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+	for round := 0; round < 3; round++ {
+		for start := 0; start < len(content); start += 300 {
+			assert.Nil(t, hasher.Append(content[start:min(start+300, len(content))]))
+		}
+		// Finish is idempotent and the hasher rejects data until it is reset
+		for j := 0; j < 2; j++ {
+			checksums, contentLen, redundancyType, err := hasher.Finish()
+			assert.Nil(t, err)
+			assert.Nil(t, diffHashResult(expected, NewHashResult(checksums, contentLen, redundancyType)))
+		}
+		assert.ErrorIs(t, hasher.Append([]byte{1}), ErrAlreadyFinished)
+		assert.ErrorIs(t, hasher.AppendOwned([]byte{1}), ErrAlreadyFinished)
+		hasher.Reset()
+	}
+	ReleaseHasher(hasher)
+
+	// a pooled hasher is reset with the new params
+	hasher = AcquireHasher(segSize, 6, 3)
+	defer ReleaseHasher(hasher)
+	assert.Nil(t, hasher.Append(content[:segSize]))
+	checksums, contentLen, _, err := hasher.Finish()
+	assert.Nil(t, err)
+	assert.Len(t, checksums, 10)
+	assert.Equal(t, int64(segSize), contentLen)
+}
+
+func TestWithStrategy(t *testing.T) {
+	const segSize = 4096
+	content := TestVectorData(3*segSize + 5)
+	ecResult, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, 4, 2,
+		WithStrategy(redundancy.NewReedSolomonStrategy(4, 2)))
+	assert.Nil(t, err)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, 4, 2, true)
+	assert.Nil(t, err)
+	assert.Nil(t, diffHashResult(expected, ecResult))
+
+	for _, mode := range []Mode{ModeSerial, ModeParallel} {
+		computed, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, 4, 2, WithMode(mode),
+			WithStrategy(redundancy.NewReplicationStrategy(3)))
+		assert.Nil(t, err)
+		assert.Equal(t, redundancy.RedundancyReplicaType, computed.RedundancyType)
+		assert.Equal(t, 4, len(computed.Checksums))
+		// every replica holds the whole segments
+		for _, checksum := range computed.Checksums[1:] {
+			assert.Equal(t, computed.Checksums[0], checksum)
+		}
+	}
+	// the local parity pieces of LRC are hashed as well
+	lrc, err := redundancy.NewLRCStrategy(4, 2, 2)
+	assert.Nil(t, err)
+	computed, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, 4, 2, WithStrategy(lrc))
+	assert.Nil(t, err)
+	assert.Equal(t, 1+lrc.PieceCount(), len(computed.Checksums))
+	assert.Equal(t, ecResult.Checksums, computed.Checksums[:7])
+}
+
+func TestComputeIntegrityHashWithParams(t *testing.T) {
+	const segSize = 4096
+	content := TestVectorData(2*segSize + 7)
+	params, err := redundancy.NewRedundancyParams(segSize, 4, 2)
+	assert.Nil(t, err)
+	computed, err := ComputeIntegrityHashWithParams(bytes.NewReader(content), params)
+	assert.Nil(t, err)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, 4, 2, true)
+	assert.Nil(t, err)
+	assert.Nil(t, diffHashResult(expected, computed))
+
+	_, err = ComputeIntegrityHashWithParams(bytes.NewReader(content), redundancy.RedundancyParams{SegmentSize: segSize,
+		DataShards: 4})
+	assert.ErrorIs(t, err, redundancy.ErrInvalidRedundancyParams)
+}
@@ -0,0 +1,253 @@
+package hash
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/id"
+)
+
+var (
+	// ErrJobNotFound is returned when the JobManager has no job of the id
+	ErrJobNotFound = errors.New("hash job not found")
+	// ErrJobNotDone is returned when a job which is still pending or running is removed
+	ErrJobNotDone = errors.New("hash job is not done")
+)
+
+// JobStatus describes the state of a hash job
+type JobStatus int
+
+const (
+	// JobPending jobs wait for a free slot of the JobManager
+	JobPending JobStatus = iota
+	// JobRunning jobs are computing the integrity hash
+	JobRunning
+	// JobSucceeded jobs have a result
+	JobSucceeded
+	// JobFailed jobs have an error
+	JobFailed
+	// JobCanceled jobs were canceled by JobManager.Cancel or by the context of JobManager.Submit
+	JobCanceled
+)
+
+func (s JobStatus) String() string {
+	switch s {
+	case JobPending:
+		return "pending"
+	case JobRunning:
+		return "running"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// Done return true if the job will not change anymore
+func (s JobStatus) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+// JobInfo is a snapshot of a hash job
+type JobInfo struct {
+	// ID is "hash-" followed by an id.ID, the IDs of the jobs submitted later sort after
+	ID     string
+	Status JobStatus
+	// BytesRead is the number of bytes hashed so far
+	BytesRead int64
+	// TotalBytes is the object size given to JobManager.Submit, -1 if unknown
+	TotalBytes int64
+	Result     *HashResult
+	Err        error
+	SubmitTime time.Time
+	StartTime  time.Time
+	FinishTime time.Time
+}
+
+type hashJob struct {
+	info      JobInfo
+	bytesRead atomic.Int64
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// JobManager runs integrity hash computations in the background, so a server can start hashing an object and poll
+// the job instead of blocking a request until the object is hashed. Finished jobs are kept until they are removed.
+type JobManager struct {
+	mu    sync.Mutex
+	jobs  map[string]*hashJob
+	slots chan struct{}
+}
+
+// NewJobManager return a manager running up to maxConcurrent jobs at the same time, the other jobs are pending
+func NewJobManager(maxConcurrent int) *JobManager {
+	if maxConcurrent < 1 {
+		maxConcurrent = 1
+	}
+	return &JobManager{jobs: make(map[string]*hashJob), slots: make(chan struct{}, maxConcurrent)}
+}
+
+// Submit starts a job computing the integrity hash result of the reader like ComputeIntegrityHashWithOptions and
+// return its id. The reader must stay readable until the job is done; size is only used to report the progress,
+// pass -1 if it is unknown. The job is canceled when ctx is done.
+func (m *JobManager) Submit(ctx context.Context, reader io.Reader, size int64, segmentSize int64, dataShards,
+	parityShards int, opts ...Option,
+) string {
+	ctx, cancel := context.WithCancel(ctx)
+	job := &hashJob{
+		info: JobInfo{
+			ID:         "hash-" + id.New().String(),
+			Status:     JobPending,
+			TotalBytes: size,
+			SubmitTime: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.jobs[job.info.ID] = job
+	m.mu.Unlock()
+
+	go m.run(ctx, job, &progressReader{ctx: ctx, reader: reader, bytesRead: &job.bytesRead}, segmentSize,
+		dataShards, parityShards, opts)
+	return job.info.ID
+}
+
+func (m *JobManager) run(ctx context.Context, job *hashJob, reader io.Reader, segmentSize int64, dataShards,
+	parityShards int, opts []Option,
+) {
+	defer close(job.done)
+	defer job.cancel()
+
+	select {
+	case m.slots <- struct{}{}:
+		defer func() { <-m.slots }()
+	case <-ctx.Done():
+		m.finish(job, nil, ctx.Err())
+		return
+	}
+	m.mu.Lock()
+	job.info.Status = JobRunning
+	job.info.StartTime = time.Now()
+	m.mu.Unlock()
+
+	result, err := ComputeIntegrityHashWithOptions(reader, segmentSize, dataShards, parityShards, opts...)
+	if ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	m.finish(job, result, err)
+}
+
+func (m *JobManager) finish(job *hashJob, result *HashResult, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job.info.FinishTime = time.Now()
+	job.info.Result = result
+	job.info.Err = err
+	switch {
+	case err == nil:
+		job.info.Status = JobSucceeded
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		job.info.Status = JobCanceled
+	default:
+		job.info.Status = JobFailed
+	}
+}
+
+// Status return a snapshot of the job
+func (m *JobManager) Status(id string) (JobInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return JobInfo{}, ErrJobNotFound
+	}
+	info := job.info
+	info.BytesRead = job.bytesRead.Load()
+	return info, nil
+}
+
+// List return the snapshots of all the jobs which are not removed
+func (m *JobManager) List() []JobInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]JobInfo, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		info := job.info
+		info.BytesRead = job.bytesRead.Load()
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// Cancel stops the job, it has no effect on a job which is done
+func (m *JobManager) Cancel(id string) error {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return ErrJobNotFound
+	}
+	job.cancel()
+	return nil
+}
+
+// Wait blocks until the job is done or ctx is done and return the result of the job
+func (m *JobManager) Wait(ctx context.Context, id string) (*HashResult, error) {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	info, err := m.Status(id)
+	if err != nil {
+		return nil, err
+	}
+	return info.Result, info.Err
+}
+
+// Remove forgets a job which is done
+func (m *JobManager) Remove(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if !job.info.Status.Done() {
+		return ErrJobNotDone
+	}
+	delete(m.jobs, id)
+	return nil
+}
+
+// progressReader counts the bytes read and fails once ctx is done, which stops the computation reading it.
+// A read blocked in the underlying reader is not interrupted.
+type progressReader struct {
+	ctx       context.Context
+	reader    io.Reader
+	bytesRead *atomic.Int64
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	r.bytesRead.Add(int64(n))
+	return n, err
+}
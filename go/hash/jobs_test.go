@@ -0,0 +1,71 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestJobManager(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(10*segSize + 1)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, true)
+	assert.Nil(t, err)
+
+	manager := NewJobManager(1)
+	id := manager.Submit(context.Background(), bytes.NewReader(content), int64(len(content)), segSize,
+		redundancy.DataBlocks, redundancy.ParityBlocks)
+	result, err := manager.Wait(context.Background(), id)
+	assert.Nil(t, err)
+	assert.Nil(t, diffHashResult(expected, result))
+	info, err := manager.Status(id)
+	assert.Nil(t, err)
+	assert.Equal(t, JobSucceeded, info.Status)
+	assert.Equal(t, int64(len(content)), info.BytesRead)
+	assert.Equal(t, int64(len(content)), info.TotalBytes)
+
+	// the second job is pending while the first one blocks the only slot of the manager
+	pipeReader, pipeWriter := io.Pipe()
+	blocked := manager.Submit(context.Background(), pipeReader, -1, segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	_, err = pipeWriter.Write(content[:100])
+	assert.Nil(t, err)
+	pending := manager.Submit(context.Background(), bytes.NewReader(content), -1, segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks)
+	assert.Eventually(t, func() bool {
+		info, err = manager.Status(blocked)
+		return err == nil && info.Status == JobRunning && info.BytesRead == 100
+	}, time.Second, time.Millisecond)
+	info, err = manager.Status(pending)
+	assert.Nil(t, err)
+	assert.Equal(t, JobPending, info.Status)
+	assert.ErrorIs(t, manager.Remove(blocked), ErrJobNotDone)
+	assert.Len(t, manager.List(), 3)
+
+	assert.Nil(t, manager.Cancel(blocked))
+	// the canceled job notices the cancellation once its pending read returns
+	assert.Nil(t, pipeWriter.Close())
+	_, err = manager.Wait(context.Background(), blocked)
+	assert.ErrorIs(t, err, context.Canceled)
+	info, err = manager.Status(blocked)
+	assert.Nil(t, err)
+	assert.Equal(t, JobCanceled, info.Status)
+
+	result, err = manager.Wait(context.Background(), pending)
+	assert.Nil(t, err)
+	assert.Nil(t, diffHashResult(expected, result))
+
+	assert.Nil(t, manager.Remove(blocked))
+	_, err = manager.Status(blocked)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	assert.ErrorIs(t, manager.Cancel("unknown"), ErrJobNotFound)
+	_, err = manager.Wait(context.Background(), "unknown")
+	assert.ErrorIs(t, err, ErrJobNotFound)
+}
@@ -0,0 +1,102 @@
+package hash
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryLimiter bounds the memory of the segments held by the hash computations sharing it, e.g. a limiter shared
+// by all the requests of a SP process keeps their computations together below the budget.
+type MemoryLimiter struct {
+	mu     sync.Mutex
+	budget int64
+	used   int64
+	// released is closed and replaced on every release to wake up the waiting Acquire calls
+	released chan struct{}
+}
+
+// NewMemoryLimiter return a limiter of budget bytes
+func NewMemoryLimiter(budget int64) *MemoryLimiter {
+	return &MemoryLimiter{budget: budget, released: make(chan struct{})}
+}
+
+// Acquire reserves n bytes, waiting for other holders to release memory until ctx is done.
+// It returns ErrMemoryBudgetExceeded at once if n is larger than the whole budget.
+func (l *MemoryLimiter) Acquire(ctx context.Context, n int64) error {
+	for {
+		l.mu.Lock()
+		if n > l.budget {
+			l.mu.Unlock()
+			return fmt.Errorf("%w: %d bytes requested, budget %d bytes", ErrMemoryBudgetExceeded, n, l.budget)
+		}
+		if l.used+n <= l.budget {
+			l.used += n
+			l.mu.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TryAcquire reserves n bytes if they are available and returns ErrMemoryBudgetExceeded otherwise
+func (l *MemoryLimiter) TryAcquire(n int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.used+n > l.budget {
+		return fmt.Errorf("%w: %d bytes requested, %d of %d bytes in use", ErrMemoryBudgetExceeded, n, l.used,
+			l.budget)
+	}
+	l.used += n
+	return nil
+}
+
+// Release returns n bytes reserved by Acquire or TryAcquire
+func (l *MemoryLimiter) Release(n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= n
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// Used return the number of reserved bytes
+func (l *MemoryLimiter) Used() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+// segmentMemory return the memory held while one segment is hashed: the segment and its erasure encoded pieces
+func segmentMemory(segmentSize int64, dataShards, parityShards int) int64 {
+	if dataShards <= 0 {
+		return segmentSize
+	}
+	shardSize := (segmentSize + int64(dataShards) - 1) / int64(dataShards)
+	return segmentSize + shardSize*int64(dataShards+parityShards)
+}
+
+// acquireMemory reserves n bytes of the memory limiter of the options if any
+func (o *hashOptions) acquireMemory(ctx context.Context, n int64) error {
+	if o.memoryLimiter == nil {
+		return nil
+	}
+	if o.waitMemory {
+		return o.memoryLimiter.Acquire(ctx, n)
+	}
+	return o.memoryLimiter.TryAcquire(n)
+}
+
+// releaseMemory returns n bytes to the memory limiter of the options if any
+func (o *hashOptions) releaseMemory(n int64) {
+	if o.memoryLimiter != nil {
+		o.memoryLimiter.Release(n)
+	}
+}
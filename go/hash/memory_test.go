@@ -0,0 +1,84 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestMemoryLimiter(t *testing.T) {
+	limiter := NewMemoryLimiter(100)
+	assert.Nil(t, limiter.Acquire(context.Background(), 60))
+	assert.ErrorIs(t, limiter.TryAcquire(50), ErrMemoryBudgetExceeded)
+	assert.ErrorIs(t, limiter.Acquire(context.Background(), 101), ErrMemoryBudgetExceeded)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, limiter.Acquire(ctx, 50), context.DeadlineExceeded)
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- limiter.Acquire(context.Background(), 50)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("the memory is acquired before it is released")
+	case <-time.After(10 * time.Millisecond):
+	}
+	limiter.Release(60)
+	assert.Nil(t, <-acquired)
+	assert.Equal(t, int64(50), limiter.Used())
+}
+
+func TestHashWithMemoryLimiter(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(20*segSize + 1)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, true)
+	assert.Nil(t, err)
+	memory := segmentMemory(segSize, redundancy.DataBlocks, redundancy.ParityBlocks)
+
+	// the computations sharing the limiter wait for each other
+	limiter := NewMemoryLimiter(2 * memory)
+	var wg sync.WaitGroup
+	for _, opts := range [][]Option{
+		{WithMode(ModeSerial)},
+		{WithMode(ModeSerial), WithPrefetch(3)},
+		{WithMode(ModeParallel)},
+		{WithMode(ModeParallel)},
+	} {
+		wg.Add(1)
+		go func(opts []Option) {
+			defer wg.Done()
+			computed, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize,
+				redundancy.DataBlocks, redundancy.ParityBlocks, append(opts, WithMemoryLimiter(limiter, true))...)
+			assert.Nil(t, err)
+			assert.Nil(t, diffHashResult(expected, computed))
+		}(opts)
+	}
+	wg.Wait()
+	assert.Equal(t, int64(0), limiter.Used())
+
+	// a budget below a single segment can never be satisfied
+	limiter = NewMemoryLimiter(memory - 1)
+	for _, mode := range []Mode{ModeSerial, ModeParallel} {
+		_, err = ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, WithMode(mode), WithMemoryLimiter(limiter, true))
+		assert.ErrorIs(t, err, ErrMemoryBudgetExceeded)
+	}
+
+	// the computation fails instead of waiting for memory held by another one
+	limiter = NewMemoryLimiter(memory)
+	assert.Nil(t, limiter.TryAcquire(1))
+	_, err = ComputeIntegrityHashWithOptions(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, WithMode(ModeSerial), WithMemoryLimiter(limiter, false))
+	assert.ErrorIs(t, err, ErrMemoryBudgetExceeded)
+	limiter.Release(1)
+	assert.Equal(t, int64(0), limiter.Used())
+}
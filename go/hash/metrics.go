@@ -0,0 +1,26 @@
+package hash
+
+import "time"
+
+// MetricsCollector receives the measurements of the integrity hash computation, the methods may be called
+// concurrently by the hash workers. See the metrics package for a Prometheus implementation.
+type MetricsCollector interface {
+	// ObserveSegment is called after a segment of size bytes has been hashed and erasure encoded
+	ObserveSegment(size int, latency time.Duration)
+	// ObserveEncode is called after a segment has been erasure encoded
+	ObserveEncode(latency time.Duration)
+	// ObserveQueueDepth is called with the number of segments waiting for a hash worker
+	ObserveQueueDepth(depth int)
+	// ObserveActiveWorkers is called with the number of hash workers busy with a segment
+	ObserveActiveWorkers(active int)
+}
+
+type nopMetricsCollector struct{}
+
+func (nopMetricsCollector) ObserveSegment(int, time.Duration) {}
+
+func (nopMetricsCollector) ObserveEncode(time.Duration) {}
+
+func (nopMetricsCollector) ObserveQueueDepth(int) {}
+
+func (nopMetricsCollector) ObserveActiveWorkers(int) {}
@@ -0,0 +1,262 @@
+package hash
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// Mode selects the version used to compute the integrity hash
+type Mode int
+
+const (
+	// ModeAuto computes the integrity hash of objects smaller than the auto threshold using the serial version and
+	// of the other objects using the parallel version
+	ModeAuto Mode = iota
+	// ModeSerial always computes the integrity hash using the serial version
+	ModeSerial
+	// ModeParallel always computes the integrity hash using the parallel version
+	ModeParallel
+)
+
+// DefaultAutoThresholdSegments is the auto threshold in segments if WithAutoThreshold is not provided, the parallel
+// version is not worth its workers for objects of a few segments
+const DefaultAutoThresholdSegments = 4
+
+const (
+	// DefaultMaxRetries is the number of times a failed read of a remote object is retried
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the delay before the first retry, it doubles on every retry
+	DefaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Option configures how the integrity hash is computed by ComputeIntegrityHashWithOptions
+type Option func(*hashOptions)
+
+// logger is the logger of the hash module
+var logger = log.Module("hash")
+
+type hashOptions struct {
+	mode          Mode
+	autoThreshold int64
+	minObjectSize int64
+	metrics       MetricsCollector
+	logger        log.Logger
+	httpClient    *http.Client
+	maxRetries    int
+	retryBackoff  time.Duration
+	concurrency   int
+	prefetchDepth int
+	// shardConcurrency is the number of ec pieces of a segment hashed at the same time
+	shardConcurrency int
+	memoryLimiter    *MemoryLimiter
+	waitMemory       bool
+	workers          int
+	// sink stores the segments read by ComputeIntegrityHashTee into layout
+	sink   SegmentSink
+	layout *StoredLayout
+	// strategy splits the segments into pieces, the ec params are used if nil
+	strategy redundancy.RedundancyStrategy
+	// traceCtx parents the spans of the computation
+	traceCtx context.Context
+	// greenfieldCompat verifies the result against the greenfield-common algorithm
+	greenfieldCompat bool
+	// fastChecksums collects the fast checksums of the segments and of the pieces if WithFastChecksums is provided
+	fastChecksums *fastChecksumCollector
+}
+
+func newHashOptions(opts []Option) *hashOptions {
+	options := &hashOptions{
+		metrics:          nopMetricsCollector{},
+		logger:           logger,
+		httpClient:       http.DefaultClient,
+		maxRetries:       DefaultMaxRetries,
+		retryBackoff:     DefaultRetryBackoff,
+		concurrency:      maxThreadNum,
+		shardConcurrency: 1,
+		traceCtx:         context.Background(),
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithMode computes the integrity hash using the version selected by mode, ModeAuto is used by default
+func WithMode(mode Mode) Option {
+	return func(o *hashOptions) {
+		o.mode = mode
+	}
+}
+
+// WithSerial computes the integrity hash using the serial version if isSerial is true and using the parallel version
+// otherwise, it is a shortcut of WithMode
+func WithSerial(isSerial bool) Option {
+	if isSerial {
+		return WithMode(ModeSerial)
+	}
+	return WithMode(ModeParallel)
+}
+
+// WithAutoThreshold sets the object size from which ModeAuto uses the parallel version,
+// DefaultAutoThresholdSegments segments are used by default
+func WithAutoThreshold(size int64) Option {
+	return func(o *hashOptions) {
+		o.autoThreshold = size
+	}
+}
+
+// WithMinObjectSize rejects objects smaller than size, ErrEmptyObject is returned for an empty object and
+// ErrObjectTooSmall for any other object below the minimum. By default every object size is accepted.
+func WithMinObjectSize(size int64) Option {
+	return func(o *hashOptions) {
+		o.minObjectSize = size
+	}
+}
+
+// WithMetrics reports the hashing progress to the collector
+func WithMetrics(collector MetricsCollector) Option {
+	return func(o *hashOptions) {
+		if collector != nil {
+			o.metrics = collector
+		}
+	}
+}
+
+// WithTraceContext opens the spans of the computation as children of the span of ctx, e.g. of the upload of the
+// object, see the tracing package. ComputeIntegrityHashFromSource uses its ctx instead.
+func WithTraceContext(ctx context.Context) Option {
+	return func(o *hashOptions) {
+		if ctx != nil {
+			o.traceCtx = ctx
+		}
+	}
+}
+
+// WithLogger logs the errors of the computation to l instead of the logger of the hash module, see log.Module
+func WithLogger(l log.Logger) Option {
+	return func(o *hashOptions) {
+		if l != nil {
+			o.logger = l
+		}
+	}
+}
+
+// WithHTTPClient fetches remote objects using client instead of http.DefaultClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *hashOptions) {
+		if client != nil {
+			o.httpClient = client
+		}
+	}
+}
+
+// WithRetry retries a failed read of a remote object up to maxRetries times, waiting backoff before the first retry
+// and doubling it on every retry. Requests rejected by the server, e.g. with 404, are not retried.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(o *hashOptions) {
+		o.maxRetries = maxRetries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithConcurrency sets the number of segments of a remote object fetched and hashed at the same time
+func WithConcurrency(n int) Option {
+	return func(o *hashOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithPrefetch reads up to depth segments ahead of the serial version while the previous segments are encoded and
+// hashed, which hides the latency of slow disks. The parallel version always reads ahead of its workers.
+func WithPrefetch(depth int) Option {
+	return func(o *hashOptions) {
+		o.prefetchDepth = depth
+	}
+}
+
+// WithShardConcurrency hashes up to n ec pieces of a segment at the same time instead of one after another, which
+// reduces the latency of large segments encoded to many pieces. The hashes keep the order of the pieces.
+func WithShardConcurrency(n int) Option {
+	return func(o *hashOptions) {
+		if n > 0 {
+			o.shardConcurrency = n
+		}
+	}
+}
+
+// WithMemoryLimiter reserves the memory of every segment from limiter before reading it and releases it once the
+// segment is hashed. If wait is true the computation waits for the memory released by the other computations
+// sharing the limiter, otherwise it fails with ErrMemoryBudgetExceeded as soon as the budget is used up.
+func WithMemoryLimiter(limiter *MemoryLimiter, wait bool) Option {
+	return func(o *hashOptions) {
+		o.memoryLimiter = limiter
+		o.waitMemory = wait
+	}
+}
+
+// WithWorkers computes the integrity hash using n workers in the parallel version instead of the default set by
+// SetTuneResult or derived from the number of CPUs
+func WithWorkers(n int) Option {
+	return func(o *hashOptions) {
+		o.workers = n
+	}
+}
+
+// workerNum return the number of workers of the parallel version
+func (o *hashOptions) workerNum() int {
+	if o.workers > 0 {
+		return o.workers
+	}
+	if tuned := tunedWorkers.Load(); tuned > 0 {
+		return int(tuned)
+	}
+	// the thread num should be less than maxThreadNum and at least one, otherwise no worker consumes the jobs
+	threadNum := runtime.NumCPU() / 2
+	if threadNum > maxThreadNum {
+		threadNum = maxThreadNum
+	}
+	if threadNum < 1 {
+		threadNum = 1
+	}
+	return threadNum
+}
+
+// WithStrategy computes the piece hashes using the pieces of strategy instead of the Reed-Solomon shards, the ec
+// params are then ignored
+func WithStrategy(strategy redundancy.RedundancyStrategy) Option {
+	return func(o *hashOptions) {
+		o.strategy = strategy
+	}
+}
+
+// WithGreenfieldCompat verifies that the integrity hashes are the ones computed by greenfield-common, the result
+// is then computed twice, see CompatCheck. ComputeIntegrityHashWithOptions returns ErrGreenfieldDivergence if they
+// differ.
+func WithGreenfieldCompat() Option {
+	return func(o *hashOptions) {
+		o.greenfieldCompat = true
+	}
+}
+
+// WithFastChecksums computes the CRC32C of every segment and of every piece alongside their sha256 checksums, they
+// are returned in the FastChecksums of the result, see FastChecksum
+func WithFastChecksums() Option {
+	return func(o *hashOptions) {
+		o.fastChecksums = &fastChecksumCollector{}
+	}
+}
+
+// redundancy return the strategy splitting the segments into pieces
+func (o *hashOptions) redundancy(dataShards, parityShards int) redundancy.RedundancyStrategy {
+	if o.strategy != nil {
+		return o.strategy
+	}
+	return redundancy.NewReedSolomonStrategy(dataShards, parityShards)
+}
@@ -0,0 +1,79 @@
+package hash
+
+import (
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// HashVersion identifies the algorithms and the layout used to compute the integrity hashes of a HashResult,
+// the integrity hashes themselves carry no version
+type HashVersion uint8
+
+const (
+	// HashVersionLegacy is the version of the results serialized before the version was introduced, they were
+	// computed as HashVersionV1
+	HashVersionLegacy HashVersion = 0
+	// HashVersionV1 computes the checksums with sha256, the integrity hashes as the sha256 of the concatenated
+	// checksums and the pieces with the Reed-Solomon erasure coding
+	HashVersionV1 HashVersion = 1
+	// CurrentHashVersion is the version of the results computed by this package
+	CurrentHashVersion = HashVersionV1
+)
+
+// String return the descriptor of the algorithms of the version
+func (v HashVersion) String() string {
+	switch v {
+	case HashVersionLegacy, HashVersionV1:
+		return "sha256-concat-reedsolomon"
+	default:
+		return fmt.Sprintf("unknown hash version %d", uint8(v))
+	}
+}
+
+// HashResult describes the integrity hashes of an object
+type HashResult struct {
+	// Version identifies how the checksums were computed, results of an unsupported version can not be verified
+	Version HashVersion `json:"version"`
+	// Checksums contains the integrity hash of the PrimarySP followed by the integrity hashes of the SecondarySPs
+	Checksums      [][]byte                  `json:"checksums"`
+	ContentLength  int64                     `json:"content_length"`
+	RedundancyType redundancy.RedundancyType `json:"redundancy_type"`
+	// FastChecksums are the fast checksums of the segments and of the pieces, they are computed only with
+	// WithFastChecksums
+	FastChecksums *FastChecksums `json:"fast_checksums,omitempty"`
+}
+
+// NewHashResult wraps the values returned by the ComputeIntegrityHash family into a HashResult
+func NewHashResult(checksums [][]byte, contentLength int64, redundancyType redundancy.RedundancyType) *HashResult {
+	return &HashResult{
+		Version:        CurrentHashVersion,
+		Checksums:      checksums,
+		ContentLength:  contentLength,
+		RedundancyType: redundancyType,
+	}
+}
+
+// PrimaryChecksum return the integrity hash of the PrimarySP
+func (r *HashResult) PrimaryChecksum() []byte {
+	if len(r.Checksums) == 0 {
+		return nil
+	}
+	return r.Checksums[0]
+}
+
+// SecondaryChecksums return the integrity hashes of the SecondarySPs ordered by ec index
+func (r *HashResult) SecondaryChecksums() [][]byte {
+	if len(r.Checksums) == 0 {
+		return nil
+	}
+	return r.Checksums[1:]
+}
+
+// CheckVersion return ErrUnsupportedHashVersion if the result was computed by a version unknown to this package
+func (r *HashResult) CheckVersion() error {
+	if r.Version > CurrentHashVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedHashVersion, r.Version)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package hash
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/retry"
+	"github.com/zkMeLabs/mechain-common/go/tracing"
+)
+
+// ObjectSource provides random access to an object which is not on the local disk, e.g. on an HTTP server
+type ObjectSource interface {
+	// Size return the size of the object
+	Size(ctx context.Context) (int64, error)
+	// ReadRange return a reader of the length bytes of the object starting at offset
+	ReadRange(ctx context.Context, offset, length int64) (io.ReadCloser, error)
+}
+
+// ComputeIntegrityHashFromSource return the integrity hash result of the object of source.
+// The segments are fetched by ranged reads and hashed by WithConcurrency workers, a failed read is retried as
+// configured by WithRetry. The computation stops as soon as ctx is done.
+func ComputeIntegrityHashFromSource(ctx context.Context, source ObjectSource, segmentSize int64, dataShards,
+	parityShards int, opts ...Option,
+) (*HashResult, error) {
+	return computeIntegrityHashFromSource(ctx, source, segmentSize, dataShards, parityShards, newHashOptions(opts))
+}
+
+func computeIntegrityHashFromSource(ctx context.Context, source ObjectSource, segmentSize int64, dataShards,
+	parityShards int, options *hashOptions,
+) (_ *HashResult, err error) {
+	ctx, span := tracing.Start(ctx, "hash.ComputeIntegrityHashFromSource", tracing.Int64("segment_size", segmentSize),
+		tracing.Int("data_shards", dataShards), tracing.Int("parity_shards", parityShards))
+	defer func() {
+		tracing.End(span, err)
+	}()
+	var size int64
+	err = retryRead(ctx, options, func() error {
+		var err error
+		size, err = source.Size(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, readerError(err)
+	}
+	if err = checkObjectSize(size, options); err != nil {
+		return nil, err
+	}
+
+	segCount := int((size + segmentSize - 1) / segmentSize)
+	segChecksumList := make([][]byte, segCount)
+	pieceChecksumLists := make([][][]byte, segCount)
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	jobs := make(chan int)
+	for i := 0; i < options.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for segIndex := range jobs {
+				offset := int64(segIndex) * segmentSize
+				length := min(segmentSize, size-offset)
+				memory := segmentMemory(length, dataShards, parityShards)
+				err := options.acquireMemory(workerCtx, memory)
+				var checksum []byte
+				var pieceChecksums [][]byte
+				if err == nil {
+					checksum, pieceChecksums, err = hashSourceSegment(workerCtx, source, segIndex, offset, length,
+						dataShards, parityShards, options)
+					options.releaseMemory(memory)
+				}
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				segChecksumList[segIndex] = checksum
+				pieceChecksumLists[segIndex] = pieceChecksums
+			}
+		}()
+	}
+sendJobs:
+	for segIndex := 0; segIndex < segCount; segIndex++ {
+		select {
+		case jobs <- segIndex:
+		case <-workerCtx.Done():
+			break sendJobs
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	strategy := options.redundancy(dataShards, parityShards)
+	ecShards := strategy.PieceCount()
+	hashList := make([][]byte, ecShards+1)
+	hashList[0] = GenerateIntegrityHash(segChecksumList)
+	for index := 0; index < ecShards; index++ {
+		pieceChecksums := make([][]byte, segCount)
+		for segIndex := range pieceChecksumLists {
+			pieceChecksums[segIndex] = pieceChecksumLists[segIndex][index]
+		}
+		hashList[index+1] = GenerateIntegrityHash(pieceChecksums)
+	}
+	result := NewHashResult(hashList, size, strategy.RedundancyType())
+	result.FastChecksums = options.fastChecksums.result(ecShards)
+	return result, nil
+}
+
+// hashSourceSegment fetches one segment of the source and return its checksum and the checksums of its ec pieces
+func hashSourceSegment(ctx context.Context, source ObjectSource, segIndex int, offset, length int64, dataShards,
+	parityShards int, options *hashOptions,
+) (_ []byte, _ [][]byte, err error) {
+	start := time.Now()
+	ctx, span := startSegmentSpan(ctx, segIndex, int(length))
+	defer func() {
+		tracing.End(span, err)
+	}()
+	data := make([]byte, length)
+	err = retryRead(ctx, options, func() error {
+		rc, err := source.ReadRange(ctx, offset, length)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		_, err = io.ReadFull(rc, data)
+		return err
+	})
+	if err != nil {
+		return nil, nil, &SegmentError{Segment: segIndex, Kind: ErrReaderFailed, Err: err}
+	}
+
+	checksum := GenerateChecksum(data)
+	pieceChecksums, err := computePieceHashes(ctx, segIndex, data, dataShards, parityShards, options)
+	if err != nil {
+		return nil, nil, &SegmentError{Segment: segIndex, Kind: ErrEncodeFailed, Err: err}
+	}
+	options.metrics.ObserveSegment(len(data), time.Since(start))
+	return checksum, pieceChecksums, nil
+}
+
+// retryRead calls fn until it succeeds, fails with an error which is not temporary or the retries of options are
+// used up
+func retryRead(ctx context.Context, options *hashOptions, fn func() error) error {
+	return retry.Do(ctx, func(context.Context) error {
+		return fn()
+	}, retry.WithMaxRetries(options.maxRetries), retry.WithBackoff(options.retryBackoff, 0, 2), retry.WithJitter(0),
+		retry.WithOnRetry(func(_ int, err error, delay time.Duration) {
+			options.logger.Warnf("failed to read remote object, retry in %s: %s", delay, err)
+		}))
+}
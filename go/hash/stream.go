@@ -0,0 +1,79 @@
+package hash
+
+import (
+	"context"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+	"github.com/zkMeLabs/mechain-common/go/segment"
+)
+
+// SegmentResult describes the checksum of one segment and the erasure encoded pieces of it
+type SegmentResult struct {
+	SegmentIndex int
+	// Checksum is the checksum of the segment which is stored by the PrimarySP
+	Checksum []byte
+	// Pieces are the erasure encoded pieces of the segment ordered by ec index
+	Pieces [][]byte
+	// PieceChecksums are the checksums of Pieces ordered by ec index
+	PieceChecksums [][]byte
+}
+
+// ComputeIntegrityHashStream split the reader into segments and sends the SegmentResult of every segment to results
+// in segment order as soon as it is computed, so the caller can upload the pieces of a segment while the rest of the
+// object is still being hashed. The results channel is closed when the func returns.
+// It returns the integrity hash result of the whole object once all the segments have been sent.
+func ComputeIntegrityHashStream(ctx context.Context, reader io.Reader, segmentSize int64, dataShards,
+	parityShards int, results chan<- SegmentResult,
+) (*HashResult, error) {
+	defer close(results)
+
+	ecShards := dataShards + parityShards
+	hasher := NewHasher(segmentSize, dataShards, parityShards)
+	hasher.Init()
+	// the pieces are sent to the caller, so the segments are never released to the pool of the splitter
+	splitter := segment.SplitBySegment(reader, segmentSize)
+	for {
+		// stop before reading and encoding another segment once the context is canceled
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		segIndex, data, _, err := splitter.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, readerError(err)
+		}
+
+		checksum := GenerateChecksum(data)
+		pieces, err := redundancy.EncodeRawSegment(data, dataShards, parityShards)
+		if err != nil {
+			return nil, &SegmentError{Segment: segIndex, Kind: ErrEncodeFailed, Err: err}
+		}
+		pieceChecksums := make([][]byte, ecShards)
+		for index, piece := range pieces {
+			pieceChecksums[index] = GenerateChecksum(piece)
+			hasher.ecDataHashes[index] = append(hasher.ecDataHashes[index], pieceChecksums[index])
+		}
+		hasher.segHashes = append(hasher.segHashes, checksum)
+		hasher.contentLen += int64(len(data))
+
+		select {
+		case results <- SegmentResult{
+			SegmentIndex:   segIndex,
+			Checksum:       checksum,
+			Pieces:         pieces,
+			PieceChecksums: pieceChecksums,
+		}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	checksums, contentLen, redundancyType, err := hasher.Finish()
+	if err != nil {
+		return nil, err
+	}
+	return NewHashResult(checksums, contentLen, redundancyType), nil
+}
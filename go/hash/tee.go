@@ -0,0 +1,93 @@
+package hash
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// SegmentSink stores the segments of an object while ComputeIntegrityHashTee hashes it, so the raw content of a
+// stream which can be read only once is kept without a second pass. The segments are written in order.
+type SegmentSink interface {
+	// WriteSegment stores the data of the segment starting at offset of the object, data must not be retained
+	WriteSegment(index int, offset int64, data []byte) (StoredSegment, error)
+}
+
+// StoredSegment describes where a SegmentSink stored a segment
+type StoredSegment struct {
+	Index  int
+	Offset int64
+	Size   int64
+	// Path is the file of the segment if the sink stores every segment in its own file
+	Path string
+}
+
+// StoredLayout describes all the stored segments of an object ordered by segment index
+type StoredLayout struct {
+	Segments []StoredSegment
+}
+
+// Remove deletes the files of the stored segments, e.g. once they are uploaded or if the hashing failed
+func (l *StoredLayout) Remove() error {
+	var errs []error
+	for _, segment := range l.Segments {
+		if segment.Path == "" {
+			continue
+		}
+		if err := os.Remove(segment.Path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type writerAtSink struct {
+	writer io.WriterAt
+}
+
+// TeeToWriter return a sink writing every segment at its offset of writer, e.g. a file preallocated for the object
+func TeeToWriter(writer io.WriterAt) SegmentSink {
+	return &writerAtSink{writer: writer}
+}
+
+func (s *writerAtSink) WriteSegment(index int, offset int64, data []byte) (StoredSegment, error) {
+	if _, err := s.writer.WriteAt(data, offset); err != nil {
+		return StoredSegment{}, err
+	}
+	return StoredSegment{Index: index, Offset: offset, Size: int64(len(data))}, nil
+}
+
+// ComputeIntegrityHashTee return the integrity hash result of the reader content like
+// ComputeIntegrityHashWithOptions and stores every segment with sink while it is hashed.
+// The layout of the stored segments is returned even if the hashing fails, so the caller can clean them up.
+func ComputeIntegrityHashTee(reader io.Reader, segmentSize int64, dataShards, parityShards int, sink SegmentSink,
+	opts ...Option,
+) (*HashResult, *StoredLayout, error) {
+	options := newHashOptions(opts)
+	options.sink = sink
+	options.layout = &StoredLayout{}
+	checksums, contentLen, redundancyType, err := computeIntegrityHash(reader, segmentSize, dataShards, parityShards,
+		options)
+	if err != nil {
+		return nil, options.layout, err
+	}
+	if err = checkObjectSize(contentLen, options); err != nil {
+		return nil, options.layout, err
+	}
+	result := NewHashResult(checksums, contentLen, redundancyType)
+	result.FastChecksums = options.fastChecksums.result(len(checksums) - 1)
+	return result, options.layout, nil
+}
+
+// storeSegment stores the segment with the sink of the options if any
+func (o *hashOptions) storeSegment(index int, offset int64, data []byte) error {
+	if o.sink == nil {
+		return nil
+	}
+	stored, err := o.sink.WriteSegment(index, offset, data)
+	if err != nil {
+		return &SegmentError{Segment: index, Kind: ErrStoreFailed, Err: err}
+	}
+	o.layout.Segments = append(o.layout.Segments, stored)
+	return nil
+}
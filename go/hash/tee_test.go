@@ -0,0 +1,66 @@
+package hash
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+type failingSink struct{}
+
+func (failingSink) WriteSegment(int, int64, []byte) (StoredSegment, error) {
+	return StoredSegment{}, errors.New("disk full")
+}
+
+func TestComputeIntegrityHashTee(t *testing.T) {
+	const segSize = 1024
+	content := TestVectorData(5*segSize + 7)
+	expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+		redundancy.ParityBlocks, true)
+	assert.Nil(t, err)
+
+	for _, mode := range []Mode{ModeSerial, ModeParallel} {
+		// the object is restored from the writer
+		f, err := os.Create(filepath.Join(t.TempDir(), "object"))
+		assert.Nil(t, err)
+		result, layout, err := ComputeIntegrityHashTee(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, TeeToWriter(f), WithMode(mode))
+		assert.Nil(t, err)
+		assert.Nil(t, diffHashResult(expected, result))
+		assert.Len(t, layout.Segments, 6)
+		assert.Equal(t, StoredSegment{Index: 5, Offset: 5 * segSize, Size: 7}, layout.Segments[5])
+		assert.Nil(t, f.Close())
+		stored, err := os.ReadFile(f.Name())
+		assert.Nil(t, err)
+		assert.Equal(t, content, stored)
+
+		// the object is restored from the segment files
+		dir := t.TempDir()
+		result, layout, err = ComputeIntegrityHashTee(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, TeeToFiles(dir), WithMode(mode))
+		assert.Nil(t, err)
+		assert.Nil(t, diffHashResult(expected, result))
+		var restored []byte
+		for index, segment := range layout.Segments {
+			assert.Equal(t, index, segment.Index)
+			data, err := os.ReadFile(segment.Path)
+			assert.Nil(t, err)
+			restored = append(restored, data...)
+		}
+		assert.Equal(t, content, restored)
+		assert.Nil(t, layout.Remove())
+		files, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Empty(t, files)
+
+		_, _, err = ComputeIntegrityHashTee(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, failingSink{}, WithMode(mode))
+		assert.ErrorIs(t, err, ErrStoreFailed)
+	}
+}
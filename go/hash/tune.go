@@ -0,0 +1,106 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// DefaultTuneSegmentSize is the segment size measured by Tune if TuneConfig.SegmentSize is not set
+const DefaultTuneSegmentSize = 16 * 1024 * 1024
+
+// tunedWorkers is the default worker number of the parallel version set by SetTuneResult, 0 if unset
+var tunedWorkers atomic.Int64
+
+// TuneConfig describes the workload measured by Tune
+type TuneConfig struct {
+	// SegmentSize is a chain parameter, it is measured rather than tuned, DefaultTuneSegmentSize by default
+	SegmentSize int64
+	// DataShards and ParityShards default to redundancy.DataBlocks and redundancy.ParityBlocks
+	DataShards   int
+	ParityShards int
+	// SampleSize is the size of the object hashed by every measurement, 8 segments by default
+	SampleSize int64
+	// Workers are the candidate worker numbers, the powers of two up to twice the number of CPUs by default
+	Workers []int
+	// Rounds is the number of measurements of every candidate, the fastest is kept, 2 by default
+	Rounds int
+}
+
+// TuneResult is the outcome of Tune, it can be saved with SaveTuneResult and applied by the next start of the
+// service with LoadTuneResult and SetTuneResult
+type TuneResult struct {
+	SegmentSize int64 `json:"segment_size"`
+	Workers     int   `json:"workers"`
+	// Throughput is the throughput in bytes per second measured for every candidate worker number
+	Throughput map[int]float64 `json:"throughput"`
+}
+
+// Tune measures the throughput of the parallel version on the local machine for every candidate worker number and
+// return the fastest one. It returns ctx.Err() if ctx is done before all the measurements complete.
+func Tune(ctx context.Context, config TuneConfig) (*TuneResult, error) {
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = DefaultTuneSegmentSize
+	}
+	if config.DataShards <= 0 || config.ParityShards <= 0 {
+		config.DataShards, config.ParityShards = redundancy.DataBlocks, redundancy.ParityBlocks
+	}
+	if config.SampleSize <= 0 {
+		config.SampleSize = 8 * config.SegmentSize
+	}
+	if len(config.Workers) == 0 {
+		for n := 1; n <= 2*runtime.NumCPU(); n *= 2 {
+			config.Workers = append(config.Workers, n)
+		}
+	}
+	if config.Rounds <= 0 {
+		config.Rounds = 2
+	}
+
+	sample := TestVectorData(config.SampleSize)
+	result := &TuneResult{SegmentSize: config.SegmentSize, Throughput: make(map[int]float64, len(config.Workers))}
+	for _, workers := range config.Workers {
+		if workers <= 0 {
+			continue
+		}
+		options := newHashOptions([]Option{WithWorkers(workers)})
+		var fastest time.Duration
+		for round := 0; round < config.Rounds; round++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			start := time.Now()
+			if _, _, _, err := computeIntegrityHashParallel(bytes.NewReader(sample), config.SegmentSize,
+				config.DataShards, config.ParityShards, options); err != nil {
+				return nil, err
+			}
+			if elapsed := time.Since(start); round == 0 || elapsed < fastest {
+				fastest = elapsed
+			}
+		}
+		throughput := float64(config.SampleSize) / fastest.Seconds()
+		result.Throughput[workers] = throughput
+		if result.Workers == 0 || throughput > result.Throughput[result.Workers] {
+			result.Workers = workers
+		}
+	}
+	if result.Workers == 0 {
+		return nil, errors.New("no positive worker number to tune")
+	}
+	return result, nil
+}
+
+// SetTuneResult sets the worker number of result as the default of the parallel version, a nil result restores the
+// default derived from the number of CPUs
+func SetTuneResult(result *TuneResult) {
+	if result == nil {
+		tunedWorkers.Store(0)
+		return
+	}
+	tunedWorkers.Store(int64(result.Workers))
+}
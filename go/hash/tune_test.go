@@ -0,0 +1,52 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestTune(t *testing.T) {
+	result, err := Tune(context.Background(), TuneConfig{SegmentSize: 1024, SampleSize: 8 * 1024, Workers: []int{1, 2, 3}})
+	assert.Nil(t, err)
+	assert.Contains(t, []int{1, 2, 3}, result.Workers)
+	assert.Len(t, result.Throughput, 3)
+
+	filePath := filepath.Join(t.TempDir(), "tune.json")
+	assert.Nil(t, SaveTuneResult(result, filePath))
+	loaded, err := LoadTuneResult(filePath)
+	assert.Nil(t, err)
+	assert.Equal(t, result, loaded)
+
+	SetTuneResult(&TuneResult{Workers: 7})
+	assert.Equal(t, 7, newHashOptions(nil).workerNum())
+	assert.Equal(t, 2, newHashOptions([]Option{WithWorkers(2)}).workerNum())
+	SetTuneResult(nil)
+	assert.LessOrEqual(t, newHashOptions(nil).workerNum(), maxThreadNum)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Tune(ctx, TuneConfig{SegmentSize: 1024})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func BenchmarkComputeIntegrityHashWorkers(b *testing.B) {
+	content := TestVectorData(8 * segmentSize)
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("workers %d", workers), func(b *testing.B) {
+			b.SetBytes(int64(len(content)))
+			for i := 0; i < b.N; i++ {
+				if _, err := ComputeIntegrityHashWithOptions(bytes.NewReader(content), segmentSize,
+					redundancy.DataBlocks, redundancy.ParityBlocks, WithMode(ModeParallel), WithWorkers(workers)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package hash
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// httpSource reads the ranges of an object served by an HTTP server which supports range requests
+type httpSource struct {
+	client *http.Client
+	url    string
+	size   int64
+}
+
+// Size return the content length of the HEAD response
+func (s *httpSource) Size(_ context.Context) (int64, error) {
+	return s.size, nil
+}
+
+// ReadRange sends a ranged GET request and return the body of the partial content response
+func (s *httpSource) ReadRange(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, &StatusError{Method: req.Method, URL: s.url, StatusCode: resp.StatusCode}
+	}
+	return resp.Body, nil
+}
+
+// ComputeIntegrityHashFromURL return the integrity hash result of the object served at url over HTTP(S).
+// If the server supports range requests, the segments are fetched by ranged GETs and hashed in parallel as by
+// ComputeIntegrityHashFromSource, otherwise the response of a single GET is hashed as a stream.
+// Failed requests are retried as configured by WithRetry and the computation stops as soon as ctx is done.
+func ComputeIntegrityHashFromURL(ctx context.Context, url string, segmentSize int64, dataShards, parityShards int,
+	opts ...Option,
+) (*HashResult, error) {
+	options := newHashOptions(opts)
+
+	var resp *http.Response
+	err := retryRead(ctx, options, func() error {
+		var err error
+		resp, err = doRequest(ctx, options.httpClient, http.MethodHead, url)
+		return err
+	})
+	if err != nil {
+		return nil, readerError(err)
+	}
+	resp.Body.Close()
+	if strings.Contains(resp.Header.Get("Accept-Ranges"), "bytes") && resp.ContentLength >= 0 {
+		source := &httpSource{client: options.httpClient, url: url, size: resp.ContentLength}
+		return computeIntegrityHashFromSource(ctx, source, segmentSize, dataShards, parityShards, options)
+	}
+
+	err = retryRead(ctx, options, func() error {
+		var err error
+		resp, err = doRequest(ctx, options.httpClient, http.MethodGet, url)
+		return err
+	})
+	if err != nil {
+		return nil, readerError(err)
+	}
+	defer resp.Body.Close()
+	checksums, contentLen, redundancyType, err := computeIntegrityHash(resp.Body, segmentSize, dataShards,
+		parityShards, options)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkObjectSize(contentLen, options); err != nil {
+		return nil, err
+	}
+	result := NewHashResult(checksums, contentLen, redundancyType)
+	result.FastChecksums = options.fastChecksums.result(len(checksums) - 1)
+	return result, nil
+}
+
+// doRequest sends a request without body and return the response if its status code is 2xx
+func doRequest(ctx context.Context, client *http.Client, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, &StatusError{Method: method, URL: url, StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
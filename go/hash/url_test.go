@@ -0,0 +1,78 @@
+package hash
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestComputeIntegrityHashFromURL(t *testing.T) {
+	const segSize = 1024
+	for _, size := range []int64{0, 1, segSize, 5*segSize + 3} {
+		content := TestVectorData(size)
+		expected, err := computeHashResult(bytes.NewReader(content), segSize, redundancy.DataBlocks,
+			redundancy.ParityBlocks, true)
+		assert.Nil(t, err)
+
+		var failures int32 = 2
+		var rangeRequests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the first requests fail temporarily and must be retried
+			if atomic.AddInt32(&failures, -1) >= 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if r.Header.Get("Range") != "" {
+				atomic.AddInt32(&rangeRequests, 1)
+			}
+			http.ServeContent(w, r, "object", time.Time{}, bytes.NewReader(content))
+		}))
+		computed, err := ComputeIntegrityHashFromURL(context.Background(), server.URL, segSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks, WithRetry(3, time.Millisecond), WithConcurrency(3))
+		server.Close()
+		assert.Nil(t, err)
+		assert.Nil(t, diffHashResult(expected, computed))
+		assert.Equal(t, int32((size+segSize-1)/segSize), rangeRequests)
+
+		// a server without range support is hashed as a stream
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(content)
+		}))
+		computed, err = ComputeIntegrityHashFromURL(context.Background(), server.URL, segSize,
+			redundancy.DataBlocks, redundancy.ParityBlocks)
+		server.Close()
+		assert.Nil(t, err)
+		assert.Nil(t, diffHashResult(expected, computed))
+	}
+}
+
+func TestComputeIntegrityHashFromURLErrors(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := ComputeIntegrityHashFromURL(context.Background(), server.URL, 1024, redundancy.DataBlocks,
+		redundancy.ParityBlocks, WithRetry(3, time.Millisecond))
+	assert.ErrorIs(t, err, ErrReaderFailed)
+	var statusErr *StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusNotFound, statusErr.StatusCode)
+	// a rejected request is not retried
+	assert.Equal(t, int32(1), requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ComputeIntegrityHashFromURL(ctx, server.URL, 1024, redundancy.DataBlocks, redundancy.ParityBlocks)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,127 @@
+package hash
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+)
+
+// goldenVectors stores the canonical test vectors generated by GenerateTestVectors, run
+// `go test ./hash -run TestGoldenVectors -update` from the go module root to regenerate it
+//
+//go:embed golden/vectors.json
+var goldenVectors []byte
+
+var (
+	vectorObjectSizes  = []int64{0, 1, 255, 1024, 4095, 4096, 4097, 10000}
+	vectorSegmentSizes = []int64{1024, 4096}
+	vectorECParams     = [][2]int{{4, 2}, {6, 3}}
+)
+
+// TestVector describes one canonical integrity hash computation, it can be used by the SDKs of other languages
+// to check the compatibility with this package
+type TestVector struct {
+	Name         string `json:"name"`
+	ObjectSize   int64  `json:"object_size"`
+	SegmentSize  int64  `json:"segment_size"`
+	DataShards   int    `json:"data_shards"`
+	ParityShards int    `json:"parity_shards"`
+	HashResult
+}
+
+// TestVectorData return the deterministic content of a test vector with the given size,
+// the i-th byte of the content is i mod 251 plus one
+func TestVectorData(size int64) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i%251 + 1)
+	}
+	return data
+}
+
+// GenerateTestVectors compute the test vectors for the matrix of object sizes, segment sizes and ec params
+func GenerateTestVectors() ([]TestVector, error) {
+	vectors := make([]TestVector, 0, len(vectorObjectSizes)*len(vectorSegmentSizes)*len(vectorECParams))
+	for _, ecParams := range vectorECParams {
+		for _, segmentSize := range vectorSegmentSizes {
+			for _, objectSize := range vectorObjectSizes {
+				vector, err := GenerateTestVector(objectSize, segmentSize, ecParams[0], ecParams[1])
+				if err != nil {
+					return nil, err
+				}
+				vectors = append(vectors, vector)
+			}
+		}
+	}
+	return vectors, nil
+}
+
+// GenerateTestVector compute the test vector of the content returned by TestVectorData
+func GenerateTestVector(objectSize, segmentSize int64, dataShards, parityShards int) (TestVector, error) {
+	checksums, contentLen, redundancyType, err := ComputeIntegrityHashSerial(bytes.NewReader(TestVectorData(objectSize)),
+		segmentSize, dataShards, parityShards)
+	if err != nil {
+		return TestVector{}, err
+	}
+	return TestVector{
+		Name:         fmt.Sprintf("size_%d_segment_%d_ec_%d_%d", objectSize, segmentSize, dataShards, parityShards),
+		ObjectSize:   objectSize,
+		SegmentSize:  segmentSize,
+		DataShards:   dataShards,
+		ParityShards: parityShards,
+		HashResult:   *NewHashResult(checksums, contentLen, redundancyType),
+	}, nil
+}
+
+// GoldenTestVectors return the test vectors embedded in this package
+func GoldenTestVectors() ([]TestVector, error) {
+	var vectors []TestVector
+	if err := json.Unmarshal(goldenVectors, &vectors); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+// VerifyTestVector recompute the test vector with both the serial and the parallel version functions
+// and compare the results with the expected hash result
+func VerifyTestVector(vector TestVector) error {
+	for _, isSerial := range []bool{true, false} {
+		checksums, contentLen, redundancyType, err := ComputeIntegrityHash(
+			bytes.NewReader(TestVectorData(vector.ObjectSize)), vector.SegmentSize, vector.DataShards,
+			vector.ParityShards, isSerial)
+		if err != nil {
+			return err
+		}
+		computed := NewHashResult(checksums, contentLen, redundancyType)
+		if err = diffHashResult(&vector.HashResult, computed); err != nil {
+			return fmt.Errorf("test vector %s (serial: %t): %w", vector.Name, isSerial, err)
+		}
+	}
+	return nil
+}
+
+// diffHashResult return an error describing the first difference between the expected and computed results
+func diffHashResult(expected, computed *HashResult) error {
+	if err := expected.CheckVersion(); err != nil {
+		return err
+	}
+	if computed.Version != CurrentHashVersion {
+		return fmt.Errorf("expect version %d, got %d", CurrentHashVersion, computed.Version)
+	}
+	if computed.ContentLength != expected.ContentLength {
+		return fmt.Errorf("expect content length %d, got %d", expected.ContentLength, computed.ContentLength)
+	}
+	if computed.RedundancyType != expected.RedundancyType {
+		return fmt.Errorf("expect redundancy type %s, got %s", expected.RedundancyType, computed.RedundancyType)
+	}
+	if len(computed.Checksums) != len(expected.Checksums) {
+		return fmt.Errorf("expect %d checksums, got %d", len(expected.Checksums), len(computed.Checksums))
+	}
+	for id, checksum := range computed.Checksums {
+		if !bytes.Equal(checksum, expected.Checksums[id]) {
+			return fmt.Errorf("checksum %d mismatch", id)
+		}
+	}
+	return nil
+}
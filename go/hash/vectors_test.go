@@ -0,0 +1,36 @@
+package hash
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden regenerates golden/vectors.json instead of verifying it. The flag is registered for the whole
+// hash test binary, TestGoldenVectors is the only test which reads it and it skips the verification when set,
+// so rerun the test without -update afterwards.
+var updateGolden = flag.Bool("update", false, "regenerate golden/vectors.json instead of verifying it")
+
+func TestGoldenVectors(t *testing.T) {
+	if *updateGolden {
+		vectors, err := GenerateTestVectors()
+		require.NoError(t, err)
+		content, err := json.MarshalIndent(vectors, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile("golden/vectors.json", append(content, '\n'), 0o600))
+		t.Skip("golden vectors regenerated, rerun without -update to verify them")
+	}
+
+	vectors, err := GoldenTestVectors()
+	require.NoError(t, err)
+	assert.Equal(t, len(vectorObjectSizes)*len(vectorSegmentSizes)*len(vectorECParams), len(vectors))
+	for _, vector := range vectors {
+		assert.Equal(t, vector.DataShards+vector.ParityShards+1, len(vector.Checksums))
+		assert.Equal(t, vector.ObjectSize, vector.ContentLength)
+		assert.NoError(t, VerifyTestVector(vector))
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ExpiryTimestampFormat is the ISO 8601 format of HTTPHeaderExpiryTimestamp
+const ExpiryTimestampFormat = "2006-01-02T15:04:05Z"
+
+var (
+	// ErrMissingAuthorization is returned when the request has no Authorization header
+	ErrMissingAuthorization = errors.New("missing authorization header")
+	// ErrUnsupportedAuthType is returned when the Authorization header uses an unsupported auth type
+	ErrUnsupportedAuthType = errors.New("unsupported auth type")
+	// ErrInvalidAuthorization is returned when the Authorization header can not be parsed or its signature recovered
+	ErrInvalidAuthorization = errors.New("invalid authorization header")
+	// ErrInvalidExpiryTimestamp is returned when the expiry timestamp is missing, malformed or more than
+	// MaxExpiryAgeInSec ahead
+	ErrInvalidExpiryTimestamp = errors.New("invalid expiry timestamp")
+	// ErrRequestExpired is returned when the expiry timestamp of the request has passed
+	ErrRequestExpired = errors.New("request is expired")
+)
+
+// SignRequestECDSA signs the canonical request with the private key in the GNFD1-ECDSA auth type, the request is
+// valid until expiry which must be within MaxExpiryAgeInSec. It sets HTTPHeaderExpiryTimestamp, which is a signed
+// header, and HTTPHeaderAuthorization, so the other signed headers must be set before.
+func SignRequestECDSA(req *http.Request, privateKey *ecdsa.PrivateKey, expiry time.Time) error {
+	req.Header.Set(HTTPHeaderExpiryTimestamp, expiry.UTC().Format(ExpiryTimestampFormat))
+	sig, err := crypto.Sign(GetMsgToSignInGNFD1Auth(req), privateKey)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(HTTPHeaderAuthorization, AuthorizationHeader(Gnfd1Ecdsa, sig))
+	return nil
+}
+
+// AuthorizationHeader return the Authorization header of the signature in the auth type, e.g.
+// "GNFD1-ECDSA, Signature=<hex signature>"
+func AuthorizationHeader(authType string, signature []byte) string {
+	return authType + ", Signature=" + hex.EncodeToString(signature)
+}
+
+// ParseAuthorizationHeader return the auth type and the signature of an Authorization header
+func ParseAuthorizationHeader(header string) (string, []byte, error) {
+	authType, signature, found := strings.Cut(header, ",")
+	if !found {
+		return "", nil, fmt.Errorf("%w: %q", ErrInvalidAuthorization, header)
+	}
+	value, found := strings.CutPrefix(strings.TrimSpace(signature), "Signature=")
+	if !found {
+		return "", nil, fmt.Errorf("%w: missing signature", ErrInvalidAuthorization)
+	}
+	sig, err := hex.DecodeString(value)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", ErrInvalidAuthorization, err)
+	}
+	return strings.TrimSpace(authType), sig, nil
+}
+
+// VerifyRequestECDSA verify the GNFD1-ECDSA Authorization header and the expiry timestamp of the request at now,
+// it returns the address of the signer
+func VerifyRequestECDSA(req *http.Request, now time.Time) (common.Address, error) {
+	header := req.Header.Get(HTTPHeaderAuthorization)
+	if header == "" {
+		return common.Address{}, ErrMissingAuthorization
+	}
+	authType, sig, err := ParseAuthorizationHeader(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if authType != Gnfd1Ecdsa {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrUnsupportedAuthType, authType)
+	}
+	if err = CheckExpiryTimestamp(req.Header.Get(HTTPHeaderExpiryTimestamp), now); err != nil {
+		return common.Address{}, err
+	}
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%w: signature length %d", ErrInvalidAuthorization, len(sig))
+	}
+	pubKey, err := crypto.SigToPub(GetMsgToSignInGNFD1Auth(req), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrInvalidAuthorization, err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// CheckExpiryTimestamp return an error if the expiry timestamp has passed at now or is more than MaxExpiryAgeInSec
+// after now
+func CheckExpiryTimestamp(expiryTimestamp string, now time.Time) error {
+	expiry, err := time.Parse(ExpiryTimestampFormat, expiryTimestamp)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidExpiryTimestamp, expiryTimestamp)
+	}
+	if expiry.Before(now) {
+		return fmt.Errorf("%w: expired at %s", ErrRequestExpired, expiryTimestamp)
+	}
+	if expiry.After(now.Add(MaxExpiryAgeInSec * time.Second)) {
+		return fmt.Errorf("%w: %s is more than %d seconds ahead", ErrInvalidExpiryTimestamp, expiryTimestamp,
+			MaxExpiryAgeInSec)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignRequestECDSA(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	req, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket/object?a=1", nil)
+	require.NoError(t, err)
+	req.Header.Set(HTTPHeaderUserAddress, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	require.NoError(t, SignRequestECDSA(req, privateKey, now.Add(time.Hour)))
+	assert.Equal(t, "2024-01-02T04:04:05Z", req.Header.Get(HTTPHeaderExpiryTimestamp))
+
+	signer, err := VerifyRequestECDSA(req, now)
+	require.NoError(t, err)
+	assert.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey), signer)
+
+	tampered := req.Clone(req.Context())
+	tampered.Header.Set(HTTPHeaderUserAddress, "0x00")
+	signer, err = VerifyRequestECDSA(tampered, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, crypto.PubkeyToAddress(privateKey.PublicKey), signer)
+
+	_, err = VerifyRequestECDSA(req, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, ErrRequestExpired)
+	_, err = VerifyRequestECDSA(req, now.Add(-MaxExpiryAgeInSec*time.Second))
+	assert.ErrorIs(t, err, ErrInvalidExpiryTimestamp)
+
+	unsigned := req.Clone(req.Context())
+	unsigned.Header.Del(HTTPHeaderAuthorization)
+	_, err = VerifyRequestECDSA(unsigned, now)
+	assert.ErrorIs(t, err, ErrMissingAuthorization)
+}
+
+func TestParseAuthorizationHeader(t *testing.T) {
+	authType, sig, err := ParseAuthorizationHeader(AuthorizationHeader(Gnfd1Eddsa, []byte{1, 2}))
+	require.NoError(t, err)
+	assert.Equal(t, Gnfd1Eddsa, authType)
+	assert.Equal(t, []byte{1, 2}, sig)
+
+	authType, sig, err = ParseAuthorizationHeader("GNFD1-ECDSA,Signature=0a")
+	require.NoError(t, err)
+	assert.Equal(t, Gnfd1Ecdsa, authType)
+	assert.Equal(t, []byte{10}, sig)
+
+	for _, invalid := range []string{"GNFD1-ECDSA", "GNFD1-ECDSA, Sig=00", "GNFD1-ECDSA, Signature=xyz"} {
+		_, _, err = ParseAuthorizationHeader(invalid)
+		assert.ErrorIs(t, err, ErrInvalidAuthorization, invalid)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket", nil)
+	require.NoError(t, err)
+	req.Header.Set(HTTPHeaderAuthorization, AuthorizationHeader(Gnfd1Eddsa, []byte{1}))
+	_, err = VerifyRequestECDSA(req, time.Now())
+	assert.ErrorIs(t, err, ErrUnsupportedAuthType)
+}
+
+func TestCheckExpiryTimestamp(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, CheckExpiryTimestamp("2024-01-02T03:04:05Z", now))
+	assert.NoError(t, CheckExpiryTimestamp("2024-01-09T03:04:05Z", now))
+	assert.ErrorIs(t, CheckExpiryTimestamp("2024-01-09T03:04:06Z", now), ErrInvalidExpiryTimestamp)
+	assert.ErrorIs(t, CheckExpiryTimestamp("2024-01-02T03:04:04Z", now), ErrRequestExpired)
+	assert.ErrorIs(t, CheckExpiryTimestamp("", now), ErrInvalidExpiryTimestamp)
+	assert.ErrorIs(t, CheckExpiryTimestamp("20240102T030405Z", now), ErrInvalidExpiryTimestamp)
+}
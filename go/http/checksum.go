@@ -0,0 +1,46 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+// SetPieceHashHeaders sets the integrity hash and the piece checksums x-headers of the replication of pieces
+func SetPieceHashHeaders(header http.Header, integrityHash []byte, pieceChecksums [][]byte) {
+	header.Set(HTTPHeaderIntegrityHash, hash.ChecksumToHex(integrityHash))
+	header.Set(HTTPHeaderPieceHash, hash.ChecksumsToHexList(pieceChecksums))
+}
+
+// ParsePieceHashHeaders return the integrity hash and the piece checksums of the x-headers set by
+// SetPieceHashHeaders, the integrity hash must match the piece checksums otherwise hash.ErrIntegrityHashMismatch is
+// returned
+func ParsePieceHashHeaders(header http.Header) ([]byte, [][]byte, error) {
+	integrityHash, err := hash.ParseChecksumHex(header.Get(HTTPHeaderIntegrityHash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s header: %w", HTTPHeaderIntegrityHash, err)
+	}
+	pieceChecksums, err := hash.ParseChecksumsHexList(header.Get(HTTPHeaderPieceHash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s header: %w", HTTPHeaderPieceHash, err)
+	}
+	if err = hash.VerifyIntegrityHash(integrityHash, pieceChecksums); err != nil {
+		return nil, nil, err
+	}
+	return integrityHash, pieceChecksums, nil
+}
+
+// SetChecksumsHeader sets the x-header of the integrity hashes of an object
+func SetChecksumsHeader(header http.Header, checksums [][]byte) {
+	header.Set(HTTPHeaderChecksums, hash.ChecksumsToBase64List(checksums))
+}
+
+// ParseChecksumsHeader return the integrity hashes of the x-header set by SetChecksumsHeader
+func ParseChecksumsHeader(header http.Header) ([][]byte, error) {
+	checksums, err := hash.ParseChecksumsBase64List(header.Get(HTTPHeaderChecksums))
+	if err != nil {
+		return nil, fmt.Errorf("%s header: %w", HTTPHeaderChecksums, err)
+	}
+	return checksums, nil
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+func TestPieceHashHeaders(t *testing.T) {
+	pieceChecksums := [][]byte{hash.GenerateChecksum([]byte("a")), hash.GenerateChecksum([]byte("b"))}
+	integrityHash := hash.GenerateIntegrityHash(pieceChecksums)
+	header := http.Header{}
+	SetPieceHashHeaders(header, integrityHash, pieceChecksums)
+	assert.Equal(t, hash.ChecksumToHex(integrityHash), header.Get(HTTPHeaderIntegrityHash))
+
+	parsedHash, parsedChecksums, err := ParsePieceHashHeaders(header)
+	require.NoError(t, err)
+	assert.Equal(t, integrityHash, parsedHash)
+	assert.Equal(t, pieceChecksums, parsedChecksums)
+
+	SetPieceHashHeaders(header, integrityHash, pieceChecksums[:1])
+	_, _, err = ParsePieceHashHeaders(header)
+	assert.ErrorIs(t, err, hash.ErrIntegrityHashMismatch)
+	header.Set(HTTPHeaderPieceHash, "zz")
+	_, _, err = ParsePieceHashHeaders(header)
+	assert.ErrorIs(t, err, hash.ErrInvalidChecksumEncoding)
+}
+
+func TestChecksumsHeader(t *testing.T) {
+	checksums := [][]byte{hash.GenerateChecksum([]byte("a")), hash.GenerateChecksum([]byte("b"))}
+	header := http.Header{}
+	SetChecksumsHeader(header, checksums)
+	parsed, err := ParseChecksumsHeader(header)
+	require.NoError(t, err)
+	assert.Equal(t, checksums, parsed)
+
+	header.Set(HTTPHeaderChecksums, "A")
+	_, err = ParseChecksumsHeader(header)
+	assert.ErrorIs(t, err, hash.ErrInvalidChecksumEncoding)
+}
@@ -12,6 +12,13 @@ const (
 	HTTPHeaderContentMD5    = "Content-MD5"
 	HTTPHeaderRange         = "Range"
 	HTTPHeaderContentSHA256 = "X-Gnfd-Content-Sha256"
+	// HTTPHeaderIntegrityHash is the hex encoded integrity hash of the pieces replicated to a SP
+	HTTPHeaderIntegrityHash = "X-Gnfd-Integrity-Hash"
+	// HTTPHeaderPieceHash is the comma separated hex encoded checksums of the pieces replicated to a SP
+	HTTPHeaderPieceHash = "X-Gnfd-Piece-Hash"
+	// HTTPHeaderChecksums is the comma separated base64 encoded integrity hashes of an object, the one of the
+	// PrimarySP first
+	HTTPHeaderChecksums = "X-Gnfd-Checksums"
 
 	HTTPHeaderUserAddress = "X-Gnfd-User-Address"
 	// HTTPHeaderDate The date and time format must follow the ISO 8601 standard, and must be formatted with the "yyyyMMddTHHmmssZ" format. For example if the date and time was "08/01/2016 15:32:41.982-700" then it must first be converted to UTC (Coordinated Universal Time) and then submitted as "20160801T223241Z".
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCanonicalRequest(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPut,
+		"http://sp.example.com/bucket/ob%20ject/%E4%B8%AD?b=2&a=1&a=x+y&c=1%2B1&empty=", nil)
+	require.NoError(t, err)
+	req.Header.Add(HTTPHeaderContentSHA256, "  abc   def  ")
+	req.Header.Add(HTTPHeaderContentType, "text/plain")
+	req.Header.Add(HTTPHeaderContentType, "charset=utf-8")
+	req.Header.Add("X-Other", "not signed")
+
+	expected := "PUT\n" +
+		"/bucket/ob%20ject/%E4%B8%AD\n" +
+		"a=1&a=x%20y&b=2&c=1%2B1&empty=\n" +
+		"content-type:text/plain,charset=utf-8\n" +
+		"x-gnfd-content-sha256:abc def\n" +
+		"sp.example.com\n" +
+		"\n" +
+		"content-type;x-gnfd-content-sha256"
+	assert.Equal(t, expected, GetCanonicalRequest(req))
+
+	// the host of the server side request is taken from req.Host
+	serverReq := req.Clone(req.Context())
+	serverReq.URL.Host = ""
+	serverReq.Host = "sp.example.com"
+	assert.Equal(t, expected, GetCanonicalRequest(serverReq))
+	assert.Equal(t, GetMsgToSignInGNFD1Auth(req), GetMsgToSignInGNFD1Auth(serverReq))
+}
+
+func TestGetCanonicalRequestHeaderOrder(t *testing.T) {
+	req1, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket", nil)
+	require.NoError(t, err)
+	req1.Header.Set(HTTPHeaderUserAddress, "0x01")
+	req1.Header.Set(HTTPHeaderDate, "20160801T223241Z")
+	req2, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket", nil)
+	require.NoError(t, err)
+	req2.Header.Set(HTTPHeaderDate, "20160801T223241Z")
+	req2.Header.Set(HTTPHeaderUserAddress, "0x01")
+	assert.Equal(t, GetCanonicalRequest(req1), GetCanonicalRequest(req2))
+
+	req2.Header.Set(HTTPHeaderUserAddress, "0x02")
+	assert.NotEqual(t, GetMsgToSignInGNFD1Auth(req1), GetMsgToSignInGNFD1Auth(req2))
+}
+
+func TestGetMsgToSignInGNFD1AuthForPreSignedURL(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket/object?"+
+		HTTPHeaderExpiryTimestamp+"=2021-09-30T16%3A25%3A24Z", nil)
+	require.NoError(t, err)
+	signed := GetMsgToSignInGNFD1AuthForPreSignedURL(req.Clone(req.Context()))
+
+	query := req.URL.Query()
+	query.Set(HTTPHeaderAuthorization, "GNFD1-ECDSA, Signature=00")
+	req.URL.RawQuery = query.Encode()
+	assert.Equal(t, signed, GetMsgToSignInGNFD1AuthForPreSignedURL(req))
+}
+
+func TestEncodePath(t *testing.T) {
+	assert.Equal(t, "/bucket/a-b_c.d~e", EncodePath("/bucket/a-b_c.d~e"))
+	assert.Equal(t, "/bucket/a%20b%2Bc%3F", EncodePath("/bucket/a b+c?"))
+	assert.Equal(t, "/%E4%B8%AD%E6%96%87", EncodePath("/中文"))
+}
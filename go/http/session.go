@@ -0,0 +1,181 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrInvalidSessionKey is returned when the registration of a session key is not signed by its user, has an
+	// unexpected nonce or is already expired
+	ErrInvalidSessionKey = errors.New("invalid session key")
+	// ErrSessionKeyNotFound is returned when the user of a request has no registered session key
+	ErrSessionKeyNotFound = errors.New("session key not found")
+	// ErrSessionKeyExpired is returned when the session key of a request is expired
+	ErrSessionKeyExpired = errors.New("session key is expired")
+)
+
+// SessionKey is an ephemeral EdDSA key registered by a user on a SP, the dapps sign the requests of the user with
+// it in the GNFD1-EDDSA auth type instead of asking for a personal_sign for every request
+type SessionKey struct {
+	UserAddress common.Address
+	// Domain is the domain of the dapp the key is registered for
+	Domain    string
+	PublicKey ed25519.PublicKey
+	// Nonce is the registration count of the user on the domain, a registration must use the next nonce so a
+	// replayed registration is rejected
+	Nonce      uint64
+	ExpiryDate time.Time
+}
+
+// RegistrationMessage return the message signed by the user with personal_sign to register the session key
+func (k *SessionKey) RegistrationMessage() string {
+	return fmt.Sprintf("%s wants you to sign in with your Mechain account:\n%s\n\n"+
+		"Register your identity public key %s\n\nURI: %s\nVersion: 1\nNonce: %d\nExpiration Time: %s",
+		k.Domain, k.UserAddress.Hex(), hex.EncodeToString(k.PublicKey), k.Domain, k.Nonce,
+		k.ExpiryDate.UTC().Format(ExpiryTimestampFormat))
+}
+
+// SignRegistration return the personal_sign signature of the registration message of the session key by the
+// private key of its user
+func (k *SessionKey) SignRegistration(privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := crypto.Sign(accounts.TextHash([]byte(k.RegistrationMessage())), privateKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// VerifyRegistration verify the personal_sign signature of the registration message is signed by the user of the
+// session key, V of the signature may be 0, 1, 27 or 28
+func (k *SessionKey) VerifyRegistration(sig []byte) error {
+	if len(sig) != crypto.SignatureLength || len(k.PublicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: malformed registration", ErrInvalidSessionKey)
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(k.RegistrationMessage())), sig)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSessionKey, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != k.UserAddress {
+		return fmt.Errorf("%w: registration signed by %s", ErrInvalidSessionKey, signer)
+	}
+	return nil
+}
+
+// SignRequestEDDSA signs the canonical request with the session private key in the GNFD1-EDDSA auth type like
+// SignRequestECDSA, the user address header must be set before
+func SignRequestEDDSA(req *http.Request, privateKey ed25519.PrivateKey, expiry time.Time) {
+	req.Header.Set(HTTPHeaderExpiryTimestamp, expiry.UTC().Format(ExpiryTimestampFormat))
+	sig := ed25519.Sign(privateKey, GetMsgToSignInGNFD1Auth(req))
+	req.Header.Set(HTTPHeaderAuthorization, AuthorizationHeader(Gnfd1Eddsa, sig))
+}
+
+// VerifyRequestEDDSA verify the GNFD1-EDDSA Authorization header and the expiry timestamp of the request is signed
+// by the session key at now
+func VerifyRequestEDDSA(req *http.Request, key *SessionKey, now time.Time) error {
+	header := req.Header.Get(HTTPHeaderAuthorization)
+	if header == "" {
+		return ErrMissingAuthorization
+	}
+	authType, sig, err := ParseAuthorizationHeader(header)
+	if err != nil {
+		return err
+	}
+	if authType != Gnfd1Eddsa {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAuthType, authType)
+	}
+	if now.After(key.ExpiryDate) {
+		return fmt.Errorf("%w: expired at %s", ErrSessionKeyExpired, key.ExpiryDate)
+	}
+	if err = CheckExpiryTimestamp(req.Header.Get(HTTPHeaderExpiryTimestamp), now); err != nil {
+		return err
+	}
+	if !ed25519.Verify(key.PublicKey, GetMsgToSignInGNFD1Auth(req), sig) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidAuthorization)
+	}
+	return nil
+}
+
+type sessionKeyID struct {
+	user   common.Address
+	domain string
+}
+
+// SessionKeyRegistry keeps the session keys registered on a SP, the latest registration of a user on a domain
+// replaces the previous one. It never reads the time itself, Register and Verify check the expiries at the now of
+// the caller, e.g. the Now of a clock.Clock. It is safe for concurrent use.
+type SessionKeyRegistry struct {
+	mu     sync.RWMutex
+	keys   map[sessionKeyID]*SessionKey
+	nonces map[sessionKeyID]uint64
+}
+
+// NewSessionKeyRegistry return an empty SessionKeyRegistry
+func NewSessionKeyRegistry() *SessionKeyRegistry {
+	return &SessionKeyRegistry{
+		keys:   make(map[sessionKeyID]*SessionKey),
+		nonces: make(map[sessionKeyID]uint64),
+	}
+}
+
+// Nonce return the nonce of the next registration of the user on the domain
+func (r *SessionKeyRegistry) Nonce(user common.Address, domain string) uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.nonces[sessionKeyID{user: user, domain: domain}]
+}
+
+// Register verifies the registration of the session key at now and stores it, the key must use the nonce returned
+// by Nonce and expire within MaxExpiryAgeInSec
+func (r *SessionKeyRegistry) Register(key *SessionKey, sig []byte, now time.Time) error {
+	if err := CheckExpiryTimestamp(key.ExpiryDate.UTC().Format(ExpiryTimestampFormat), now); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSessionKey, err)
+	}
+	if err := key.VerifyRegistration(sig); err != nil {
+		return err
+	}
+	id := sessionKeyID{user: key.UserAddress, domain: key.Domain}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if key.Nonce != r.nonces[id] {
+		return fmt.Errorf("%w: nonce %d, expect %d", ErrInvalidSessionKey, key.Nonce, r.nonces[id])
+	}
+	r.nonces[id] = key.Nonce + 1
+	r.keys[id] = key
+	return nil
+}
+
+// Verify verifies the GNFD1-EDDSA request of the user of HTTPHeaderUserAddress is signed at now by the session key
+// registered on the domain, it returns the address of the user
+func (r *SessionKeyRegistry) Verify(req *http.Request, domain string, now time.Time) (common.Address, error) {
+	user := req.Header.Get(HTTPHeaderUserAddress)
+	if !common.IsHexAddress(user) {
+		return common.Address{}, fmt.Errorf("%w: invalid user address %q", ErrInvalidAuthorization, user)
+	}
+	id := sessionKeyID{user: common.HexToAddress(user), domain: domain}
+	r.mu.RLock()
+	key, ok := r.keys[id]
+	r.mu.RUnlock()
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: user %s on %s", ErrSessionKeyNotFound, user, domain)
+	}
+	if err := VerifyRequestEDDSA(req, key, now); err != nil {
+		return common.Address{}, err
+	}
+	return id.user, nil
+}
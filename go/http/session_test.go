@@ -0,0 +1,135 @@
+package http
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+)
+
+const dappDomain = "https://dapp.example.com"
+
+func TestSessionKey(t *testing.T) {
+	userKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	user := crypto.PubkeyToAddress(userKey.PublicKey)
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	registry := NewSessionKeyRegistry()
+	key := &SessionKey{
+		UserAddress: user,
+		Domain:      dappDomain,
+		PublicKey:   publicKey,
+		Nonce:       registry.Nonce(user, dappDomain),
+		ExpiryDate:  now.Add(24 * time.Hour),
+	}
+	sig, err := key.SignRegistration(userKey)
+	require.NoError(t, err)
+	require.NoError(t, registry.Register(key, sig, now))
+	assert.Equal(t, uint64(1), registry.Nonce(user, dappDomain))
+	assert.ErrorIs(t, registry.Register(key, sig, now), ErrInvalidSessionKey)
+
+	req, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket/object", nil)
+	require.NoError(t, err)
+	req.Header.Set(HTTPHeaderUserAddress, user.Hex())
+	SignRequestEDDSA(req, privateKey, now.Add(time.Minute))
+
+	signer, err := registry.Verify(req, dappDomain, now)
+	require.NoError(t, err)
+	assert.Equal(t, user, signer)
+
+	_, err = registry.Verify(req, "https://other.example.com", now)
+	assert.ErrorIs(t, err, ErrSessionKeyNotFound)
+	_, err = registry.Verify(req, dappDomain, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrRequestExpired)
+	_, err = registry.Verify(req, dappDomain, now.Add(25*time.Hour))
+	assert.ErrorIs(t, err, ErrSessionKeyExpired)
+
+	tampered := req.Clone(req.Context())
+	tampered.URL.Path = "/bucket/other"
+	_, err = registry.Verify(tampered, dappDomain, now)
+	assert.ErrorIs(t, err, ErrInvalidAuthorization)
+
+	ecdsaReq := req.Clone(req.Context())
+	require.NoError(t, SignRequestECDSA(ecdsaReq, userKey, now.Add(time.Minute)))
+	_, err = registry.Verify(ecdsaReq, dappDomain, now)
+	assert.ErrorIs(t, err, ErrUnsupportedAuthType)
+}
+
+func TestSessionKeyRegistration(t *testing.T) {
+	userKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	key := &SessionKey{
+		UserAddress: crypto.PubkeyToAddress(userKey.PublicKey),
+		Domain:      dappDomain,
+		PublicKey:   publicKey,
+		ExpiryDate:  now.Add(time.Hour),
+	}
+	sig, err := key.SignRegistration(userKey)
+	require.NoError(t, err)
+	assert.NoError(t, key.VerifyRegistration(sig))
+
+	registry := NewSessionKeyRegistry()
+	assert.ErrorIs(t, registry.Register(key, sig, now.Add(2*time.Hour)), ErrInvalidSessionKey)
+
+	replaced := *key
+	replaced.PublicKey, _, err = ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, replaced.VerifyRegistration(sig), ErrInvalidSessionKey)
+
+	skipped := *key
+	skipped.Nonce = 1
+	skippedSig, err := skipped.SignRegistration(userKey)
+	require.NoError(t, err)
+	assert.ErrorIs(t, registry.Register(&skipped, skippedSig, now), ErrInvalidSessionKey)
+	assert.NoError(t, registry.Register(key, sig, now))
+	assert.NoError(t, registry.Register(&skipped, skippedSig, now))
+
+	assert.ErrorIs(t, key.VerifyRegistration(sig[:10]), ErrInvalidSessionKey)
+}
+
+func TestSessionKeyRegistryClock(t *testing.T) {
+	userKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	user := crypto.PubkeyToAddress(userKey.PublicKey)
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	c := clock.NewFake(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	registry := NewSessionKeyRegistry()
+	key := &SessionKey{
+		UserAddress: user,
+		Domain:      dappDomain,
+		PublicKey:   publicKey,
+		ExpiryDate:  c.Now().Add(time.Hour),
+	}
+	sig, err := key.SignRegistration(userKey)
+	require.NoError(t, err)
+	require.NoError(t, registry.Register(key, sig, c.Now()))
+
+	// the session key expires as the clock advances, without sleeping
+	for _, step := range []time.Duration{30 * time.Minute, 29 * time.Minute, 2 * time.Minute} {
+		c.Advance(step)
+		req, err := http.NewRequest(http.MethodGet, "http://sp.example.com/bucket/object", nil)
+		require.NoError(t, err)
+		req.Header.Set(HTTPHeaderUserAddress, user.Hex())
+		SignRequestEDDSA(req, privateKey, c.Now().Add(time.Minute))
+		_, err = registry.Verify(req, dappDomain, c.Now())
+		if c.Now().After(key.ExpiryDate) {
+			assert.ErrorIs(t, err, ErrSessionKeyExpired)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
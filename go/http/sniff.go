@@ -0,0 +1,87 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	// the decoders of the image formats whose dimensions are extracted
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	// SniffLen is the number of leading bytes of the content used to detect its MIME type
+	SniffLen = 512
+	// DefaultPeekLen is the number of leading bytes of the content peeked by SniffReader, enough for the headers of
+	// the common image formats
+	DefaultPeekLen = 64 * 1024
+)
+
+// ContentMetadata is the metadata detected from the leading bytes of an object
+type ContentMetadata struct {
+	// MIMEType is the MIME type without parameters, e.g. "image/png"
+	MIMEType string
+	// Charset is the charset of the text content, e.g. "utf-8", empty for binary content
+	Charset string
+	// Width and Height are the dimensions in pixels of the images whose header is within the leading bytes
+	Width  int
+	Height int
+}
+
+// ContentType return the value of the Content-Type header of the content
+func (m ContentMetadata) ContentType() string {
+	if m.Charset == "" {
+		return m.MIMEType
+	}
+	return mime.FormatMediaType(m.MIMEType, map[string]string{"charset": m.Charset})
+}
+
+// SniffContent detects the metadata of the content from its leading bytes, the MIME type with the algorithm of
+// http.DetectContentType on its first SniffLen bytes so every gateway detects the same type.
+func SniffContent(head []byte) ContentMetadata {
+	mimeType, params, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return ContentMetadata{MIMEType: "application/octet-stream"}
+	}
+	metadata := ContentMetadata{MIMEType: mimeType, Charset: strings.ToLower(params["charset"])}
+	if mimeType == "text/plain" && metadata.Charset == "utf-8" && isJSON(head) {
+		metadata.MIMEType = "application/json"
+	}
+	if strings.HasPrefix(mimeType, "image/") {
+		if config, _, err := image.DecodeConfig(bytes.NewReader(head)); err == nil {
+			metadata.Width, metadata.Height = config.Width, config.Height
+		}
+	}
+	return metadata
+}
+
+// isJSON reports whether the first SniffLen bytes of the text look like the start of a JSON object or array
+func isJSON(text []byte) bool {
+	text = bytes.TrimLeft(text[:min(len(text), SniffLen)], " \t\r\n")
+	if len(text) == 0 || (text[0] != '{' && text[0] != '[') {
+		return false
+	}
+	text = bytes.TrimLeft(text[1:], " \t\r\n")
+	return len(text) == 0 || bytes.IndexByte([]byte(`"{[]}-0123456789tfn`), text[0]) >= 0
+}
+
+// SniffReader peeks at most peekLen leading bytes of r, DefaultPeekLen if it is not positive, and return the metadata
+// of the content and a reader replaying the peeked bytes followed by the rest of r, so the content read through it,
+// e.g. by the hashing and the upload, is the content of r.
+func SniffReader(r io.Reader, peekLen int) (ContentMetadata, io.Reader, error) {
+	if peekLen <= 0 {
+		peekLen = DefaultPeekLen
+	}
+	head := make([]byte, peekLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return ContentMetadata{}, nil, err
+	}
+	head = head[:n]
+	return SniffContent(head), io.MultiReader(bytes.NewReader(head), r), nil
+}
@@ -0,0 +1,96 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSniffContent(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 33, 17))
+	var pngData, jpegData, gifData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, img))
+	require.NoError(t, jpeg.Encode(&jpegData, img, nil))
+	require.NoError(t, gif.Encode(&gifData, img, nil))
+
+	for _, tc := range []struct {
+		name        string
+		head        []byte
+		metadata    ContentMetadata
+		contentType string
+	}{
+		{"png", pngData.Bytes(), ContentMetadata{MIMEType: "image/png", Width: 33, Height: 17}, "image/png"},
+		{"jpeg", jpegData.Bytes(), ContentMetadata{MIMEType: "image/jpeg", Width: 33, Height: 17}, "image/jpeg"},
+		{"gif", gifData.Bytes(), ContentMetadata{MIMEType: "image/gif", Width: 33, Height: 17}, "image/gif"},
+		{"truncated png", pngData.Bytes()[:16], ContentMetadata{MIMEType: "image/png"}, "image/png"},
+		{
+			"text", []byte("hello, world"), ContentMetadata{MIMEType: "text/plain", Charset: "utf-8"},
+			"text/plain; charset=utf-8",
+		},
+		{
+			"utf-16", []byte{0xfe, 0xff, 0, 'h', 0, 'i'}, ContentMetadata{MIMEType: "text/plain", Charset: "utf-16be"},
+			"text/plain; charset=utf-16be",
+		},
+		{
+			"html", []byte("<!DOCTYPE html><html></html>"), ContentMetadata{MIMEType: "text/html", Charset: "utf-8"},
+			"text/html; charset=utf-8",
+		},
+		{
+			"json", []byte(" \n{\"key\": [1, 2]}"), ContentMetadata{MIMEType: "application/json", Charset: "utf-8"},
+			"application/json; charset=utf-8",
+		},
+		{
+			"not json", []byte("{curly text}"), ContentMetadata{MIMEType: "text/plain", Charset: "utf-8"},
+			"text/plain; charset=utf-8",
+		},
+		{"pdf", []byte("%PDF-1.7\n"), ContentMetadata{MIMEType: "application/pdf"}, "application/pdf"},
+		{
+			"binary", []byte{0, 1, 2, 3, 0xff}, ContentMetadata{MIMEType: "application/octet-stream"},
+			"application/octet-stream",
+		},
+	} {
+		metadata := SniffContent(tc.head)
+		assert.Equal(t, tc.metadata, metadata, tc.name)
+		assert.Equal(t, tc.contentType, metadata.ContentType(), tc.name)
+	}
+}
+
+func TestSniffReader(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 300, 200))
+	var content bytes.Buffer
+	require.NoError(t, png.Encode(&content, img))
+	content.Write(bytes.Repeat([]byte{7}, 100*1024))
+
+	for _, peekLen := range []int{0, 100, 1 << 20} {
+		metadata, reader, err := SniffReader(iotest.HalfReader(bytes.NewReader(content.Bytes())), peekLen)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", metadata.MIMEType)
+		replayed, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, content.Bytes(), replayed)
+	}
+	metadata, _, err := SniffReader(bytes.NewReader(content.Bytes()), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 300, metadata.Width)
+	assert.Equal(t, 200, metadata.Height)
+
+	metadata, reader, err := SniffReader(bytes.NewReader(nil), 0)
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain", metadata.MIMEType)
+	replayed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Empty(t, replayed)
+
+	errRead := errors.New("read failed")
+	_, _, err = SniffReader(iotest.ErrReader(errRead), 0)
+	assert.ErrorIs(t, err, errRead)
+}
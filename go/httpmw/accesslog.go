@@ -0,0 +1,37 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+// AccessLog logs the requests with their status, response size and duration to the logger, e.g. log.NewSlogLogger
+// or zerologger.New: the requests served with a 5xx status at error level and the other requests at info level
+func AccessLog(logger log.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w}
+			// the caller is authenticated by an inner middleware, which reports it through the holder
+			holder := &callerHolder{}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), callerHolderKey{}, holder)))
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			caller := "anonymous"
+			if address, ok := holder.get(); ok {
+				caller = address.String()
+			}
+			logf := logger.Infof
+			if sw.status >= http.StatusInternalServerError {
+				logf = logger.Errorf
+			}
+			logf("http %s %s: status=%d size=%d remote=%s caller=%s request_id=%s duration=%s", r.Method,
+				r.URL.RequestURI(), sw.status, sw.size, r.RemoteAddr, caller, w.Header().Get(HeaderRequestID),
+				time.Since(start))
+		})
+	}
+}
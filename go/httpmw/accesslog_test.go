@@ -0,0 +1,48 @@
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+type recordLogger struct {
+	log.NopLogger
+	infos, errors []string
+}
+
+func (l *recordLogger) Infof(format string, args ...interface{}) {
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+}
+
+func (l *recordLogger) Errorf(format string, args ...interface{}) {
+	l.errors = append(l.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAccessLog(t *testing.T) {
+	logger := &recordLogger{}
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "failed", http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}), RequestID(), AccessLog(logger))
+
+	req := httptest.NewRequest(http.MethodGet, "/object?offset=1", nil)
+	req.Header.Set(HeaderRequestID, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/fail", nil))
+
+	require.Len(t, logger.infos, 1)
+	assert.Contains(t, logger.infos[0], "http GET /object?offset=1: status=200 size=5 remote=192.0.2.1:1234 "+
+		"caller=anonymous request_id=req-1 duration=")
+	require.Len(t, logger.errors, 1)
+	assert.Contains(t, logger.errors[0], "http PUT /fail: status=502 size=7")
+}
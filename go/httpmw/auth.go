@@ -0,0 +1,145 @@
+package httpmw
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+)
+
+// ErrUnauthorizedCaller is returned when a request is signed by a caller which is not authorized
+var ErrUnauthorizedCaller = errors.New("caller is not authorized")
+
+type callerKey struct{}
+
+type callerHolderKey struct{}
+
+// callerHolder reports the caller authenticated by an Authenticator to the outer middlewares, e.g. AccessLog
+type callerHolder struct {
+	mu     sync.Mutex
+	caller common.Address
+	ok     bool
+}
+
+func (h *callerHolder) set(caller common.Address) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.caller, h.ok = caller, true
+}
+
+func (h *callerHolder) get() (common.Address, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.caller, h.ok
+}
+
+// CallerFromContext return the address of the caller authenticated by an Authenticator
+func CallerFromContext(ctx context.Context) (common.Address, bool) {
+	caller, ok := ctx.Value(callerKey{}).(common.Address)
+	return caller, ok
+}
+
+// Authenticator verifies the GNFD1-ECDSA Authorization header and the expiry timestamp of the requests and sets the
+// address of their caller in the context of the handlers, see CallerFromContext
+type Authenticator struct {
+	callers map[common.Address]struct{}
+	public  []string
+	now     func() time.Time
+}
+
+// AuthOption configures an Authenticator
+type AuthOption func(*Authenticator)
+
+// WithAuthorizedCallers only serves the requests signed by the keys of the callers, the requests signed by any key
+// are served by default
+func WithAuthorizedCallers(callers ...common.Address) AuthOption {
+	return func(a *Authenticator) {
+		a.callers = make(map[common.Address]struct{}, len(callers))
+		for _, caller := range callers {
+			a.callers[caller] = struct{}{}
+		}
+	}
+}
+
+// WithPublicPaths serves the requests to one of the paths or below it without authorization, e.g. "/status" and
+// "/status/sp" but not "/statusadmin"
+func WithPublicPaths(paths ...string) AuthOption {
+	return func(a *Authenticator) {
+		a.public = append(a.public, paths...)
+	}
+}
+
+// isPublic reports whether the path is one of the public paths or below it, by whole path segments
+func (a *Authenticator) isPublic(path string) bool {
+	for _, public := range a.public {
+		if path == public || strings.HasPrefix(path, strings.TrimSuffix(public, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithClock checks the expiry timestamps of the requests at the time of c instead of the system clock, e.g. a
+// clock.Fake in tests
+func WithClock(c clock.Clock) AuthOption {
+	return func(a *Authenticator) {
+		a.now = c.Now
+	}
+}
+
+// NewAuthenticator return an Authenticator of the requests
+func NewAuthenticator(opts ...AuthOption) *Authenticator {
+	a := &Authenticator{now: time.Now}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Authenticate verify the authorization of the request and return its caller
+func (a *Authenticator) Authenticate(r *http.Request) (common.Address, error) {
+	caller, err := commonhttp.VerifyRequestECDSA(r, a.now())
+	if err != nil {
+		return common.Address{}, err
+	}
+	if a.callers != nil {
+		if _, ok := a.callers[caller]; !ok {
+			return common.Address{}, fmt.Errorf("%w: %s", ErrUnauthorizedCaller, caller)
+		}
+	}
+	return caller, nil
+}
+
+// Middleware return the middleware rejecting the requests which are not authorized, with 401 Unauthorized or with
+// 403 Forbidden when their caller is not authorized
+func (a *Authenticator) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a.isPublic(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			caller, err := a.Authenticate(r)
+			if errors.Is(err, ErrUnauthorizedCaller) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if holder, ok := r.Context().Value(callerHolderKey{}).(*callerHolder); ok {
+				holder.set(caller)
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, caller)))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+func TestAuthenticator(t *testing.T) {
+	callerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	caller := crypto.PubkeyToAddress(callerKey.PublicKey)
+	c := clock.NewFake(time.Unix(1700000000, 0))
+
+	var authenticated common.Address
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authenticated, _ = CallerFromContext(r.Context())
+	}), AccessLog(log.NopLogger{}), NewAuthenticator(WithAuthorizedCallers(caller), WithPublicPaths("/status"),
+		WithClock(c)).Middleware())
+
+	serve := func(req *http.Request) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://sp.mechain.io/bucket/object", nil)
+	require.NoError(t, commonhttp.SignRequestECDSA(req, callerKey, c.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusOK, serve(req))
+	assert.Equal(t, caller, authenticated)
+
+	req = httptest.NewRequest(http.MethodGet, "https://sp.mechain.io/bucket/object", nil)
+	require.NoError(t, commonhttp.SignRequestECDSA(req, otherKey, c.Now().Add(time.Minute)))
+	assert.Equal(t, http.StatusForbidden, serve(req))
+
+	req = httptest.NewRequest(http.MethodGet, "https://sp.mechain.io/bucket/object", nil)
+	require.NoError(t, commonhttp.SignRequestECDSA(req, callerKey, c.Now().Add(time.Minute)))
+	c.Advance(time.Minute + time.Second)
+	assert.Equal(t, http.StatusUnauthorized, serve(req))
+
+	assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest(http.MethodGet, "/bucket/object", nil)))
+	authenticated = common.Address{}
+	assert.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodGet, "/status", nil)))
+	assert.Equal(t, http.StatusOK, serve(httptest.NewRequest(http.MethodGet, "/status/sp", nil)))
+	assert.Equal(t, common.Address{}, authenticated)
+
+	// the public paths match whole path segments
+	assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest(http.MethodGet, "/statusadmin", nil)))
+	assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest(http.MethodGet, "/status-internal/keys", nil)))
+}
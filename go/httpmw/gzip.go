@@ -0,0 +1,152 @@
+package httpmw
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DefaultGzipContentTypes are the content types compressed by Gzip by default, the object payloads served by the
+// SPs are not compressed as they usually are binary or already compressed
+var DefaultGzipContentTypes = []string{"text/", "application/json", "application/xml"}
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(nil)
+	},
+}
+
+type gzipOptions struct {
+	contentTypes []string
+}
+
+// GzipOption configures the Gzip middleware
+type GzipOption func(*gzipOptions)
+
+// WithGzipContentTypes only compresses the responses whose content type starts with one of the prefixes,
+// DefaultGzipContentTypes by default
+func WithGzipContentTypes(prefixes ...string) GzipOption {
+	return func(o *gzipOptions) {
+		o.contentTypes = prefixes
+	}
+}
+
+// Gzip compresses the responses of the clients accepting the gzip encoding, if their content type is compressible
+// and they are not encoded already or partial
+func Gzip(opts ...GzipOption) Middleware {
+	options := gzipOptions{contentTypes: DefaultGzipContentTypes}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipWriter{ResponseWriter: w, contentTypes: options.contentTypes}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header accepts the gzip encoding
+func acceptsGzip(acceptEncoding string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		if name = strings.TrimSpace(name); name != "gzip" && name != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(params, " ", "")
+		return q != "q=0" && !strings.HasPrefix(q, "q=0.0") && q != "q=0."
+	}
+	return false
+}
+
+// gzipWriter compresses the response once its headers show it is compressible
+type gzipWriter struct {
+	http.ResponseWriter
+	contentTypes []string
+	wroteHeader  bool
+	gz           *gzip.Writer
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *gzipWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.compressible(status) {
+		header := w.Header()
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush implements http.Flusher
+func (w *gzipWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap return the wrapped writer for http.ResponseController
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible reports whether the response of the status can be compressed
+func (w *gzipWriter) compressible(status int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent {
+		return false
+	}
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	for _, prefix := range w.contentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// close flushes the compressed response and releases its gzip writer
+func (w *gzipWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	_ = w.gz.Close()
+	gzipWriters.Put(w.gz)
+	w.gz = nil
+}
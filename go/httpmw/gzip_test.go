@@ -0,0 +1,69 @@
+package httpmw
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.True(t, acceptsGzip("gzip"))
+	assert.True(t, acceptsGzip("deflate, gzip;q=0.8"))
+	assert.True(t, acceptsGzip("*"))
+	assert.False(t, acceptsGzip(""))
+	assert.False(t, acceptsGzip("br, deflate"))
+	assert.False(t, acceptsGzip("gzip;q=0"))
+	assert.False(t, acceptsGzip("gzip; q=0.000"))
+}
+
+func TestGzip(t *testing.T) {
+	body := strings.Repeat(`{"bucket":"mechain"}`, 100)
+	handler := Gzip()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Length", "2000")
+		case "/object":
+			w.Header().Set("Content-Type", "application/octet-stream")
+		case "/range":
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	recorder := serve("/json", "gzip")
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "", recorder.Header().Get("Content-Length"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+	assert.Less(t, recorder.Body.Len(), len(body))
+	reader, err := gzip.NewReader(recorder.Body)
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(decompressed))
+
+	// the content type is sniffed when not set
+	recorder = serve("/sniffed", "gzip")
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	for _, recorder = range []*httptest.ResponseRecorder{serve("/json", "br"), serve("/object", "gzip"),
+		serve("/range", "gzip")} {
+		assert.Equal(t, "", recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, body, recorder.Body.String())
+	}
+}
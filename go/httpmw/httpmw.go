@@ -0,0 +1,81 @@
+// Package httpmw provides the net/http middlewares shared by the SP gateway services, matching the gRPC interceptors
+// of the grpc package: the verification of the GNFD1-ECDSA Authorization header, the injection of request IDs,
+// access logging, gzip compression, the recovery of the panics and per-IP rate limiting. They are meant to be chained
+// with the request ID and the recovery first, e.g.
+//
+//	handler := httpmw.Chain(mux,
+//		httpmw.RequestID(),
+//		httpmw.Recovery(nil),
+//		httpmw.AccessLog(logger),
+//		limiter.Middleware(),
+//		authenticator.Middleware(),
+//		httpmw.Gzip(),
+//	)
+package httpmw
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Middleware wraps a handler
+type Middleware func(http.Handler) http.Handler
+
+// Chain return the handler wrapped by the middlewares, the first middleware is the outermost one
+func Chain(handler http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// statusWriter records the status and the size of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap return the wrapped writer for http.ResponseController
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,177 @@
+package httpmw
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/ratelimit"
+)
+
+// Limit is the rate of the requests of a client served by an IPRateLimiter, a zero Rate does not limit the requests
+type Limit struct {
+	// Rate is the number of requests per second
+	Rate float64
+	// Burst is the number of requests which can be served at once, at least 1
+	Burst int
+}
+
+// IPRateLimiter limits the rate of the requests of each client IP with a token bucket. It is safe for concurrent use.
+type IPRateLimiter struct {
+	limit         Limit
+	forwardedFor  bool
+	pruneInterval time.Duration
+	now           func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+// bucket is the token bucket of a client, refilled at the rate of the limit
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimitOption configures an IPRateLimiter
+type RateLimitOption func(*IPRateLimiter)
+
+// WithForwardedFor identifies the clients by the last address of the X-Forwarded-For header, which is appended by
+// the reverse proxy in front of the service, instead of the remote address of the connection. It must only be used
+// behind such a proxy as the header is set by the clients otherwise.
+func WithForwardedFor() RateLimitOption {
+	return func(l *IPRateLimiter) {
+		l.forwardedFor = true
+	}
+}
+
+// NewIPRateLimiter return an IPRateLimiter limiting the requests of each client IP to the limit
+func NewIPRateLimiter(limit Limit, opts ...RateLimitOption) *IPRateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	l := &IPRateLimiter{limit: limit, now: time.Now, buckets: make(map[string]*bucket)}
+	if limit.Rate > 0 {
+		// a bucket untouched for this time is full again and can be forgotten
+		l.pruneInterval = time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow reports whether a request of the client IP can be served now, and consumes a token of its bucket if so,
+// otherwise it also return the time until the next token
+func (l *IPRateLimiter) Allow(ip string) (bool, time.Duration) {
+	if l.limit.Rate <= 0 {
+		return true, 0
+	}
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+	b, ok := l.buckets[ip]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.buckets[ip] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(l.limit.Burst), b.tokens+elapsed.Seconds()*l.limit.Rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.limit.Rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// prune forgets the buckets full again, at most once per prune interval
+func (l *IPRateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < l.pruneInterval {
+		return
+	}
+	for ip, b := range l.buckets {
+		if now.Sub(b.last) >= l.pruneInterval {
+			delete(l.buckets, ip)
+		}
+	}
+	l.lastPrune = now
+}
+
+// ClientIP return the IP of the client of the request
+func (l *IPRateLimiter) ClientIP(r *http.Request) string {
+	if l.forwardedFor {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			addresses := strings.Split(forwarded[len(forwarded)-1], ",")
+			if ip := strings.TrimSpace(addresses[len(addresses)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Middleware return the middleware rejecting the requests over the limit of their client IP with 429 Too Many
+// Requests and a Retry-After header
+func (l *IPRateLimiter) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ok, wait := l.Allow(l.ClientIP(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimit return the middleware rejecting the requests over the limits checked by checks, e.g. the limit of their
+// caller and the limit of their bucket, with 429 Too Many Requests and a Retry-After header, see ratelimit.AllowAll.
+// The requests whose limits can not be checked, e.g. because the store of the limiters is down, are rejected with
+// 503 Service Unavailable. It must follow the Authenticator for the checks to use CallerFromContext, e.g.
+//
+//	httpmw.RateLimit(func(r *http.Request) []ratelimit.Check {
+//		return append(httpmw.CallerChecks(accounts)(r), buckets.Check(bucketName(r)))
+//	})
+func RateLimit(checks func(r *http.Request) []ratelimit.Check) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := ratelimit.AllowAll(r.Context(), checks(r)...)
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+				return
+			}
+			if !res.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CallerChecks return the checks of RateLimit limiting the requests of each caller authenticated by an
+// Authenticator with the limiter, keyed by the hex address of the caller. The requests without a caller are not
+// limited.
+func CallerChecks(limiter *ratelimit.Limiter) func(r *http.Request) []ratelimit.Check {
+	return func(r *http.Request) []ratelimit.Check {
+		caller, ok := CallerFromContext(r.Context())
+		if !ok {
+			return nil
+		}
+		return []ratelimit.Check{limiter.Check(caller.Hex())}
+	}
+}
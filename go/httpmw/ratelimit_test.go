@@ -0,0 +1,100 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/ratelimit"
+)
+
+func TestIPRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := NewIPRateLimiter(Limit{Rate: 0.5, Burst: 2})
+	limiter.now = func() time.Time { return now }
+
+	ok, _ := limiter.Allow("10.0.0.1")
+	assert.True(t, ok)
+	ok, _ = limiter.Allow("10.0.0.1")
+	assert.True(t, ok)
+	ok, wait := limiter.Allow("10.0.0.1")
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, wait)
+	ok, _ = limiter.Allow("10.0.0.2")
+	assert.True(t, ok)
+
+	now = now.Add(2 * time.Second)
+	ok, _ = limiter.Allow("10.0.0.1")
+	assert.True(t, ok)
+
+	// the full buckets are forgotten
+	now = now.Add(time.Minute)
+	ok, _ = limiter.Allow("10.0.0.3")
+	assert.True(t, ok)
+	assert.Len(t, limiter.buckets, 1)
+
+	unlimited := NewIPRateLimiter(Limit{})
+	for i := 0; i < 100; i++ {
+		ok, _ = unlimited.Allow("10.0.0.1")
+		assert.True(t, ok)
+	}
+}
+
+func TestIPRateLimiterMiddleware(t *testing.T) {
+	handler := NewIPRateLimiter(Limit{Rate: 0.1, Burst: 1}).Middleware()(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	assert.Equal(t, http.StatusOK, serve("10.0.0.1:1234").Code)
+	recorder := serve("10.0.0.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "10", recorder.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve("10.0.0.2:1234").Code)
+}
+
+func TestClientIP(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Add("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+	req.Header.Add("X-Forwarded-For", "3.3.3.3")
+	assert.Equal(t, "10.0.0.1", NewIPRateLimiter(Limit{}).ClientIP(req))
+	assert.Equal(t, "3.3.3.3", NewIPRateLimiter(Limit{}, WithForwardedFor()).ClientIP(req))
+
+	req.Header.Del("X-Forwarded-For")
+	req.RemoteAddr = "[::1]:1234"
+	assert.Equal(t, "::1", NewIPRateLimiter(Limit{}, WithForwardedFor()).ClientIP(req))
+}
+
+func TestRateLimit(t *testing.T) {
+	accounts := ratelimit.New("account", ratelimit.PerMinute(1))
+	buckets := ratelimit.New("bucket", ratelimit.PerMinute(2))
+	handler := RateLimit(func(r *http.Request) []ratelimit.Check {
+		return append(CallerChecks(accounts)(r), buckets.Check(r.URL.Path))
+	})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	serve := func(caller *common.Address, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if caller != nil {
+			req = req.WithContext(context.WithValue(req.Context(), callerKey{}, *caller))
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+	alice := common.HexToAddress("0x1")
+	assert.Equal(t, http.StatusOK, serve(&alice, "/photos").Code)
+	recorder := serve(&alice, "/videos")
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, serve(nil, "/photos").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(nil, "/photos").Code)
+}
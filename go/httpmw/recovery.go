@@ -0,0 +1,41 @@
+package httpmw
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+// RecoveryHandler writes the response of a request whose handler panicked with p, stack is the stack trace of the
+// panic
+type RecoveryHandler func(w http.ResponseWriter, r *http.Request, p interface{}, stack []byte)
+
+// DefaultRecoveryHandler logs the panic and its stack trace with the logger of the log package and writes a 500
+// Internal Server Error, without the details of the panic which are only logged
+func DefaultRecoveryHandler(w http.ResponseWriter, r *http.Request, p interface{}, stack []byte) {
+	log.Errorf("http %s %s: panic: %v\n%s", r.Method, r.URL.RequestURI(), p, stack)
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// Recovery recovers the panics of the handlers and writes the response of the handler, DefaultRecoveryHandler if
+// nil. The http.ErrAbortHandler panics aborting a response are not recovered.
+func Recovery(handler RecoveryHandler) Middleware {
+	if handler == nil {
+		handler = DefaultRecoveryHandler
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if p := recover(); p != nil {
+					if err, ok := p.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+						panic(p)
+					}
+					handler(w, r, p, debug.Stack())
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
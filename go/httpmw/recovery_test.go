@@ -0,0 +1,48 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+func TestRecovery(t *testing.T) {
+	logger := &recordLogger{}
+	log.SetLogger(logger)
+	defer log.SetLogger(log.NopLogger{})
+
+	handler := Recovery(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/abort" {
+			panic(http.ErrAbortHandler)
+		}
+		panic("boom")
+	}))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/object", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "boom")
+	require.Len(t, logger.errors, 1)
+	assert.Contains(t, logger.errors[0], "http GET /object: panic: boom")
+	assert.Contains(t, logger.errors[0], "runtime/debug.Stack")
+
+	assert.PanicsWithError(t, http.ErrAbortHandler.Error(), func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+
+	var recovered interface{}
+	handler = Recovery(func(w http.ResponseWriter, _ *http.Request, p interface{}, _ []byte) {
+		recovered = p
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(42)
+	}))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, 42, recovered)
+}
@@ -0,0 +1,32 @@
+package httpmw
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+// HeaderRequestID is the header of the ID of a request, set on the responses
+const HeaderRequestID = requestid.HTTPHeader
+
+// RequestIDFromContext return the ID of the request set by the RequestID middleware
+func RequestIDFromContext(ctx context.Context) string {
+	return requestid.FromContext(ctx)
+}
+
+// RequestID sets the ID of the requests in their context and in the HeaderRequestID header of their responses,
+// the ID given by the client or a proxy in the HeaderRequestID or traceparent header of the request or a new random
+// ID
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok := requestid.ExtractHTTP(r.Header)
+			if !ok {
+				id = requestid.New()
+			}
+			w.Header().Set(HeaderRequestID, id)
+			next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+		})
+	}
+}
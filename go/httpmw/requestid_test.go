@@ -0,0 +1,40 @@
+package httpmw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/requestid"
+)
+
+func TestRequestID(t *testing.T) {
+	var id string
+	handler := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = RequestIDFromContext(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, recorder.Header().Get(HeaderRequestID))
+
+	for _, invalid := range []string{"with space", "line\nbreak", strings.Repeat("a", requestid.MaxLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(HeaderRequestID, invalid)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		assert.NotEqual(t, invalid, id)
+		assert.Len(t, id, 32)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderRequestID, "gateway-42")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "gateway-42", id)
+	assert.Equal(t, "gateway-42", recorder.Header().Get(HeaderRequestID))
+	assert.Equal(t, "", RequestIDFromContext(req.Context()))
+}
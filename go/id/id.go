@@ -0,0 +1,228 @@
+// Package id generates the IDs of the jobs, the tasks and the requests of the SP services, so they are sortable by
+// creation time and never collide across the machines of a deployment:
+//
+//   - ID is a snowflake-style 64-bit ID made of the milliseconds since Epoch, the machine ID and a sequence number,
+//     compact enough for the database keys
+//   - UUID is a version 7 UUID of RFC 9562 made of the Unix milliseconds, a counter and random bits, to interoperate
+//     with the tools expecting UUIDs or W3C trace IDs
+//
+// The machine ID of the default Generator is read from MachineIDEnv, or derived from the host name.
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+const (
+	// MachineIDEnv is the environment variable of the machine ID of the default Generator
+	MachineIDEnv = "MECHAIN_MACHINE_ID"
+	// MaxMachineID is the largest machine ID
+	MaxMachineID = 1<<machineBits - 1
+
+	machineBits  = 10
+	sequenceBits = 12
+	maxSequence  = 1<<sequenceBits - 1
+)
+
+// Epoch is the origin of the timestamps of the IDs, they overflow 69 years later
+var Epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ErrInvalidID is returned when an ID or a UUID can not be parsed
+var ErrInvalidID = errors.New("invalid id")
+
+var logger = log.Module("id")
+
+// ID is a snowflake-style ID, IDs generated later by a Generator are greater
+type ID uint64
+
+// Time return the time when the ID was generated, truncated to the millisecond
+func (id ID) Time() time.Time {
+	return Epoch.Add(time.Duration(id>>(machineBits+sequenceBits)) * time.Millisecond)
+}
+
+// Machine return the machine ID of the Generator of the ID
+func (id ID) Machine() uint16 {
+	return uint16(id>>sequenceBits) & MaxMachineID
+}
+
+// String return the ID in 16 hex characters, the strings sort like the IDs
+func (id ID) String() string {
+	return fmt.Sprintf("%016x", uint64(id))
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (id *ID) UnmarshalText(text []byte) error {
+	parsed, err := ParseID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// ParseID parses the string of an ID
+func ParseID(s string) (ID, error) {
+	if len(s) != 16 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	return ID(v), nil
+}
+
+// Generator generates the IDs and the UUIDs of a machine. When the clock moves backward, or more IDs are generated
+// in a millisecond than the sequence holds, the IDs keep increasing from the last timestamp. It is safe for
+// concurrent use.
+type Generator struct {
+	machine uint16
+	clock   clock.Clock
+
+	mu       sync.Mutex
+	last     int64
+	sequence uint16
+	uuidLast int64
+	counter  uint16
+}
+
+// Option configures a Generator
+type Option func(*Generator)
+
+// WithClock reads the time from c instead of the system clock, e.g. a clock.Fake in tests
+func WithClock(c clock.Clock) Option {
+	return func(g *Generator) {
+		g.clock = c
+	}
+}
+
+// NewGenerator return a Generator of the machine, the machine IDs of the generators running at the same time must
+// be unique and at most MaxMachineID
+func NewGenerator(machineID uint16, opts ...Option) (*Generator, error) {
+	if machineID > MaxMachineID {
+		return nil, fmt.Errorf("machine id %d is larger than %d", machineID, MaxMachineID)
+	}
+	g := &Generator{machine: machineID, clock: clock.Real()}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g, nil
+}
+
+// Machine return the machine ID of the generator
+func (g *Generator) Machine() uint16 {
+	return g.machine
+}
+
+// Next return a new ID
+func (g *Generator) Next() ID {
+	ms := max(g.clock.Now().Sub(Epoch).Milliseconds(), 0)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if ms <= g.last {
+		ms = g.last
+		if g.sequence == maxSequence {
+			ms++
+			g.sequence = 0
+		} else {
+			g.sequence++
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.last = ms
+	return ID(ms)<<(machineBits+sequenceBits) | ID(g.machine)<<sequenceBits | ID(g.sequence)
+}
+
+// NextUUID return a new version 7 UUID, its 12 bits following the timestamp are a counter starting at a random
+// value in every millisecond, so the UUIDs of a generator are increasing
+func (g *Generator) NextUUID() UUID {
+	ms := max(g.clock.Now().UnixMilli(), 0)
+	var u UUID
+	_, _ = rand.Read(u[6:])
+	g.mu.Lock()
+	if ms <= g.uuidLast && g.counter < maxSequence {
+		ms = g.uuidLast
+		g.counter++
+	} else {
+		if ms <= g.uuidLast {
+			ms = g.uuidLast + 1
+		}
+		// half of the counter is left to the UUIDs following in the millisecond
+		g.counter = binary.BigEndian.Uint16(u[6:8]) & (maxSequence >> 1)
+	}
+	g.uuidLast = ms
+	counter := g.counter
+	g.mu.Unlock()
+
+	binary.BigEndian.PutUint16(u[4:6], uint16(ms))
+	binary.BigEndian.PutUint32(u[0:4], uint32(ms>>16))
+	binary.BigEndian.PutUint16(u[6:8], 7<<12|counter)
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// MachineID return the machine ID of MachineIDEnv if it is set, or a hash of the host name
+func MachineID() (uint16, error) {
+	if s, ok := os.LookupEnv(MachineIDEnv); ok {
+		v, err := strconv.ParseUint(s, 10, 16)
+		if err != nil || v > MaxMachineID {
+			return 0, fmt.Errorf("invalid %s %q, expect an integer between 0 and %d", MachineIDEnv, s, MaxMachineID)
+		}
+		return uint16(v), nil
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(hostname))
+	return uint16(h.Sum32() % (MaxMachineID + 1)), nil
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultGenerator *Generator
+)
+
+// Default return the Generator of New and NewUUID, its machine ID is the one of MachineID, or a random one if
+// MachineID fails
+func Default() *Generator {
+	defaultOnce.Do(func() {
+		machineID, err := MachineID()
+		if err != nil {
+			var b [2]byte
+			_, _ = rand.Read(b[:])
+			machineID = binary.BigEndian.Uint16(b[:]) & MaxMachineID
+			logger.Warnf("%v, using the random machine id %d", err, machineID)
+		}
+		defaultGenerator, _ = NewGenerator(machineID)
+	})
+	return defaultGenerator
+}
+
+// New return a new ID of the default Generator
+func New() ID {
+	return Default().Next()
+}
+
+// NewUUID return a new UUID of the default Generator
+func NewUUID() UUID {
+	return Default().NextUUID()
+}
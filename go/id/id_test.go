@@ -0,0 +1,99 @@
+package id
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+)
+
+func TestGenerator(t *testing.T) {
+	c := clock.NewFake(Epoch.Add(time.Hour))
+	g, err := NewGenerator(5, WithClock(c))
+	require.NoError(t, err)
+
+	first := g.Next()
+	assert.Equal(t, Epoch.Add(time.Hour), first.Time())
+	assert.Equal(t, uint16(5), first.Machine())
+	assert.Equal(t, first+1, g.Next())
+
+	c.Advance(time.Millisecond)
+	next := g.Next()
+	assert.Equal(t, Epoch.Add(time.Hour+time.Millisecond), next.Time())
+	assert.Less(t, first.String(), next.String())
+
+	// the IDs keep increasing when the clock moves backward or the sequence overflows
+	backward := clock.NewFake(Epoch.Add(time.Hour))
+	g, err = NewGenerator(5, WithClock(backward))
+	require.NoError(t, err)
+	last := g.Next()
+	for i := 0; i < 2*maxSequence; i++ {
+		next := g.Next()
+		assert.Greater(t, next, last)
+		last = next
+	}
+	assert.Equal(t, Epoch.Add(time.Hour+time.Millisecond), last.Time())
+
+	_, err = NewGenerator(MaxMachineID + 1)
+	assert.Error(t, err)
+}
+
+func TestGeneratorConcurrent(t *testing.T) {
+	g, err := NewGenerator(1)
+	require.NoError(t, err)
+	var mu sync.Mutex
+	seen := make(map[ID]struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := g.Next()
+				mu.Lock()
+				seen[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 8000)
+}
+
+func TestParseID(t *testing.T) {
+	id := New()
+	parsed, err := ParseID(id.String())
+	require.NoError(t, err)
+	assert.Equal(t, id, parsed)
+	assert.Len(t, id.String(), 16)
+
+	data, err := json.Marshal(map[string]ID{"id": id})
+	require.NoError(t, err)
+	var decoded map[string]ID
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, id, decoded["id"])
+
+	_, err = ParseID("123")
+	assert.ErrorIs(t, err, ErrInvalidID)
+	_, err = ParseID("zzzzzzzzzzzzzzzz")
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
+
+func TestMachineID(t *testing.T) {
+	t.Setenv(MachineIDEnv, "42")
+	machineID, err := MachineID()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(42), machineID)
+
+	t.Setenv(MachineIDEnv, "1024")
+	_, err = MachineID()
+	assert.Error(t, err)
+	t.Setenv(MachineIDEnv, "sp")
+	_, err = MachineID()
+	assert.Error(t, err)
+}
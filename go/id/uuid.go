@@ -0,0 +1,75 @@
+package id
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// UUID is an RFC 9562 UUID
+type UUID [16]byte
+
+// Version return the version of the UUID, 7 for the UUIDs of a Generator
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// Time return the time of a version 7 UUID, truncated to the millisecond
+func (u UUID) Time() time.Time {
+	ms := int64(binary.BigEndian.Uint32(u[0:4]))<<16 | int64(binary.BigEndian.Uint16(u[4:6]))
+	return time.UnixMilli(ms)
+}
+
+// String return the UUID in its canonical form, e.g. 018f3f6e-7a3c-7d2e-9b1a-0c4f5e6d7a8b
+func (u UUID) String() string {
+	var b [36]byte
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b[:])
+}
+
+// Hex return the UUID in 32 hex characters, the form of the W3C trace IDs
+func (u UUID) Hex() string {
+	return hex.EncodeToString(u[:])
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (u *UUID) UnmarshalText(text []byte) error {
+	parsed, err := ParseUUID(string(text))
+	if err != nil {
+		return err
+	}
+	*u = parsed
+	return nil
+}
+
+// ParseUUID parses a UUID in its canonical form or in 32 hex characters
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	var digits string
+	switch {
+	case len(s) == 32:
+		digits = s
+	case len(s) == 36 && s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-':
+		digits = s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	default:
+		return u, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	if _, err := hex.Decode(u[:], []byte(digits)); err != nil {
+		return u, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	return u, nil
+}
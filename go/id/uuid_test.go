@@ -0,0 +1,61 @@
+package id
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+)
+
+func TestNextUUID(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	c := clock.NewFake(now)
+	g, err := NewGenerator(0, WithClock(c))
+	require.NoError(t, err)
+
+	u := g.NextUUID()
+	assert.Equal(t, 7, u.Version())
+	assert.Equal(t, byte(0x80), u[8]&0xc0)
+	assert.True(t, now.Equal(u.Time()))
+
+	// the UUIDs keep increasing within a millisecond and past the overflow of the counter
+	last := u
+	for i := 0; i < 2*maxSequence; i++ {
+		next := g.NextUUID()
+		assert.Less(t, last.String(), next.String())
+		last = next
+	}
+	assert.True(t, last.Time().After(now))
+
+	c.Advance(time.Second)
+	assert.True(t, now.Add(time.Second).Equal(g.NextUUID().Time()))
+}
+
+func TestParseUUID(t *testing.T) {
+	u := NewUUID()
+	assert.Len(t, u.String(), 36)
+	assert.Len(t, u.Hex(), 32)
+	for _, s := range []string{u.String(), u.Hex()} {
+		parsed, err := ParseUUID(s)
+		require.NoError(t, err)
+		assert.Equal(t, u, parsed)
+	}
+
+	parsed, err := ParseUUID("018bcfe5-6800-7000-8000-000000000000")
+	require.NoError(t, err)
+	assert.Equal(t, 7, parsed.Version())
+	assert.Equal(t, "018bcfe5-6800-7000-8000-000000000000", parsed.String())
+
+	for _, s := range []string{"", "018bcfe5-6800-7000-8000", "018bcfe5x6800-7000-8000-000000000000",
+		"zz8bcfe5-6800-7000-8000-000000000000"} {
+		_, err = ParseUUID(s)
+		assert.ErrorIs(t, err, ErrInvalidID, s)
+	}
+
+	var decoded UUID
+	require.NoError(t, decoded.UnmarshalText([]byte(u.String())))
+	assert.Equal(t, u, decoded)
+}
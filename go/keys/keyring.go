@@ -0,0 +1,123 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrKeyNotFound is returned when a key ring holds no key of an address
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeyRing holds the private keys of several accounts in memory and return their signers by address. It is safe for
+// concurrent use.
+type KeyRing struct {
+	mu      sync.RWMutex
+	signers map[common.Address]*PrivateKeySigner
+}
+
+// NewKeyRing return an empty KeyRing
+func NewKeyRing() *KeyRing {
+	return &KeyRing{signers: make(map[common.Address]*PrivateKeySigner)}
+}
+
+// Add adds the private key to the key ring and return its address
+func (r *KeyRing) Add(privateKey *ecdsa.PrivateKey) common.Address {
+	signer := NewPrivateKeySigner(privateKey)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.signers[signer.Address()] = signer
+	return signer.Address()
+}
+
+// Generate adds a new random private key to the key ring and return its address
+func (r *KeyRing) Generate() (common.Address, error) {
+	privateKey, err := crypto.GenerateKey()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return r.Add(privateKey), nil
+}
+
+// Import adds the private key of the keystore encrypted with the password and return its address
+func (r *KeyRing) Import(keystore *KeystoreJSON, password string) (common.Address, error) {
+	privateKey, err := DecryptKey(keystore, password)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return r.Add(privateKey), nil
+}
+
+// ImportDir adds the private keys of the keystore files of the directory encrypted with the password and return
+// their addresses
+func (r *KeyRing) ImportDir(dir, password string) ([]common.Address, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []common.Address
+	for _, entry := range entries {
+		if entry.IsDir() || !isKeyFile(entry.Name()) {
+			continue
+		}
+		privateKey, err := ReadKeyFile(filepath.Join(dir, entry.Name()), password)
+		if err != nil {
+			return addresses, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		addresses = append(addresses, r.Add(privateKey))
+	}
+	return addresses, nil
+}
+
+// Export return the keystore of the private key of the address encrypted with the password
+func (r *KeyRing) Export(address common.Address, password string, opts ...KeystoreOption) (*KeystoreJSON, error) {
+	signer, err := r.signer(address)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptKey(signer.privateKey, password, opts...)
+}
+
+// Remove removes the private key of the address from the key ring
+func (r *KeyRing) Remove(address common.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.signers, address)
+}
+
+// Addresses return the addresses of the keys of the key ring in ascending order
+func (r *KeyRing) Addresses() []common.Address {
+	r.mu.RLock()
+	addresses := make([]common.Address, 0, len(r.signers))
+	for address := range r.signers {
+		addresses = append(addresses, address)
+	}
+	r.mu.RUnlock()
+	sort.Slice(addresses, func(i, j int) bool {
+		return bytes.Compare(addresses[i][:], addresses[j][:]) < 0
+	})
+	return addresses
+}
+
+// Signer return the signer of the key of the address, or ErrKeyNotFound
+func (r *KeyRing) Signer(address common.Address) (Signer, error) {
+	return r.signer(address)
+}
+
+func (r *KeyRing) signer(address common.Address) (*PrivateKeySigner, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	signer, ok := r.signers[address]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, address)
+	}
+	return signer, nil
+}
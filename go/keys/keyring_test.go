@@ -0,0 +1,69 @@
+package keys
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/eip712"
+)
+
+func TestKeyRing(t *testing.T) {
+	ring := NewKeyRing()
+	first, err := ring.Generate()
+	require.NoError(t, err)
+	second, err := ring.Generate()
+	require.NoError(t, err)
+	addresses := ring.Addresses()
+	assert.Len(t, addresses, 2)
+	assert.ElementsMatch(t, []common.Address{first, second}, addresses)
+
+	keystore, err := ring.Export(first, "password", WithScrypt(1<<10, 1))
+	require.NoError(t, err)
+	ring.Remove(first)
+	_, err = ring.Signer(first)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+	imported, err := ring.Import(keystore, "password")
+	require.NoError(t, err)
+	assert.Equal(t, first, imported)
+
+	dir := t.TempDir()
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = WriteKeyFile(dir, privateKey, "password", WithScrypt(1<<10, 1))
+	require.NoError(t, err)
+	loaded, err := ring.ImportDir(dir, "password")
+	require.NoError(t, err)
+	assert.Equal(t, []common.Address{crypto.PubkeyToAddress(privateKey.PublicKey)}, loaded)
+	assert.Len(t, ring.Addresses(), 3)
+	_, err = ring.ImportDir(dir, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+}
+
+func TestSigner(t *testing.T) {
+	ring := NewKeyRing()
+	address, err := ring.Generate()
+	require.NoError(t, err)
+	signer, err := ring.Signer(address)
+	require.NoError(t, err)
+	assert.Equal(t, address, signer.Address())
+
+	digest := crypto.Keccak256([]byte("message"))
+	sig, err := signer.SignDigest(digest)
+	require.NoError(t, err)
+	pubKey, err := crypto.SigToPub(digest, sig)
+	require.NoError(t, err)
+	assert.Equal(t, address, crypto.PubkeyToAddress(*pubKey))
+	_, err = signer.SignDigest(digest[1:])
+	assert.Error(t, err)
+
+	domain := eip712.NewDomain(big.NewInt(5151))
+	msg := &eip712.MigrateBucketApproval{Operator: address, BucketName: "bucket", DstPrimarySPID: 2, ExpiredHeight: 100}
+	sig, err = signer.SignTypedData(domain, msg)
+	require.NoError(t, err)
+	assert.NoError(t, eip712.Verify(address, domain, msg, sig))
+}
@@ -0,0 +1,308 @@
+// Package keys manages the secp256k1 account keys of the tools and the services built on the chain: it reads and
+// writes them in the Web3 Secret Storage keystore format of the wallets, keeps them in key rings and signs digests
+// and EIP-712 typed data with them, either locally or through a remote signing service keeping the keys, e.g. in an
+// HSM.
+package keys
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KeystoreVersion is the version of the Web3 Secret Storage keystores
+	KeystoreVersion = 3
+	// StandardScryptN and StandardScryptP are the scrypt params of the keystores of the wallets
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+	// LightScryptN and LightScryptP are scrypt params using less memory and time, e.g. for the keys of services
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	kdfScrypt      = "scrypt"
+	kdfPBKDF2      = "pbkdf2"
+	cipherAES128   = "aes-128-ctr"
+	derivedKeySize = 32
+	saltSize       = 32
+	scryptR        = 8
+)
+
+var (
+	// ErrInvalidKeystore is returned when a keystore is malformed or uses unsupported functions
+	ErrInvalidKeystore = errors.New("invalid keystore")
+	// ErrInvalidPassword is returned when the password of a keystore does not match its mac
+	ErrInvalidPassword = errors.New("invalid keystore password")
+)
+
+// CryptoJSON is the encrypted private key of a keystore
+type CryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams CipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+// CipherParamsJSON are the params of the cipher of a keystore
+type CipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// KeystoreJSON is a private key encrypted with a password in the Web3 Secret Storage format
+type KeystoreJSON struct {
+	Address string     `json:"address"`
+	Crypto  CryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type keystoreOptions struct {
+	kdf         string
+	scryptN     int
+	scryptP     int
+	pbkdf2Count int
+}
+
+// KeystoreOption configures the encryption of a keystore
+type KeystoreOption func(*keystoreOptions)
+
+// WithScrypt derives the encryption key with scrypt of cost n and parallelization p, StandardScryptN and
+// StandardScryptP by default
+func WithScrypt(n, p int) KeystoreOption {
+	return func(o *keystoreOptions) {
+		o.kdf, o.scryptN, o.scryptP = kdfScrypt, n, p
+	}
+}
+
+// WithPBKDF2 derives the encryption key with pbkdf2-sha256 of count iterations instead of scrypt
+func WithPBKDF2(count int) KeystoreOption {
+	return func(o *keystoreOptions) {
+		o.kdf, o.pbkdf2Count = kdfPBKDF2, count
+	}
+}
+
+// EncryptKey return the keystore of the private key encrypted with the password
+func EncryptKey(privateKey *ecdsa.PrivateKey, password string, opts ...KeystoreOption) (*KeystoreJSON, error) {
+	options := keystoreOptions{kdf: kdfScrypt, scryptN: StandardScryptN, scryptP: StandardScryptP}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	random := make([]byte, saltSize+aes.BlockSize+16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	salt, iv, id := random[:saltSize], random[saltSize:saltSize+aes.BlockSize], random[saltSize+aes.BlockSize:]
+
+	kdfParams := map[string]interface{}{"dklen": derivedKeySize, "salt": hex.EncodeToString(salt)}
+	if options.kdf == kdfScrypt {
+		kdfParams["n"], kdfParams["r"], kdfParams["p"] = options.scryptN, scryptR, options.scryptP
+	} else {
+		kdfParams["c"], kdfParams["prf"] = options.pbkdf2Count, "hmac-sha256"
+	}
+	derivedKey, err := deriveKey(options.kdf, kdfParams, password)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := aes128CTR(derivedKey[:16], iv, crypto.FromECDSA(privateKey))
+	if err != nil {
+		return nil, err
+	}
+	// set the version and the variant of a random UUID
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return &KeystoreJSON{
+		Address: hex.EncodeToString(crypto.PubkeyToAddress(privateKey.PublicKey).Bytes()),
+		Crypto: CryptoJSON{
+			Cipher:       cipherAES128,
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: CipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          options.kdf,
+			KDFParams:    kdfParams,
+			MAC:          hex.EncodeToString(crypto.Keccak256(derivedKey[16:32], cipherText)),
+		},
+		ID:      fmt.Sprintf("%x-%x-%x-%x-%x", id[:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: KeystoreVersion,
+	}, nil
+}
+
+// DecryptKey return the private key of the keystore encrypted with the password
+func DecryptKey(keystore *KeystoreJSON, password string) (*ecdsa.PrivateKey, error) {
+	if keystore.Version != KeystoreVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrInvalidKeystore, keystore.Version)
+	}
+	if keystore.Crypto.Cipher != cipherAES128 {
+		return nil, fmt.Errorf("%w: cipher %q", ErrInvalidKeystore, keystore.Crypto.Cipher)
+	}
+	mac, err := hex.DecodeString(keystore.Crypto.MAC)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mac: %w", ErrInvalidKeystore, err)
+	}
+	cipherText, err := hex.DecodeString(keystore.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ciphertext: %w", ErrInvalidKeystore, err)
+	}
+	iv, err := hex.DecodeString(keystore.Crypto.CipherParams.IV)
+	if err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("%w: cipher iv", ErrInvalidKeystore)
+	}
+	derivedKey, err := deriveKey(keystore.Crypto.KDF, keystore.Crypto.KDFParams, password)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(mac, crypto.Keccak256(derivedKey[16:32], cipherText)) {
+		return nil, ErrInvalidPassword
+	}
+	secret, err := aes128CTR(derivedKey[:16], iv, cipherText)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := crypto.ToECDSA(common.LeftPadBytes(secret, 32))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeystore, err)
+	}
+	if keystore.Address != "" &&
+		common.HexToAddress(keystore.Address) != crypto.PubkeyToAddress(privateKey.PublicKey) {
+		return nil, fmt.Errorf("%w: address does not match the private key", ErrInvalidKeystore)
+	}
+	return privateKey, nil
+}
+
+// ParseKeystore parses the json of a keystore
+func ParseKeystore(data []byte) (*KeystoreJSON, error) {
+	keystore := new(KeystoreJSON)
+	if err := json.Unmarshal(data, keystore); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeystore, err)
+	}
+	return keystore, nil
+}
+
+// KeyFileName return the name of the keystore file of the address created at the time, like the wallets name them
+func KeyFileName(address common.Address, t time.Time) string {
+	ts := t.UTC().Format("2006-01-02T15-04-05.000000000Z")
+	return fmt.Sprintf("UTC--%s--%s", ts, hex.EncodeToString(address[:]))
+}
+
+// WriteKeyFile encrypts the private key with the password into a new keystore file in the directory, readable by its
+// owner only, and return the path of the file
+func WriteKeyFile(dir string, privateKey *ecdsa.PrivateKey, password string, opts ...KeystoreOption) (string,
+	error,
+) {
+	keystore, err := EncryptKey(privateKey, password, opts...)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(keystore)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, KeyFileName(crypto.PubkeyToAddress(privateKey.PublicKey), time.Now()))
+	// write a temporary file renamed once complete so a crash leaves no partial keystore
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return "", err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadKeyFile return the private key of the keystore file encrypted with the password
+func ReadKeyFile(path, password string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keystore, err := ParseKeystore(data)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(keystore, password)
+}
+
+// deriveKey return the key derived from the password by the kdf of a keystore
+func deriveKey(kdf string, params map[string]interface{}, password string) ([]byte, error) {
+	saltHex, _ := params["salt"].(string)
+	salt, err := hex.DecodeString(saltHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: kdf salt", ErrInvalidKeystore)
+	}
+	if intParam(params["dklen"]) != derivedKeySize {
+		return nil, fmt.Errorf("%w: kdf dklen %v", ErrInvalidKeystore, params["dklen"])
+	}
+	switch kdf {
+	case kdfScrypt:
+		derivedKey, err := scrypt.Key([]byte(password), salt, intParam(params["n"]), intParam(params["r"]),
+			intParam(params["p"]), derivedKeySize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidKeystore, err)
+		}
+		return derivedKey, nil
+	case kdfPBKDF2:
+		c := intParam(params["c"])
+		if prf, _ := params["prf"].(string); prf != "hmac-sha256" || c < 1 {
+			return nil, fmt.Errorf("%w: pbkdf2 prf %q, count %v", ErrInvalidKeystore, prf, params["c"])
+		}
+		return pbkdf2.Key([]byte(password), salt, c, derivedKeySize, sha256.New), nil
+	default:
+		return nil, fmt.Errorf("%w: kdf %q", ErrInvalidKeystore, kdf)
+	}
+}
+
+// intParam return the integer kdf param, given as an int or, once parsed from json, as a float64
+func intParam(param interface{}) int {
+	switch v := param.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+func aes128CTR(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(data))
+	cipher.NewCTR(block, iv).XORKeyStream(out, data)
+	return out, nil
+}
+
+// isKeyFile reports whether the file name is the name of a keystore file, not of a temporary or hidden file
+func isKeyFile(name string) bool {
+	return !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, "~")
+}
@@ -0,0 +1,101 @@
+package keys
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the test vectors of the Web3 Secret Storage definition, encrypting
+// 7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d with "testpassword"
+var testVectors = map[string]string{
+	"scrypt": `{"crypto": {"cipher": "aes-128-ctr", "cipherparams": {"iv": "83dbcc02d8ccb40e466191a123791e0e"},
+		"ciphertext": "d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c", "kdf": "scrypt",
+		"kdfparams": {"dklen": 32, "n": 262144, "r": 1, "p": 8,
+		"salt": "ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},
+		"mac": "2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6", "version": 3}`,
+	"pbkdf2": `{"crypto": {"cipher": "aes-128-ctr", "cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+		"ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46", "kdf": "pbkdf2",
+		"kdfparams": {"c": 262144, "dklen": 32, "prf": "hmac-sha256",
+		"salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},
+		"mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},
+		"id": "3198bc9c-6672-5ab3-d995-4942343ae5b6", "version": 3}`,
+}
+
+func TestDecryptTestVectors(t *testing.T) {
+	for name, vector := range testVectors {
+		keystore, err := ParseKeystore([]byte(vector))
+		require.NoError(t, err, name)
+		privateKey, err := DecryptKey(keystore, "testpassword")
+		require.NoError(t, err, name)
+		assert.Equal(t, "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d",
+			hex.EncodeToString(crypto.FromECDSA(privateKey)), name)
+		_, err = DecryptKey(keystore, "wrongpassword")
+		assert.ErrorIs(t, err, ErrInvalidPassword, name)
+	}
+}
+
+func TestEncryptKey(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	for _, opt := range []KeystoreOption{WithScrypt(1<<10, 1), WithPBKDF2(1000)} {
+		keystore, err := EncryptKey(privateKey, "password", opt)
+		require.NoError(t, err)
+		assert.Equal(t, KeystoreVersion, keystore.Version)
+		assert.Len(t, keystore.ID, 36)
+
+		data, err := json.Marshal(keystore)
+		require.NoError(t, err)
+		parsed, err := ParseKeystore(data)
+		require.NoError(t, err)
+		decrypted, err := DecryptKey(parsed, "password")
+		require.NoError(t, err)
+		assert.Equal(t, crypto.FromECDSA(privateKey), crypto.FromECDSA(decrypted))
+	}
+
+	keystore, err := EncryptKey(privateKey, "password", WithScrypt(1<<10, 1))
+	require.NoError(t, err)
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := *keystore
+	address.Address = hex.EncodeToString(crypto.PubkeyToAddress(other.PublicKey).Bytes())
+	_, err = DecryptKey(&address, "password")
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+	version := *keystore
+	version.Version = 1
+	_, err = DecryptKey(&version, "password")
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+	kdf := *keystore
+	kdf.Crypto.KDF = "argon2"
+	_, err = DecryptKey(&kdf, "password")
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+	_, err = EncryptKey(privateKey, "password", WithScrypt(1000, 1))
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+	_, err = ParseKeystore([]byte("{"))
+	assert.ErrorIs(t, err, ErrInvalidKeystore)
+}
+
+func TestKeyFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keystore")
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	path, err := WriteKeyFile(dir, privateKey, "password", WithScrypt(1<<10, 1))
+	require.NoError(t, err)
+	assert.Contains(t, filepath.Base(path), hex.EncodeToString(crypto.PubkeyToAddress(privateKey.PublicKey).Bytes()))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	decrypted, err := ReadKeyFile(path, "password")
+	require.NoError(t, err)
+	assert.Equal(t, crypto.FromECDSA(privateKey), crypto.FromECDSA(decrypted))
+	_, err = ReadKeyFile(path, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+}
@@ -0,0 +1,299 @@
+package keys
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/zkMeLabs/mechain-common/go/canonicaljson"
+	"github.com/zkMeLabs/mechain-common/go/eip712"
+	commonhttp "github.com/zkMeLabs/mechain-common/go/http"
+)
+
+const (
+	// SignPath is the path of the signing service signing a digest
+	SignPath = "/v1/sign"
+	// DefaultRequestTimeout is the timeout of the requests of a RemoteSigner
+	DefaultRequestTimeout = 10 * time.Second
+	// requestExpiry is the validity of the authorization of the requests of a RemoteSigner
+	requestExpiry = time.Minute
+	// maxRequestSize bounds the body of the requests read by a SignerHandler
+	maxRequestSize = 1 << 20
+)
+
+var (
+	// ErrRemoteSigner is returned when the signing service fails or rejects a request
+	ErrRemoteSigner = errors.New("remote signer error")
+	// ErrSignerMismatch is returned when a signature returned by the signing service is not signed by the address
+	ErrSignerMismatch = errors.New("signature is not signed by the signer address")
+)
+
+// SignRequest is the body of the requests to the signing service
+type SignRequest struct {
+	Address common.Address `json:"address"`
+	Digest  hexutil.Bytes  `json:"digest"`
+	// TypedData is the EIP-712 typed data whose digest is signed, if any, so the service can check what it signs
+	TypedData json.RawMessage `json:"typed_data,omitempty"`
+}
+
+// SignResponse is the body of the responses of the signing service
+type SignResponse struct {
+	// Signature is the [R||S||V] signature of the digest, V is 0 or 1
+	Signature hexutil.Bytes `json:"signature,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// RemoteSigner is a Signer whose key is kept by a signing service, e.g. in front of an HSM, served by a
+// SignerHandler. It checks the signatures returned by the service are signed by its address.
+type RemoteSigner struct {
+	endpoint  string
+	address   common.Address
+	client    *http.Client
+	timeout   time.Duration
+	callerKey *ecdsa.PrivateKey
+}
+
+// RemoteOption configures a RemoteSigner
+type RemoteOption func(*RemoteSigner)
+
+// WithHTTPClient sends the requests with the client, e.g. configured with mTLS, http.DefaultClient by default
+func WithHTTPClient(client *http.Client) RemoteOption {
+	return func(s *RemoteSigner) {
+		s.client = client
+	}
+}
+
+// WithRequestTimeout sets the timeout of the requests, DefaultRequestTimeout by default
+func WithRequestTimeout(timeout time.Duration) RemoteOption {
+	return func(s *RemoteSigner) {
+		s.timeout = timeout
+	}
+}
+
+// WithCallerKey signs the requests with the key in the GNFD1-ECDSA auth type, so the service can authorize the caller
+func WithCallerKey(privateKey *ecdsa.PrivateKey) RemoteOption {
+	return func(s *RemoteSigner) {
+		s.callerKey = privateKey
+	}
+}
+
+// NewRemoteSigner return a Signer signing with the key of the address kept by the signing service at the endpoint,
+// e.g. "https://signer.internal:9000"
+func NewRemoteSigner(endpoint string, address common.Address, opts ...RemoteOption) *RemoteSigner {
+	s := &RemoteSigner{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		address:  address,
+		client:   http.DefaultClient,
+		timeout:  DefaultRequestTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Address implements Signer
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// SignDigest implements Signer
+func (s *RemoteSigner) SignDigest(digest []byte) ([]byte, error) {
+	if len(digest) != common.HashLength {
+		return nil, fmt.Errorf("digest must be %d bytes, got %d", common.HashLength, len(digest))
+	}
+	return s.sign(&SignRequest{Address: s.address, Digest: digest})
+}
+
+// SignTypedData implements Signer
+func (s *RemoteSigner) SignTypedData(domain eip712.Domain, msg eip712.Message) ([]byte, error) {
+	digest, err := eip712.Digest(domain, msg)
+	if err != nil {
+		return nil, err
+	}
+	typedData, err := eip712.TypedDataJSON(domain, msg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.sign(&SignRequest{Address: s.address, Digest: digest, TypedData: typedData})
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// sign sends the request to the signing service and return the signature of the digest once checked
+func (s *RemoteSigner) sign(signReq *SignRequest) ([]byte, error) {
+	// the typed data is sent in its canonical form, without the html escaping of encoding/json
+	body, err := canonicaljson.Marshal(signReq)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint+SignPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(commonhttp.HTTPHeaderContentType, "application/json")
+	if s.callerKey != nil {
+		sum := sha256.Sum256(body)
+		req.Header.Set(commonhttp.HTTPHeaderContentSHA256, hex.EncodeToString(sum[:]))
+		if err = commonhttp.SignRequestECDSA(req, s.callerKey, time.Now().Add(requestExpiry)); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteSigner, err)
+	}
+	defer resp.Body.Close()
+	var signResp SignResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxRequestSize)).Decode(&signResp); err != nil {
+		return nil, fmt.Errorf("%w: status %d: %w", ErrRemoteSigner, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d: %s", ErrRemoteSigner, resp.StatusCode, signResp.Error)
+	}
+	if len(signResp.Signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("%w: signature length %d", ErrRemoteSigner, len(signResp.Signature))
+	}
+	pubKey, err := crypto.SigToPub(signReq.Digest, signResp.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRemoteSigner, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != s.address {
+		return nil, fmt.Errorf("%w: signed by %s instead of %s", ErrSignerMismatch, signer, s.address)
+	}
+	return signResp.Signature, nil
+}
+
+// SignerSource return the signers of the keys kept by a signing service, e.g. a KeyRing or the keys of an HSM
+type SignerSource interface {
+	Signer(address common.Address) (Signer, error)
+}
+
+// SignPolicy checks a request before it is signed, e.g. which typed data a key may sign, and the address of the
+// caller, zero when the requests are not authorized
+type SignPolicy func(caller common.Address, req *SignRequest) error
+
+// SignerHandler is the http.Handler of a signing service, signing the digests of the requests of the RemoteSigners
+// with the keys of a SignerSource
+type SignerHandler struct {
+	signers SignerSource
+	callers map[common.Address]struct{}
+	policy  SignPolicy
+	now     func() time.Time
+}
+
+// HandlerOption configures a SignerHandler
+type HandlerOption func(*SignerHandler)
+
+// WithAuthorizedCallers only serves the requests signed by the keys of the callers, see WithCallerKey
+func WithAuthorizedCallers(callers ...common.Address) HandlerOption {
+	return func(h *SignerHandler) {
+		h.callers = make(map[common.Address]struct{}, len(callers))
+		for _, caller := range callers {
+			h.callers[caller] = struct{}{}
+		}
+	}
+}
+
+// WithSignPolicy checks the requests with the policy before they are signed
+func WithSignPolicy(policy SignPolicy) HandlerOption {
+	return func(h *SignerHandler) {
+		h.policy = policy
+	}
+}
+
+// NewSignerHandler return the handler of a signing service signing with the keys of the signers
+func NewSignerHandler(signers SignerSource, opts ...HandlerOption) *SignerHandler {
+	h := &SignerHandler{signers: signers, now: time.Now}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *SignerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != SignPath {
+		writeSignResponse(w, http.StatusNotFound, SignResponse{Error: "not found"})
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeSignResponse(w, http.StatusMethodNotAllowed, SignResponse{Error: "method not allowed"})
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		writeSignResponse(w, http.StatusBadRequest, SignResponse{Error: err.Error()})
+		return
+	}
+	caller, err := h.authorize(r, body)
+	if err != nil {
+		writeSignResponse(w, http.StatusUnauthorized, SignResponse{Error: err.Error()})
+		return
+	}
+	var signReq SignRequest
+	if err = json.Unmarshal(body, &signReq); err != nil || len(signReq.Digest) != common.HashLength {
+		writeSignResponse(w, http.StatusBadRequest, SignResponse{Error: "invalid sign request"})
+		return
+	}
+	signer, err := h.signers.Signer(signReq.Address)
+	if err != nil {
+		writeSignResponse(w, http.StatusNotFound, SignResponse{Error: err.Error()})
+		return
+	}
+	if h.policy != nil {
+		if err = h.policy(caller, &signReq); err != nil {
+			writeSignResponse(w, http.StatusForbidden, SignResponse{Error: err.Error()})
+			return
+		}
+	}
+	sig, err := signer.SignDigest(signReq.Digest)
+	if err != nil {
+		writeSignResponse(w, http.StatusInternalServerError, SignResponse{Error: err.Error()})
+		return
+	}
+	writeSignResponse(w, http.StatusOK, SignResponse{Signature: sig})
+}
+
+// authorize return the caller of the request if the handler only serves authorized callers
+func (h *SignerHandler) authorize(r *http.Request, body []byte) (common.Address, error) {
+	if h.callers == nil {
+		return common.Address{}, nil
+	}
+	sum := sha256.Sum256(body)
+	if r.Header.Get(commonhttp.HTTPHeaderContentSHA256) != hex.EncodeToString(sum[:]) {
+		return common.Address{}, errors.New("content sha256 does not match the body")
+	}
+	caller, err := commonhttp.VerifyRequestECDSA(r, h.now())
+	if err != nil {
+		return common.Address{}, err
+	}
+	if _, ok := h.callers[caller]; !ok {
+		return common.Address{}, fmt.Errorf("caller %s is not authorized", caller)
+	}
+	return caller, nil
+}
+
+func writeSignResponse(w http.ResponseWriter, status int, resp SignResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
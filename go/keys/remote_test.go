@@ -0,0 +1,113 @@
+package keys
+
+import (
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/eip712"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	ring := NewKeyRing()
+	address, err := ring.Generate()
+	require.NoError(t, err)
+	var typedData int
+	server := httptest.NewServer(NewSignerHandler(ring, WithSignPolicy(func(_ common.Address, req *SignRequest) error {
+		if len(req.TypedData) > 0 {
+			typedData++
+		}
+		return nil
+	})))
+	defer server.Close()
+
+	signer := NewRemoteSigner(server.URL+"/", address)
+	assert.Equal(t, address, signer.Address())
+	digest := crypto.Keccak256([]byte("message"))
+	sig, err := signer.SignDigest(digest)
+	require.NoError(t, err)
+	pubKey, err := crypto.SigToPub(digest, sig)
+	require.NoError(t, err)
+	assert.Equal(t, address, crypto.PubkeyToAddress(*pubKey))
+
+	domain := eip712.NewDomain(big.NewInt(5151))
+	msg := &eip712.MigrateBucketApproval{Operator: address, BucketName: "bucket", DstPrimarySPID: 2, ExpiredHeight: 100}
+	sig, err = signer.SignTypedData(domain, msg)
+	require.NoError(t, err)
+	assert.NoError(t, eip712.Verify(address, domain, msg, sig))
+	assert.Equal(t, 1, typedData)
+
+	unknown := NewRemoteSigner(server.URL, common.HexToAddress("0x01"))
+	_, err = unknown.SignDigest(digest)
+	assert.ErrorIs(t, err, ErrRemoteSigner)
+	_, err = signer.SignDigest(digest[1:])
+	assert.Error(t, err)
+}
+
+func TestRemoteSignerMismatch(t *testing.T) {
+	ring := NewKeyRing()
+	address, err := ring.Generate()
+	require.NoError(t, err)
+	other, err := ring.Generate()
+	require.NoError(t, err)
+	otherSigner, err := ring.Signer(other)
+	require.NoError(t, err)
+	// a misconfigured service signing with another key
+	server := httptest.NewServer(NewSignerHandler(signerSourceFunc(func(common.Address) (Signer, error) {
+		return otherSigner, nil
+	})))
+	defer server.Close()
+
+	_, err = NewRemoteSigner(server.URL, address).SignDigest(crypto.Keccak256([]byte("message")))
+	assert.ErrorIs(t, err, ErrSignerMismatch)
+}
+
+func TestSignerHandlerAuthorization(t *testing.T) {
+	ring := NewKeyRing()
+	address, err := ring.Generate()
+	require.NoError(t, err)
+	callerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	strangerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	errDenied := errors.New("denied")
+	server := httptest.NewServer(NewSignerHandler(ring,
+		WithAuthorizedCallers(crypto.PubkeyToAddress(callerKey.PublicKey)),
+		WithSignPolicy(func(caller common.Address, req *SignRequest) error {
+			if caller != crypto.PubkeyToAddress(callerKey.PublicKey) || req.Digest[0] == 0 {
+				return errDenied
+			}
+			return nil
+		})))
+	defer server.Close()
+	digest := crypto.Keccak256([]byte("message"))
+	digest[0] = 1
+
+	_, err = NewRemoteSigner(server.URL, address, WithCallerKey(callerKey)).SignDigest(digest)
+	assert.NoError(t, err)
+	_, err = NewRemoteSigner(server.URL, address).SignDigest(digest)
+	assert.ErrorContains(t, err, "status 401")
+	_, err = NewRemoteSigner(server.URL, address, WithCallerKey(strangerKey)).SignDigest(digest)
+	assert.ErrorContains(t, err, "status 401")
+	digest[0] = 0
+	_, err = NewRemoteSigner(server.URL, address, WithCallerKey(callerKey)).SignDigest(digest)
+	assert.ErrorContains(t, err, "status 403")
+
+	resp, err := http.Get(server.URL + SignPath)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+type signerSourceFunc func(common.Address) (Signer, error)
+
+func (f signerSourceFunc) Signer(address common.Address) (Signer, error) {
+	return f(address)
+}
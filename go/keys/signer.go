@@ -0,0 +1,51 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/zkMeLabs/mechain-common/go/eip712"
+)
+
+// Signer signs with the private key of an account, e.g. kept in memory or in a remote signing service
+type Signer interface {
+	// Address return the address of the account
+	Address() common.Address
+	// SignDigest return the [R||S||V] signature of the 32 bytes digest, V is 0 or 1
+	SignDigest(digest []byte) ([]byte, error)
+	// SignTypedData return the [R||S||V] signature of the EIP-712 digest of the message in the domain, V is 27 or
+	// 28 like the signatures of the wallets
+	SignTypedData(domain eip712.Domain, msg eip712.Message) ([]byte, error)
+}
+
+// PrivateKeySigner is a Signer holding the private key in memory
+type PrivateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+// NewPrivateKeySigner return a Signer signing with the private key
+func NewPrivateKeySigner(privateKey *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{privateKey: privateKey, address: crypto.PubkeyToAddress(privateKey.PublicKey)}
+}
+
+// Address implements Signer
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+// SignDigest implements Signer
+func (s *PrivateKeySigner) SignDigest(digest []byte) ([]byte, error) {
+	if len(digest) != common.HashLength {
+		return nil, fmt.Errorf("digest must be %d bytes, got %d", common.HashLength, len(digest))
+	}
+	return crypto.Sign(digest, s.privateKey)
+}
+
+// SignTypedData implements Signer
+func (s *PrivateKeySigner) SignTypedData(domain eip712.Domain, msg eip712.Message) ([]byte, error) {
+	return eip712.Sign(s.privateKey, domain, msg)
+}
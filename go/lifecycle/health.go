@@ -0,0 +1,88 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// DefaultCheckTimeout bounds the time the health checks of the services are given by the readiness endpoint
+const DefaultCheckTimeout = 5 * time.Second
+
+// Status is the health of a service reported by the readiness endpoint
+type Status struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Live reports whether the daemon is alive, i.e. its services are not stopping
+func (m *Manager) Live() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return !m.stopping
+}
+
+// Ready checks the health of the services and reports whether they can all serve: every service must be started
+// and pass its health check, see HealthChecker. The statuses are sorted by service name.
+func (m *Manager) Ready(ctx context.Context) (bool, []Status) {
+	m.mu.Lock()
+	started := make(map[string]Service, len(m.started))
+	for _, service := range m.started {
+		started[service.Name()] = service
+	}
+	names := append([]string(nil), m.names...)
+	stopping := m.stopping
+	m.mu.Unlock()
+
+	sort.Strings(names)
+	ready := !stopping
+	statuses := make([]Status, 0, len(names))
+	for _, name := range names {
+		status := Status{Name: name, Healthy: true}
+		service, ok := started[name]
+		switch {
+		case !ok:
+			status.Healthy, status.Error = false, "not started"
+		default:
+			if checker, ok := service.(HealthChecker); ok {
+				if err := checker.Healthy(ctx); err != nil {
+					status.Healthy, status.Error = false, err.Error()
+				}
+			}
+		}
+		ready = ready && status.Healthy
+		statuses = append(statuses, status)
+	}
+	return ready, statuses
+}
+
+// Handler return the HTTP handler of the probes of the daemon: /livez answers 200 while the services are not
+// stopping and /readyz answers 200 once they are all started and healthy, with their statuses in JSON, and 503
+// otherwise
+func (m *Manager) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livez", func(w http.ResponseWriter, _ *http.Request) {
+		if !m.Live() {
+			http.Error(w, "stopping", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), DefaultCheckTimeout)
+		defer cancel()
+		ready, statuses := m.Ready(ctx)
+		w.Header().Set("Content-Type", "application/json")
+		if !ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(struct {
+			Ready    bool     `json:"ready"`
+			Services []Status `json:"services"`
+		}{Ready: ready, Services: statuses})
+	})
+	return mux
+}
@@ -0,0 +1,66 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+type checkedService struct {
+	Service
+	err error
+}
+
+func (s *checkedService) Healthy(context.Context) error {
+	return s.err
+}
+
+func TestHandler(t *testing.T) {
+	ctx := context.Background()
+	db := &checkedService{Service: NewService("db", nil, nil)}
+	m := NewManager(WithLogger(log.NopLogger{}))
+	require.NoError(t, m.Register(db))
+	require.NoError(t, m.Register(NewService("server", nil, nil), "db"))
+	handler := m.Handler()
+	serve := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+	readiness := func() (int, []Status) {
+		recorder := serve("/readyz")
+		var body struct {
+			Ready    bool     `json:"ready"`
+			Services []Status `json:"services"`
+		}
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&body))
+		assert.Equal(t, recorder.Code == http.StatusOK, body.Ready)
+		return recorder.Code, body.Services
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/livez").Code)
+	code, statuses := readiness()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, []Status{{Name: "db", Error: "not started"}, {Name: "server", Error: "not started"}}, statuses)
+
+	require.NoError(t, m.Start(ctx))
+	code, statuses = readiness()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []Status{{Name: "db", Healthy: true}, {Name: "server", Healthy: true}}, statuses)
+
+	db.err = errors.New("connection refused")
+	code, statuses = readiness()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, Status{Name: "db", Error: "connection refused"}, statuses[0])
+
+	require.NoError(t, m.Stop(ctx))
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/livez").Code)
+}
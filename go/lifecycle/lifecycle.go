@@ -0,0 +1,283 @@
+// Package lifecycle starts and stops the services of a daemon, e.g. its gRPC server, its piece store and its
+// background jobs, in the order of their dependencies, and serves their readiness and liveness:
+//
+//	m := lifecycle.NewManager()
+//	_ = m.Register(store)
+//	_ = m.Register(server, store.Name())
+//	http.Handle("/", m.Handler())
+//	err := m.Run(ctx)
+//
+// Run starts the services, every service after its dependencies, waits for SIGINT or SIGTERM and stops them in the
+// reverse order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+var (
+	// ErrDuplicateService is returned when a service is registered twice under the same name
+	ErrDuplicateService = errors.New("duplicate service")
+	// ErrUnknownDependency is returned when a service depends on a service which is not registered
+	ErrUnknownDependency = errors.New("unknown dependency")
+	// ErrDependencyCycle is returned when the dependencies of the services form a cycle
+	ErrDependencyCycle = errors.New("dependency cycle")
+	// ErrAlreadyStarted is returned when a Manager is started twice or registers a service once started
+	ErrAlreadyStarted = errors.New("services already started")
+)
+
+// DefaultStopTimeout bounds the time Run gives the services to stop
+const DefaultStopTimeout = 30 * time.Second
+
+// Service is a component of a daemon
+type Service interface {
+	// Name return the name of the service, unique in a Manager
+	Name() string
+	// Start starts the service, it must return once the service is ready to serve and leave it running in the
+	// background. ctx is done when the start is given up.
+	Start(ctx context.Context) error
+	// Stop stops the service and releases its resources, ctx is done when the stop should be cut short
+	Stop(ctx context.Context) error
+}
+
+// HealthChecker is implemented by the services which can check their health, e.g. ping their database. A started
+// service without it is healthy.
+type HealthChecker interface {
+	// Healthy return an error if the service can not serve
+	Healthy(ctx context.Context) error
+}
+
+// funcService adapts funcs to a Service
+type funcService struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func(ctx context.Context) error
+}
+
+// NewService return a Service named name running start and stop, either may be nil
+func NewService(name string, start, stop func(ctx context.Context) error) Service {
+	return &funcService{name: name, start: start, stop: stop}
+}
+
+func (s *funcService) Name() string {
+	return s.name
+}
+
+func (s *funcService) Start(ctx context.Context) error {
+	if s.start == nil {
+		return nil
+	}
+	return s.start(ctx)
+}
+
+func (s *funcService) Stop(ctx context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+	return s.stop(ctx)
+}
+
+// Option configures a Manager
+type Option func(*Manager)
+
+// WithStopTimeout bounds the time Run gives the services to stop, DefaultStopTimeout by default
+func WithStopTimeout(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.stopTimeout = d
+		}
+	}
+}
+
+// WithSignals stops the services run by Run on the signals instead of SIGINT and SIGTERM
+func WithSignals(signals ...os.Signal) Option {
+	return func(m *Manager) {
+		m.signals = signals
+	}
+}
+
+// WithLogger logs the starts and the stops of the services to l instead of the package level logger of the log
+// package
+func WithLogger(l log.Logger) Option {
+	return func(m *Manager) {
+		if l != nil {
+			m.logger = l
+		}
+	}
+}
+
+// entry is a registered service
+type entry struct {
+	service   Service
+	dependsOn []string
+}
+
+// Manager starts and stops the registered services in the order of their dependencies. It is safe for concurrent
+// use.
+type Manager struct {
+	stopTimeout time.Duration
+	signals     []os.Signal
+	logger      log.Logger
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	names    []string
+	started  []Service
+	starting bool
+	stopping bool
+}
+
+// NewManager return a Manager without service
+func NewManager(opts ...Option) *Manager {
+	m := &Manager{
+		stopTimeout: DefaultStopTimeout,
+		signals:     []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		logger:      log.GetLogger(),
+		entries:     make(map[string]*entry),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Register registers the service, started after the services named by dependsOn and stopped before them. The
+// dependencies need not be registered yet, they are resolved by Start.
+func (m *Manager) Register(service Service, dependsOn ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.starting {
+		return ErrAlreadyStarted
+	}
+	name := service.Name()
+	if _, ok := m.entries[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateService, name)
+	}
+	m.entries[name] = &entry{service: service, dependsOn: dependsOn}
+	m.names = append(m.names, name)
+	return nil
+}
+
+// order return the services sorted so every service follows its dependencies, the services are otherwise kept in
+// the order of their registration. The lock must be held.
+func (m *Manager) order() ([]Service, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	marks := make(map[string]int, len(m.entries))
+	ordered := make([]Service, 0, len(m.entries))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		e, ok := m.entries[name]
+		if !ok {
+			return fmt.Errorf("%w: %s of %s", ErrUnknownDependency, name, path[len(path)-1])
+		}
+		switch marks[name] {
+		case visiting:
+			return fmt.Errorf("%w: %v", ErrDependencyCycle, append(path, name))
+		case visited:
+			return nil
+		}
+		marks[name] = visiting
+		for _, dependency := range e.dependsOn {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		marks[name] = visited
+		ordered = append(ordered, e.service)
+		return nil
+	}
+	for _, name := range m.names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Start starts the services in the order of their dependencies. If a service fails to start, the services already
+// started are stopped in the reverse order and the error of the service is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	if m.starting {
+		m.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	ordered, err := m.order()
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.starting = true
+	m.mu.Unlock()
+
+	for _, service := range ordered {
+		m.logger.Infof("starting service %s", service.Name())
+		if err := service.Start(ctx); err != nil {
+			m.logger.Errorf("failed to start service %s: %s", service.Name(), err)
+			if stopErr := m.Stop(context.WithoutCancel(ctx)); stopErr != nil {
+				err = errors.Join(err, stopErr)
+			}
+			return fmt.Errorf("start service %s: %w", service.Name(), err)
+		}
+		m.mu.Lock()
+		stopping := m.stopping
+		if !stopping {
+			m.started = append(m.started, service)
+		}
+		m.mu.Unlock()
+		if stopping {
+			// Stop was called while the service was starting
+			return errors.Join(errors.New("services stopped while starting"), service.Stop(ctx))
+		}
+	}
+	return nil
+}
+
+// Stop stops the started services in the reverse order of their start, every service is stopped even if another one
+// fails to and the errors are joined
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	m.stopping = true
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		service := started[i]
+		m.logger.Infof("stopping service %s", service.Name())
+		if err := service.Stop(ctx); err != nil {
+			m.logger.Errorf("failed to stop service %s: %s", service.Name(), err)
+			errs = append(errs, fmt.Errorf("stop service %s: %w", service.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run starts the services, waits until ctx is done or a signal of the manager is received, then stops the services
+// within the stop timeout. It return the error of the start or of the stop.
+func (m *Manager) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, m.signals...)
+	defer stop()
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	m.logger.Infof("stopping services: %s", context.Cause(ctx))
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.stopTimeout)
+	defer cancel()
+	return m.Stop(stopCtx)
+}
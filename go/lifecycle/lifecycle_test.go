@@ -0,0 +1,92 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+// recordService records its starts and stops in events
+func recordService(name string, events *[]string, startErr, stopErr error) Service {
+	return NewService(name, func(context.Context) error {
+		*events = append(*events, "start "+name)
+		return startErr
+	}, func(context.Context) error {
+		*events = append(*events, "stop "+name)
+		return stopErr
+	})
+}
+
+func TestManager(t *testing.T) {
+	ctx := context.Background()
+	var events []string
+	m := NewManager(WithLogger(log.NopLogger{}))
+	require.NoError(t, m.Register(recordService("server", &events, nil, nil), "store", "db"))
+	require.NoError(t, m.Register(recordService("store", &events, nil, nil), "db"))
+	require.NoError(t, m.Register(recordService("db", &events, nil, nil)))
+	assert.ErrorIs(t, m.Register(recordService("db", &events, nil, nil)), ErrDuplicateService)
+
+	require.NoError(t, m.Start(ctx))
+	assert.ErrorIs(t, m.Start(ctx), ErrAlreadyStarted)
+	assert.ErrorIs(t, m.Register(recordService("cache", &events, nil, nil)), ErrAlreadyStarted)
+	require.NoError(t, m.Stop(ctx))
+	assert.Equal(t, []string{"start db", "start store", "start server", "stop server", "stop store", "stop db"}, events)
+}
+
+func TestManagerStartFailure(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	var events []string
+	m := NewManager(WithLogger(log.NopLogger{}))
+	require.NoError(t, m.Register(recordService("db", &events, nil, errFailed)))
+	require.NoError(t, m.Register(recordService("store", &events, nil, nil), "db"))
+	require.NoError(t, m.Register(recordService("server", &events, errFailed, nil), "store"))
+
+	err := m.Start(ctx)
+	assert.ErrorIs(t, err, errFailed)
+	assert.ErrorContains(t, err, "start service server")
+	assert.ErrorContains(t, err, "stop service db")
+	assert.Equal(t, []string{"start db", "start store", "start server", "stop store", "stop db"}, events)
+}
+
+func TestManagerDependencies(t *testing.T) {
+	m := NewManager(WithLogger(log.NopLogger{}))
+	require.NoError(t, m.Register(NewService("a", nil, nil), "b"))
+	require.NoError(t, m.Register(NewService("b", nil, nil), "c"))
+	assert.ErrorIs(t, m.Start(context.Background()), ErrUnknownDependency)
+
+	require.NoError(t, m.Register(NewService("c", nil, nil), "a"))
+	err := m.Start(context.Background())
+	assert.ErrorIs(t, err, ErrDependencyCycle)
+	assert.ErrorContains(t, err, "[a b c a]")
+}
+
+func TestManagerRun(t *testing.T) {
+	var events []string
+	stopped := make(chan struct{})
+	m := NewManager(WithLogger(log.NopLogger{}), WithSignals(syscall.SIGUSR1), WithStopTimeout(time.Second))
+	require.NoError(t, m.Register(recordService("db", &events, nil, nil)))
+	require.NoError(t, m.Register(NewService("server", func(context.Context) error {
+		go func() {
+			_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+		}()
+		return nil
+	}, func(ctx context.Context) error {
+		_, ok := ctx.Deadline()
+		assert.True(t, ok)
+		close(stopped)
+		return nil
+	}), "db"))
+
+	require.NoError(t, m.Run(context.Background()))
+	<-stopped
+	assert.Equal(t, []string{"start db", "stop db"}, events)
+	assert.False(t, m.Live())
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+)
+
+// maxLevelsSize bounds the size of the levels put to LevelHandler
+const maxLevelsSize = 64 << 10
+
+// LevelHandler return the HTTP handler of the levels of the modules: GET answers the levels in the format of
+// ParseLevels and PUT replaces them by the levels of the body, e.g.
+//
+//	curl -X PUT -d 'info,hash=debug' http://localhost:9090/debug/log/levels
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			spec, err := io.ReadAll(io.LimitReader(r.Body, maxLevelsSize))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			levels, err := ParseLevels(string(spec))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			SetLevels(levels)
+			Infof("log levels set to %s", levels)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, GetLevels().String()+"\n")
+	})
+}
+
+// WatchLevels reloads the levels of the modules on SIGHUP until ctx is done: load return them in the format of
+// ParseLevels, e.g. from the configuration file of the service. load may also reopen the log files, see
+// RotatingFile.Reopen. The levels are kept if load fails. It does nothing on the platforms without SIGHUP.
+func WatchLevels(ctx context.Context, load func() (string, error)) {
+	if len(reloadSignals) == 0 {
+		return
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, reloadSignals...)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			}
+			spec, err := load()
+			if err != nil {
+				Errorf("failed to reload the log levels: %s", err)
+				continue
+			}
+			levels, err := ParseLevels(spec)
+			if err != nil {
+				Errorf("failed to reload the log levels: %s", err)
+				continue
+			}
+			SetLevels(levels)
+			Infof("log levels reloaded: %s", levels)
+		}
+	}()
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	defer SetLevels(Levels{})
+	handler := LevelHandler()
+	serve := func(method, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "/", strings.NewReader(body)))
+		return recorder
+	}
+
+	assert.Equal(t, "debug\n", serve(http.MethodGet, "").Body.String())
+	recorder := serve(http.MethodPut, "info,hash=debug")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "info,hash=debug\n", recorder.Body.String())
+	assert.Equal(t, LevelDebug, LevelOf("hash"))
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "hash=loud").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodDelete, "").Code)
+	assert.Equal(t, "info,hash=debug\n", serve(http.MethodGet, "").Body.String())
+}
+
+func TestWatchLevels(t *testing.T) {
+	defer SetLevels(Levels{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	loaded := make(chan struct{})
+	WatchLevels(ctx, func() (string, error) {
+		defer close(loaded)
+		return "error,hash=info", nil
+	})
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+	select {
+	case <-loaded:
+	case <-time.After(5 * time.Second):
+		t.Fatal("levels not reloaded")
+	}
+	assert.Eventually(t, func() bool {
+		return LevelOf("hash") == LevelInfo
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, LevelError, LevelOf("piecestore"))
+}
@@ -0,0 +1,223 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Level is the severity of a message
+type Level int
+
+const (
+	// LevelDebug is the level of the messages useful to debug
+	LevelDebug Level = iota
+	// LevelInfo is the level of the messages about the normal operation
+	LevelInfo
+	// LevelWarn is the level of the messages about unexpected but handled conditions
+	LevelWarn
+	// LevelError is the level of the messages about failed operations
+	LevelError
+	// LevelOff disables all the messages
+	LevelOff
+)
+
+var levelNames = []string{"debug", "info", "warn", "error", "off"}
+
+// String implements fmt.Stringer
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelOff {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// ParseLevel return the level of its name, e.g. "info", "warning" is accepted for LevelWarn
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return LevelWarn, nil
+	}
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q", name)
+}
+
+// Levels are the levels of the modules, the messages of a module below its level are discarded by its Module logger
+type Levels struct {
+	// Default is the level of the modules without level
+	Default Level
+	// Modules are the levels of the modules by name. The level of a module without level is the level of its closest
+	// parent, e.g. "redundancy" for "redundancy/erasure", or Default.
+	Modules map[string]Level
+}
+
+// ParseLevels parses the levels from a comma separated list of a default level and of levels of modules, e.g.
+// "info,hash=debug,redundancy/erasure=error". The default level is LevelDebug if it is not specified.
+func ParseLevels(spec string) (Levels, error) {
+	levels := Levels{Default: LevelDebug, Modules: make(map[string]Level)}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		module, name, ok := strings.Cut(item, "=")
+		if !ok {
+			module, name = "", module
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return Levels{}, err
+		}
+		module = strings.TrimSpace(module)
+		if module == "" {
+			levels.Default = level
+			continue
+		}
+		levels.Modules[module] = level
+	}
+	return levels, nil
+}
+
+// String return the levels in the format of ParseLevels, the modules sorted by name
+func (l Levels) String() string {
+	items := []string{l.Default.String()}
+	modules := make([]string, 0, len(l.Modules))
+	for module := range l.Modules {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	for _, module := range modules {
+		items = append(items, module+"="+l.Modules[module].String())
+	}
+	return strings.Join(items, ",")
+}
+
+// levelOf return the level of the module
+func (l Levels) levelOf(module string) Level {
+	for {
+		if level, ok := l.Modules[module]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(module, '/')
+		if i < 0 {
+			return l.Default
+		}
+		module = module[:i]
+	}
+}
+
+var (
+	levelsMu sync.RWMutex
+	levels   = Levels{Default: LevelDebug, Modules: map[string]Level{}}
+)
+
+// SetLevels replaces the levels of the modules, it can be called at runtime, see LevelHandler and WatchLevels
+func SetLevels(l Levels) {
+	l = l.clone()
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	levels = l
+}
+
+// SetLevel sets the level of the module, or the default level if module is empty
+func SetLevel(module string, level Level) {
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	if module == "" {
+		levels.Default = level
+		return
+	}
+	l := levels.clone()
+	l.Modules[module] = level
+	levels = l
+}
+
+// GetLevels return the levels of the modules, all the messages pass by default
+func GetLevels() Levels {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	return levels.clone()
+}
+
+// clone return a copy of the levels, the levels in use are never modified
+func (l Levels) clone() Levels {
+	modules := make(map[string]Level, len(l.Modules))
+	for module, level := range l.Modules {
+		modules[module] = level
+	}
+	return Levels{Default: l.Default, Modules: modules}
+}
+
+// LevelOf return the current level of the module
+func LevelOf(module string) Level {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	return levels.levelOf(module)
+}
+
+type moduleLogger struct {
+	name string
+}
+
+// Module return the logger of a module, e.g. "hash" or "redundancy/erasure": it discards the messages below the level
+// of the module, see SetLevels, and passes the others prefixed by the name of the module to the package level logger
+func Module(name string) Logger {
+	return &moduleLogger{name: name}
+}
+
+func (l *moduleLogger) logf(level Level, format string, args []interface{}) {
+	if level < LevelOf(l.name) {
+		return
+	}
+	logger := GetLogger()
+	if _, ok := logger.(NopLogger); ok {
+		return
+	}
+	msg := l.name + ": " + fmt.Sprintf(format, args...)
+	switch level {
+	case LevelDebug:
+		logger.Debugf("%s", msg)
+	case LevelInfo:
+		logger.Infof("%s", msg)
+	case LevelWarn:
+		logger.Warnf("%s", msg)
+	default:
+		logger.Errorf("%s", msg)
+	}
+}
+
+func (l *moduleLogger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, format, args)
+}
+
+func (l *moduleLogger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, format, args)
+}
+
+func (l *moduleLogger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, format, args)
+}
+
+func (l *moduleLogger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, format, args)
+}
@@ -0,0 +1,76 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type allLogger struct {
+	messages []string
+}
+
+func (l *allLogger) Debugf(format string, args ...interface{}) {
+	l.messages = append(l.messages, "DEBUG "+fmt.Sprintf(format, args...))
+}
+
+func (l *allLogger) Infof(format string, args ...interface{}) {
+	l.messages = append(l.messages, "INFO "+fmt.Sprintf(format, args...))
+}
+
+func (l *allLogger) Warnf(format string, args ...interface{}) {
+	l.messages = append(l.messages, "WARN "+fmt.Sprintf(format, args...))
+}
+
+func (l *allLogger) Errorf(format string, args ...interface{}) {
+	l.messages = append(l.messages, "ERROR "+fmt.Sprintf(format, args...))
+}
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels(" warning, hash=debug,redundancy/erasure=ERROR ,")
+	require.NoError(t, err)
+	assert.Equal(t, Levels{Default: LevelWarn, Modules: map[string]Level{"hash": LevelDebug,
+		"redundancy/erasure": LevelError}}, levels)
+	assert.Equal(t, "warn,hash=debug,redundancy/erasure=error", levels.String())
+
+	levels, err = ParseLevels("")
+	require.NoError(t, err)
+	assert.Equal(t, "debug", levels.String())
+	_, err = ParseLevels("hash=verbose")
+	assert.EqualError(t, err, `unknown log level "verbose"`)
+
+	var level Level
+	require.NoError(t, level.UnmarshalText([]byte("off")))
+	assert.Equal(t, LevelOff, level)
+	assert.Equal(t, "level(7)", Level(7).String())
+}
+
+func TestModule(t *testing.T) {
+	defer SetLogger(nil)
+	defer SetLevels(Levels{})
+	l := &allLogger{}
+	SetLogger(l)
+	levels, err := ParseLevels("info,redundancy=warn,redundancy/erasure=debug")
+	require.NoError(t, err)
+	SetLevels(levels)
+
+	hash, redundancy := Module("hash"), Module("redundancy/segment")
+	erasure := Module("redundancy/erasure")
+	hash.Debugf("dropped")
+	hash.Infof("hashed %d segments", 2)
+	redundancy.Infof("dropped")
+	redundancy.Warnf("corrupted shard %d", 1)
+	erasure.Debugf("encoded")
+	assert.Equal(t, []string{"INFO hash: hashed 2 segments", "WARN redundancy/segment: corrupted shard 1",
+		"DEBUG redundancy/erasure: encoded"}, l.messages)
+
+	SetLevel("hash", LevelOff)
+	SetLevel("", LevelError)
+	assert.Equal(t, LevelOff, LevelOf("hash"))
+	assert.Equal(t, LevelError, LevelOf("piecestore"))
+	hash.Errorf("dropped")
+	assert.Len(t, l.messages, 3)
+	assert.Equal(t, LevelInfo, levels.Default, "SetLevel must not modify the levels passed to SetLevels")
+}
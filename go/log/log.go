@@ -0,0 +1,102 @@
+// Package log defines the Logger used by the packages of mechain-common. Nothing is logged by default,
+// call SetLogger with an adapter such as NewSlogLogger or zerologger.New to integrate with the logger of a service:
+//
+//	f, err := log.NewRotatingFile("/var/log/sp.log", log.WithMaxBackups(10))
+//	log.SetLogger(log.Sample(zerologger.New(zerolog.New(f)), 100, time.Second))
+//
+// The packages log through the logger of their module, see Module, whose level can be changed at runtime by
+// SetLevels, LevelHandler or WatchLevels, e.g. "info,hash=debug".
+package log
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Logger is the logging interface consumed by the packages of mechain-common
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type loggerHolder struct {
+	Logger
+}
+
+var logger atomic.Value
+
+func init() {
+	logger.Store(loggerHolder{NopLogger{}})
+}
+
+// SetLogger replaces the package level logger, a nil logger disables logging
+func SetLogger(l Logger) {
+	if l == nil {
+		l = NopLogger{}
+	}
+	logger.Store(loggerHolder{l})
+}
+
+// GetLogger return the package level logger
+func GetLogger() Logger {
+	return logger.Load().(loggerHolder).Logger
+}
+
+// Debugf logs a debug message by the package level logger
+func Debugf(format string, args ...interface{}) {
+	GetLogger().Debugf(format, args...)
+}
+
+// Infof logs an info message by the package level logger
+func Infof(format string, args ...interface{}) {
+	GetLogger().Infof(format, args...)
+}
+
+// Warnf logs a warning message by the package level logger
+func Warnf(format string, args ...interface{}) {
+	GetLogger().Warnf(format, args...)
+}
+
+// Errorf logs an error message by the package level logger
+func Errorf(format string, args ...interface{}) {
+	GetLogger().Errorf(format, args...)
+}
+
+// NopLogger discards all the messages
+type NopLogger struct{}
+
+func (NopLogger) Debugf(string, ...interface{}) {}
+
+func (NopLogger) Infof(string, ...interface{}) {}
+
+func (NopLogger) Warnf(string, ...interface{}) {}
+
+func (NopLogger) Errorf(string, ...interface{}) {}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger adapts a slog.Logger to Logger
+func NewSlogLogger(l *slog.Logger) Logger {
+	return &slogLogger{logger: l}
+}
+
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debug(fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.logger.Info(fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warn(fmt.Sprintf(format, args...))
+}
+
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Error(fmt.Sprintf(format, args...))
+}
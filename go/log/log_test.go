@@ -0,0 +1,44 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordLogger struct {
+	NopLogger
+	messages []string
+}
+
+func (l *recordLogger) Errorf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestSetLogger(t *testing.T) {
+	defer SetLogger(nil)
+
+	// the default logger discards all the messages
+	Errorf("discarded")
+
+	l := &recordLogger{}
+	SetLogger(l)
+	Errorf("failed to read: %s", "EOF")
+	Infof("ignored by recordLogger")
+	assert.Equal(t, []string{"failed to read: EOF"}, l.messages)
+
+	SetLogger(nil)
+	assert.Equal(t, NopLogger{}, GetLogger())
+}
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	l.Infof("filtered")
+	l.Errorf("encode fail: %d", 1)
+	assert.NotContains(t, buf.String(), "filtered")
+	assert.Contains(t, buf.String(), `level=ERROR msg="encode fail: 1"`)
+}
@@ -0,0 +1,206 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the format of the time of the rotation in the names of the backups, it sorts as the time
+const backupTimeFormat = "20060102T150405.000"
+
+// RotatingFile is a log file rotated when it reaches its maximum size: it is renamed to a backup named by the time of
+// the rotation, e.g. sp-20240102T150405.000.log for sp.log, and a new file is created. It is an io.WriteCloser to pass
+// to the sinks, e.g. zerolog.New(f) or slog.NewJSONHandler(f, nil), and is safe for concurrent use.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	now        func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// RotateOption configures a RotatingFile
+type RotateOption func(*RotatingFile)
+
+// WithMaxSize rotates the file when a write would make it larger than size bytes, 100 MiB by default. A size of 0
+// disables the rotation, the file can still be rotated by Rotate.
+func WithMaxSize(size int64) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxSize = size
+	}
+}
+
+// WithMaxBackups removes the oldest backups beyond n, all the backups are kept by default
+func WithMaxBackups(n int) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxBackups = n
+	}
+}
+
+// WithMaxAge removes the backups rotated for longer than age, all the backups are kept by default
+func WithMaxAge(age time.Duration) RotateOption {
+	return func(f *RotatingFile) {
+		f.maxAge = age
+	}
+}
+
+// NewRotatingFile opens the log file at path, appending to it if it exists
+func NewRotatingFile(path string, opts ...RotateOption) (*RotatingFile, error) {
+	f := &RotatingFile{path: filepath.Clean(path), maxSize: 100 << 20, now: time.Now}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write implements io.Writer, the file is rotated first if the write would make it larger than its maximum size
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate renames the file to a backup and creates a new file
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	if err := os.Rename(f.path, f.backupPath(f.now())); err != nil {
+		// keep writing to the file rather than losing the messages
+		return errors.Join(err, f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	return f.prune()
+}
+
+// Reopen closes and reopens the file, e.g. on SIGHUP once the file has been rotated by logrotate
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+		f.file = nil
+	}
+	return f.open()
+}
+
+// Close implements io.Closer
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// backupPath return the path of the backup rotated at t
+func (f *RotatingFile) backupPath(t time.Time) string {
+	ext := filepath.Ext(f.path)
+	return strings.TrimSuffix(f.path, ext) + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// Backups return the paths of the backups of the file, the oldest first
+func (f *RotatingFile) Backups() ([]string, error) {
+	ext := filepath.Ext(f.path)
+	prefix := filepath.Base(strings.TrimSuffix(f.path, ext)) + "-"
+	entries, err := os.ReadDir(filepath.Dir(f.path))
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err != nil {
+			continue
+		}
+		backups = append(backups, filepath.Join(filepath.Dir(f.path), name))
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// prune removes the backups beyond the maximum number of backups or older than the maximum age
+func (f *RotatingFile) prune() error {
+	if f.maxBackups <= 0 && f.maxAge <= 0 {
+		return nil
+	}
+	backups, err := f.Backups()
+	if err != nil {
+		return err
+	}
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	now := f.now()
+	for i, backup := range backups {
+		expired := f.maxBackups > 0 && i < len(backups)-f.maxBackups
+		if f.maxAge > 0 {
+			rotated, _ := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(backup, prefix), ext))
+			expired = expired || now.Sub(rotated) > f.maxAge
+		}
+		if !expired {
+			continue
+		}
+		if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove log backup: %w", err)
+		}
+	}
+	return nil
+}
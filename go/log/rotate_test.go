@@ -0,0 +1,68 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sp.log")
+	require.NoError(t, os.WriteFile(path, []byte("old\n"), 0o600))
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	f, err := NewRotatingFile(path, WithMaxSize(10), WithMaxBackups(2), WithMaxAge(time.Hour))
+	require.NoError(t, err)
+	f.now = func() time.Time { return now }
+	defer f.Close()
+
+	write := func(s string) {
+		_, err := f.Write([]byte(s))
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+	}
+	write("12345\n")
+	write("abc\n")
+	write("0123456789abcdef\n")
+	write("x\n")
+	backups, err := f.Backups()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "sp-20240102T150407.000.log"),
+		filepath.Join(dir, "sp-20240102T150408.000.log")}, backups)
+	content, err := os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Equal(t, "abc\n", string(content))
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "x\n", string(content))
+
+	now = now.Add(time.Hour)
+	require.NoError(t, f.Rotate())
+	backups, err = f.Backups()
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "sp-20240102T160409.000.log")}, backups)
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sp.log")
+	f, err := NewRotatingFile(path)
+	require.NoError(t, err)
+	_, err = f.Write([]byte("a\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Rename(path, path+".1"))
+	require.NoError(t, f.Reopen())
+	_, err = f.Write([]byte("b\n"))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	_, err = f.Write([]byte("c\n"))
+	assert.ErrorIs(t, err, os.ErrClosed)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "b\n", string(content))
+}
@@ -0,0 +1,117 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type multiLogger []Logger
+
+// Multi return a logger passing the messages to all the loggers, e.g. to a console and to a RotatingFile
+func Multi(loggers ...Logger) Logger {
+	return multiLogger(loggers)
+}
+
+func (m multiLogger) Debugf(format string, args ...interface{}) {
+	for _, l := range m {
+		l.Debugf(format, args...)
+	}
+}
+
+func (m multiLogger) Infof(format string, args ...interface{}) {
+	for _, l := range m {
+		l.Infof(format, args...)
+	}
+}
+
+func (m multiLogger) Warnf(format string, args ...interface{}) {
+	for _, l := range m {
+		l.Warnf(format, args...)
+	}
+}
+
+func (m multiLogger) Errorf(format string, args ...interface{}) {
+	for _, l := range m {
+		l.Errorf(format, args...)
+	}
+}
+
+type sampleKey struct {
+	level  Level
+	format string
+}
+
+type sampleCount struct {
+	start   time.Time
+	logged  int
+	dropped int
+}
+
+type sampler struct {
+	logger Logger
+	burst  int
+	period time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	counts map[sampleKey]*sampleCount
+}
+
+// Sample return a logger passing at most burst messages of the same level and format per period to l, e.g. to bound
+// the messages of a failure repeated for every request. The number of messages dropped in a period is reported with
+// the first message of the next period.
+func Sample(l Logger, burst int, period time.Duration) Logger {
+	return &sampler{logger: l, burst: burst, period: period, now: time.Now, counts: make(map[sampleKey]*sampleCount)}
+}
+
+func (s *sampler) allow(level Level, format string) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	key := sampleKey{level: level, format: format}
+	count, ok := s.counts[key]
+	if !ok {
+		count = &sampleCount{start: now}
+		s.counts[key] = count
+	}
+	dropped := 0
+	if now.Sub(count.start) >= s.period {
+		dropped = count.dropped
+		*count = sampleCount{start: now}
+	}
+	if count.logged >= s.burst {
+		count.dropped++
+		return false, 0
+	}
+	count.logged++
+	return true, dropped
+}
+
+func (s *sampler) logf(level Level, logf func(string, ...interface{}), format string, args []interface{}) {
+	ok, dropped := s.allow(level, format)
+	if !ok {
+		return
+	}
+	if dropped > 0 {
+		logf("%s (%d similar messages dropped)", fmt.Sprintf(format, args...), dropped)
+		return
+	}
+	logf(format, args...)
+}
+
+func (s *sampler) Debugf(format string, args ...interface{}) {
+	s.logf(LevelDebug, s.logger.Debugf, format, args)
+}
+
+func (s *sampler) Infof(format string, args ...interface{}) {
+	s.logf(LevelInfo, s.logger.Infof, format, args)
+}
+
+func (s *sampler) Warnf(format string, args ...interface{}) {
+	s.logf(LevelWarn, s.logger.Warnf, format, args)
+}
+
+func (s *sampler) Errorf(format string, args ...interface{}) {
+	s.logf(LevelError, s.logger.Errorf, format, args)
+}
@@ -0,0 +1,38 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSample(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := &allLogger{}
+	s := Sample(l, 2, time.Minute)
+	s.(*sampler).now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		s.Errorf("failed to put piece %d", i)
+		s.Warnf("slow")
+	}
+	s.Errorf("failed to get piece")
+	now = now.Add(time.Minute)
+	s.Errorf("failed to put piece %d", 5)
+	s.Warnf("slow")
+	assert.Equal(t, []string{"ERROR failed to put piece 0", "WARN slow", "ERROR failed to put piece 1", "WARN slow",
+		"ERROR failed to get piece", "ERROR failed to put piece 5 (3 similar messages dropped)",
+		"WARN slow (3 similar messages dropped)"}, l.messages)
+}
+
+func TestMulti(t *testing.T) {
+	l1, l2 := &allLogger{}, &allLogger{}
+	l := Multi(l1, l2)
+	l.Debugf("a")
+	l.Infof("b")
+	l.Warnf("c")
+	l.Errorf("d")
+	assert.Equal(t, []string{"DEBUG a", "INFO b", "WARN c", "ERROR d"}, l1.messages)
+	assert.Equal(t, l1.messages, l2.messages)
+}
@@ -0,0 +1,11 @@
+//go:build !js
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals are the signals of WatchLevels
+var reloadSignals = []os.Signal{syscall.SIGHUP}
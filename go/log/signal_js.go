@@ -0,0 +1,6 @@
+package log
+
+import "os"
+
+// reloadSignals are the signals of WatchLevels, there is no SIGHUP in the browsers
+var reloadSignals []os.Signal
@@ -0,0 +1,33 @@
+// Package zerologger adapts a zerolog.Logger to the Logger interface of mechain-common
+package zerologger
+
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+type zeroLogger struct {
+	logger zerolog.Logger
+}
+
+// New adapts a zerolog.Logger to log.Logger, e.g. log.SetLogger(zerologger.New(zlog.Logger))
+func New(l zerolog.Logger) log.Logger {
+	return &zeroLogger{logger: l}
+}
+
+func (l *zeroLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debug().Msgf(format, args...)
+}
+
+func (l *zeroLogger) Infof(format string, args ...interface{}) {
+	l.logger.Info().Msgf(format, args...)
+}
+
+func (l *zeroLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warn().Msgf(format, args...)
+}
+
+func (l *zeroLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Error().Msgf(format, args...)
+}
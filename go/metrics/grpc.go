@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+)
+
+// GRPCMetrics exports the measurements of the requests served by a gRPC service as Prometheus metrics,
+// it implements grpc.MetricsCollector and can be passed to grpc.UnaryServerMetrics and grpc.StreamServerMetrics
+type GRPCMetrics struct {
+	handled *prometheus.CounterVec
+	latency *prometheus.HistogramVec
+}
+
+// NewGRPCMetrics creates the gRPC metrics prefixed by namespace, the metrics should be registered by Register
+func NewGRPCMetrics(namespace string) *GRPCMetrics {
+	return &GRPCMetrics{
+		handled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "server_handled_total",
+			Help:      "Total number of requests served, by method and code.",
+		}, []string{"method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "server_handling_seconds",
+			Help:      "Time spent to serve a request, by method.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 16),
+		}, []string{"method"}),
+	}
+}
+
+// Collectors return all the collectors of the gRPC metrics
+func (m *GRPCMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.handled, m.latency}
+}
+
+// Register registers all the collectors of the gRPC metrics to registerer
+func (m *GRPCMetrics) Register(registerer prometheus.Registerer) error {
+	return Register(registerer, m)
+}
+
+// ObserveRequest records a request of the full method served with the code
+func (m *GRPCMetrics) ObserveRequest(fullMethod string, code codes.Code, latency time.Duration) {
+	m.handled.WithLabelValues(fullMethod, code.String()).Inc()
+	m.latency.WithLabelValues(fullMethod).Observe(latency.Seconds())
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGRPCMetrics(t *testing.T) {
+	m := NewGRPCMetrics("mechain")
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, m.Register(registry))
+
+	m.ObserveRequest("/mechain.Service/Get", codes.OK, 10*time.Millisecond)
+	m.ObserveRequest("/mechain.Service/Get", codes.OK, 20*time.Millisecond)
+	m.ObserveRequest("/mechain.Service/Get", codes.NotFound, time.Millisecond)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.handled.WithLabelValues("/mechain.Service/Get", "OK")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.handled.WithLabelValues("/mechain.Service/Get", "NotFound")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.latency))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.Collectors()), len(families))
+}
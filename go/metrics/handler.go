@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultPath is the path under which the services serve their metrics
+const DefaultPath = "/metrics"
+
+// Handler return the HTTP handler exposing the metrics of gatherer, DefaultRegistry if nil, in the Prometheus text
+// or protobuf format negotiated with the scraper. A metric which can not be gathered is skipped rather than failing
+// the scrape.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	if gatherer == nil {
+		gatherer = DefaultRegistry()
+	}
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// ServeMux return a mux serving the metrics of gatherer, DefaultRegistry if nil, under DefaultPath
+func ServeMux(gatherer prometheus.Gatherer) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(DefaultPath, Handler(gatherer))
+	return mux
+}
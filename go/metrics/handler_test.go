@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := NewPipelineMetrics("mechain")
+	require.NoError(t, m.Register(registry))
+	m.ObserveOperation("upload", 10, 0, nil)
+
+	server := httptest.NewServer(ServeMux(registry))
+	defer server.Close()
+	resp, err := http.Get(server.URL + DefaultPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `mechain_pipeline_bytes_total{pipeline="upload"} 10`)
+
+	resp, err = http.Get(server.URL + "/other")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HashMetrics exports the measurements of the hash package as Prometheus metrics,
+// it implements hash.MetricsCollector and can be passed to hash.WithMetrics
+type HashMetrics struct {
+	bytesHashed    prometheus.Counter
+	segments       prometheus.Counter
+	segmentLatency prometheus.Histogram
+	encodeLatency  prometheus.Histogram
+	queueDepth     prometheus.Gauge
+	activeWorkers  prometheus.Gauge
+}
+
+// NewHashMetrics creates the hash metrics prefixed by namespace, the metrics should be registered by Register
+func NewHashMetrics(namespace string) *HashMetrics {
+	return &HashMetrics{
+		bytesHashed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "hash",
+			Name:      "bytes_total",
+			Help:      "Total number of bytes hashed and erasure encoded.",
+		}),
+		segments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "hash",
+			Name:      "segments_total",
+			Help:      "Total number of segments hashed and erasure encoded.",
+		}),
+		segmentLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "hash",
+			Name:      "segment_duration_seconds",
+			Help:      "Time spent to hash and erasure encode one segment.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		encodeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "hash",
+			Name:      "encode_duration_seconds",
+			Help:      "Time spent to erasure encode one segment.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "hash",
+			Name:      "queue_depth",
+			Help:      "Number of segments waiting for a hash worker.",
+		}),
+		activeWorkers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "hash",
+			Name:      "active_workers",
+			Help:      "Number of hash workers busy with a segment.",
+		}),
+	}
+}
+
+// Collectors return all the collectors of the hash metrics
+func (m *HashMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.bytesHashed, m.segments, m.segmentLatency, m.encodeLatency, m.queueDepth,
+		m.activeWorkers}
+}
+
+// Register registers all the collectors of the hash metrics to registerer
+func (m *HashMetrics) Register(registerer prometheus.Registerer) error {
+	return Register(registerer, m)
+}
+
+// ObserveSegment records the size and latency of a hashed segment
+func (m *HashMetrics) ObserveSegment(size int, latency time.Duration) {
+	m.bytesHashed.Add(float64(size))
+	m.segments.Inc()
+	m.segmentLatency.Observe(latency.Seconds())
+}
+
+// ObserveEncode records the erasure encoding latency of a segment
+func (m *HashMetrics) ObserveEncode(latency time.Duration) {
+	m.encodeLatency.Observe(latency.Seconds())
+}
+
+// ObserveQueueDepth records the number of segments waiting for a hash worker
+func (m *HashMetrics) ObserveQueueDepth(depth int) {
+	m.queueDepth.Set(float64(depth))
+}
+
+// ObserveActiveWorkers records the number of busy hash workers
+func (m *HashMetrics) ObserveActiveWorkers(active int) {
+	m.activeWorkers.Set(float64(active))
+}
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashMetrics(t *testing.T) {
+	m := NewHashMetrics("mechain")
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, m.Register(registry))
+
+	m.ObserveSegment(1024, time.Millisecond)
+	m.ObserveSegment(100, time.Millisecond)
+	m.ObserveEncode(time.Millisecond)
+	m.ObserveQueueDepth(3)
+	m.ObserveActiveWorkers(2)
+
+	assert.Equal(t, float64(1124), testutil.ToFloat64(m.bytesHashed))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.segments))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.queueDepth))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.activeWorkers))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.Collectors()), len(families))
+}
@@ -0,0 +1,97 @@
+// Package metrics exports the measurements of the packages of mechain-common as Prometheus metrics, and provides the
+// registry, the standard collectors and the HTTP handler shared by the services built on them. The metrics are
+// named <namespace>_<subsystem>_<name>, in snake case, the namespace being DefaultNamespace unless a service has its
+// own, and the counters end with _total and the durations and the sizes with their unit, _seconds and _bytes.
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+)
+
+// DefaultNamespace is the namespace of the metrics of the mechain services
+const DefaultNamespace = "mechain"
+
+// CollectorSet is implemented by the metrics of this package, e.g. HashMetrics, to be registered by Register
+type CollectorSet interface {
+	// Collectors return all the collectors of the set
+	Collectors() []prometheus.Collector
+}
+
+// Register registers all the collectors of the sets to registerer, it stops at the first error
+func Register(registerer prometheus.Registerer, sets ...CollectorSet) error {
+	for _, set := range sets {
+		for _, collector := range set.Collectors() {
+			if err := registerer.Register(collector); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NewRegistry return a registry with the standard collectors registered: the process collector, e.g. the cpu time
+// and the open file descriptors, and the go runtime collector, e.g. the goroutines and the memory stats
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}), collectors.NewGoCollector())
+	return registry
+}
+
+var (
+	defaultRegistry     *prometheus.Registry
+	defaultRegistryOnce sync.Once
+)
+
+// DefaultRegistry return the registry shared by the components of a service, created by NewRegistry on first use.
+// Unlike prometheus.DefaultRegisterer it is not touched by the dependencies registering their own metrics.
+func DefaultRegistry() *prometheus.Registry {
+	defaultRegistryOnce.Do(func() {
+		defaultRegistry = NewRegistry()
+	})
+	return defaultRegistry
+}
+
+// CheckNames return an error listing the metrics of gatherer breaking the naming conventions of the package, see
+// the package documentation. The metrics of the standard collectors, prefixed by go_, process_ and promhttp_, are
+// exempt from the namespace.
+func CheckNames(gatherer prometheus.Gatherer, namespace string) error {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return err
+	}
+	var problems []string
+	for _, family := range families {
+		name := family.GetName()
+		if !strings.HasPrefix(name, namespace+"_") && !isStandardMetric(name) {
+			problems = append(problems, fmt.Sprintf("%s: not in namespace %s", name, namespace))
+		}
+	}
+	lintProblems, err := promlint.NewWithMetricFamilies(families).Lint()
+	if err != nil {
+		return err
+	}
+	for _, problem := range lintProblems {
+		if !isStandardMetric(problem.Metric) {
+			problems = append(problems, fmt.Sprintf("%s: %s", problem.Metric, problem.Text))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("metrics break the naming conventions: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func isStandardMetric(name string) bool {
+	for _, prefix := range []string{"go_", "process_", "promhttp_"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	assert.Same(t, DefaultRegistry(), DefaultRegistry())
+
+	registry := NewRegistry()
+	require.NoError(t, Register(registry, NewHashMetrics(DefaultNamespace), NewRedundancyMetrics(DefaultNamespace),
+		NewPieceStoreMetrics(DefaultNamespace), NewGRPCMetrics(DefaultNamespace), NewPipelineMetrics(DefaultNamespace)))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	assert.True(t, names["go_goroutines"])
+	assert.True(t, names["mechain_hash_bytes_total"])
+	assert.NoError(t, CheckNames(registry, DefaultNamespace))
+
+	assert.Error(t, Register(registry, NewHashMetrics(DefaultNamespace)))
+}
+
+func TestCheckNames(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "other_requests_total", Help: "Requests."}))
+	assert.ErrorContains(t, CheckNames(registry, DefaultNamespace), "other_requests_total: not in namespace mechain")
+
+	registry = prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Name: "mechain_requests", Help: "Requests."}))
+	assert.ErrorContains(t, CheckNames(registry, DefaultNamespace), "mechain_requests: counter metrics should have")
+}
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PieceStoreMetrics exports the measurements of the piece cache of the piecestore package as Prometheus metrics,
+// it implements piecestore.MetricsCollector and can be passed to piecestore.NewCachedStore
+type PieceStoreMetrics struct {
+	hits        prometheus.Counter
+	misses      prometheus.Counter
+	evictions   prometheus.Counter
+	cachedBytes prometheus.Gauge
+}
+
+// NewPieceStoreMetrics creates the piece store metrics prefixed by namespace, the metrics should be registered by
+// Register
+func NewPieceStoreMetrics(namespace string) *PieceStoreMetrics {
+	return &PieceStoreMetrics{
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "piecestore",
+			Name:      "cache_hits_total",
+			Help:      "Total number of pieces served from the cache.",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "piecestore",
+			Name:      "cache_misses_total",
+			Help:      "Total number of pieces missing in the cache.",
+		}),
+		evictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "piecestore",
+			Name:      "cache_evictions_total",
+			Help:      "Total number of pieces evicted from the cache.",
+		}),
+		cachedBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "piecestore",
+			Name:      "cache_bytes",
+			Help:      "Total size of the cached pieces.",
+		}),
+	}
+}
+
+// Collectors return all the collectors of the piece store metrics
+func (m *PieceStoreMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.hits, m.misses, m.evictions, m.cachedBytes}
+}
+
+// Register registers all the collectors of the piece store metrics to registerer
+func (m *PieceStoreMetrics) Register(registerer prometheus.Registerer) error {
+	return Register(registerer, m)
+}
+
+// ObserveCacheHit records a piece served from the cache
+func (m *PieceStoreMetrics) ObserveCacheHit() {
+	m.hits.Inc()
+}
+
+// ObserveCacheMiss records a piece read from the underlying store
+func (m *PieceStoreMetrics) ObserveCacheMiss() {
+	m.misses.Inc()
+}
+
+// ObserveCacheEviction records an evicted piece
+func (m *PieceStoreMetrics) ObserveCacheEviction() {
+	m.evictions.Inc()
+}
+
+// ObserveCachedBytes records the total size of the cached pieces
+func (m *PieceStoreMetrics) ObserveCachedBytes(size int64) {
+	m.cachedBytes.Set(float64(size))
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPieceStoreMetrics(t *testing.T) {
+	m := NewPieceStoreMetrics("mechain")
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, m.Register(registry))
+
+	m.ObserveCacheHit()
+	m.ObserveCacheHit()
+	m.ObserveCacheMiss()
+	m.ObserveCacheEviction()
+	m.ObserveCachedBytes(2048)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.hits))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.misses))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.evictions))
+	assert.Equal(t, float64(2048), testutil.ToFloat64(m.cachedBytes))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.Collectors()), len(families))
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PipelineMetrics exports the throughput of the pipelines of a service, e.g. the uploads or the downloads of the
+// objects, as Prometheus metrics labeled by the name of the pipeline
+type PipelineMetrics struct {
+	bytes      *prometheus.CounterVec
+	operations *prometheus.CounterVec
+	errors     *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	inFlight   *prometheus.GaugeVec
+}
+
+// NewPipelineMetrics creates the pipeline metrics prefixed by namespace, the metrics should be registered by Register
+func NewPipelineMetrics(namespace string) *PipelineMetrics {
+	return &PipelineMetrics{
+		bytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pipeline",
+			Name:      "bytes_total",
+			Help:      "Total number of bytes processed, by pipeline.",
+		}, []string{"pipeline"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pipeline",
+			Name:      "operations_total",
+			Help:      "Total number of operations completed, by pipeline.",
+		}, []string{"pipeline"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "pipeline",
+			Name:      "errors_total",
+			Help:      "Total number of operations failed, by pipeline.",
+		}, []string{"pipeline"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "pipeline",
+			Name:      "operation_duration_seconds",
+			Help:      "Time spent to complete an operation, by pipeline.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 18),
+		}, []string{"pipeline"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "pipeline",
+			Name:      "operations_in_flight",
+			Help:      "Number of operations in progress, by pipeline.",
+		}, []string{"pipeline"}),
+	}
+}
+
+// Collectors return all the collectors of the pipeline metrics
+func (m *PipelineMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.bytes, m.operations, m.errors, m.latency, m.inFlight}
+}
+
+// Register registers all the collectors of the pipeline metrics to registerer
+func (m *PipelineMetrics) Register(registerer prometheus.Registerer) error {
+	return Register(registerer, m)
+}
+
+// ObserveOperation records an operation of the pipeline which processed size bytes, a failed operation is counted
+// as an error and its bytes are not counted
+func (m *PipelineMetrics) ObserveOperation(pipeline string, size int64, latency time.Duration, err error) {
+	m.latency.WithLabelValues(pipeline).Observe(latency.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(pipeline).Inc()
+		return
+	}
+	m.operations.WithLabelValues(pipeline).Inc()
+	m.bytes.WithLabelValues(pipeline).Add(float64(size))
+}
+
+// Start records the start of an operation of the pipeline, the returned function must be called once it ends with
+// the bytes it processed and its error, e.g.
+//
+//	done := m.Start("upload")
+//	size, err := upload(ctx, object)
+//	done(size, err)
+func (m *PipelineMetrics) Start(pipeline string) func(size int64, err error) {
+	start := time.Now()
+	m.inFlight.WithLabelValues(pipeline).Inc()
+	return func(size int64, err error) {
+		m.inFlight.WithLabelValues(pipeline).Dec()
+		m.ObserveOperation(pipeline, size, time.Since(start), err)
+	}
+}
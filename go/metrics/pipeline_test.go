@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipelineMetrics(t *testing.T) {
+	m := NewPipelineMetrics("mechain")
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, m.Register(registry))
+
+	done := m.Start("upload")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.inFlight.WithLabelValues("upload")))
+	done(1024, nil)
+	m.ObserveOperation("upload", 100, time.Millisecond, nil)
+	m.ObserveOperation("upload", 100, time.Millisecond, errors.New("failed"))
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.inFlight.WithLabelValues("upload")))
+	assert.Equal(t, float64(1124), testutil.ToFloat64(m.bytes.WithLabelValues("upload")))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.operations.WithLabelValues("upload")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.errors.WithLabelValues("upload")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.latency))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.Collectors()), len(families))
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RedundancyMetrics exports the measurements of the redundancy package as Prometheus metrics,
+// it implements redundancy.MetricsCollector and can be passed to redundancy.SetMetrics
+type RedundancyMetrics struct {
+	bytesEncoded    prometheus.Counter
+	bytesDecoded    prometheus.Counter
+	encodeLatency   prometheus.Histogram
+	decodeLatency   prometheus.Histogram
+	reconstructions prometheus.Counter
+	lostShards      prometheus.Counter
+	verifyFailures  prometheus.Counter
+}
+
+// NewRedundancyMetrics creates the redundancy metrics prefixed by namespace, the metrics should be registered by
+// Register. The encode and decode throughputs are the rates of the byte counters.
+func NewRedundancyMetrics(namespace string) *RedundancyMetrics {
+	return &RedundancyMetrics{
+		bytesEncoded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "encoded_bytes_total",
+			Help:      "Total number of segment bytes erasure encoded.",
+		}),
+		bytesDecoded: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "decoded_bytes_total",
+			Help:      "Total number of segment bytes decoded from their shards.",
+		}),
+		encodeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "encode_duration_seconds",
+			Help:      "Time spent to erasure encode one segment.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		decodeLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "decode_duration_seconds",
+			Help:      "Time spent to decode one segment.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+		}),
+		reconstructions: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "reconstructions_total",
+			Help:      "Total number of reconstructions of lost shards.",
+		}),
+		lostShards: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "reconstructed_shards_total",
+			Help:      "Total number of lost shards reconstructed.",
+		}),
+		verifyFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "redundancy",
+			Name:      "verify_failures_total",
+			Help:      "Total number of segments with inconsistent shards.",
+		}),
+	}
+}
+
+// Collectors return all the collectors of the redundancy metrics
+func (m *RedundancyMetrics) Collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.bytesEncoded, m.bytesDecoded, m.encodeLatency, m.decodeLatency,
+		m.reconstructions, m.lostShards, m.verifyFailures}
+}
+
+// Register registers all the collectors of the redundancy metrics to registerer
+func (m *RedundancyMetrics) Register(registerer prometheus.Registerer) error {
+	return Register(registerer, m)
+}
+
+// ObserveEncode records the size and latency of an encoded segment
+func (m *RedundancyMetrics) ObserveEncode(size int, latency time.Duration) {
+	m.bytesEncoded.Add(float64(size))
+	m.encodeLatency.Observe(latency.Seconds())
+}
+
+// ObserveDecode records the size and latency of a decoded segment
+func (m *RedundancyMetrics) ObserveDecode(size int, latency time.Duration) {
+	m.bytesDecoded.Add(float64(size))
+	m.decodeLatency.Observe(latency.Seconds())
+}
+
+// ObserveReconstruction records a reconstruction of lost shards
+func (m *RedundancyMetrics) ObserveReconstruction(lostShards int) {
+	m.reconstructions.Inc()
+	m.lostShards.Add(float64(lostShards))
+}
+
+// ObserveVerifyFailure records a segment with inconsistent shards
+func (m *RedundancyMetrics) ObserveVerifyFailure() {
+	m.verifyFailures.Inc()
+}
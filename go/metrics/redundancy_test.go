@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedundancyMetrics(t *testing.T) {
+	m := NewRedundancyMetrics("mechain")
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, m.Register(registry))
+
+	m.ObserveEncode(1024, time.Millisecond)
+	m.ObserveDecode(100, time.Millisecond)
+	m.ObserveReconstruction(2)
+	m.ObserveReconstruction(1)
+	m.ObserveVerifyFailure()
+
+	assert.Equal(t, float64(1024), testutil.ToFloat64(m.bytesEncoded))
+	assert.Equal(t, float64(100), testutil.ToFloat64(m.bytesDecoded))
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.reconstructions))
+	assert.Equal(t, float64(3), testutil.ToFloat64(m.lostShards))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.verifyFailures))
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Equal(t, len(m.Collectors()), len(families))
+}
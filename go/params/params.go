@@ -0,0 +1,141 @@
+// Package params provides the storage params of the chain to the hash and redundancy layers, they are fetched by a
+// pluggable Querier and cached for a TTL, see the cache package
+package params
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/cache"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// ErrPayloadTooLarge is returned when an object is larger than the max payload size of the chain
+var ErrPayloadTooLarge = errors.New("the payload size exceeds the max payload size")
+
+// StorageParams are the storage params of the chain used to hash and encode the objects
+type StorageParams struct {
+	MaxSegmentSize          uint64
+	RedundantDataChunkNum   uint32
+	RedundantParityChunkNum uint32
+	MaxPayloadSize          uint64
+}
+
+// RedundancyParams return the validated redundancy params of the storage params
+func (p StorageParams) RedundancyParams() (redundancy.RedundancyParams, error) {
+	return redundancy.NewRedundancyParams(int64(p.MaxSegmentSize), int(p.RedundantDataChunkNum),
+		int(p.RedundantParityChunkNum))
+}
+
+// CheckPayloadSize return ErrPayloadTooLarge if an object of payloadSize bytes can not be created
+func (p StorageParams) CheckPayloadSize(payloadSize uint64) error {
+	if payloadSize > p.MaxPayloadSize {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrPayloadTooLarge, payloadSize, p.MaxPayloadSize)
+	}
+	return nil
+}
+
+// Querier fetches the storage params from the chain, e.g. by the Params query of the storage module
+type Querier interface {
+	QueryStorageParams(ctx context.Context) (StorageParams, error)
+}
+
+// QuerierFunc adapts a func to a Querier
+type QuerierFunc func(ctx context.Context) (StorageParams, error)
+
+// QueryStorageParams implements Querier
+func (f QuerierFunc) QueryStorageParams(ctx context.Context) (StorageParams, error) {
+	return f(ctx)
+}
+
+// Provider caches the storage params fetched by the Querier for a TTL and notifies the subscribers when a fetch
+// returns changed params. The concurrent callers of an expired cache query the chain once. It is safe for concurrent
+// use.
+type Provider struct {
+	querier Querier
+	now     func() time.Time
+	cache   *cache.Cache[struct{}, StorageParams]
+
+	mu          sync.Mutex
+	params      StorageParams
+	fetched     bool
+	nextID      int
+	subscribers map[int]func(old, new StorageParams)
+}
+
+// NewProvider return a Provider fetching the params from the querier at most once per ttl. opts configure the cache
+// further, e.g. cache.WithStale serves the expired params while they are fetched again.
+func NewProvider(querier Querier, ttl time.Duration, opts ...cache.Option) *Provider {
+	p := &Provider{
+		querier:     querier,
+		now:         time.Now,
+		subscribers: make(map[int]func(old, new StorageParams)),
+	}
+	opts = append([]cache.Option{cache.WithTTL(ttl), cache.WithClock(func() time.Time { return p.now() })}, opts...)
+	p.cache = cache.New(func(ctx context.Context, _ struct{}) (StorageParams, error) {
+		return p.fetch(ctx)
+	}, opts...)
+	return p
+}
+
+// StorageParams return the cached storage params, they are fetched if the cache is empty or older than the TTL
+func (p *Provider) StorageParams(ctx context.Context) (StorageParams, error) {
+	return p.cache.Get(ctx, struct{}{})
+}
+
+// Refresh fetches the storage params regardless of the cache
+func (p *Provider) Refresh(ctx context.Context) (StorageParams, error) {
+	return p.cache.Reload(ctx, struct{}{})
+}
+
+// RedundancyParams return the redundancy params of the cached storage params, see StorageParams
+func (p *Provider) RedundancyParams(ctx context.Context) (redundancy.RedundancyParams, error) {
+	params, err := p.StorageParams(ctx)
+	if err != nil {
+		return redundancy.RedundancyParams{}, err
+	}
+	return params.RedundancyParams()
+}
+
+// Subscribe registers fn to be called with the previous and the new params every time a fetch returns changed
+// params, fn is called synchronously by the goroutine of the fetch. The returned func unregisters fn.
+func (p *Provider) Subscribe(fn func(old, new StorageParams)) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	id := p.nextID
+	p.nextID++
+	p.subscribers[id] = fn
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.subscribers, id)
+	}
+}
+
+// fetch queries the params and notifies the subscribers if they changed, the fetches are run one at a time by the
+// cache
+func (p *Provider) fetch(ctx context.Context) (StorageParams, error) {
+	params, err := p.querier.QueryStorageParams(ctx)
+	if err != nil {
+		return StorageParams{}, fmt.Errorf("failed to query storage params: %w", err)
+	}
+
+	p.mu.Lock()
+	old, changed := p.params, p.fetched && p.params != params
+	p.params, p.fetched = params, true
+	var subscribers []func(old, new StorageParams)
+	if changed {
+		for _, fn := range p.subscribers {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(old, params)
+	}
+	return params, nil
+}
@@ -0,0 +1,130 @@
+package params
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+var defaultParams = StorageParams{
+	MaxSegmentSize:          16 * 1024 * 1024,
+	RedundantDataChunkNum:   4,
+	RedundantParityChunkNum: 2,
+	MaxPayloadSize:          64 * 1024 * 1024 * 1024,
+}
+
+type fakeQuerier struct {
+	mu      sync.Mutex
+	params  StorageParams
+	err     error
+	queries atomic.Int32
+}
+
+func (q *fakeQuerier) QueryStorageParams(context.Context) (StorageParams, error) {
+	q.queries.Add(1)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.params, q.err
+}
+
+func (q *fakeQuerier) set(params StorageParams, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.params, q.err = params, err
+}
+
+func TestProviderCache(t *testing.T) {
+	querier := &fakeQuerier{params: defaultParams}
+	provider := NewProvider(querier, time.Minute)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params, err := provider.StorageParams(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, defaultParams, params)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), querier.queries.Load())
+
+	redundancyParams, err := provider.RedundancyParams(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, redundancy.RedundancyParams{SegmentSize: 16 * 1024 * 1024, DataShards: 4, ParityShards: 2},
+		redundancyParams)
+	assert.Equal(t, int32(1), querier.queries.Load())
+
+	now = now.Add(time.Minute)
+	_, err = provider.StorageParams(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), querier.queries.Load())
+
+	_, err = provider.Refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), querier.queries.Load())
+
+	queryErr := errors.New("node unavailable")
+	querier.set(StorageParams{}, queryErr)
+	_, err = provider.Refresh(ctx)
+	assert.ErrorIs(t, err, queryErr)
+	params, err := provider.StorageParams(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, defaultParams, params)
+}
+
+func TestProviderSubscribe(t *testing.T) {
+	querier := &fakeQuerier{params: defaultParams}
+	provider := NewProvider(querier, time.Minute)
+	var changes [][2]StorageParams
+	unsubscribe := provider.Subscribe(func(old, new StorageParams) {
+		changes = append(changes, [2]StorageParams{old, new})
+	})
+
+	ctx := context.Background()
+	_, err := provider.Refresh(ctx)
+	require.NoError(t, err)
+	_, err = provider.Refresh(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	changed := defaultParams
+	changed.RedundantParityChunkNum = 3
+	querier.set(changed, nil)
+	_, err = provider.Refresh(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, [][2]StorageParams{{defaultParams, changed}}, changes)
+
+	unsubscribe()
+	querier.set(defaultParams, nil)
+	_, err = provider.Refresh(ctx)
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+}
+
+func TestStorageParams(t *testing.T) {
+	assert.NoError(t, defaultParams.CheckPayloadSize(defaultParams.MaxPayloadSize))
+	assert.ErrorIs(t, defaultParams.CheckPayloadSize(defaultParams.MaxPayloadSize+1), ErrPayloadTooLarge)
+
+	invalid := defaultParams
+	invalid.RedundantParityChunkNum = 0
+	_, err := invalid.RedundancyParams()
+	assert.ErrorIs(t, err, redundancy.ErrInvalidRedundancyParams)
+
+	provider := NewProvider(QuerierFunc(func(context.Context) (StorageParams, error) { return invalid, nil }),
+		time.Minute)
+	_, err = provider.RedundancyParams(context.Background())
+	assert.ErrorIs(t, err, redundancy.ErrInvalidRedundancyParams)
+}
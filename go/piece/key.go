@@ -0,0 +1,97 @@
+// Package piece derives the keys under which the SPs store the segments and the erasure encoded pieces of objects
+package piece
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SegmentSeparator separates the object id and the segment index in a piece key
+	SegmentSeparator = "_s"
+	// ECSeparator separates the segment index and the ec index in an ec piece key
+	ECSeparator = "_p"
+	// NoECIndex is the ECIndex of the key of a segment piece stored by the PrimarySP
+	NoECIndex int32 = -1
+)
+
+// ErrInvalidPieceKey is returned when a piece key can not be parsed
+var ErrInvalidPieceKey = errors.New("invalid piece key")
+
+// Key identifies a piece stored by a SP, either a segment stored by the PrimarySP or an erasure encoded piece
+// of a segment stored by a SecondarySP
+type Key struct {
+	ObjectID     uint64
+	SegmentIndex uint32
+	// ECIndex is the erasure coding index of the piece, it is NoECIndex for a segment piece
+	ECIndex int32
+}
+
+// NewSegmentKey return the key of a segment piece
+func NewSegmentKey(objectID uint64, segmentIndex uint32) Key {
+	return Key{ObjectID: objectID, SegmentIndex: segmentIndex, ECIndex: NoECIndex}
+}
+
+// NewECKey return the key of an erasure encoded piece
+func NewECKey(objectID uint64, segmentIndex uint32, ecIndex uint32) Key {
+	return Key{ObjectID: objectID, SegmentIndex: segmentIndex, ECIndex: int32(ecIndex)}
+}
+
+// SegmentPieceKey return the key of a segment piece, e.g. "100_s2" is the third segment of object 100
+func SegmentPieceKey(objectID uint64, segmentIndex uint32) string {
+	return NewSegmentKey(objectID, segmentIndex).String()
+}
+
+// ECPieceKey return the key of an erasure encoded piece, e.g. "100_s2_p4" is the fifth ec piece of the third
+// segment of object 100
+func ECPieceKey(objectID uint64, segmentIndex uint32, ecIndex uint32) string {
+	return NewECKey(objectID, segmentIndex, ecIndex).String()
+}
+
+// IsECPiece return true if the key is the key of an erasure encoded piece
+func (k Key) IsECPiece() bool {
+	return k.ECIndex != NoECIndex
+}
+
+// String return the piece key
+func (k Key) String() string {
+	key := strconv.FormatUint(k.ObjectID, 10) + SegmentSeparator + strconv.FormatUint(uint64(k.SegmentIndex), 10)
+	if k.IsECPiece() {
+		key += ECSeparator + strconv.FormatInt(int64(k.ECIndex), 10)
+	}
+	return key
+}
+
+// ParseKey parses a segment piece key or an ec piece key, only the keys returned by Key.String are accepted
+func ParseKey(key string) (Key, error) {
+	objectPart, segmentPart, found := strings.Cut(key, SegmentSeparator)
+	if !found {
+		return Key{}, fmt.Errorf("%w: %q has no segment index", ErrInvalidPieceKey, key)
+	}
+	objectID, err := strconv.ParseUint(objectPart, 10, 64)
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: %q has an invalid object id", ErrInvalidPieceKey, key)
+	}
+
+	ecIndex := int64(NoECIndex)
+	segmentPart, ecPart, isECPiece := strings.Cut(segmentPart, ECSeparator)
+	if isECPiece {
+		ecIndex, err = strconv.ParseInt(ecPart, 10, 32)
+		if err != nil || ecIndex < 0 {
+			return Key{}, fmt.Errorf("%w: %q has an invalid ec index", ErrInvalidPieceKey, key)
+		}
+	}
+	segmentIndex, err := strconv.ParseUint(segmentPart, 10, 32)
+	if err != nil {
+		return Key{}, fmt.Errorf("%w: %q has an invalid segment index", ErrInvalidPieceKey, key)
+	}
+
+	parsed := Key{ObjectID: objectID, SegmentIndex: uint32(segmentIndex), ECIndex: int32(ecIndex)}
+	// reject the non canonical spellings, e.g. with leading zeros or signs, so a piece has a single key
+	if parsed.String() != key {
+		return Key{}, fmt.Errorf("%w: %q is not canonical", ErrInvalidPieceKey, key)
+	}
+	return parsed, nil
+}
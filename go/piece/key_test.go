@@ -0,0 +1,33 @@
+package piece
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPieceKey(t *testing.T) {
+	assert.Equal(t, "100_s2", SegmentPieceKey(100, 2))
+	assert.Equal(t, "100_s2_p4", ECPieceKey(100, 2, 4))
+
+	key, err := ParseKey("100_s2")
+	assert.Nil(t, err)
+	assert.Equal(t, NewSegmentKey(100, 2), key)
+	assert.False(t, key.IsECPiece())
+
+	key, err = ParseKey("18446744073709551615_s4294967295_p0")
+	assert.Nil(t, err)
+	assert.Equal(t, NewECKey(18446744073709551615, 4294967295, 0), key)
+	assert.True(t, key.IsECPiece())
+	assert.Equal(t, "18446744073709551615_s4294967295_p0", key.String())
+
+	for _, invalid := range []string{"", "100", "abc_s1", "100_s", "100_s1_p", "100_s1_p-1", "100_s-1", "100_s1_p1_p2"} {
+		_, err = ParseKey(invalid)
+		assert.ErrorIs(t, err, ErrInvalidPieceKey, invalid)
+	}
+	// the non canonical spellings of a valid key are rejected
+	for _, invalid := range []string{"1_s01_p+1", "01_s1", "+1_s1", "1_s+1", "1_s1_p01", "1_s1_p+0"} {
+		_, err = ParseKey(invalid)
+		assert.ErrorIs(t, err, ErrInvalidPieceKey, invalid)
+	}
+}
@@ -0,0 +1,162 @@
+package piecestore
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// CachedStore is a write-through PieceStore keeping the recently used pieces of another store in memory, the cached
+// pieces are bounded by their total size and the least recently used ones are evicted first. Concurrent Gets of the
+// same missing piece share a single read of the underlying store. It is safe for concurrent use.
+type CachedStore struct {
+	store    PieceStore
+	maxBytes int64
+	metrics  MetricsCollector
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[piece.Key]*list.Element
+	size    int64
+	// generation is bumped by every write so a read or a write started before it does not cache stale data
+	generation uint64
+	loads      map[piece.Key]*load
+}
+
+type cacheEntry struct {
+	key  piece.Key
+	data []byte
+}
+
+// load is a read of the underlying store shared by the concurrent Gets of a piece
+type load struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// NewCachedStore return a CachedStore of the store caching at most maxBytes of pieces, the measurements are sent to
+// collector, which may be nil
+func NewCachedStore(store PieceStore, maxBytes int64, collector MetricsCollector) *CachedStore {
+	if collector == nil {
+		collector = nopMetricsCollector{}
+	}
+	return &CachedStore{
+		store:    store,
+		maxBytes: maxBytes,
+		metrics:  collector,
+		lru:      list.New(),
+		entries:  make(map[piece.Key]*list.Element),
+		loads:    make(map[piece.Key]*load),
+	}
+}
+
+// Put implements PieceStore, the data is written to the underlying store then cached
+func (s *CachedStore) Put(ctx context.Context, key piece.Key, data []byte) error {
+	generation := s.invalidate(key)
+	if err := s.store.Put(ctx, key, data); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if generation == s.generation {
+		s.add(key, bytes.Clone(data))
+	}
+	return nil
+}
+
+// Get implements PieceStore, the returned data is a copy. The Gets sharing a read of the underlying store share its
+// error too, e.g. the cancellation of the context of the first Get.
+func (s *CachedStore) Get(ctx context.Context, key piece.Key) ([]byte, error) {
+	s.mu.Lock()
+	if elem, ok := s.entries[key]; ok {
+		s.lru.MoveToFront(elem)
+		data := bytes.Clone(elem.Value.(*cacheEntry).data)
+		s.mu.Unlock()
+		s.metrics.ObserveCacheHit()
+		return data, nil
+	}
+	s.metrics.ObserveCacheMiss()
+	l, ok := s.loads[key]
+	if !ok {
+		l = &load{done: make(chan struct{})}
+		s.loads[key] = l
+		generation := s.generation
+		s.mu.Unlock()
+
+		l.data, l.err = s.store.Get(ctx, key)
+		s.mu.Lock()
+		delete(s.loads, key)
+		if l.err == nil && generation == s.generation {
+			s.add(key, l.data)
+		}
+		s.mu.Unlock()
+		close(l.done)
+		return bytes.Clone(l.data), l.err
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-l.done:
+		return bytes.Clone(l.data), l.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Delete implements PieceStore
+func (s *CachedStore) Delete(ctx context.Context, key piece.Key) error {
+	s.invalidate(key)
+	return s.store.Delete(ctx, key)
+}
+
+// Stat implements PieceStore
+func (s *CachedStore) Stat(ctx context.Context, key piece.Key) (PieceInfo, error) {
+	s.mu.Lock()
+	elem, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok {
+		return PieceInfo{Key: key, Size: int64(len(elem.Value.(*cacheEntry).data))}, nil
+	}
+	return s.store.Stat(ctx, key)
+}
+
+// invalidate removes the piece from the cache before it is written and return the new generation
+func (s *CachedStore) invalidate(key piece.Key) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+		s.metrics.ObserveCachedBytes(s.size)
+	}
+	return s.generation
+}
+
+// add caches the data of the piece and evicts the least recently used pieces exceeding maxBytes, a piece larger
+// than maxBytes is not cached. The lock must be held.
+func (s *CachedStore) add(key piece.Key, data []byte) {
+	if int64(len(data)) > s.maxBytes {
+		return
+	}
+	if elem, ok := s.entries[key]; ok {
+		s.remove(elem)
+	}
+	s.entries[key] = s.lru.PushFront(&cacheEntry{key: key, data: data})
+	s.size += int64(len(data))
+	for s.size > s.maxBytes {
+		s.remove(s.lru.Back())
+		s.metrics.ObserveCacheEviction()
+	}
+	s.metrics.ObserveCachedBytes(s.size)
+}
+
+// remove drops the element from the cache. The lock must be held.
+func (s *CachedStore) remove(elem *list.Element) {
+	entry := s.lru.Remove(elem).(*cacheEntry)
+	delete(s.entries, entry.key)
+	s.size -= int64(len(entry.data))
+}
@@ -0,0 +1,128 @@
+package piecestore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// slowStore counts the Gets of its MemoryStore, which wait for release if it is not nil
+type slowStore struct {
+	*MemoryStore
+	gets    atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowStore) Get(ctx context.Context, key piece.Key) ([]byte, error) {
+	s.gets.Add(1)
+	if s.release != nil {
+		<-s.release
+	}
+	return s.MemoryStore.Get(ctx, key)
+}
+
+type countingCollector struct {
+	hits, misses, evictions atomic.Int32
+	cachedBytes             atomic.Int64
+}
+
+func (c *countingCollector) ObserveCacheHit() { c.hits.Add(1) }
+
+func (c *countingCollector) ObserveCacheMiss() { c.misses.Add(1) }
+
+func (c *countingCollector) ObserveCacheEviction() { c.evictions.Add(1) }
+
+func (c *countingCollector) ObserveCachedBytes(size int64) { c.cachedBytes.Store(size) }
+
+func TestCachedStore(t *testing.T) {
+	testPieceStore(t, NewCachedStore(NewMemoryStore(), 1024, nil))
+
+	ctx := context.Background()
+	backend := &slowStore{MemoryStore: NewMemoryStore()}
+	collector := &countingCollector{}
+	store := NewCachedStore(backend, 10, collector)
+	keys := []piece.Key{piece.NewSegmentKey(1, 0), piece.NewSegmentKey(1, 1), piece.NewSegmentKey(1, 2)}
+	for _, key := range keys {
+		require.NoError(t, backend.Put(ctx, key, []byte("abcd")))
+	}
+
+	for i := 0; i < 3; i++ {
+		data, err := store.Get(ctx, keys[0])
+		require.NoError(t, err)
+		assert.Equal(t, []byte("abcd"), data)
+		data[0] = 'x'
+	}
+	assert.Equal(t, int32(1), backend.gets.Load())
+	assert.Equal(t, int32(2), collector.hits.Load())
+	assert.Equal(t, int32(1), collector.misses.Load())
+
+	_, err := store.Get(ctx, keys[1])
+	require.NoError(t, err)
+	_, err = store.Get(ctx, keys[0])
+	require.NoError(t, err)
+	_, err = store.Get(ctx, keys[2])
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), collector.evictions.Load())
+	assert.Equal(t, int64(8), collector.cachedBytes.Load())
+	_, err = store.Get(ctx, keys[0])
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), backend.gets.Load())
+
+	require.NoError(t, store.Put(ctx, keys[1], []byte("this piece is too large")))
+	_, err = store.Get(ctx, keys[1])
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), backend.gets.Load())
+
+	require.NoError(t, store.Put(ctx, keys[0], []byte("new")))
+	data, err := store.Get(ctx, keys[0])
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), data)
+	info, err := store.Stat(ctx, keys[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), info.Size)
+	assert.Equal(t, int32(4), backend.gets.Load())
+
+	require.NoError(t, store.Delete(ctx, keys[0]))
+	_, err = store.Get(ctx, keys[0])
+	assert.ErrorIs(t, err, ErrPieceNotFound)
+}
+
+func TestCachedStoreSingleflight(t *testing.T) {
+	ctx := context.Background()
+	backend := &slowStore{MemoryStore: NewMemoryStore(), release: make(chan struct{})}
+	key := piece.NewECKey(7, 0, 1)
+	require.NoError(t, backend.MemoryStore.Put(ctx, key, []byte("hot")))
+	store := NewCachedStore(backend, 1024, nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := store.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("hot"), data)
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		return len(store.loads) == 1 && backend.gets.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err := store.Get(canceled, key)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(backend.release)
+	wg.Wait()
+	assert.Equal(t, int32(1), backend.gets.Load())
+}
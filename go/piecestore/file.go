@@ -0,0 +1,134 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// FileStore is a PieceStore keeping every piece in a file of a local directory, the pieces of an object share the
+// sub directory named by the object id modulo 256 so no directory grows too large
+type FileStore struct {
+	root string
+}
+
+// NewFileStore return a FileStore of the root directory, which is created if it does not exist
+func NewFileStore(root string) (*FileStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileStore{root: root}, nil
+}
+
+// path return the path of the file of the piece
+func (s *FileStore) path(key piece.Key) string {
+	return filepath.Join(s.root, strconv.FormatUint(key.ObjectID%256, 16), key.String())
+}
+
+// Put implements PieceStore, the data is written to a temporary file renamed to the piece file, so a piece is never
+// read partially written
+func (s *FileStore) Put(ctx context.Context, key piece.Key, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key.String()+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write piece %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get implements PieceStore
+func (s *FileStore) Get(ctx context.Context, key piece.Key) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	return data, nil
+}
+
+// Delete implements PieceStore
+func (s *FileStore) Delete(ctx context.Context, key piece.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Stat implements PieceStore
+func (s *FileStore) Stat(ctx context.Context, key piece.Key) (PieceInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return PieceInfo{}, err
+	}
+	info, err := os.Stat(s.path(key))
+	if err != nil {
+		return PieceInfo{}, notFound(key, err)
+	}
+	return PieceInfo{Key: key, Size: info.Size()}, nil
+}
+
+// Walk implements Walker, the files which are not piece files, e.g. the temporary files of the puts in flight, are
+// skipped
+func (s *FileStore) Walk(ctx context.Context, fn func(info PieceInfo) error) error {
+	return filepath.WalkDir(s.root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		key, err := piece.ParseKey(entry.Name())
+		if err != nil || path != s.path(key) {
+			return nil
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(PieceInfo{Key: key, Size: info.Size()})
+	})
+}
+
+// notFound wraps a file not found error into ErrPieceNotFound
+func notFound(key piece.Key, err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrPieceNotFound, key)
+	}
+	return err
+}
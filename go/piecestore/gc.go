@@ -0,0 +1,180 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// DefaultGCBatchSize is the number of pieces deleted at the same time by GarbageCollector.Collect
+const DefaultGCBatchSize = 64
+
+// ErrVersionNotTracked is returned when releasing an object version unknown to a GarbageCollector
+var ErrVersionNotTracked = errors.New("object version is not tracked")
+
+// ObjectVersion identifies a version of the content of an object, the versions of an object share its piece keys
+type ObjectVersion struct {
+	ObjectID uint64
+	Version  uint64
+}
+
+// GarbageCollector counts the object versions referencing every stored piece and deletes the pieces no version
+// references anymore once the object versions are deleted or rejected. An unreferenced piece is deleted after a
+// safety window, so the readers in flight can finish and a piece referenced again in the meantime, e.g. by the
+// next version of its object, is kept. It is safe for concurrent use.
+type GarbageCollector struct {
+	store     PieceStore
+	window    time.Duration
+	batchSize int
+	now       func() time.Time
+
+	mu       sync.Mutex
+	refs     map[piece.Key]int
+	versions map[ObjectVersion][]piece.Key
+	// pending are the unreferenced pieces and the time they can be deleted at
+	pending map[piece.Key]time.Time
+}
+
+// NewGarbageCollector return a GarbageCollector deleting the pieces of store window after they are unreferenced,
+// batchSize pieces at the same time, DefaultGCBatchSize if it is not positive
+func NewGarbageCollector(store PieceStore, window time.Duration, batchSize int) *GarbageCollector {
+	if batchSize <= 0 {
+		batchSize = DefaultGCBatchSize
+	}
+	return &GarbageCollector{
+		store:     store,
+		window:    window,
+		batchSize: batchSize,
+		now:       time.Now,
+		refs:      make(map[piece.Key]int),
+		versions:  make(map[ObjectVersion][]piece.Key),
+		pending:   make(map[piece.Key]time.Time),
+	}
+}
+
+// Track records that the pieces of keys belong to the object version, tracking a version again replaces its keys
+func (c *GarbageCollector) Track(version ObjectVersion, keys []piece.Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.versions[version]
+	c.versions[version] = append([]piece.Key(nil), keys...)
+	for _, key := range keys {
+		c.refs[key]++
+		delete(c.pending, key)
+	}
+	c.unref(previous)
+}
+
+// Release records that the object version is deleted or rejected, its pieces referenced by no other version become
+// deletable after the safety window
+func (c *GarbageCollector) Release(version ObjectVersion) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys, ok := c.versions[version]
+	if !ok {
+		return fmt.Errorf("%w: object %d version %d", ErrVersionNotTracked, version.ObjectID, version.Version)
+	}
+	delete(c.versions, version)
+	c.unref(keys)
+	return nil
+}
+
+// unref drops a reference to every key, the lock must be held
+func (c *GarbageCollector) unref(keys []piece.Key) {
+	deletableAt := c.now().Add(c.window)
+	for _, key := range keys {
+		c.refs[key]--
+		if c.refs[key] <= 0 {
+			delete(c.refs, key)
+			c.pending[key] = deletableAt
+		}
+	}
+}
+
+// References return the number of tracked object versions referencing the piece
+func (c *GarbageCollector) References(key piece.Key) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.refs[key]
+}
+
+// Deletable return the unreferenced pieces whose safety window is over, ordered by key
+func (c *GarbageCollector) Deletable() []piece.Key {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.deletable(false)
+}
+
+// deletable return the unreferenced pieces whose safety window is over, ordered by key, and removes them from the
+// pending pieces if claim is true. The lock must be held.
+func (c *GarbageCollector) deletable(claim bool) []piece.Key {
+	now := c.now()
+	var keys []piece.Key
+	for key, deletableAt := range c.pending {
+		if !now.Before(deletableAt) {
+			keys = append(keys, key)
+			if claim {
+				delete(c.pending, key)
+			}
+		}
+	}
+	return sortKeys(keys)
+}
+
+// Pending return the number of unreferenced pieces not deleted yet, including the ones in their safety window
+func (c *GarbageCollector) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.pending)
+}
+
+// Collect deletes the deletable pieces from the store in batches and return the number of deleted pieces. The pieces
+// failing to be deleted, or not deleted because ctx is done, stay pending and are retried by the next Collect, the
+// errors are joined.
+func (c *GarbageCollector) Collect(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	keys := c.deletable(true)
+	c.mu.Unlock()
+
+	deleted := 0
+	var errs []error
+	for start := 0; start < len(keys); start += c.batchSize {
+		batch := keys[start:min(start+c.batchSize, len(keys))]
+		batchErrs := make([]error, len(batch))
+		if err := ctx.Err(); err != nil {
+			for i := range batchErrs {
+				batchErrs[i] = err
+			}
+		} else {
+			var wg sync.WaitGroup
+			for i, key := range batch {
+				wg.Add(1)
+				go func(i int, key piece.Key) {
+					defer wg.Done()
+					batchErrs[i] = c.store.Delete(ctx, key)
+				}(i, key)
+			}
+			wg.Wait()
+		}
+
+		c.mu.Lock()
+		for i, key := range batch {
+			if batchErrs[i] == nil {
+				deleted++
+				continue
+			}
+			errs = append(errs, fmt.Errorf("failed to delete piece %s: %w", key, batchErrs[i]))
+			if _, tracked := c.refs[key]; !tracked {
+				if _, ok := c.pending[key]; !ok {
+					c.pending[key] = c.now()
+				}
+			}
+		}
+		c.mu.Unlock()
+	}
+	return deleted, errors.Join(errs...)
+}
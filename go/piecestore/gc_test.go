@@ -0,0 +1,127 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+// failingStore fails the deletes of the pieces of failing
+type failingStore struct {
+	*MemoryStore
+	failing map[piece.Key]bool
+}
+
+func (s *failingStore) Delete(ctx context.Context, key piece.Key) error {
+	if s.failing[key] {
+		return errors.New("disk failure")
+	}
+	return s.MemoryStore.Delete(ctx, key)
+}
+
+func TestGarbageCollector(t *testing.T) {
+	ctx := context.Background()
+	pieces := &failingStore{MemoryStore: NewMemoryStore(), failing: make(map[piece.Key]bool)}
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	gc := NewGarbageCollector(pieces, time.Hour, 2)
+	gc.now = func() time.Time { return now }
+
+	v1 := ObjectVersion{ObjectID: 1, Version: 1}
+	v2 := ObjectVersion{ObjectID: 1, Version: 2}
+	v1Keys := []piece.Key{piece.NewSegmentKey(1, 0), piece.NewSegmentKey(1, 1), piece.NewSegmentKey(1, 2)}
+	v2Keys := []piece.Key{piece.NewSegmentKey(1, 0)}
+	for _, key := range v1Keys {
+		require.NoError(t, pieces.Put(ctx, key, []byte(key.String())))
+	}
+	gc.Track(v1, v1Keys)
+	gc.Track(v2, v2Keys)
+	assert.Equal(t, 2, gc.References(v1Keys[0]))
+	assert.ErrorIs(t, gc.Release(ObjectVersion{ObjectID: 2}), ErrVersionNotTracked)
+
+	require.NoError(t, gc.Release(v1))
+	assert.ErrorIs(t, gc.Release(v1), ErrVersionNotTracked)
+	assert.Equal(t, 1, gc.References(v1Keys[0]))
+	assert.Equal(t, 2, gc.Pending())
+	assert.Empty(t, gc.Deletable())
+	deleted, err := gc.Collect(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, v1Keys[1:], gc.Deletable())
+	pieces.failing[v1Keys[2]] = true
+	deleted, err = gc.Collect(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, 1, gc.Pending())
+	_, err = pieces.Get(ctx, v1Keys[1])
+	assert.ErrorIs(t, err, ErrPieceNotFound)
+
+	delete(pieces.failing, v1Keys[2])
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = gc.Collect(canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, gc.Pending())
+	deleted, err = gc.Collect(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	assert.Zero(t, gc.Pending())
+
+	// a piece referenced again within the safety window is kept
+	require.NoError(t, gc.Release(v2))
+	gc.Track(ObjectVersion{ObjectID: 1, Version: 3}, v2Keys)
+	now = now.Add(2 * time.Hour)
+	deleted, err = gc.Collect(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+	_, err = pieces.Get(ctx, v2Keys[0])
+	assert.NoError(t, err)
+
+	// tracking a version again replaces its keys
+	gc.Track(ObjectVersion{ObjectID: 1, Version: 3}, v1Keys[1:2])
+	assert.Empty(t, gc.Deletable())
+	now = now.Add(time.Hour)
+	assert.Equal(t, v2Keys, gc.Deletable())
+}
+
+func TestGarbageCollectorState(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	gc := NewGarbageCollector(NewMemoryStore(), time.Hour, 0)
+	gc.now = func() time.Time { return now }
+	v1 := ObjectVersion{ObjectID: 1, Version: 1}
+	v2 := ObjectVersion{ObjectID: 1, Version: 2}
+	gc.Track(v1, []piece.Key{piece.NewSegmentKey(1, 0), piece.NewECKey(1, 1, 2)})
+	gc.Track(v2, []piece.Key{piece.NewSegmentKey(1, 0)})
+	gc.Track(ObjectVersion{ObjectID: 2, Version: 1}, []piece.Key{piece.NewSegmentKey(2, 0)})
+	require.NoError(t, gc.Release(ObjectVersion{ObjectID: 2, Version: 1}))
+
+	kv := store.NewMemoryStore()
+	require.NoError(t, kv.Put([]byte("gc/versions/stale"), []byte("[]")))
+	require.NoError(t, kv.Put([]byte("sessions/1"), []byte("{}")))
+	require.NoError(t, gc.SaveState(ctx, kv, []byte("gc/")))
+	n, err := store.Count(kv, []byte("gc/"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	restored := NewGarbageCollector(NewMemoryStore(), time.Hour, 0)
+	restored.now = func() time.Time { return now.Add(time.Hour) }
+	require.NoError(t, restored.LoadState(ctx, kv, []byte("gc/")))
+	assert.Equal(t, 2, restored.References(piece.NewSegmentKey(1, 0)))
+	assert.Equal(t, 1, restored.References(piece.NewECKey(1, 1, 2)))
+	assert.Equal(t, []piece.Key{piece.NewSegmentKey(2, 0)}, restored.Deletable())
+	require.NoError(t, restored.Release(v1))
+	assert.Equal(t, 2, restored.Pending())
+
+	ok, err := kv.Has([]byte("sessions/1"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,134 @@
+package piecestore
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+// the keys of the state of a GarbageCollector under its prefix
+const (
+	gcVersionsPrefix = "versions/"
+	gcPendingPrefix  = "pending/"
+)
+
+// gcVersionKey return the key of the pieces of an object version, ordered by object id and version
+func gcVersionKey(prefix []byte, version ObjectVersion) []byte {
+	key := append(append([]byte(nil), prefix...), gcVersionsPrefix...)
+	key = binary.BigEndian.AppendUint64(key, version.ObjectID)
+	return binary.BigEndian.AppendUint64(key, version.Version)
+}
+
+// SaveState writes the tracked object versions and the pending pieces of the collector to s under prefix, replacing
+// the state saved before in the same transaction, so the reference counts survive a restart, see LoadState
+func (c *GarbageCollector) SaveState(ctx context.Context, s store.Store, prefix []byte) error {
+	c.mu.Lock()
+	versions := make(map[ObjectVersion][]string, len(c.versions))
+	for version, keys := range c.versions {
+		encoded := make([]string, len(keys))
+		for i, key := range keys {
+			encoded[i] = key.String()
+		}
+		versions[version] = encoded
+	}
+	pending := make(map[piece.Key]time.Time, len(c.pending))
+	for key, deletableAt := range c.pending {
+		pending[key] = deletableAt
+	}
+	c.mu.Unlock()
+
+	return s.Update(ctx, func(txn store.Txn) error {
+		var stale [][]byte
+		if err := store.ForEach(txn, prefix, func(key, _ []byte) error {
+			stale = append(stale, append([]byte(nil), key...))
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, key := range stale {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+		for version, keys := range versions {
+			value, err := json.Marshal(keys)
+			if err != nil {
+				return err
+			}
+			if err = txn.Put(gcVersionKey(prefix, version), value); err != nil {
+				return err
+			}
+		}
+		for key, deletableAt := range pending {
+			stateKey := append(append(append([]byte(nil), prefix...), gcPendingPrefix...), key.String()...)
+			if err := txn.Put(stateKey, strconv.AppendInt(nil, deletableAt.UnixNano(), 10)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// LoadState replaces the state of the collector by the state saved to s under prefix by SaveState
+func (c *GarbageCollector) LoadState(ctx context.Context, s store.Store, prefix []byte) error {
+	versions := make(map[ObjectVersion][]piece.Key)
+	pending := make(map[piece.Key]time.Time)
+	err := s.View(ctx, func(r store.Reader) error {
+		versionsPrefix := append(append([]byte(nil), prefix...), gcVersionsPrefix...)
+		if err := store.ForEach(r, versionsPrefix, func(key, value []byte) error {
+			id := key[len(versionsPrefix):]
+			if len(id) != 16 {
+				return fmt.Errorf("invalid gc state key %q", key)
+			}
+			var encoded []string
+			if err := json.Unmarshal(value, &encoded); err != nil {
+				return fmt.Errorf("invalid gc state of %q: %w", key, err)
+			}
+			keys := make([]piece.Key, len(encoded))
+			for i, k := range encoded {
+				var err error
+				if keys[i], err = piece.ParseKey(k); err != nil {
+					return err
+				}
+			}
+			version := ObjectVersion{ObjectID: binary.BigEndian.Uint64(id), Version: binary.BigEndian.Uint64(id[8:])}
+			versions[version] = keys
+			return nil
+		}); err != nil {
+			return err
+		}
+		pendingPrefix := append(append([]byte(nil), prefix...), gcPendingPrefix...)
+		return store.ForEach(r, pendingPrefix, func(key, value []byte) error {
+			pieceKey, err := piece.ParseKey(string(key[len(pendingPrefix):]))
+			if err != nil {
+				return err
+			}
+			deletableAt, err := strconv.ParseInt(string(value), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid gc state of %q: %w", key, err)
+			}
+			pending[pieceKey] = time.Unix(0, deletableAt)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	refs := make(map[piece.Key]int)
+	for _, keys := range versions {
+		for _, key := range keys {
+			refs[key]++
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions, c.refs, c.pending = versions, refs, pending
+	return nil
+}
@@ -0,0 +1,90 @@
+package piecestore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// MemoryStore is a PieceStore keeping the pieces in memory, e.g. for tests. It is safe for concurrent use.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	pieces map[piece.Key][]byte
+}
+
+// NewMemoryStore return an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{pieces: make(map[piece.Key][]byte)}
+}
+
+// Put implements PieceStore, the data is copied
+func (s *MemoryStore) Put(ctx context.Context, key piece.Key, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pieces[key] = bytes.Clone(data)
+	return nil
+}
+
+// Get implements PieceStore, the returned data is a copy
+func (s *MemoryStore) Get(ctx context.Context, key piece.Key) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.pieces[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPieceNotFound, key)
+	}
+	return bytes.Clone(data), nil
+}
+
+// Delete implements PieceStore
+func (s *MemoryStore) Delete(ctx context.Context, key piece.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pieces, key)
+	return nil
+}
+
+// Stat implements PieceStore
+func (s *MemoryStore) Stat(ctx context.Context, key piece.Key) (PieceInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return PieceInfo{}, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.pieces[key]
+	if !ok {
+		return PieceInfo{}, fmt.Errorf("%w: %s", ErrPieceNotFound, key)
+	}
+	return PieceInfo{Key: key, Size: int64(len(data))}, nil
+}
+
+// Walk implements Walker, fn is called on a snapshot of the pieces so it may use the store
+func (s *MemoryStore) Walk(ctx context.Context, fn func(info PieceInfo) error) error {
+	s.mu.RLock()
+	infos := make([]PieceInfo, 0, len(s.pieces))
+	for key, data := range s.pieces {
+		infos = append(infos, PieceInfo{Key: key, Size: int64(len(data))})
+	}
+	s.mu.RUnlock()
+	for _, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package piecestore
+
+// MetricsCollector receives the measurements of a CachedStore, the methods may be called concurrently.
+// See the metrics package for a Prometheus implementation.
+type MetricsCollector interface {
+	// ObserveCacheHit is called when a piece is served from the cache
+	ObserveCacheHit()
+	// ObserveCacheMiss is called when a piece is not in the cache and is read from the underlying store
+	ObserveCacheMiss()
+	// ObserveCacheEviction is called when the least recently used piece is evicted to make room for another
+	ObserveCacheEviction()
+	// ObserveCachedBytes is called with the total size of the cached pieces after it changed
+	ObserveCachedBytes(size int64)
+}
+
+type nopMetricsCollector struct{}
+
+func (nopMetricsCollector) ObserveCacheHit() {}
+
+func (nopMetricsCollector) ObserveCacheMiss() {}
+
+func (nopMetricsCollector) ObserveCacheEviction() {}
+
+func (nopMetricsCollector) ObserveCachedBytes(int64) {}
@@ -0,0 +1,38 @@
+// Package piecestore defines the storage of the pieces of the SPs addressed by their piece keys, with in-memory and
+// local disk implementations; package s3 implements it on S3 compatible storages
+package piecestore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// ErrPieceNotFound is returned when no piece is stored under the key
+var ErrPieceNotFound = errors.New("piece not found")
+
+// PieceInfo describes a stored piece
+type PieceInfo struct {
+	Key  piece.Key
+	Size int64
+}
+
+// PieceStore stores the segments and the ec pieces of the objects by their piece keys
+type PieceStore interface {
+	// Put stores the data under the key, replacing the piece already stored under it
+	Put(ctx context.Context, key piece.Key, data []byte) error
+	// Get return the data stored under the key, or ErrPieceNotFound
+	Get(ctx context.Context, key piece.Key) ([]byte, error)
+	// Delete removes the piece stored under the key, deleting a missing piece is not an error
+	Delete(ctx context.Context, key piece.Key) error
+	// Stat return the info of the piece stored under the key, or ErrPieceNotFound
+	Stat(ctx context.Context, key piece.Key) (PieceInfo, error)
+}
+
+// Walker is implemented by the PieceStores which can enumerate their pieces
+type Walker interface {
+	// Walk calls fn for every stored piece in no particular order, an error returned by fn stops the walk and is
+	// returned
+	Walk(ctx context.Context, fn func(info PieceInfo) error) error
+}
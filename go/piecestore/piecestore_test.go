@@ -0,0 +1,100 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// testPieceStore checks the behaviour shared by every PieceStore
+func testPieceStore(t *testing.T, store PieceStore) {
+	ctx := context.Background()
+	segment := piece.NewSegmentKey(300, 1)
+	ec := piece.NewECKey(300, 1, 2)
+
+	_, err := store.Get(ctx, segment)
+	assert.ErrorIs(t, err, ErrPieceNotFound)
+	_, err = store.Stat(ctx, segment)
+	assert.ErrorIs(t, err, ErrPieceNotFound)
+	assert.NoError(t, store.Delete(ctx, segment))
+
+	data := []byte("segment data")
+	require.NoError(t, store.Put(ctx, segment, data))
+	require.NoError(t, store.Put(ctx, ec, []byte("ec")))
+	data[0] = 'S'
+	got, err := store.Get(ctx, segment)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("segment data"), got)
+	info, err := store.Stat(ctx, ec)
+	require.NoError(t, err)
+	assert.Equal(t, PieceInfo{Key: ec, Size: 2}, info)
+
+	require.NoError(t, store.Put(ctx, segment, []byte("replaced")))
+	got, err = store.Get(ctx, segment)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("replaced"), got)
+
+	require.NoError(t, store.Put(ctx, segment, nil))
+	info, err = store.Stat(ctx, segment)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), info.Size)
+
+	require.NoError(t, store.Delete(ctx, segment))
+	_, err = store.Get(ctx, segment)
+	assert.ErrorIs(t, err, ErrPieceNotFound)
+	_, err = store.Get(ctx, ec)
+	assert.NoError(t, err)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, store.Put(canceled, segment, data), context.Canceled)
+}
+
+// testWalker runs the tests every Walker must pass
+func testWalker(t *testing.T, store interface {
+	PieceStore
+	Walker
+},
+) {
+	ctx := context.Background()
+	keys := []piece.Key{piece.NewSegmentKey(1, 0), piece.NewECKey(1, 0, 3), piece.NewECKey(257, 2, 0)}
+	for i, key := range keys {
+		require.NoError(t, store.Put(ctx, key, make([]byte, i)))
+	}
+	var infos []PieceInfo
+	require.NoError(t, store.Walk(ctx, func(info PieceInfo) error {
+		infos = append(infos, info)
+		return nil
+	}))
+	assert.ElementsMatch(t, []PieceInfo{{Key: keys[0]}, {Key: keys[1], Size: 1}, {Key: keys[2], Size: 2}}, infos)
+
+	errStop := errors.New("stop")
+	assert.ErrorIs(t, store.Walk(ctx, func(PieceInfo) error { return errStop }), errStop)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, store.Walk(canceled, func(PieceInfo) error { return nil }), context.Canceled)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testPieceStore(t, NewMemoryStore())
+	testWalker(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+	testPieceStore(t, store)
+
+	dir := t.TempDir()
+	store, err = NewFileStore(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1_s0.tmp123"), nil, 0o600))
+	testWalker(t, store)
+}
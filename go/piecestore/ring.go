@@ -0,0 +1,130 @@
+package piecestore
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// DefaultVirtualNodes is the number of virtual nodes of a shard on a Ring if NewRing is given a non-positive count
+const DefaultVirtualNodes = 128
+
+// Ring is a consistent hash ring assigning the piece keys to named shards. Every shard owns virtualNodes points of
+// the ring so the keys spread evenly, and adding or removing a shard only moves the keys of the points it owns.
+// A Ring is not safe for concurrent modification.
+type Ring struct {
+	virtualNodes int
+	points       []uint64
+	owners       map[uint64]string
+	shards       map[string]struct{}
+}
+
+// NewRing return an empty Ring placing virtualNodes points per shard
+func NewRing(virtualNodes int) *Ring {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	return &Ring{virtualNodes: virtualNodes, owners: make(map[uint64]string), shards: make(map[string]struct{})}
+}
+
+// Clone return a copy of the ring, which may be modified independently
+func (r *Ring) Clone() *Ring {
+	clone := NewRing(r.virtualNodes)
+	for shard := range r.shards {
+		clone.Add(shard)
+	}
+	return clone
+}
+
+// Add places the points of the shard on the ring, adding an existing shard does nothing
+func (r *Ring) Add(shard string) {
+	if r.Has(shard) {
+		return
+	}
+	r.shards[shard] = struct{}{}
+	for i := 0; i < r.virtualNodes; i++ {
+		point := ringHash(shard + "#" + strconv.Itoa(i))
+		if _, taken := r.owners[point]; !taken {
+			r.owners[point] = shard
+			r.points = append(r.points, point)
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+}
+
+// Remove removes the points of the shard from the ring, removing a missing shard does nothing
+func (r *Ring) Remove(shard string) {
+	if !r.Has(shard) {
+		return
+	}
+	delete(r.shards, shard)
+	points := r.points[:0]
+	for _, point := range r.points {
+		if r.owners[point] == shard {
+			delete(r.owners, point)
+			continue
+		}
+		points = append(points, point)
+	}
+	r.points = points
+}
+
+// Has return true if the shard is on the ring
+func (r *Ring) Has(shard string) bool {
+	_, ok := r.shards[shard]
+	return ok
+}
+
+// Shards return the names of the shards on the ring in ascending order
+func (r *Ring) Shards() []string {
+	shards := make([]string, 0, len(r.shards))
+	for shard := range r.shards {
+		shards = append(shards, shard)
+	}
+	sort.Strings(shards)
+	return shards
+}
+
+// Locate return the shard owning the key, which is the owner of the first point at or after the hash of the key,
+// and false if the ring is empty
+func (r *Ring) Locate(key piece.Key) (string, bool) {
+	if len(r.points) == 0 {
+		return "", false
+	}
+	hash := ringHash(key.String())
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]], true
+}
+
+// Move is the migration of a piece between two shards
+type Move struct {
+	Key  piece.Key
+	From string
+	To   string
+}
+
+// PlanRebalance return the moves of the keys whose shard on the after ring differs from the one on the before ring,
+// the keys not located on either ring are skipped
+func PlanRebalance(before, after *Ring, keys []piece.Key) []Move {
+	var moves []Move
+	for _, key := range keys {
+		from, okFrom := before.Locate(key)
+		to, okTo := after.Locate(key)
+		if okFrom && okTo && from != to {
+			moves = append(moves, Move{Key: key, From: from, To: to})
+		}
+	}
+	return moves
+}
+
+// ringHash return the position of the value on the ring, the first 8 bytes of its sha256
+func ringHash(value string) uint64 {
+	sum := sha256.Sum256([]byte(value))
+	return binary.BigEndian.Uint64(sum[:8])
+}
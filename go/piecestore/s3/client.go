@@ -0,0 +1,239 @@
+// Package s3 is a minimal client of S3 compatible object storages such as AWS S3 and MinIO, which signs the requests
+// with AWS Signature Version 4
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+const (
+	// DefaultRegion is the region used to sign the requests if Config.Region is empty
+	DefaultRegion = "us-east-1"
+
+	signAlgorithm = "AWS4-HMAC-SHA256"
+	service       = "s3"
+	// emptyPayloadHash is the sha256 of an empty request body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// Config describes an S3 compatible endpoint and the credentials to access it
+type Config struct {
+	// Endpoint is the URL of the storage, e.g. https://s3.amazonaws.com or http://127.0.0.1:9000
+	Endpoint     string
+	Region       string
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// VirtualHostStyle addresses the bucket as a sub domain of the endpoint instead of the first path segment,
+	// MinIO usually requires the path style
+	VirtualHostStyle bool
+}
+
+// Client sends signed requests to an S3 compatible storage
+type Client struct {
+	cfg        Config
+	endpoint   *url.URL
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewClient return a client of the storage described by cfg, http.DefaultClient is used if httpClient is nil
+func NewClient(cfg Config, httpClient *http.Client) (*Client, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, errors.New("the endpoint should be an absolute URL")
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{cfg: cfg, endpoint: endpoint, httpClient: httpClient, now: time.Now}, nil
+}
+
+// HeadObject return the size of the object
+func (c *Client) HeadObject(ctx context.Context, bucket, key string) (int64, error) {
+	resp, err := c.do(ctx, http.MethodHead, bucket, key, nil, nil, http.StatusOK)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.ContentLength, nil
+}
+
+// GetObject return the content of the object
+func (c *Client) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, nil, nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// PutObject stores the data as the object, replacing the existing object
+func (c *Client) PutObject(ctx context.Context, bucket, key string, data []byte) error {
+	resp, err := c.do(ctx, http.MethodPut, bucket, key, nil, data, http.StatusOK)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// DeleteObject removes the object, the storages report no error for a missing object
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, bucket, key, nil, nil, http.StatusNoContent)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// GetObjectRange return a reader of the length bytes of the object starting at offset
+func (c *Client) GetObjectRange(ctx context.Context, bucket, key string, offset, length int64) (io.ReadCloser, error) {
+	header := http.Header{}
+	header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+length-1, 10))
+	resp, err := c.do(ctx, http.MethodGet, bucket, key, header, nil, http.StatusPartialContent)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do sends a signed request with the body and return the response if its status code is expectedStatus
+func (c *Client) do(ctx context.Context, method, bucket, key string, header http.Header, body []byte,
+	expectedStatus int,
+) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, bucket, key, header, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != expectedStatus {
+		resp.Body.Close()
+		return nil, &hash.StatusError{Method: method, URL: req.URL.String(), StatusCode: resp.StatusCode}
+	}
+	return resp, nil
+}
+
+// newRequest return a signed request of the object with the body, which may be nil
+func (c *Client) newRequest(ctx context.Context, method, bucket, key string, header http.Header, body []byte) (
+	*http.Request, error,
+) {
+	u := *c.endpoint
+	objectPath := "/" + bucket + "/" + key
+	if c.cfg.VirtualHostStyle {
+		u.Host = bucket + "." + u.Host
+		objectPath = "/" + key
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + objectPath
+	u.RawPath = escapePath(u.Path)
+
+	var reader io.Reader
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		reader = bytes.NewReader(body)
+		sum := sha256.Sum256(body)
+		payloadHash = hex.EncodeToString(sum[:])
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	c.sign(req, payloadHash)
+	return req, nil
+}
+
+// sign adds the AWS Signature Version 4 authorization header to the request
+func (c *Client) sign(req *http.Request, payloadHash string) {
+	amzDate := c.now().UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := amzDate[:8] + "/" + c.cfg.Region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := signAlgorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.cfg.SecretKey), amzDate[:8])
+	signingKey = hmacSHA256(signingKey, c.cfg.Region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", signAlgorithm+" Credential="+c.cfg.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escapePath percent-encodes every byte of the path except the unreserved characters and the slashes
+func escapePath(path string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		b := path[i]
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || strings.IndexByte("-_.~/", b) >= 0 {
+			escaped.WriteByte(b)
+			continue
+		}
+		escaped.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{b})))
+	}
+	return escaped.String()
+}
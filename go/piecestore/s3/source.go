@@ -0,0 +1,39 @@
+package s3
+
+import (
+	"context"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+// Object is an object of an S3 compatible storage, it implements hash.ObjectSource
+type Object struct {
+	client *Client
+	bucket string
+	key    string
+}
+
+// Object return the object of the bucket named by key
+func (c *Client) Object(bucket, key string) *Object {
+	return &Object{client: c, bucket: bucket, key: key}
+}
+
+// Size return the size of the object
+func (o *Object) Size(ctx context.Context) (int64, error) {
+	return o.client.HeadObject(ctx, o.bucket, o.key)
+}
+
+// ReadRange return a reader of the length bytes of the object starting at offset
+func (o *Object) ReadRange(ctx context.Context, offset, length int64) (io.ReadCloser, error) {
+	return o.client.GetObjectRange(ctx, o.bucket, o.key, offset, length)
+}
+
+// ComputeIntegrityHash return the integrity hash result of the object stored in the bucket under key, the segments
+// are fetched by ranged GETs and hashed in parallel as by hash.ComputeIntegrityHashFromSource
+func (c *Client) ComputeIntegrityHash(ctx context.Context, bucket, key string, segmentSize int64, dataShards,
+	parityShards int, opts ...hash.Option,
+) (*hash.HashResult, error) {
+	return hash.ComputeIntegrityHashFromSource(ctx, c.Object(bucket, key), segmentSize, dataShards, parityShards,
+		opts...)
+}
@@ -0,0 +1,69 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+)
+
+// Store is a piecestore.PieceStore keeping every piece in an object of a bucket of an S3 compatible storage, the
+// object key is the prefix followed by the piece key
+type Store struct {
+	client *Client
+	bucket string
+	prefix string
+}
+
+// NewStore return a Store of the pieces in the bucket under the prefix, e.g. "pieces/"
+func NewStore(client *Client, bucket, prefix string) *Store {
+	return &Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *Store) objectKey(key piece.Key) string {
+	return s.prefix + key.String()
+}
+
+// Put implements piecestore.PieceStore
+func (s *Store) Put(ctx context.Context, key piece.Key, data []byte) error {
+	if data == nil {
+		data = []byte{}
+	}
+	return s.client.PutObject(ctx, s.bucket, s.objectKey(key), data)
+}
+
+// Get implements piecestore.PieceStore
+func (s *Store) Get(ctx context.Context, key piece.Key) ([]byte, error) {
+	data, err := s.client.GetObject(ctx, s.bucket, s.objectKey(key))
+	if err != nil {
+		return nil, notFound(key, err)
+	}
+	return data, nil
+}
+
+// Delete implements piecestore.PieceStore
+func (s *Store) Delete(ctx context.Context, key piece.Key) error {
+	return notFound(key, s.client.DeleteObject(ctx, s.bucket, s.objectKey(key)))
+}
+
+// Stat implements piecestore.PieceStore
+func (s *Store) Stat(ctx context.Context, key piece.Key) (piecestore.PieceInfo, error) {
+	size, err := s.client.HeadObject(ctx, s.bucket, s.objectKey(key))
+	if err != nil {
+		return piecestore.PieceInfo{}, notFound(key, err)
+	}
+	return piecestore.PieceInfo{Key: key, Size: size}, nil
+}
+
+// notFound wraps the error of a missing object into piecestore.ErrPieceNotFound
+func notFound(key piece.Key, err error) error {
+	var statusErr *hash.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: %s: %w", piecestore.ErrPieceNotFound, key, err)
+	}
+	return err
+}
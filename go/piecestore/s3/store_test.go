@@ -0,0 +1,77 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+)
+
+// fakeStorage is an in-memory S3 compatible storage serving the object requests of a single bucket
+func fakeStorage(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		data, ok := objects[r.URL.Path]
+		switch r.Method {
+		case http.MethodPut:
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			objects[r.URL.Path] = body
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodGet, http.MethodHead:
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			if r.Method == http.MethodGet {
+				_, _ = w.Write(data)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStore(t *testing.T) {
+	client, err := NewClient(Config{Endpoint: fakeStorage(t).URL, AccessKey: "minio", SecretKey: "minio123"}, nil)
+	require.NoError(t, err)
+	store := NewStore(client, "bucket", "pieces/")
+	ctx := context.Background()
+	key := piece.NewECKey(1, 2, 3)
+
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, piecestore.ErrPieceNotFound)
+	_, err = store.Stat(ctx, key)
+	assert.ErrorIs(t, err, piecestore.ErrPieceNotFound)
+
+	require.NoError(t, store.Put(ctx, key, []byte("ec piece")))
+	data, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("ec piece"), data)
+	info, err := store.Stat(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, piecestore.PieceInfo{Key: key, Size: 8}, info)
+
+	require.NoError(t, store.Delete(ctx, key))
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, piecestore.ErrPieceNotFound)
+}
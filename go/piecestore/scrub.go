@@ -0,0 +1,325 @@
+package piecestore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+var (
+	// ErrUnknownObject is returned by an ObjectSource for an object which does not exist, e.g. deleted
+	ErrUnknownObject = errors.New("unknown object")
+	// ErrRepairFailed is returned when a piece can not be rebuilt from the other pieces of its segment
+	ErrRepairFailed = errors.New("failed to repair piece")
+)
+
+// ObjectInfo describes an object as stored on chain
+type ObjectInfo struct {
+	ContentLength int64
+	Params        redundancy.RedundancyParams
+	// Roots contains the integrity hash of the PrimarySP followed by the integrity hashes of the SecondarySPs
+	// ordered by ec index, like hash.HashResult.Checksums
+	Roots [][]byte
+}
+
+// ObjectSource provides the expected state of the objects whose pieces are audited
+type ObjectSource interface {
+	// ObjectInfo return the info of the object, or ErrUnknownObject
+	ObjectInfo(ctx context.Context, objectID uint64) (ObjectInfo, error)
+	// Checksums return the piece checksums of the object ordered by segment index, the segment checksums of the
+	// PrimarySP if ecIndex is piece.NoECIndex and the checksums of the ec pieces of ecIndex otherwise
+	Checksums(ctx context.Context, objectID uint64, ecIndex int32) ([][]byte, error)
+}
+
+// ScrubReport is the outcome of a scrub of a PieceStore
+type ScrubReport struct {
+	Scanned int
+	// Corrupted are the pieces whose checksum does not match the expected checksum
+	Corrupted []piece.Key
+	// Missing are the pieces of the checksum lists of the ec indexes held by the store which are not stored
+	Missing []piece.Key
+	// Orphaned are the pieces of unknown objects or beyond the segments of their object, which can be collected
+	Orphaned []piece.Key
+	// Repaired are the corrupted and missing pieces rebuilt and stored again
+	Repaired []piece.Key
+	// Errors are the errors of the objects which could not be audited by object id, and of the pieces which could not
+	// be repaired
+	Errors map[uint64]error
+}
+
+// ScrubOption configures a Scrubber
+type ScrubOption func(*Scrubber)
+
+// WithRepair rebuilds the corrupted and missing pieces from the other pieces of their segment read from the store
+// source, e.g. a ShardedStore of the other SPs, and stores them again. The pieces are chosen with
+// redundancy.PlanRepair and a piece failing its checksum is replaced by the next available one.
+func WithRepair(source PieceStore) ScrubOption {
+	return func(s *Scrubber) {
+		s.repairSource = source
+	}
+}
+
+// Scrubber audits the pieces of a store: every piece is read and its checksum compared with the expected checksum
+// list of its object, which is verified against the integrity hash of its SP first
+type Scrubber struct {
+	store        PieceStore
+	walker       Walker
+	objects      ObjectSource
+	repairSource PieceStore
+}
+
+// NewScrubber return a Scrubber of the store, which must implement Walker, checking its pieces against objects
+func NewScrubber(store PieceStore, objects ObjectSource, opts ...ScrubOption) (*Scrubber, error) {
+	walker, ok := store.(Walker)
+	if !ok {
+		return nil, fmt.Errorf("piece store %T can not be walked", store)
+	}
+	s := &Scrubber{store: store, walker: walker, objects: objects}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+// Scrub audits every piece of the store. An object which can not be audited, e.g. because its checksums can not be
+// fetched, is recorded in the report errors and the scrub goes on; an error is returned only if the walk fails or
+// ctx is done.
+func (s *Scrubber) Scrub(ctx context.Context) (*ScrubReport, error) {
+	objects := make(map[uint64][]piece.Key)
+	err := s.walker.Walk(ctx, func(info PieceInfo) error {
+		objects[info.Key.ObjectID] = append(objects[info.Key.ObjectID], info.Key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	objectIDs := make([]uint64, 0, len(objects))
+	for objectID := range objects {
+		objectIDs = append(objectIDs, objectID)
+	}
+	sort.Slice(objectIDs, func(i, j int) bool { return objectIDs[i] < objectIDs[j] })
+
+	report := &ScrubReport{Errors: make(map[uint64]error)}
+	for _, objectID := range objectIDs {
+		if err = ctx.Err(); err != nil {
+			return report, err
+		}
+		if err = s.scrubObject(ctx, objectID, objects[objectID], report); err != nil {
+			report.Errors[objectID] = err
+		}
+	}
+	return report, nil
+}
+
+// Run scrubs the store every interval until ctx is done and sends every report to fn
+func (s *Scrubber) Run(ctx context.Context, interval time.Duration, fn func(report *ScrubReport)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		report, err := s.Scrub(ctx)
+		if err != nil {
+			log.Errorf("failed to scrub the piece store: %s", err)
+			continue
+		}
+		fn(report)
+	}
+}
+
+// objectChecksums caches the verified checksum lists of the ec indexes of an object
+type objectChecksums struct {
+	objectID uint64
+	info     ObjectInfo
+	lists    map[int32][][]byte
+}
+
+// get return the checksums of the ec index verified against its integrity hash
+func (c *objectChecksums) get(ctx context.Context, objects ObjectSource, ecIndex int32) ([][]byte, error) {
+	if checksums, ok := c.lists[ecIndex]; ok {
+		return checksums, nil
+	}
+	if int(ecIndex)+1 >= len(c.info.Roots) {
+		return nil, fmt.Errorf("ec index %d of object %d out of %d integrity hashes", ecIndex, c.objectID,
+			len(c.info.Roots))
+	}
+	checksums, err := objects.Checksums(ctx, c.objectID, ecIndex)
+	if err != nil {
+		return nil, err
+	}
+	if err = hash.VerifyIntegrityHash(c.info.Roots[ecIndex+1], checksums); err != nil {
+		return nil, fmt.Errorf("checksums of ec index %d of object %d: %w", ecIndex, c.objectID, err)
+	}
+	c.lists[ecIndex] = checksums
+	return checksums, nil
+}
+
+// scrubObject audits the stored pieces of the object
+func (s *Scrubber) scrubObject(ctx context.Context, objectID uint64, keys []piece.Key, report *ScrubReport) error {
+	report.Scanned += len(keys)
+	info, err := s.objects.ObjectInfo(ctx, objectID)
+	if errors.Is(err, ErrUnknownObject) {
+		report.Orphaned = append(report.Orphaned, sortKeys(keys)...)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	checksums := &objectChecksums{objectID: objectID, info: info, lists: make(map[int32][][]byte)}
+
+	stored := make(map[int32]map[uint32]bool)
+	var broken []piece.Key
+	for _, key := range sortKeys(keys) {
+		expected, err := checksums.get(ctx, s.objects, key.ECIndex)
+		if err != nil {
+			return err
+		}
+		if int(key.SegmentIndex) >= len(expected) {
+			report.Orphaned = append(report.Orphaned, key)
+			continue
+		}
+		if stored[key.ECIndex] == nil {
+			stored[key.ECIndex] = make(map[uint32]bool)
+		}
+		stored[key.ECIndex][key.SegmentIndex] = true
+		data, err := s.store.Get(ctx, key)
+		if errors.Is(err, ErrPieceNotFound) {
+			// deleted during the scrub
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash.GenerateChecksum(data), expected[key.SegmentIndex]) {
+			report.Corrupted = append(report.Corrupted, key)
+			broken = append(broken, key)
+		}
+	}
+	for ecIndex, segments := range stored {
+		for segIndex := range checksums.lists[ecIndex] {
+			if !segments[uint32(segIndex)] {
+				key := piece.Key{ObjectID: objectID, SegmentIndex: uint32(segIndex), ECIndex: ecIndex}
+				report.Missing = append(report.Missing, key)
+				broken = append(broken, key)
+			}
+		}
+	}
+	sortKeys(report.Missing)
+
+	if s.repairSource == nil {
+		return nil
+	}
+	var errs []error
+	for _, key := range sortKeys(broken) {
+		if err = s.repair(ctx, checksums, key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		report.Repaired = append(report.Repaired, key)
+	}
+	return errors.Join(errs...)
+}
+
+// repair rebuilds the piece from the pieces of its segment read from the repair source and stores it
+func (s *Scrubber) repair(ctx context.Context, checksums *objectChecksums, key piece.Key) error {
+	params := checksums.info.Params
+	segmentSize := params.Layout(checksums.info.ContentLength).SegmentLength(int64(key.SegmentIndex))
+	pieces := make([][]byte, params.PieceCount())
+	available := make([]bool, len(pieces))
+	for ecIndex := range available {
+		available[ecIndex] = int32(ecIndex) != key.ECIndex
+	}
+	var errs []error
+	for {
+		plan, ok := redundancy.PlanRepair(available, params.DataShards, params.ParityShards)
+		if !ok {
+			return fmt.Errorf("%w %s: %w", ErrRepairFailed, key, errors.Join(errs...))
+		}
+		complete := true
+		for _, ecIndex := range plan {
+			if pieces[ecIndex] != nil {
+				continue
+			}
+			ecKey := piece.NewECKey(key.ObjectID, key.SegmentIndex, uint32(ecIndex))
+			data, err := s.repairPiece(ctx, checksums, ecKey)
+			if err != nil {
+				errs = append(errs, err)
+				available[ecIndex] = false
+				complete = false
+				continue
+			}
+			pieces[ecIndex] = data
+		}
+		if !complete {
+			continue
+		}
+
+		used := make([][]byte, len(pieces))
+		for _, ecIndex := range plan {
+			used[ecIndex] = pieces[ecIndex]
+		}
+		segment, err := params.Decode(used, segmentSize)
+		if err != nil {
+			return fmt.Errorf("%w %s: %w", ErrRepairFailed, key, err)
+		}
+		data := segment
+		if key.IsECPiece() {
+			encoded, err := params.Encode(segment)
+			if err != nil {
+				return fmt.Errorf("%w %s: %w", ErrRepairFailed, key, err)
+			}
+			data = encoded[key.ECIndex]
+		}
+		expected, err := checksums.get(ctx, s.objects, key.ECIndex)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(hash.GenerateChecksum(data), expected[key.SegmentIndex]) {
+			return fmt.Errorf("%w %s: %w", ErrRepairFailed, key, hash.ErrPieceChecksumMismatch)
+		}
+		return s.store.Put(ctx, key, data)
+	}
+}
+
+// repairPiece reads an ec piece from the repair source and verify its checksum
+func (s *Scrubber) repairPiece(ctx context.Context, checksums *objectChecksums, key piece.Key) ([]byte, error) {
+	expected, err := checksums.get(ctx, s.objects, key.ECIndex)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.repairSource.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if int(key.SegmentIndex) >= len(expected) ||
+		!bytes.Equal(hash.GenerateChecksum(data), expected[key.SegmentIndex]) {
+		return nil, fmt.Errorf("%w: %s", hash.ErrPieceChecksumMismatch, key)
+	}
+	return data, nil
+}
+
+// sortKeys sorts the keys by object id, segment index and ec index and return them
+func sortKeys(keys []piece.Key) []piece.Key {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.ObjectID != b.ObjectID {
+			return a.ObjectID < b.ObjectID
+		}
+		if a.SegmentIndex != b.SegmentIndex {
+			return a.SegmentIndex < b.SegmentIndex
+		}
+		return a.ECIndex < b.ECIndex
+	})
+	return keys
+}
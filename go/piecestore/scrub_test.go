@@ -0,0 +1,171 @@
+package piecestore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// memoryObjects is an ObjectSource of the objects stored by storeObject
+type memoryObjects struct {
+	infos     map[uint64]ObjectInfo
+	checksums map[uint64]map[int32][][]byte
+}
+
+func (o *memoryObjects) ObjectInfo(_ context.Context, objectID uint64) (ObjectInfo, error) {
+	info, ok := o.infos[objectID]
+	if !ok {
+		return ObjectInfo{}, fmt.Errorf("%w: %d", ErrUnknownObject, objectID)
+	}
+	return info, nil
+}
+
+func (o *memoryObjects) Checksums(_ context.Context, objectID uint64, ecIndex int32) ([][]byte, error) {
+	return o.checksums[objectID][ecIndex], nil
+}
+
+// storeObject puts the segment pieces and the ec pieces of the content into store and records the object
+func (o *memoryObjects) storeObject(t *testing.T, store PieceStore, objectID uint64, content []byte,
+	params redundancy.RedundancyParams,
+) {
+	ctx := context.Background()
+	checksums := make(map[int32][][]byte)
+	for segIndex := 0; int64(segIndex)*params.SegmentSize < int64(len(content)); segIndex++ {
+		segment := content[int64(segIndex)*params.SegmentSize:min(int64(segIndex+1)*params.SegmentSize,
+			int64(len(content)))]
+		require.NoError(t, store.Put(ctx, piece.NewSegmentKey(objectID, uint32(segIndex)), segment))
+		checksums[piece.NoECIndex] = append(checksums[piece.NoECIndex], hash.GenerateChecksum(segment))
+		pieces, err := params.Encode(segment)
+		require.NoError(t, err)
+		for ecIndex, data := range pieces {
+			require.NoError(t, store.Put(ctx, piece.NewECKey(objectID, uint32(segIndex), uint32(ecIndex)), data))
+			checksums[int32(ecIndex)] = append(checksums[int32(ecIndex)], hash.GenerateChecksum(data))
+		}
+	}
+	roots, contentLength, _, err := hash.ComputeIntegrityHashSerial(bytes.NewReader(content), params.SegmentSize,
+		params.DataShards, params.ParityShards)
+	require.NoError(t, err)
+	o.infos[objectID] = ObjectInfo{ContentLength: contentLength, Params: params, Roots: roots}
+	o.checksums[objectID] = checksums
+}
+
+// copyPieces copies the pieces of the ec indexes from src to dst
+func copyPieces(t *testing.T, dst PieceStore, src *MemoryStore, ecIndexes ...int32) {
+	ctx := context.Background()
+	require.NoError(t, src.Walk(ctx, func(info PieceInfo) error {
+		for _, ecIndex := range ecIndexes {
+			if info.Key.ECIndex == ecIndex {
+				data, err := src.Get(ctx, info.Key)
+				require.NoError(t, err)
+				return dst.Put(ctx, info.Key, data)
+			}
+		}
+		return nil
+	}))
+}
+
+func TestScrubber(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	objects := &memoryObjects{infos: make(map[uint64]ObjectInfo), checksums: make(map[uint64]map[int32][][]byte)}
+	all := NewMemoryStore()
+	objects.storeObject(t, all, 1, hash.TestVectorData(3*1024+10), params)
+	objects.storeObject(t, all, 2, hash.TestVectorData(100), params)
+
+	// the store of an SP holding the segment pieces and the ec pieces of ec index 1
+	store := NewMemoryStore()
+	copyPieces(t, store, all, piece.NoECIndex, 1)
+	corrupted := []piece.Key{piece.NewECKey(1, 1, 1), piece.NewSegmentKey(1, 2)}
+	for _, key := range corrupted {
+		require.NoError(t, store.Put(ctx, key, []byte("corrupted")))
+	}
+	missing := piece.NewECKey(1, 3, 1)
+	require.NoError(t, store.Delete(ctx, missing))
+	orphaned := []piece.Key{piece.NewECKey(1, 4, 1), piece.NewSegmentKey(99, 0)}
+	for _, key := range orphaned {
+		require.NoError(t, store.Put(ctx, key, []byte("orphaned")))
+	}
+
+	scrubber, err := NewScrubber(store, objects)
+	require.NoError(t, err)
+	report, err := scrubber.Scrub(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2*4+2*1+len(orphaned)-1, report.Scanned)
+	assert.Equal(t, corrupted, report.Corrupted)
+	assert.Equal(t, []piece.Key{missing}, report.Missing)
+	assert.Equal(t, orphaned, report.Orphaned)
+	assert.Empty(t, report.Repaired)
+	assert.Empty(t, report.Errors)
+
+	// the repair falls back to a parity piece for a corrupted piece of the repair source
+	require.NoError(t, all.Put(ctx, piece.NewECKey(1, 1, 0), []byte("corrupted")))
+	scrubber, err = NewScrubber(store, objects, WithRepair(all))
+	require.NoError(t, err)
+	report, err = scrubber.Scrub(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []piece.Key{corrupted[0], corrupted[1], missing}, report.Repaired)
+	assert.Empty(t, report.Errors)
+	report, err = scrubber.Scrub(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Corrupted)
+	assert.Empty(t, report.Missing)
+	assert.Equal(t, orphaned, report.Orphaned)
+
+	// too few valid pieces in the repair source
+	require.NoError(t, store.Put(ctx, corrupted[0], []byte("corrupted")))
+	scrubber, err = NewScrubber(store, objects, WithRepair(NewMemoryStore()))
+	require.NoError(t, err)
+	report, err = scrubber.Scrub(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []piece.Key{corrupted[0]}, report.Corrupted)
+	assert.Empty(t, report.Repaired)
+	assert.ErrorIs(t, report.Errors[1], ErrRepairFailed)
+	assert.ErrorIs(t, report.Errors[1], ErrPieceNotFound)
+
+	// checksums not matching the integrity hash of the SP
+	objects.checksums[2][1] = objects.checksums[2][0]
+	report, err = scrubber.Scrub(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, report.Errors[2], hash.ErrIntegrityHashMismatch)
+}
+
+func TestScrubberRun(t *testing.T) {
+	_, err := NewScrubber(NewCachedStore(NewMemoryStore(), 1024, nil), nil)
+	assert.Error(t, err)
+
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	objects := &memoryObjects{infos: make(map[uint64]ObjectInfo), checksums: make(map[uint64]map[int32][][]byte)}
+	store := NewMemoryStore()
+	objects.storeObject(t, store, 1, hash.TestVectorData(2000), params)
+	scrubber, err := NewScrubber(store, objects)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reports := make(chan *ScrubReport, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scrubber.Run(ctx, time.Millisecond, func(report *ScrubReport) {
+			select {
+			case reports <- report:
+			default:
+			}
+		})
+	}()
+	report := <-reports
+	cancel()
+	<-done
+	assert.Equal(t, 2*7, report.Scanned)
+	assert.Empty(t, report.Corrupted)
+}
@@ -0,0 +1,193 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+var (
+	// ErrNoShard is returned when a ShardedStore has no shard to locate a piece on
+	ErrNoShard = errors.New("no shard")
+	// ErrShardExists is returned when adding a shard whose name is already used
+	ErrShardExists = errors.New("shard already exists")
+	// ErrShardNotFound is returned when a shard is unknown
+	ErrShardNotFound = errors.New("shard not found")
+)
+
+// ShardedStore is a PieceStore distributing the pieces over the PieceStores of its shards, e.g. disks or buckets,
+// with a consistent hash Ring. The shards may be added and removed online: until the next change of the shards, a
+// piece missing on its shard is looked up on the shard owning it before the last change, so the pieces stay readable
+// while the moves of PlanRebalance are migrated. It is safe for concurrent use.
+type ShardedStore struct {
+	mu       sync.RWMutex
+	ring     *Ring
+	previous *Ring
+	// stores keeps the stores of the shards of the current and the previous rings
+	stores map[string]PieceStore
+}
+
+// NewShardedStore return a ShardedStore without shards placing virtualNodes points per shard on its ring
+func NewShardedStore(virtualNodes int) *ShardedStore {
+	ring := NewRing(virtualNodes)
+	return &ShardedStore{ring: ring, previous: ring.Clone(), stores: make(map[string]PieceStore)}
+}
+
+// AddShard adds the store as the shard named name, the pieces now located on it are migrated by the moves of
+// PlanRebalance
+func (s *ShardedStore) AddShard(name string, store PieceStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.stores[name]; ok {
+		return fmt.Errorf("%w: %s", ErrShardExists, name)
+	}
+	s.stores[name] = store
+	s.change(func(ring *Ring) { ring.Add(name) })
+	return nil
+}
+
+// RemoveShard removes the shard named name, its store is kept until the next change of the shards so its pieces
+// can be migrated by the moves of PlanRebalance
+func (s *ShardedStore) RemoveShard(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ring.Has(name) {
+		return fmt.Errorf("%w: %s", ErrShardNotFound, name)
+	}
+	s.change(func(ring *Ring) { ring.Remove(name) })
+	return nil
+}
+
+// change applies fn to a copy of the ring, which becomes the current ring, and drops the stores of the shards on
+// neither the new nor the previous ring
+func (s *ShardedStore) change(fn func(ring *Ring)) {
+	s.previous = s.ring
+	s.ring = s.ring.Clone()
+	fn(s.ring)
+	for name := range s.stores {
+		if !s.ring.Has(name) && !s.previous.Has(name) {
+			delete(s.stores, name)
+		}
+	}
+}
+
+// Shards return the names of the shards in ascending order
+func (s *ShardedStore) Shards() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ring.Shards()
+}
+
+// Locate return the name of the shard of the key
+func (s *ShardedStore) Locate(key piece.Key) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	shard, ok := s.ring.Locate(key)
+	if !ok {
+		return "", ErrNoShard
+	}
+	return shard, nil
+}
+
+// PlanRebalance return the moves of the keys between the shards before and after the last change of the shards
+func (s *ShardedStore) PlanRebalance(keys []piece.Key) []Move {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return PlanRebalance(s.previous, s.ring, keys)
+}
+
+// Migrate copies the piece of the move to its destination shard and deletes it from its source shard, a piece
+// missing on the source is skipped as it is already migrated or deleted
+func (s *ShardedStore) Migrate(ctx context.Context, move Move) error {
+	s.mu.RLock()
+	from, okFrom := s.stores[move.From]
+	to, okTo := s.stores[move.To]
+	s.mu.RUnlock()
+	if !okFrom || !okTo {
+		return fmt.Errorf("%w: moving %s from %s to %s", ErrShardNotFound, move.Key, move.From, move.To)
+	}
+	data, err := from.Get(ctx, move.Key)
+	if errors.Is(err, ErrPieceNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err = to.Put(ctx, move.Key, data); err != nil {
+		return err
+	}
+	return from.Delete(ctx, move.Key)
+}
+
+// owners return the stores of the shard of the key and, if it differs, of its shard before the last change
+func (s *ShardedStore) owners(key piece.Key) (current, previous PieceStore, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	shard, ok := s.ring.Locate(key)
+	if !ok {
+		return nil, nil, ErrNoShard
+	}
+	if previousShard, ok := s.previous.Locate(key); ok && previousShard != shard {
+		previous = s.stores[previousShard]
+	}
+	return s.stores[shard], previous, nil
+}
+
+// Put implements PieceStore, the stale piece on the shard of the key before the last change is deleted so it is not
+// migrated over the new data
+func (s *ShardedStore) Put(ctx context.Context, key piece.Key, data []byte) error {
+	current, previous, err := s.owners(key)
+	if err != nil {
+		return err
+	}
+	if err = current.Put(ctx, key, data); err != nil {
+		return err
+	}
+	if previous != nil {
+		return previous.Delete(ctx, key)
+	}
+	return nil
+}
+
+// Get implements PieceStore
+func (s *ShardedStore) Get(ctx context.Context, key piece.Key) ([]byte, error) {
+	current, previous, err := s.owners(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := current.Get(ctx, key)
+	if errors.Is(err, ErrPieceNotFound) && previous != nil {
+		return previous.Get(ctx, key)
+	}
+	return data, err
+}
+
+// Delete implements PieceStore, the piece is deleted from its shard before the last change too
+func (s *ShardedStore) Delete(ctx context.Context, key piece.Key) error {
+	current, previous, err := s.owners(key)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		if err = previous.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return current.Delete(ctx, key)
+}
+
+// Stat implements PieceStore
+func (s *ShardedStore) Stat(ctx context.Context, key piece.Key) (PieceInfo, error) {
+	current, previous, err := s.owners(key)
+	if err != nil {
+		return PieceInfo{}, err
+	}
+	info, err := current.Stat(ctx, key)
+	if errors.Is(err, ErrPieceNotFound) && previous != nil {
+		return previous.Stat(ctx, key)
+	}
+	return info, err
+}
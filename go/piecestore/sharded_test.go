@@ -0,0 +1,125 @@
+package piecestore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+func testKeys(n int) []piece.Key {
+	keys := make([]piece.Key, n)
+	for i := range keys {
+		keys[i] = piece.NewECKey(uint64(i/6), uint32(i%3), uint32(i%6))
+	}
+	return keys
+}
+
+func TestRing(t *testing.T) {
+	ring := NewRing(0)
+	_, ok := ring.Locate(piece.NewSegmentKey(1, 0))
+	assert.False(t, ok)
+
+	for i := 0; i < 4; i++ {
+		ring.Add(fmt.Sprintf("disk%d", i))
+	}
+	ring.Add("disk0")
+	assert.Equal(t, []string{"disk0", "disk1", "disk2", "disk3"}, ring.Shards())
+	assert.Len(t, ring.points, 4*DefaultVirtualNodes)
+
+	keys := testKeys(6000)
+	counts := make(map[string]int)
+	for _, key := range keys {
+		shard, ok := ring.Locate(key)
+		require.True(t, ok)
+		counts[shard]++
+	}
+	for shard, count := range counts {
+		assert.InDelta(t, len(keys)/4, count, float64(len(keys))/10, shard)
+	}
+
+	after := ring.Clone()
+	after.Add("disk4")
+	moves := PlanRebalance(ring, after, keys)
+	assert.InDelta(t, len(keys)/5, len(moves), float64(len(keys))/10)
+	for _, move := range moves {
+		assert.Equal(t, "disk4", move.To)
+	}
+	assert.Empty(t, PlanRebalance(ring, ring, keys))
+
+	after.Remove("disk4")
+	after.Remove("disk9")
+	assert.Equal(t, ring.points, after.points)
+	assert.Empty(t, PlanRebalance(ring, after, keys))
+}
+
+func TestShardedStore(t *testing.T) {
+	store := NewShardedStore(16)
+	_, err := store.Get(context.Background(), piece.NewSegmentKey(1, 0))
+	assert.ErrorIs(t, err, ErrNoShard)
+
+	require.NoError(t, store.AddShard("disk0", NewMemoryStore()))
+	require.NoError(t, store.AddShard("disk1", NewMemoryStore()))
+	assert.ErrorIs(t, store.AddShard("disk1", NewMemoryStore()), ErrShardExists)
+	assert.ErrorIs(t, store.RemoveShard("disk9"), ErrShardNotFound)
+	testPieceStore(t, store)
+}
+
+func TestShardedStoreRebalance(t *testing.T) {
+	ctx := context.Background()
+	store := NewShardedStore(16)
+	require.NoError(t, store.AddShard("disk0", NewMemoryStore()))
+	require.NoError(t, store.AddShard("disk1", NewMemoryStore()))
+	keys := testKeys(300)
+	for _, key := range keys {
+		require.NoError(t, store.Put(ctx, key, []byte(key.String())))
+	}
+
+	checkKeys := func() {
+		for _, key := range keys {
+			data, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, []byte(key.String()), data)
+		}
+	}
+	migrate := func(expectMoves bool) {
+		moves := store.PlanRebalance(keys)
+		assert.Equal(t, expectMoves, len(moves) > 0)
+		for i, move := range moves {
+			require.NoError(t, store.Migrate(ctx, move))
+			if i%2 == 0 {
+				require.NoError(t, store.Migrate(ctx, move))
+			}
+		}
+		for _, key := range keys {
+			shard, err := store.Locate(key)
+			require.NoError(t, err)
+			_, err = store.stores[shard].Stat(ctx, key)
+			assert.NoError(t, err)
+		}
+	}
+
+	disk2 := NewMemoryStore()
+	require.NoError(t, store.AddShard("disk2", disk2))
+	checkKeys()
+	migrate(true)
+	checkKeys()
+	assert.NotEmpty(t, disk2.pieces)
+
+	require.NoError(t, store.RemoveShard("disk0"))
+	assert.Equal(t, []string{"disk1", "disk2"}, store.Shards())
+	checkKeys()
+	migrate(true)
+	checkKeys()
+
+	require.NoError(t, store.AddShard("disk3", NewMemoryStore()))
+	_, ok := store.stores["disk0"]
+	assert.False(t, ok)
+	assert.ErrorIs(t, store.Migrate(ctx, Move{Key: keys[0], From: "disk0", To: "disk3"}), ErrShardNotFound)
+	migrate(true)
+	checkKeys()
+}
@@ -0,0 +1,86 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/tracing"
+)
+
+// TracedStore is a PieceStore opening a span, see the tracing package, around every operation of another store. A
+// missing piece is not recorded as an error of the span. It implements Walker if the underlying store does.
+type TracedStore struct {
+	store PieceStore
+}
+
+// NewTracedStore return a TracedStore of the store
+func NewTracedStore(store PieceStore) *TracedStore {
+	return &TracedStore{store: store}
+}
+
+// Put implements PieceStore
+func (s *TracedStore) Put(ctx context.Context, key piece.Key, data []byte) (err error) {
+	ctx, span := tracing.Start(ctx, "piecestore.Put", tracing.String("key", key.String()),
+		tracing.Int("size", len(data)))
+	defer func() {
+		endSpan(span, err)
+	}()
+	return s.store.Put(ctx, key, data)
+}
+
+// Get implements PieceStore
+func (s *TracedStore) Get(ctx context.Context, key piece.Key) (data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "piecestore.Get", tracing.String("key", key.String()))
+	defer func() {
+		span.SetAttributes(tracing.Int("size", len(data)))
+		endSpan(span, err)
+	}()
+	return s.store.Get(ctx, key)
+}
+
+// Delete implements PieceStore
+func (s *TracedStore) Delete(ctx context.Context, key piece.Key) (err error) {
+	ctx, span := tracing.Start(ctx, "piecestore.Delete", tracing.String("key", key.String()))
+	defer func() {
+		endSpan(span, err)
+	}()
+	return s.store.Delete(ctx, key)
+}
+
+// Stat implements PieceStore
+func (s *TracedStore) Stat(ctx context.Context, key piece.Key) (info PieceInfo, err error) {
+	ctx, span := tracing.Start(ctx, "piecestore.Stat", tracing.String("key", key.String()))
+	defer func() {
+		endSpan(span, err)
+	}()
+	return s.store.Stat(ctx, key)
+}
+
+// Walk implements Walker, the walk is traced as a whole. It fails if the underlying store is not a Walker.
+func (s *TracedStore) Walk(ctx context.Context, fn func(info PieceInfo) error) (err error) {
+	walker, ok := s.store.(Walker)
+	if !ok {
+		return fmt.Errorf("piece store %T can not be walked", s.store)
+	}
+	ctx, span := tracing.Start(ctx, "piecestore.Walk")
+	pieces := 0
+	defer func() {
+		span.SetAttributes(tracing.Int("pieces", pieces))
+		tracing.End(span, err)
+	}()
+	return walker.Walk(ctx, func(info PieceInfo) error {
+		pieces++
+		return fn(info)
+	})
+}
+
+// endSpan ends the span of an operation, ErrPieceNotFound is marked as an attribute rather than an error
+func endSpan(span tracing.Span, err error) {
+	if errors.Is(err, ErrPieceNotFound) {
+		span.SetAttributes(tracing.Bool("not_found", true))
+		err = nil
+	}
+	tracing.End(span, err)
+}
@@ -0,0 +1,75 @@
+package piecestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/tracing"
+)
+
+type recordSpan struct {
+	name  string
+	attrs []tracing.Attribute
+	err   error
+}
+
+func (s *recordSpan) SetAttributes(attrs ...tracing.Attribute) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordSpan) End() {}
+
+type recordTracer struct {
+	spans []*recordSpan
+}
+
+func (t *recordTracer) Start(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context,
+	tracing.Span,
+) {
+	span := &recordSpan{name: name, attrs: attrs}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func TestTracedStore(t *testing.T) {
+	testPieceStore(t, NewTracedStore(NewMemoryStore()))
+	testWalker(t, NewTracedStore(NewMemoryStore()))
+
+	tracer := &recordTracer{}
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	ctx := context.Background()
+	key := piece.NewECKey(300, 1, 2)
+	store := NewTracedStore(NewMemoryStore())
+	require.NoError(t, store.Put(ctx, key, []byte("ec")))
+	_, err := store.Get(ctx, piece.NewSegmentKey(300, 1))
+	assert.ErrorIs(t, err, ErrPieceNotFound)
+	require.NoError(t, store.Walk(ctx, func(PieceInfo) error { return nil }))
+
+	require.Len(t, tracer.spans, 3)
+	assert.Equal(t, "piecestore.Put", tracer.spans[0].name)
+	assert.Equal(t, []tracing.Attribute{tracing.String("key", key.String()), tracing.Int("size", 2)},
+		tracer.spans[0].attrs)
+	assert.Equal(t, "piecestore.Get", tracer.spans[1].name)
+	assert.Contains(t, tracer.spans[1].attrs, tracing.Bool("not_found", true))
+	assert.Nil(t, tracer.spans[1].err)
+	assert.Equal(t, "piecestore.Walk", tracer.spans[2].name)
+	assert.Contains(t, tracer.spans[2].attrs, tracing.Int("pieces", 1))
+
+	errStop := errors.New("stop")
+	assert.ErrorIs(t, store.Walk(ctx, func(PieceInfo) error { return errStop }), errStop)
+	assert.ErrorIs(t, tracer.spans[3].err, errStop)
+
+	assert.Error(t, NewTracedStore(NewCachedStore(NewMemoryStore(), 1<<10, nil)).Walk(ctx,
+		func(PieceInfo) error { return nil }))
+}
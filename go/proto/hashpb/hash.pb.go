@@ -0,0 +1,551 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.0
+// 	protoc        (unknown)
+// source: mechain/common/hash/v1/hash.proto
+
+package hashpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// HashParams are the params of the redundancy of an object, the zero params take the defaults of the service.
+type HashParams struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// segment_size is the size of the segments the object is split into.
+	SegmentSize int64 `protobuf:"varint,1,opt,name=segment_size,json=segmentSize,proto3" json:"segment_size,omitempty"`
+	// data_shards is the number of data shards of a segment.
+	DataShards uint32 `protobuf:"varint,2,opt,name=data_shards,json=dataShards,proto3" json:"data_shards,omitempty"`
+	// parity_shards is the number of parity shards of a segment.
+	ParityShards uint32 `protobuf:"varint,3,opt,name=parity_shards,json=parityShards,proto3" json:"parity_shards,omitempty"`
+}
+
+func (x *HashParams) Reset() {
+	*x = HashParams{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashParams) ProtoMessage() {}
+
+func (x *HashParams) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashParams.ProtoReflect.Descriptor instead.
+func (*HashParams) Descriptor() ([]byte, []int) {
+	return file_mechain_common_hash_v1_hash_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HashParams) GetSegmentSize() int64 {
+	if x != nil {
+		return x.SegmentSize
+	}
+	return 0
+}
+
+func (x *HashParams) GetDataShards() uint32 {
+	if x != nil {
+		return x.DataShards
+	}
+	return 0
+}
+
+func (x *HashParams) GetParityShards() uint32 {
+	if x != nil {
+		return x.ParityShards
+	}
+	return 0
+}
+
+// ComputeIntegrityHashRequest is a chunk of an object to hash.
+type ComputeIntegrityHashRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// params are read from the first request of the stream only.
+	Params *HashParams `protobuf:"bytes,1,opt,name=params,proto3" json:"params,omitempty"`
+	// data is the next chunk of the object.
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ComputeIntegrityHashRequest) Reset() {
+	*x = ComputeIntegrityHashRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComputeIntegrityHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputeIntegrityHashRequest) ProtoMessage() {}
+
+func (x *ComputeIntegrityHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputeIntegrityHashRequest.ProtoReflect.Descriptor instead.
+func (*ComputeIntegrityHashRequest) Descriptor() ([]byte, []int) {
+	return file_mechain_common_hash_v1_hash_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ComputeIntegrityHashRequest) GetParams() *HashParams {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *ComputeIntegrityHashRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// HashResult describes the integrity hashes of an object.
+type HashResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// version identifies how the checksums were computed.
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// checksums contains the integrity hash of the PrimarySP followed by the integrity hashes of the SecondarySPs.
+	Checksums [][]byte `protobuf:"bytes,2,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	// content_length is the size of the object.
+	ContentLength int64 `protobuf:"varint,3,opt,name=content_length,json=contentLength,proto3" json:"content_length,omitempty"`
+	// redundancy_type is the RedundancyType of the storage module of the chain.
+	RedundancyType int32 `protobuf:"varint,4,opt,name=redundancy_type,json=redundancyType,proto3" json:"redundancy_type,omitempty"`
+}
+
+func (x *HashResult) Reset() {
+	*x = HashResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HashResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HashResult) ProtoMessage() {}
+
+func (x *HashResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HashResult.ProtoReflect.Descriptor instead.
+func (*HashResult) Descriptor() ([]byte, []int) {
+	return file_mechain_common_hash_v1_hash_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HashResult) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *HashResult) GetChecksums() [][]byte {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *HashResult) GetContentLength() int64 {
+	if x != nil {
+		return x.ContentLength
+	}
+	return 0
+}
+
+func (x *HashResult) GetRedundancyType() int32 {
+	if x != nil {
+		return x.RedundancyType
+	}
+	return 0
+}
+
+// VerifyIntegrityHashRequest is a chunk of an object to verify.
+type VerifyIntegrityHashRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// params are read from the first request of the stream only.
+	Params *HashParams `protobuf:"bytes,1,opt,name=params,proto3" json:"params,omitempty"`
+	// expected_checksums are the expected integrity hashes of the PrimarySP and of the SecondarySPs, they are read
+	// from the first request of the stream only.
+	ExpectedChecksums [][]byte `protobuf:"bytes,2,rep,name=expected_checksums,json=expectedChecksums,proto3" json:"expected_checksums,omitempty"`
+	// data is the next chunk of the object.
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *VerifyIntegrityHashRequest) Reset() {
+	*x = VerifyIntegrityHashRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyIntegrityHashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyIntegrityHashRequest) ProtoMessage() {}
+
+func (x *VerifyIntegrityHashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyIntegrityHashRequest.ProtoReflect.Descriptor instead.
+func (*VerifyIntegrityHashRequest) Descriptor() ([]byte, []int) {
+	return file_mechain_common_hash_v1_hash_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *VerifyIntegrityHashRequest) GetParams() *HashParams {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *VerifyIntegrityHashRequest) GetExpectedChecksums() [][]byte {
+	if x != nil {
+		return x.ExpectedChecksums
+	}
+	return nil
+}
+
+func (x *VerifyIntegrityHashRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// VerifyIntegrityHashResponse is the outcome of a verification.
+type VerifyIntegrityHashResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// valid is true when all the integrity hashes are the expected ones.
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// result is the computed integrity hashes of the object.
+	Result *HashResult `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	// mismatches are the indexes in checksums of the integrity hashes which differ from the expected ones.
+	Mismatches []uint32 `protobuf:"varint,3,rep,packed,name=mismatches,proto3" json:"mismatches,omitempty"`
+}
+
+func (x *VerifyIntegrityHashResponse) Reset() {
+	*x = VerifyIntegrityHashResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyIntegrityHashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyIntegrityHashResponse) ProtoMessage() {}
+
+func (x *VerifyIntegrityHashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_hash_v1_hash_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyIntegrityHashResponse.ProtoReflect.Descriptor instead.
+func (*VerifyIntegrityHashResponse) Descriptor() ([]byte, []int) {
+	return file_mechain_common_hash_v1_hash_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *VerifyIntegrityHashResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyIntegrityHashResponse) GetResult() *HashResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *VerifyIntegrityHashResponse) GetMismatches() []uint32 {
+	if x != nil {
+		return x.Mismatches
+	}
+	return nil
+}
+
+var File_mechain_common_hash_v1_hash_proto protoreflect.FileDescriptor
+
+var file_mechain_common_hash_v1_hash_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x2f, 0x68, 0x61, 0x73, 0x68, 0x2f, 0x76, 0x31, 0x2f, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x16, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x63, 0x6f, 0x6d,
+	0x6d, 0x6f, 0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x22, 0x75, 0x0a, 0x0a, 0x48,
+	0x61, 0x73, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x64, 0x61, 0x74, 0x61, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x70, 0x61, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x69, 0x74, 0x79, 0x53, 0x68, 0x61, 0x72,
+	0x64, 0x73, 0x22, 0x6d, 0x0a, 0x1b, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x49, 0x6e, 0x74,
+	0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x3a, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d,
+	0x6f, 0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x50,
+	0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x94, 0x01, 0x0a, 0x0a, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68,
+	0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x6e, 0x74,
+	0x65, 0x6e, 0x74, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0d, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12,
+	0x27, 0x0a, 0x0f, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61, 0x6e, 0x63, 0x79, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64,
+	0x61, 0x6e, 0x63, 0x79, 0x54, 0x79, 0x70, 0x65, 0x22, 0x9b, 0x01, 0x0a, 0x1a, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x79, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x3a, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31,
+	0x2e, 0x48, 0x61, 0x73, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x52, 0x06, 0x70, 0x61, 0x72,
+	0x61, 0x6d, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f,
+	0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0c, 0x52,
+	0x11, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75,
+	0x6d, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x8f, 0x01, 0x0a, 0x1b, 0x56, 0x65, 0x72, 0x69, 0x66,
+	0x79, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x3a, 0x0a, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x6d,
+	0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x68, 0x61,
+	0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x69, 0x73, 0x6d,
+	0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x0a, 0x6d, 0x69,
+	0x73, 0x6d, 0x61, 0x74, 0x63, 0x68, 0x65, 0x73, 0x32, 0x83, 0x02, 0x0a, 0x0b, 0x48, 0x61, 0x73,
+	0x68, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x71, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x70,
+	0x75, 0x74, 0x65, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68,
+	0x12, 0x33, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f,
+	0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74,
+	0x65, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e,
+	0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x48,
+	0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x28, 0x01, 0x12, 0x80, 0x01, 0x0a, 0x13,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48,
+	0x61, 0x73, 0x68, 0x12, 0x32, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72,
+	0x69, 0x66, 0x79, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x48, 0x61, 0x73, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x33, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69,
+	0x6e, 0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x68, 0x61, 0x73, 0x68, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x49, 0x6e, 0x74, 0x65, 0x67, 0x72, 0x69, 0x74, 0x79,
+	0x48, 0x61, 0x73, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x42, 0x34,
+	0x5a, 0x32, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x6b, 0x4d,
+	0x65, 0x4c, 0x61, 0x62, 0x73, 0x2f, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2d, 0x63, 0x6f,
+	0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x67, 0x6f, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x68, 0x61,
+	0x73, 0x68, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_mechain_common_hash_v1_hash_proto_rawDescOnce sync.Once
+	file_mechain_common_hash_v1_hash_proto_rawDescData = file_mechain_common_hash_v1_hash_proto_rawDesc
+)
+
+func file_mechain_common_hash_v1_hash_proto_rawDescGZIP() []byte {
+	file_mechain_common_hash_v1_hash_proto_rawDescOnce.Do(func() {
+		file_mechain_common_hash_v1_hash_proto_rawDescData = protoimpl.X.CompressGZIP(file_mechain_common_hash_v1_hash_proto_rawDescData)
+	})
+	return file_mechain_common_hash_v1_hash_proto_rawDescData
+}
+
+var file_mechain_common_hash_v1_hash_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_mechain_common_hash_v1_hash_proto_goTypes = []interface{}{
+	(*HashParams)(nil),                  // 0: mechain.common.hash.v1.HashParams
+	(*ComputeIntegrityHashRequest)(nil), // 1: mechain.common.hash.v1.ComputeIntegrityHashRequest
+	(*HashResult)(nil),                  // 2: mechain.common.hash.v1.HashResult
+	(*VerifyIntegrityHashRequest)(nil),  // 3: mechain.common.hash.v1.VerifyIntegrityHashRequest
+	(*VerifyIntegrityHashResponse)(nil), // 4: mechain.common.hash.v1.VerifyIntegrityHashResponse
+}
+var file_mechain_common_hash_v1_hash_proto_depIdxs = []int32{
+	0, // 0: mechain.common.hash.v1.ComputeIntegrityHashRequest.params:type_name -> mechain.common.hash.v1.HashParams
+	0, // 1: mechain.common.hash.v1.VerifyIntegrityHashRequest.params:type_name -> mechain.common.hash.v1.HashParams
+	2, // 2: mechain.common.hash.v1.VerifyIntegrityHashResponse.result:type_name -> mechain.common.hash.v1.HashResult
+	1, // 3: mechain.common.hash.v1.HashService.ComputeIntegrityHash:input_type -> mechain.common.hash.v1.ComputeIntegrityHashRequest
+	3, // 4: mechain.common.hash.v1.HashService.VerifyIntegrityHash:input_type -> mechain.common.hash.v1.VerifyIntegrityHashRequest
+	2, // 5: mechain.common.hash.v1.HashService.ComputeIntegrityHash:output_type -> mechain.common.hash.v1.HashResult
+	4, // 6: mechain.common.hash.v1.HashService.VerifyIntegrityHash:output_type -> mechain.common.hash.v1.VerifyIntegrityHashResponse
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_mechain_common_hash_v1_hash_proto_init() }
+func file_mechain_common_hash_v1_hash_proto_init() {
+	if File_mechain_common_hash_v1_hash_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_mechain_common_hash_v1_hash_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashParams); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mechain_common_hash_v1_hash_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ComputeIntegrityHashRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mechain_common_hash_v1_hash_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HashResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mechain_common_hash_v1_hash_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyIntegrityHashRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mechain_common_hash_v1_hash_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyIntegrityHashResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mechain_common_hash_v1_hash_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mechain_common_hash_v1_hash_proto_goTypes,
+		DependencyIndexes: file_mechain_common_hash_v1_hash_proto_depIdxs,
+		MessageInfos:      file_mechain_common_hash_v1_hash_proto_msgTypes,
+	}.Build()
+	File_mechain_common_hash_v1_hash_proto = out.File
+	file_mechain_common_hash_v1_hash_proto_rawDesc = nil
+	file_mechain_common_hash_v1_hash_proto_goTypes = nil
+	file_mechain_common_hash_v1_hash_proto_depIdxs = nil
+}
@@ -0,0 +1,149 @@
+// Package piecepb is the canonical schema of the hash and erasure coding metadata of the objects and of their pieces,
+// generated from proto/mechain/common/piece/v1/piece.proto, so the SP databases and APIs serialize it the same way.
+// The helpers of convert.go convert it from and to the types of the storage module of the chain and the piece keys.
+package piecepb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// ErrInvalidIntegrityMeta is returned by Validate when the segments of an ObjectIntegrityMeta are not consistent
+var ErrInvalidIntegrityMeta = errors.New("invalid object integrity meta")
+
+// NewPieceInfo return the info of the piece identified by the key
+func NewPieceInfo(key piece.Key, size int64, checksum []byte) *PieceInfo {
+	return &PieceInfo{
+		ObjectId:     key.ObjectID,
+		SegmentIndex: key.SegmentIndex,
+		EcIndex:      key.ECIndex,
+		Size:         size,
+		Checksum:     checksum,
+	}
+}
+
+// Key return the key of the piece
+func (x *PieceInfo) Key() piece.Key {
+	return piece.Key{ObjectID: x.GetObjectId(), SegmentIndex: x.GetSegmentIndex(), ECIndex: x.GetEcIndex()}
+}
+
+// Verify checks the data of the piece, e.g. once it is transferred. The cheap fast checksum is checked first if it
+// was computed, so a corrupted transfer fails with hash.ErrFastChecksumMismatch without computing the sha256 of the
+// data, which must match the checksum otherwise hash.ErrPieceChecksumMismatch is returned.
+func (x *PieceInfo) Verify(data []byte) error {
+	if x.GetFastChecksum() != 0 {
+		if err := hash.VerifyFastChecksum(data, x.GetFastChecksum()); err != nil {
+			return fmt.Errorf("piece %s: %w", x.Key(), err)
+		}
+	}
+	if !bytes.Equal(hash.GenerateChecksum(data), x.GetChecksum()) {
+		return fmt.Errorf("piece %s: %w", x.Key(), hash.ErrPieceChecksumMismatch)
+	}
+	return nil
+}
+
+// NewObjectIntegrityMeta return the meta of an object of the chain stored with the params, the checksums are shared
+// with the object info. The chain records neither the version of the checksums nor the segments, the meta has no
+// version and no segment.
+func NewObjectIntegrityMeta(info *storagetypes.ObjectInfo, params *storagetypes.VersionedParams) *ObjectIntegrityMeta {
+	return &ObjectIntegrityMeta{
+		ObjectId:       info.Id.Uint64(),
+		PayloadSize:    int64(info.PayloadSize),
+		RedundancyType: int32(info.RedundancyType),
+		SegmentSize:    int64(params.MaxSegmentSize),
+		DataShards:     params.RedundantDataChunkNum,
+		ParityShards:   params.RedundantParityChunkNum,
+		Checksums:      info.Checksums,
+	}
+}
+
+// ObjectInfo return the object info of the chain holding the id, the payload size, the redundancy type and the
+// checksums of the meta, the checksums are shared with the meta
+func (x *ObjectIntegrityMeta) ObjectInfo() *storagetypes.ObjectInfo {
+	return &storagetypes.ObjectInfo{
+		Id:             sdkmath.NewUint(x.GetObjectId()),
+		PayloadSize:    uint64(x.GetPayloadSize()),
+		RedundancyType: x.StorageRedundancyType(),
+		Checksums:      x.GetChecksums(),
+	}
+}
+
+// VersionedParams return the params of the chain holding the segment size and the shards of the meta
+func (x *ObjectIntegrityMeta) VersionedParams() *storagetypes.VersionedParams {
+	return &storagetypes.VersionedParams{
+		MaxSegmentSize:          uint64(x.GetSegmentSize()),
+		RedundantDataChunkNum:   x.GetDataShards(),
+		RedundantParityChunkNum: x.GetParityShards(),
+	}
+}
+
+// StorageRedundancyType return the redundancy type of the meta as the RedundancyType of the chain
+func (x *ObjectIntegrityMeta) StorageRedundancyType() storagetypes.RedundancyType {
+	return storagetypes.RedundancyType(x.GetRedundancyType())
+}
+
+// PieceInfos return the infos of the pieces of the segments of the meta, the segment piece of each segment followed
+// by its erasure encoded pieces, with their fast checksums if they were computed
+func (x *ObjectIntegrityMeta) PieceInfos() []*PieceInfo {
+	infos := make([]*PieceInfo, 0, len(x.GetSegments())*(1+int(x.GetDataShards()+x.GetParityShards())))
+	for _, segment := range x.GetSegments() {
+		segmentInfo := NewPieceInfo(piece.NewSegmentKey(x.GetObjectId(), segment.GetSegmentIndex()),
+			segment.GetSize(), segment.GetChecksum())
+		segmentInfo.FastChecksum = segment.GetFastChecksum()
+		infos = append(infos, segmentInfo)
+		for ecIndex, checksum := range segment.GetPieceChecksums() {
+			pieceInfo := NewPieceInfo(piece.NewECKey(x.GetObjectId(), segment.GetSegmentIndex(), uint32(ecIndex)),
+				segment.GetPieceSize(), checksum)
+			if ecIndex < len(segment.GetPieceFastChecksums()) {
+				pieceInfo.FastChecksum = segment.GetPieceFastChecksums()[ecIndex]
+			}
+			infos = append(infos, pieceInfo)
+		}
+	}
+	return infos
+}
+
+// Validate checks that the integrity hashes and the segments of the meta are consistent with its payload size, its
+// segment size and its shards, a meta without segment is valid. It returns ErrInvalidIntegrityMeta otherwise.
+func (x *ObjectIntegrityMeta) Validate() error {
+	if x.GetPayloadSize() < 0 || x.GetSegmentSize() <= 0 || x.GetDataShards() == 0 {
+		return fmt.Errorf("%w: payload size %d, segment size %d, %d data shards", ErrInvalidIntegrityMeta,
+			x.GetPayloadSize(), x.GetSegmentSize(), x.GetDataShards())
+	}
+	pieceCount := int(x.GetDataShards() + x.GetParityShards())
+	if len(x.GetChecksums()) != 0 && len(x.GetChecksums()) != pieceCount+1 {
+		return fmt.Errorf("%w: %d integrity hashes for %d pieces", ErrInvalidIntegrityMeta, len(x.GetChecksums()),
+			pieceCount)
+	}
+	if len(x.GetSegments()) == 0 {
+		return nil
+	}
+
+	segmentCount := (x.GetPayloadSize() + x.GetSegmentSize() - 1) / x.GetSegmentSize()
+	if int64(len(x.GetSegments())) != segmentCount {
+		return fmt.Errorf("%w: %d segments, expect %d", ErrInvalidIntegrityMeta, len(x.GetSegments()), segmentCount)
+	}
+	for index, segment := range x.GetSegments() {
+		size := min(x.GetSegmentSize(), x.GetPayloadSize()-int64(index)*x.GetSegmentSize())
+		if segment.GetSegmentIndex() != uint32(index) || segment.GetSize() != size {
+			return fmt.Errorf("%w: segment %d has index %d and size %d, expect size %d", ErrInvalidIntegrityMeta,
+				index, segment.GetSegmentIndex(), segment.GetSize(), size)
+		}
+		if len(segment.GetPieceChecksums()) != pieceCount {
+			return fmt.Errorf("%w: segment %d has %d piece checksums for %d pieces", ErrInvalidIntegrityMeta, index,
+				len(segment.GetPieceChecksums()), pieceCount)
+		}
+		if fastChecksums := len(segment.GetPieceFastChecksums()); fastChecksums != 0 && fastChecksums != pieceCount {
+			return fmt.Errorf("%w: segment %d has %d piece fast checksums for %d pieces", ErrInvalidIntegrityMeta,
+				index, fastChecksums, pieceCount)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package piecepb
+
+import (
+	"testing"
+
+	sdkmath "cosmossdk.io/math"
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+func testMeta() *ObjectIntegrityMeta {
+	checksums := func(count int) [][]byte {
+		list := make([][]byte, count)
+		for i := range list {
+			list[i] = []byte{byte(i), 1, 2, 3}
+		}
+		return list
+	}
+	return &ObjectIntegrityMeta{
+		ObjectId:       100,
+		Version:        1,
+		PayloadSize:    2500,
+		RedundancyType: int32(storagetypes.REDUNDANCY_EC_TYPE),
+		SegmentSize:    1024,
+		DataShards:     4,
+		ParityShards:   2,
+		Checksums:      checksums(7),
+		Segments: []*SegmentInfo{
+			{SegmentIndex: 0, Size: 1024, Checksum: []byte{0}, PieceSize: 256, PieceChecksums: checksums(6)},
+			{SegmentIndex: 1, Size: 1024, Checksum: []byte{1}, PieceSize: 256, PieceChecksums: checksums(6)},
+			{SegmentIndex: 2, Size: 452, Checksum: []byte{2}, PieceSize: 113, PieceChecksums: checksums(6)},
+		},
+	}
+}
+
+func TestPieceInfo(t *testing.T) {
+	for _, key := range []piece.Key{piece.NewSegmentKey(100, 2), piece.NewECKey(100, 2, 5)} {
+		info := NewPieceInfo(key, 1024, []byte{1, 2})
+		assert.Equal(t, key, info.Key())
+		assert.Equal(t, int64(1024), info.GetSize())
+	}
+
+	infos := testMeta().PieceInfos()
+	require.Len(t, infos, 3*7)
+	assert.Equal(t, "100_s0", infos[0].Key().String())
+	assert.Equal(t, "100_s2_p5", infos[20].Key().String())
+	assert.Equal(t, int64(113), infos[20].GetSize())
+}
+
+func TestPieceInfoVerify(t *testing.T) {
+	data := []byte("piece data")
+	info := NewPieceInfo(piece.NewECKey(100, 0, 1), int64(len(data)), hash.GenerateChecksum(data))
+	assert.NoError(t, info.Verify(data))
+	assert.ErrorIs(t, info.Verify([]byte("piece date")), hash.ErrPieceChecksumMismatch)
+
+	// the corrupted data fail the fast checksum before the sha256 checksum
+	info.FastChecksum = hash.FastChecksum(data)
+	assert.NoError(t, info.Verify(data))
+	err := info.Verify([]byte("piece date"))
+	assert.ErrorIs(t, err, hash.ErrFastChecksumMismatch)
+	assert.NotErrorIs(t, err, hash.ErrPieceChecksumMismatch)
+
+	meta := testMeta()
+	meta.Segments[2].FastChecksum = 7
+	meta.Segments[2].PieceFastChecksums = []uint32{1, 2, 3, 4, 5, 6}
+	infos := meta.PieceInfos()
+	assert.Equal(t, uint32(0), infos[1].GetFastChecksum())
+	assert.Equal(t, uint32(7), infos[14].GetFastChecksum())
+	assert.Equal(t, uint32(6), infos[20].GetFastChecksum())
+	assert.NoError(t, meta.Validate())
+	meta.Segments[2].PieceFastChecksums = meta.Segments[2].PieceFastChecksums[1:]
+	assert.ErrorIs(t, meta.Validate(), ErrInvalidIntegrityMeta)
+}
+
+func TestObjectInfoConversion(t *testing.T) {
+	meta := testMeta()
+	info := meta.ObjectInfo()
+	assert.Equal(t, sdkmath.NewUint(100), info.Id)
+	assert.Equal(t, uint64(2500), info.PayloadSize)
+	assert.Equal(t, storagetypes.REDUNDANCY_EC_TYPE, info.RedundancyType)
+	params := meta.VersionedParams()
+	assert.Equal(t, uint64(1024), params.MaxSegmentSize)
+
+	// the chain records neither the version nor the segments
+	converted := NewObjectIntegrityMeta(info, params)
+	meta.Version, meta.Segments = 0, nil
+	assert.True(t, proto.Equal(meta, converted))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, testMeta().Validate())
+	meta := testMeta()
+	meta.Segments = nil
+	assert.NoError(t, meta.Validate())
+	empty := &ObjectIntegrityMeta{SegmentSize: 1024, DataShards: 4, ParityShards: 2}
+	assert.NoError(t, empty.Validate())
+
+	for name, corrupt := range map[string]func(meta *ObjectIntegrityMeta){
+		"no data shard":    func(meta *ObjectIntegrityMeta) { meta.DataShards = 0 },
+		"missing checksum": func(meta *ObjectIntegrityMeta) { meta.Checksums = meta.Checksums[1:] },
+		"missing segment":  func(meta *ObjectIntegrityMeta) { meta.Segments = meta.Segments[1:] },
+		"wrong size":       func(meta *ObjectIntegrityMeta) { meta.Segments[2].Size = 1024 },
+		"missing piece": func(meta *ObjectIntegrityMeta) {
+			meta.Segments[1].PieceChecksums = meta.Segments[1].PieceChecksums[1:]
+		},
+	} {
+		meta := testMeta()
+		corrupt(meta)
+		assert.ErrorIs(t, meta.Validate(), ErrInvalidIntegrityMeta, name)
+	}
+}
@@ -0,0 +1,496 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.0
+// 	protoc        (unknown)
+// source: mechain/common/piece/v1/piece.proto
+
+package piecepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PieceInfo describes a piece stored by a SP, either a segment stored by the PrimarySP or an erasure encoded piece of
+// a segment stored by a SecondarySP.
+type PieceInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// object_id is the id of the object on the chain.
+	ObjectId uint64 `protobuf:"varint,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	// segment_index is the index of the segment in the object.
+	SegmentIndex uint32 `protobuf:"varint,2,opt,name=segment_index,json=segmentIndex,proto3" json:"segment_index,omitempty"`
+	// ec_index is the erasure coding index of the piece, it is -1 for a segment piece.
+	EcIndex int32 `protobuf:"varint,3,opt,name=ec_index,json=ecIndex,proto3" json:"ec_index,omitempty"`
+	// size is the size of the piece.
+	Size int64 `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	// checksum is the sha256 checksum of the piece.
+	Checksum []byte `protobuf:"bytes,5,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// fast_checksum is the CRC32C of the piece, which detects the corruption of a transfer before the sha256
+	// verification, it is 0 when it was not computed.
+	FastChecksum uint32 `protobuf:"fixed32,6,opt,name=fast_checksum,json=fastChecksum,proto3" json:"fast_checksum,omitempty"`
+}
+
+func (x *PieceInfo) Reset() {
+	*x = PieceInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_piece_v1_piece_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PieceInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PieceInfo) ProtoMessage() {}
+
+func (x *PieceInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_piece_v1_piece_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PieceInfo.ProtoReflect.Descriptor instead.
+func (*PieceInfo) Descriptor() ([]byte, []int) {
+	return file_mechain_common_piece_v1_piece_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PieceInfo) GetObjectId() uint64 {
+	if x != nil {
+		return x.ObjectId
+	}
+	return 0
+}
+
+func (x *PieceInfo) GetSegmentIndex() uint32 {
+	if x != nil {
+		return x.SegmentIndex
+	}
+	return 0
+}
+
+func (x *PieceInfo) GetEcIndex() int32 {
+	if x != nil {
+		return x.EcIndex
+	}
+	return 0
+}
+
+func (x *PieceInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *PieceInfo) GetChecksum() []byte {
+	if x != nil {
+		return x.Checksum
+	}
+	return nil
+}
+
+func (x *PieceInfo) GetFastChecksum() uint32 {
+	if x != nil {
+		return x.FastChecksum
+	}
+	return 0
+}
+
+// SegmentInfo describes a segment of an object and its erasure encoded pieces.
+type SegmentInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// segment_index is the index of the segment in the object.
+	SegmentIndex uint32 `protobuf:"varint,1,opt,name=segment_index,json=segmentIndex,proto3" json:"segment_index,omitempty"`
+	// size is the size of the segment, only the last segment of an object is smaller than the segment size.
+	Size int64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// checksum is the sha256 checksum of the segment.
+	Checksum []byte `protobuf:"bytes,3,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	// piece_size is the size of each erasure encoded piece of the segment.
+	PieceSize int64 `protobuf:"varint,4,opt,name=piece_size,json=pieceSize,proto3" json:"piece_size,omitempty"`
+	// piece_checksums are the sha256 checksums of the erasure encoded pieces of the segment by ec index.
+	PieceChecksums [][]byte `protobuf:"bytes,5,rep,name=piece_checksums,json=pieceChecksums,proto3" json:"piece_checksums,omitempty"`
+	// fast_checksum is the CRC32C of the segment, it is 0 when it was not computed.
+	FastChecksum uint32 `protobuf:"fixed32,6,opt,name=fast_checksum,json=fastChecksum,proto3" json:"fast_checksum,omitempty"`
+	// piece_fast_checksums are the CRC32C of the erasure encoded pieces of the segment by ec index, they are empty
+	// when they were not computed.
+	PieceFastChecksums []uint32 `protobuf:"fixed32,7,rep,packed,name=piece_fast_checksums,json=pieceFastChecksums,proto3" json:"piece_fast_checksums,omitempty"`
+}
+
+func (x *SegmentInfo) Reset() {
+	*x = SegmentInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_piece_v1_piece_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SegmentInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SegmentInfo) ProtoMessage() {}
+
+func (x *SegmentInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_piece_v1_piece_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SegmentInfo.ProtoReflect.Descriptor instead.
+func (*SegmentInfo) Descriptor() ([]byte, []int) {
+	return file_mechain_common_piece_v1_piece_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SegmentInfo) GetSegmentIndex() uint32 {
+	if x != nil {
+		return x.SegmentIndex
+	}
+	return 0
+}
+
+func (x *SegmentInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SegmentInfo) GetChecksum() []byte {
+	if x != nil {
+		return x.Checksum
+	}
+	return nil
+}
+
+func (x *SegmentInfo) GetPieceSize() int64 {
+	if x != nil {
+		return x.PieceSize
+	}
+	return 0
+}
+
+func (x *SegmentInfo) GetPieceChecksums() [][]byte {
+	if x != nil {
+		return x.PieceChecksums
+	}
+	return nil
+}
+
+func (x *SegmentInfo) GetFastChecksum() uint32 {
+	if x != nil {
+		return x.FastChecksum
+	}
+	return 0
+}
+
+func (x *SegmentInfo) GetPieceFastChecksums() []uint32 {
+	if x != nil {
+		return x.PieceFastChecksums
+	}
+	return nil
+}
+
+// ObjectIntegrityMeta describes the integrity hashes and the erasure coding of an object.
+type ObjectIntegrityMeta struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// object_id is the id of the object on the chain.
+	ObjectId uint64 `protobuf:"varint,1,opt,name=object_id,json=objectId,proto3" json:"object_id,omitempty"`
+	// version identifies how the checksums were computed, it is 0 when it is unknown.
+	Version uint32 `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	// payload_size is the size of the object.
+	PayloadSize int64 `protobuf:"varint,3,opt,name=payload_size,json=payloadSize,proto3" json:"payload_size,omitempty"`
+	// redundancy_type is the RedundancyType of the storage module of the chain.
+	RedundancyType int32 `protobuf:"varint,4,opt,name=redundancy_type,json=redundancyType,proto3" json:"redundancy_type,omitempty"`
+	// segment_size is the size of the segments the object is split into.
+	SegmentSize int64 `protobuf:"varint,5,opt,name=segment_size,json=segmentSize,proto3" json:"segment_size,omitempty"`
+	// data_shards is the number of data shards of a segment.
+	DataShards uint32 `protobuf:"varint,6,opt,name=data_shards,json=dataShards,proto3" json:"data_shards,omitempty"`
+	// parity_shards is the number of parity shards of a segment.
+	ParityShards uint32 `protobuf:"varint,7,opt,name=parity_shards,json=parityShards,proto3" json:"parity_shards,omitempty"`
+	// checksums contains the integrity hash of the PrimarySP followed by the integrity hashes of the SecondarySPs.
+	Checksums [][]byte `protobuf:"bytes,8,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	// segments describe the segments of the object, they are empty when only the integrity hashes are known.
+	Segments []*SegmentInfo `protobuf:"bytes,9,rep,name=segments,proto3" json:"segments,omitempty"`
+}
+
+func (x *ObjectIntegrityMeta) Reset() {
+	*x = ObjectIntegrityMeta{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_mechain_common_piece_v1_piece_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ObjectIntegrityMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ObjectIntegrityMeta) ProtoMessage() {}
+
+func (x *ObjectIntegrityMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_mechain_common_piece_v1_piece_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ObjectIntegrityMeta.ProtoReflect.Descriptor instead.
+func (*ObjectIntegrityMeta) Descriptor() ([]byte, []int) {
+	return file_mechain_common_piece_v1_piece_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ObjectIntegrityMeta) GetObjectId() uint64 {
+	if x != nil {
+		return x.ObjectId
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetVersion() uint32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetPayloadSize() int64 {
+	if x != nil {
+		return x.PayloadSize
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetRedundancyType() int32 {
+	if x != nil {
+		return x.RedundancyType
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetSegmentSize() int64 {
+	if x != nil {
+		return x.SegmentSize
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetDataShards() uint32 {
+	if x != nil {
+		return x.DataShards
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetParityShards() uint32 {
+	if x != nil {
+		return x.ParityShards
+	}
+	return 0
+}
+
+func (x *ObjectIntegrityMeta) GetChecksums() [][]byte {
+	if x != nil {
+		return x.Checksums
+	}
+	return nil
+}
+
+func (x *ObjectIntegrityMeta) GetSegments() []*SegmentInfo {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+var File_mechain_common_piece_v1_piece_proto protoreflect.FileDescriptor
+
+var file_mechain_common_piece_v1_piece_proto_rawDesc = []byte{
+	0x0a, 0x23, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2f, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e,
+	0x2f, 0x70, 0x69, 0x65, 0x63, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x70, 0x69, 0x65, 0x63, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x17, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2e, 0x63,
+	0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x69, 0x65, 0x63, 0x65, 0x2e, 0x76, 0x31, 0x22, 0xbd,
+	0x01, 0x0a, 0x09, 0x50, 0x69, 0x65, 0x63, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1b, 0x0a, 0x09,
+	0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x08, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x65, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x19,
+	0x0a, 0x08, 0x65, 0x63, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x07, 0x65, 0x63, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x61, 0x73,
+	0x74, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x07,
+	0x52, 0x0c, 0x66, 0x61, 0x73, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x22, 0x81,
+	0x02, 0x0a, 0x0b, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x23,
+	0x0a, 0x0d, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e,
+	0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x70, 0x69, 0x65, 0x63, 0x65, 0x53, 0x69,
+	0x7a, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x63, 0x68, 0x65, 0x63,
+	0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x0e, 0x70, 0x69, 0x65,
+	0x63, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x66,
+	0x61, 0x73, 0x74, 0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x07, 0x52, 0x0c, 0x66, 0x61, 0x73, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d,
+	0x12, 0x30, 0x0a, 0x14, 0x70, 0x69, 0x65, 0x63, 0x65, 0x5f, 0x66, 0x61, 0x73, 0x74, 0x5f, 0x63,
+	0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x07, 0x52, 0x12,
+	0x70, 0x69, 0x65, 0x63, 0x65, 0x46, 0x61, 0x73, 0x74, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75,
+	0x6d, 0x73, 0x22, 0xe1, 0x02, 0x0a, 0x13, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x6e, 0x74,
+	0x65, 0x67, 0x72, 0x69, 0x74, 0x79, 0x4d, 0x65, 0x74, 0x61, 0x12, 0x1b, 0x0a, 0x09, 0x6f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x5f, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64,
+	0x53, 0x69, 0x7a, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x64, 0x75, 0x6e, 0x64, 0x61, 0x6e,
+	0x63, 0x79, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x72,
+	0x65, 0x64, 0x75, 0x6e, 0x64, 0x61, 0x6e, 0x63, 0x79, 0x54, 0x79, 0x70, 0x65, 0x12, 0x21, 0x0a,
+	0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0b, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x69, 0x7a, 0x65,
+	0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x61, 0x74, 0x61, 0x53, 0x68, 0x61, 0x72, 0x64,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x61, 0x72, 0x69, 0x74, 0x79, 0x5f, 0x73, 0x68, 0x61, 0x72,
+	0x64, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x61, 0x72, 0x69, 0x74, 0x79,
+	0x53, 0x68, 0x61, 0x72, 0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73,
+	0x75, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x09, 0x63, 0x68, 0x65, 0x63, 0x6b,
+	0x73, 0x75, 0x6d, 0x73, 0x12, 0x40, 0x0a, 0x08, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73,
+	0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x24, 0x2e, 0x6d, 0x65, 0x63, 0x68, 0x61, 0x69, 0x6e,
+	0x2e, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2e, 0x70, 0x69, 0x65, 0x63, 0x65, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08, 0x73, 0x65,
+	0x67, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x7a, 0x6b, 0x4d, 0x65, 0x4c, 0x61, 0x62, 0x73, 0x2f, 0x6d, 0x65,
+	0x63, 0x68, 0x61, 0x69, 0x6e, 0x2d, 0x63, 0x6f, 0x6d, 0x6d, 0x6f, 0x6e, 0x2f, 0x67, 0x6f, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x70, 0x69, 0x65, 0x63, 0x65, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_mechain_common_piece_v1_piece_proto_rawDescOnce sync.Once
+	file_mechain_common_piece_v1_piece_proto_rawDescData = file_mechain_common_piece_v1_piece_proto_rawDesc
+)
+
+func file_mechain_common_piece_v1_piece_proto_rawDescGZIP() []byte {
+	file_mechain_common_piece_v1_piece_proto_rawDescOnce.Do(func() {
+		file_mechain_common_piece_v1_piece_proto_rawDescData = protoimpl.X.CompressGZIP(file_mechain_common_piece_v1_piece_proto_rawDescData)
+	})
+	return file_mechain_common_piece_v1_piece_proto_rawDescData
+}
+
+var file_mechain_common_piece_v1_piece_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_mechain_common_piece_v1_piece_proto_goTypes = []interface{}{
+	(*PieceInfo)(nil),           // 0: mechain.common.piece.v1.PieceInfo
+	(*SegmentInfo)(nil),         // 1: mechain.common.piece.v1.SegmentInfo
+	(*ObjectIntegrityMeta)(nil), // 2: mechain.common.piece.v1.ObjectIntegrityMeta
+}
+var file_mechain_common_piece_v1_piece_proto_depIdxs = []int32{
+	1, // 0: mechain.common.piece.v1.ObjectIntegrityMeta.segments:type_name -> mechain.common.piece.v1.SegmentInfo
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_mechain_common_piece_v1_piece_proto_init() }
+func file_mechain_common_piece_v1_piece_proto_init() {
+	if File_mechain_common_piece_v1_piece_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_mechain_common_piece_v1_piece_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PieceInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mechain_common_piece_v1_piece_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SegmentInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_mechain_common_piece_v1_piece_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ObjectIntegrityMeta); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_mechain_common_piece_v1_piece_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_mechain_common_piece_v1_piece_proto_goTypes,
+		DependencyIndexes: file_mechain_common_piece_v1_piece_proto_depIdxs,
+		MessageInfos:      file_mechain_common_piece_v1_piece_proto_msgTypes,
+	}.Build()
+	File_mechain_common_piece_v1_piece_proto = out.File
+	file_mechain_common_piece_v1_piece_proto_rawDesc = nil
+	file_mechain_common_piece_v1_piece_proto_goTypes = nil
+	file_mechain_common_piece_v1_piece_proto_depIdxs = nil
+}
@@ -0,0 +1,70 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// decPrecision is the number of decimals of the sdk.Dec prices of the chain
+const decPrecision = 18
+
+// ErrInvalidDec is returned when a decimal does not parse as a non-negative sdk.Dec
+var ErrInvalidDec = errors.New("invalid decimal")
+
+// ReadFlowRate return the flow rates, in wei per second, charged by the chain for the charged read quota of a bucket:
+// the rate paid to the virtual payment address of the global virtual group family, readPrice per byte per second
+// times the quota, and the validator tax, validatorTaxRate times the first rate, both truncated like sdk.Dec. The
+// prices are decimals of at most 18 decimals, e.g. "0.0000000871".
+func ReadFlowRate(readPrice, validatorTaxRate string, chargedReadQuota uint64) (*big.Int, *big.Int, error) {
+	price, err := parseDec(readPrice)
+	if err != nil {
+		return nil, nil, err
+	}
+	taxRate, err := parseDec(validatorTaxRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	rate := new(big.Rat).Mul(price, new(big.Rat).SetInt(new(big.Int).SetUint64(chargedReadQuota)))
+	primary := truncate(rate)
+	tax := truncate(new(big.Rat).Mul(taxRate, new(big.Rat).SetInt(primary)))
+	return primary, tax, nil
+}
+
+// parseDec parses a non-negative decimal of at most 18 decimals like sdk.NewDecFromStr
+func parseDec(s string) (*big.Rat, error) {
+	if _, decimals, found := strings.Cut(s, "."); found && len(decimals) > decPrecision {
+		return nil, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidDec, s, decPrecision)
+	}
+	if strings.ContainsAny(s, "eE/") {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDec, s)
+	}
+	dec, ok := new(big.Rat).SetString(s)
+	if !ok || dec.Sign() < 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDec, s)
+	}
+	return dec, nil
+}
+
+// truncate return the integer part of the non-negative rational
+func truncate(r *big.Rat) *big.Int {
+	return new(big.Int).Quo(r.Num(), r.Denom())
+}
+
+// MonthKey return the month of the read quota period of t, e.g. "2024-01", the periods are calendar months in UTC
+func MonthKey(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// MonthStart return the start of the read quota period of t
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// NextReset return the time the monthly read quotas are reset after t, the start of the next period
+func NextReset(t time.Time) time.Time {
+	return MonthStart(t).AddDate(0, 1, 0)
+}
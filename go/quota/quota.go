@@ -0,0 +1,178 @@
+// Package quota accounts the read quota of the buckets like the SPs bill it: every bucket reads from a monthly free
+// quota, then from the one-time free quota granted by its PrimarySP, then from the charged read quota paid on chain
+// every month. The monthly quotas are reset at the start of every calendar month in UTC.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrBucketNotFound is returned when the read quota of a bucket is not set
+	ErrBucketNotFound = errors.New("bucket quota not found")
+	// ErrQuotaExceeded is returned when a read exceeds the remaining read quota of its bucket
+	ErrQuotaExceeded = errors.New("read quota exceeded")
+)
+
+// Usage is the read quota of a bucket and its consumption during the month
+type Usage struct {
+	BucketID uint64 `json:"bucket_id"`
+	// Month is the period of the monthly consumptions, see MonthKey
+	Month string `json:"month"`
+	// ChargedQuota is the charged read quota of the bucket paid on chain, in bytes per month
+	ChargedQuota uint64 `json:"charged_quota"`
+	// FreeQuota is the one-time free read quota granted by the PrimarySP
+	FreeQuota uint64 `json:"free_quota"`
+	// MonthlyFreeQuota is the free read quota granted every month
+	MonthlyFreeQuota uint64 `json:"monthly_free_quota"`
+
+	ConsumedCharged     uint64 `json:"consumed_charged"`
+	ConsumedFree        uint64 `json:"consumed_free"`
+	ConsumedMonthlyFree uint64 `json:"consumed_monthly_free"`
+}
+
+// Remaining return the bytes the bucket can still read this month
+func (u Usage) Remaining() uint64 {
+	return sub(u.MonthlyFreeQuota, u.ConsumedMonthlyFree) + sub(u.FreeQuota, u.ConsumedFree) +
+		sub(u.ChargedQuota, u.ConsumedCharged)
+}
+
+// reset starts the month of now, the monthly consumptions are cleared if it is a new month
+func (u *Usage) reset(now time.Time) {
+	if month := MonthKey(now); u.Month != month {
+		u.Month = month
+		u.ConsumedCharged = 0
+		u.ConsumedMonthlyFree = 0
+	}
+}
+
+// Charge is the breakdown of the bytes of a read by the quota it consumed
+type Charge struct {
+	MonthlyFree uint64
+	Free        uint64
+	Charged     uint64
+}
+
+// Total return the bytes of the read
+func (c Charge) Total() uint64 {
+	return c.MonthlyFree + c.Free + c.Charged
+}
+
+// Accountant tracks the read quota consumption of the buckets. It is safe for concurrent use.
+type Accountant struct {
+	monthlyFreeQuota uint64
+
+	mu      sync.Mutex
+	buckets map[uint64]*Usage
+}
+
+// NewAccountant return an Accountant granting monthlyFreeQuota bytes of free read every month to every bucket
+func NewAccountant(monthlyFreeQuota uint64) *Accountant {
+	return &Accountant{monthlyFreeQuota: monthlyFreeQuota, buckets: make(map[uint64]*Usage)}
+}
+
+// SetBucket sets the charged read quota and the one-time free read quota of the bucket, e.g. when it is created or
+// its charged read quota is updated on chain; the consumptions are kept
+func (a *Accountant) SetBucket(bucketID, chargedQuota, freeQuota uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage, ok := a.buckets[bucketID]
+	if !ok {
+		usage = &Usage{BucketID: bucketID, MonthlyFreeQuota: a.monthlyFreeQuota}
+		a.buckets[bucketID] = usage
+	}
+	usage.ChargedQuota = chargedQuota
+	usage.FreeQuota = freeQuota
+}
+
+// RemoveBucket stops tracking the bucket, e.g. when it is deleted
+func (a *Accountant) RemoveBucket(bucketID uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.buckets, bucketID)
+}
+
+// Charge consumes the read quota of the bucket for a read of bytes at now, the monthly free quota first, then the
+// one-time free quota, then the charged quota. Nothing is consumed and ErrQuotaExceeded is returned if the remaining
+// quota is lower than bytes.
+func (a *Accountant) Charge(bucketID, bytes uint64, now time.Time) (Charge, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage, ok := a.buckets[bucketID]
+	if !ok {
+		return Charge{}, fmt.Errorf("%w: %d", ErrBucketNotFound, bucketID)
+	}
+	usage.reset(now)
+	if remaining := usage.Remaining(); bytes > remaining {
+		return Charge{}, fmt.Errorf("%w: bucket %d reads %d bytes, %d remaining", ErrQuotaExceeded, bucketID, bytes,
+			remaining)
+	}
+
+	var charge Charge
+	charge.MonthlyFree = min(bytes, sub(usage.MonthlyFreeQuota, usage.ConsumedMonthlyFree))
+	bytes -= charge.MonthlyFree
+	charge.Free = min(bytes, sub(usage.FreeQuota, usage.ConsumedFree))
+	charge.Charged = bytes - charge.Free
+	usage.ConsumedMonthlyFree += charge.MonthlyFree
+	usage.ConsumedFree += charge.Free
+	usage.ConsumedCharged += charge.Charged
+	return charge, nil
+}
+
+// Remaining return the bytes the bucket can still read in the month of now
+func (a *Accountant) Remaining(bucketID uint64, now time.Time) (uint64, error) {
+	usage, err := a.Usage(bucketID, now)
+	if err != nil {
+		return 0, err
+	}
+	return usage.Remaining(), nil
+}
+
+// Usage return a copy of the read quota and the consumption of the bucket in the month of now
+func (a *Accountant) Usage(bucketID uint64, now time.Time) (Usage, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage, ok := a.buckets[bucketID]
+	if !ok {
+		return Usage{}, fmt.Errorf("%w: %d", ErrBucketNotFound, bucketID)
+	}
+	usage.reset(now)
+	return *usage, nil
+}
+
+// Snapshot return a copy of the usages of all the buckets ordered by bucket id, to persist or report them
+func (a *Accountant) Snapshot() []Usage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usages := make([]Usage, 0, len(a.buckets))
+	for _, usage := range a.buckets {
+		usages = append(usages, *usage)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].BucketID < usages[j].BucketID
+	})
+	return usages
+}
+
+// Restore replaces the usages of the buckets with the usages of a snapshot, e.g. after a restart
+func (a *Accountant) Restore(usages []Usage) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.buckets = make(map[uint64]*Usage, len(usages))
+	for _, usage := range usages {
+		usage := usage
+		a.buckets[usage.BucketID] = &usage
+	}
+}
+
+// sub return a - b, or 0 if b is greater
+func sub(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
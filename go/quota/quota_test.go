@@ -0,0 +1,104 @@
+package quota
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthMath(t *testing.T) {
+	now := time.Date(2024, time.January, 31, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*3600))
+	assert.Equal(t, "2024-02", MonthKey(now))
+	assert.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), MonthStart(now))
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), NextReset(now))
+	assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		NextReset(time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestAccountantCharge(t *testing.T) {
+	jan := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	a := NewAccountant(100)
+	_, err := a.Charge(1, 10, jan)
+	require.ErrorIs(t, err, ErrBucketNotFound)
+
+	a.SetBucket(1, 1000, 50)
+	remaining, err := a.Remaining(1, jan)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1150), remaining)
+
+	charge, err := a.Charge(1, 120, jan)
+	require.NoError(t, err)
+	assert.Equal(t, Charge{MonthlyFree: 100, Free: 20}, charge)
+	charge, err = a.Charge(1, 100, jan)
+	require.NoError(t, err)
+	assert.Equal(t, Charge{Free: 30, Charged: 70}, charge)
+	assert.Equal(t, uint64(100), charge.Total())
+
+	_, err = a.Charge(1, 931, jan)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	usage, err := a.Usage(1, jan)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(70), usage.ConsumedCharged)
+	assert.Equal(t, uint64(930), usage.Remaining())
+
+	// the monthly quotas are reset, the one-time free quota is not
+	feb := NextReset(jan)
+	usage, err = a.Usage(1, feb)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{BucketID: 1, Month: "2024-02", ChargedQuota: 1000, FreeQuota: 50, MonthlyFreeQuota: 100,
+		ConsumedFree: 50}, usage)
+	charge, err = a.Charge(1, 1100, feb)
+	require.NoError(t, err)
+	assert.Equal(t, Charge{MonthlyFree: 100, Charged: 1000}, charge)
+
+	// lowering the charged quota keeps the consumption
+	a.SetBucket(1, 500, 50)
+	remaining, err = a.Remaining(1, feb)
+	require.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	a.RemoveBucket(1)
+	_, err = a.Remaining(1, feb)
+	require.ErrorIs(t, err, ErrBucketNotFound)
+}
+
+func TestAccountantSnapshot(t *testing.T) {
+	now := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	a := NewAccountant(0)
+	a.SetBucket(2, 100, 0)
+	a.SetBucket(1, 0, 10)
+	_, err := a.Charge(2, 40, now)
+	require.NoError(t, err)
+
+	snapshot := a.Snapshot()
+	require.Len(t, snapshot, 2)
+	assert.Equal(t, uint64(1), snapshot[0].BucketID)
+	assert.Equal(t, uint64(2), snapshot[1].BucketID)
+
+	restored := NewAccountant(0)
+	restored.Restore(snapshot)
+	remaining, err := restored.Remaining(2, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(60), remaining)
+	assert.Equal(t, snapshot, restored.Snapshot())
+}
+
+func TestReadFlowRate(t *testing.T) {
+	primary, tax, err := ReadFlowRate("0.0000000871", "0.01", 1<<30)
+	require.NoError(t, err)
+	// 0.0000000871 * 1073741824 = 93.52291..., 0.01 * 93 = 0.93
+	assert.Equal(t, "93", primary.String())
+	assert.Equal(t, "0", tax.String())
+
+	primary, tax, err = ReadFlowRate("1.5", "0.1", 1000)
+	require.NoError(t, err)
+	assert.Equal(t, "1500", primary.String())
+	assert.Equal(t, "150", tax.String())
+
+	for _, dec := range []string{"", "-1", "1e3", "1/3", "0.0000000000000000001", "abc"} {
+		_, _, err = ReadFlowRate(dec, "0", 1)
+		assert.ErrorIs(t, err, ErrInvalidDec, dec)
+	}
+}
@@ -0,0 +1,201 @@
+// Package ratelimit limits the rate of the requests by key, e.g. by account or by bucket, with token buckets or
+// sliding windows. The state of the limiters is kept by a Store, in memory by default or in a store shared by the
+// instances of a service such as Redis, so a limit holds across the instances:
+//
+//	accounts := ratelimit.New("account", ratelimit.PerSecond(10), ratelimit.WithStore(store))
+//	buckets := ratelimit.New("bucket", ratelimit.PerMinute(600), ratelimit.WithStore(store),
+//		ratelimit.WithAlgorithm(ratelimit.SlidingWindow))
+//	res, err := ratelimit.AllowAll(ctx, accounts.Check(account), buckets.Check(bucket))
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+// Limit is the number of requests allowed per period, a zero Limit does not limit the requests
+type Limit struct {
+	// Requests is the number of requests allowed per Period
+	Requests int
+	// Period is the period of the limit
+	Period time.Duration
+	// Burst is the number of requests a token bucket serves at once, Requests if 0. It is ignored by the sliding
+	// windows.
+	Burst int
+}
+
+// PerSecond return the Limit of n requests per second
+func PerSecond(n int) Limit {
+	return Limit{Requests: n, Period: time.Second}
+}
+
+// PerMinute return the Limit of n requests per minute
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute}
+}
+
+// Unlimited reports whether the limit does not limit the requests
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// burst return the capacity of the token buckets of the limit
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// rate return the number of tokens refilled per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the decision on a request
+type Result struct {
+	// Allowed reports whether the request can be served
+	Allowed bool
+	// Remaining is the number of requests which can still be served now
+	Remaining int
+	// RetryAfter is, for a denied request, the time until a request may be allowed
+	RetryAfter time.Duration
+}
+
+// Algorithm is the algorithm of a Limiter
+type Algorithm int
+
+const (
+	// TokenBucket allows bursts of Burst requests refilled at the rate of the limit
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows Requests requests in any period, estimated from the counts of the current and the
+	// previous fixed windows
+	SlidingWindow
+)
+
+// Option configures a Limiter
+type Option func(*Limiter)
+
+// WithStore keeps the state of the limiter in store instead of a MemoryStore of its own
+func WithStore(store Store) Option {
+	return func(l *Limiter) {
+		l.store = store
+	}
+}
+
+// WithAlgorithm sets the algorithm of the limiter, TokenBucket by default
+func WithAlgorithm(algorithm Algorithm) Option {
+	return func(l *Limiter) {
+		l.algorithm = algorithm
+	}
+}
+
+// WithKeyLimit limits the requests of the key, e.g. of an account with a higher plan, instead of the default limit
+func WithKeyLimit(key string, limit Limit) Option {
+	return func(l *Limiter) {
+		l.limits[key] = limit
+	}
+}
+
+// WithFailOpen allows the requests when the store fails, e.g. when Redis is unreachable, the failure is logged. The
+// requests are denied with the error of the store otherwise.
+func WithFailOpen() Option {
+	return func(l *Limiter) {
+		l.failOpen = true
+	}
+}
+
+// Limiter limits the rate of the requests of each key. It is safe for concurrent use.
+type Limiter struct {
+	name         string
+	defaultLimit Limit
+	limits       map[string]Limit
+	algorithm    Algorithm
+	store        Store
+	failOpen     bool
+	now          func() time.Time
+}
+
+// New return a Limiter limiting the requests of each key to the default limit, the name prefixes the keys in the
+// store so the limiters may share it
+func New(name string, defaultLimit Limit, opts ...Option) *Limiter {
+	l := &Limiter{
+		name:         name,
+		defaultLimit: defaultLimit,
+		limits:       make(map[string]Limit),
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.store == nil {
+		l.store = NewMemoryStore()
+	}
+	return l
+}
+
+// Limit return the limit of the key
+func (l *Limiter) Limit(key string) Limit {
+	if limit, ok := l.limits[key]; ok {
+		return limit
+	}
+	return l.defaultLimit
+}
+
+// Allow reports whether a request of the key can be served now, and counts it if so
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	limit := l.Limit(key)
+	if limit.Unlimited() {
+		return Result{Allowed: true, Remaining: -1}, nil
+	}
+	storeKey := l.name + ":" + key
+	var (
+		res Result
+		err error
+	)
+	if l.algorithm == SlidingWindow {
+		res, err = l.store.CountRequest(ctx, storeKey, limit, l.now())
+	} else {
+		res, err = l.store.TakeToken(ctx, storeKey, limit, l.now())
+	}
+	if err != nil {
+		if l.failOpen {
+			log.Warnf("rate limiter %s failed, allow %s: %s", l.name, key, err)
+			return Result{Allowed: true, Remaining: -1}, nil
+		}
+		return Result{}, fmt.Errorf("rate limiter %s: %w", l.name, err)
+	}
+	return res, nil
+}
+
+// Check is a key checked against a Limiter by AllowAll
+type Check struct {
+	Limiter *Limiter
+	Key     string
+}
+
+// Check return the Check of the key against the limiter
+func (l *Limiter) Check(key string) Check {
+	return Check{Limiter: l, Key: key}
+}
+
+// AllowAll reports whether a request passing every check, e.g. the limit of its account and the limit of its
+// bucket, can be served. The checks are made in order and stop at the first one denying the request, whose Result
+// is returned; the requests counted by the previous checks are not given back. Otherwise the Result with the fewest
+// remaining requests is returned.
+func AllowAll(ctx context.Context, checks ...Check) (Result, error) {
+	res := Result{Allowed: true, Remaining: -1}
+	for _, check := range checks {
+		r, err := check.Limiter.Allow(ctx, check.Key)
+		if err != nil || !r.Allowed {
+			return r, err
+		}
+		if res.Remaining < 0 || (r.Remaining >= 0 && r.Remaining < res.Remaining) {
+			res.Remaining = r.Remaining
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestLimiter(name string, limit Limit, opts ...Option) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	l := New(name, limit, opts...)
+	l.now = clock.Now
+	return l, clock
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	l, clock := newTestLimiter("account", Limit{Requests: 1, Period: 2 * time.Second, Burst: 2},
+		WithKeyLimit("vip", PerSecond(100)))
+
+	res, err := l.Allow(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1}, res)
+	res, _ = l.Allow(ctx, "alice")
+	assert.True(t, res.Allowed)
+	res, _ = l.Allow(ctx, "alice")
+	assert.Equal(t, Result{Allowed: false, RetryAfter: 2 * time.Second}, res)
+	res, _ = l.Allow(ctx, "bob")
+	assert.True(t, res.Allowed)
+	for i := 0; i < 100; i++ {
+		res, _ = l.Allow(ctx, "vip")
+		assert.True(t, res.Allowed)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	res, _ = l.Allow(ctx, "alice")
+	assert.Equal(t, Result{Allowed: false, RetryAfter: time.Second}, res)
+	clock.now = clock.now.Add(time.Second)
+	res, _ = l.Allow(ctx, "alice")
+	assert.True(t, res.Allowed)
+
+	// the full buckets are forgotten
+	clock.now = clock.now.Add(time.Hour)
+	res, _ = l.Allow(ctx, "carol")
+	assert.True(t, res.Allowed)
+	assert.Len(t, l.store.(*MemoryStore).buckets, 1)
+
+	unlimited, _ := newTestLimiter("account", Limit{})
+	res, err = unlimited.Allow(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: -1}, res)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	l, clock := newTestLimiter("bucket", PerMinute(10), WithAlgorithm(SlidingWindow))
+	clock.now = clock.now.Truncate(time.Minute)
+
+	for i := 0; i < 10; i++ {
+		res, err := l.Allow(ctx, "photos")
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 9-i, res.Remaining)
+	}
+	res, _ := l.Allow(ctx, "photos")
+	assert.Equal(t, Result{Allowed: false, RetryAfter: time.Minute}, res)
+
+	// half of the previous window is still in the sliding window
+	clock.now = clock.now.Add(90 * time.Second)
+	for i := 0; i < 5; i++ {
+		res, _ = l.Allow(ctx, "photos")
+		assert.True(t, res.Allowed)
+	}
+	res, _ = l.Allow(ctx, "photos")
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 6*time.Second, res.RetryAfter)
+	clock.now = clock.now.Add(6 * time.Second)
+	res, _ = l.Allow(ctx, "photos")
+	assert.True(t, res.Allowed)
+
+	// the windows older than the previous one are ignored
+	clock.now = clock.now.Add(5 * time.Minute)
+	res, _ = l.Allow(ctx, "photos")
+	assert.Equal(t, Result{Allowed: true, Remaining: 9}, res)
+}
+
+type failingStore struct{}
+
+func (failingStore) TakeToken(context.Context, string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store down")
+}
+
+func (failingStore) CountRequest(context.Context, string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store down")
+}
+
+func TestAllowAll(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	accounts, _ := newTestLimiter("account", PerSecond(5), WithStore(store))
+	buckets, _ := newTestLimiter("bucket", PerSecond(2), WithStore(store))
+
+	res, err := AllowAll(ctx, accounts.Check("alice"), buckets.Check("photos"))
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1}, res)
+	res, _ = AllowAll(ctx, accounts.Check("alice"), buckets.Check("photos"))
+	assert.True(t, res.Allowed)
+	res, _ = AllowAll(ctx, accounts.Check("alice"), buckets.Check("photos"))
+	assert.False(t, res.Allowed)
+	res, _ = AllowAll(ctx, accounts.Check("alice"), buckets.Check("videos"))
+	assert.Equal(t, Result{Allowed: true, Remaining: 1}, res)
+
+	failing := New("account", PerSecond(1), WithStore(failingStore{}))
+	_, err = failing.Allow(ctx, "alice")
+	assert.ErrorContains(t, err, "rate limiter account: store down")
+	failOpen := New("account", PerSecond(1), WithStore(failingStore{}), WithFailOpen())
+	res, err = failOpen.Allow(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
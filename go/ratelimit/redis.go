@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RedisClient runs the Lua scripts of a RedisStore, e.g. an adapter of the Eval method of a go-redis client:
+//
+//	ratelimit.RedisClientFunc(func(ctx context.Context, script string, keys []string,
+//		args ...interface{}) (interface{}, error) {
+//		return client.Eval(ctx, script, keys, args...).Result()
+//	})
+type RedisClient interface {
+	// Eval runs the script with the keys and the args and return its result
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// RedisClientFunc adapts a func to a RedisClient
+type RedisClientFunc func(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+
+// Eval implements RedisClient
+func (f RedisClientFunc) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{},
+	error,
+) {
+	return f(ctx, script, keys, args...)
+}
+
+// RedisStore is a Store keeping the state of the limiters in Redis, shared by the instances of a service. Every
+// operation is a Lua script so it is atomic, the times are given by the instances so their clocks should be in sync.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisStore return a RedisStore whose keys are prefixed by prefix, e.g. "ratelimit:"
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// tokenBucketScript refills and takes a token of the bucket KEYS[1] with the rate ARGV[1] in tokens per ms, the
+// burst ARGV[2] at the time ARGV[3] in ms. It return whether a token was taken and the tokens left as a string.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'last')
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+if now > last then
+	tokens = math.min(burst, tokens + (now - last) * rate)
+	last = now
+end
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'last', tostring(last))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1)
+return {allowed, tostring(tokens)}
+`
+
+// slidingWindowScript counts a request in the window KEYS[1] if the requests of the window and of the previous
+// window KEYS[2] weighted by ARGV[2] are below the limit ARGV[1], the windows expire after ARGV[3] ms. It return
+// whether the request was counted, the count of the window before it and the count of the previous window.
+const slidingWindowScript = `
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+local count = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * weight + count + 1 > limit then
+	return {0, count, previous}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, count, previous}
+`
+
+// TakeToken implements Store
+func (s *RedisStore) TakeToken(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	reply, err := s.client.Eval(ctx, tokenBucketScript, []string{s.prefix + key},
+		strconv.FormatFloat(limit.rate()/1000, 'g', -1, 64), limit.burst(), now.UnixMilli())
+	if err != nil {
+		return Result{}, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected reply %v of the token bucket script", reply)
+	}
+	allowed, err := replyInt(values[0])
+	if err != nil {
+		return Result{}, err
+	}
+	tokensReply, ok := values[1].(string)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected tokens %v", values[1])
+	}
+	tokens, err := strconv.ParseFloat(tokensReply, 64)
+	if err != nil {
+		return Result{}, err
+	}
+	if allowed == 1 {
+		return Result{Allowed: true, Remaining: int(tokens)}, nil
+	}
+	_, _, wait := takeToken(tokens, 0, limit)
+	return Result{Allowed: false, RetryAfter: wait}, nil
+}
+
+// CountRequest implements Store
+func (s *RedisStore) CountRequest(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	start := now.Truncate(limit.Period)
+	elapsed := now.Sub(start)
+	current := s.prefix + key + ":" + strconv.FormatInt(start.UnixMilli(), 10)
+	previous := s.prefix + key + ":" + strconv.FormatInt(start.Add(-limit.Period).UnixMilli(), 10)
+	weight := 1 - float64(elapsed)/float64(limit.Period)
+	reply, err := s.client.Eval(ctx, slidingWindowScript, []string{current, previous}, limit.Requests,
+		strconv.FormatFloat(weight, 'g', -1, 64), (2 * limit.Period).Milliseconds())
+	if err != nil {
+		return Result{}, err
+	}
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected reply %v of the sliding window script", reply)
+	}
+	ints := make([]int64, len(values))
+	for i, value := range values {
+		if ints[i], err = replyInt(value); err != nil {
+			return Result{}, err
+		}
+	}
+	return windowResult(limit, elapsed, int(ints[1]), int(ints[2])), nil
+}
+
+// replyInt return the integer of a reply of Redis
+func replyInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	}
+	return 0, fmt.Errorf("unexpected integer %v", value)
+}
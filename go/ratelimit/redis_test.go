@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type evalCall struct {
+	script string
+	keys   []string
+	args   []interface{}
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	var calls []evalCall
+	var reply interface{}
+	client := RedisClientFunc(func(_ context.Context, script string, keys []string, args ...interface{}) (interface{},
+		error,
+	) {
+		calls = append(calls, evalCall{script: script, keys: keys, args: args})
+		return reply, nil
+	})
+	store := NewRedisStore(client, "ratelimit:")
+	now := time.UnixMilli(1700000010000)
+	limit := Limit{Requests: 1, Period: 2 * time.Second, Burst: 2}
+
+	reply = []interface{}{int64(1), "1.5"}
+	res, err := store.TakeToken(ctx, "account:alice", limit, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1}, res)
+	assert.Equal(t, evalCall{script: tokenBucketScript, keys: []string{"ratelimit:account:alice"},
+		args: []interface{}{"0.0005", 2, int64(1700000010000)}}, calls[0])
+
+	reply = []interface{}{int64(0), "0.5"}
+	res, err = store.TakeToken(ctx, "account:alice", limit, now)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: false, RetryAfter: time.Second}, res)
+
+	reply = []interface{}{int64(1), int64(3), int64(10)}
+	res, err = store.CountRequest(ctx, "bucket:photos", PerMinute(10), time.UnixMilli(1700000070000))
+	require.NoError(t, err)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1}, res)
+	assert.Equal(t, []string{"ratelimit:bucket:photos:1700000040000", "ratelimit:bucket:photos:1699999980000"},
+		calls[2].keys)
+	assert.Equal(t, []interface{}{10, "0.5", int64(120000)}, calls[2].args)
+
+	reply = "unexpected"
+	_, err = store.CountRequest(ctx, "bucket:photos", PerMinute(10), now)
+	assert.Error(t, err)
+}
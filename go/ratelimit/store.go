@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Store keeps the state of the limiters, the operations on a key must be atomic as the stores shared by several
+// instances of a service are updated concurrently
+type Store interface {
+	// TakeToken refills the token bucket of the key at the rate of the limit and takes a token if it holds one
+	TakeToken(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+	// CountRequest counts a request in the sliding window of the key if the limit allows it
+	CountRequest(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// DefaultPruneInterval is the interval at which a MemoryStore forgets the idle keys
+const DefaultPruneInterval = time.Minute
+
+// MemoryStore is a Store keeping the state of the limiters in memory, it forgets the keys whose state is back to
+// the initial one. It is safe for concurrent use.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	windows   map[string]*window
+	lastPrune time.Time
+}
+
+// tokenBucket is the token bucket of a key
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	// idle is the time after which the bucket is full again
+	idle time.Time
+}
+
+// window is the sliding window of a key
+type window struct {
+	start    time.Time
+	count    int
+	previous int
+	period   time.Duration
+}
+
+// NewMemoryStore return an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*tokenBucket), windows: make(map[string]*window)}
+}
+
+// TakeToken implements Store
+func (s *MemoryStore) TakeToken(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	burst := float64(limit.burst())
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = b
+	}
+	allowed, tokens, wait := takeToken(b.tokens, now.Sub(b.last), limit)
+	b.tokens = tokens
+	if now.After(b.last) {
+		b.last = now
+	}
+	b.idle = b.last.Add(time.Duration((burst - b.tokens) / limit.rate() * float64(time.Second)))
+	return Result{Allowed: allowed, Remaining: int(b.tokens), RetryAfter: wait}, nil
+}
+
+// CountRequest implements Store
+func (s *MemoryStore) CountRequest(_ context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+	start := now.Truncate(limit.Period)
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{start: start, period: limit.Period}
+		s.windows[key] = w
+	}
+	if !w.start.Equal(start) {
+		if w.start.Add(limit.Period).Equal(start) {
+			w.previous = w.count
+		} else {
+			w.previous = 0
+		}
+		w.start, w.count, w.period = start, 0, limit.Period
+	}
+	res := windowResult(limit, now.Sub(start), w.count, w.previous)
+	if res.Allowed {
+		w.count++
+	}
+	return res, nil
+}
+
+// prune forgets the idle keys, at most once per DefaultPruneInterval. The lock must be held.
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < DefaultPruneInterval {
+		return
+	}
+	for key, b := range s.buckets {
+		if !now.Before(b.idle) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if !now.Before(w.start.Add(2 * w.period)) {
+			delete(s.windows, key)
+		}
+	}
+	s.lastPrune = now
+}
+
+// takeToken refills the tokens for the elapsed time and takes one, it return whether a token was taken, the tokens
+// left and, if none was taken, the time until the next token
+func takeToken(tokens float64, elapsed time.Duration, limit Limit) (bool, float64, time.Duration) {
+	rate := limit.rate()
+	if elapsed > 0 {
+		tokens = math.Min(float64(limit.burst()), tokens+elapsed.Seconds()*rate)
+	}
+	if tokens < 1 {
+		return false, tokens, time.Duration(math.Ceil((1 - tokens) / rate * float64(time.Second)))
+	}
+	return true, tokens - 1, 0
+}
+
+// windowResult decides on a request made elapsed after the start of the current window, in which count requests
+// were counted after previous requests in the previous window. The requests of the previous window are weighted by
+// the part of it still in the sliding window.
+func windowResult(limit Limit, elapsed time.Duration, count, previous int) Result {
+	weight := 1 - float64(elapsed)/float64(limit.Period)
+	estimate := float64(previous)*weight + float64(count)
+	if estimate+1 <= float64(limit.Requests) {
+		return Result{Allowed: true, Remaining: int(float64(limit.Requests) - estimate - 1)}
+	}
+	// the estimate decreases as the previous window slides out, until the end of the current window
+	wait := limit.Period - elapsed
+	if free := float64(limit.Requests - count - 1); previous > 0 && free >= 0 {
+		slide := time.Duration((1 - free/float64(previous)) * float64(limit.Period))
+		if slide-elapsed < wait {
+			wait = slide - elapsed
+		}
+	}
+	if wait <= 0 {
+		wait = time.Millisecond
+	}
+	return Result{Allowed: false, RetryAfter: wait}
+}
@@ -0,0 +1,35 @@
+package redundancy
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// CPUCapabilities reports the hardware acceleration of the galois field arithmetic of the erasure encoding
+type CPUCapabilities struct {
+	Arch string
+	// Accelerated is true if the encoding uses SIMD instructions instead of the pure go version
+	Accelerated bool
+	// Instructions are the SIMD instruction sets used by the encoding, from the fastest
+	Instructions []string
+}
+
+// Capabilities return the hardware acceleration of the erasure encoding on this machine, so operators can confirm
+// the SIMD instructions are used, the pure go version is several times slower
+func Capabilities() CPUCapabilities {
+	instructions := simdInstructions()
+	return CPUCapabilities{
+		Arch:         runtime.GOARCH,
+		Accelerated:  len(instructions) > 0,
+		Instructions: instructions,
+	}
+}
+
+// String return a one line report of the capabilities
+func (c CPUCapabilities) String() string {
+	if !c.Accelerated {
+		return fmt.Sprintf("%s: not accelerated", c.Arch)
+	}
+	return fmt.Sprintf("%s: accelerated with %s", c.Arch, strings.Join(c.Instructions, ", "))
+}
@@ -0,0 +1,28 @@
+//go:build !noasm && !appengine && !gccgo
+
+package redundancy
+
+import (
+	"github.com/klauspost/cpuid/v2"
+)
+
+// simdInstructions return the instruction sets selected by the reed-solomon library on amd64
+func simdInstructions() []string {
+	var instructions []string
+	if cpuid.CPU.Supports(cpuid.AVX512F, cpuid.GFNI, cpuid.AVX512DQ) {
+		instructions = append(instructions, "GFNI")
+	}
+	if cpuid.CPU.Supports(cpuid.AVX512F, cpuid.AVX512BW, cpuid.AVX512VL) {
+		instructions = append(instructions, "AVX512")
+	}
+	if cpuid.CPU.Supports(cpuid.AVX2) {
+		instructions = append(instructions, "AVX2")
+	}
+	if cpuid.CPU.Supports(cpuid.SSSE3) {
+		instructions = append(instructions, "SSSE3")
+	}
+	if cpuid.CPU.Supports(cpuid.SSE2) {
+		instructions = append(instructions, "SSE2")
+	}
+	return instructions
+}
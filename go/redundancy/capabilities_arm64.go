@@ -0,0 +1,8 @@
+//go:build !noasm && !appengine && !gccgo
+
+package redundancy
+
+// simdInstructions return the instruction sets of the reed-solomon library on arm64, NEON is part of armv8
+func simdInstructions() []string {
+	return []string{"NEON"}
+}
@@ -0,0 +1,14 @@
+//go:build !noasm && !appengine && !gccgo
+
+package redundancy
+
+import (
+	"testing"
+)
+
+func TestCapabilitiesNEON(t *testing.T) {
+	capabilities := Capabilities()
+	if !capabilities.Accelerated || capabilities.Instructions[0] != "NEON" {
+		t.Errorf("expect NEON acceleration, got %s", capabilities)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build (!amd64 && !arm64 && !ppc64le) || noasm || appengine || gccgo
+
+package redundancy
+
+// simdInstructions return no instruction set, the reed-solomon library uses its pure go version
+func simdInstructions() []string {
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !noasm && !appengine && !gccgo
+
+package redundancy
+
+// simdInstructions return the instruction sets of the reed-solomon library on ppc64le
+func simdInstructions() []string {
+	return []string{"VSX"}
+}
@@ -0,0 +1,42 @@
+package redundancy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime"
+	"testing"
+)
+
+// TestParityVector checks the parity shards of a fixed segment against the ones of the pure go version, so every
+// accelerated version, e.g. NEON on arm64 or AVX2 and GFNI on amd64, computes the same piece hashes
+func TestParityVector(t *testing.T) {
+	segmentData := make([]byte, 64*1024+3)
+	for i := range segmentData {
+		segmentData[i] = byte(i * 7 % 251)
+	}
+	shards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+	expected := []string{
+		"9c04f62c22500c396198356af2d660754ec7828be7d623672744a87ab9c85bf5",
+		"fbf923b7a2cf9c92baf2d8b0090242fea400a08361bc62b6499f16f7755ac925",
+	}
+	for i, parity := range shards[DataBlocks:] {
+		checksum := sha256.Sum256(parity)
+		if hex.EncodeToString(checksum[:]) != expected[i] {
+			t.Errorf("parity shard %d differs on %s", i, Capabilities())
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	capabilities := Capabilities()
+	if capabilities.Arch != runtime.GOARCH {
+		t.Errorf("expect arch %s, got %s", runtime.GOARCH, capabilities.Arch)
+	}
+	if capabilities.Accelerated != (len(capabilities.Instructions) > 0) {
+		t.Errorf("inconsistent capabilities %s", capabilities)
+	}
+	t.Log(capabilities)
+}
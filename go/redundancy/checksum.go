@@ -0,0 +1,102 @@
+package redundancy
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+
+	"github.com/zkMeLabs/mechain-common/go/tracing"
+)
+
+// ShardChecksumSize is the size of the checksum trailer appended to each shard by WithShardChecksum
+const ShardChecksumSize = crc32.Size
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// CodecOption configures EncodeRawSegment and DecodeRawSegment
+type CodecOption func(*codecOptions)
+
+type codecOptions struct {
+	shardChecksum bool
+	// traceCtx parents the spans of the encoding and the decoding, which are not traced if nil
+	traceCtx context.Context
+}
+
+func newCodecOptions(opts []CodecOption) *codecOptions {
+	options := &codecOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithShardChecksum appends a crc32c trailer to each shard at encode and validates it at decode, the shards with
+// a wrong checksum are treated as lost so the bit-rot of a stored piece never makes a wrong segment.
+// The trailer changes the pieces, it must not be used for the pieces hashed on chain.
+func WithShardChecksum() CodecOption {
+	return func(o *codecOptions) {
+		o.shardChecksum = true
+	}
+}
+
+// WithTraceContext traces the encoding or the decoding with a span child of the span of ctx, see the tracing package.
+// They are not traced otherwise, so the segments encoded by other pipelines, e.g. the hash package, do not each make
+// a trace of their own.
+func WithTraceContext(ctx context.Context) CodecOption {
+	return func(o *codecOptions) {
+		o.traceCtx = ctx
+	}
+}
+
+// startSpan opens the span of an encoding or a decoding if a trace context is given, or return a no-op span
+func (o *codecOptions) startSpan(name string, size tracing.Attribute, dataShards, parityShards int) tracing.Span {
+	if o.traceCtx == nil {
+		_, span := tracing.NopTracer{}.Start(context.Background(), name)
+		return span
+	}
+	_, span := tracing.Start(o.traceCtx, name, size, tracing.Int("data_shards", dataShards),
+		tracing.Int("parity_shards", parityShards))
+	return span
+}
+
+// appendShardChecksums appends the checksum trailer to the shards, the shards are copied as the encoded shards
+// share their backing array
+func appendShardChecksums(shards [][]byte) {
+	for i, shard := range shards {
+		withChecksum := make([]byte, len(shard), len(shard)+ShardChecksumSize)
+		copy(withChecksum, shard)
+		shards[i] = binary.BigEndian.AppendUint32(withChecksum, crc32.Checksum(shard, castagnoli))
+	}
+}
+
+// stripShardChecksums return the shards without their checksum trailer and the indexes of the shards with a wrong
+// checksum, which are returned empty. The shards are not modified.
+func stripShardChecksums(shards [][]byte) ([][]byte, []int) {
+	stripped := make([][]byte, len(shards))
+	var corrupted []int
+	for i, shard := range shards {
+		if len(shard) == 0 {
+			continue
+		}
+		if len(shard) < ShardChecksumSize {
+			corrupted = append(corrupted, i)
+			continue
+		}
+		data := shard[:len(shard)-ShardChecksumSize]
+		if crc32.Checksum(data, castagnoli) != binary.BigEndian.Uint32(shard[len(data):]) {
+			corrupted = append(corrupted, i)
+			continue
+		}
+		stripped[i] = data
+	}
+	return stripped, corrupted
+}
+
+// checksumError return the error of a decode failing after the corrupted shards have been dropped
+func checksumError(err error, corrupted []int) error {
+	if len(corrupted) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w: shards %v: %w", ErrShardChecksumMismatch, corrupted, err)
+}
@@ -0,0 +1,42 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestShardChecksum(t *testing.T) {
+	segmentData := initSegmentData(1001)
+	plainShards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+	shards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks, WithShardChecksum())
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+	for i := range shards {
+		if len(shards[i]) != len(plainShards[i])+ShardChecksumSize ||
+			!bytes.Equal(shards[i][:len(plainShards[i])], plainShards[i]) {
+			t.Errorf("expect shard %d followed by its checksum", i)
+		}
+	}
+
+	// a rotten shard is dropped instead of corrupting the segment
+	shards[1][5] ^= 0xff
+	decoded, err := DecodeRawSegment(shards, int64(len(segmentData)), DataBlocks, ParityBlocks, WithShardChecksum())
+	if err != nil {
+		t.Fatalf("segment decode failed: %s", err)
+	}
+	if !bytes.Equal(decoded, segmentData) {
+		t.Errorf("decoded segment differs")
+	}
+
+	shards[2][0] ^= 0xff
+	shards[4] = nil
+	_, err = DecodeRawSegment(shards, int64(len(segmentData)), DataBlocks, ParityBlocks, WithShardChecksum())
+	if !errors.Is(err, ErrShardChecksumMismatch) || !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrShardChecksumMismatch and ErrTooFewShards, got %v", err)
+	}
+}
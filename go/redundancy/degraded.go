@@ -0,0 +1,89 @@
+package redundancy
+
+import (
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy/erasure"
+)
+
+// ReadSegmentRange return the length bytes at offset of the original segment from its shards, the lost shards are
+// empty. Only the data shards holding the range are read, and if some of them are lost only the bytes of the range
+// are reconstructed from dataShards available shards, so reads stay cheap while SPs are down.
+func ReadSegmentRange(shards [][]byte, segmentSize, offset, length int64, dataShards, parityShards int) ([]byte,
+	error,
+) {
+	if offset < 0 || length < 0 || offset+length > segmentSize {
+		return nil, fmt.Errorf("%w: offset %d, length %d, segment size %d", ErrInvalidRange, offset, length,
+			segmentSize)
+	}
+	if length == 0 {
+		return []byte{}, nil
+	}
+	shardSize, err := checkShards(shards, dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	if segmentSize > int64(shardSize)*int64(dataShards) {
+		return nil, fmt.Errorf("%w: segment size %d, %d data shards of %d bytes", ErrInvalidSegmentSize, segmentSize,
+			dataShards, shardSize)
+	}
+
+	size := int64(shardSize)
+	first, last := int(offset/size), int((offset+length-1)/size)
+	// the columns of the lost data shards needed by the range
+	colStart, colEnd := size, int64(0)
+	required := make([]bool, len(shards))
+	for i := first; i <= last; i++ {
+		if len(shards[i]) > 0 {
+			continue
+		}
+		required[i] = true
+		start, end := int64(0), size
+		if i == first {
+			start = offset % size
+		}
+		if i == last {
+			end = (offset+length-1)%size + 1
+		}
+		colStart, colEnd = min(colStart, start), max(colEnd, end)
+	}
+
+	data := shards
+	if colStart < colEnd {
+		encoder, err := erasure.NewRSEncoder(dataShards, parityShards, segmentSize)
+		if err != nil {
+			return nil, err
+		}
+		columns := make([][]byte, len(shards))
+		for i, shard := range shards {
+			if len(shard) > 0 {
+				columns[i] = shard[colStart:colEnd]
+			}
+		}
+		if err = encoder.ReconstructSome(columns, required); err != nil {
+			return nil, err
+		}
+		data = make([][]byte, len(shards))
+		copy(data, shards)
+		for i := range required {
+			if required[i] {
+				// only the columns of the range are reconstructed, place them at their offsets in the shard
+				data[i] = make([]byte, size)
+				copy(data[i][colStart:colEnd], columns[i])
+			}
+		}
+	}
+
+	result := make([]byte, 0, length)
+	for i := first; i <= last; i++ {
+		start, end := int64(0), size
+		if i == first {
+			start = offset % size
+		}
+		if i == last {
+			end = (offset+length-1)%size + 1
+		}
+		result = append(result, data[i][start:end]...)
+	}
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestReadSegmentRange(t *testing.T) {
+	segmentData := initSegmentData(1001)
+	shards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+	ranges := [][2]int64{{0, 1001}, {0, 1}, {10, 100}, {240, 30}, {500, 501}, {1000, 1}, {251, 251}}
+	for _, lost := range [][]int{{}, {1}, {0, 3}, {2, 5}} {
+		available := make([][]byte, len(shards))
+		copy(available, shards)
+		for _, index := range lost {
+			available[index] = nil
+		}
+		for _, r := range ranges {
+			data, err := ReadSegmentRange(available, 1001, r[0], r[1], DataBlocks, ParityBlocks)
+			if err != nil {
+				t.Fatalf("read range %v without shards %v failed: %s", r, lost, err)
+			}
+			if !bytes.Equal(data, segmentData[r[0]:r[0]+r[1]]) {
+				t.Errorf("range %v without shards %v differs", r, lost)
+			}
+		}
+	}
+
+	if _, err = ReadSegmentRange(shards, 1001, 1000, 2, DataBlocks, ParityBlocks); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("expect ErrInvalidRange, got %v", err)
+	}
+	shards[0], shards[1], shards[2] = nil, nil, nil
+	if _, err = ReadSegmentRange(shards, 1001, 0, 10, DataBlocks, ParityBlocks); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+}
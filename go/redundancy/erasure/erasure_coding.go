@@ -6,24 +6,108 @@ import (
 	"sync"
 
 	"github.com/klauspost/reedsolomon"
-	"github.com/rs/zerolog/log"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
 )
 
+// logger is the logger of the erasure coding module
+var logger = log.Module("redundancy/erasure")
+
 // RSEncoder - reedSolomon RSEncoder encoding details.
 type RSEncoder struct {
-	encoder                  func() reedsolomon.Encoder
+	encoder                  reedsolomon.Encoder
 	dataShards, parityShards int
 	blockSize                int64 // the data size to be encoded
 }
 
-// NewRSEncoder creates a new RSEncoder with reed-solomon encoder
+// Options tunes the reed-solomon encoders for the shard counts and the CPU. With the zero value, the number of
+// goroutines of an encode is adjusted to the shard size of the block of the RSEncoder.
+type Options struct {
+	// AutoGoroutinesShardSize adjusts the number of goroutines of an encode for shards of this size, it overrides
+	// MaxGoroutines
+	AutoGoroutinesShardSize int
+	// MaxGoroutines limits the number of goroutines of an encode
+	MaxGoroutines int
+	// MinSplitSize is the minimum number of bytes encoded by a goroutine
+	MinSplitSize int
+	// LeopardGF16 uses the Leopard GF(2^16) codec which supports up to 65536 shards. Its parity shards differ from
+	// the default codec, so the piece hashes do not match the ones of the chain.
+	LeopardGF16 bool
+}
+
+// reedsolomonOptions return the options of the reed-solomon library
+func (o Options) reedsolomonOptions() []reedsolomon.Option {
+	var opts []reedsolomon.Option
+	if o.MaxGoroutines > 0 {
+		opts = append(opts, reedsolomon.WithMaxGoroutines(o.MaxGoroutines))
+	}
+	if o.AutoGoroutinesShardSize > 0 {
+		opts = append(opts, reedsolomon.WithAutoGoroutines(o.AutoGoroutinesShardSize))
+	}
+	if o.MinSplitSize > 0 {
+		opts = append(opts, reedsolomon.WithMinSplitSize(o.MinSplitSize))
+	}
+	if o.LeopardGF16 {
+		opts = append(opts, reedsolomon.WithLeopardGF16(true))
+	}
+	return opts
+}
+
+var (
+	optionsMu      sync.RWMutex
+	encoderOptions Options
+)
+
+// SetOptions sets the options of the encoders created afterwards
+func SetOptions(opts Options) {
+	optionsMu.Lock()
+	defer optionsMu.Unlock()
+	encoderOptions = opts
+}
+
+// GetOptions return the options of the encoders
+func GetOptions() Options {
+	optionsMu.RLock()
+	defer optionsMu.RUnlock()
+	return encoderOptions
+}
+
+type encoderKey struct {
+	dataShards, parityShards int
+	options                  Options
+}
+
+// encoders caches the reed-solomon encoders by ec params and options, generating the matrices of an encoder is not
+// repeated for every segment and the inversion matrices of the decodes are shared. The encoders are safe for
+// concurrent use.
+var encoders sync.Map
+
+// cachedEncoder return the cached encoder of the ec params and options, creating it on first use
+func cachedEncoder(dataShards, parityShards int, options Options) (reedsolomon.Encoder, error) {
+	key := encoderKey{dataShards: dataShards, parityShards: parityShards, options: options}
+	if encoder, ok := encoders.Load(key); ok {
+		return encoder.(reedsolomon.Encoder), nil
+	}
+	encoder, err := reedsolomon.New(dataShards, parityShards, options.reedsolomonOptions()...)
+	if err != nil {
+		logger.Errorf("new RS encoder fail: %s", err)
+		return nil, err
+	}
+	cached, _ := encoders.LoadOrStore(key, encoder)
+	return cached.(reedsolomon.Encoder), nil
+}
+
+// NewRSEncoder creates a new RSEncoder with reed-solomon encoder configured by the options of SetOptions, the
+// reed-solomon encoders are cached by ec params and options, and by shard size if no options are set.
+// More than 256 shards require the LeopardGF16 option.
 func NewRSEncoder(dataShards, parityShards int, blockSize int64) (r RSEncoder, err error) {
 	// Check the parameters for sanity now.
 	if dataShards <= 0 || parityShards < 0 {
 		return r, reedsolomon.ErrInvShardNum
 	}
 
-	if dataShards+parityShards > 256 {
+	options := GetOptions()
+	if dataShards+parityShards > 256 && (!options.LeopardGF16 || dataShards+parityShards > 65536) {
 		return r, reedsolomon.ErrMaxShardNum
 	}
 
@@ -32,20 +116,13 @@ func NewRSEncoder(dataShards, parityShards int, blockSize int64) (r RSEncoder, e
 		parityShards: parityShards,
 		blockSize:    blockSize,
 	}
-	var encoder reedsolomon.Encoder
-	var once sync.Once
-	r.encoder = func() reedsolomon.Encoder {
-		once.Do(func() {
-			r, err := reedsolomon.New(dataShards, parityShards,
-				reedsolomon.WithAutoGoroutines(int(r.ShardSize())))
-			if err != nil {
-				log.Error().Msg("new RS encoder fail:" + err.Error())
-			}
-			encoder = r
-		})
-		return encoder
+	if options == (Options{}) {
+		options.AutoGoroutinesShardSize = int(r.ShardSize())
+	}
+	if r.encoder, err = cachedEncoder(dataShards, parityShards, options); err != nil {
+		return RSEncoder{}, err
 	}
-	return
+	return r, nil
 }
 
 // EncodeData encodes the given data and returns the reed-solomon encoded shards
@@ -53,18 +130,23 @@ func (r *RSEncoder) EncodeData(content []byte) ([][]byte, error) {
 	if len(content) == 0 {
 		return make([][]byte, r.dataShards+r.parityShards), nil
 	}
-	encoded, err := r.encoder().Split(content)
+	encoded, err := r.encoder.Split(content)
 	if err != nil {
-		log.Error().Msg("encoder split data error: " + err.Error())
+		logger.Errorf("encoder split data error: %s", err)
 		return nil, err
 	}
-	if err = r.encoder().Encode(encoded); err != nil {
-		log.Error().Msg("encoder encode fail:" + err.Error())
+	if err = r.encoder.Encode(encoded); err != nil {
+		logger.Errorf("encoder encode fail: %s", err)
 		return nil, err
 	}
 	return encoded, nil
 }
 
+// EncodeShards computes the parity shards of the data shards in place, all the shards must have the same size
+func (r *RSEncoder) EncodeShards(shards [][]byte) error {
+	return r.encoder.Encode(shards)
+}
+
 // DecodeDataShards decodes the input erasure encoded data shards data.
 // The func will recreate any missing data shards if possible.
 func (r *RSEncoder) DecodeDataShards(content [][]byte) error {
@@ -78,19 +160,19 @@ func (r *RSEncoder) DecodeDataShards(content [][]byte) error {
 	if emptyShardNum == len(content) {
 		return nil
 	}
-	return r.encoder().ReconstructData(content)
+	return r.encoder.ReconstructData(content)
 }
 
 // DecodeShards decodes the input erasure encoded data and verifies it.
 // The func recreate the missing shards if possible.
 func (r *RSEncoder) DecodeShards(data [][]byte) error {
-	if err := r.encoder().Reconstruct(data); err != nil {
-		log.Error().Msg("failed to recreate the missing shard:" + err.Error())
+	if err := r.encoder.Reconstruct(data); err != nil {
+		logger.Errorf("failed to recreate the missing shard: %s", err)
 		return err
 	}
-	ok, err := r.encoder().Verify(data)
+	ok, err := r.encoder.Verify(data)
 	if err != nil {
-		log.Error().Msg("failed to verify:" + err.Error())
+		logger.Errorf("failed to verify: %s", err)
 		return err
 	}
 
@@ -100,6 +182,22 @@ func (r *RSEncoder) DecodeShards(data [][]byte) error {
 	return nil
 }
 
+// EncodeIdx adds the parity of one data shard, or a part of it, to the parity shards which must start out zeroed.
+// Each data shard must be added exactly once.
+func (r *RSEncoder) EncodeIdx(dataShard []byte, idx int, parity [][]byte) error {
+	return r.encoder.EncodeIdx(dataShard, idx, parity)
+}
+
+// ReconstructSome recreates the missing shards marked as required, the other missing shards are left empty
+func (r *RSEncoder) ReconstructSome(shards [][]byte, required []bool) error {
+	return r.encoder.ReconstructSome(shards, required)
+}
+
+// Verify returns true if the parity shards contain the right data, all the shards must be present
+func (r *RSEncoder) Verify(shards [][]byte) (bool, error) {
+	return r.encoder.Verify(shards)
+}
+
 // ShardSize - returns actual shared size from blockSize.
 func (r *RSEncoder) ShardSize() int64 {
 	shardNum := int64(r.dataShards)
@@ -114,7 +212,7 @@ func (r *RSEncoder) ShardSize() int64 {
 func (r *RSEncoder) GetOriginalData(shardsData [][]byte, originLength int64) ([]byte, error) {
 	err := r.DecodeDataShards(shardsData)
 	if err != nil {
-		log.Error().Msg("failed to decode shards:" + err.Error())
+		logger.Errorf("failed to decode shards: %s", err)
 		return []byte(""), err
 	}
 
@@ -99,3 +99,70 @@ func TestRSEncoder(t *testing.T) {
 		t.Errorf("decode should failed")
 	}
 }
+
+func TestOptions(t *testing.T) {
+	defer SetOptions(Options{})
+
+	if _, err := NewRSEncoder(200, 100, 1024); err == nil {
+		t.Errorf("expect more than 256 shards to fail without leopard")
+	}
+
+	SetOptions(Options{LeopardGF16: true, MaxGoroutines: 2})
+	encoder, err := NewRSEncoder(200, 100, 200*64)
+	if err != nil {
+		t.Fatalf("new RSEncoder with leopard failed: %s", err)
+	}
+	originData := make([]byte, 200*64)
+	for i := range originData {
+		originData[i] = byte(rand.Intn(256))
+	}
+	shards, err := encoder.EncodeData(originData)
+	if err != nil {
+		t.Fatalf("encode failed: %s", err)
+	}
+	for i := 0; i < 100; i++ {
+		shards[i*2] = nil
+	}
+	decoded, err := encoder.GetOriginalData(shards, int64(len(originData)))
+	if err != nil {
+		t.Fatalf("decode failed: %s", err)
+	}
+	if !bytes.Equal(decoded, originData) {
+		t.Errorf("decoded data differs")
+	}
+
+	// the encoders of different options are cached apart
+	defaultEncoder, err := cachedEncoder(dataShards, parityShards, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tunedEncoder, err := cachedEncoder(dataShards, parityShards, Options{MaxGoroutines: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaultEncoder == tunedEncoder {
+		t.Errorf("expect different encoders for different options")
+	}
+}
+
+func TestDefaultOptionsByShardSize(t *testing.T) {
+	small, err := NewRSEncoder(dataShards, parityShards, 4*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	large, err := NewRSEncoder(dataShards, parityShards, 16*1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := NewRSEncoder(dataShards, parityShards, 16*1024*1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the goroutines are adjusted to the shard size without options
+	if small.encoder == large.encoder {
+		t.Errorf("expect different encoders for different shard sizes")
+	}
+	if large.encoder != again.encoder {
+		t.Errorf("expect the encoder of a shard size to be cached")
+	}
+}
@@ -0,0 +1,58 @@
+package redundancy
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidShardNum is returned when the number of shards is not the sum of the data and parity shards
+	ErrInvalidShardNum = errors.New("the shard number does not match the ec params")
+	// ErrShardSizeMismatch is returned when the available shards do not have the same size
+	ErrShardSizeMismatch = errors.New("the shards have different sizes")
+	// ErrTooFewShards is returned when less shards than the data shards are available
+	ErrTooFewShards = errors.New("too few shards to reconstruct the segment")
+	// ErrMissingShards is returned when shards which must all be present are missing
+	ErrMissingShards = errors.New("shards are missing")
+	// ErrCorruptionNotLocated is returned when the shards are inconsistent but the corrupted ones can not be told
+	// apart, which happens if more than parityShards/2 shards are corrupted
+	ErrCorruptionNotLocated = errors.New("the corrupted shards can not be located")
+	// ErrShortSegment is returned when a segment stream ends before the segment size
+	ErrShortSegment = errors.New("the segment stream is shorter than the segment size")
+	// ErrInvalidSegmentMeta is returned when a segment meta header can not be decoded
+	ErrInvalidSegmentMeta = errors.New("invalid segment meta")
+	// ErrShardChecksumMismatch is returned when shards with a wrong checksum trailer prevent the decode
+	ErrShardChecksumMismatch = errors.New("shard checksum mismatch")
+	// ErrShortShardBuffer is returned when a shard buffer has not the capacity of a shard
+	ErrShortShardBuffer = errors.New("the shard buffer is too small")
+	// ErrInvalidRedundancyParams is returned when the ec params are not allowed by the chain
+	ErrInvalidRedundancyParams = errors.New("invalid redundancy params")
+	// ErrInvalidRange is returned when a range is not within the segment
+	ErrInvalidRange = errors.New("the range is out of the segment")
+	// ErrInvalidSegmentSize is returned when the segment size does not fit in the data shards
+	ErrInvalidSegmentSize = errors.New("the segment size does not match the shard size")
+)
+
+// checkShards return the size of the available shards, which are the non-empty ones, and an error if the missing
+// shards can not be reconstructed
+func checkShards(shards [][]byte, dataShards, parityShards int) (int, error) {
+	if len(shards) != dataShards+parityShards {
+		return 0, fmt.Errorf("%w: %d shards, %d data shards and %d parity shards", ErrInvalidShardNum, len(shards),
+			dataShards, parityShards)
+	}
+	shardSize, available := 0, 0
+	for _, shard := range shards {
+		if len(shard) == 0 {
+			continue
+		}
+		if available > 0 && len(shard) != shardSize {
+			return 0, fmt.Errorf("%w: %d and %d bytes", ErrShardSizeMismatch, shardSize, len(shard))
+		}
+		shardSize = len(shard)
+		available++
+	}
+	if available < dataShards {
+		return 0, fmt.Errorf("%w: %d shards available, %d required", ErrTooFewShards, available, dataShards)
+	}
+	return shardSize, nil
+}
@@ -0,0 +1,201 @@
+package redundancy
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FountainStrategy is a systematic random linear fountain code over GF(2): the first SourceSymbols pieces are the
+// segment split like the data shards, every other piece is the xor of a pseudo-random subset of the source symbols
+// derived from Seed and the piece index. Any number of repair pieces can be generated with EncodeSymbol, so repair
+// creates new pieces instead of recreating the lost ones. The segment is decoded from any set of pieces whose
+// subsets are independent, which is almost always the case with a few pieces more than SourceSymbols.
+type FountainStrategy struct {
+	SourceSymbols int
+	RepairSymbols int
+	Seed          uint64
+}
+
+// NewFountainStrategy creates a FountainStrategy with sourceSymbols source pieces and repairSymbols repair pieces
+func NewFountainStrategy(sourceSymbols, repairSymbols int, seed uint64) (FountainStrategy, error) {
+	if sourceSymbols <= 0 || repairSymbols < 0 {
+		return FountainStrategy{}, fmt.Errorf("%w: %d source symbols and %d repair symbols", ErrInvalidShardNum,
+			sourceSymbols, repairSymbols)
+	}
+	return FountainStrategy{SourceSymbols: sourceSymbols, RepairSymbols: repairSymbols, Seed: seed}, nil
+}
+
+// symbolSize return the size of the symbols of a segment
+func (s FountainStrategy) symbolSize(segmentSize int) int {
+	return (segmentSize + s.SourceSymbols - 1) / s.SourceSymbols
+}
+
+// coefficients return the source symbols combined by the piece of index as a bitset
+func (s FountainStrategy) coefficients(index int) []uint64 {
+	coeffs := make([]uint64, (s.SourceSymbols+63)/64)
+	if index < s.SourceSymbols {
+		coeffs[index/64] |= 1 << (index % 64)
+		return coeffs
+	}
+	// splitmix64 keeps the subsets stable across go versions
+	state := s.Seed ^ uint64(index)*0x9e3779b97f4a7c15
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+	for {
+		empty := true
+		for i := range coeffs {
+			coeffs[i] = next()
+			if i == len(coeffs)-1 && s.SourceSymbols%64 != 0 {
+				coeffs[i] &= 1<<(s.SourceSymbols%64) - 1
+			}
+			empty = empty && coeffs[i] == 0
+		}
+		if !empty {
+			return coeffs
+		}
+	}
+}
+
+// sourceSymbols split the segment into the zero padded source symbols
+func (s FountainStrategy) sourceSymbols(segment []byte) [][]byte {
+	size := s.symbolSize(len(segment))
+	symbols := make([][]byte, s.SourceSymbols)
+	for i := range symbols {
+		symbols[i] = make([]byte, size)
+		start := min(i*size, len(segment))
+		copy(symbols[i], segment[start:min(start+size, len(segment))])
+	}
+	return symbols
+}
+
+// EncodeSymbol return the piece of index of the segment, index may exceed the piece count to generate more repair
+// pieces
+func (s FountainStrategy) EncodeSymbol(segment []byte, index int) []byte {
+	if len(segment) == 0 {
+		return nil
+	}
+	return s.combine(s.sourceSymbols(segment), index)
+}
+
+// combine return the xor of the source symbols of the piece of index
+func (s FountainStrategy) combine(symbols [][]byte, index int) []byte {
+	coeffs := s.coefficients(index)
+	piece := make([]byte, len(symbols[0]))
+	for i, symbol := range symbols {
+		if coeffs[i/64]&(1<<(i%64)) != 0 {
+			for j, b := range symbol {
+				piece[j] ^= b
+			}
+		}
+	}
+	return piece
+}
+
+// Encode return the source pieces followed by the repair pieces of the segment
+func (s FountainStrategy) Encode(segment []byte) ([][]byte, error) {
+	pieces := make([][]byte, s.PieceCount())
+	if len(segment) == 0 {
+		return pieces, nil
+	}
+	symbols := s.sourceSymbols(segment)
+	copy(pieces, symbols)
+	for index := s.SourceSymbols; index < len(pieces); index++ {
+		pieces[index] = s.combine(symbols, index)
+	}
+	return pieces, nil
+}
+
+// Decode reconstructs the segment from the available pieces by gaussian elimination, the lost pieces are empty.
+// pieces may hold more than PieceCount pieces, e.g. the repair pieces generated by EncodeSymbol, see DecodeSymbols.
+func (s FountainStrategy) Decode(pieces [][]byte, segmentSize int64) ([]byte, error) {
+	symbols := make(map[int][]byte, len(pieces))
+	for index, piece := range pieces {
+		if len(piece) != 0 {
+			symbols[index] = piece
+		}
+	}
+	return s.DecodeSymbols(symbols, segmentSize)
+}
+
+// DecodeSymbols reconstructs the segment from the pieces keyed by their index, any index generated by EncodeSymbol
+// is accepted. ErrTooFewShards is returned if the pieces do not determine the source symbols, which needs at least
+// SourceSymbols pieces.
+func (s FountainStrategy) DecodeSymbols(symbols map[int][]byte, segmentSize int64) ([]byte, error) {
+	if segmentSize == 0 {
+		return []byte(""), nil
+	}
+	if len(symbols) < s.SourceSymbols {
+		return nil, fmt.Errorf("%w: %d pieces for %d source symbols", ErrTooFewShards, len(symbols),
+			s.SourceSymbols)
+	}
+	indexes := make([]int, 0, len(symbols))
+	for index := range symbols {
+		if index < 0 {
+			return nil, fmt.Errorf("%w: negative piece index %d", ErrInvalidShardNum, index)
+		}
+		indexes = append(indexes, index)
+	}
+	slices.Sort(indexes)
+	size := s.symbolSize(int(segmentSize))
+	type row struct {
+		coeffs []uint64
+		data   []byte
+	}
+	var rows []row
+	for _, index := range indexes {
+		piece := symbols[index]
+		if len(piece) != size {
+			return nil, fmt.Errorf("%w: piece %d has %d bytes, %d expected", ErrShardSizeMismatch, index,
+				len(piece), size)
+		}
+		rows = append(rows, row{coeffs: s.coefficients(index), data: append([]byte(nil), piece...)})
+	}
+
+	for col := 0; col < s.SourceSymbols; col++ {
+		word, bit := col/64, uint64(1)<<(col%64)
+		pivot := -1
+		for r := col; r < len(rows); r++ {
+			if rows[r].coeffs[word]&bit != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, fmt.Errorf("%w: source symbol %d can not be recovered from %d pieces", ErrTooFewShards, col,
+				len(rows))
+		}
+		rows[col], rows[pivot] = rows[pivot], rows[col]
+		for r := range rows {
+			if r == col || rows[r].coeffs[word]&bit == 0 {
+				continue
+			}
+			for i := range rows[r].coeffs {
+				rows[r].coeffs[i] ^= rows[col].coeffs[i]
+			}
+			for i, b := range rows[col].data {
+				rows[r].data[i] ^= b
+			}
+		}
+	}
+
+	segment := make([]byte, 0, size*s.SourceSymbols)
+	for col := 0; col < s.SourceSymbols; col++ {
+		segment = append(segment, rows[col].data...)
+	}
+	return segment[:segmentSize], nil
+}
+
+// PieceCount return the number of source and repair pieces
+func (s FountainStrategy) PieceCount() int {
+	return s.SourceSymbols + s.RepairSymbols
+}
+
+// RedundancyType return REDUNDANCY_EC_TYPE, the chain has no dedicated type for fountain codes
+func (s FountainStrategy) RedundancyType() RedundancyType {
+	return RedundancyECType
+}
@@ -0,0 +1,90 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestFountainStrategy(t *testing.T) {
+	strategy, err := NewFountainStrategy(8, 8, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segmentData := initSegmentData(1001)
+	pieces, err := strategy.Encode(segmentData)
+	if err != nil {
+		t.Fatalf("fountain encode failed: %s", err)
+	}
+	if len(pieces) != 16 {
+		t.Fatalf("expect 16 pieces, got %d", len(pieces))
+	}
+	for index := range pieces {
+		if !bytes.Equal(strategy.EncodeSymbol(segmentData, index), pieces[index]) {
+			t.Errorf("piece %d differs from EncodeSymbol", index)
+		}
+	}
+
+	for _, lost := range [][]int{{}, {0, 1, 2}, {0, 2, 4, 6}} {
+		available := make([][]byte, len(pieces))
+		copy(available, pieces)
+		for _, index := range lost {
+			available[index] = nil
+		}
+		decoded, err := strategy.Decode(available, int64(len(segmentData)))
+		if err != nil {
+			t.Fatalf("fountain decode without pieces %v failed: %s", lost, err)
+		}
+		if !bytes.Equal(decoded, segmentData) {
+			t.Errorf("decoded segment without pieces %v differs", lost)
+		}
+	}
+
+	// only 7 pieces are left for 8 source symbols
+	available := make([][]byte, len(pieces))
+	copy(available[9:], pieces[9:])
+	if _, err = strategy.Decode(available, int64(len(segmentData))); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+}
+
+func TestFountainDecodeRepairSymbols(t *testing.T) {
+	strategy, err := NewFountainStrategy(8, 8, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segmentData := initSegmentData(1001)
+
+	// only repair pieces generated beyond the piece count are available
+	symbols := make(map[int][]byte)
+	for index := strategy.PieceCount(); index < strategy.PieceCount()+16; index++ {
+		symbols[index] = strategy.EncodeSymbol(segmentData, index)
+	}
+	decoded, err := strategy.DecodeSymbols(symbols, int64(len(segmentData)))
+	if err != nil {
+		t.Fatalf("fountain decode from repair pieces failed: %s", err)
+	}
+	if !bytes.Equal(decoded, segmentData) {
+		t.Error("segment decoded from repair pieces differs")
+	}
+
+	pieces := make([][]byte, strategy.PieceCount()+16)
+	for index, symbol := range symbols {
+		pieces[index] = symbol
+	}
+	decoded, err = strategy.Decode(pieces, int64(len(segmentData)))
+	if err != nil {
+		t.Fatalf("fountain decode of %d pieces failed: %s", len(pieces), err)
+	}
+	if !bytes.Equal(decoded, segmentData) {
+		t.Error("segment decoded from repair pieces differs")
+	}
+
+	few := make(map[int][]byte)
+	for index := strategy.PieceCount(); len(few) < strategy.SourceSymbols-1; index++ {
+		few[index] = symbols[index]
+	}
+	if _, err = strategy.DecodeSymbols(few, int64(len(segmentData))); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package redundancy
+
+import (
+	"fmt"
+)
+
+// EncodeStripes splits the segment into stripes of stripeSize bytes per data shard and erasure encodes them one
+// after another, emit receives the blocks of each shard for a stripe in orders. Each shard is the concatenation of
+// its blocks, so the shard prefixes can be sent before the whole segment is encoded and an encode only needs the
+// memory of a stripe.
+func EncodeStripes(content []byte, dataShards, parityShards int, stripeSize int64,
+	emit func(stripe int, blocks [][]byte) error,
+) error {
+	if stripeSize <= 0 {
+		return fmt.Errorf("%w: stripe size %d", ErrInvalidSegmentMeta, stripeSize)
+	}
+	stripeData := int(stripeSize) * dataShards
+	for stripe, offset := 0, 0; offset < len(content); stripe, offset = stripe+1, offset+stripeData {
+		end := min(offset+stripeData, len(content))
+		// limit the capacity, the encoding uses the spare capacity of the stripe and would overwrite the next one
+		blocks, err := EncodeRawSegment(content[offset:end:end], dataShards, parityShards)
+		if err != nil {
+			return err
+		}
+		if err = emit(stripe, blocks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EncodeRawSegmentInterleaved encode a raw byte array stripe by stripe and return the shards in orders and the
+// meta recording the stripe size, which is needed to decode them with DecodeRawSegmentWithMeta
+func EncodeRawSegmentInterleaved(content []byte, dataShards, parityShards int, stripeSize int64) ([][]byte,
+	SegmentMeta, error,
+) {
+	meta := SegmentMeta{SegmentSize: int64(len(content)), DataShards: dataShards, ParityShards: parityShards,
+		StripeSize: stripeSize}
+	shards := make([][]byte, dataShards+parityShards)
+	if len(content) > 0 {
+		for i := range shards {
+			shards[i] = make([]byte, 0, meta.ShardSize())
+		}
+	}
+	err := EncodeStripes(content, dataShards, parityShards, stripeSize, func(_ int, blocks [][]byte) error {
+		for i, block := range blocks {
+			shards[i] = append(shards[i], block...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, SegmentMeta{}, err
+	}
+	return shards, meta, nil
+}
+
+// decodeInterleaved decode the shards stripe by stripe
+func decodeInterleaved(shards [][]byte, meta SegmentMeta) ([]byte, error) {
+	if meta.SegmentSize == 0 {
+		return []byte(""), nil
+	}
+	shardSize, err := checkShards(shards, meta.DataShards, meta.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+	if int64(shardSize) != meta.ShardSize() {
+		return nil, fmt.Errorf("%w: shards of %d bytes, %d expected", ErrShardSizeMismatch, shardSize,
+			meta.ShardSize())
+	}
+
+	segment := make([]byte, 0, meta.SegmentSize)
+	stripeData := meta.StripeSize * int64(meta.DataShards)
+	blocks := make([][]byte, len(shards))
+	for offset, blockOffset := int64(0), int64(0); offset < meta.SegmentSize; offset += stripeData {
+		stripeLen := min(stripeData, meta.SegmentSize-offset)
+		blockSize := (stripeLen + int64(meta.DataShards) - 1) / int64(meta.DataShards)
+		for i, shard := range shards {
+			blocks[i] = nil
+			if len(shard) > 0 {
+				// the blocks of the stripe, the lost shards stay empty
+				blocks[i] = shard[blockOffset : blockOffset+blockSize : blockOffset+blockSize]
+			}
+		}
+		stripe, err := DecodeRawSegment(blocks, stripeLen, meta.DataShards, meta.ParityShards)
+		if err != nil {
+			return nil, err
+		}
+		segment = append(segment, stripe...)
+		blockOffset += blockSize
+	}
+	return segment, nil
+}
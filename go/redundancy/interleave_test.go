@@ -0,0 +1,49 @@
+package redundancy
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeRawSegmentInterleaved(t *testing.T) {
+	for _, size := range []int{1, 1000, 1001, 4096, 5000} {
+		segmentData := initSegmentData(size)
+		original := append([]byte(nil), segmentData...)
+		shards, meta, err := EncodeRawSegmentInterleaved(segmentData, DataBlocks, ParityBlocks, 256)
+		if err != nil {
+			t.Fatalf("interleaved encode failed: %s", err)
+		}
+		if int64(len(shards[0])) != meta.ShardSize() {
+			t.Errorf("expect shards of %d bytes, got %d", meta.ShardSize(), len(shards[0]))
+		}
+
+		header, _ := meta.MarshalBinary()
+		var decodedMeta SegmentMeta
+		if err = decodedMeta.UnmarshalBinary(header); err != nil || decodedMeta.StripeSize != 256 {
+			t.Fatalf("expect stripe size 256, got %v %v", decodedMeta, err)
+		}
+		shards[0], shards[4] = nil, nil
+		decoded, err := DecodeRawSegmentWithMeta(shards, decodedMeta)
+		if err != nil {
+			t.Fatalf("interleaved decode of %d bytes failed: %s", size, err)
+		}
+		if !bytes.Equal(decoded, original) || !bytes.Equal(segmentData, original) {
+			t.Errorf("decoded %d bytes segment differs", size)
+		}
+	}
+
+	// the blocks of a stripe are the prefixes of the shards
+	segmentData := initSegmentData(3000)
+	shards, _, _ := EncodeRawSegmentInterleaved(segmentData, DataBlocks, ParityBlocks, 256)
+	var stripes int
+	err := EncodeStripes(segmentData, DataBlocks, ParityBlocks, 256, func(stripe int, blocks [][]byte) error {
+		if stripe == 0 && !bytes.Equal(blocks[5], shards[5][:256]) {
+			t.Errorf("expect the first block to be the prefix of the shard")
+		}
+		stripes++
+		return nil
+	})
+	if err != nil || stripes != 3 {
+		t.Errorf("expect 3 stripes, got %d %v", stripes, err)
+	}
+}
@@ -0,0 +1,89 @@
+package redundancy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy/erasure"
+)
+
+// EncodeRawSegmentInto encode a raw byte array into the shard buffers, which are resliced to the shard size, and
+// produces the same shards as EncodeRawSegment without allocating them. Each buffer must have the capacity of a
+// shard, which ShardPool provides.
+func EncodeRawSegmentInto(segment []byte, shards [][]byte, dataShards, parityShards int) error {
+	start := time.Now()
+	if len(shards) != dataShards+parityShards {
+		return fmt.Errorf("%w: %d shards, %d data shards and %d parity shards", ErrInvalidShardNum, len(shards),
+			dataShards, parityShards)
+	}
+	encoder, err := erasure.NewRSEncoder(dataShards, parityShards, int64(len(segment)))
+	if err != nil {
+		return err
+	}
+	shardSize := int(encoder.ShardSize())
+	for i := range shards {
+		if cap(shards[i]) < shardSize {
+			return fmt.Errorf("%w: shard %d has %d bytes, %d required", ErrShortShardBuffer, i, cap(shards[i]),
+				shardSize)
+		}
+		shards[i] = shards[i][:shardSize]
+	}
+	// an empty segment is encoded to empty shards
+	if shardSize == 0 {
+		return nil
+	}
+	for i := 0; i < dataShards; i++ {
+		start := min(i*shardSize, len(segment))
+		n := copy(shards[i], segment[start:min(start+shardSize, len(segment))])
+		// zero the padding of the last data shards
+		clear(shards[i][n:])
+	}
+	if err = encoder.EncodeShards(shards); err != nil {
+		return err
+	}
+	metrics().ObserveEncode(len(segment), time.Since(start))
+	return nil
+}
+
+// ShardPool reuses the shard buffers of the segments of an object for EncodeRawSegmentInto
+type ShardPool struct {
+	pool       sync.Pool
+	shardCount int
+	shardSize  int
+}
+
+// NewShardPool creates a ShardPool of buffers fitting the shards of segments up to segmentSize bytes
+func NewShardPool(segmentSize int64, dataShards, parityShards int) *ShardPool {
+	shardSize := int((segmentSize + int64(dataShards) - 1) / int64(dataShards))
+	p := &ShardPool{shardCount: dataShards + parityShards, shardSize: shardSize}
+	p.pool.New = func() any {
+		// a single allocation holds all the shards
+		buffer := make([]byte, p.shardCount*p.shardSize)
+		shards := make([][]byte, p.shardCount)
+		for i := range shards {
+			shards[i] = buffer[i*p.shardSize : i*p.shardSize : (i+1)*p.shardSize]
+		}
+		return shards
+	}
+	return p
+}
+
+// Get return shard buffers from the pool
+func (p *ShardPool) Get() [][]byte {
+	return p.pool.Get().([][]byte)
+}
+
+// Put returns the shard buffers to the pool once the shards are no longer used
+func (p *ShardPool) Put(shards [][]byte) {
+	if len(shards) != p.shardCount {
+		return
+	}
+	for i := range shards {
+		if cap(shards[i]) < p.shardSize {
+			return
+		}
+		shards[i] = shards[i][:0]
+	}
+	p.pool.Put(shards)
+}
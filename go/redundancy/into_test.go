@@ -0,0 +1,61 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestEncodeRawSegmentInto(t *testing.T) {
+	const segmentSize = 4096
+	pool := NewShardPool(segmentSize, DataBlocks, ParityBlocks)
+	for _, size := range []int{segmentSize, 1001, 3, 0} {
+		segmentData := initSegmentData(size)
+		expected, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+		if err != nil {
+			t.Fatalf("segment encode failed: %s", err)
+		}
+		shards := pool.Get()
+		// dirty buffers from a previous segment must not leak into the padding
+		for i := range shards {
+			shards[i] = shards[i][:cap(shards[i])]
+			for j := range shards[i] {
+				shards[i][j] = 0xff
+			}
+		}
+		if err = EncodeRawSegmentInto(segmentData, shards, DataBlocks, ParityBlocks); err != nil {
+			t.Fatalf("encode into failed: %s", err)
+		}
+		for i := range expected {
+			if !bytes.Equal(shards[i], expected[i]) {
+				t.Errorf("shard %d of a %d bytes segment differs from EncodeRawSegment", i, size)
+			}
+		}
+		pool.Put(shards)
+	}
+
+	shards := make([][]byte, DataBlocks+ParityBlocks)
+	if err := EncodeRawSegmentInto(initSegmentData(100), shards, DataBlocks, ParityBlocks); !errors.Is(err,
+		ErrShortShardBuffer) {
+		t.Errorf("expect ErrShortShardBuffer, got %v", err)
+	}
+}
+
+func BenchmarkEncodeRawSegmentInto(b *testing.B) {
+	for _, segmentSize := range []int{1024 * 1024, 16 * 1024 * 1024} {
+		segmentData := initSegmentData(segmentSize)
+		pool := NewShardPool(int64(segmentSize), DataBlocks, ParityBlocks)
+		b.Run(fmt.Sprintf("segment %d", segmentSize), func(b *testing.B) {
+			b.SetBytes(int64(segmentSize))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				shards := pool.Get()
+				if err := EncodeRawSegmentInto(segmentData, shards, DataBlocks, ParityBlocks); err != nil {
+					b.Fatal(err)
+				}
+				pool.Put(shards)
+			}
+		})
+	}
+}
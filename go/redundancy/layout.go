@@ -0,0 +1,77 @@
+package redundancy
+
+// ObjectLayout describes how an object is split into segments and each segment into ec pieces
+type ObjectLayout struct {
+	ObjectSize   int64
+	SegmentCount int64
+	// SegmentSize is the size of the full segments, LastSegmentSize the size of the last segment which is shorter
+	// unless the object size is a multiple of the segment size
+	SegmentSize     int64
+	LastSegmentSize int64
+	// PieceSize is the size of each ec piece of the full segments, LastPieceSize of the last segment
+	PieceSize     int64
+	LastPieceSize int64
+	PieceCount    int
+	// StoredSize is the size of all the ec pieces of the object
+	StoredSize int64
+}
+
+// SegmentCount return the number of segments of an object, an empty object has no segment
+func SegmentCount(objectSize, segmentSize int64) int64 {
+	if objectSize <= 0 {
+		return 0
+	}
+	return (objectSize + segmentSize - 1) / segmentSize
+}
+
+// Layout return the layout of an object of objectSize bytes
+func (p RedundancyParams) Layout(objectSize int64) ObjectLayout {
+	layout := ObjectLayout{
+		ObjectSize:   objectSize,
+		SegmentCount: SegmentCount(objectSize, p.SegmentSize),
+		SegmentSize:  p.SegmentSize,
+		PieceSize:    p.MaxPieceSize(),
+		PieceCount:   p.PieceCount(),
+	}
+	if layout.SegmentCount == 0 {
+		layout.PieceSize = 0
+		return layout
+	}
+	layout.LastSegmentSize = objectSize - (layout.SegmentCount-1)*p.SegmentSize
+	layout.LastPieceSize = p.PieceSize(layout.LastSegmentSize)
+	layout.StoredSize = ((layout.SegmentCount-1)*layout.PieceSize + layout.LastPieceSize) * int64(layout.PieceCount)
+	return layout
+}
+
+// SegmentLength return the size of the segment of index
+func (l ObjectLayout) SegmentLength(segIndex int64) int64 {
+	switch {
+	case segIndex < 0 || segIndex >= l.SegmentCount:
+		return 0
+	case segIndex == l.SegmentCount-1:
+		return l.LastSegmentSize
+	default:
+		return l.SegmentSize
+	}
+}
+
+// SegmentPieceSize return the size of each ec piece of the segment of index
+func (l ObjectLayout) SegmentPieceSize(segIndex int64) int64 {
+	switch {
+	case segIndex < 0 || segIndex >= l.SegmentCount:
+		return 0
+	case segIndex == l.SegmentCount-1:
+		return l.LastPieceSize
+	default:
+		return l.PieceSize
+	}
+}
+
+// Expansion return the ratio of the stored size to the object size, including the padding of the last segment,
+// 0 for an empty object
+func (l ObjectLayout) Expansion() float64 {
+	if l.ObjectSize == 0 {
+		return 0
+	}
+	return float64(l.StoredSize) / float64(l.ObjectSize)
+}
@@ -0,0 +1,39 @@
+package redundancy
+
+import (
+	"testing"
+)
+
+func TestObjectLayout(t *testing.T) {
+	params, err := NewRedundancyParams(16*1024*1024, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layout := params.Layout(2*16*1024*1024 + 1001)
+	if layout.SegmentCount != 3 || layout.LastSegmentSize != 1001 || layout.PieceSize != 4*1024*1024 ||
+		layout.LastPieceSize != 251 {
+		t.Errorf("unexpected layout %+v", layout)
+	}
+	if layout.SegmentPieceSize(1) != 4*1024*1024 || layout.SegmentPieceSize(2) != 251 ||
+		layout.SegmentPieceSize(3) != 0 || layout.SegmentLength(2) != 1001 {
+		t.Errorf("unexpected segment piece sizes of %+v", layout)
+	}
+	if expected := int64(6 * (2*4*1024*1024 + 251)); layout.StoredSize != expected {
+		t.Errorf("expect stored size %d, got %d", expected, layout.StoredSize)
+	}
+	// the last pieces have the size of the ones of EncodeRawSegment
+	pieces, _ := EncodeRawSegment(initSegmentData(1001), DataBlocks, ParityBlocks)
+	if int64(len(pieces[0])) != layout.LastPieceSize {
+		t.Errorf("expect last pieces of %d bytes, got %d", len(pieces[0]), layout.LastPieceSize)
+	}
+
+	full := params.Layout(4 * 16 * 1024 * 1024)
+	if full.LastSegmentSize != full.SegmentSize || full.Expansion() != 1.5 {
+		t.Errorf("unexpected layout %+v with expansion %f", full, full.Expansion())
+	}
+	empty := params.Layout(0)
+	if empty.SegmentCount != 0 || empty.StoredSize != 0 || empty.Expansion() != 0 {
+		t.Errorf("unexpected empty layout %+v", empty)
+	}
+}
@@ -0,0 +1,156 @@
+package redundancy
+
+import (
+	"fmt"
+)
+
+// LRCStrategy is a Local Reconstruction Code: the data shards are erasure encoded with GlobalParityShards
+// Reed-Solomon parity shards, and split into LocalGroups groups each protected by a xor local parity shard, so a
+// single lost piece of a group is repaired from its group only.
+// The pieces are the data shards, the global parity shards and the local parity shards in orders.
+type LRCStrategy struct {
+	DataShards         int
+	LocalGroups        int
+	GlobalParityShards int
+}
+
+// NewLRCStrategy creates a LRCStrategy, the data shards are split into localGroups groups of the same size, the
+// last group is smaller if dataShards is not a multiple of localGroups. The params leaving the last group without
+// data shard, e.g. 5 data shards in 4 groups of 2, are rejected.
+func NewLRCStrategy(dataShards, localGroups, globalParityShards int) (LRCStrategy, error) {
+	if dataShards <= 0 || globalParityShards < 0 || localGroups <= 0 || localGroups > dataShards ||
+		(localGroups-1)*((dataShards+localGroups-1)/localGroups) >= dataShards {
+		return LRCStrategy{}, fmt.Errorf("%w: %d data shards, %d local groups, %d global parity shards",
+			ErrInvalidShardNum, dataShards, localGroups, globalParityShards)
+	}
+	return LRCStrategy{DataShards: dataShards, LocalGroups: localGroups, GlobalParityShards: globalParityShards}, nil
+}
+
+// groupSize return the number of data shards of the groups
+func (s LRCStrategy) groupSize() int {
+	return (s.DataShards + s.LocalGroups - 1) / s.LocalGroups
+}
+
+// group return the indexes of the data shards of the group
+func (s LRCStrategy) group(g int) []int {
+	start := g * s.groupSize()
+	end := min(start+s.groupSize(), s.DataShards)
+	indexes := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
+
+// localParityIndex return the piece index of the local parity shard of the group
+func (s LRCStrategy) localParityIndex(g int) int {
+	return s.DataShards + s.GlobalParityShards + g
+}
+
+// Encode return the data shards, the global parity shards and the local parity shards of the segment
+func (s LRCStrategy) Encode(segment []byte) ([][]byte, error) {
+	shards, err := EncodeRawSegment(segment, s.DataShards, s.GlobalParityShards)
+	if err != nil {
+		return nil, err
+	}
+	pieces := make([][]byte, s.PieceCount())
+	copy(pieces, shards)
+	if len(segment) == 0 {
+		return pieces, nil
+	}
+	for g := 0; g < s.LocalGroups; g++ {
+		pieces[s.localParityIndex(g)] = xorShards(shards, s.group(g))
+	}
+	return pieces, nil
+}
+
+// Decode repairs the lost data shards from their group if possible and reconstructs the segment, the global parity
+// shards are only used if the local repair is not enough
+func (s LRCStrategy) Decode(pieces [][]byte, segmentSize int64) ([]byte, error) {
+	if len(pieces) != s.PieceCount() {
+		return nil, fmt.Errorf("%w: %d pieces, %d expected", ErrInvalidShardNum, len(pieces), s.PieceCount())
+	}
+	shards := make([][]byte, s.DataShards+s.GlobalParityShards)
+	copy(shards, pieces)
+	for g := 0; g < s.LocalGroups; g++ {
+		s.repairLocal(shards, pieces[s.localParityIndex(g)], s.group(g))
+	}
+	return DecodeRawSegment(shards, segmentSize, s.DataShards, s.GlobalParityShards)
+}
+
+// repairLocal recreates the lost data shard of the group if it is the only one lost and the local parity shard is
+// available
+func (s LRCStrategy) repairLocal(shards [][]byte, localParity []byte, group []int) {
+	if len(localParity) == 0 {
+		return
+	}
+	lost := -1
+	for _, index := range group {
+		if len(shards[index]) == 0 {
+			if lost >= 0 {
+				return
+			}
+			lost = index
+		}
+	}
+	if lost < 0 {
+		return
+	}
+	available := make([][]byte, 0, len(group))
+	for _, index := range group {
+		if index != lost {
+			if len(shards[index]) != len(localParity) {
+				return
+			}
+			available = append(available, shards[index])
+		}
+	}
+	available = append(available, localParity)
+	indexes := make([]int, len(available))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	shards[lost] = xorShards(available, indexes)
+}
+
+// LocalRepairSet return the indexes of the pieces needed to repair the lost piece within its group, false is
+// returned for the global parity shards which need a full reconstruction
+func (s LRCStrategy) LocalRepairSet(index int) ([]int, bool) {
+	g := -1
+	switch {
+	case index >= 0 && index < s.DataShards:
+		g = index / s.groupSize()
+	case index >= s.DataShards+s.GlobalParityShards && index < s.PieceCount():
+		g = index - s.DataShards - s.GlobalParityShards
+	default:
+		return nil, false
+	}
+	var repairSet []int
+	for _, member := range append(s.group(g), s.localParityIndex(g)) {
+		if member != index {
+			repairSet = append(repairSet, member)
+		}
+	}
+	return repairSet, true
+}
+
+// PieceCount return the number of data, global parity and local parity shards
+func (s LRCStrategy) PieceCount() int {
+	return s.DataShards + s.GlobalParityShards + s.LocalGroups
+}
+
+// RedundancyType return REDUNDANCY_EC_TYPE, the chain has no dedicated type for LRC
+func (s LRCStrategy) RedundancyType() RedundancyType {
+	return RedundancyECType
+}
+
+// xorShards return the xor of the shards of indexes which have the same size
+func xorShards(shards [][]byte, indexes []int) []byte {
+	result := make([]byte, len(shards[indexes[0]]))
+	for _, index := range indexes {
+		for i, b := range shards[index] {
+			result[i] ^= b
+		}
+	}
+	return result
+}
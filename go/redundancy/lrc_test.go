@@ -0,0 +1,100 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLRCStrategy(t *testing.T) {
+	strategy, err := NewLRCStrategy(6, 2, 2)
+	if err != nil {
+		t.Fatalf("new lrc strategy failed: %s", err)
+	}
+	segmentData := initSegmentData(1000)
+	pieces, err := strategy.Encode(segmentData)
+	if err != nil {
+		t.Fatalf("lrc encode failed: %s", err)
+	}
+	if len(pieces) != 10 {
+		t.Fatalf("expect 10 pieces, got %d", len(pieces))
+	}
+
+	for _, lost := range [][]int{{1}, {1, 4}, {0, 3, 6, 7}, {2, 8, 9}} {
+		lostPieces := make([][]byte, len(pieces))
+		copy(lostPieces, pieces)
+		for _, index := range lost {
+			lostPieces[index] = nil
+		}
+		decoded, err := strategy.Decode(lostPieces, int64(len(segmentData)))
+		if err != nil {
+			t.Fatalf("lrc decode without pieces %v failed: %s", lost, err)
+		}
+		if !bytes.Equal(decoded, segmentData) {
+			t.Errorf("decoded segment without pieces %v differs", lost)
+		}
+	}
+
+	// a single lost data shard is repaired from its group without the global parity shards
+	lostPieces := make([][]byte, len(pieces))
+	copy(lostPieces, pieces)
+	lostPieces[4], lostPieces[6], lostPieces[7] = nil, nil, nil
+	if decoded, err := strategy.Decode(lostPieces, int64(len(segmentData))); err != nil ||
+		!bytes.Equal(decoded, segmentData) {
+		t.Errorf("local repair failed: %v", err)
+	}
+
+	if repairSet, ok := strategy.LocalRepairSet(4); !ok || !reflect.DeepEqual(repairSet, []int{3, 5, 9}) {
+		t.Errorf("expect repair set [3 5 9], got %v %v", repairSet, ok)
+	}
+	if _, ok := strategy.LocalRepairSet(6); ok {
+		t.Errorf("expect no local repair set for a global parity shard")
+	}
+	if _, err = NewLRCStrategy(2, 3, 1); !errors.Is(err, ErrInvalidShardNum) {
+		t.Errorf("expect ErrInvalidShardNum, got %v", err)
+	}
+}
+
+func TestNewLRCStrategyGroups(t *testing.T) {
+	for _, tc := range []struct {
+		dataShards, localGroups, globalParityShards int
+		valid                                       bool
+	}{
+		// the groups of 2 leave the last group without data shard
+		{5, 4, 2, false},
+		{4, 3, 2, false},
+		{7, 3, 1, true},
+		{6, 2, 2, true},
+		{2, 3, 1, false},
+	} {
+		strategy, err := NewLRCStrategy(tc.dataShards, tc.localGroups, tc.globalParityShards)
+		if !tc.valid {
+			if !errors.Is(err, ErrInvalidShardNum) {
+				t.Errorf("%+v: expect ErrInvalidShardNum, got %v", tc, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%+v: new lrc strategy failed: %s", tc, err)
+		}
+		segmentData := initSegmentData(1000)
+		pieces, err := strategy.Encode(segmentData)
+		if err != nil {
+			t.Fatalf("%+v: lrc encode failed: %s", tc, err)
+		}
+		for index := 0; index < tc.dataShards; index++ {
+			repairSet, ok := strategy.LocalRepairSet(index)
+			if !ok || len(repairSet) == 0 {
+				t.Errorf("%+v: no local repair set for data shard %d", tc, index)
+			}
+			lostPieces := make([][]byte, len(pieces))
+			copy(lostPieces, pieces)
+			lostPieces[index] = nil
+			decoded, err := strategy.Decode(lostPieces, int64(len(segmentData)))
+			if err != nil || !bytes.Equal(decoded, segmentData) {
+				t.Errorf("%+v: decode without data shard %d failed: %v", tc, index, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package redundancy
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SegmentMetaSize is the size of a marshalled SegmentMeta
+const SegmentMetaSize = 24
+
+// SegmentMeta records the original size of an encoded segment and its ec params, the shards of the last segment
+// of an object are zero padded and the padding is only stripped knowing the original size
+type SegmentMeta struct {
+	SegmentSize  int64
+	DataShards   int
+	ParityShards int
+	// StripeSize is the size of the blocks of each shard encoded together by EncodeRawSegmentInterleaved, 0 if the
+	// segment is encoded at once
+	StripeSize int64
+}
+
+// ShardSize return the size of the shards of the segment
+func (m SegmentMeta) ShardSize() int64 {
+	if m.StripeSize <= 0 {
+		return (m.SegmentSize + int64(m.DataShards) - 1) / int64(m.DataShards)
+	}
+	stripeData := m.StripeSize * int64(m.DataShards)
+	rest := m.SegmentSize % stripeData
+	return m.SegmentSize/stripeData*m.StripeSize + (rest+int64(m.DataShards)-1)/int64(m.DataShards)
+}
+
+// Padding return the number of zero bytes appended to the segment to fill the data shards
+func (m SegmentMeta) Padding() int64 {
+	return m.ShardSize()*int64(m.DataShards) - m.SegmentSize
+}
+
+// MarshalBinary encodes the meta to a SegmentMetaSize bytes header, which can be stored along the shards
+func (m SegmentMeta) MarshalBinary() ([]byte, error) {
+	header := make([]byte, SegmentMetaSize)
+	binary.BigEndian.PutUint64(header[0:8], uint64(m.SegmentSize))
+	binary.BigEndian.PutUint32(header[8:12], uint32(m.DataShards))
+	binary.BigEndian.PutUint32(header[12:16], uint32(m.ParityShards))
+	binary.BigEndian.PutUint64(header[16:24], uint64(m.StripeSize))
+	return header, nil
+}
+
+// UnmarshalBinary decodes a header encoded by MarshalBinary
+func (m *SegmentMeta) UnmarshalBinary(header []byte) error {
+	if len(header) != SegmentMetaSize {
+		return fmt.Errorf("%w: %d bytes header", ErrInvalidSegmentMeta, len(header))
+	}
+	meta := SegmentMeta{
+		SegmentSize:  int64(binary.BigEndian.Uint64(header[0:8])),
+		DataShards:   int(binary.BigEndian.Uint32(header[8:12])),
+		ParityShards: int(binary.BigEndian.Uint32(header[12:16])),
+		StripeSize:   int64(binary.BigEndian.Uint64(header[16:24])),
+	}
+	if meta.SegmentSize < 0 || meta.DataShards <= 0 || meta.ParityShards < 0 || meta.StripeSize < 0 {
+		return fmt.Errorf("%w: segment size %d, %d data shards, %d parity shards and stripe size %d",
+			ErrInvalidSegmentMeta, meta.SegmentSize, meta.DataShards, meta.ParityShards, meta.StripeSize)
+	}
+	*m = meta
+	return nil
+}
+
+// EncodeRawSegmentWithMeta encode a raw byte array and return the erasure encoded shards in orders and the meta
+// needed to decode them
+func EncodeRawSegmentWithMeta(content []byte, dataShards, parityShards int) ([][]byte, SegmentMeta, error) {
+	shards, err := EncodeRawSegment(content, dataShards, parityShards)
+	if err != nil {
+		return nil, SegmentMeta{}, err
+	}
+	return shards, SegmentMeta{SegmentSize: int64(len(content)), DataShards: dataShards,
+		ParityShards: parityShards}, nil
+}
+
+// DecodeRawSegmentWithMeta decode the erasure encoded shards and return exactly the original content without
+// the padding, the interleaved shards are decoded stripe by stripe
+func DecodeRawSegmentWithMeta(shards [][]byte, meta SegmentMeta) ([]byte, error) {
+	if meta.StripeSize > 0 {
+		return decodeInterleaved(shards, meta)
+	}
+	return DecodeRawSegment(shards, meta.SegmentSize, meta.DataShards, meta.ParityShards)
+}
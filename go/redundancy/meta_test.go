@@ -0,0 +1,40 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSegmentMeta(t *testing.T) {
+	segmentData := initSegmentData(1001)
+	shards, meta, err := EncodeRawSegmentWithMeta(segmentData, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+	if meta.ShardSize() != int64(len(shards[0])) || meta.Padding() != 3 {
+		t.Errorf("expect shard size %d and 3 bytes padding, got %d and %d", len(shards[0]), meta.ShardSize(),
+			meta.Padding())
+	}
+
+	header, _ := meta.MarshalBinary()
+	var decodedMeta SegmentMeta
+	if err = decodedMeta.UnmarshalBinary(header); err != nil || decodedMeta != meta {
+		t.Fatalf("expect meta %v, got %v %v", meta, decodedMeta, err)
+	}
+	shards[1] = nil
+	decoded, err := DecodeRawSegmentWithMeta(shards, decodedMeta)
+	if err != nil {
+		t.Fatalf("segment decode failed: %s", err)
+	}
+	if !bytes.Equal(decoded, segmentData) {
+		t.Errorf("expect the original %d bytes, got %d bytes", len(segmentData), len(decoded))
+	}
+
+	if err = decodedMeta.UnmarshalBinary(header[:10]); !errors.Is(err, ErrInvalidSegmentMeta) {
+		t.Errorf("expect ErrInvalidSegmentMeta, got %v", err)
+	}
+	if err = decodedMeta.UnmarshalBinary(make([]byte, SegmentMetaSize)); !errors.Is(err, ErrInvalidSegmentMeta) {
+		t.Errorf("expect ErrInvalidSegmentMeta for zero data shards, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package redundancy
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// MetricsCollector receives the measurements of the erasure encoding, the methods may be called concurrently.
+// See the metrics package for a Prometheus implementation.
+type MetricsCollector interface {
+	// ObserveEncode is called after a segment of size bytes has been erasure encoded
+	ObserveEncode(size int, latency time.Duration)
+	// ObserveDecode is called after a segment of size bytes has been decoded from its shards
+	ObserveDecode(size int, latency time.Duration)
+	// ObserveReconstruction is called when lost shards are reconstructed, by a decode or by ReconstructShards
+	ObserveReconstruction(lostShards int)
+	// ObserveVerifyFailure is called when VerifyShards finds inconsistent shards
+	ObserveVerifyFailure()
+}
+
+type nopMetricsCollector struct{}
+
+func (nopMetricsCollector) ObserveEncode(int, time.Duration) {}
+
+func (nopMetricsCollector) ObserveDecode(int, time.Duration) {}
+
+func (nopMetricsCollector) ObserveReconstruction(int) {}
+
+func (nopMetricsCollector) ObserveVerifyFailure() {}
+
+type metricsHolder struct {
+	collector MetricsCollector
+}
+
+var metricsCollector atomic.Value
+
+func init() {
+	metricsCollector.Store(metricsHolder{collector: nopMetricsCollector{}})
+}
+
+// SetMetrics sets the collector of the erasure encoding measurements of the package, nil disables the measurements
+func SetMetrics(collector MetricsCollector) {
+	if collector == nil {
+		collector = nopMetricsCollector{}
+	}
+	metricsCollector.Store(metricsHolder{collector: collector})
+}
+
+func metrics() MetricsCollector {
+	return metricsCollector.Load().(metricsHolder).collector
+}
+
+// lostShards return the number of empty shards among the first n shards
+func lostShards(shards [][]byte, n int) int {
+	lost := 0
+	for _, shard := range shards[:min(n, len(shards))] {
+		if len(shard) == 0 {
+			lost++
+		}
+	}
+	return lost
+}
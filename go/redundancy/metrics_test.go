@@ -0,0 +1,76 @@
+package redundancy
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type testMetricsCollector struct {
+	mu              sync.Mutex
+	encodedBytes    int
+	decodedBytes    int
+	reconstructions []int
+	verifyFailures  int
+}
+
+func (c *testMetricsCollector) ObserveEncode(size int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.encodedBytes += size
+}
+
+func (c *testMetricsCollector) ObserveDecode(size int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decodedBytes += size
+}
+
+func (c *testMetricsCollector) ObserveReconstruction(lostShards int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reconstructions = append(c.reconstructions, lostShards)
+}
+
+func (c *testMetricsCollector) ObserveVerifyFailure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.verifyFailures++
+}
+
+func TestMetrics(t *testing.T) {
+	collector := &testMetricsCollector{}
+	SetMetrics(collector)
+	defer SetMetrics(nil)
+
+	segmentData := initSegmentData(1000)
+	shards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = DecodeRawSegment(shards, 1000, DataBlocks, ParityBlocks); err != nil {
+		t.Fatal(err)
+	}
+	shards[0], shards[5] = nil, nil
+	if _, err = DecodeRawSegment(shards, 1000, DataBlocks, ParityBlocks); err != nil {
+		t.Fatal(err)
+	}
+	// the decode reconstructed the lost data shard in place
+	shards[0] = nil
+	if err = ReconstructShards(shards, DataBlocks, ParityBlocks); err != nil {
+		t.Fatal(err)
+	}
+	shards[1][0] ^= 0xff
+	if _, err = VerifyShards(shards, DataBlocks, ParityBlocks); err != nil {
+		t.Fatal(err)
+	}
+
+	if collector.encodedBytes != 1000 || collector.decodedBytes != 2000 || collector.verifyFailures != 1 {
+		t.Errorf("unexpected measurements %d encoded, %d decoded, %d verify failures", collector.encodedBytes,
+			collector.decodedBytes, collector.verifyFailures)
+	}
+	// the decode reconstructs the lost data shard, ReconstructShards both lost shards
+	if len(collector.reconstructions) != 2 || collector.reconstructions[0] != 1 || collector.reconstructions[1] != 2 {
+		t.Errorf("unexpected reconstructions %v", collector.reconstructions)
+	}
+}
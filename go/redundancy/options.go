@@ -0,0 +1,14 @@
+package redundancy
+
+import (
+	"github.com/zkMeLabs/mechain-common/go/redundancy/erasure"
+)
+
+// Options tunes the reed-solomon encoders, see erasure.Options
+type Options = erasure.Options
+
+// SetOptions sets the options of the reed-solomon encoders used afterwards by the package, e.g. to limit the
+// goroutines of an encode or to use Leopard GF(2^16) for large shard counts
+func SetOptions(opts Options) {
+	erasure.SetOptions(opts)
+}
@@ -0,0 +1,83 @@
+package redundancy
+
+import (
+	"fmt"
+)
+
+// MaxShards is the maximum number of data and parity shards of a segment
+const MaxShards = 256
+
+// RedundancyParams are the ec params of the objects, fetched from chain
+type RedundancyParams struct {
+	SegmentSize  int64
+	DataShards   int
+	ParityShards int
+}
+
+// NewRedundancyParams creates RedundancyParams and validates them
+func NewRedundancyParams(segmentSize int64, dataShards, parityShards int) (RedundancyParams, error) {
+	params := RedundancyParams{SegmentSize: segmentSize, DataShards: dataShards, ParityShards: parityShards}
+	return params, params.Validate()
+}
+
+// Validate return ErrInvalidRedundancyParams if the params are not allowed by the chain: at least one data and one
+// parity shard, at most MaxShards shards, and a segment size which is a multiple of the data shards so the full
+// segments are not padded
+func (p RedundancyParams) Validate() error {
+	switch {
+	case p.DataShards <= 0:
+		return fmt.Errorf("%w: %d data shards", ErrInvalidRedundancyParams, p.DataShards)
+	case p.ParityShards < 1:
+		return fmt.Errorf("%w: %d parity shards, at least 1 required", ErrInvalidRedundancyParams, p.ParityShards)
+	case p.DataShards+p.ParityShards > MaxShards:
+		return fmt.Errorf("%w: %d shards, at most %d allowed", ErrInvalidRedundancyParams,
+			p.DataShards+p.ParityShards, MaxShards)
+	case p.SegmentSize <= 0:
+		return fmt.Errorf("%w: segment size %d", ErrInvalidRedundancyParams, p.SegmentSize)
+	case p.SegmentSize%int64(p.DataShards) != 0:
+		return fmt.Errorf("%w: segment size %d is not a multiple of %d data shards", ErrInvalidRedundancyParams,
+			p.SegmentSize, p.DataShards)
+	}
+	return nil
+}
+
+// PieceCount return the number of ec pieces of a segment
+func (p RedundancyParams) PieceCount() int {
+	return p.DataShards + p.ParityShards
+}
+
+// PieceSize return the size of each ec piece of a segment of segmentSize bytes, the last segment of an object is
+// shorter than the segment size
+func (p RedundancyParams) PieceSize(segmentSize int64) int64 {
+	return (segmentSize + int64(p.DataShards) - 1) / int64(p.DataShards)
+}
+
+// MaxPieceSize return the size of each ec piece of a full segment
+func (p RedundancyParams) MaxPieceSize() int64 {
+	return p.PieceSize(p.SegmentSize)
+}
+
+// Strategy return the Reed-Solomon strategy of the params
+func (p RedundancyParams) Strategy() ReedSolomonStrategy {
+	return NewReedSolomonStrategy(p.DataShards, p.ParityShards)
+}
+
+// Encode validates the params and return the ec pieces of the segment
+func (p RedundancyParams) Encode(segment []byte) ([][]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	if int64(len(segment)) > p.SegmentSize {
+		return nil, fmt.Errorf("%w: %d bytes segment, segment size %d", ErrInvalidSegmentSize, len(segment),
+			p.SegmentSize)
+	}
+	return EncodeRawSegment(segment, p.DataShards, p.ParityShards)
+}
+
+// Decode validates the params and reconstructs the segment of segmentSize bytes from the ec pieces
+func (p RedundancyParams) Decode(pieces [][]byte, segmentSize int64) ([]byte, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return DecodeRawSegment(pieces, segmentSize, p.DataShards, p.ParityShards)
+}
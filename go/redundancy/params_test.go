@@ -0,0 +1,44 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRedundancyParams(t *testing.T) {
+	params, err := NewRedundancyParams(16*1024*1024, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("expect valid params, got %s", err)
+	}
+	if params.MaxPieceSize() != 4*1024*1024 || params.PieceSize(1001) != 251 || params.PieceCount() != 6 {
+		t.Errorf("unexpected piece sizes %d %d %d", params.MaxPieceSize(), params.PieceSize(1001), params.PieceCount())
+	}
+	segmentData := initSegmentData(1001)
+	pieces, err := params.Encode(segmentData)
+	if err != nil {
+		t.Fatalf("encode failed: %s", err)
+	}
+	if int64(len(pieces[0])) != params.PieceSize(1001) {
+		t.Errorf("expect pieces of %d bytes, got %d", params.PieceSize(1001), len(pieces[0]))
+	}
+	pieces[0] = nil
+	if decoded, err := params.Decode(pieces, 1001); err != nil || !bytes.Equal(decoded, segmentData) {
+		t.Errorf("decode failed: %v", err)
+	}
+
+	for _, invalid := range []RedundancyParams{
+		{SegmentSize: 1024, DataShards: 0, ParityShards: 2},
+		{SegmentSize: 1024, DataShards: 4, ParityShards: 0},
+		{SegmentSize: 1024, DataShards: 200, ParityShards: 100},
+		{SegmentSize: 1023, DataShards: 4, ParityShards: 2},
+		{SegmentSize: 0, DataShards: 4, ParityShards: 2},
+	} {
+		if err = invalid.Validate(); !errors.Is(err, ErrInvalidRedundancyParams) {
+			t.Errorf("expect %+v to be invalid, got %v", invalid, err)
+		}
+	}
+	if _, err = params.Encode(make([]byte, params.SegmentSize+1)); !errors.Is(err, ErrInvalidSegmentSize) {
+		t.Errorf("expect ErrInvalidSegmentSize, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package redundancy
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// EncodePool erasure encodes segments with a fixed number of workers shared by all its callers, so concurrent
+// batches do not multiply the encoding goroutines
+type EncodePool struct {
+	jobs      chan encodeJob
+	workers   sync.WaitGroup
+	closeOnce sync.Once
+}
+
+type encodeJob struct {
+	segment      []byte
+	dataShards   int
+	parityShards int
+	shards       *[][]byte
+	err          *error
+	done         *sync.WaitGroup
+}
+
+// NewEncodePool starts an EncodePool with workers workers, at least one
+func NewEncodePool(workers int) *EncodePool {
+	workers = max(workers, 1)
+	pool := &EncodePool{jobs: make(chan encodeJob, workers)}
+	pool.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+func (p *EncodePool) work() {
+	defer p.workers.Done()
+	for job := range p.jobs {
+		*job.shards, *job.err = EncodeRawSegment(job.segment, job.dataShards, job.parityShards)
+		job.done.Done()
+	}
+}
+
+// EncodeSegments encode the segments concurrently and return the shards of each segment in the order of the
+// segments, the error of the first failed segment is returned
+func (p *EncodePool) EncodeSegments(segments [][]byte, dataShards, parityShards int) ([][][]byte, error) {
+	results := make([][][]byte, len(segments))
+	errs := make([]error, len(segments))
+	var done sync.WaitGroup
+	done.Add(len(segments))
+	for i, segment := range segments {
+		p.jobs <- encodeJob{
+			segment:      segment,
+			dataShards:   dataShards,
+			parityShards: parityShards,
+			shards:       &results[i],
+			err:          &errs[i],
+			done:         &done,
+		}
+	}
+	done.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode segment %d: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
+// Close stops the workers once the queued segments are encoded, the pool must not be used afterwards
+func (p *EncodePool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.jobs)
+	})
+	p.workers.Wait()
+}
+
+var (
+	defaultPool     *EncodePool
+	defaultPoolOnce sync.Once
+)
+
+// EncodeSegments encode the segments concurrently with a pool of runtime.NumCPU() workers shared by the package
+// and return the shards of each segment in the order of the segments
+func EncodeSegments(segments [][]byte, dataShards, parityShards int) ([][][]byte, error) {
+	defaultPoolOnce.Do(func() {
+		defaultPool = NewEncodePool(runtime.NumCPU())
+	})
+	return defaultPool.EncodeSegments(segments, dataShards, parityShards)
+}
@@ -0,0 +1,46 @@
+package redundancy
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestEncodeSegments(t *testing.T) {
+	segments := make([][]byte, 20)
+	for i := range segments {
+		segments[i] = initSegmentData(1000 + i)
+	}
+	pool := NewEncodePool(3)
+	defer pool.Close()
+
+	// the batches of concurrent callers share the workers
+	var wg sync.WaitGroup
+	for caller := 0; caller < 4; caller++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := pool.EncodeSegments(segments, DataBlocks, ParityBlocks)
+			if err != nil {
+				t.Errorf("encode segments failed: %s", err)
+				return
+			}
+			for i, segment := range segments {
+				expected, _ := EncodeRawSegment(segment, DataBlocks, ParityBlocks)
+				for j := range expected {
+					if !bytes.Equal(results[i][j], expected[j]) {
+						t.Errorf("shard %d of segment %d differs", j, i)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if _, err := EncodeSegments(segments, 300, 1); err == nil {
+		t.Errorf("expect invalid ec params to fail")
+	}
+	if results, err := EncodeSegments(nil, DataBlocks, ParityBlocks); err != nil || len(results) != 0 {
+		t.Errorf("expect no result, got %v %v", results, err)
+	}
+}
@@ -0,0 +1,24 @@
+package redundancy
+
+// PlanRepair return the indexes of the shards to fetch to reconstruct a segment and whether the reconstruction is
+// possible, available tells which shards can be fetched. The available data shards are preferred as the segment
+// is decoded without computation if all the data shards are fetched, the first available parity shards replace the
+// missing data shards.
+func PlanRepair(available []bool, dataShards, parityShards int) ([]int, bool) {
+	if dataShards <= 0 || parityShards < 0 || len(available) != dataShards+parityShards {
+		return nil, false
+	}
+	fetch := make([]int, 0, dataShards)
+	for index, ok := range available {
+		if len(fetch) == dataShards {
+			break
+		}
+		if ok {
+			fetch = append(fetch, index)
+		}
+	}
+	if len(fetch) < dataShards {
+		return nil, false
+	}
+	return fetch, true
+}
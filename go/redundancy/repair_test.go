@@ -0,0 +1,26 @@
+package redundancy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanRepair(t *testing.T) {
+	testCases := []struct {
+		available []bool
+		fetch     []int
+		ok        bool
+	}{
+		{[]bool{true, true, true, true, true, true}, []int{0, 1, 2, 3}, true},
+		{[]bool{true, false, true, true, true, true}, []int{0, 2, 3, 4}, true},
+		{[]bool{false, true, false, true, false, true}, nil, false},
+		{[]bool{false, true, false, true, true, true}, []int{1, 3, 4, 5}, true},
+		{[]bool{true, true, true}, nil, false},
+	}
+	for _, tc := range testCases {
+		fetch, ok := PlanRepair(tc.available, DataBlocks, ParityBlocks)
+		if ok != tc.ok || !reflect.DeepEqual(fetch, tc.fetch) {
+			t.Errorf("plan repair of %v: expect %v %v, got %v %v", tc.available, tc.fetch, tc.ok, fetch, ok)
+		}
+	}
+}
@@ -1,14 +1,20 @@
 package redundancy
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/rs/zerolog/log"
-
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/piece"
 	"github.com/zkMeLabs/mechain-common/go/redundancy/erasure"
+	"github.com/zkMeLabs/mechain-common/go/tracing"
 )
 
+// logger is the logger of the redundancy module
+var logger = log.Module("redundancy")
+
 // PieceObject - details of the erasure encoded piece
 type PieceObject struct {
 	Key       string
@@ -38,7 +44,7 @@ var defaultECConfig = ECConfig{
 // NewSegment creates a new Segment object
 func NewSegment(size int64, content []byte, segmentID int, objectID string) *Segment {
 	return &Segment{
-		SegmentName: objectID + "_s" + strconv.Itoa(segmentID),
+		SegmentName: objectID + piece.SegmentSeparator + strconv.Itoa(segmentID),
 		SegmentSize: size,
 		SegmentID:   segmentID,
 		Data:        content,
@@ -49,19 +55,19 @@ func NewSegment(size int64, content []byte, segmentID int, objectID string) *Seg
 func EncodeSegment(s *Segment) ([]*PieceObject, error) {
 	encoder, err := erasure.NewRSEncoder(defaultECConfig.dataBlocks, defaultECConfig.parityBlocks, s.SegmentSize)
 	if err != nil {
-		log.Error().Msg("new RSEncoder fail" + err.Error())
+		logger.Errorf("new RSEncoder fail: %s", err)
 		return nil, err
 	}
 	shards, err := encoder.EncodeData(s.Data)
 	if err != nil {
-		log.Error().Msg("encode data fail :" + err.Error() + "segment name:" + s.SegmentName)
+		logger.Errorf("encode data fail: %s, segment name: %s", err, s.SegmentName)
 		return nil, err
 	}
 
 	pieceObjectList := make([]*PieceObject, DataBlocks+ParityBlocks)
 	for index, shard := range shards {
 		piece := &PieceObject{
-			Key:       s.SegmentName + "_p" + strconv.Itoa(index),
+			Key:       s.SegmentName + piece.ECSeparator + strconv.Itoa(index),
 			ECData:    shard,
 			ECIndex:   index,
 			PieceSize: len(shard),
@@ -76,7 +82,7 @@ func EncodeSegment(s *Segment) ([]*PieceObject, error) {
 func DecodeSegment(pieces []*PieceObject, segmentSize int64) (*Segment, error) {
 	encoder, err := erasure.NewRSEncoder(defaultECConfig.dataBlocks, defaultECConfig.parityBlocks, segmentSize)
 	if err != nil {
-		log.Error().Msg("new RSEncoder fail" + err.Error())
+		logger.Errorf("new RSEncoder fail: %s", err)
 		return nil, err
 	}
 
@@ -87,19 +93,19 @@ func DecodeSegment(pieces []*PieceObject, segmentSize int64) (*Segment, error) {
 
 	deCodeBytes, err := encoder.GetOriginalData(pieceObjectData, segmentSize)
 	if err != nil {
-		log.Error().Msg("reconstruct segment content fail:" + err.Error())
+		logger.Errorf("reconstruct segment content fail: %s", err)
 		return nil, err
 	}
 
 	// construct the segmentId and segmentName from piece key
 	pieceName := pieces[0].Key
-	segIndex := strings.Index(pieceName, "_s")
-	ecIndex := strings.Index(pieceName, "_p")
+	segIndex := strings.Index(pieceName, piece.SegmentSeparator)
+	ecIndex := strings.Index(pieceName, piece.ECSeparator)
 
-	segIDStr := pieceName[segIndex+2 : ecIndex]
+	segIDStr := pieceName[segIndex+len(piece.SegmentSeparator) : ecIndex]
 	segID, err := strconv.Atoi(segIDStr)
 	if err != nil {
-		log.Error().Msg("fetch segment ID fail: " + err.Error())
+		logger.Errorf("fetch segment ID fail: %s", err)
 		return nil, err
 	}
 
@@ -112,31 +118,97 @@ func DecodeSegment(pieces []*PieceObject, segmentSize int64) (*Segment, error) {
 }
 
 // EncodeRawSegment encode a raw byte array and return erasure encoded shards in orders
-func EncodeRawSegment(content []byte, dataShards, parityShards int) ([][]byte, error) {
+func EncodeRawSegment(content []byte, dataShards, parityShards int, opts ...CodecOption) (_ [][]byte, err error) {
+	start := time.Now()
+	options := newCodecOptions(opts)
+	span := options.startSpan("redundancy.Encode", tracing.Int("size", len(content)), dataShards, parityShards)
+	defer func() {
+		tracing.End(span, err)
+	}()
 	encoder, err := erasure.NewRSEncoder(dataShards, parityShards, int64(len(content)))
 	if err != nil {
-		log.Error().Msg("new RSEncoder fail:" + err.Error())
+		logger.Errorf("new RSEncoder fail: %s", err)
 		return nil, err
 	}
 	shards, err := encoder.EncodeData(content)
 	if err != nil {
 		return nil, err
 	}
+	if len(content) > 0 && options.shardChecksum {
+		appendShardChecksums(shards)
+	}
+	metrics().ObserveEncode(len(content), time.Since(start))
 	return shards, nil
 }
 
 // DecodeRawSegment decode the erasure encoded data and return original content
-// If the piece data has lost, need to pass an empty bytes array as one piece
-func DecodeRawSegment(pieceData [][]byte, segmentSize int64, dataShards, parityShards int) ([]byte, error) {
+// If the piece data has lost, need to pass an empty bytes array as one piece.
+// ErrTooFewShards is returned if less than dataShards pieces are available.
+func DecodeRawSegment(pieceData [][]byte, segmentSize int64, dataShards, parityShards int,
+	opts ...CodecOption,
+) (_ []byte, err error) {
+	// an empty segment is encoded to empty pieces
+	if segmentSize == 0 {
+		return []byte(""), nil
+	}
+	options := newCodecOptions(opts)
+	span := options.startSpan("redundancy.Decode", tracing.Int64("size", segmentSize), dataShards, parityShards)
+	defer func() {
+		tracing.End(span, err)
+	}()
+	var corrupted []int
+	if options.shardChecksum {
+		pieceData, corrupted = stripShardChecksums(pieceData)
+		if len(corrupted) > 0 {
+			logger.Warnf("drop shards with wrong checksums: %v", corrupted)
+		}
+	}
+	shardSize, err := checkShards(pieceData, dataShards, parityShards)
+	if err != nil {
+		return nil, checksumError(err, corrupted)
+	}
+	if segmentSize > int64(shardSize)*int64(dataShards) {
+		return nil, fmt.Errorf("%w: segment size %d, %d data shards of %d bytes", ErrInvalidSegmentSize, segmentSize,
+			dataShards, shardSize)
+	}
 	encoder, err := erasure.NewRSEncoder(dataShards, parityShards, segmentSize)
 	if err != nil {
-		log.Error().Msg("new RSEncoder fail:" + err.Error())
+		logger.Errorf("new RSEncoder fail: %s", err)
 		return nil, err
 	}
 
+	start := time.Now()
+	lost := lostShards(pieceData, dataShards)
 	deCodeBytes, err := encoder.GetOriginalData(pieceData, segmentSize)
 	if err != nil {
-		return nil, err
+		return nil, checksumError(err, corrupted)
 	}
+	if lost > 0 {
+		metrics().ObserveReconstruction(lost)
+		span.SetAttributes(tracing.Int("lost_shards", lost))
+	}
+	metrics().ObserveDecode(len(deCodeBytes), time.Since(start))
 	return deCodeBytes, nil
 }
+
+// ReconstructShards recreates the missing data and parity shards in place, the missing shards are the empty ones.
+// ErrTooFewShards is returned if less than dataShards shards are available.
+func ReconstructShards(shards [][]byte, dataShards, parityShards int) error {
+	shardSize, err := checkShards(shards, dataShards, parityShards)
+	if err != nil {
+		return err
+	}
+	encoder, err := erasure.NewRSEncoder(dataShards, parityShards, int64(shardSize)*int64(dataShards))
+	if err != nil {
+		logger.Errorf("new RSEncoder fail: %s", err)
+		return err
+	}
+	lost := lostShards(shards, len(shards))
+	if err = encoder.DecodeShards(shards); err != nil {
+		return err
+	}
+	if lost > 0 {
+		metrics().ObserveReconstruction(lost)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package redundancy
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
@@ -140,3 +141,66 @@ func initSegmentData(segmentSize int) []byte {
 	}
 	return segmentData
 }
+
+func TestReconstructShards(t *testing.T) {
+	segmentData := initSegmentData(1000)
+	pieceShards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+
+	// lose one data shard and one parity shard
+	shards := make([][]byte, len(pieceShards))
+	copy(shards, pieceShards)
+	shards[1] = nil
+	shards[5] = nil
+	if err = ReconstructShards(shards, DataBlocks, ParityBlocks); err != nil {
+		t.Fatalf("reconstruct failed: %s", err)
+	}
+	for i := range shards {
+		if !bytes.Equal(shards[i], pieceShards[i]) {
+			t.Errorf("shard %d is not reconstructed", i)
+		}
+	}
+
+	shards[0], shards[1], shards[2] = nil, nil, nil
+	if err = ReconstructShards(shards, DataBlocks, ParityBlocks); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+	if _, err = DecodeRawSegment(shards, 1000, DataBlocks, ParityBlocks); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+	if _, err = DecodeRawSegment(make([][]byte, DataBlocks+ParityBlocks), 1000, DataBlocks,
+		ParityBlocks); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards for all shards lost, got %v", err)
+	}
+	if decoded, err := DecodeRawSegment(make([][]byte, DataBlocks+ParityBlocks), 0, DataBlocks,
+		ParityBlocks); err != nil || len(decoded) != 0 {
+		t.Errorf("expect an empty segment, got %v", err)
+	}
+	if err = ReconstructShards(pieceShards[:5], DataBlocks, ParityBlocks); !errors.Is(err, ErrInvalidShardNum) {
+		t.Errorf("expect ErrInvalidShardNum, got %v", err)
+	}
+	copy(shards, pieceShards)
+	shards[2] = shards[2][:10]
+	if err = ReconstructShards(shards, DataBlocks, ParityBlocks); !errors.Is(err, ErrShardSizeMismatch) {
+		t.Errorf("expect ErrShardSizeMismatch, got %v", err)
+	}
+	if _, err = DecodeRawSegment(pieceShards, 2000, DataBlocks, ParityBlocks); !errors.Is(err, ErrInvalidSegmentSize) {
+		t.Errorf("expect ErrInvalidSegmentSize, got %v", err)
+	}
+}
+
+func BenchmarkEncodeRawSegment(b *testing.B) {
+	for _, segmentSize := range []int{16 * 1024, 1024 * 1024, 16 * 1024 * 1024} {
+		segmentData := initSegmentData(segmentSize)
+		b.Run(fmt.Sprintf("segment %d", segmentSize), func(b *testing.B) {
+			b.SetBytes(int64(segmentSize))
+			for i := 0; i < b.N; i++ {
+				if _, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package redundancy
+
+import (
+	"fmt"
+)
+
+// RedundancyStrategy splits a segment into the pieces stored by the SPs and reconstructs it from them
+type RedundancyStrategy interface {
+	// Encode return the pieces of the segment in orders
+	Encode(segment []byte) ([][]byte, error)
+	// Decode reconstructs the segment of segmentSize bytes, the lost pieces are empty
+	Decode(pieces [][]byte, segmentSize int64) ([]byte, error)
+	// PieceCount return the number of pieces of a segment
+	PieceCount() int
+	// RedundancyType return the redundancy type recorded on chain
+	RedundancyType() RedundancyType
+}
+
+// ReedSolomonStrategy erasure encodes a segment into data and parity shards
+type ReedSolomonStrategy struct {
+	DataShards   int
+	ParityShards int
+}
+
+// NewReedSolomonStrategy creates a ReedSolomonStrategy with the ec params
+func NewReedSolomonStrategy(dataShards, parityShards int) ReedSolomonStrategy {
+	return ReedSolomonStrategy{DataShards: dataShards, ParityShards: parityShards}
+}
+
+// Encode return the data and parity shards of the segment
+func (s ReedSolomonStrategy) Encode(segment []byte) ([][]byte, error) {
+	return EncodeRawSegment(segment, s.DataShards, s.ParityShards)
+}
+
+// Decode reconstructs the segment from at least DataShards shards
+func (s ReedSolomonStrategy) Decode(pieces [][]byte, segmentSize int64) ([]byte, error) {
+	return DecodeRawSegment(pieces, segmentSize, s.DataShards, s.ParityShards)
+}
+
+// PieceCount return the number of data and parity shards
+func (s ReedSolomonStrategy) PieceCount() int {
+	return s.DataShards + s.ParityShards
+}
+
+// RedundancyType return REDUNDANCY_EC_TYPE
+func (s ReedSolomonStrategy) RedundancyType() RedundancyType {
+	return RedundancyECType
+}
+
+// ReplicationStrategy stores a full copy of the segment in every piece
+type ReplicationStrategy struct {
+	Replicas int
+}
+
+// NewReplicationStrategy creates a ReplicationStrategy with the number of replicas
+func NewReplicationStrategy(replicas int) ReplicationStrategy {
+	return ReplicationStrategy{Replicas: replicas}
+}
+
+// Encode return Replicas pieces sharing the segment, they must not be modified
+func (s ReplicationStrategy) Encode(segment []byte) ([][]byte, error) {
+	if s.Replicas <= 0 {
+		return nil, fmt.Errorf("%w: %d replicas", ErrInvalidShardNum, s.Replicas)
+	}
+	pieces := make([][]byte, s.Replicas)
+	for i := range pieces {
+		pieces[i] = segment
+	}
+	return pieces, nil
+}
+
+// Decode return the first available piece
+func (s ReplicationStrategy) Decode(pieces [][]byte, segmentSize int64) ([]byte, error) {
+	if len(pieces) != s.Replicas {
+		return nil, fmt.Errorf("%w: %d pieces, %d replicas", ErrInvalidShardNum, len(pieces), s.Replicas)
+	}
+	if segmentSize == 0 {
+		return []byte(""), nil
+	}
+	for _, piece := range pieces {
+		if int64(len(piece)) == segmentSize {
+			return piece, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: no replica of %d bytes available", ErrTooFewShards, segmentSize)
+}
+
+// PieceCount return the number of replicas
+func (s ReplicationStrategy) PieceCount() int {
+	return s.Replicas
+}
+
+// RedundancyType return REDUNDANCY_REPLICA_TYPE
+func (s ReplicationStrategy) RedundancyType() RedundancyType {
+	return RedundancyReplicaType
+}
@@ -0,0 +1,36 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRedundancyStrategy(t *testing.T) {
+	segmentData := initSegmentData(1000)
+	for _, strategy := range []RedundancyStrategy{
+		NewReedSolomonStrategy(DataBlocks, ParityBlocks),
+		NewReplicationStrategy(3),
+	} {
+		pieces, err := strategy.Encode(segmentData)
+		if err != nil {
+			t.Fatalf("%T encode failed: %s", strategy, err)
+		}
+		if len(pieces) != strategy.PieceCount() {
+			t.Errorf("%T: expect %d pieces, got %d", strategy, strategy.PieceCount(), len(pieces))
+		}
+		pieces[0] = nil
+		decoded, err := strategy.Decode(pieces, int64(len(segmentData)))
+		if err != nil {
+			t.Fatalf("%T decode failed: %s", strategy, err)
+		}
+		if !bytes.Equal(decoded, segmentData) {
+			t.Errorf("%T: decoded segment differs", strategy)
+		}
+	}
+
+	replication := NewReplicationStrategy(2)
+	if _, err := replication.Decode([][]byte{nil, nil}, 1000); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+}
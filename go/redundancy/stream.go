@@ -0,0 +1,212 @@
+package redundancy
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy/erasure"
+)
+
+// DefaultStreamChunkSize is the size of the reads and writes of the stream encoder and decoder
+const DefaultStreamChunkSize = 64 * 1024
+
+// StreamEncoder erasure encodes a segment stream and writes each shard to its own io.Writer. The data shards are
+// written as the segment is read, only the parity shards and one chunk are kept in memory.
+type StreamEncoder struct {
+	dataShards   int
+	parityShards int
+	chunkSize    int
+}
+
+// NewStreamEncoder creates a StreamEncoder with the ec params
+func NewStreamEncoder(dataShards, parityShards int) (*StreamEncoder, error) {
+	// check the ec params
+	if _, err := erasure.NewRSEncoder(dataShards, parityShards, 0); err != nil {
+		return nil, err
+	}
+	return &StreamEncoder{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		chunkSize:    DefaultStreamChunkSize,
+	}, nil
+}
+
+// Encode reads segmentSize bytes from reader and writes the shards to writers in orders, the shards are the same as
+// the ones of EncodeRawSegment. Nothing is written for an empty segment.
+// The data shards are written one after another, the parity shards after all the data shards.
+func (e *StreamEncoder) Encode(reader io.Reader, segmentSize int64, writers []io.Writer) error {
+	if len(writers) != e.dataShards+e.parityShards {
+		return fmt.Errorf("%w: %d writers, %d data shards and %d parity shards", ErrInvalidShardNum, len(writers),
+			e.dataShards, e.parityShards)
+	}
+	if segmentSize == 0 {
+		return nil
+	}
+	encoder, err := erasure.NewRSEncoder(e.dataShards, e.parityShards, segmentSize)
+	if err != nil {
+		return err
+	}
+	shardSize := int(encoder.ShardSize())
+	parity := make([][]byte, e.parityShards)
+	for i := range parity {
+		parity[i] = make([]byte, shardSize)
+	}
+	chunk := make([]byte, min(e.chunkSize, shardSize))
+	parityChunk := make([][]byte, e.parityShards)
+
+	remaining := segmentSize
+	for i := 0; i < e.dataShards; i++ {
+		for offset := 0; offset < shardSize; offset += len(chunk) {
+			n := min(len(chunk), shardSize-offset)
+			data := chunk[:n]
+			// the tail of the last data shards is zero padded
+			read := int(min(int64(n), remaining))
+			if _, err = io.ReadFull(reader, data[:read]); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return fmt.Errorf("%w: %d bytes missing", ErrShortSegment, remaining)
+				}
+				return err
+			}
+			clear(data[read:])
+			remaining -= int64(read)
+
+			if _, err = writers[i].Write(data); err != nil {
+				return fmt.Errorf("failed to write shard %d: %w", i, err)
+			}
+			for j := range parity {
+				parityChunk[j] = parity[j][offset : offset+n]
+			}
+			if err = encoder.EncodeIdx(data, i, parityChunk); err != nil {
+				return err
+			}
+		}
+	}
+	for j, shard := range parity {
+		if _, err = writers[e.dataShards+j].Write(shard); err != nil {
+			return fmt.Errorf("failed to write shard %d: %w", e.dataShards+j, err)
+		}
+	}
+	return nil
+}
+
+// StreamDecoder reconstructs a segment from the shard streams and writes it to an io.Writer. If all the data shards
+// are available they are copied without decoding, otherwise only the data shards are kept in memory and the parity
+// shards are read chunk by chunk.
+type StreamDecoder struct {
+	dataShards   int
+	parityShards int
+	chunkSize    int
+}
+
+// NewStreamDecoder creates a StreamDecoder with the ec params
+func NewStreamDecoder(dataShards, parityShards int) (*StreamDecoder, error) {
+	// check the ec params
+	if _, err := erasure.NewRSEncoder(dataShards, parityShards, 0); err != nil {
+		return nil, err
+	}
+	return &StreamDecoder{
+		dataShards:   dataShards,
+		parityShards: parityShards,
+		chunkSize:    DefaultStreamChunkSize,
+	}, nil
+}
+
+// Decode reads the shards from readers in orders, a nil reader is a lost shard, and writes the segmentSize bytes of
+// the original segment to writer. ErrTooFewShards is returned if less than dataShards readers are available.
+// The data shards are preferred, the parity readers which are not needed are not read.
+func (d *StreamDecoder) Decode(readers []io.Reader, segmentSize int64, writer io.Writer) error {
+	if len(readers) != d.dataShards+d.parityShards {
+		return fmt.Errorf("%w: %d readers, %d data shards and %d parity shards", ErrInvalidShardNum, len(readers),
+			d.dataShards, d.parityShards)
+	}
+	available, missingData := 0, 0
+	for i, reader := range readers {
+		if reader != nil {
+			available++
+		} else if i < d.dataShards {
+			missingData++
+		}
+	}
+	if available < d.dataShards {
+		return fmt.Errorf("%w: %d shards available, %d required", ErrTooFewShards, available, d.dataShards)
+	}
+	if segmentSize == 0 {
+		return nil
+	}
+	encoder, err := erasure.NewRSEncoder(d.dataShards, d.parityShards, segmentSize)
+	if err != nil {
+		return err
+	}
+	shardSize := encoder.ShardSize()
+
+	if missingData == 0 {
+		remaining := segmentSize
+		for i := 0; i < d.dataShards && remaining > 0; i++ {
+			n := min(shardSize, remaining)
+			if _, err = io.CopyN(writer, readers[i], n); err != nil {
+				if err == io.EOF {
+					return fmt.Errorf("%w: shard %d is short", ErrShortSegment, i)
+				}
+				return err
+			}
+			remaining -= n
+		}
+		return nil
+	}
+
+	// use the first parity shards to replace the missing data shards
+	data := make([][]byte, d.dataShards)
+	for i := range data {
+		data[i] = make([]byte, shardSize)
+	}
+	parity := make([][]byte, d.parityShards)
+	for j := 0; j < d.parityShards && missingData > 0; j++ {
+		if readers[d.dataShards+j] != nil {
+			parity[j] = make([]byte, min(int64(d.chunkSize), shardSize))
+			missingData--
+		}
+	}
+	stripe := make([][]byte, d.dataShards+d.parityShards)
+	for offset := int64(0); offset < shardSize; offset += int64(d.chunkSize) {
+		end := min(offset+int64(d.chunkSize), shardSize)
+		for i := range stripe {
+			stripe[i] = nil
+			switch {
+			case i < d.dataShards && readers[i] == nil:
+				// reconstructed in place of the data shard
+				stripe[i] = data[i][offset:offset:end]
+			case i < d.dataShards:
+				stripe[i] = data[i][offset:end]
+			case parity[i-d.dataShards] != nil:
+				stripe[i] = parity[i-d.dataShards][:end-offset]
+			default:
+				continue
+			}
+			if readers[i] == nil {
+				continue
+			}
+			if _, err = io.ReadFull(readers[i], stripe[i]); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return fmt.Errorf("%w: shard %d is short", ErrShortSegment, i)
+				}
+				return err
+			}
+		}
+		if err = encoder.DecodeDataShards(stripe); err != nil {
+			return err
+		}
+		for i := 0; i < d.dataShards; i++ {
+			copy(data[i][offset:end], stripe[i])
+		}
+	}
+
+	remaining := segmentSize
+	for i := 0; i < d.dataShards && remaining > 0; i++ {
+		n := min(shardSize, remaining)
+		if _, err = writer.Write(data[i][:n]); err != nil {
+			return err
+		}
+		remaining -= n
+	}
+	return nil
+}
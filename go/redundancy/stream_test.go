@@ -0,0 +1,95 @@
+package redundancy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestStreamEncoder(t *testing.T) {
+	encoder, err := NewStreamEncoder(DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("new stream encoder failed: %s", err)
+	}
+	// use a small chunk to encode the shards in several chunks
+	encoder.chunkSize = 100
+	for _, segmentSize := range []int{1, 99, 1000, 1001, 4*DefaultStreamChunkSize + 3} {
+		segmentData := initSegmentData(segmentSize)
+		expected, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+		if err != nil {
+			t.Fatalf("segment encode failed: %s", err)
+		}
+
+		buffers := make([]*bytes.Buffer, DataBlocks+ParityBlocks)
+		writers := make([]io.Writer, len(buffers))
+		for i := range buffers {
+			buffers[i] = &bytes.Buffer{}
+			writers[i] = buffers[i]
+		}
+		if err = encoder.Encode(bytes.NewReader(segmentData), int64(segmentSize), writers); err != nil {
+			t.Fatalf("stream encode of %d bytes failed: %s", segmentSize, err)
+		}
+		for i := range expected {
+			if !bytes.Equal(buffers[i].Bytes(), expected[i]) {
+				t.Errorf("shard %d of a %d bytes segment differs from EncodeRawSegment", i, segmentSize)
+			}
+		}
+	}
+
+	writers := make([]io.Writer, DataBlocks+ParityBlocks)
+	for i := range writers {
+		writers[i] = io.Discard
+	}
+	if err = encoder.Encode(bytes.NewReader(initSegmentData(10)), 20, writers); !errors.Is(err, ErrShortSegment) {
+		t.Errorf("expect ErrShortSegment, got %v", err)
+	}
+	if err = encoder.Encode(bytes.NewReader(nil), 20, writers[:2]); !errors.Is(err, ErrInvalidShardNum) {
+		t.Errorf("expect ErrInvalidShardNum, got %v", err)
+	}
+}
+
+func TestStreamDecoder(t *testing.T) {
+	decoder, err := NewStreamDecoder(DataBlocks, ParityBlocks)
+	if err != nil {
+		t.Fatalf("new stream decoder failed: %s", err)
+	}
+	decoder.chunkSize = 100
+	for _, segmentSize := range []int{1, 1001, 4*DefaultStreamChunkSize + 3} {
+		segmentData := initSegmentData(segmentSize)
+		shards, err := EncodeRawSegment(segmentData, DataBlocks, ParityBlocks)
+		if err != nil {
+			t.Fatalf("segment encode failed: %s", err)
+		}
+		for _, lost := range [][]int{{}, {4, 5}, {1}, {0, 3}, {2, 4}} {
+			readers := make([]io.Reader, len(shards))
+			for i := range shards {
+				readers[i] = bytes.NewReader(shards[i])
+			}
+			for _, i := range lost {
+				readers[i] = nil
+			}
+			var decoded bytes.Buffer
+			if err = decoder.Decode(readers, int64(segmentSize), &decoded); err != nil {
+				t.Fatalf("stream decode of %d bytes without shards %v failed: %s", segmentSize, lost, err)
+			}
+			if !bytes.Equal(decoded.Bytes(), segmentData) {
+				t.Errorf("decoded %d bytes segment without shards %v differs", segmentSize, lost)
+			}
+		}
+	}
+
+	shards, _ := EncodeRawSegment(initSegmentData(1000), DataBlocks, ParityBlocks)
+	readers := make([]io.Reader, len(shards))
+	readers[0] = bytes.NewReader(shards[0])
+	readers[4] = bytes.NewReader(shards[4])
+	readers[5] = bytes.NewReader(shards[5])
+	if err = decoder.Decode(readers, 1000, io.Discard); !errors.Is(err, ErrTooFewShards) {
+		t.Errorf("expect ErrTooFewShards, got %v", err)
+	}
+	readers[1] = bytes.NewReader(shards[1][:10])
+	readers[2] = bytes.NewReader(shards[2])
+	if err = decoder.Decode(readers, 1000, io.Discard); !errors.Is(err, ErrShortSegment) {
+		t.Errorf("expect ErrShortSegment, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package redundancy
+
+import "strconv"
+
+// RedundancyType is the redundancy type of the pieces of an object. Its values are the ones of the RedundancyType of
+// the storage module of the chain, which this package does not import so it builds for js/wasm, see
+// StorageRedundancyType.
+type RedundancyType int32
+
+const (
+	// RedundancyECType is REDUNDANCY_EC_TYPE of the chain, the pieces are erasure encoded
+	RedundancyECType RedundancyType = 0
+	// RedundancyReplicaType is REDUNDANCY_REPLICA_TYPE of the chain, the pieces are replicas of the segments
+	RedundancyReplicaType RedundancyType = 1
+)
+
+// String return the name of the redundancy type on chain, e.g. REDUNDANCY_EC_TYPE
+func (t RedundancyType) String() string {
+	switch t {
+	case RedundancyECType:
+		return "REDUNDANCY_EC_TYPE"
+	case RedundancyReplicaType:
+		return "REDUNDANCY_REPLICA_TYPE"
+	default:
+		return strconv.Itoa(int(t))
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !js
+
+package redundancy
+
+import (
+	storagetypes "github.com/evmos/evmos/v12/x/storage/types"
+)
+
+// StorageRedundancyType return the redundancy type as the RedundancyType of the storage module of the chain
+func (t RedundancyType) StorageRedundancyType() storagetypes.RedundancyType {
+	return storagetypes.RedundancyType(t)
+}
+
+// RedundancyTypeOf return the redundancy type of the RedundancyType of the storage module of the chain
+func RedundancyTypeOf(t storagetypes.RedundancyType) RedundancyType {
+	return RedundancyType(t)
+}
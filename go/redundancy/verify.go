@@ -0,0 +1,80 @@
+package redundancy
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/redundancy/erasure"
+)
+
+// VerifyShards checks that the stored shards of a segment are consistent and return the indexes of the corrupted
+// ones, an empty list if the shards are consistent. All the shards must be present.
+// Consistent shards are verified without reconstruction. Up to parityShards/2 corrupted shards are located by
+// finding the fewest shards whose reconstruction from the others makes all the shards consistent, beyond that the
+// smallest consistent reconstruction may be another codeword which blames healthy shards, so
+// ErrCorruptionNotLocated is returned.
+func VerifyShards(shards [][]byte, dataShards, parityShards int) ([]int, error) {
+	shardSize, err := checkShards(shards, dataShards, parityShards)
+	if err != nil {
+		return nil, err
+	}
+	for index, shard := range shards {
+		if len(shard) == 0 {
+			return nil, fmt.Errorf("%w: shard %d", ErrMissingShards, index)
+		}
+	}
+	encoder, err := erasure.NewRSEncoder(dataShards, parityShards, int64(shardSize)*int64(dataShards))
+	if err != nil {
+		return nil, err
+	}
+	ok, err := encoder.Verify(shards)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return []int{}, nil
+	}
+	metrics().ObserveVerifyFailure()
+
+	for suspects := 1; suspects <= parityShards/2; suspects++ {
+		if corrupted := locateCorruption(encoder, shards, suspects); corrupted != nil {
+			return corrupted, nil
+		}
+	}
+	return nil, ErrCorruptionNotLocated
+}
+
+// locateCorruption tries every combination of suspects shards and return the indexes of the shards which differ
+// from their reconstruction if the reconstruction makes the shards consistent
+func locateCorruption(encoder erasure.RSEncoder, shards [][]byte, suspects int) []int {
+	combination := make([]int, suspects)
+	var search func(start, depth int) []int
+	search = func(start, depth int) []int {
+		if depth == suspects {
+			candidate := make([][]byte, len(shards))
+			copy(candidate, shards)
+			for _, index := range combination {
+				candidate[index] = nil
+			}
+			// DecodeShards reconstructs the suspects and verifies all the shards
+			if encoder.DecodeShards(candidate) != nil {
+				return nil
+			}
+			var corrupted []int
+			for _, index := range combination {
+				if !bytes.Equal(candidate[index], shards[index]) {
+					corrupted = append(corrupted, index)
+				}
+			}
+			return corrupted
+		}
+		for index := start; index < len(shards); index++ {
+			combination[depth] = index
+			if corrupted := search(index+1, depth+1); corrupted != nil {
+				return corrupted
+			}
+		}
+		return nil
+	}
+	return search(0, 0)
+}
@@ -0,0 +1,52 @@
+package redundancy
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyShards(t *testing.T) {
+	segmentData := initSegmentData(1000)
+	shards, err := EncodeRawSegment(segmentData, 6, 3)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+
+	corrupted, err := VerifyShards(shards, 6, 3)
+	if err != nil || len(corrupted) != 0 {
+		t.Fatalf("expect consistent shards, got %v %v", corrupted, err)
+	}
+
+	// a single corrupted shard is located with 3 parity shards, two are not
+	shards[2][10] ^= 0xff
+	corrupted, err = VerifyShards(shards, 6, 3)
+	if err != nil || !reflect.DeepEqual(corrupted, []int{2}) {
+		t.Errorf("expect shard 2 corrupted, got %v %v", corrupted, err)
+	}
+	shards[7][0] ^= 0xff
+	if _, err = VerifyShards(shards, 6, 3); !errors.Is(err, ErrCorruptionNotLocated) {
+		t.Errorf("expect ErrCorruptionNotLocated, got %v", err)
+	}
+
+	shards[0] = nil
+	if _, err = VerifyShards(shards, 6, 3); !errors.Is(err, ErrMissingShards) {
+		t.Errorf("expect ErrMissingShards, got %v", err)
+	}
+
+	// two corrupted shards are located with 4 parity shards, three are not
+	shards, err = EncodeRawSegment(segmentData, 6, 4)
+	if err != nil {
+		t.Fatalf("segment encode failed: %s", err)
+	}
+	shards[2][10] ^= 0xff
+	shards[7][0] ^= 0xff
+	corrupted, err = VerifyShards(shards, 6, 4)
+	if err != nil || !reflect.DeepEqual(corrupted, []int{2, 7}) {
+		t.Errorf("expect shards 2 and 7 corrupted, got %v %v", corrupted, err)
+	}
+	shards[4][1] ^= 0xff
+	if _, err = VerifyShards(shards, 6, 4); !errors.Is(err, ErrCorruptionNotLocated) {
+		t.Errorf("expect ErrCorruptionNotLocated, got %v", err)
+	}
+}
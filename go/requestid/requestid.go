@@ -0,0 +1,149 @@
+// Package requestid propagates the IDs of the requests across the SP components so their logs can be correlated:
+// it generates the IDs, carries them in the context, injects and extracts them from the http headers and the gRPC
+// metadata, falling back to the trace ID of a W3C traceparent, and prefixes the logs with them.
+package requestid
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zkMeLabs/mechain-common/go/id"
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+const (
+	// HTTPHeader is the http header of the ID of a request
+	HTTPHeader = "X-Request-Id"
+	// MetadataKey is the gRPC metadata key of the ID of a request
+	MetadataKey = "x-request-id"
+	// TraceParentHeader is the W3C trace context header, whose trace ID is used when a request has no ID
+	TraceParentHeader = "traceparent"
+	// MaxLength bounds the length of the IDs accepted from the clients
+	MaxLength = 128
+)
+
+type contextKey struct{}
+
+// New return a new request ID of 32 hex characters, the hex form of a version 7 UUID, see id.NewUUID, so the IDs
+// sort by time and are valid W3C trace IDs
+func New() string {
+	return id.NewUUID().Hex()
+}
+
+// Valid reports whether the request ID received from a client can be logged and propagated as is: it is not empty,
+// at most MaxLength long and only made of printable ASCII characters without space
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext return a copy of the context carrying the request ID
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext return the request ID carried by the context, empty if none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Ensure return the context carrying the request ID of the context, or a new one if it carries none, and the ID
+func Ensure(ctx context.Context) (context.Context, string) {
+	if id := FromContext(ctx); id != "" {
+		return ctx, id
+	}
+	id := New()
+	return NewContext(ctx, id), id
+}
+
+// ExtractHTTP return the request ID of the http headers, or the trace ID of their traceparent, if valid
+func ExtractHTTP(header http.Header) (string, bool) {
+	if id := header.Get(HTTPHeader); Valid(id) {
+		return id, true
+	}
+	return traceID(header.Get(TraceParentHeader))
+}
+
+// InjectHTTP sets the request ID carried by the context in the http headers, e.g. of an outgoing request
+func InjectHTTP(ctx context.Context, header http.Header) {
+	if id := FromContext(ctx); id != "" {
+		header.Set(HTTPHeader, id)
+	}
+}
+
+// ExtractMetadata return the request ID of the incoming gRPC metadata of the context, or the trace ID of their
+// traceparent, if valid
+func ExtractMetadata(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(MetadataKey); len(values) > 0 && Valid(values[0]) {
+		return values[0], true
+	}
+	if values := md.Get(TraceParentHeader); len(values) > 0 {
+		return traceID(values[0])
+	}
+	return "", false
+}
+
+// InjectMetadata return the context with the request ID it carries appended to its outgoing gRPC metadata
+func InjectMetadata(ctx context.Context) context.Context {
+	if id := FromContext(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+	}
+	return ctx
+}
+
+// traceID return the trace ID of a W3C traceparent "<version>-<trace id>-<parent id>-<flags>", if valid
+func traceID(traceParent string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceParent), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 || parts[1] == strings.Repeat("0", 32) {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || strings.ToLower(parts[1]) != parts[1] {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// Logger return the logger prefixing the logs with the request ID carried by the context, or the logger itself if
+// the context carries none
+func Logger(ctx context.Context, logger log.Logger) log.Logger {
+	id := FromContext(ctx)
+	if id == "" {
+		return logger
+	}
+	return &requestLogger{logger: logger, id: id}
+}
+
+// requestLogger passes the request ID as an argument, the IDs of the clients may contain formatting verbs
+type requestLogger struct {
+	logger log.Logger
+	id     string
+}
+
+func (l *requestLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Debugf("request_id=%s "+format, append([]interface{}{l.id}, args...)...)
+}
+
+func (l *requestLogger) Infof(format string, args ...interface{}) {
+	l.logger.Infof("request_id=%s "+format, append([]interface{}{l.id}, args...)...)
+}
+
+func (l *requestLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Warnf("request_id=%s "+format, append([]interface{}{l.id}, args...)...)
+}
+
+func (l *requestLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Errorf("request_id=%s "+format, append([]interface{}{l.id}, args...)...)
+}
@@ -0,0 +1,104 @@
+package requestid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zkMeLabs/mechain-common/go/log"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid(New()))
+	assert.NotEqual(t, New(), New())
+	assert.True(t, Valid("gateway-42"))
+	assert.False(t, Valid(""))
+	assert.False(t, Valid("with space"))
+	assert.False(t, Valid("line\nbreak"))
+	assert.False(t, Valid("ünicode"))
+	assert.False(t, Valid(strings.Repeat("a", MaxLength+1)))
+}
+
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", FromContext(ctx))
+	ctx, id := Ensure(ctx)
+	assert.Len(t, id, 32)
+	assert.Equal(t, id, FromContext(ctx))
+	same, sameID := Ensure(ctx)
+	assert.Equal(t, id, sameID)
+	assert.Equal(t, ctx, same)
+}
+
+func TestHTTP(t *testing.T) {
+	header := http.Header{}
+	_, ok := ExtractHTTP(header)
+	assert.False(t, ok)
+
+	InjectHTTP(context.Background(), header)
+	assert.Empty(t, header)
+	InjectHTTP(NewContext(context.Background(), "gateway-42"), header)
+	id, ok := ExtractHTTP(header)
+	assert.True(t, ok)
+	assert.Equal(t, "gateway-42", id)
+
+	header = http.Header{}
+	header.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	id, ok = ExtractHTTP(header)
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", id)
+	// the request ID takes precedence over the trace ID
+	header.Set(HTTPHeader, "gateway-42")
+	id, _ = ExtractHTTP(header)
+	assert.Equal(t, "gateway-42", id)
+
+	for _, invalid := range []string{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "00-4bf92f35-00f067aa0ba902b7-01", "garbage"} {
+		header = http.Header{}
+		header.Set(TraceParentHeader, invalid)
+		_, ok = ExtractHTTP(header)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	ctx := InjectMetadata(NewContext(context.Background(), "gateway-42"))
+	md, _ := metadata.FromOutgoingContext(ctx)
+	assert.Equal(t, []string{"gateway-42"}, md.Get(MetadataKey))
+
+	// the outgoing metadata of the client are the incoming metadata of the server
+	id, ok := ExtractMetadata(metadata.NewIncomingContext(context.Background(), md))
+	assert.True(t, ok)
+	assert.Equal(t, "gateway-42", id)
+
+	id, ok = ExtractMetadata(metadata.NewIncomingContext(context.Background(), metadata.Pairs(TraceParentHeader,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")))
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", id)
+
+	_, ok = ExtractMetadata(context.Background())
+	assert.False(t, ok)
+	assert.Equal(t, context.Background(), InjectMetadata(context.Background()))
+}
+
+type recordLogger struct {
+	log.NopLogger
+	infos []string
+}
+
+func (l *recordLogger) Infof(format string, args ...interface{}) {
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+}
+
+func TestLogger(t *testing.T) {
+	logger := &recordLogger{}
+	assert.Equal(t, log.Logger(logger), Logger(context.Background(), logger))
+
+	Logger(NewContext(context.Background(), "id%d"), logger).Infof("put object %s", "photo.jpg")
+	assert.Equal(t, []string{"request_id=id%d put object photo.jpg"}, logger.infos)
+}
@@ -0,0 +1,240 @@
+// Package resource builds and parses the resource names (GRN) of the buckets, objects and groups used by the
+// permission module of the chain, e.g. "grn:o::bucket/object"
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Type is the type of a resource, the values are the ResourceType of the chain
+type Type int32
+
+const (
+	TypeUnspecified Type = 0
+	TypeBucket      Type = 1
+	TypeObject      Type = 2
+	TypeGroup       Type = 3
+)
+
+// String return the name of the ResourceType of the chain
+func (t Type) String() string {
+	switch t {
+	case TypeBucket:
+		return "RESOURCE_TYPE_BUCKET"
+	case TypeObject:
+		return "RESOURCE_TYPE_OBJECT"
+	case TypeGroup:
+		return "RESOURCE_TYPE_GROUP"
+	default:
+		return "RESOURCE_TYPE_UNSPECIFIED"
+	}
+}
+
+const (
+	BucketTypeAbbr = "b"
+	ObjectTypeAbbr = "o"
+	GroupTypeAbbr  = "g"
+)
+
+var (
+	// ErrInvalidGRN is returned when a string is not a valid resource name
+	ErrInvalidGRN = errors.New("invalid resource name")
+	// ErrGRNTypeMismatch is returned when a name is read from a resource name of another type
+	ErrGRNTypeMismatch = errors.New("resource name type mismatch")
+)
+
+var (
+	validGRNRegex       = regexp.MustCompile("^grn:([bog]):([^:]*):([^:]*)$")
+	validGRNRegexNoWild = regexp.MustCompile("^grn:([bog]):([^:]*):([^:*]*)$")
+)
+
+// GRN is the resource name of a bucket, an object or a group:
+//
+//	bucket: "grn:b::bucketName"
+//	object: "grn:o::bucketName/objectName"
+//	group: "grn:g:ownerAddress:groupName"
+//
+// The names may contain the wildcards "*" and "?" in the resources of the policies.
+type GRN struct {
+	resType    Type
+	groupOwner common.Address
+	// name is the bucket name, the bucket name and the object name joined by "/" or the group name
+	name string
+}
+
+// NewBucketGRN return the resource name of the bucket
+func NewBucketGRN(bucketName string) GRN {
+	return GRN{resType: TypeBucket, name: bucketName}
+}
+
+// NewObjectGRN return the resource name of the object
+func NewObjectGRN(bucketName, objectName string) GRN {
+	return GRN{resType: TypeObject, name: bucketName + "/" + objectName}
+}
+
+// NewGroupGRN return the resource name of the group of the owner
+func NewGroupGRN(owner common.Address, groupName string) GRN {
+	return GRN{resType: TypeGroup, groupOwner: owner, name: groupName}
+}
+
+// Type return the type of the resource
+func (r GRN) Type() Type {
+	return r.resType
+}
+
+// String return the resource name, it is empty for the zero GRN
+func (r GRN) String() string {
+	switch r.resType {
+	case TypeBucket:
+		return strings.TrimSuffix("grn:"+BucketTypeAbbr+"::"+r.name, ":")
+	case TypeObject:
+		return strings.TrimSuffix("grn:"+ObjectTypeAbbr+"::"+r.name, ":")
+	case TypeGroup:
+		return strings.TrimSuffix("grn:"+GroupTypeAbbr+":"+r.groupOwner.Hex()+":"+r.name, ":")
+	default:
+		return ""
+	}
+}
+
+// BucketName return the bucket name of a bucket or an object resource
+func (r GRN) BucketName() (string, error) {
+	switch r.resType {
+	case TypeBucket:
+		return r.name, nil
+	case TypeObject:
+		bucketName, _, err := splitObjectName(r.name)
+		return bucketName, err
+	default:
+		return "", fmt.Errorf("%w: no bucket name in a %s", ErrGRNTypeMismatch, r.resType)
+	}
+}
+
+// BucketAndObjectName return the bucket name and the object name of an object resource
+func (r GRN) BucketAndObjectName() (string, string, error) {
+	if r.resType != TypeObject {
+		return "", "", fmt.Errorf("%w: no object name in a %s", ErrGRNTypeMismatch, r.resType)
+	}
+	return splitObjectName(r.name)
+}
+
+// GroupOwnerAndName return the owner and the name of a group resource
+func (r GRN) GroupOwnerAndName() (common.Address, string, error) {
+	if r.resType != TypeGroup {
+		return common.Address{}, "", fmt.Errorf("%w: no group in a %s", ErrGRNTypeMismatch, r.resType)
+	}
+	return r.groupOwner, r.name, nil
+}
+
+// Equal return true if the resource names are the same
+func (r GRN) Equal(other GRN) bool {
+	return r == other
+}
+
+// Match return true if the resource name, which may contain wildcards, matches the resource name target of the
+// same type: "*" matches any sequence of characters, including "/", and "?" matches a single character
+func (r GRN) Match(target GRN) bool {
+	return r.resType == target.resType && r.groupOwner == target.groupOwner && matchWildcard(r.name, target.name)
+}
+
+// Parse parses a resource name without wildcards and validates its names like the chain
+func Parse(grn string) (GRN, error) {
+	return parse(grn, false)
+}
+
+// ParseWithWildcards parses a resource name of a policy, the bucket and group names may contain wildcards
+func ParseWithWildcards(grn string) (GRN, error) {
+	return parse(grn, true)
+}
+
+func parse(grn string, wildcards bool) (GRN, error) {
+	regex := validGRNRegexNoWild
+	if wildcards {
+		regex = validGRNRegex
+	}
+	match := regex.FindStringSubmatch(grn)
+	if match == nil {
+		return GRN{}, fmt.Errorf("%w: %q", ErrInvalidGRN, grn)
+	}
+	abbr, account, name := match[1], match[2], match[3]
+
+	switch abbr {
+	case BucketTypeAbbr:
+		if account != "" {
+			return GRN{}, fmt.Errorf("%w: account in bucket resource name %q", ErrInvalidGRN, grn)
+		}
+		if strings.Contains(name, "/") {
+			return GRN{}, fmt.Errorf("%w: '/' in bucket resource name %q", ErrInvalidGRN, grn)
+		}
+		if !wildcards {
+			if err := CheckBucketName(name); err != nil {
+				return GRN{}, fmt.Errorf("%w: %w", ErrInvalidGRN, err)
+			}
+		}
+		return NewBucketGRN(name), nil
+	case ObjectTypeAbbr:
+		if account != "" {
+			return GRN{}, fmt.Errorf("%w: account in object resource name %q", ErrInvalidGRN, grn)
+		}
+		if _, _, err := splitObjectName(name); err != nil {
+			return GRN{}, err
+		}
+		return GRN{resType: TypeObject, name: name}, nil
+	default:
+		if !common.IsHexAddress(account) {
+			return GRN{}, fmt.Errorf("%w: invalid group owner %q", ErrInvalidGRN, account)
+		}
+		if !wildcards {
+			if err := CheckGroupName(name); err != nil {
+				return GRN{}, fmt.Errorf("%w: %w", ErrInvalidGRN, err)
+			}
+		}
+		return NewGroupGRN(common.HexToAddress(account), name), nil
+	}
+}
+
+// splitObjectName splits the name of an object resource into its valid bucket name and object name
+func splitObjectName(name string) (string, string, error) {
+	bucketName, objectName, found := strings.Cut(name, "/")
+	if !found {
+		return "", "", fmt.Errorf("%w: expect bucketName/objectName, actual %q", ErrInvalidGRN, name)
+	}
+	if err := CheckBucketName(bucketName); err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidGRN, err)
+	}
+	if err := CheckObjectName(objectName); err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidGRN, err)
+	}
+	return bucketName, objectName, nil
+}
+
+// matchWildcard return true if the name matches the pattern of "*" and "?" wildcards
+func matchWildcard(patternStr, nameStr string) bool {
+	pattern, name := []rune(patternStr), []rune(nameStr)
+	p, n := 0, 0
+	star, starName := -1, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+		case p < len(pattern) && pattern[p] == '*':
+			star, starName = p, n
+			p++
+		case star >= 0:
+			p = star + 1
+			starName++
+			n = starName
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
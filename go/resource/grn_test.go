@@ -0,0 +1,99 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var owner = common.HexToAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed")
+
+func TestGRN(t *testing.T) {
+	for _, tc := range []struct {
+		grn      GRN
+		expected string
+	}{
+		{NewBucketGRN("bucket"), "grn:b::bucket"},
+		{NewObjectGRN("bucket", "dir/object"), "grn:o::bucket/dir/object"},
+		{NewGroupGRN(owner, "group"), "grn:g:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed:group"},
+	} {
+		assert.Equal(t, tc.expected, tc.grn.String())
+		parsed, err := Parse(tc.expected)
+		require.NoError(t, err, tc.expected)
+		assert.True(t, tc.grn.Equal(parsed), tc.expected)
+	}
+	assert.Equal(t, "", GRN{}.String())
+
+	grn := NewObjectGRN("bucket", "dir/object")
+	assert.Equal(t, TypeObject, grn.Type())
+	bucketName, objectName, err := grn.BucketAndObjectName()
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", bucketName)
+	assert.Equal(t, "dir/object", objectName)
+	bucketName, err = grn.BucketName()
+	require.NoError(t, err)
+	assert.Equal(t, "bucket", bucketName)
+	_, _, err = grn.GroupOwnerAndName()
+	assert.ErrorIs(t, err, ErrGRNTypeMismatch)
+
+	groupOwner, groupName, err := NewGroupGRN(owner, "group").GroupOwnerAndName()
+	require.NoError(t, err)
+	assert.Equal(t, owner, groupOwner)
+	assert.Equal(t, "group", groupName)
+	_, err = NewGroupGRN(owner, "group").BucketName()
+	assert.ErrorIs(t, err, ErrGRNTypeMismatch)
+	_, _, err = NewBucketGRN("bucket").BucketAndObjectName()
+	assert.ErrorIs(t, err, ErrGRNTypeMismatch)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, invalid := range []string{
+		"",
+		"grn:x::bucket",
+		"grn:b::bucket:extra",
+		"grn:b:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed:bucket",
+		"grn:b::bu",
+		"grn:b::Bucket",
+		"grn:b::192.168.1.1",
+		"grn:b::bucket/object",
+		"grn:b::buck*",
+		"grn:o::bucket",
+		"grn:o::bucket/",
+		"grn:o::bucket/a//b",
+		"grn:o::bucket/a/../b",
+		"grn:o:owner:bucket/object",
+		"grn:g:owner:group",
+		"grn:g:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed:gr",
+		"grn:g:0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed:" + strings.Repeat("g", 64),
+	} {
+		_, err := Parse(invalid)
+		assert.ErrorIs(t, err, ErrInvalidGRN, invalid)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	pattern, err := ParseWithWildcards("grn:b::sample*bucket")
+	require.NoError(t, err)
+	assert.True(t, pattern.Match(NewBucketGRN("samplebucket")))
+	assert.True(t, pattern.Match(NewBucketGRN("sample-new-bucket")))
+	assert.False(t, pattern.Match(NewBucketGRN("sample-bucket-2")))
+	assert.False(t, pattern.Match(NewObjectGRN("samplebucket", "object")))
+
+	pattern, err = ParseWithWildcards("grn:o::bucket/dir/*")
+	require.NoError(t, err)
+	assert.True(t, pattern.Match(NewObjectGRN("bucket", "dir/sub/object")))
+	assert.False(t, pattern.Match(NewObjectGRN("bucket", "other/object")))
+
+	pattern, err = ParseWithWildcards("grn:g:" + owner.Hex() + ":group-?")
+	require.NoError(t, err)
+	assert.True(t, pattern.Match(NewGroupGRN(owner, "group-中")))
+	assert.False(t, pattern.Match(NewGroupGRN(owner, "group-10")))
+	assert.False(t, pattern.Match(NewGroupGRN(common.Address{}, "group-1")))
+
+	// the bucket name of an object resource can not contain wildcards like on chain
+	_, err = ParseWithWildcards("grn:o::buck*/object")
+	assert.ErrorIs(t, err, ErrInvalidGRN)
+}
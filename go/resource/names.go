@@ -0,0 +1,175 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MinBucketNameLength is the minimum length in bytes of a bucket name
+	MinBucketNameLength = 3
+	// MaxBucketNameLength is the maximum length in bytes of a bucket name
+	MaxBucketNameLength = 63
+	// MaxObjectNameLength is the maximum length in bytes of an object name
+	MaxObjectNameLength = 1024
+	// MinGroupNameLength is the minimum length in bytes of a group name
+	MinGroupNameLength = 3
+	// MaxGroupNameLength is the maximum length in bytes of a group name
+	MaxGroupNameLength = 63
+)
+
+var (
+	// ErrInvalidBucketName is the Kind of the NameError of a bucket name
+	ErrInvalidBucketName = errors.New("invalid bucket name")
+	// ErrInvalidObjectName is the Kind of the NameError of an object name
+	ErrInvalidObjectName = errors.New("invalid object name")
+	// ErrInvalidGroupName is the Kind of the NameError of a group name
+	ErrInvalidGroupName = errors.New("invalid group name")
+)
+
+// Violation is the naming rule broken by a name
+type Violation int
+
+const (
+	// ViolationEmpty is reported for an empty or blank name
+	ViolationEmpty Violation = iota + 1
+	// ViolationTooShort is reported for a name shorter than the minimum length
+	ViolationTooShort
+	// ViolationTooLong is reported for a name longer than the maximum length
+	ViolationTooLong
+	// ViolationInvalidCharacter is reported for a bucket name which is not DNS compatible: only lower case
+	// letters, digits, dots and hyphens, starting and ending with a letter or a digit and no dot next to a dot or
+	// a hyphen
+	ViolationInvalidCharacter
+	// ViolationIPAddress is reported for a bucket name formatted as an ip address
+	ViolationIPAddress
+	// ViolationNotUTF8 is reported for an object or group name which is not valid UTF-8
+	ViolationNotUTF8
+	// ViolationBadPathComponent is reported for an object name with a "." or ".." path component
+	ViolationBadPathComponent
+	// ViolationEmptyPathComponent is reported for an object name containing "//"
+	ViolationEmptyPathComponent
+)
+
+// String return the description of the naming rule
+func (v Violation) String() string {
+	switch v {
+	case ViolationEmpty:
+		return "empty"
+	case ViolationTooShort:
+		return "too short"
+	case ViolationTooLong:
+		return "too long"
+	case ViolationInvalidCharacter:
+		return "invalid characters"
+	case ViolationIPAddress:
+		return "ip address"
+	case ViolationNotUTF8:
+		return "not UTF-8"
+	case ViolationBadPathComponent:
+		return "bad path component"
+	case ViolationEmptyPathComponent:
+		return "contains \"//\""
+	default:
+		return fmt.Sprintf("violation %d", int(v))
+	}
+}
+
+// NameError describes the naming rule broken by a name, errors.Is matches its Kind
+type NameError struct {
+	// Kind is ErrInvalidBucketName, ErrInvalidObjectName or ErrInvalidGroupName
+	Kind      error
+	Name      string
+	Violation Violation
+}
+
+func (e *NameError) Error() string {
+	name := e.Name
+	if len(name) > 64 {
+		name = name[:64] + "..."
+	}
+	return fmt.Sprintf("%s %q: %s", e.Kind, name, e.Violation)
+}
+
+func (e *NameError) Unwrap() error {
+	return e.Kind
+}
+
+var (
+	validBucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9\.\-]{1,61}[a-z0-9]$`)
+	ipAddress       = regexp.MustCompile(`^(\d+\.){3}\d+$`)
+)
+
+// CheckBucketName return a NameError of ErrInvalidBucketName if the bucket name is not 3 to 63 DNS compatible
+// characters or looks like an ip address
+func CheckBucketName(bucketName string) error {
+	violation := func(v Violation) error {
+		return &NameError{Kind: ErrInvalidBucketName, Name: bucketName, Violation: v}
+	}
+	switch {
+	case strings.TrimSpace(bucketName) == "":
+		return violation(ViolationEmpty)
+	case len(bucketName) < MinBucketNameLength:
+		return violation(ViolationTooShort)
+	case len(bucketName) > MaxBucketNameLength:
+		return violation(ViolationTooLong)
+	case ipAddress.MatchString(bucketName):
+		return violation(ViolationIPAddress)
+	case strings.Contains(bucketName, "..") || strings.Contains(bucketName, ".-") ||
+		strings.Contains(bucketName, "-.") || !validBucketName.MatchString(bucketName):
+		return violation(ViolationInvalidCharacter)
+	}
+	return nil
+}
+
+// CheckObjectName return a NameError of ErrInvalidObjectName if the object name is blank, longer than 1024 bytes,
+// has a "." or ".." path component, is not UTF-8 or contains "//"
+func CheckObjectName(objectName string) error {
+	violation := func(v Violation) error {
+		return &NameError{Kind: ErrInvalidObjectName, Name: objectName, Violation: v}
+	}
+	switch {
+	case strings.TrimSpace(objectName) == "":
+		return violation(ViolationEmpty)
+	case len(objectName) > MaxObjectNameLength:
+		return violation(ViolationTooLong)
+	case hasBadPathComponent(objectName):
+		return violation(ViolationBadPathComponent)
+	case !utf8.ValidString(objectName):
+		return violation(ViolationNotUTF8)
+	case strings.Contains(objectName, "//"):
+		return violation(ViolationEmptyPathComponent)
+	}
+	return nil
+}
+
+// CheckGroupName return a NameError of ErrInvalidGroupName if the group name is not 3 to 63 bytes of UTF-8
+func CheckGroupName(groupName string) error {
+	violation := func(v Violation) error {
+		return &NameError{Kind: ErrInvalidGroupName, Name: groupName, Violation: v}
+	}
+	switch {
+	case strings.TrimSpace(groupName) == "":
+		return violation(ViolationEmpty)
+	case len(groupName) < MinGroupNameLength:
+		return violation(ViolationTooShort)
+	case len(groupName) > MaxGroupNameLength:
+		return violation(ViolationTooLong)
+	case !utf8.ValidString(groupName):
+		return violation(ViolationNotUTF8)
+	}
+	return nil
+}
+
+func hasBadPathComponent(path string) bool {
+	for _, component := range strings.Split(strings.TrimSpace(path), "/") {
+		switch strings.TrimSpace(component) {
+		case ".", "..":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckNames(t *testing.T) {
+	for _, tc := range []struct {
+		check     func(string) error
+		name      string
+		kind      error
+		violation Violation
+	}{
+		{CheckBucketName, "my-bucket.1", nil, 0},
+		{CheckBucketName, " ", ErrInvalidBucketName, ViolationEmpty},
+		{CheckBucketName, "ab", ErrInvalidBucketName, ViolationTooShort},
+		{CheckBucketName, strings.Repeat("b", 64), ErrInvalidBucketName, ViolationTooLong},
+		{CheckBucketName, "192.168.1.1", ErrInvalidBucketName, ViolationIPAddress},
+		{CheckBucketName, "my..bucket", ErrInvalidBucketName, ViolationInvalidCharacter},
+		{CheckBucketName, "my-.bucket", ErrInvalidBucketName, ViolationInvalidCharacter},
+		{CheckBucketName, "-bucket", ErrInvalidBucketName, ViolationInvalidCharacter},
+		{CheckBucketName, "My-bucket", ErrInvalidBucketName, ViolationInvalidCharacter},
+		{CheckBucketName, "my_bucket", ErrInvalidBucketName, ViolationInvalidCharacter},
+		{CheckObjectName, "dir/文件.txt", nil, 0},
+		{CheckObjectName, "", ErrInvalidObjectName, ViolationEmpty},
+		{CheckObjectName, strings.Repeat("o", 1025), ErrInvalidObjectName, ViolationTooLong},
+		{CheckObjectName, "./file", ErrInvalidObjectName, ViolationBadPathComponent},
+		{CheckObjectName, "dir/ .. /file", ErrInvalidObjectName, ViolationBadPathComponent},
+		{CheckObjectName, "\xff", ErrInvalidObjectName, ViolationNotUTF8},
+		{CheckObjectName, "dir//file", ErrInvalidObjectName, ViolationEmptyPathComponent},
+		{CheckGroupName, "group", nil, 0},
+		{CheckGroupName, "\t", ErrInvalidGroupName, ViolationEmpty},
+		{CheckGroupName, "gr", ErrInvalidGroupName, ViolationTooShort},
+		{CheckGroupName, strings.Repeat("g", 64), ErrInvalidGroupName, ViolationTooLong},
+		{CheckGroupName, "\xff\xfe\xfd", ErrInvalidGroupName, ViolationNotUTF8},
+	} {
+		err := tc.check(tc.name)
+		if tc.kind == nil {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		assert.ErrorIs(t, err, tc.kind, tc.name)
+		var nameErr *NameError
+		if assert.True(t, errors.As(err, &nameErr), tc.name) {
+			assert.Equal(t, tc.violation, nameErr.Violation, tc.name)
+			assert.Equal(t, tc.name, nameErr.Name)
+		}
+	}
+
+	assert.Equal(t, `invalid bucket name "ab": too short`, CheckBucketName("ab").Error())
+}
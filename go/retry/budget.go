@@ -0,0 +1,50 @@
+package retry
+
+import "sync"
+
+// Budget bounds the retries of an operation shared by many calls, so a failing dependency is not flooded with
+// retries: it holds up to maxTokens tokens, every failed attempt takes one token and every success gives back
+// tokenRatio tokens, and the retries are allowed while more than half of the tokens are left. It follows the retry
+// throttling of gRPC. It is safe for concurrent use.
+type Budget struct {
+	mu         sync.Mutex
+	maxTokens  float64
+	tokenRatio float64
+	tokens     float64
+}
+
+// NewBudget return a full Budget of maxTokens tokens, every success gives back tokenRatio tokens
+func NewBudget(maxTokens, tokenRatio float64) *Budget {
+	return &Budget{maxTokens: maxTokens, tokenRatio: tokenRatio, tokens: maxTokens}
+}
+
+// Tokens return the tokens left
+func (b *Budget) Tokens() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens
+}
+
+// record takes a token for a failed attempt or gives back tokenRatio tokens for a success
+func (b *Budget) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if success {
+		b.tokens += b.tokenRatio
+		if b.tokens > b.maxTokens {
+			b.tokens = b.maxTokens
+		}
+		return
+	}
+	b.tokens--
+	if b.tokens < 0 {
+		b.tokens = 0
+	}
+}
+
+// allow reports whether a retry is allowed
+func (b *Budget) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tokens > b.maxTokens/2
+}
@@ -0,0 +1,214 @@
+// Package retry retries the operations failing on temporary errors, e.g. the reads of the remote objects or the puts
+// of the pieces to the SPs, with an exponential backoff and jitter:
+//
+//	err := retry.Do(ctx, func(ctx context.Context) error {
+//		return sink.Put(ctx, key, data)
+//	}, retry.WithMaxRetries(5), retry.WithBudget(putBudget))
+//
+// The retries stop once the retries, the elapsed time or the Budget of the operation are used up, or when fn fails
+// with an error which is not retryable.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a failed operation is retried
+	DefaultMaxRetries = 3
+	// DefaultInitialBackoff is the delay before the first retry
+	DefaultInitialBackoff = 100 * time.Millisecond
+	// DefaultMaxBackoff bounds the delay between two attempts
+	DefaultMaxBackoff = 10 * time.Second
+	// DefaultMultiplier is the factor applied to the delay on every retry
+	DefaultMultiplier = 2
+	// DefaultJitter is the fraction of the delay randomized so the clients failing together do not retry together
+	DefaultJitter = 0.2
+)
+
+// Option configures Do
+type Option func(*options)
+
+type options struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	maxElapsed     time.Duration
+	retryable      func(error) bool
+	budget         *Budget
+	onRetry        func(attempt int, err error, delay time.Duration)
+	clock          clock.Clock
+}
+
+// WithMaxRetries retries a failed operation up to n times, 0 disables the retries
+func WithMaxRetries(n int) Option {
+	return func(o *options) {
+		if n >= 0 {
+			o.maxRetries = n
+		}
+	}
+}
+
+// WithBackoff waits initial before the first retry, multiplies the delay by multiplier on every retry and bounds it
+// to max, 0 leaves it unbounded
+func WithBackoff(initial, max time.Duration, multiplier float64) Option {
+	return func(o *options) {
+		o.initialBackoff = initial
+		o.maxBackoff = max
+		if multiplier >= 1 {
+			o.multiplier = multiplier
+		}
+	}
+}
+
+// WithJitter randomizes the delays by up to the fraction, between 0 and 1, of the delay, 0 disables the jitter
+func WithJitter(fraction float64) Option {
+	return func(o *options) {
+		if fraction >= 0 && fraction <= 1 {
+			o.jitter = fraction
+		}
+	}
+}
+
+// WithMaxElapsed stops retrying once d elapsed since the first attempt, or would elapse before the next one
+func WithMaxElapsed(d time.Duration) Option {
+	return func(o *options) {
+		o.maxElapsed = d
+	}
+}
+
+// WithRetryable classifies the errors which are retried instead of DefaultRetryable, the Permanent errors are never
+// retried
+func WithRetryable(retryable func(error) bool) Option {
+	return func(o *options) {
+		if retryable != nil {
+			o.retryable = retryable
+		}
+	}
+}
+
+// WithBudget retries only while the budget allows it, the budget is shared by the calls of an operation
+func WithBudget(budget *Budget) Option {
+	return func(o *options) {
+		o.budget = budget
+	}
+}
+
+// WithOnRetry calls fn before waiting delay to retry the attempt, counted from 0, failed with err, e.g. to log it
+func WithOnRetry(fn func(attempt int, err error, delay time.Duration)) Option {
+	return func(o *options) {
+		o.onRetry = fn
+	}
+}
+
+// WithClock measures the elapsed time and waits on c instead of the system clock, e.g. a clock.Fake in tests
+func WithClock(c clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		maxRetries:     DefaultMaxRetries,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		multiplier:     DefaultMultiplier,
+		jitter:         DefaultJitter,
+		retryable:      DefaultRetryable,
+		clock:          clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// permanentError marks an error which is not retried
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent wraps err so Do does not retry it, Do return err unwrapped. It return nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// DefaultRetryable return false for the errors which a retry can not fix: the errors of the contexts and the errors
+// having a Temporary method returning false, e.g. the hash.StatusError of a 403 response
+func DefaultRetryable(err error) bool {
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// Do calls fn until it succeeds or the retries are used up, it return the error of the last attempt, or the error
+// of ctx if it is done while waiting to retry
+func Do(ctx context.Context, fn func(ctx context.Context) error, opts ...Option) error {
+	o := newOptions(opts)
+	start := o.clock.Now()
+	backoff := o.initialBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if o.budget != nil {
+			o.budget.record(err == nil)
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if err == nil || attempt >= o.maxRetries || ctx.Err() != nil || !o.retryable(err) {
+			return err
+		}
+		delay := o.jitterDelay(backoff)
+		if o.maxElapsed > 0 && o.clock.Since(start)+delay > o.maxElapsed {
+			return err
+		}
+		if o.budget != nil && !o.budget.allow() {
+			return err
+		}
+		if o.onRetry != nil {
+			o.onRetry(attempt, err, delay)
+		}
+		timer := o.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		backoff = time.Duration(float64(backoff) * o.multiplier)
+		if o.maxBackoff > 0 && backoff > o.maxBackoff {
+			backoff = o.maxBackoff
+		}
+	}
+}
+
+// jitterDelay return the backoff randomized by the jitter of the options
+func (o *options) jitterDelay(backoff time.Duration) time.Duration {
+	if o.jitter == 0 || backoff <= 0 {
+		return backoff
+	}
+	delta := o.jitter * float64(backoff)
+	return time.Duration(float64(backoff) - delta + rand.Float64()*2*delta)
+}
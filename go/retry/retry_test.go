@@ -0,0 +1,136 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/clock"
+)
+
+type temporaryError bool
+
+func (e temporaryError) Error() string {
+	return "temporary error"
+}
+
+func (e temporaryError) Temporary() bool {
+	return bool(e)
+}
+
+// failing return a fn failing n times with err
+func failing(n int, err error, calls *int) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls <= n {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestDo(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	fast := []Option{WithBackoff(time.Millisecond, 4*time.Millisecond, 2), WithJitter(0)}
+
+	calls := 0
+	var delays []time.Duration
+	opts := append(fast, WithOnRetry(func(_ int, _ error, delay time.Duration) { delays = append(delays, delay) }))
+	assert.NoError(t, Do(ctx, failing(3, errFailed, &calls), opts...))
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond}, delays)
+
+	calls = 0
+	assert.ErrorIs(t, Do(ctx, failing(5, errFailed, &calls), append(fast, WithMaxRetries(2))...), errFailed)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	assert.Equal(t, temporaryError(false), Do(ctx, failing(5, temporaryError(false), &calls), fast...))
+	assert.Equal(t, 1, calls)
+	calls = 0
+	assert.NoError(t, Do(ctx, failing(2, temporaryError(true), &calls), fast...))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	assert.Equal(t, errFailed, Do(ctx, failing(5, Permanent(errFailed), &calls), fast...))
+	assert.Equal(t, 1, calls)
+	assert.Nil(t, Permanent(nil))
+
+	calls = 0
+	notRetried := WithRetryable(func(err error) bool { return !errors.Is(err, errFailed) })
+	assert.ErrorIs(t, Do(ctx, failing(5, errFailed, &calls), append(fast, notRetried)...), errFailed)
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	slow := []Option{WithBackoff(time.Hour, 0, 2), WithMaxElapsed(time.Second)}
+	assert.ErrorIs(t, Do(ctx, failing(5, errFailed, &calls), slow...), errFailed)
+	assert.Equal(t, 1, calls)
+
+	canceled, cancel := context.WithCancel(ctx)
+	calls = 0
+	err := Do(canceled, func(context.Context) error {
+		calls++
+		cancel()
+		return errFailed
+	}, fast...)
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 1, calls)
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, Do(timeout, func(context.Context) error { return errFailed }, WithBackoff(time.Hour, 0, 2)),
+		context.DeadlineExceeded)
+}
+
+func TestDoClock(t *testing.T) {
+	c := clock.NewFake(time.Unix(1700000000, 0))
+	errFailed := errors.New("failed")
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		done <- Do(context.Background(), failing(5, errFailed, &calls), WithClock(c), WithJitter(0),
+			WithBackoff(time.Minute, 0, 2), WithMaxElapsed(5*time.Minute))
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+	c.Advance(2 * time.Minute)
+	// the next retry would wait until 7 minutes elapsed
+	assert.ErrorIs(t, <-done, errFailed)
+	assert.Equal(t, 3, calls)
+}
+
+func TestJitter(t *testing.T) {
+	o := newOptions([]Option{WithJitter(0.5)})
+	for i := 0; i < 100; i++ {
+		delay := o.jitterDelay(time.Second)
+		assert.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		assert.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+	assert.Equal(t, time.Second, newOptions([]Option{WithJitter(0)}).jitterDelay(time.Second))
+}
+
+func TestBudget(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	budget := NewBudget(4, 0.5)
+	fast := []Option{WithBackoff(time.Millisecond, 0, 2), WithJitter(0), WithBudget(budget), WithMaxRetries(10)}
+
+	calls := 0
+	assert.ErrorIs(t, Do(ctx, failing(10, errFailed, &calls), fast...), errFailed)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, float64(2), budget.Tokens())
+
+	calls = 0
+	assert.ErrorIs(t, Do(ctx, failing(10, errFailed, &calls), fast...), errFailed)
+	assert.Equal(t, 1, calls)
+
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, Do(ctx, func(context.Context) error { return nil }, fast...))
+	}
+	assert.Equal(t, float64(4), budget.Tokens())
+}
@@ -0,0 +1,95 @@
+// Package segment splits the content of readers into the segments of objects, so the hashing, the uploads and the
+// encryption split the objects the same way
+package segment
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// segmentPools holds a pool of segment buffers by segment size, the splitters of the same segment size share it
+var segmentPools sync.Map
+
+// segmentPool return the pool of the buffers of segmentSize bytes
+func segmentPool(segmentSize int64) *sync.Pool {
+	if pool, ok := segmentPools.Load(segmentSize); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := segmentPools.LoadOrStore(segmentSize, &sync.Pool{
+		New: func() any {
+			return make([]byte, segmentSize)
+		},
+	})
+	return pool.(*sync.Pool)
+}
+
+// Splitter splits the content of a reader into the segments of an object, see SplitBySegment
+type Splitter struct {
+	reader      io.Reader
+	segmentSize int64
+	pool        *sync.Pool
+	index       int
+	// peek is the first byte of the next segment, read to tell whether the previous segment is the last one
+	peek    [1]byte
+	hasPeek bool
+	// err is returned by the next call of Next, io.EOF once the last segment was returned
+	err error
+}
+
+// SplitBySegment return a splitter of the reader content into segments of segmentSize bytes, only the last segment
+// is shorter. Short reads of the reader never split a segment and an empty reader has no segment, like the
+// segments of the integrity hashes. The segments are read in pooled buffers.
+func SplitBySegment(reader io.Reader, segmentSize int64) *Splitter {
+	return &Splitter{reader: reader, segmentSize: segmentSize, pool: segmentPool(segmentSize)}
+}
+
+// Next return the index of the next segment, its data and whether it is the last segment of the reader, or io.EOF
+// once the reader is drained. The next byte of the reader is read ahead to tell whether a full segment is the last
+// one. A failed read returns the error of the reader, the segments before it are returned first.
+// The data is a pooled buffer owned by the caller, which can return it to the pool with Release once it is done.
+func (s *Splitter) Next() (index int, segment []byte, isLast bool, err error) {
+	if s.err != nil {
+		return 0, nil, false, s.err
+	}
+	buffer := s.pool.Get().([]byte)[:s.segmentSize]
+	offset := 0
+	if s.hasPeek {
+		buffer[0] = s.peek[0]
+		offset = 1
+	}
+	n, err := io.ReadFull(s.reader, buffer[offset:])
+	n += offset
+	switch {
+	case errors.Is(err, io.EOF) && n == 0:
+		s.pool.Put(buffer)
+		s.err = io.EOF
+		return 0, nil, false, io.EOF
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		// the short segment is the last one
+		s.err = io.EOF
+	case err != nil:
+		s.pool.Put(buffer)
+		s.err = err
+		return 0, nil, false, err
+	default:
+		_, err = io.ReadFull(s.reader, s.peek[:])
+		s.hasPeek = err == nil
+		if err != nil {
+			s.err = err
+		}
+	}
+
+	index = s.index
+	s.index++
+	return index, buffer[:n], errors.Is(s.err, io.EOF), nil
+}
+
+// Release returns the buffer of a segment returned by Next to the pool, neither the segment nor the slices sharing
+// its buffer, e.g. the pieces split in its spare capacity, may be used after the call
+func (s *Splitter) Release(segment []byte) {
+	if int64(cap(segment)) != s.segmentSize {
+		return
+	}
+	s.pool.Put(segment[:cap(segment)])
+}
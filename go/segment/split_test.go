@@ -0,0 +1,67 @@
+package segment
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitBySegment(t *testing.T) {
+	const segmentSize = 16
+	for _, size := range []int{0, 1, segmentSize - 1, segmentSize, segmentSize + 1, 3 * segmentSize} {
+		content := make([]byte, size)
+		for i := range content {
+			content[i] = byte(i)
+		}
+
+		// short reads never split a segment
+		splitter := SplitBySegment(iotest.OneByteReader(bytes.NewReader(content)), segmentSize)
+		var joined []byte
+		count := 0
+		for {
+			index, data, isLast, err := splitter.Next()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			assert.Equal(t, count, index)
+			count++
+			assert.Equal(t, len(joined)+len(data) == size, isLast, "size %d segment %d", size, index)
+			if !isLast {
+				assert.Len(t, data, segmentSize)
+			}
+			joined = append(joined, data...)
+			splitter.Release(data)
+		}
+		assert.Equal(t, (size+segmentSize-1)/segmentSize, count, "size %d", size)
+		assert.Equal(t, content, append([]byte{}, joined...))
+		_, _, _, err := splitter.Next()
+		assert.Equal(t, io.EOF, err)
+	}
+}
+
+func TestSplitBySegmentError(t *testing.T) {
+	readErr := errors.New("read failed")
+	content := bytes.Repeat([]byte{1}, 40)
+	splitter := SplitBySegment(io.MultiReader(bytes.NewReader(content), iotest.ErrReader(readErr)), 16)
+
+	// the full segments before the error are returned, the partial segment is not
+	for index := 0; index < 2; index++ {
+		_, data, isLast, err := splitter.Next()
+		require.NoError(t, err)
+		assert.Len(t, data, 16)
+		assert.False(t, isLast)
+	}
+	_, _, _, err := splitter.Next()
+	assert.ErrorIs(t, err, readErr)
+	_, _, _, err = splitter.Next()
+	assert.ErrorIs(t, err, readErr)
+
+	// the buffers of other sizes are not pooled
+	splitter.Release(make([]byte, 8))
+}
@@ -0,0 +1,178 @@
+// Package leveldbstore implements store.Store on LevelDB
+package leveldbstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+// Store is a store.Store on a LevelDB database, its transactions run one at a time
+type Store struct {
+	db *leveldb.DB
+}
+
+// Open opens the LevelDB database in the directory, it is created if it does not exist
+func Open(dir string) (*Store, error) {
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// New return a Store on the database, e.g. opened with leveldb.Open on an in-memory storage. Closing the Store
+// closes the database.
+func New(db *leveldb.DB) *Store {
+	return &Store{db: db}
+}
+
+// convertErr return the error of the store package of a LevelDB error
+func convertErr(err error) error {
+	switch {
+	case errors.Is(err, leveldb.ErrNotFound):
+		return store.ErrNotFound
+	case errors.Is(err, leveldb.ErrClosed):
+		return store.ErrClosed
+	default:
+		return err
+	}
+}
+
+// reader is implemented by a LevelDB database, snapshot and transaction
+type reader interface {
+	Get(key []byte, ro *opt.ReadOptions) ([]byte, error)
+	Has(key []byte, ro *opt.ReadOptions) (bool, error)
+	NewIterator(slice *util.Range, ro *opt.ReadOptions) iterator.Iterator
+}
+
+// readerOf adapts a LevelDB reader to store.Reader
+type readerOf struct {
+	r reader
+}
+
+func (r readerOf) Get(key []byte) ([]byte, error) {
+	value, err := r.r.Get(key, nil)
+	return value, convertErr(err)
+}
+
+func (r readerOf) Has(key []byte) (bool, error) {
+	ok, err := r.r.Has(key, nil)
+	return ok, convertErr(err)
+}
+
+func (r readerOf) NewIterator(prefix []byte) store.Iterator {
+	var slice *util.Range
+	if len(prefix) > 0 {
+		slice = util.BytesPrefix(prefix)
+	}
+	return &levelIterator{it: r.r.NewIterator(slice, nil)}
+}
+
+// Get implements store.Reader
+func (s *Store) Get(key []byte) ([]byte, error) {
+	return readerOf{s.db}.Get(key)
+}
+
+// Has implements store.Reader
+func (s *Store) Has(key []byte) (bool, error) {
+	return readerOf{s.db}.Has(key)
+}
+
+// NewIterator implements store.Reader, the iterator visits a snapshot of the keys at its creation
+func (s *Store) NewIterator(prefix []byte) store.Iterator {
+	return readerOf{s.db}.NewIterator(prefix)
+}
+
+// Put implements store.Writer
+func (s *Store) Put(key, value []byte) error {
+	return convertErr(s.db.Put(key, value, nil))
+}
+
+// Delete implements store.Writer
+func (s *Store) Delete(key []byte) error {
+	return convertErr(s.db.Delete(key, nil))
+}
+
+// Update implements store.Store on a LevelDB transaction, which blocks the other writes until it is done
+func (s *Store) Update(ctx context.Context, fn func(txn store.Txn) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tr, err := s.db.OpenTransaction()
+	if err != nil {
+		return convertErr(err)
+	}
+	if err = fn(&txn{readerOf: readerOf{tr}, tr: tr}); err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		tr.Discard()
+		return err
+	}
+	return convertErr(tr.Commit())
+}
+
+// View implements store.Store on a LevelDB snapshot
+func (s *Store) View(ctx context.Context, fn func(r store.Reader) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	snapshot, err := s.db.GetSnapshot()
+	if err != nil {
+		return convertErr(err)
+	}
+	defer snapshot.Release()
+	return fn(readerOf{snapshot})
+}
+
+// Close implements store.Store
+func (s *Store) Close() error {
+	return convertErr(s.db.Close())
+}
+
+// txn adapts a LevelDB transaction to store.Txn
+type txn struct {
+	readerOf
+	tr *leveldb.Transaction
+}
+
+func (t *txn) Put(key, value []byte) error {
+	return convertErr(t.tr.Put(key, value, nil))
+}
+
+func (t *txn) Delete(key []byte) error {
+	return convertErr(t.tr.Delete(key, nil))
+}
+
+// levelIterator adapts a LevelDB iterator to store.Iterator
+type levelIterator struct {
+	it iterator.Iterator
+}
+
+func (it *levelIterator) Next() bool {
+	return it.it.Next()
+}
+
+func (it *levelIterator) Key() []byte {
+	return it.it.Key()
+}
+
+func (it *levelIterator) Value() []byte {
+	return it.it.Value()
+}
+
+func (it *levelIterator) Err() error {
+	return convertErr(it.it.Error())
+}
+
+func (it *levelIterator) Close() error {
+	it.it.Release()
+	return nil
+}
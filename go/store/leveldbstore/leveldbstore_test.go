@@ -0,0 +1,26 @@
+package leveldbstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+
+	"github.com/zkMeLabs/mechain-common/go/store"
+	"github.com/zkMeLabs/mechain-common/go/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	s, err := Open(t.TempDir())
+	require.NoError(t, err)
+	storetest.Run(t, s)
+	_, err = s.Get([]byte("key"))
+	require.ErrorIs(t, err, store.ErrClosed)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	require.NoError(t, err)
+	storetest.Run(t, New(db))
+}
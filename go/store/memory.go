@@ -0,0 +1,266 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a Store keeping the keys in memory, e.g. for tests. Its transactions run one at a time and fn must
+// not use the store itself.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	keys   []string
+	values map[string][]byte
+	closed bool
+}
+
+// NewMemoryStore return an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{values: make(map[string][]byte)}
+}
+
+// Get implements Reader
+func (s *MemoryStore) Get(key []byte) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	return s.get(key)
+}
+
+func (s *MemoryStore) get(key []byte) ([]byte, error) {
+	value, ok := s.values[string(key)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return bytes.Clone(value), nil
+}
+
+// Has implements Reader
+func (s *MemoryStore) Has(key []byte) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return false, ErrClosed
+	}
+	_, ok := s.values[string(key)]
+	return ok, nil
+}
+
+// NewIterator implements Reader, the iterator visits a snapshot of the keys at its creation
+func (s *MemoryStore) NewIterator(prefix []byte) Iterator {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return &sliceIterator{err: ErrClosed}
+	}
+	return s.snapshot(prefix, nil)
+}
+
+// snapshot return an iterator over the keys starting with prefix, overridden by the writes of a transaction
+func (s *MemoryStore) snapshot(prefix []byte, writes map[string][]byte) Iterator {
+	p := string(prefix)
+	it := &sliceIterator{index: -1}
+	for i := sort.SearchStrings(s.keys, p); i < len(s.keys) && len(s.keys[i]) >= len(p) && s.keys[i][:len(p)] == p; i++ {
+		if _, ok := writes[s.keys[i]]; ok {
+			continue
+		}
+		it.keys = append(it.keys, []byte(s.keys[i]))
+		it.values = append(it.values, s.values[s.keys[i]])
+	}
+	if len(writes) == 0 {
+		return it
+	}
+	for key, value := range writes {
+		if value != nil && len(key) >= len(p) && key[:len(p)] == p {
+			it.keys = append(it.keys, []byte(key))
+			it.values = append(it.values, value)
+		}
+	}
+	sort.Sort(it)
+	return it
+}
+
+// Put implements Writer
+func (s *MemoryStore) Put(key, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.put(string(key), bytes.Clone(value))
+	return nil
+}
+
+func (s *MemoryStore) put(key string, value []byte) {
+	if value == nil {
+		value = []byte{}
+	}
+	if _, ok := s.values[key]; !ok {
+		i := sort.SearchStrings(s.keys, key)
+		s.keys = append(s.keys, "")
+		copy(s.keys[i+1:], s.keys[i:])
+		s.keys[i] = key
+	}
+	s.values[key] = value
+}
+
+// Delete implements Writer
+func (s *MemoryStore) Delete(key []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.delete(string(key))
+	return nil
+}
+
+func (s *MemoryStore) delete(key string) {
+	if _, ok := s.values[key]; !ok {
+		return
+	}
+	delete(s.values, key)
+	i := sort.SearchStrings(s.keys, key)
+	s.keys = append(s.keys[:i], s.keys[i+1:]...)
+}
+
+// Update implements Store
+func (s *MemoryStore) Update(ctx context.Context, fn func(txn Txn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	txn := &memoryTxn{store: s, writes: make(map[string][]byte)}
+	if err := fn(txn); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for key, value := range txn.writes {
+		if value == nil {
+			s.delete(key)
+			continue
+		}
+		s.put(key, value)
+	}
+	return nil
+}
+
+// View implements Store
+func (s *MemoryStore) View(ctx context.Context, fn func(r Reader) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fn(&memoryTxn{store: s})
+}
+
+// Close implements Store
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.keys, s.values = nil, nil
+	return nil
+}
+
+// memoryTxn is a transaction of a MemoryStore, the lock of the store is held while it runs
+type memoryTxn struct {
+	store *MemoryStore
+	// writes are the values written by the transaction, nil for a deleted key
+	writes map[string][]byte
+}
+
+func (t *memoryTxn) Get(key []byte) ([]byte, error) {
+	if value, ok := t.writes[string(key)]; ok {
+		if value == nil {
+			return nil, ErrNotFound
+		}
+		return bytes.Clone(value), nil
+	}
+	return t.store.get(key)
+}
+
+func (t *memoryTxn) Has(key []byte) (bool, error) {
+	if value, ok := t.writes[string(key)]; ok {
+		return value != nil, nil
+	}
+	_, ok := t.store.values[string(key)]
+	return ok, nil
+}
+
+func (t *memoryTxn) NewIterator(prefix []byte) Iterator {
+	return t.store.snapshot(prefix, t.writes)
+}
+
+func (t *memoryTxn) Put(key, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	t.writes[string(key)] = bytes.Clone(value)
+	return nil
+}
+
+func (t *memoryTxn) Delete(key []byte) error {
+	t.writes[string(key)] = nil
+	return nil
+}
+
+// sliceIterator iterates over sorted keys
+type sliceIterator struct {
+	keys   [][]byte
+	values [][]byte
+	index  int
+	err    error
+}
+
+func (it *sliceIterator) Len() int {
+	return len(it.keys)
+}
+
+func (it *sliceIterator) Less(i, j int) bool {
+	return bytes.Compare(it.keys[i], it.keys[j]) < 0
+}
+
+func (it *sliceIterator) Swap(i, j int) {
+	it.keys[i], it.keys[j] = it.keys[j], it.keys[i]
+	it.values[i], it.values[j] = it.values[j], it.values[i]
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.err != nil || it.index+1 >= len(it.keys) {
+		it.index = len(it.keys)
+		return false
+	}
+	it.index++
+	return true
+}
+
+func (it *sliceIterator) Key() []byte {
+	return it.keys[it.index]
+}
+
+func (it *sliceIterator) Value() []byte {
+	return it.values[it.index]
+}
+
+func (it *sliceIterator) Err() error {
+	return it.err
+}
+
+func (it *sliceIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,24 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/zkMeLabs/mechain-common/go/store"
+	"github.com/zkMeLabs/mechain-common/go/store/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	s := store.NewMemoryStore()
+	storetest.Run(t, s)
+	_, err := s.Get([]byte("counter"))
+	assert.ErrorIs(t, err, store.ErrClosed)
+}
+
+func TestPrefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("session0"), store.PrefixEnd([]byte("session/")))
+	assert.Equal(t, []byte{0x01}, store.PrefixEnd([]byte{0x00, 0xff}))
+	assert.Nil(t, store.PrefixEnd([]byte{0xff, 0xff}))
+	assert.Nil(t, store.PrefixEnd(nil))
+}
@@ -0,0 +1,197 @@
+// Package pebblestore implements store.Store on Pebble
+package pebblestore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+// Store is a store.Store on a Pebble database. Its transactions are indexed batches run one at a time, the writes
+// are synced to disk.
+type Store struct {
+	db *pebble.DB
+	// mu runs the transactions one at a time
+	mu sync.Mutex
+}
+
+// Open opens the Pebble database in the directory, it is created if it does not exist. opts may be nil.
+func Open(dir string, opts *pebble.Options) (*Store, error) {
+	db, err := pebble.Open(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// convertErr return the error of the store package of a Pebble error
+func convertErr(err error) error {
+	switch {
+	case errors.Is(err, pebble.ErrNotFound):
+		return store.ErrNotFound
+	case errors.Is(err, pebble.ErrClosed):
+		return store.ErrClosed
+	default:
+		return err
+	}
+}
+
+// reader is implemented by a Pebble database, snapshot and indexed batch
+type reader interface {
+	pebble.Reader
+}
+
+// readerOf adapts a Pebble reader to store.Reader
+type readerOf struct {
+	r reader
+}
+
+func (r readerOf) Get(key []byte) ([]byte, error) {
+	value, closer, err := r.r.Get(key)
+	if err != nil {
+		return nil, convertErr(err)
+	}
+	defer closer.Close()
+	return bytes.Clone(value), nil
+}
+
+func (r readerOf) Has(key []byte) (bool, error) {
+	_, closer, err := r.r.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, convertErr(err)
+	}
+	return true, closer.Close()
+}
+
+func (r readerOf) NewIterator(prefix []byte) store.Iterator {
+	opts := &pebble.IterOptions{}
+	if len(prefix) > 0 {
+		opts.LowerBound, opts.UpperBound = prefix, store.PrefixEnd(prefix)
+	}
+	it, err := r.r.NewIter(opts)
+	if err != nil {
+		return &pebbleIterator{err: convertErr(err)}
+	}
+	return &pebbleIterator{it: it}
+}
+
+// Get implements store.Reader
+func (s *Store) Get(key []byte) ([]byte, error) {
+	return readerOf{s.db}.Get(key)
+}
+
+// Has implements store.Reader
+func (s *Store) Has(key []byte) (bool, error) {
+	return readerOf{s.db}.Has(key)
+}
+
+// NewIterator implements store.Reader, the iterator visits a snapshot of the keys at its creation
+func (s *Store) NewIterator(prefix []byte) store.Iterator {
+	return readerOf{s.db}.NewIterator(prefix)
+}
+
+// Put implements store.Writer
+func (s *Store) Put(key, value []byte) error {
+	return convertErr(s.db.Set(key, value, pebble.Sync))
+}
+
+// Delete implements store.Writer
+func (s *Store) Delete(key []byte) error {
+	return convertErr(s.db.Delete(key, pebble.Sync))
+}
+
+// Update implements store.Store on an indexed batch committed atomically, the transactions are serializable with
+// one another but not with the direct writes
+func (s *Store) Update(ctx context.Context, fn func(txn store.Txn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	batch := s.db.NewIndexedBatch()
+	defer batch.Close()
+	if err := fn(&txn{readerOf: readerOf{batch}, batch: batch}); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return convertErr(batch.Commit(pebble.Sync))
+}
+
+// View implements store.Store on a Pebble snapshot
+func (s *Store) View(ctx context.Context, fn func(r store.Reader) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	snapshot := s.db.NewSnapshot()
+	defer snapshot.Close()
+	return fn(readerOf{snapshot})
+}
+
+// Close implements store.Store
+func (s *Store) Close() error {
+	return convertErr(s.db.Close())
+}
+
+// txn adapts a Pebble indexed batch to store.Txn
+type txn struct {
+	readerOf
+	batch *pebble.Batch
+}
+
+func (t *txn) Put(key, value []byte) error {
+	return t.batch.Set(key, value, nil)
+}
+
+func (t *txn) Delete(key []byte) error {
+	return t.batch.Delete(key, nil)
+}
+
+// pebbleIterator adapts a Pebble iterator to store.Iterator
+type pebbleIterator struct {
+	it      *pebble.Iterator
+	started bool
+	err     error
+}
+
+func (it *pebbleIterator) Next() bool {
+	if it.it == nil {
+		return false
+	}
+	if !it.started {
+		it.started = true
+		return it.it.First()
+	}
+	return it.it.Next()
+}
+
+func (it *pebbleIterator) Key() []byte {
+	return it.it.Key()
+}
+
+func (it *pebbleIterator) Value() []byte {
+	return it.it.Value()
+}
+
+func (it *pebbleIterator) Err() error {
+	if it.it == nil {
+		return it.err
+	}
+	return convertErr(it.it.Error())
+}
+
+func (it *pebbleIterator) Close() error {
+	if it.it == nil {
+		return nil
+	}
+	return convertErr(it.it.Close())
+}
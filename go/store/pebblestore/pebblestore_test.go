@@ -0,0 +1,23 @@
+package pebblestore
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/cockroachdb/pebble/vfs"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/store/storetest"
+)
+
+func TestStore(t *testing.T) {
+	s, err := Open(t.TempDir(), nil)
+	require.NoError(t, err)
+	storetest.Run(t, s)
+}
+
+func TestMemFS(t *testing.T) {
+	s, err := Open("", &pebble.Options{FS: vfs.NewMem()})
+	require.NoError(t, err)
+	storetest.Run(t, s)
+}
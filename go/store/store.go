@@ -0,0 +1,119 @@
+// Package store defines a transactional key-value store, e.g. to persist the upload sessions of an SP or the
+// references of its pieces, with an in-memory implementation. The leveldbstore and pebblestore packages implement
+// it on LevelDB and Pebble.
+//
+// The keys are ordered bytewise, the iterators visit them in order:
+//
+//	err := s.Update(ctx, func(txn store.Txn) error {
+//		refs, err := txn.Get(key)
+//		...
+//		return txn.Put(key, refs)
+//	})
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when getting a key which is not in the store
+	ErrNotFound = errors.New("key not found")
+	// ErrClosed is returned when using a closed store
+	ErrClosed = errors.New("store is closed")
+)
+
+// Iterator visits the keys of a store in order, the slices it return are only valid until the next call to Next:
+//
+//	it := r.NewIterator(prefix)
+//	defer it.Close()
+//	for it.Next() {
+//		use(it.Key(), it.Value())
+//	}
+//	return it.Err()
+type Iterator interface {
+	// Next moves to the next key and reports whether there is one
+	Next() bool
+	// Key return the current key
+	Key() []byte
+	// Value return the value of the current key
+	Value() []byte
+	// Err return the error which stopped the iteration, if any
+	Err() error
+	// Close releases the iterator, it must be called once done
+	Close() error
+}
+
+// Reader reads a store or a transaction
+type Reader interface {
+	// Get return the value of the key, or ErrNotFound. The value can be retained by the caller.
+	Get(key []byte) ([]byte, error)
+	// Has reports whether the key is in the store
+	Has(key []byte) (bool, error)
+	// NewIterator return an iterator over the keys starting with prefix, over all the keys if prefix is empty
+	NewIterator(prefix []byte) Iterator
+}
+
+// Writer writes a store or a transaction, the keys and the values are copied
+type Writer interface {
+	// Put sets the value of the key
+	Put(key, value []byte) error
+	// Delete removes the key, deleting a missing key is not an error
+	Delete(key []byte) error
+}
+
+// Txn is a read-write transaction, its reads see its own writes
+type Txn interface {
+	Reader
+	Writer
+}
+
+// Store is a transactional key-value store, it is safe for concurrent use. Its direct reads and writes are atomic
+// by themselves.
+type Store interface {
+	Reader
+	Writer
+	// Update runs fn in a transaction which is committed if fn return nil and discarded otherwise. The transactions
+	// are serializable, the store may run them one at a time.
+	Update(ctx context.Context, fn func(txn Txn) error) error
+	// View runs fn with a consistent read-only snapshot of the store
+	View(ctx context.Context, fn func(r Reader) error) error
+	// Close closes the store, the transactions running keep their result
+	Close() error
+}
+
+// PrefixEnd return the smallest key greater than all the keys starting with prefix, or nil if there is none, e.g.
+// to bound an iteration by prefix
+func PrefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// ForEach calls fn with every key starting with prefix and its value, in order, until fn return an error
+func ForEach(r Reader, prefix []byte, fn func(key, value []byte) error) error {
+	it := r.NewIterator(prefix)
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Key(), it.Value()); err != nil {
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// Count return the number of keys starting with prefix
+func Count(r Reader, prefix []byte) (int, error) {
+	n := 0
+	err := ForEach(r, prefix, func(_, _ []byte) error {
+		n++
+		return nil
+	})
+	return n, err
+}
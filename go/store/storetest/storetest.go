@@ -0,0 +1,116 @@
+// Package storetest checks the implementations of store.Store
+package storetest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+// collect return the keys and the values starting with prefix
+func collect(t *testing.T, r store.Reader, prefix string) map[string]string {
+	t.Helper()
+	var keys []string
+	values := make(map[string]string)
+	require.NoError(t, store.ForEach(r, []byte(prefix), func(key, value []byte) error {
+		keys = append(keys, string(key))
+		values[string(key)] = string(value)
+		return nil
+	}))
+	assert.IsIncreasing(t, keys, "keys must be visited in order")
+	return values
+}
+
+// Run checks the behavior of an empty store, it closes the store
+func Run(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	_, err := s.Get([]byte("missing"))
+	assert.ErrorIs(t, err, store.ErrNotFound)
+	require.NoError(t, s.Put([]byte("session/b"), []byte("2")))
+	require.NoError(t, s.Put([]byte("session/a"), []byte("1")))
+	require.NoError(t, s.Put([]byte("session0"), []byte("x")))
+	require.NoError(t, s.Put([]byte("refs/a"), []byte{}))
+	value, err := s.Get([]byte("session/a"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+	ok, err := s.Has([]byte("refs/a"))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NoError(t, s.Delete([]byte("refs/a")))
+	require.NoError(t, s.Delete([]byte("refs/a")))
+	ok, err = s.Has([]byte("refs/a"))
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Equal(t, map[string]string{"session/a": "1", "session/b": "2"}, collect(t, s, "session/"))
+	assert.Equal(t, map[string]string{"session/a": "1", "session/b": "2", "session0": "x"}, collect(t, s, ""))
+
+	// a transaction reads its own writes and is committed as a whole
+	require.NoError(t, s.Update(ctx, func(txn store.Txn) error {
+		require.NoError(t, txn.Put([]byte("session/c"), []byte("3")))
+		require.NoError(t, txn.Delete([]byte("session/a")))
+		_, err := txn.Get([]byte("session/a"))
+		assert.ErrorIs(t, err, store.ErrNotFound)
+		value, err := txn.Get([]byte("session/c"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("3"), value)
+		assert.Equal(t, map[string]string{"session/b": "2", "session/c": "3"}, collect(t, txn, "session/"))
+		return nil
+	}))
+	assert.Equal(t, map[string]string{"session/b": "2", "session/c": "3"}, collect(t, s, "session/"))
+
+	errAbort := errors.New("abort")
+	assert.ErrorIs(t, s.Update(ctx, func(txn store.Txn) error {
+		require.NoError(t, txn.Put([]byte("session/d"), []byte("4")))
+		require.NoError(t, txn.Delete([]byte("session/b")))
+		return errAbort
+	}), errAbort)
+	assert.Equal(t, map[string]string{"session/b": "2", "session/c": "3"}, collect(t, s, "session/"))
+
+	require.NoError(t, s.View(ctx, func(r store.Reader) error {
+		n, err := store.Count(r, []byte("session"))
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+		return nil
+	}))
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, s.Update(canceled, func(store.Txn) error { return nil }), context.Canceled)
+
+	// concurrent read-modify-write transactions do not lose updates
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.NoError(t, s.Update(ctx, func(txn store.Txn) error {
+					n := 0
+					value, err := txn.Get([]byte("counter"))
+					if err == nil {
+						n, err = strconv.Atoi(string(value))
+					}
+					if err != nil && !errors.Is(err, store.ErrNotFound) {
+						return err
+					}
+					return txn.Put([]byte("counter"), []byte(strconv.Itoa(n+1)))
+				}))
+			}
+		}()
+	}
+	wg.Wait()
+	value, err = s.Get([]byte("counter"))
+	require.NoError(t, err)
+	assert.Equal(t, "80", string(value))
+}
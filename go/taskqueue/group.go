@@ -0,0 +1,62 @@
+package taskqueue
+
+import (
+	"context"
+	"sync"
+)
+
+// Group runs related tasks on a Pool and stops at the first failed one, like errgroup.Group
+type Group struct {
+	pool   *Pool
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// Group return a new Group of tasks running on the pool and its context, derived from ctx, which is canceled by
+// the first failed task
+func (p *Pool) Group(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{pool: p, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Go submits the task with the context of the group, blocking while its lane is full. It return the error which
+// stopped the group instead, the task is not submitted then.
+func (g *Group) Go(fn Task, opts ...TaskOption) error {
+	if g.ctx.Err() != nil {
+		return context.Cause(g.ctx)
+	}
+	future, err := g.pool.Submit(g.ctx, fn, opts...)
+	if err != nil {
+		if g.ctx.Err() != nil {
+			return context.Cause(g.ctx)
+		}
+		g.fail(err)
+		return err
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := future.Err(); err != nil {
+			g.fail(err)
+		}
+	}()
+	return nil
+}
+
+// Wait waits for the submitted tasks to end and return the first error of the group
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
+
+func (g *Group) fail(err error) {
+	g.errOnce.Do(func() {
+		g.err = err
+		g.cancel(err)
+	})
+}
@@ -0,0 +1,357 @@
+// Package taskqueue runs tasks on a bounded pool of workers. The tasks are queued in priority lanes, the workers
+// always take the task of the highest priority first; a task may have a deadline, after which it is canceled or, if
+// it did not start, skipped; a panicking task fails with a *PanicError instead of crashing the process; and the pool
+// drains its queued tasks when it is closed. A Group runs related tasks, e.g. the segments of an object, and stops
+// at the first failed one:
+//
+//	pool := taskqueue.New(8)
+//	defer pool.Close()
+//	group, ctx := pool.Group(ctx)
+//	for _, segment := range segments {
+//		if err := group.Go(func(ctx context.Context) error { return hashSegment(ctx, segment) }); err != nil {
+//			break
+//		}
+//	}
+//	err := group.Wait()
+package taskqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/id"
+)
+
+var (
+	// ErrClosed is returned when a task is submitted to a closed Pool
+	ErrClosed = errors.New("task queue is closed")
+	// ErrQueueFull is returned by TrySubmit when the lane of the task is full
+	ErrQueueFull = errors.New("task queue is full")
+)
+
+// DefaultQueueSize is the number of tasks each lane of a Pool holds
+const DefaultQueueSize = 100
+
+// Priority is the priority of a task
+type Priority int
+
+// The priorities of the tasks, from the highest
+const (
+	PriorityHigh Priority = iota
+	PriorityNormal
+	PriorityLow
+	numPriorities
+)
+
+// Task is a unit of work, ctx is done when the task is canceled or its deadline expires
+type Task func(ctx context.Context) error
+
+// PanicError is the error of a task which panicked
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error implements error
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Option configures a Pool
+type Option func(*Pool)
+
+// WithQueueSize sets the number of tasks each lane holds, Submit blocks while the lane of its task is full
+func WithQueueSize(n int) Option {
+	return func(p *Pool) {
+		if n > 0 {
+			p.queueSize = n
+		}
+	}
+}
+
+// WithActiveObserver calls observe with the number of busy workers every time it changes, e.g. to export it as a
+// metric. It is called concurrently by the workers.
+func WithActiveObserver(observe func(active int)) Option {
+	return func(p *Pool) {
+		p.observeActive = observe
+	}
+}
+
+// TaskOption configures a submitted task
+type TaskOption func(*task)
+
+// WithPriority queues the task in the lane of the priority, PriorityNormal by default
+func WithPriority(priority Priority) TaskOption {
+	return func(t *task) {
+		if priority >= PriorityHigh && priority < numPriorities {
+			t.priority = priority
+		}
+	}
+}
+
+// WithDeadline cancels the task at the deadline, or skips it with context.DeadlineExceeded if it did not start
+func WithDeadline(deadline time.Time) TaskOption {
+	return func(t *task) {
+		t.deadline = deadline
+	}
+}
+
+// WithTimeout cancels the task after d since it was submitted, see WithDeadline
+func WithTimeout(d time.Duration) TaskOption {
+	return func(t *task) {
+		t.deadline = time.Now().Add(d)
+	}
+}
+
+// WithTaskID sets the ID of the task, e.g. the ID of the job it belongs to, a new id.ID by default, see Future.ID
+func WithTaskID(taskID string) TaskOption {
+	return func(t *task) {
+		t.future.id = taskID
+	}
+}
+
+// WithCleanup calls cleanup once the task ended or was skipped, e.g. to release the resources reserved for it
+func WithCleanup(cleanup func()) TaskOption {
+	return func(t *task) {
+		t.cleanup = cleanup
+	}
+}
+
+// task is a queued task
+type task struct {
+	ctx      context.Context
+	fn       Task
+	priority Priority
+	deadline time.Time
+	cleanup  func()
+	future   *Future
+}
+
+// Future is the outcome of a submitted task
+type Future struct {
+	id   string
+	done chan struct{}
+	err  error
+}
+
+// ID return the ID of the task, e.g. to log it
+func (f *Future) ID() string {
+	return f.id
+}
+
+// Done return a channel closed once the task ended or was skipped
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err return the error of the task once it ended
+func (f *Future) Err() error {
+	<-f.done
+	return f.err
+}
+
+// Wait waits for the task to end and return its error, or return the error of ctx if it is done first
+func (f *Future) Wait(ctx context.Context) error {
+	select {
+	case <-f.done:
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pool runs the submitted tasks on a fixed number of workers. It is safe for concurrent use.
+type Pool struct {
+	queueSize     int
+	observeActive func(active int)
+	lanes         [numPriorities]chan *task
+	// ready holds a token for every queued task so the workers block on a single channel
+	ready chan struct{}
+	// stopCtx is canceled by Shutdown to cancel the running tasks
+	stopCtx context.Context
+	stop    context.CancelFunc
+
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	activeMu sync.Mutex
+	active   int
+}
+
+// New return a Pool of n workers, at least 1
+func New(n int, opts ...Option) *Pool {
+	if n < 1 {
+		n = 1
+	}
+	p := &Pool{queueSize: DefaultQueueSize}
+	for _, opt := range opts {
+		opt(p)
+	}
+	for i := range p.lanes {
+		p.lanes[i] = make(chan *task, p.queueSize)
+	}
+	p.ready = make(chan struct{}, int(numPriorities)*p.queueSize)
+	p.stopCtx, p.stop = context.WithCancel(context.Background())
+	p.workers.Add(n)
+	for i := 0; i < n; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues the task, blocking while its lane is full. The task runs with ctx, which cancels it, or skips it if
+// it is done before the task starts.
+func (p *Pool) Submit(ctx context.Context, fn Task, opts ...TaskOption) (*Future, error) {
+	return p.submit(ctx, fn, opts, true)
+}
+
+// TrySubmit queues the task like Submit, or return ErrQueueFull at once if its lane is full
+func (p *Pool) TrySubmit(ctx context.Context, fn Task, opts ...TaskOption) (*Future, error) {
+	return p.submit(ctx, fn, opts, false)
+}
+
+func (p *Pool) submit(ctx context.Context, fn Task, opts []TaskOption, wait bool) (*Future, error) {
+	t := &task{ctx: ctx, fn: fn, priority: PriorityNormal, future: &Future{done: make(chan struct{})}}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.future.id == "" {
+		t.future.id = id.New().String()
+	}
+	// the read lock keeps Close from closing the channels while the task is queued
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return nil, ErrClosed
+	}
+	lane := p.lanes[t.priority]
+	if wait {
+		select {
+		case lane <- t:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		select {
+		case lane <- t:
+		default:
+			return nil, ErrQueueFull
+		}
+	}
+	p.ready <- struct{}{}
+	return t.future, nil
+}
+
+// QueueDepth return the number of queued tasks which did not start
+func (p *Pool) QueueDepth() int {
+	return len(p.ready)
+}
+
+// Active return the number of busy workers
+func (p *Pool) Active() int {
+	p.activeMu.Lock()
+	defer p.activeMu.Unlock()
+	return p.active
+}
+
+// Close stops accepting tasks and waits for the queued and the running tasks to end
+func (p *Pool) Close() {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.ready)
+	}
+	p.mu.Unlock()
+	p.workers.Wait()
+}
+
+// Shutdown stops accepting tasks and waits for the queued and the running tasks to end like Close until ctx is done,
+// then it cancels the running tasks, skips the queued ones and return the error of ctx once the workers exited
+func (p *Pool) Shutdown(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		p.Close()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		p.stop()
+		<-drained
+		return ctx.Err()
+	}
+}
+
+// work runs the queued tasks until the pool is closed and drained
+func (p *Pool) work() {
+	defer p.workers.Done()
+	for range p.ready {
+		p.run(p.next())
+	}
+}
+
+// next return the queued task of the highest priority. A task is queued before its token is sent to ready so one is
+// left for every received token, but a pass over the lanes may miss it while the other workers take theirs.
+func (p *Pool) next() *task {
+	for {
+		for _, lane := range p.lanes {
+			select {
+			case t := <-lane:
+				return t
+			default:
+			}
+		}
+		runtime.Gosched()
+	}
+}
+
+// run runs the task and completes its future
+func (p *Pool) run(t *task) {
+	defer close(t.future.done)
+	if t.cleanup != nil {
+		defer t.cleanup()
+	}
+	ctx := t.ctx
+	if !t.deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, t.deadline)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		t.future.err = err
+		return
+	}
+	if err := p.stopCtx.Err(); err != nil {
+		t.future.err = err
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stopCancel := context.AfterFunc(p.stopCtx, cancel)
+	defer stopCancel()
+
+	p.addActive(1)
+	defer p.addActive(-1)
+	defer func() {
+		if v := recover(); v != nil {
+			t.future.err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	t.future.err = t.fn(ctx)
+}
+
+func (p *Pool) addActive(delta int) {
+	p.activeMu.Lock()
+	defer p.activeMu.Unlock()
+	p.active += delta
+	if p.observeActive != nil {
+		p.observeActive(p.active)
+	}
+}
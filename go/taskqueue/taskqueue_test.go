@@ -0,0 +1,167 @@
+package taskqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	var maxActive atomic.Int32
+	pool := New(4, WithActiveObserver(func(active int) {
+		for {
+			current := maxActive.Load()
+			if int32(active) <= current || maxActive.CompareAndSwap(current, int32(active)) {
+				return
+			}
+		}
+	}))
+
+	var done atomic.Int32
+	futures := make([]*Future, 0, 20)
+	for i := 0; i < 20; i++ {
+		future, err := pool.Submit(ctx, func(context.Context) error {
+			time.Sleep(time.Millisecond)
+			done.Add(1)
+			return nil
+		})
+		require.NoError(t, err)
+		futures = append(futures, future)
+	}
+	errFailed := errors.New("failed")
+	failed, err := pool.Submit(ctx, func(context.Context) error { return errFailed }, WithTaskID("failed"))
+	require.NoError(t, err)
+	assert.Equal(t, "failed", failed.ID())
+	assert.NotEqual(t, futures[0].ID(), futures[1].ID())
+	panicked, err := pool.Submit(ctx, func(context.Context) error { panic("boom") })
+	require.NoError(t, err)
+
+	for _, future := range futures {
+		assert.NoError(t, future.Wait(ctx))
+	}
+	assert.ErrorIs(t, failed.Err(), errFailed)
+	var panicErr *PanicError
+	require.ErrorAs(t, panicked.Err(), &panicErr)
+	assert.Equal(t, "boom", panicErr.Value)
+	assert.NotEmpty(t, panicErr.Stack)
+	assert.LessOrEqual(t, maxActive.Load(), int32(4))
+
+	pool.Close()
+	assert.Equal(t, int32(20), done.Load())
+	_, err = pool.Submit(ctx, func(context.Context) error { return nil })
+	assert.ErrorIs(t, err, ErrClosed)
+	pool.Close()
+}
+
+func TestPriority(t *testing.T) {
+	ctx := context.Background()
+	pool := New(1)
+	defer pool.Close()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	_, err := pool.Submit(ctx, func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) Task {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	var futures []*Future
+	for _, submit := range []struct {
+		name     string
+		priority Priority
+	}{{"low", PriorityLow}, {"normal", PriorityNormal}, {"high", PriorityHigh}} {
+		future, err := pool.Submit(ctx, record(submit.name), WithPriority(submit.priority))
+		require.NoError(t, err)
+		futures = append(futures, future)
+	}
+	assert.Equal(t, 3, pool.QueueDepth())
+	close(release)
+	for _, future := range futures {
+		require.NoError(t, future.Err())
+	}
+	assert.Equal(t, []string{"high", "normal", "low"}, order)
+}
+
+func TestDeadlines(t *testing.T) {
+	ctx := context.Background()
+	pool := New(1, WithQueueSize(1))
+	release := make(chan struct{})
+	blocking, err := pool.Submit(ctx, func(context.Context) error {
+		<-release
+		return nil
+	})
+	require.NoError(t, err)
+
+	var cleaned atomic.Bool
+	skipped, err := pool.Submit(ctx, func(context.Context) error { return nil }, WithTimeout(time.Millisecond),
+		WithCleanup(func() { cleaned.Store(true) }))
+	require.NoError(t, err)
+	_, err = pool.TrySubmit(ctx, func(context.Context) error { return nil })
+	assert.ErrorIs(t, err, ErrQueueFull)
+	time.Sleep(5 * time.Millisecond)
+	close(release)
+	require.NoError(t, blocking.Err())
+	assert.ErrorIs(t, skipped.Err(), context.DeadlineExceeded)
+	assert.True(t, cleaned.Load())
+
+	canceled, err := pool.Submit(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithDeadline(time.Now().Add(time.Millisecond)))
+	require.NoError(t, err)
+	assert.ErrorIs(t, canceled.Err(), context.DeadlineExceeded)
+
+	running, err := pool.Submit(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.NoError(t, err)
+	shutdownCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Shutdown(shutdownCtx), context.DeadlineExceeded)
+	assert.ErrorIs(t, running.Err(), context.Canceled)
+}
+
+func TestGroup(t *testing.T) {
+	pool := New(2)
+	defer pool.Close()
+
+	group, ctx := pool.Group(context.Background())
+	var sum atomic.Int32
+	for i := 1; i <= 10; i++ {
+		i := i
+		require.NoError(t, group.Go(func(context.Context) error {
+			sum.Add(int32(i))
+			return nil
+		}))
+	}
+	assert.NoError(t, group.Wait())
+	assert.Equal(t, int32(55), sum.Load())
+	assert.Error(t, ctx.Err())
+
+	errFailed := errors.New("failed")
+	group, ctx = pool.Group(context.Background())
+	require.NoError(t, group.Go(func(context.Context) error { return errFailed }))
+	<-ctx.Done()
+	assert.ErrorIs(t, group.Go(func(context.Context) error { return nil }), errFailed)
+	assert.ErrorIs(t, group.Wait(), errFailed)
+}
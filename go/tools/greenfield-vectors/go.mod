@@ -0,0 +1,3 @@
+module github.com/zkMeLabs/mechain-common/go/tools/greenfield-vectors
+
+go 1.21
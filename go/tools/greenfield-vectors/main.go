@@ -0,0 +1,126 @@
+// greenfield-vectors computes the integrity hashes of the test vectors of the params of the Greenfield chain with
+// greenfield-common itself, and pins them with the greenfield-common version into the JSON file embedded by the hash
+// package. It is a separate module so the mechain-common module does not depend on greenfield-common, the
+// `greenfield-vectors` target of the Makefile adds greenfield-common at the requested version and runs it.
+//
+// Usage:
+//
+//	greenfield-vectors -out ../../hash/golden/greenfield_vectors.json
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime/debug"
+
+	gnfdhash "github.com/bnb-chain/greenfield-common/go/hash"
+)
+
+const (
+	greenfieldCommonModule = "github.com/bnb-chain/greenfield-common/go"
+	segmentSize            = 16 * 1024 * 1024
+	dataShards             = 4
+	parityShards           = 2
+)
+
+var objectSizes = []int64{0, 1, 1000, segmentSize - 1, segmentSize, segmentSize + 1, 2*segmentSize + 12345}
+
+// corpus is the file embedded by the hash package
+type corpus struct {
+	// Module and Version identify the greenfield-common release which computed the vectors
+	Module  string   `json:"module"`
+	Version string   `json:"version"`
+	Vectors []vector `json:"vectors"`
+}
+
+// vector is a test vector of the corpus, its input is the input of the test vectors of the hash package
+type vector struct {
+	Name           string   `json:"name"`
+	ObjectSize     int64    `json:"object_size"`
+	SegmentSize    int64    `json:"segment_size"`
+	DataShards     int      `json:"data_shards"`
+	ParityShards   int      `json:"parity_shards"`
+	InputSHA256    string   `json:"input_sha256"`
+	Checksums      [][]byte `json:"checksums"`
+	ContentLength  int64    `json:"content_length"`
+	RedundancyType int32    `json:"redundancy_type"`
+}
+
+func main() {
+	output := flag.String("out", "", "file to write the corpus to")
+	flag.Parse()
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "-out is required")
+		os.Exit(2)
+	}
+	if err := generate(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate the greenfield vectors: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// generate writes the corpus computed by greenfield-common to output
+func generate(output string) error {
+	c := corpus{Module: greenfieldCommonModule, Version: greenfieldCommonVersion()}
+	if c.Version == "" {
+		return fmt.Errorf("the version of %s is unknown or replaced", greenfieldCommonModule)
+	}
+	for _, objectSize := range objectSizes {
+		input := vectorData(objectSize)
+		checksums, contentLength, redundancyType, err := gnfdhash.ComputeIntegrityHash(bytes.NewReader(input),
+			segmentSize, dataShards, parityShards, true)
+		if err != nil {
+			return fmt.Errorf("object size %d: %w", objectSize, err)
+		}
+		inputSum := sha256.Sum256(input)
+		c.Vectors = append(c.Vectors, vector{
+			Name: fmt.Sprintf("greenfield_size_%d_segment_%d_ec_%d_%d", objectSize, segmentSize, dataShards,
+				parityShards),
+			ObjectSize:     objectSize,
+			SegmentSize:    segmentSize,
+			DataShards:     dataShards,
+			ParityShards:   parityShards,
+			InputSHA256:    hex.EncodeToString(inputSum[:]),
+			Checksums:      checksums,
+			ContentLength:  contentLength,
+			RedundancyType: int32(redundancyType),
+		})
+	}
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, append(content, '\n'), 0o644)
+}
+
+// greenfieldCommonVersion return the version of greenfield-common linked in the binary, an empty version if it is
+// replaced by another module or directory
+func greenfieldCommonVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == greenfieldCommonModule {
+			if dep.Replace != nil {
+				return ""
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+// vectorData return the input of the test vectors of the hash package, the i-th byte is i mod 251 plus one
+func vectorData(size int64) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i%251 + 1)
+	}
+	return data
+}
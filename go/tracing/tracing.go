@@ -0,0 +1,104 @@
+// Package tracing defines the spans the library opens around its pipelines, the hashing of the segments, the
+// erasure encoding and decoding and the piece store operations, so operators can see where the time of a slow upload
+// goes. The tracer is a no-op by default, SetTracer plugs a tracer such as an adapter of an OpenTelemetry
+// trace.Tracer, whose Start, SetAttributes, RecordError and End methods the interfaces mirror.
+package tracing
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Attribute is a key value pair describing a span, the value is a string, a bool, an int64 or a float64
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String return a string attribute
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int return an integer attribute, its value is an int64
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: int64(value)}
+}
+
+// Int64 return an integer attribute
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool return a boolean attribute
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation of a trace
+type Span interface {
+	// SetAttributes adds the attributes to the span
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed with the error
+	RecordError(err error)
+	// End ends the span, it must be called once
+	End()
+}
+
+// Tracer opens the spans
+type Tracer interface {
+	// Start opens a span named name, child of the span of ctx if any, and return the context carrying it
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// NopTracer is a Tracer whose spans record nothing
+type NopTracer struct{}
+
+// Start implements Tracer
+func (NopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attribute) {}
+
+func (nopSpan) RecordError(error) {}
+
+func (nopSpan) End() {}
+
+type tracerHolder struct {
+	tracer Tracer
+}
+
+var tracer atomic.Value
+
+func init() {
+	tracer.Store(tracerHolder{tracer: NopTracer{}})
+}
+
+// SetTracer sets the tracer of the spans of the library, nil restores the no-op tracer
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = NopTracer{}
+	}
+	tracer.Store(tracerHolder{tracer: t})
+}
+
+// GetTracer return the tracer of the spans of the library
+func GetTracer() Tracer {
+	return tracer.Load().(tracerHolder).tracer
+}
+
+// Start opens a span with the tracer of the library
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return GetTracer().Start(ctx, name, attrs...)
+}
+
+// End records the error, if any, and ends the span, e.g. deferred with the named error of a function
+func End(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
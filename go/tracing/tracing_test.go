@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type spanKey struct{}
+
+type recordSpan struct {
+	name   string
+	parent string
+	attrs  []Attribute
+	err    error
+	ended  bool
+}
+
+func (s *recordSpan) SetAttributes(attrs ...Attribute) {
+	s.attrs = append(s.attrs, attrs...)
+}
+
+func (s *recordSpan) RecordError(err error) {
+	s.err = err
+}
+
+func (s *recordSpan) End() {
+	s.ended = true
+}
+
+type recordTracer struct {
+	spans []*recordSpan
+}
+
+func (t *recordTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	span := &recordSpan{name: name, attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*recordSpan); ok {
+		span.parent = parent.name
+	}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestTracer(t *testing.T) {
+	assert.Equal(t, NopTracer{}, GetTracer())
+	ctx, span := Start(context.Background(), "nop")
+	assert.Equal(t, context.Background(), ctx)
+	End(span, errors.New("ignored"))
+
+	tracer := &recordTracer{}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	ctx, parent := Start(context.Background(), "upload", String("bucket", "mechain"))
+	_, child := Start(ctx, "segment", Int("segment", 3), Int64("size", 1<<20), Bool("last", true))
+	child.SetAttributes(String("mode", "serial"))
+	End(child, errors.New("encode failed"))
+	End(parent, nil)
+
+	assert.Len(t, tracer.spans, 2)
+	assert.Equal(t, "upload", tracer.spans[0].name)
+	assert.Equal(t, []Attribute{{Key: "bucket", Value: "mechain"}}, tracer.spans[0].attrs)
+	assert.Nil(t, tracer.spans[0].err)
+	assert.True(t, tracer.spans[0].ended)
+	assert.Equal(t, "upload", tracer.spans[1].parent)
+	assert.Equal(t, []Attribute{{Key: "segment", Value: int64(3)}, {Key: "size", Value: int64(1 << 20)},
+		{Key: "last", Value: true}, {Key: "mode", Value: "serial"}}, tracer.spans[1].attrs)
+	assert.EqualError(t, tracer.spans[1].err, "encode failed")
+
+	SetTracer(nil)
+	assert.Equal(t, NopTracer{}, GetTracer())
+}
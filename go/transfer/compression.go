@@ -0,0 +1,42 @@
+package transfer
+
+import (
+	"context"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/compression"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+// UploadCompressed compresses the object read from reader by chunks of the segment size with the codec and uploads
+// the compressed stream like Upload. It return the integrity hashes of the compressed stream, whose content length
+// is the content length of the object on chain, and the compression metadata to store with the object.
+func (u *Uploader) UploadCompressed(ctx context.Context, codec compression.Codec, objectID uint64,
+	reader io.Reader,
+) (*hash.HashResult, *compression.Info, error) {
+	compressed, err := compression.NewReader(codec, u.params.SegmentSize, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := u.Upload(ctx, objectID, compressed)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := compressed.Info()
+	return result, &info, nil
+}
+
+// DownloadDecompressed downloads the object compressed by UploadCompressed like Download and writes its content to
+// writer, every segment is verified before it is decompressed
+func (d *Downloader) DownloadDecompressed(ctx context.Context, meta ObjectMeta, info *compression.Info,
+	writer io.Writer,
+) error {
+	content, err := compression.NewWriter(*info, writer)
+	if err != nil {
+		return err
+	}
+	if err = d.Download(ctx, meta, content); err != nil {
+		return err
+	}
+	return content.Close()
+}
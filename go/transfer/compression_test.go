@@ -0,0 +1,51 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/compression"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestCompressedTransfer(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	text := bytes.Repeat([]byte("compressible content "), 300)
+
+	for _, content := range [][]byte{nil, text, hash.TestVectorData(3000), append(bytes.Clone(text),
+		hash.TestVectorData(2000)...)} {
+		store := piecestore.NewMemoryStore()
+		uploader, err := NewUploader(store, params)
+		require.NoError(t, err)
+		result, info, err := uploader.UploadCompressed(ctx, compression.Deflate(), 7, bytes.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), info.ContentLength)
+		assert.Equal(t, int64(1024), info.ChunkSize)
+
+		meta := ObjectMeta{ObjectID: 7, ContentLength: result.ContentLength, Params: params, Roots: result.Checksums}
+		fetcher := &storeFetcher{store: store, segments: redundancy.SegmentCount(result.ContentLength, 1024)}
+		var buf bytes.Buffer
+		require.NoError(t, NewDownloader(fetcher).DownloadDecompressed(ctx, meta, info, &buf))
+		assert.True(t, bytes.Equal(content, buf.Bytes()))
+	}
+
+	store := piecestore.NewMemoryStore()
+	uploader, err := NewUploader(store, params)
+	require.NoError(t, err)
+	result, info, err := uploader.UploadCompressed(ctx, compression.Deflate(), 7, bytes.NewReader(text))
+	require.NoError(t, err)
+	assert.Less(t, result.ContentLength, int64(len(text))/4)
+	meta := ObjectMeta{ObjectID: 7, ContentLength: result.ContentLength, Params: params, Roots: result.Checksums}
+	fetcher := &storeFetcher{store: store, segments: redundancy.SegmentCount(result.ContentLength, 1024)}
+	info.ContentLength++
+	err = NewDownloader(fetcher).DownloadDecompressed(ctx, meta, info, &bytes.Buffer{})
+	assert.ErrorIs(t, err, compression.ErrCorruptedStream)
+}
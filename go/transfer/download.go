@@ -0,0 +1,221 @@
+// Package transfer moves objects between the clients and the SPs: the Downloader reassembles an object from the ec
+// pieces of the SecondarySPs, the Uploader splits an object into the pieces dispatched to the SPs and the Recoverer
+// rebuilds the pieces of a failed SecondarySP
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+var (
+	// ErrInvalidObjectMeta is returned when the integrity hashes of an object do not match its redundancy params
+	ErrInvalidObjectMeta = errors.New("invalid object meta")
+	// ErrTooFewPieces is returned when less valid ec pieces than the data shards of a segment can be fetched
+	ErrTooFewPieces = errors.New("too few valid pieces to reconstruct the segment")
+)
+
+// ObjectMeta describes the object to download as stored on chain
+type ObjectMeta struct {
+	ObjectID      uint64
+	ContentLength int64
+	Params        redundancy.RedundancyParams
+	// Roots contains the integrity hash of the PrimarySP followed by the integrity hashes of the SecondarySPs
+	// ordered by ec index, like hash.HashResult.Checksums
+	Roots [][]byte
+}
+
+// PieceFetcher retrieves the pieces of the objects and their checksums from the SPs
+type PieceFetcher interface {
+	// FetchChecksums return the piece checksums of the object ordered by segment index, the segment checksums of
+	// the PrimarySP if ecIndex is piece.NoECIndex and the checksums of the ec pieces of ecIndex otherwise
+	FetchChecksums(ctx context.Context, objectID uint64, ecIndex int32) ([][]byte, error)
+	// FetchPiece return the data of the piece
+	FetchPiece(ctx context.Context, key piece.Key) ([]byte, error)
+}
+
+// Downloader reassembles the objects from the ec pieces of the SecondarySPs. Every piece is verified against the
+// checksums of its SP, which are verified against the integrity hash of the SP, and every reconstructed segment is
+// verified against the segment checksums of the PrimarySP before it is written, so no unverified byte is written.
+// Only one segment is held in memory at a time.
+type Downloader struct {
+	fetcher PieceFetcher
+}
+
+// NewDownloader return a Downloader fetching the pieces with fetcher
+func NewDownloader(fetcher PieceFetcher) *Downloader {
+	return &Downloader{fetcher: fetcher}
+}
+
+// Download writes the object to writer. The ec pieces of a segment are fetched concurrently, data pieces first,
+// and the pieces which can not be fetched or fail their checksum are replaced by parity pieces; an ec index whose
+// checksums do not match its integrity hash is not used. ErrTooFewPieces is returned if a segment can not be
+// reconstructed, the object already written is the content of the previous segments.
+func (d *Downloader) Download(ctx context.Context, meta ObjectMeta, writer io.Writer) error {
+	segmentChecksums, err := d.segmentChecksums(ctx, meta)
+	if err != nil {
+		return err
+	}
+	layout := meta.Params.Layout(meta.ContentLength)
+	pieceChecksums := &pieceChecksums{lists: make(map[int32]*checksumList)}
+	for segIndex := int64(0); segIndex < layout.SegmentCount; segIndex++ {
+		segment, err := d.verifiedSegment(ctx, meta, layout, segmentChecksums, pieceChecksums, segIndex,
+			piece.NoECIndex)
+		if err != nil {
+			return err
+		}
+		if _, err = writer.Write(segment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// segmentChecksums validates the object meta and return the verified segment checksums of the PrimarySP
+func (d *Downloader) segmentChecksums(ctx context.Context, meta ObjectMeta) ([][]byte, error) {
+	if err := meta.Params.Validate(); err != nil {
+		return nil, err
+	}
+	if len(meta.Roots) != 1+meta.Params.PieceCount() {
+		return nil, fmt.Errorf("%w: %d integrity hashes, expect %d", ErrInvalidObjectMeta, len(meta.Roots),
+			1+meta.Params.PieceCount())
+	}
+	segmentChecksums, err := d.checksums(ctx, meta, piece.NoECIndex)
+	if err != nil {
+		return nil, err
+	}
+	if segmentCount := meta.Params.Layout(meta.ContentLength).SegmentCount; int64(len(segmentChecksums)) !=
+		segmentCount {
+		return nil, fmt.Errorf("%w: %d segment checksums, expect %d", ErrInvalidObjectMeta, len(segmentChecksums),
+			segmentCount)
+	}
+	return segmentChecksums, nil
+}
+
+// verifiedSegment reconstructs the segment without the ec index skip and verify it against its segment checksum
+func (d *Downloader) verifiedSegment(ctx context.Context, meta ObjectMeta, layout redundancy.ObjectLayout,
+	segmentChecksums [][]byte, pieceChecksums *pieceChecksums, segIndex int64, skip int32,
+) ([]byte, error) {
+	segment, err := d.segment(ctx, meta, pieceChecksums, uint32(segIndex), layout.SegmentLength(segIndex), skip)
+	if err != nil {
+		return nil, fmt.Errorf("segment %d: %w", segIndex, err)
+	}
+	if !bytes.Equal(hash.GenerateChecksum(segment), segmentChecksums[segIndex]) {
+		return nil, fmt.Errorf("segment %d: %w", segIndex, hash.ErrPieceChecksumMismatch)
+	}
+	return segment, nil
+}
+
+// checksums fetches the piece checksums of the ec index and verify them against its integrity hash
+func (d *Downloader) checksums(ctx context.Context, meta ObjectMeta, ecIndex int32) ([][]byte, error) {
+	checksums, err := d.fetcher.FetchChecksums(ctx, meta.ObjectID, ecIndex)
+	if err != nil {
+		return nil, err
+	}
+	if err = hash.VerifyIntegrityHash(meta.Roots[ecIndex+1], checksums); err != nil {
+		return nil, fmt.Errorf("checksums of ec index %d: %w", ecIndex, err)
+	}
+	return checksums, nil
+}
+
+// pieceChecksums caches the verified checksums of the ec indexes across the segments of a download
+type pieceChecksums struct {
+	mu    sync.Mutex
+	lists map[int32]*checksumList
+}
+
+type checksumList struct {
+	once      sync.Once
+	checksums [][]byte
+	err       error
+}
+
+// get return the verified checksum of the piece, the checksums of its ec index are fetched on first use
+func (c *pieceChecksums) get(ctx context.Context, d *Downloader, meta ObjectMeta, key piece.Key) ([]byte, error) {
+	c.mu.Lock()
+	list, ok := c.lists[key.ECIndex]
+	if !ok {
+		list = &checksumList{}
+		c.lists[key.ECIndex] = list
+	}
+	c.mu.Unlock()
+	list.once.Do(func() {
+		list.checksums, list.err = d.checksums(ctx, meta, key.ECIndex)
+	})
+	if list.err != nil {
+		return nil, list.err
+	}
+	if int(key.SegmentIndex) >= len(list.checksums) {
+		return nil, fmt.Errorf("%w: no checksum of %s", ErrInvalidObjectMeta, key)
+	}
+	return list.checksums[key.SegmentIndex], nil
+}
+
+// segment fetches and verifies the ec pieces of the segment and reconstructs it, the ec index skip, unless it is
+// piece.NoECIndex, is not fetched
+func (d *Downloader) segment(ctx context.Context, meta ObjectMeta, checksums *pieceChecksums, segIndex uint32,
+	segmentSize int64, skip int32,
+) ([]byte, error) {
+	pieces := make([][]byte, meta.Params.PieceCount())
+	candidates := make([]int, 0, len(pieces))
+	for ecIndex := range pieces {
+		if int32(ecIndex) != skip {
+			candidates = append(candidates, ecIndex)
+		}
+	}
+	var errs []error
+	next, valid := 0, 0
+	for valid < meta.Params.DataShards && next < len(candidates) {
+		batch := min(meta.Params.DataShards-valid, len(candidates)-next)
+		batchErrs := make([]error, batch)
+		var wg sync.WaitGroup
+		for i := 0; i < batch; i++ {
+			wg.Add(1)
+			go func(i, ecIndex int) {
+				defer wg.Done()
+				pieces[ecIndex], batchErrs[i] = d.piece(ctx, meta, checksums,
+					piece.NewECKey(meta.ObjectID, segIndex, uint32(ecIndex)))
+			}(i, candidates[next+i])
+		}
+		wg.Wait()
+		for i, err := range batchErrs {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("ec index %d: %w", candidates[next+i], err))
+				continue
+			}
+			valid++
+		}
+		next += batch
+	}
+	if valid < meta.Params.DataShards {
+		return nil, fmt.Errorf("%w: %d valid, %d required: %w", ErrTooFewPieces, valid, meta.Params.DataShards,
+			errors.Join(errs...))
+	}
+	return meta.Params.Decode(pieces, segmentSize)
+}
+
+// piece fetches the ec piece and verify its checksum
+func (d *Downloader) piece(ctx context.Context, meta ObjectMeta, checksums *pieceChecksums, key piece.Key) ([]byte,
+	error,
+) {
+	checksum, err := checksums.get(ctx, d, meta, key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.fetcher.FetchPiece(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(hash.GenerateChecksum(data), checksum) {
+		return nil, fmt.Errorf("%w: %s", hash.ErrPieceChecksumMismatch, key)
+	}
+	return data, nil
+}
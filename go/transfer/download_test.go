@@ -0,0 +1,130 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+var errUnavailable = errors.New("sp unavailable")
+
+// memoryFetcher serves the pieces and the checksums of the objects split by storeObject
+type memoryFetcher struct {
+	mu        sync.Mutex
+	pieces    map[piece.Key][]byte
+	checksums map[int32][][]byte
+	// unavailable are the ec indexes failing every fetch
+	unavailable map[int32]bool
+	fetched     int
+}
+
+func (f *memoryFetcher) FetchChecksums(_ context.Context, _ uint64, ecIndex int32) ([][]byte, error) {
+	if f.unavailable[ecIndex] {
+		return nil, errUnavailable
+	}
+	return f.checksums[ecIndex], nil
+}
+
+func (f *memoryFetcher) FetchPiece(_ context.Context, key piece.Key) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched++
+	data, ok := f.pieces[key]
+	if !ok || f.unavailable[key.ECIndex] {
+		return nil, errUnavailable
+	}
+	return data, nil
+}
+
+// storeObject splits the content into its segments and ec pieces and return the meta of the object and a fetcher
+// serving its pieces
+func storeObject(t *testing.T, objectID uint64, content []byte, params redundancy.RedundancyParams) (ObjectMeta,
+	*memoryFetcher,
+) {
+	fetcher := &memoryFetcher{
+		pieces:      make(map[piece.Key][]byte),
+		checksums:   make(map[int32][][]byte),
+		unavailable: make(map[int32]bool),
+	}
+	for segIndex := 0; int64(segIndex)*params.SegmentSize < int64(len(content)); segIndex++ {
+		segment := content[int64(segIndex)*params.SegmentSize:min(int64(segIndex+1)*params.SegmentSize,
+			int64(len(content)))]
+		fetcher.checksums[piece.NoECIndex] = append(fetcher.checksums[piece.NoECIndex],
+			hash.GenerateChecksum(segment))
+		pieces, err := params.Encode(segment)
+		require.NoError(t, err)
+		for ecIndex, data := range pieces {
+			fetcher.pieces[piece.NewECKey(objectID, uint32(segIndex), uint32(ecIndex))] = data
+			fetcher.checksums[int32(ecIndex)] = append(fetcher.checksums[int32(ecIndex)], hash.GenerateChecksum(data))
+		}
+	}
+
+	checksums, contentLength, _, err := hash.ComputeIntegrityHashSerial(bytes.NewReader(content), params.SegmentSize,
+		params.DataShards, params.ParityShards)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(content)), contentLength)
+	for ecIndex := int32(-1); ecIndex < int32(params.PieceCount()); ecIndex++ {
+		require.Equal(t, checksums[ecIndex+1], hash.GenerateIntegrityHash(fetcher.checksums[ecIndex]))
+	}
+	return ObjectMeta{ObjectID: objectID, ContentLength: contentLength, Params: params, Roots: checksums}, fetcher
+}
+
+func TestDownload(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(3*1024 + 10)
+	ctx := context.Background()
+
+	meta, fetcher := storeObject(t, 1, content, params)
+	var buf bytes.Buffer
+	require.NoError(t, NewDownloader(fetcher).Download(ctx, meta, &buf))
+	assert.Equal(t, content, buf.Bytes())
+	assert.Equal(t, 4*4, fetcher.fetched)
+
+	fetcher.unavailable[1] = true
+	fetcher.pieces[piece.NewECKey(1, 2, 3)] = []byte("corrupted")
+	fetcher.fetched = 0
+	buf.Reset()
+	require.NoError(t, NewDownloader(fetcher).Download(ctx, meta, &buf))
+	assert.Equal(t, content, buf.Bytes())
+
+	fetcher.checksums[5] = fetcher.checksums[4]
+	buf.Reset()
+	err = NewDownloader(fetcher).Download(ctx, meta, &buf)
+	assert.ErrorIs(t, err, ErrTooFewPieces)
+	assert.ErrorIs(t, err, hash.ErrIntegrityHashMismatch)
+	assert.ErrorIs(t, err, hash.ErrPieceChecksumMismatch)
+	assert.ErrorIs(t, err, errUnavailable)
+	assert.Equal(t, content[:2*1024], buf.Bytes())
+}
+
+func TestDownloadInvalidMeta(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	meta, fetcher := storeObject(t, 1, nil, params)
+	var buf bytes.Buffer
+	require.NoError(t, NewDownloader(fetcher).Download(ctx, meta, &buf))
+	assert.Empty(t, buf.Bytes())
+
+	meta, fetcher = storeObject(t, 2, hash.TestVectorData(100), params)
+	invalid := meta
+	invalid.Roots = invalid.Roots[:3]
+	assert.ErrorIs(t, NewDownloader(fetcher).Download(ctx, invalid, &buf), ErrInvalidObjectMeta)
+	invalid = meta
+	invalid.ContentLength = 2000
+	assert.ErrorIs(t, NewDownloader(fetcher).Download(ctx, invalid, &buf), ErrInvalidObjectMeta)
+	invalid = meta
+	invalid.Roots = append([][]byte{hash.EmptyIntegrityHash()}, meta.Roots[1:]...)
+	assert.ErrorIs(t, NewDownloader(fetcher).Download(ctx, invalid, &buf), hash.ErrIntegrityHashMismatch)
+}
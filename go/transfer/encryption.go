@@ -0,0 +1,55 @@
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/crypto/envelope"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+)
+
+// UploadEncrypted encrypts the object read from reader with a new data key wrapped by kek and uploads the ciphertext
+// like Upload. It return the integrity hashes of the ciphertext, whose content length is the content length of the
+// object on chain, and the envelope to store with the object to decrypt it.
+func (u *Uploader) UploadEncrypted(ctx context.Context, kek envelope.KeyEncryptionKey, objectID uint64,
+	reader io.Reader,
+) (*hash.HashResult, *envelope.Envelope, error) {
+	env, dataKey, err := envelope.NewEnvelope(ctx, kek, u.params.SegmentSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext, err := env.EncryptReader(dataKey, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := u.Upload(ctx, objectID, ciphertext)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, env, nil
+}
+
+// DownloadDecrypted downloads the object encrypted by UploadEncrypted like Download and writes its plaintext to
+// writer, the data key of the envelope is unwrapped by kek. Every segment is verified then authenticated before its
+// plaintext is written.
+func (d *Downloader) DownloadDecrypted(ctx context.Context, meta ObjectMeta, env *envelope.Envelope,
+	kek envelope.KeyEncryptionKey, writer io.Writer,
+) error {
+	if env.SegmentSize != meta.Params.SegmentSize {
+		return fmt.Errorf("%w: envelope of segment size %d, segment size %d", ErrInvalidObjectMeta, env.SegmentSize,
+			meta.Params.SegmentSize)
+	}
+	dataKey, err := env.DataKey(ctx, kek)
+	if err != nil {
+		return err
+	}
+	plaintext, err := env.DecryptWriter(dataKey, writer)
+	if err != nil {
+		return err
+	}
+	if err = d.Download(ctx, meta, plaintext); err != nil {
+		return err
+	}
+	return plaintext.Close()
+}
@@ -0,0 +1,108 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/crypto/envelope"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// xorKEK wraps the data keys by xoring them with a fixed byte
+type xorKEK byte
+
+func (k xorKEK) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	wrapped := bytes.Clone(dataKey)
+	for i := range wrapped {
+		wrapped[i] ^= byte(k)
+	}
+	return wrapped, nil
+}
+
+func (k xorKEK) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	return k.WrapKey(ctx, wrappedKey)
+}
+
+// storeFetcher serves the pieces of a piece store and computes the checksums of an object from them
+type storeFetcher struct {
+	store    piecestore.PieceStore
+	segments int64
+}
+
+func (f *storeFetcher) FetchChecksums(ctx context.Context, objectID uint64, ecIndex int32) ([][]byte, error) {
+	checksums := make([][]byte, f.segments)
+	for segIndex := range checksums {
+		data, err := f.store.Get(ctx, piece.Key{ObjectID: objectID, SegmentIndex: uint32(segIndex), ECIndex: ecIndex})
+		if err != nil {
+			return nil, err
+		}
+		checksums[segIndex] = hash.GenerateChecksum(data)
+	}
+	return checksums, nil
+}
+
+func (f *storeFetcher) FetchPiece(ctx context.Context, key piece.Key) ([]byte, error) {
+	return f.store.Get(ctx, key)
+}
+
+func TestEncryptedTransfer(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	kek := xorKEK(0x5a)
+
+	for _, size := range []int64{0, 100, 1008, 3*1024 + 10} {
+		content := hash.TestVectorData(size)
+		store := piecestore.NewMemoryStore()
+		uploader, err := NewUploader(store, params)
+		require.NoError(t, err)
+		result, env, err := uploader.UploadEncrypted(ctx, kek, 3, bytes.NewReader(content))
+		require.NoError(t, err)
+		assert.Equal(t, env.CiphertextLength(size), result.ContentLength)
+		if size > 0 {
+			segment, err := store.Get(ctx, piece.NewSegmentKey(3, 0))
+			require.NoError(t, err)
+			assert.NotContains(t, string(segment), string(content[:min(size, 32)]))
+		}
+
+		meta := ObjectMeta{ObjectID: 3, ContentLength: result.ContentLength, Params: params, Roots: result.Checksums}
+		fetcher := &storeFetcher{store: store, segments: redundancy.SegmentCount(result.ContentLength, 1024)}
+		var buf bytes.Buffer
+		require.NoError(t, NewDownloader(fetcher).DownloadDecrypted(ctx, meta, env, kek, &buf))
+		assert.True(t, bytes.Equal(content, buf.Bytes()), size)
+
+		buf.Reset()
+		require.NoError(t, NewDownloader(fetcher).Download(ctx, meta, &buf))
+		assert.Equal(t, result.ContentLength, int64(buf.Len()))
+	}
+}
+
+func TestEncryptedTransferFailures(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	store := piecestore.NewMemoryStore()
+	uploader, err := NewUploader(store, params)
+	require.NoError(t, err)
+	result, env, err := uploader.UploadEncrypted(ctx, xorKEK(1), 3, bytes.NewReader(hash.TestVectorData(3000)))
+	require.NoError(t, err)
+	meta := ObjectMeta{ObjectID: 3, ContentLength: result.ContentLength, Params: params, Roots: result.Checksums}
+	fetcher := &storeFetcher{store: store, segments: 3}
+
+	var buf bytes.Buffer
+	err = NewDownloader(fetcher).DownloadDecrypted(ctx, meta, env, xorKEK(2), &buf)
+	assert.ErrorIs(t, err, envelope.ErrDecryptFailed)
+	assert.Empty(t, buf.Bytes())
+
+	other := *env
+	other.SegmentSize = 2048
+	err = NewDownloader(fetcher).DownloadDecrypted(ctx, meta, &other, xorKEK(1), &buf)
+	assert.ErrorIs(t, err, ErrInvalidObjectMeta)
+}
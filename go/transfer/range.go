@@ -0,0 +1,85 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// ErrInvalidRange is returned when a range is not within the content of its object
+var ErrInvalidRange = errors.New("invalid range")
+
+// RangeReader reads a range of an object. Only the segments overlapping the range are fetched and reconstructed, one
+// at a time, and each of them is verified against the segment checksums of the PrimarySP before its bytes are
+// returned, like Download.
+type RangeReader struct {
+	ctx              context.Context
+	downloader       *Downloader
+	meta             ObjectMeta
+	layout           redundancy.ObjectLayout
+	segmentChecksums [][]byte
+	pieceChecksums   *pieceChecksums
+
+	// offset is the offset in the object of the next byte to read, end the offset following the range
+	offset int64
+	end    int64
+	// buf are the bytes of the current segment following offset
+	buf []byte
+	err error
+}
+
+// NewRangeReader return a RangeReader of the length bytes of the object starting at offset, ErrInvalidRange is
+// returned if the range is not within the content of the object. The segment checksums of the PrimarySP are fetched
+// and verified before it returns.
+func (d *Downloader) NewRangeReader(ctx context.Context, meta ObjectMeta, offset, length int64) (*RangeReader,
+	error,
+) {
+	if offset < 0 || length < 0 || offset > meta.ContentLength || length > meta.ContentLength-offset {
+		return nil, fmt.Errorf("%w: [%d, %d+%d) of an object of %d bytes", ErrInvalidRange, offset, offset, length,
+			meta.ContentLength)
+	}
+	segmentChecksums, err := d.segmentChecksums(ctx, meta)
+	if err != nil {
+		return nil, err
+	}
+	return &RangeReader{
+		ctx:              ctx,
+		downloader:       d,
+		meta:             meta,
+		layout:           meta.Params.Layout(meta.ContentLength),
+		segmentChecksums: segmentChecksums,
+		pieceChecksums:   &pieceChecksums{lists: make(map[int32]*checksumList)},
+		offset:           offset,
+		end:              offset + length,
+	}, nil
+}
+
+// Read implements io.Reader, it return io.EOF at the end of the range. A segment failing to be reconstructed or
+// verified fails every following Read.
+func (r *RangeReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(r.buf) == 0 {
+		if r.offset >= r.end {
+			return 0, io.EOF
+		}
+		segIndex := r.offset / r.layout.SegmentSize
+		segment, err := r.downloader.verifiedSegment(r.ctx, r.meta, r.layout, r.segmentChecksums, r.pieceChecksums,
+			segIndex, piece.NoECIndex)
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		segmentOffset := segIndex * r.layout.SegmentSize
+		r.buf = segment[r.offset-segmentOffset : min(int64(len(segment)), r.end-segmentOffset)]
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.offset += int64(n)
+	return n, nil
+}
@@ -0,0 +1,76 @@
+package transfer
+
+import (
+	"context"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestRangeReader(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(3*1024 + 10)
+	ctx := context.Background()
+	meta, fetcher := storeObject(t, 1, content, params)
+	downloader := NewDownloader(fetcher)
+
+	for _, tc := range []struct {
+		offset, length int64
+		segments       int
+	}{
+		{0, 0, 0},
+		{0, int64(len(content)), 4},
+		{100, 200, 1},
+		{1000, 100, 2},
+		{1024, 1024, 1},
+		{2047, 1000, 2},
+		{3 * 1024, 10, 1},
+		{int64(len(content)), 0, 0},
+	} {
+		fetcher.fetched = 0
+		reader, err := downloader.NewRangeReader(ctx, meta, tc.offset, tc.length)
+		require.NoError(t, err)
+		require.NoError(t, iotest.TestReader(reader, content[tc.offset:tc.offset+tc.length]),
+			"[%d, +%d)", tc.offset, tc.length)
+		assert.Equal(t, 4*tc.segments, fetcher.fetched, "[%d, +%d)", tc.offset, tc.length)
+	}
+
+	for _, r := range [][2]int64{{-1, 10}, {0, -1}, {0, int64(len(content)) + 1}, {int64(len(content)) + 1, 0}} {
+		_, err = downloader.NewRangeReader(ctx, meta, r[0], r[1])
+		assert.ErrorIs(t, err, ErrInvalidRange)
+	}
+}
+
+func TestRangeReaderCorruption(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(3 * 1024)
+	ctx := context.Background()
+	meta, fetcher := storeObject(t, 1, content, params)
+
+	// a segment checksum not matching the integrity hash of the PrimarySP
+	fetcher.checksums[piece.NoECIndex][1] = hash.GenerateChecksum([]byte("corrupted"))
+	_, err = NewDownloader(fetcher).NewRangeReader(ctx, meta, 1024, 10)
+	assert.ErrorIs(t, err, hash.ErrIntegrityHashMismatch)
+
+	// too many unavailable pieces fail the read of the segment, not the previous ones
+	meta, fetcher = storeObject(t, 1, content, params)
+	fetcher.pieces[piece.NewECKey(1, 1, 0)] = []byte("corrupted")
+	fetcher.pieces[piece.NewECKey(1, 1, 1)] = []byte("corrupted")
+	fetcher.unavailable[4] = true
+	reader, err := NewDownloader(fetcher).NewRangeReader(ctx, meta, 512, 1024)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	assert.ErrorIs(t, err, ErrTooFewPieces)
+	assert.Equal(t, content[512:1024], data)
+	_, err = reader.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrTooFewPieces)
+}
@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+)
+
+// DefaultListPageSize is the number of objects listed at a time by Recoverer.Recover
+const DefaultListPageSize = 100
+
+// ErrInvalidECIndex is returned when an ec index is out of the pieces of the redundancy params of an object
+var ErrInvalidECIndex = errors.New("invalid ec index")
+
+// ObjectLister lists the objects stored on a SecondarySP, e.g. from the chain or a metadata service
+type ObjectLister interface {
+	// ListObjects return at most limit objects whose pieces of the ec index are stored on the SP, ordered by object
+	// id and following the object after, which is 0 for the first page. An empty page ends the listing.
+	ListObjects(ctx context.Context, ecIndex uint32, after uint64, limit int) ([]ObjectMeta, error)
+}
+
+// RecoveryReport is the outcome of the recovery of the objects of an ec index
+type RecoveryReport struct {
+	Recovered int
+	// Failed are the errors of the objects which could not be recovered by object id
+	Failed map[uint64]error
+}
+
+// Recoverer rebuilds the ec pieces of a failed SecondarySP from the surviving pieces of the other SPs. The pieces of
+// a segment are fetched and verified like Downloader does, the segment is reconstructed and verified against the
+// segment checksums of the PrimarySP, then erasure encoded again to get the missing piece. The checksums of the
+// rebuilt pieces of an object are verified against the integrity hash of the failed SP before any of them is put, so
+// the rebuilt pieces of an object are held in memory until it is verified.
+type Recoverer struct {
+	downloader *Downloader
+	lister     ObjectLister
+	sink       PieceSink
+	pageSize   int
+}
+
+// NewRecoverer return a Recoverer fetching the surviving pieces with fetcher, listing the objects to recover with
+// lister and putting the rebuilt pieces into sink, the PieceSink of the new SecondarySP
+func NewRecoverer(fetcher PieceFetcher, lister ObjectLister, sink PieceSink) *Recoverer {
+	return &Recoverer{
+		downloader: NewDownloader(fetcher),
+		lister:     lister,
+		sink:       sink,
+		pageSize:   DefaultListPageSize,
+	}
+}
+
+// RecoverObject rebuilds the pieces of the ec index of the object and puts them into the sink once their checksums
+// match the integrity hash of the ec index
+func (r *Recoverer) RecoverObject(ctx context.Context, meta ObjectMeta, ecIndex uint32) error {
+	segmentChecksums, err := r.downloader.segmentChecksums(ctx, meta)
+	if err != nil {
+		return err
+	}
+	if int(ecIndex) >= meta.Params.PieceCount() {
+		return fmt.Errorf("%w: %d of %d pieces", ErrInvalidECIndex, ecIndex, meta.Params.PieceCount())
+	}
+
+	layout := meta.Params.Layout(meta.ContentLength)
+	pieceChecksums := &pieceChecksums{lists: make(map[int32]*checksumList)}
+	pieces := make([][]byte, layout.SegmentCount)
+	checksums := make([][]byte, layout.SegmentCount)
+	for segIndex := int64(0); segIndex < layout.SegmentCount; segIndex++ {
+		segment, err := r.downloader.verifiedSegment(ctx, meta, layout, segmentChecksums, pieceChecksums, segIndex,
+			int32(ecIndex))
+		if err != nil {
+			return err
+		}
+		encoded, err := meta.Params.Encode(segment)
+		if err != nil {
+			return &hash.SegmentError{Segment: int(segIndex), Kind: hash.ErrEncodeFailed, Err: err}
+		}
+		pieces[segIndex] = encoded[ecIndex]
+		checksums[segIndex] = hash.GenerateChecksum(encoded[ecIndex])
+	}
+	if err = hash.VerifyIntegrityHash(meta.Roots[ecIndex+1], checksums); err != nil {
+		return fmt.Errorf("rebuilt pieces of ec index %d: %w", ecIndex, err)
+	}
+
+	for segIndex, data := range pieces {
+		key := piece.NewECKey(meta.ObjectID, uint32(segIndex), ecIndex)
+		if err = r.sink.Put(ctx, key, data); err != nil {
+			return fmt.Errorf("%w %s: %w", ErrPutPieceFailed, key, err)
+		}
+	}
+	return nil
+}
+
+// Recover rebuilds the pieces of the ec index of every object listed by the lister. An object failing to be
+// recovered is recorded in the report and the recovery goes on; an error is returned only if the listing fails or
+// ctx is done, with the report of the objects handled so far.
+func (r *Recoverer) Recover(ctx context.Context, ecIndex uint32) (RecoveryReport, error) {
+	report := RecoveryReport{Failed: make(map[uint64]error)}
+	after := uint64(0)
+	for {
+		objects, err := r.lister.ListObjects(ctx, ecIndex, after, r.pageSize)
+		if err != nil {
+			return report, fmt.Errorf("failed to list the objects after %d: %w", after, err)
+		}
+		if len(objects) == 0 {
+			return report, nil
+		}
+		for _, meta := range objects {
+			if err = ctx.Err(); err != nil {
+				return report, err
+			}
+			if err = r.RecoverObject(ctx, meta, ecIndex); err != nil {
+				log.Errorf("failed to recover ec index %d of object %d: %s", ecIndex, meta.ObjectID, err)
+				report.Failed[meta.ObjectID] = err
+				continue
+			}
+			report.Recovered++
+		}
+		after = objects[len(objects)-1].ObjectID
+	}
+}
@@ -0,0 +1,130 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// objectFetcher routes the fetches to the memoryFetcher of each object
+type objectFetcher map[uint64]*memoryFetcher
+
+func (f objectFetcher) FetchChecksums(ctx context.Context, objectID uint64, ecIndex int32) ([][]byte, error) {
+	return f[objectID].FetchChecksums(ctx, objectID, ecIndex)
+}
+
+func (f objectFetcher) FetchPiece(ctx context.Context, key piece.Key) ([]byte, error) {
+	return f[key.ObjectID].FetchPiece(ctx, key)
+}
+
+type memoryLister []ObjectMeta
+
+func (l memoryLister) ListObjects(_ context.Context, _ uint32, after uint64, limit int) ([]ObjectMeta, error) {
+	var page []ObjectMeta
+	for _, meta := range l {
+		if meta.ObjectID > after && len(page) < limit {
+			page = append(page, meta)
+		}
+	}
+	return page, nil
+}
+
+type memorySink struct {
+	mu     sync.Mutex
+	pieces map[piece.Key][]byte
+}
+
+func (s *memorySink) Put(_ context.Context, key piece.Key, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pieces[key] = data
+	return nil
+}
+
+func TestRecoverer(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	const ecIndex = 2
+
+	fetchers := objectFetcher{}
+	var lister memoryLister
+	for objectID, size := range []int64{0, 3*1024 + 10, 100, 1024, 5000} {
+		meta, fetcher := storeObject(t, uint64(objectID+1), hash.TestVectorData(size), params)
+		fetchers[meta.ObjectID] = fetcher
+		lister = append(lister, meta)
+	}
+	// the failed SP and two other unavailable SPs of the object 4, which can not be reconstructed
+	lost := make(map[piece.Key][]byte)
+	for objectID, fetcher := range fetchers {
+		for key, data := range fetcher.pieces {
+			if key.ECIndex == ecIndex {
+				lost[key] = data
+			}
+		}
+		fetcher.unavailable[ecIndex] = true
+		if objectID == 4 {
+			fetcher.unavailable[0] = true
+			fetcher.unavailable[1] = true
+		}
+	}
+	// a corrupted surviving piece is replaced by a parity piece
+	fetchers[2].pieces[piece.NewECKey(2, 1, 0)] = []byte("corrupted")
+
+	sink := &memorySink{pieces: make(map[piece.Key][]byte)}
+	recoverer := NewRecoverer(fetchers, lister, sink)
+	recoverer.pageSize = 2
+	report, err := recoverer.Recover(ctx, ecIndex)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Recovered)
+	require.Len(t, report.Failed, 1)
+	assert.ErrorIs(t, report.Failed[4], ErrTooFewPieces)
+	for key, data := range lost {
+		if key.ObjectID != 4 {
+			assert.Equal(t, data, sink.pieces[key], key.String())
+		}
+	}
+	assert.Len(t, sink.pieces, len(lost)-1)
+}
+
+func TestRecoverObjectVerification(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+	meta, fetcher := storeObject(t, 1, hash.TestVectorData(3000), params)
+	sink := &memorySink{pieces: make(map[piece.Key][]byte)}
+	recoverer := NewRecoverer(fetcher, memoryLister{meta}, sink)
+
+	err = recoverer.RecoverObject(ctx, meta, uint32(params.PieceCount()))
+	assert.ErrorIs(t, err, ErrInvalidECIndex)
+
+	// the rebuilt pieces do not match the integrity hash of the failed SP
+	meta.Roots[4] = hash.GenerateIntegrityHash([][]byte{hash.GenerateChecksum([]byte("other"))})
+	err = recoverer.RecoverObject(ctx, meta, 3)
+	assert.ErrorIs(t, err, hash.ErrIntegrityHashMismatch)
+	assert.Empty(t, sink.pieces)
+
+	failing := PieceSinkFunc(func(context.Context, piece.Key, []byte) error { return errUnavailable })
+	err = NewRecoverer(fetcher, memoryLister{meta}, failing).RecoverObject(ctx, meta, 0)
+	assert.ErrorIs(t, err, ErrPutPieceFailed)
+	assert.ErrorIs(t, err, errUnavailable)
+}
+
+type failingLister struct{}
+
+func (failingLister) ListObjects(context.Context, uint32, uint64, int) ([]ObjectMeta, error) {
+	return nil, errors.New("list failed")
+}
+
+func TestRecoverListFailure(t *testing.T) {
+	_, err := NewRecoverer(objectFetcher{}, failingLister{}, &memorySink{}).Recover(context.Background(), 0)
+	assert.ErrorContains(t, err, "list failed")
+}
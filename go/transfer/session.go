@@ -0,0 +1,357 @@
+package transfer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+var (
+	// ErrSessionNotFound is returned when no upload session is stored under the id
+	ErrSessionNotFound = errors.New("upload session not found")
+	// ErrSessionMismatch is returned when an upload session is resumed for another object or with other ec params
+	ErrSessionMismatch = errors.New("upload session does not match the upload")
+	// ErrInvalidSessionID is returned when a session id can not be used as a file name
+	ErrInvalidSessionID = errors.New("invalid upload session id")
+)
+
+// UploadSession is the progress of an upload, persisted so an interrupted upload resumes from its last completed
+// segment, which is the last segment whose pieces are all acknowledged
+type UploadSession struct {
+	ID       string `json:"id"`
+	ObjectID uint64 `json:"object_id"`
+	// Checkpoint is the state of the hash computation after the segments read, some of which may not be completed
+	Checkpoint hash.HasherCheckpoint `json:"checkpoint"`
+	// Acknowledged is the number of leading segments whose pieces are acknowledged by each SP, the PrimarySP first
+	// followed by the SecondarySPs ordered by ec index
+	Acknowledged []uint32 `json:"acknowledged"`
+}
+
+// CompletedSegments return the number of leading segments acknowledged by every SP
+func (s *UploadSession) CompletedSegments() uint32 {
+	if len(s.Acknowledged) == 0 {
+		return 0
+	}
+	completed := s.Acknowledged[0]
+	for _, acknowledged := range s.Acknowledged[1:] {
+		completed = min(completed, acknowledged)
+	}
+	return completed
+}
+
+// SessionStore persists the upload sessions
+type SessionStore interface {
+	// SaveSession stores the session under its id, replacing the previous one
+	SaveSession(ctx context.Context, session *UploadSession) error
+	// LoadSession return the session stored under the id, or ErrSessionNotFound
+	LoadSession(ctx context.Context, id string) (*UploadSession, error)
+	// DeleteSession removes the session stored under the id, deleting a missing session is not an error
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// MemorySessionStore is a SessionStore keeping the sessions in memory, e.g. for tests. It is safe for concurrent
+// use.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string][]byte
+}
+
+// NewMemorySessionStore return an empty MemorySessionStore
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string][]byte)}
+}
+
+// SaveSession implements SessionStore, the session is copied
+func (s *MemorySessionStore) SaveSession(_ context.Context, session *UploadSession) error {
+	encoded, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.ID] = encoded
+	return nil
+}
+
+// LoadSession implements SessionStore
+func (s *MemorySessionStore) LoadSession(_ context.Context, id string) (*UploadSession, error) {
+	s.mu.Lock()
+	encoded, ok := s.sessions[id]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	session := &UploadSession{}
+	return session, json.Unmarshal(encoded, session)
+}
+
+// DeleteSession implements SessionStore
+func (s *MemorySessionStore) DeleteSession(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// FileSessionStore is a SessionStore keeping every session in a JSON file of a local directory named by its id
+type FileSessionStore struct {
+	dir string
+}
+
+// NewFileSessionStore return a FileSessionStore of the directory, which is created if it does not exist
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// path return the path of the file of the session
+func (s *FileSessionStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || filepath.Base(id) != id {
+		return "", fmt.Errorf("%w: %q", ErrInvalidSessionID, id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+// SaveSession implements SessionStore, the file is replaced atomically
+func (s *FileSessionStore) SaveSession(_ context.Context, session *UploadSession) error {
+	path, err := s.path(session.ID)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, session.ID+".tmp*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(encoded)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save upload session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// LoadSession implements SessionStore
+func (s *FileSessionStore) LoadSession(_ context.Context, id string) (*UploadSession, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &UploadSession{}
+	return session, json.Unmarshal(encoded, session)
+}
+
+// DeleteSession implements SessionStore
+func (s *FileSessionStore) DeleteSession(_ context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// KVSessionStore is a SessionStore keeping every session in JSON in a key-value store under a prefix followed by
+// its id, e.g. to share the database of a service
+type KVSessionStore struct {
+	store  store.Store
+	prefix []byte
+}
+
+// NewKVSessionStore return a KVSessionStore of the key-value store keeping the sessions under prefix
+func NewKVSessionStore(s store.Store, prefix string) *KVSessionStore {
+	return &KVSessionStore{store: s, prefix: []byte(prefix)}
+}
+
+// key return the key of the session
+func (s *KVSessionStore) key(id string) ([]byte, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSessionID, id)
+	}
+	return append(append([]byte(nil), s.prefix...), id...), nil
+}
+
+// SaveSession implements SessionStore
+func (s *KVSessionStore) SaveSession(_ context.Context, session *UploadSession) error {
+	key, err := s.key(session.ID)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	if err = s.store.Put(key, encoded); err != nil {
+		return fmt.Errorf("failed to save upload session %s: %w", session.ID, err)
+	}
+	return nil
+}
+
+// LoadSession implements SessionStore
+func (s *KVSessionStore) LoadSession(_ context.Context, id string) (*UploadSession, error) {
+	key, err := s.key(id)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := s.store.Get(key)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &UploadSession{}
+	return session, json.Unmarshal(encoded, session)
+}
+
+// DeleteSession implements SessionStore
+func (s *KVSessionStore) DeleteSession(_ context.Context, id string) error {
+	key, err := s.key(id)
+	if err != nil {
+		return err
+	}
+	return s.store.Delete(key)
+}
+
+// Sessions return the ids of the stored sessions, e.g. to resume the interrupted uploads at startup
+func (s *KVSessionStore) Sessions() ([]string, error) {
+	var ids []string
+	err := store.ForEach(s.store, s.prefix, func(key, _ []byte) error {
+		ids = append(ids, string(key[len(s.prefix):]))
+		return nil
+	})
+	return ids, err
+}
+
+// sessionTracker records the acknowledged pieces of an upload into its session and saves the session every time a
+// segment is completed
+type sessionTracker struct {
+	mu      sync.Mutex
+	ctx     context.Context
+	store   SessionStore
+	session *UploadSession
+	// pending are the acknowledged segments of each SP following its leading acknowledged segments
+	pending   []map[uint32]struct{}
+	completed uint32
+}
+
+// ack records the acknowledged piece
+func (t *sessionTracker) ack(key piece.Key) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sp := int(key.ECIndex) + 1
+	t.pending[sp][key.SegmentIndex] = struct{}{}
+	for {
+		if _, ok := t.pending[sp][t.session.Acknowledged[sp]]; !ok {
+			break
+		}
+		delete(t.pending[sp], t.session.Acknowledged[sp])
+		t.session.Acknowledged[sp]++
+	}
+	if completed := t.session.CompletedSegments(); completed > t.completed {
+		t.completed = completed
+		return t.store.SaveSession(t.ctx, t.session)
+	}
+	return nil
+}
+
+// UploadResumable uploads the object like Upload and persists its progress in the session stored under id. If the
+// session exists, the upload resumes from its last completed segment: reader is sought to the end of the completed
+// segments and only the following segments are read and put. The session is deleted once the upload succeeds.
+// ErrSessionMismatch is returned if the session was created for another object or with other ec params.
+func (u *Uploader) UploadResumable(ctx context.Context, store SessionStore, id string, objectID uint64,
+	reader io.ReadSeeker,
+) (*hash.HashResult, error) {
+	session, err := store.LoadSession(ctx, id)
+	if errors.Is(err, ErrSessionNotFound) {
+		session = &UploadSession{
+			ID:           id,
+			ObjectID:     objectID,
+			Checkpoint:   u.newCheckpoint(),
+			Acknowledged: make([]uint32, 1+u.params.PieceCount()),
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	if err = u.checkSession(session, objectID); err != nil {
+		return nil, err
+	}
+
+	completed := session.CompletedSegments()
+	if session.Checkpoint, err = session.Checkpoint.Truncate(int(completed)); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSessionMismatch, err)
+	}
+	tracker := &sessionTracker{ctx: ctx, store: store, session: session, completed: completed,
+		pending: make([]map[uint32]struct{}, len(session.Acknowledged))}
+	for sp := range session.Acknowledged {
+		session.Acknowledged[sp] = completed
+		tracker.pending[sp] = make(map[uint32]struct{})
+	}
+	if _, err = reader.Seek(session.Checkpoint.ContentLength, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("%w: %w", hash.ErrReaderFailed, err)
+	}
+	if err = store.SaveSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	result, err := u.upload(ctx, objectID, reader, &session.Checkpoint, &tracker.mu, tracker.ack)
+	if err != nil {
+		return nil, err
+	}
+	if err = store.DeleteSession(ctx, id); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkSession return ErrSessionMismatch if the session is not the session of the object uploaded by the uploader
+func (u *Uploader) checkSession(session *UploadSession, objectID uint64) error {
+	checkpoint := session.Checkpoint
+	switch {
+	case session.ObjectID != objectID:
+		return fmt.Errorf("%w: session of object %d", ErrSessionMismatch, session.ObjectID)
+	case checkpoint.SegmentSize != u.params.SegmentSize || checkpoint.DataShards != u.params.DataShards ||
+		checkpoint.ParityShards != u.params.ParityShards:
+		return fmt.Errorf("%w: segment size %d, %d data shards, %d parity shards", ErrSessionMismatch,
+			checkpoint.SegmentSize, checkpoint.DataShards, checkpoint.ParityShards)
+	case len(session.Acknowledged) != 1+u.params.PieceCount():
+		return fmt.Errorf("%w: acknowledged segments of %d sps", ErrSessionMismatch, len(session.Acknowledged))
+	}
+	if err := checkpoint.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrSessionMismatch, err)
+	}
+	return nil
+}
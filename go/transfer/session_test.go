@@ -0,0 +1,121 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+	"github.com/zkMeLabs/mechain-common/go/store"
+)
+
+func TestUploadResumable(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(4*1024 + 10)
+	expected, contentLength, redundancyType, err := hash.ComputeIntegrityHashSerial(bytes.NewReader(content),
+		params.SegmentSize, params.DataShards, params.ParityShards)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	fileStore, err := NewFileSessionStore(t.TempDir())
+	require.NoError(t, err)
+	kvStore := NewKVSessionStore(store.NewMemoryStore(), "sessions/")
+	for name, store := range map[string]SessionStore{"memory": NewMemorySessionStore(), "file": fileStore,
+		"kv": kvStore} {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			var puts []piece.Key
+			broken := piece.NewECKey(9, 2, 1)
+			sink := PieceSinkFunc(func(_ context.Context, key piece.Key, _ []byte) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if key == broken {
+					return &hash.StatusError{Method: http.MethodPut, URL: key.String(), StatusCode: http.StatusForbidden}
+				}
+				puts = append(puts, key)
+				return nil
+			})
+			uploader, err := NewUploader(sink, params, WithConcurrency(1), WithRetry(0, time.Millisecond))
+			require.NoError(t, err)
+			_, err = uploader.UploadResumable(ctx, store, "upload", 9, bytes.NewReader(content))
+			assert.ErrorIs(t, err, ErrPutPieceFailed)
+
+			session, err := store.LoadSession(ctx, "upload")
+			require.NoError(t, err)
+			assert.Equal(t, uint32(2), session.CompletedSegments())
+
+			other, err := NewUploader(sink, redundancy.RedundancyParams{SegmentSize: 2048, DataShards: 4,
+				ParityShards: 2})
+			require.NoError(t, err)
+			_, err = other.UploadResumable(ctx, store, "upload", 9, bytes.NewReader(content))
+			assert.ErrorIs(t, err, ErrSessionMismatch)
+			_, err = uploader.UploadResumable(ctx, store, "upload", 10, bytes.NewReader(content))
+			assert.ErrorIs(t, err, ErrSessionMismatch)
+
+			broken = piece.Key{}
+			puts = nil
+			result, err := uploader.UploadResumable(ctx, store, "upload", 9, bytes.NewReader(content))
+			require.NoError(t, err)
+			assert.Equal(t, hash.NewHashResult(expected, contentLength, redundancyType), result)
+			assert.Len(t, puts, 3*(1+params.PieceCount()))
+			for _, key := range puts {
+				assert.GreaterOrEqual(t, key.SegmentIndex, uint32(2))
+			}
+			_, err = store.LoadSession(ctx, "upload")
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+			assert.NoError(t, store.DeleteSession(ctx, "upload"))
+		})
+	}
+
+	_, err = fileStore.LoadSession(ctx, "../upload")
+	assert.ErrorIs(t, err, ErrInvalidSessionID)
+	require.NoError(t, kvStore.SaveSession(ctx, &UploadSession{ID: "pending", ObjectID: 9}))
+	ids, err := kvStore.Sessions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pending"}, ids)
+}
+
+func TestUploadResumableCompleted(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(2*1024 + 10)
+	ctx := context.Background()
+	store := NewMemorySessionStore()
+
+	var puts int
+	uploader, err := NewUploader(PieceSinkFunc(func(context.Context, piece.Key, []byte) error {
+		puts++
+		return nil
+	}), params, WithConcurrency(1))
+	require.NoError(t, err)
+	expected, err := uploader.Upload(ctx, 3, bytes.NewReader(content))
+	require.NoError(t, err)
+
+	// the upload was interrupted after all the pieces were put but before the session was deleted
+	checkpoint := uploader.newCheckpoint()
+	hasher, err := hash.RestoreHasher(checkpoint)
+	require.NoError(t, err)
+	require.NoError(t, hasher.Append(content[:1024]))
+	require.NoError(t, hasher.Append(content[1024:2048]))
+	require.NoError(t, hasher.Append(content[2048:]))
+	_, _, _, err = hasher.Finish()
+	require.NoError(t, err)
+	completed := &UploadSession{ID: "done", ObjectID: 3, Checkpoint: hasher.Checkpoint(),
+		Acknowledged: []uint32{3, 3, 3, 3, 3, 3, 3}}
+	require.NoError(t, store.SaveSession(ctx, completed))
+
+	puts = 0
+	result, err := uploader.UploadResumable(ctx, store, "done", 3, bytes.NewReader(content))
+	require.NoError(t, err)
+	assert.Equal(t, expected, result)
+	assert.Zero(t, puts)
+}
@@ -0,0 +1,242 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zkMeLabs/mechain-common/go/breaker"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/log"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+	"github.com/zkMeLabs/mechain-common/go/retry"
+	"github.com/zkMeLabs/mechain-common/go/segment"
+)
+
+const (
+	// DefaultMaxRetries is the number of times a failed put of a piece is retried
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the delay before the first retry, it doubles on every retry
+	DefaultRetryBackoff = 100 * time.Millisecond
+	// DefaultConcurrency is the number of pieces put at the same time
+	DefaultConcurrency = 8
+)
+
+// ErrPutPieceFailed is returned when a piece can not be put to its SP, it wraps the error of the PieceSink
+var ErrPutPieceFailed = errors.New("failed to put piece")
+
+// PieceSink stores the pieces of the objects on the SPs, a piecestore.PieceStore is a PieceSink
+type PieceSink interface {
+	// Put stores the piece on its SP: the segment pieces, whose ec index is piece.NoECIndex, on the PrimarySP and the
+	// ec pieces of ec index i on the SecondarySP i
+	Put(ctx context.Context, key piece.Key, data []byte) error
+}
+
+// PieceSinkFunc adapts a func to a PieceSink
+type PieceSinkFunc func(ctx context.Context, key piece.Key, data []byte) error
+
+// Put implements PieceSink
+func (f PieceSinkFunc) Put(ctx context.Context, key piece.Key, data []byte) error {
+	return f(ctx, key, data)
+}
+
+// Option configures an Uploader
+type Option func(*Uploader)
+
+// WithRetry retries a failed put of a piece up to maxRetries times, waiting backoff before the first retry and
+// doubling it on every retry. Puts rejected by the SP, e.g. with 403, are not retried.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(u *Uploader) {
+		u.maxRetries = maxRetries
+		u.retryBackoff = backoff
+	}
+}
+
+// WithBreakers puts the pieces through the circuit breakers of breakers, keyed by the endpoint of the SP of each piece
+// returned by endpoint, or PieceEndpoint if nil. The puts to the SP of an open breaker fail at once, without retry,
+// instead of stalling the uploads on a slow or failing SP.
+func WithBreakers(breakers *breaker.Group, endpoint func(key piece.Key) string) Option {
+	return func(u *Uploader) {
+		if endpoint == nil {
+			endpoint = PieceEndpoint
+		}
+		u.breakers = breakers
+		u.endpoint = endpoint
+	}
+}
+
+// PieceEndpoint return the SP storing the piece by its role, "primary" for the segment pieces and "secondary-<ec
+// index>" for the ec pieces, for the PieceSinks whose SPs are not known by the Uploader
+func PieceEndpoint(key piece.Key) string {
+	if !key.IsECPiece() {
+		return "primary"
+	}
+	return "secondary-" + strconv.Itoa(int(key.ECIndex))
+}
+
+// WithConcurrency sets the number of pieces put at the same time, which bounds the memory of an upload
+func WithConcurrency(n int) Option {
+	return func(u *Uploader) {
+		if n > 0 {
+			u.concurrency = n
+		}
+	}
+}
+
+// Uploader splits the objects into their segments, computes their integrity hashes and erasure encodes them, then
+// puts the segment pieces and the ec pieces through a PieceSink
+type Uploader struct {
+	sink         PieceSink
+	params       redundancy.RedundancyParams
+	maxRetries   int
+	retryBackoff time.Duration
+	concurrency  int
+	breakers     *breaker.Group
+	endpoint     func(key piece.Key) string
+}
+
+// NewUploader return an Uploader putting the pieces of the objects split by params into sink
+func NewUploader(sink PieceSink, params redundancy.RedundancyParams, opts ...Option) (*Uploader, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	u := &Uploader{
+		sink:         sink,
+		params:       params,
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: DefaultRetryBackoff,
+		concurrency:  DefaultConcurrency,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u, nil
+}
+
+// Upload reads the object from reader and puts its pieces, it return the integrity hashes to create and seal the
+// object once every piece is put. The first failed put cancels the upload.
+func (u *Uploader) Upload(ctx context.Context, objectID uint64, reader io.Reader) (*hash.HashResult, error) {
+	checkpoint := u.newCheckpoint()
+	return u.upload(ctx, objectID, reader, &checkpoint, &sync.Mutex{}, nil)
+}
+
+// newCheckpoint return the hasher checkpoint of an object without segment
+func (u *Uploader) newCheckpoint() hash.HasherCheckpoint {
+	return hash.HasherCheckpoint{
+		SegmentSize:      u.params.SegmentSize,
+		DataShards:       u.params.DataShards,
+		ParityShards:     u.params.ParityShards,
+		SegmentChecksums: make([][]byte, 0),
+		PieceChecksums:   make([][][]byte, u.params.PieceCount()),
+	}
+}
+
+// upload puts the pieces of the segments read from reader, which follow the segments of the checkpoint. The
+// checksums of the segments are appended to the checkpoint while holding mu and acked, if not nil, is called after
+// every put, an error returned by acked fails the upload.
+func (u *Uploader) upload(ctx context.Context, objectID uint64, reader io.Reader, checkpoint *hash.HasherCheckpoint,
+	mu sync.Locker, acked func(key piece.Key) error,
+) (*hash.HashResult, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, u.concurrency)
+	put := func(key piece.Key, data []byte) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			err := u.put(ctx, key, data)
+			if err != nil {
+				err = fmt.Errorf("%w %s: %w", ErrPutPieceFailed, key, err)
+			} else if acked != nil {
+				err = acked(key)
+			}
+			if err != nil {
+				cancel(err)
+			}
+		}()
+	}
+
+	readErr := func() error {
+		// a resumed upload reads the segments following the ones of the checkpoint, the segments are put
+		// asynchronously so they are never released to the pool of the splitter
+		firstIndex := uint32(len(checkpoint.SegmentChecksums))
+		splitter := segment.SplitBySegment(reader, u.params.SegmentSize)
+		for ctx.Err() == nil {
+			index, data, _, err := splitter.Next()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %w", hash.ErrReaderFailed, err)
+			}
+			segIndex := firstIndex + uint32(index)
+			pieces, err := u.params.Encode(data)
+			if err != nil {
+				return &hash.SegmentError{Segment: int(segIndex), Kind: hash.ErrEncodeFailed, Err: err}
+			}
+			mu.Lock()
+			checkpoint.ContentLength += int64(len(data))
+			checkpoint.SegmentChecksums = append(checkpoint.SegmentChecksums, hash.GenerateChecksum(data))
+			for ecIndex, pieceData := range pieces {
+				checkpoint.PieceChecksums[ecIndex] = append(checkpoint.PieceChecksums[ecIndex],
+					hash.GenerateChecksum(pieceData))
+			}
+			mu.Unlock()
+			put(piece.NewSegmentKey(objectID, segIndex), data)
+			for ecIndex, pieceData := range pieces {
+				put(piece.NewECKey(objectID, segIndex, uint32(ecIndex)), pieceData)
+			}
+		}
+		return nil
+	}()
+	if readErr != nil {
+		cancel(readErr)
+	}
+	wg.Wait()
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+
+	hasher, err := hash.RestoreHasher(*checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	checksums, contentLength, redundancyType, err := hasher.Finish()
+	if err != nil {
+		return nil, err
+	}
+	return hash.NewHashResult(checksums, contentLength, redundancyType), nil
+}
+
+// put puts the piece into the sink through its circuit breaker if any, retrying the temporary failures
+func (u *Uploader) put(ctx context.Context, key piece.Key, data []byte) error {
+	return retry.Do(ctx, func(ctx context.Context) error {
+		if u.breakers == nil {
+			return u.sink.Put(ctx, key, data)
+		}
+		err := u.breakers.Do(ctx, u.endpoint(key), func(ctx context.Context) error {
+			return u.sink.Put(ctx, key, data)
+		})
+		if errors.Is(err, breaker.ErrOpen) {
+			return retry.Permanent(err)
+		}
+		return err
+	}, retry.WithMaxRetries(u.maxRetries), retry.WithBackoff(u.retryBackoff, 0, 2), retry.WithJitter(0),
+		retry.WithOnRetry(func(_ int, err error, delay time.Duration) {
+			log.Warnf("failed to put piece %s, retry in %s: %s", key, delay, err)
+		}))
+}
@@ -0,0 +1,155 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/breaker"
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/piecestore"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func TestUpload(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, size := range []int64{0, 100, 1024, 3*1024 + 10} {
+		content := hash.TestVectorData(size)
+		store := piecestore.NewMemoryStore()
+		var active, maxActive atomic.Int32
+		sink := PieceSinkFunc(func(ctx context.Context, key piece.Key, data []byte) error {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for current := maxActive.Load(); n > current && !maxActive.CompareAndSwap(current, n); {
+				current = maxActive.Load()
+			}
+			time.Sleep(time.Millisecond)
+			return store.Put(ctx, key, data)
+		})
+		uploader, err := NewUploader(sink, params, WithConcurrency(3))
+		require.NoError(t, err)
+		result, err := uploader.Upload(ctx, 5, bytes.NewReader(content))
+		require.NoError(t, err)
+
+		checksums, contentLength, redundancyType, err := hash.ComputeIntegrityHashSerial(bytes.NewReader(content),
+			params.SegmentSize, params.DataShards, params.ParityShards)
+		require.NoError(t, err)
+		assert.Equal(t, hash.NewHashResult(checksums, contentLength, redundancyType), result)
+		assert.LessOrEqual(t, maxActive.Load(), int32(3))
+
+		segments := redundancy.SegmentCount(size, params.SegmentSize)
+		for segIndex := int64(0); segIndex < segments; segIndex++ {
+			segment, err := store.Get(ctx, piece.NewSegmentKey(5, uint32(segIndex)))
+			require.NoError(t, err)
+			assert.Equal(t, content[segIndex*params.SegmentSize:min((segIndex+1)*params.SegmentSize, size)], segment)
+			for ecIndex := uint32(0); ecIndex < uint32(params.PieceCount()); ecIndex++ {
+				_, err = store.Stat(ctx, piece.NewECKey(5, uint32(segIndex), ecIndex))
+				assert.NoError(t, err)
+			}
+		}
+	}
+}
+
+func TestUploadRetry(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(2048)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	failures := make(map[piece.Key]int)
+	flaky := PieceSinkFunc(func(_ context.Context, key piece.Key, _ []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if failures[key] < 2 {
+			failures[key]++
+			return &hash.StatusError{Method: http.MethodPut, URL: key.String(), StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	uploader, err := NewUploader(flaky, params, WithRetry(2, time.Millisecond))
+	require.NoError(t, err)
+	_, err = uploader.Upload(ctx, 1, bytes.NewReader(content))
+	require.NoError(t, err)
+
+	uploader, err = NewUploader(flaky, params, WithRetry(5, time.Millisecond))
+	require.NoError(t, err)
+	failures = make(map[piece.Key]int)
+	rejected := piece.NewECKey(1, 1, 3)
+	failures[rejected] = -100
+	_, err = uploader.Upload(ctx, 1, bytes.NewReader(content))
+	assert.ErrorIs(t, err, ErrPutPieceFailed)
+	assert.Equal(t, -100+6, failures[rejected])
+
+	forbidden := PieceSinkFunc(func(_ context.Context, key piece.Key, _ []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		failures[key]++
+		if key == rejected {
+			return &hash.StatusError{Method: http.MethodPut, URL: key.String(), StatusCode: http.StatusForbidden}
+		}
+		return nil
+	})
+	uploader, err = NewUploader(forbidden, params, WithRetry(5, time.Millisecond))
+	require.NoError(t, err)
+	failures = make(map[piece.Key]int)
+	_, err = uploader.Upload(ctx, 1, bytes.NewReader(content))
+	assert.ErrorIs(t, err, ErrPutPieceFailed)
+	var statusErr *hash.StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, 1, failures[rejected])
+
+	uploader, err = NewUploader(PieceSinkFunc(func(context.Context, piece.Key, []byte) error { return nil }), params)
+	require.NoError(t, err)
+	_, err = uploader.Upload(ctx, 1, io.MultiReader(bytes.NewReader(content), iotest.ErrReader(io.ErrClosedPipe)))
+	assert.ErrorIs(t, err, hash.ErrReaderFailed)
+	assert.ErrorIs(t, err, io.ErrClosedPipe)
+
+	_, err = NewUploader(flaky, redundancy.RedundancyParams{SegmentSize: 1024, DataShards: 4})
+	assert.ErrorIs(t, err, redundancy.ErrInvalidRedundancyParams)
+}
+
+func TestUploadBreakers(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(1024, 4, 2)
+	require.NoError(t, err)
+	content := hash.TestVectorData(2048)
+	ctx := context.Background()
+
+	var failed atomic.Int32
+	sink := PieceSinkFunc(func(_ context.Context, key piece.Key, _ []byte) error {
+		if key.ECIndex == 3 {
+			failed.Add(1)
+			return &hash.StatusError{Method: http.MethodPut, URL: key.String(), StatusCode: http.StatusServiceUnavailable}
+		}
+		return nil
+	})
+	breakers := breaker.NewGroup(breaker.Config{MinCalls: 1})
+	uploader, err := NewUploader(sink, params, WithConcurrency(1), WithRetry(5, time.Millisecond),
+		WithBreakers(breakers, nil))
+	require.NoError(t, err)
+	_, err = uploader.Upload(ctx, 1, bytes.NewReader(content))
+	assert.ErrorIs(t, err, ErrPutPieceFailed)
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Equal(t, int32(1), failed.Load())
+
+	_, err = uploader.Upload(ctx, 2, bytes.NewReader(content))
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Equal(t, int32(1), failed.Load())
+	health := breakers.Health()
+	assert.Equal(t, breaker.StateOpen, health["secondary-3"].State)
+	assert.Equal(t, breaker.StateClosed, health["primary"].State)
+	assert.Equal(t, "secondary-0", PieceEndpoint(piece.NewECKey(1, 0, 0)))
+}
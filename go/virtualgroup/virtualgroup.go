@@ -0,0 +1,121 @@
+// Package virtualgroup maps the pieces of the objects to the SPs of their global virtual group: the PrimarySP
+// stores the segments and the SecondarySP at index i of the group stores the ec pieces of ec index i
+package virtualgroup
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+var (
+	// ErrInvalidVirtualGroup is returned when the SPs of a global virtual group do not match the redundancy params
+	ErrInvalidVirtualGroup = errors.New("invalid global virtual group")
+	// ErrSPNotInVirtualGroup is returned when a SP is not a member of the global virtual group
+	ErrSPNotInVirtualGroup = errors.New("sp is not in the global virtual group")
+	// ErrInvalidECIndex is returned when an ec index is out of the secondary SPs of the global virtual group
+	ErrInvalidECIndex = errors.New("invalid ec index")
+	// ErrSecondaryRootsMismatch is returned when the number of the integrity hashes of the secondary SPs does not
+	// match the global virtual group
+	ErrSecondaryRootsMismatch = errors.New("secondary integrity hashes do not match the global virtual group")
+)
+
+// GlobalVirtualGroup is the group of SPs storing the objects of a global virtual group on chain
+type GlobalVirtualGroup struct {
+	ID          uint32
+	FamilyID    uint32
+	PrimarySPID uint32
+	// SecondarySPIDs are the secondary SPs ordered by the ec index of the pieces they store
+	SecondarySPIDs []uint32
+}
+
+// Validate return ErrInvalidVirtualGroup if the group does not have one distinct secondary SP for every ec piece of
+// the params, or if the PrimarySP is also a secondary SP
+func (g *GlobalVirtualGroup) Validate(params redundancy.RedundancyParams) error {
+	if len(g.SecondarySPIDs) != params.PieceCount() {
+		return fmt.Errorf("%w: %d secondary sps, expect %d", ErrInvalidVirtualGroup, len(g.SecondarySPIDs),
+			params.PieceCount())
+	}
+	seen := make(map[uint32]struct{}, len(g.SecondarySPIDs))
+	for _, spID := range g.SecondarySPIDs {
+		if spID == g.PrimarySPID {
+			return fmt.Errorf("%w: primary sp %d is a secondary sp", ErrInvalidVirtualGroup, spID)
+		}
+		if _, ok := seen[spID]; ok {
+			return fmt.Errorf("%w: duplicated secondary sp %d", ErrInvalidVirtualGroup, spID)
+		}
+		seen[spID] = struct{}{}
+	}
+	return nil
+}
+
+// ECIndex return the ec index of the pieces stored by the secondary SP
+func (g *GlobalVirtualGroup) ECIndex(spID uint32) (int, error) {
+	for ecIndex, secondarySPID := range g.SecondarySPIDs {
+		if secondarySPID == spID {
+			return ecIndex, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: sp %d is not a secondary sp of group %d", ErrSPNotInVirtualGroup, spID, g.ID)
+}
+
+// SecondarySP return the secondary SP storing the pieces of the ec index
+func (g *GlobalVirtualGroup) SecondarySP(ecIndex int) (uint32, error) {
+	if ecIndex < 0 || ecIndex >= len(g.SecondarySPIDs) {
+		return 0, fmt.Errorf("%w: %d of %d secondary sps", ErrInvalidECIndex, ecIndex, len(g.SecondarySPIDs))
+	}
+	return g.SecondarySPIDs[ecIndex], nil
+}
+
+// PieceOwner return the SP storing the piece, the PrimarySP for a segment piece
+func (g *GlobalVirtualGroup) PieceOwner(key piece.Key) (uint32, error) {
+	if !key.IsECPiece() {
+		return g.PrimarySPID, nil
+	}
+	return g.SecondarySP(int(key.ECIndex))
+}
+
+// PieceKeys return the keys of the pieces of the object of segmentCount segments stored by the SP, the segments
+// for the PrimarySP and the ec pieces of its ec index for a secondary SP
+func (g *GlobalVirtualGroup) PieceKeys(spID uint32, objectID uint64, segmentCount uint32) ([]piece.Key, error) {
+	newKey := func(segIndex uint32) piece.Key { return piece.NewSegmentKey(objectID, segIndex) }
+	if spID != g.PrimarySPID {
+		ecIndex, err := g.ECIndex(spID)
+		if err != nil {
+			return nil, err
+		}
+		newKey = func(segIndex uint32) piece.Key { return piece.NewECKey(objectID, segIndex, uint32(ecIndex)) }
+	}
+	keys := make([]piece.Key, segmentCount)
+	for segIndex := range keys {
+		keys[segIndex] = newKey(uint32(segIndex))
+	}
+	return keys, nil
+}
+
+// CheckSecondaryRoots return ErrSecondaryRootsMismatch if there is not one integrity hash for every secondary SP
+func (g *GlobalVirtualGroup) CheckSecondaryRoots(secondaryRoots [][]byte) error {
+	if len(secondaryRoots) != len(g.SecondarySPIDs) {
+		return fmt.Errorf("%w: %d integrity hashes, %d secondary sps", ErrSecondaryRootsMismatch,
+			len(secondaryRoots), len(g.SecondarySPIDs))
+	}
+	return nil
+}
+
+// IntegrityHash return the integrity hash of the pieces stored by the SP from the hash result of the object
+func (g *GlobalVirtualGroup) IntegrityHash(result *hash.HashResult, spID uint32) ([]byte, error) {
+	if err := g.CheckSecondaryRoots(result.SecondaryChecksums()); err != nil {
+		return nil, err
+	}
+	if spID == g.PrimarySPID {
+		return result.PrimaryChecksum(), nil
+	}
+	ecIndex, err := g.ECIndex(spID)
+	if err != nil {
+		return nil, err
+	}
+	return result.SecondaryChecksums()[ecIndex], nil
+}
@@ -0,0 +1,94 @@
+package virtualgroup
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/piece"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+func newGroup() *GlobalVirtualGroup {
+	return &GlobalVirtualGroup{ID: 7, FamilyID: 1, PrimarySPID: 1, SecondarySPIDs: []uint32{11, 12, 13, 14, 15, 16}}
+}
+
+func TestValidate(t *testing.T) {
+	params, err := redundancy.NewRedundancyParams(16*1024*1024, 4, 2)
+	require.NoError(t, err)
+	assert.NoError(t, newGroup().Validate(params))
+
+	group := newGroup()
+	group.SecondarySPIDs = group.SecondarySPIDs[:5]
+	assert.ErrorIs(t, group.Validate(params), ErrInvalidVirtualGroup)
+	group = newGroup()
+	group.SecondarySPIDs[5] = 11
+	assert.ErrorIs(t, group.Validate(params), ErrInvalidVirtualGroup)
+	group = newGroup()
+	group.SecondarySPIDs[0] = group.PrimarySPID
+	assert.ErrorIs(t, group.Validate(params), ErrInvalidVirtualGroup)
+}
+
+func TestIndexes(t *testing.T) {
+	group := newGroup()
+	for ecIndex, spID := range group.SecondarySPIDs {
+		index, err := group.ECIndex(spID)
+		require.NoError(t, err)
+		assert.Equal(t, ecIndex, index)
+		secondarySPID, err := group.SecondarySP(ecIndex)
+		require.NoError(t, err)
+		assert.Equal(t, spID, secondarySPID)
+		owner, err := group.PieceOwner(piece.NewECKey(100, 3, uint32(ecIndex)))
+		require.NoError(t, err)
+		assert.Equal(t, spID, owner)
+	}
+	owner, err := group.PieceOwner(piece.NewSegmentKey(100, 3))
+	require.NoError(t, err)
+	assert.Equal(t, group.PrimarySPID, owner)
+
+	_, err = group.ECIndex(group.PrimarySPID)
+	assert.ErrorIs(t, err, ErrSPNotInVirtualGroup)
+	_, err = group.SecondarySP(6)
+	assert.ErrorIs(t, err, ErrInvalidECIndex)
+	_, err = group.SecondarySP(-1)
+	assert.ErrorIs(t, err, ErrInvalidECIndex)
+	_, err = group.PieceOwner(piece.NewECKey(100, 3, 6))
+	assert.ErrorIs(t, err, ErrInvalidECIndex)
+}
+
+func TestPieceKeys(t *testing.T) {
+	group := newGroup()
+	keys, err := group.PieceKeys(group.PrimarySPID, 100, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []piece.Key{piece.NewSegmentKey(100, 0), piece.NewSegmentKey(100, 1)}, keys)
+
+	keys, err = group.PieceKeys(13, 100, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []piece.Key{piece.NewECKey(100, 0, 2), piece.NewECKey(100, 1, 2)}, keys)
+
+	_, err = group.PieceKeys(99, 100, 2)
+	assert.ErrorIs(t, err, ErrSPNotInVirtualGroup)
+}
+
+func TestIntegrityHash(t *testing.T) {
+	group := newGroup()
+	result, err := hash.ComputeIntegrityHashWithOptions(bytes.NewReader([]byte("object")), 16*1024, 4, 2)
+	require.NoError(t, err)
+
+	root, err := group.IntegrityHash(result, group.PrimarySPID)
+	require.NoError(t, err)
+	assert.Equal(t, result.PrimaryChecksum(), root)
+	root, err = group.IntegrityHash(result, 16)
+	require.NoError(t, err)
+	assert.Equal(t, result.SecondaryChecksums()[5], root)
+
+	_, err = group.IntegrityHash(result, 99)
+	assert.ErrorIs(t, err, ErrSPNotInVirtualGroup)
+	assert.ErrorIs(t, group.CheckSecondaryRoots(result.SecondaryChecksums()[:5]), ErrSecondaryRootsMismatch)
+	_, err = group.IntegrityHash(hash.NewHashResult(result.Checksums[:6], result.ContentLength,
+		result.RedundancyType), 16)
+	assert.ErrorIs(t, err, ErrSecondaryRootsMismatch)
+}
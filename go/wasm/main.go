@@ -0,0 +1,184 @@
+//go:build js && wasm
+
+// Command wasm exposes the integrity hashes of the hash package to JavaScript, so the dapps compute the checksums of
+// their objects in the browser exactly like the Go SDKs. Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o mechain-hash.wasm ./wasm
+//
+// or make wasm, and load it with mechain-hash.js and the wasm_exec.js of the Go distribution. It sets the global
+// mechainHash object:
+//
+//	mechainHash.computeIntegrityHash(data, segmentSize, dataShards, parityShards) // Promise of the result
+//	const hasher = mechainHash.newIntegrityHasher(segmentSize, dataShards, parityShards)
+//	await hasher.write(chunk) // for every Uint8Array chunk of the object
+//	await hasher.close() // Promise of the result, or hasher.abort(reason)
+//
+// The result is {checksums, contentLength, redundancyType}, the checksums are in base64, the integrity hash of the
+// PrimarySP first. The zero parameters take the defaults of the redundancy package.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"syscall/js"
+
+	"github.com/zkMeLabs/mechain-common/go/hash"
+	"github.com/zkMeLabs/mechain-common/go/redundancy"
+)
+
+// defaultSegmentSize is the segment size of the mechain storage params
+const defaultSegmentSize = 16 * 1024 * 1024
+
+func main() {
+	js.Global().Set("mechainHash", js.ValueOf(map[string]any{
+		"computeIntegrityHash": js.FuncOf(computeIntegrityHash),
+		"newIntegrityHasher":   js.FuncOf(newIntegrityHasher),
+	}))
+	// the callbacks run as long as the program is alive
+	select {}
+}
+
+// params return the segment size and the numbers of shards of the arguments, 0 or missing for the defaults
+func params(args []js.Value) (segmentSize int64, dataShards, parityShards int) {
+	segmentSize, dataShards, parityShards = defaultSegmentSize, redundancy.DataBlocks, redundancy.ParityBlocks
+	if len(args) > 0 && args[0].Truthy() {
+		segmentSize = int64(args[0].Int())
+	}
+	if len(args) > 1 && args[1].Truthy() {
+		dataShards = args[1].Int()
+	}
+	if len(args) > 2 && args[2].Truthy() {
+		parityShards = args[2].Int()
+	}
+	return segmentSize, dataShards, parityShards
+}
+
+// computeIntegrityHash(data, segmentSize, dataShards, parityShards) return the Promise of the result of the
+// Uint8Array data
+func computeIntegrityHash(_ js.Value, args []js.Value) any {
+	if len(args) == 0 || !isUint8Array(args[0]) {
+		return reject(errors.New("computeIntegrityHash expects a Uint8Array"))
+	}
+	data := make([]byte, args[0].Length())
+	js.CopyBytesToGo(data, args[0])
+	segmentSize, dataShards, parityShards := params(args[1:])
+	return promise(func() (any, error) {
+		result, err := hash.ComputeIntegrityHashWithOptions(bytes.NewReader(data), segmentSize, dataShards,
+			parityShards)
+		if err != nil {
+			return nil, err
+		}
+		return resultValue(result), nil
+	})
+}
+
+// newIntegrityHasher(segmentSize, dataShards, parityShards) return a hasher of the chunks written to it, e.g. the
+// chunks of a ReadableStream
+func newIntegrityHasher(_ js.Value, args []js.Value) any {
+	segmentSize, dataShards, parityShards := params(args)
+	reader, writer := io.Pipe()
+	type outcome struct {
+		result *hash.HashResult
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := hash.ComputeIntegrityHashWithOptions(reader, segmentSize, dataShards, parityShards)
+		_ = reader.CloseWithError(err)
+		done <- outcome{result: result, err: err}
+	}()
+
+	var funcs []js.Func
+	method := func(fn func(args []js.Value) any) js.Func {
+		f := js.FuncOf(func(_ js.Value, args []js.Value) any { return fn(args) })
+		funcs = append(funcs, f)
+		return f
+	}
+	release := func() {
+		for _, f := range funcs {
+			f.Release()
+		}
+	}
+	return js.ValueOf(map[string]any{
+		// write(chunk) return a Promise resolved once the chunk is hashed, the chunk may be reused afterwards
+		"write": method(func(args []js.Value) any {
+			if len(args) == 0 || !isUint8Array(args[0]) {
+				return reject(errors.New("write expects a Uint8Array"))
+			}
+			chunk := make([]byte, args[0].Length())
+			js.CopyBytesToGo(chunk, args[0])
+			return promise(func() (any, error) {
+				_, err := writer.Write(chunk)
+				return nil, err
+			})
+		}),
+		// close() ends the object and return the Promise of its result
+		"close": method(func([]js.Value) any {
+			_ = writer.Close()
+			return promise(func() (any, error) {
+				outcome := <-done
+				release()
+				if outcome.err != nil {
+					return nil, outcome.err
+				}
+				return resultValue(outcome.result), nil
+			})
+		}),
+		// abort(reason) stops the hashing
+		"abort": method(func(args []js.Value) any {
+			reason := "aborted"
+			if len(args) > 0 && args[0].Truthy() {
+				reason = args[0].String()
+			}
+			_ = writer.CloseWithError(errors.New(reason))
+			go func() {
+				<-done
+				release()
+			}()
+			return nil
+		}),
+	})
+}
+
+// resultValue return the JavaScript object of the result
+func resultValue(result *hash.HashResult) js.Value {
+	checksums := make([]any, len(result.Checksums))
+	for i, checksum := range result.Checksums {
+		checksums[i] = base64.StdEncoding.EncodeToString(checksum)
+	}
+	return js.ValueOf(map[string]any{
+		"checksums":      checksums,
+		"contentLength":  result.ContentLength,
+		"redundancyType": result.RedundancyType.String(),
+	})
+}
+
+func isUint8Array(v js.Value) bool {
+	return v.InstanceOf(js.Global().Get("Uint8Array"))
+}
+
+// promise return a Promise settled by fn, which runs in its own goroutine so it does not block the event loop
+func promise(fn func() (any, error)) js.Value {
+	executor := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolve, rejectFn := args[0], args[1]
+		go func() {
+			v, err := fn()
+			if err != nil {
+				rejectFn.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return nil
+	})
+	// the executor is called by the constructor of the Promise
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
+
+// reject return a Promise rejected with err
+func reject(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(err.Error()))
+}
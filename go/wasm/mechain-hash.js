@@ -0,0 +1,69 @@
+// mechain-hash.js computes the integrity hashes of the mechain objects in the browsers and in Node.js with the Go
+// hash package compiled to WebAssembly, see main.go. wasm_exec.js of the Go distribution must be loaded first, it
+// defines the global Go class.
+//
+//	import { init, computeIntegrityHash } from './mechain-hash.js';
+//
+//	await init(fetch('/mechain-hash.wasm'));
+//	const { checksums, contentLength, redundancyType } = await computeIntegrityHash(file.stream());
+
+let loaded;
+
+// init instantiates the WebAssembly module of source, a Response, a Promise of a Response or the bytes of the
+// module, and resolves once the mechainHash functions are defined. It only instantiates the module once.
+export function init(source) {
+  if (!loaded) {
+    loaded = instantiate(source).catch((err) => {
+      loaded = undefined;
+      throw err;
+    });
+  }
+  return loaded;
+}
+
+async function instantiate(source) {
+  const go = new globalThis.Go();
+  source = await source;
+  let instance;
+  if (typeof Response !== 'undefined' && source instanceof Response) {
+    ({ instance } = await WebAssembly.instantiateStreaming(source, go.importObject));
+  } else {
+    ({ instance } = await WebAssembly.instantiate(source, go.importObject));
+  }
+  // run resolves when the Go program exits, which it does not, the functions are defined synchronously
+  go.run(instance);
+  if (!globalThis.mechainHash) {
+    throw new Error('mechain-hash: the WebAssembly module did not define mechainHash');
+  }
+  return globalThis.mechainHash;
+}
+
+// computeIntegrityHash resolves to the integrity hashes {checksums, contentLength, redundancyType} of input, a
+// Uint8Array or a ReadableStream of Uint8Arrays. The checksums are in base64, the integrity hash of the PrimarySP
+// first. The params default to a segment size of 16 MiB, 4 data shards and 2 parity shards.
+export async function computeIntegrityHash(input, { segmentSize = 0, dataShards = 0, parityShards = 0 } = {}) {
+  if (!loaded) {
+    throw new Error('mechain-hash: init must be called first');
+  }
+  const mechainHash = await loaded;
+  if (input instanceof Uint8Array) {
+    return mechainHash.computeIntegrityHash(input, segmentSize, dataShards, parityShards);
+  }
+  const hasher = mechainHash.newIntegrityHasher(segmentSize, dataShards, parityShards);
+  const reader = input.getReader();
+  try {
+    for (;;) {
+      const { done, value } = await reader.read();
+      if (done) {
+        break;
+      }
+      await hasher.write(value);
+    }
+  } catch (err) {
+    hasher.abort(String(err));
+    throw err;
+  } finally {
+    reader.releaseLock();
+  }
+  return hasher.close();
+}